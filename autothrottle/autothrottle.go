@@ -0,0 +1,773 @@
+// Package autothrottle provides the autothrottle replication throttle engine.
+// Autothrottle observes ongoing partition reassignments and dynamically sets
+// replication throttles on participating brokers according to their available
+// network capacity. The engine can be embedded by other services through Run;
+// the autothrottle binary in cmd/autothrottle is a thin wrapper around it.
+package autothrottle
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/cruisecontrol"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/decisionlog"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/history"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/k8s"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/kafkastate"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/orchestrator"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/prometheus"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/tracing"
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+var (
+	// ErrNoZK is returned when a Config has no ZooKeeper handler.
+	ErrNoZK = errors.New("a ZooKeeper handler must be specified")
+	// ErrNoMetrics is returned when a Config has no metrics handler.
+	ErrNoMetrics = errors.New("a metrics handler must be specified")
+	// ErrInvalidInterval is returned when a Config interval is <= 0.
+	ErrInvalidInterval = errors.New("interval must be > 0")
+	// ErrInvalidIntervalJitter is returned when a Config interval jitter is
+	// negative or not less than the interval.
+	ErrInvalidIntervalJitter = errors.New("interval jitter must be >= 0 and < interval")
+	// ErrKubernetesWithAPI is returned when both Kubernetes operator mode and
+	// the admin API are configured.
+	ErrKubernetesWithAPI = errors.New("the admin API can't be used in Kubernetes operator mode")
+	// ErrObserveOnlyConflict is returned when observe-only mode is configured
+	// along with the admin API or Kubernetes operator mode.
+	ErrObserveOnlyConflict = errors.New("the admin API and Kubernetes operator mode can't be used in observe-only mode")
+	// ErrFairShareWithoutBudget is returned when fair-share allocation is
+	// configured without a cluster replication budget.
+	ErrFairShareWithoutBudget = errors.New("fair-share allocation requires a cluster max rate")
+	// ErrTopicClassesWithoutFairShare is returned when topic priority classes
+	// are configured without fair-share allocation.
+	ErrTopicClassesWithoutFairShare = errors.New("topic priority classes require fair-share allocation")
+	// ErrDebugWithoutAPI is returned when the debug endpoints are enabled
+	// without the admin API.
+	ErrDebugWithoutAPI = errors.New("the debug endpoints require the admin API")
+	// ErrInvalidAPIRateLimit is returned when the admin API rate limit or
+	// burst is negative.
+	ErrInvalidAPIRateLimit = errors.New("the admin API rate limit and burst must be >= 0")
+	// ErrStateTopicWithoutKafkaNative is returned when a state topic is
+	// configured without Kafka native mode.
+	ErrStateTopicWithoutKafkaNative = errors.New("storing state in a Kafka topic requires Kafka native mode")
+	// ErrStateTopicWithACL is returned when a state topic is configured along
+	// with a config znode ACL.
+	ErrStateTopicWithACL = errors.New("config znode ACLs can't be used when storing state in a Kafka topic")
+	// ErrMultipleStateStores is returned when more than one of a state topic,
+	// etcd or Consul is configured to store state in.
+	ErrMultipleStateStores = errors.New("only one of a state topic, etcd or Consul can store state")
+	// ErrStateStoreWithACL is returned when etcd or Consul is configured to
+	// store state in along with a config znode ACL.
+	ErrStateStoreWithACL = errors.New("config znode ACLs can't be used when storing state in etcd or Consul")
+	// ErrDecisionTopicWithoutKafkaNative is returned when a decision topic is
+	// configured without Kafka native mode.
+	ErrDecisionTopicWithoutKafkaNative = errors.New("writing throttle decisions to a Kafka topic requires Kafka native mode")
+	// ErrControlTopicWithoutKafkaNative is returned when a control topic is
+	// configured without Kafka native mode.
+	ErrControlTopicWithoutKafkaNative = errors.New("reading commands from a Kafka control topic requires Kafka native mode")
+	// ErrControlTopicWithKubernetes is returned when both a control topic and
+	// Kubernetes operator mode are configured.
+	ErrControlTopicWithKubernetes = errors.New("a control topic can't be used in Kubernetes operator mode")
+	// ErrPauseOnBrokerLossWithoutWatch is returned when pausing on broker loss
+	// is configured without a broker watch interval.
+	ErrPauseOnBrokerLossWithoutWatch = errors.New("pausing on broker loss requires a broker watch interval")
+	// ErrOrphanRemovalWithoutScan is returned when orphaned throttle removal
+	// is configured without an orphan scan interval.
+	ErrOrphanRemovalWithoutScan = errors.New("removing orphaned throttles requires an orphan scan interval")
+	// ErrInvalidManagerLockTTL is returned when the throttle manager lock TTL
+	// isn't greater than the interval, such that the lease would expire
+	// between renewals.
+	ErrInvalidManagerLockTTL = errors.New("the throttle manager lock TTL must be greater than the interval")
+	// ErrInvalidCruiseControlMode is returned when the Cruise Control
+	// integration mode is neither defer nor manage.
+	ErrInvalidCruiseControlMode = errors.New("the Cruise Control mode must be either defer or manage")
+	// ErrHistoryFileWithoutRetention is returned when a history file is
+	// configured without a history retention.
+	ErrHistoryFileWithoutRetention = errors.New("a history file requires a history retention")
+	// ErrMetricsSubmissionUnsupported is returned when metrics submission is
+	// enabled with a metrics handler that can't submit metrics.
+	ErrMetricsSubmissionUnsupported = errors.New("the metrics handler doesn't support metrics submission")
+	// ErrKRaftModeWithoutKafkaNative is returned when KRaft mode is configured
+	// without Kafka native mode.
+	ErrKRaftModeWithoutKafkaNative = errors.New("running without ZooKeeper requires Kafka native mode")
+	// ErrKRaftModeWithoutStateStore is returned when KRaft mode is configured
+	// without a state topic, etcd or Consul to store state in.
+	ErrKRaftModeWithoutStateStore = errors.New("running without ZooKeeper requires a state topic, etcd or Consul to store state in")
+	// ErrManagerLockInKRaftMode is returned when the throttle manager lock is
+	// enabled in KRaft mode.
+	ErrManagerLockInKRaftMode = errors.New("the throttle manager lock requires ZooKeeper")
+)
+
+// EventWriter writes autothrottle events, such as throttle changes and
+// reassignment progress.
+type EventWriter interface {
+	Write(title string, message string)
+	// WriteCritical writes an event flagged as a critical alert.
+	WriteCritical(title string, message string)
+	// WriteTagged writes an event with the tags applied in addition to any
+	// default tags.
+	WriteTagged(title string, message string, tags ...string)
+}
+
+// DroppedEventsCounter is implemented by EventWriters that may drop events,
+// e.g. when a buffer is full. The count is reported in the status and, in
+// observe-only mode, as a Prometheus metric.
+type DroppedEventsCounter interface {
+	DroppedEvents() uint64
+}
+
+// Config holds Run configurations.
+type Config struct {
+	// The ZooKeeper handler used for cluster metadata and autothrottle state.
+	ZK kafkazk.Handler
+	// Whether the ZK handler reads cluster metadata through the Kafka Admin
+	// API rather than ZooKeeper (e.g. a kraft.Handler). Reassignments are
+	// inferred from partition replica sets and can't be submitted or
+	// cancelled, and znodes can't be written.
+	KRaftMode bool
+	// The metrics handler used to fetch broker network utilization.
+	Metrics kafkametrics.Handler
+	// The event writer. If nil, events are only logged.
+	Events EventWriter
+	// Submit the applied throttle rates, broker replication headroom and
+	// interval duration as metrics each interval. Requires a Metrics handler
+	// implementing kafkametrics.MetricsPoster.
+	SubmitMetrics bool
+	// Tags applied to submitted metrics.
+	MetricsTags []string
+	// Favor native Kafka RPCs over ZooKeeper metadata access.
+	KafkaNativeMode bool
+	// The KafkaAdmin client config, used in KafkaNativeMode.
+	KafkaAdmin kafkaadmin.Config
+	// The Kafka API request timeout in seconds.
+	KafkaAPIRequestTimeout int
+	// The ZooKeeper prefix where autothrottle configuration is stored.
+	ConfigZKPrefix string
+	// Optional Kafka topic that autothrottle state (throttle overrides, pins,
+	// the pause state, etc.) is stored in rather than beneath the
+	// ConfigZKPrefix in ZooKeeper. The topic is created as a compacted topic if
+	// it doesn't exist. Requires KafkaNativeMode.
+	StateTopic string
+	// Optional etcd or Consul store that autothrottle state is held in rather
+	// than ZooKeeper.
+	StateStore StateStoreConfig
+	// Optional Kafka topic that each throttle decision (the broker, role, old
+	// and new rates, and the reason) is written to as a JSON message. The
+	// topic is created if it doesn't exist. Requires KafkaNativeMode.
+	DecisionTopic string
+	// Optional compacted Kafka topic that the pause state and throttle
+	// overrides are declared in, read at each interval. Overrides are set
+	// under a "control-topic" requester so that they don't clobber overrides
+	// set through the admin API. The topic is created if it doesn't exist.
+	// Requires KafkaNativeMode.
+	ControlTopic string
+	// Optional ACL applied to the autothrottle config znodes. The ZK handler
+	// must be authenticated with an identity granted by the ACL.
+	ConfigZnodeACL []kafkazk.ACL
+	// Optional admin API listen address:port, or a Unix domain socket path
+	// prefixed with unix://. The admin API is disabled if unset.
+	APIListen string
+	// Optional admin gRPC API listen address:port or unix:// socket path.
+	// Requires APIListen.
+	GRPCListen string
+	// The file mode of admin API Unix domain sockets. Defaults to
+	// api.DefaultSocketMode.
+	APISocketMode os.FileMode
+	// Serve the pprof and expvar debug endpoints beneath /debug on the admin
+	// API. Requires APIListen.
+	APIDebug bool
+	// Admin API requests per second allowed per client address, with bursts
+	// of up to APIRateLimitBurst requests. Unlimited if 0.
+	APIRateLimit      float64
+	APIRateLimitBurst int
+	// How long the throttle rates and measured network utilization of each
+	// interval are retained, served by the admin API /history endpoint.
+	// History is disabled if 0.
+	HistoryRetention time.Duration
+	// Optional file that each interval's history is appended to as a JSON line
+	// and loaded from at startup, so that history survives restarts. Requires a
+	// HistoryRetention.
+	HistoryFile string
+	// The check interval.
+	Interval time.Duration
+	// The maximum random delay added to the start of each interval. Disabled
+	// if 0.
+	IntervalJitter time.Duration
+	// Align interval starts to multiples of the Interval on the wall clock.
+	AlignInterval bool
+	// The timeout for each ZooKeeper read and metrics request made at the start
+	// of an interval. Defaults to half the Interval if unset.
+	FetchTimeout time.Duration
+	// The maximum age of the previous metrics observations that the metrics of
+	// reassigning brokers missing from a fetch are interpolated from. Brokers
+	// missing from the metrics fail the throttle update if 0.
+	MetricsInterpolationMaxAge time.Duration
+	// The number of consecutive intervals ZooKeeper can be unreachable before
+	// a critical event is written. Disabled if 0.
+	ZKUnreachableIntervals int
+	// Replication throttle rate limits.
+	Limits LimitsConfig
+	// The required change in replication throttle to trigger an update (percent).
+	ChangeThreshold float64
+	// The number of iterations that throttle determinations can fail before
+	// reverting to the min-rate.
+	FailureThreshold int
+	// The number of intervals after which to issue a global throttle unset if no
+	// replication is running. The period is measured in time from the most
+	// recent unset, which is stored in ZooKeeper, so that it survives restarts.
+	CleanupAfter int64
+	// Skip automatic throttle removal.
+	SkipAutoDeleteThrottles bool
+	// Log and write an event listing the broker and topic throttle configs
+	// that automatic throttle removal would delete, without deleting them.
+	CleanupDryRun bool
+	// The maximum time to defer throttle removal after a reassignment completes
+	// until every moved replica has joined the partition ISR. Verification is
+	// disabled if 0.
+	ISRSyncGracePeriod time.Duration
+	// The period after creation that the replica catch-up of new topics is
+	// throttled. Topics created after startup with replicas not in the ISR are
+	// throttled as if they were being reassigned. Disabled if 0.
+	NewTopicCatchUp time.Duration
+	// The interval at which broker registrations are polled. When a
+	// reassignment destination broker is lost, the throttles of brokers
+	// replicating to it are reduced to the minimum rates without waiting for
+	// the next interval. Disabled if 0.
+	BrokerWatchInterval time.Duration
+	// Pause autothrottle when a reassignment destination broker is lost.
+	// Requires a BrokerWatchInterval.
+	PauseOnBrokerLoss bool
+	// The number of intervals between scans of all topic configs for
+	// throttled replicas left behind by finished reassignments, including
+	// those throttled by other tools. Disabled if 0.
+	OrphanScanIntervals int
+	// Remove orphaned topic throttled replicas found by the scan.
+	RemoveOrphanedThrottles bool
+	// Adopt replication throttles found at startup that autothrottle has no
+	// record of, rather than removing them.
+	AdoptExisting bool
+	// Cluster health guardrails.
+	Guardrails GuardrailsConfig
+	// Advisory lock shared with other throttle managers.
+	ManagerLock ManagerLockConfig
+	// Cruise Control integration.
+	CruiseControl CruiseControlConfig
+	// The number of times throttle config writes are verified and retried on a
+	// mismatch. Verification is disabled if 0.
+	VerifyAttempts int
+	// Set topic throttled replicas lists to "*" rather than enumerating
+	// reassigning replicas.
+	WildcardThrottledReplicas bool
+	// Skip recording broker and topic config values ahead of autothrottle's
+	// first write to them.
+	SkipConfigSnapshot bool
+	// Kubernetes operator mode.
+	Kubernetes KubernetesConfig
+	// Observe-only mode. Reassignment state, broker metrics and configured
+	// throttles are published as Prometheus metrics and events, but throttles
+	// are never computed or applied.
+	ObserveOnly bool
+	// Optional Prometheus metrics listen address:port, served in observe-only
+	// mode at /metrics.
+	MetricsListen string
+	// Interval tracing.
+	Tracing TracingConfig
+}
+
+// ManagerLockConfig holds throttle manager lock configurations. When enabled,
+// autothrottle holds a lease in the /throttle-manager-lock znode, renewed each
+// interval, while writing throttles. While another throttle manager (e.g. a
+// Cruise Control integration) holds an unexpired lease, no throttle changes
+// are applied and a critical event is written instead, so that the managers
+// don't overwrite each other's throttles.
+type ManagerLockConfig struct {
+	Enabled bool
+	// The name the lease is held under. Defaults to "autothrottle".
+	Owner string
+	// How long the lease is held without renewal. Must be greater than the
+	// Interval; defaults to three intervals.
+	TTL time.Duration
+}
+
+// StateStoreConfig holds the configuration of an etcd or Consul store that
+// autothrottle state is held in rather than ZooKeeper. State is keyed by the
+// path it would have had in ZooKeeper, beneath the KeyPrefix.
+type StateStoreConfig struct {
+	// The etcd client URLs, e.g. http://etcd-0:2379.
+	EtcdEndpoints []string
+	// The Consul HTTP API URL, e.g. http://localhost:8500, and optional ACL
+	// token.
+	ConsulAddress string
+	ConsulToken   string
+	// The key prefix that state is stored beneath.
+	KeyPrefix string
+}
+
+// TracingConfig holds interval tracing configurations. Each interval is
+// recorded as a trace, with spans for the ZooKeeper reads, metrics requests,
+// throttle computation and throttle config writes, and exported with the
+// OpenTelemetry protocol (OTLP) over HTTP.
+type TracingConfig struct {
+	// The OTLP/HTTP endpoint, e.g. http://localhost:4318. Tracing is disabled
+	// if unset.
+	OTLPEndpoint string
+	// Optional headers sent with each export.
+	OTLPHeaders map[string]string
+}
+
+// LimitsConfig holds replication throttle rate limits.
+type LimitsConfig struct {
+	// The minimum replication throttle rate in MB/s.
+	MinRate float64
+	// The minimum outbound and inbound replication throttle rates in MB/s.
+	// Default to the MinRate if unset.
+	SourceMinRate      float64
+	DestinationMinRate float64
+	// Map of instance types to minimum replication throttle rates in MB/s,
+	// taking precedence over the MinRate, SourceMinRate and DestinationMinRate.
+	MinRateMap map[string]float64
+	// The maximum outbound and inbound replication throttle rates as a
+	// percentage of available capacity.
+	SourceMaxRate      float64
+	DestinationMaxRate float64
+	// The minimum replication throttle rate in MB/s for each partition a
+	// broker is actively moving, up to the broker's maximum rate. Disabled if
+	// unset.
+	PartitionMinRate float64
+	// The maximum outbound and inbound replication throttle rates in MB/s. The
+	// lower of these and the percentage maximums applies. Disabled if unset.
+	SourceMaxRateAbs      float64
+	DestinationMaxRateAbs float64
+	// The maximum outbound and inbound replication throttle rates for brokers
+	// exclusively handling replication factor increases. Default to the
+	// SourceMaxRate and DestinationMaxRate if unset.
+	RFIncreaseSourceMaxRate float64
+	RFIncreaseDestMaxRate   float64
+	// The maximum outbound and inbound replication throttle rates for brokers
+	// replicating to or from brokers in a different rack (availability zone).
+	// Cross-AZ limits are disabled if unset.
+	CrossAZSourceMaxRate float64
+	CrossAZDestMaxRate   float64
+	// The maximum total replication throttle rate in MB/s across all
+	// reassigning brokers, per direction. Disabled if unset.
+	ClusterMaxRate float64
+	// Allocate the ClusterMaxRate among concurrent reassignments weighted by
+	// topic priority, rather than proportionally to broker headroom.
+	FairShare bool
+	// Map of topic priority classes (high, normal, low) to topic name regex.
+	// Topics in the high and low classes are weighted higher and lower than
+	// normal priority topics in fair-share allocations.
+	TopicClasses map[string]string
+	// Map of instance types to network capacity in MB/s.
+	CapacityMap CapacityMap
+	// The network capacity in MB/s used for brokers with an instance type
+	// missing from the CapacityMap. If unset, throttles can't be determined
+	// for such brokers.
+	DefaultCapacity float64
+	// The trailing window over which the peak network throughput of each
+	// broker is tracked. If set, broker capacities are the greater of the
+	// observed peaks and the CapacityMap.
+	CalibrationWindow time.Duration
+}
+
+// GuardrailsConfig holds cluster health guardrail configurations. When
+// tripped, throttles are dropped to the minimum rate.
+type GuardrailsConfig struct {
+	Enabled bool
+	// Max under-replicated partitions outside of ongoing reassignments.
+	MaxUnderReplicated int
+	// Max offline partitions.
+	MaxOffline int
+	// Max partitions with ISR shrinks per interval.
+	MaxISRShrinks int
+}
+
+// logEvents is an EventWriter that only logs events.
+type logEvents struct{}
+
+func (logEvents) Write(t string, m string)         { log.Printf("[event] %s: %s\n", t, m) }
+func (logEvents) WriteCritical(t string, m string) { log.Printf("[event] %s: %s\n", t, m) }
+func (logEvents) WriteTagged(t string, m string, _ ...string) {
+	log.Printf("[event] %s: %s\n", t, m)
+}
+
+// Run takes a Config and runs autothrottle until the context is cancelled.
+// An error is returned if autothrottle fails to initialize.
+func Run(ctx context.Context, cfg Config) error {
+	switch {
+	case cfg.ZK == nil:
+		return ErrNoZK
+	case cfg.Metrics == nil:
+		return ErrNoMetrics
+	case cfg.Interval <= 0:
+		return ErrInvalidInterval
+	case cfg.IntervalJitter < 0 || cfg.IntervalJitter >= cfg.Interval:
+		return ErrInvalidIntervalJitter
+	case cfg.Kubernetes.ConfigMap != "" && cfg.APIListen != "":
+		return ErrKubernetesWithAPI
+	case cfg.ObserveOnly && (cfg.APIListen != "" || cfg.Kubernetes.ConfigMap != ""):
+		return ErrObserveOnlyConflict
+	case cfg.Limits.FairShare && cfg.Limits.ClusterMaxRate <= 0:
+		return ErrFairShareWithoutBudget
+	case len(cfg.Limits.TopicClasses) > 0 && !cfg.Limits.FairShare:
+		return ErrTopicClassesWithoutFairShare
+	case cfg.APIDebug && cfg.APIListen == "":
+		return ErrDebugWithoutAPI
+	case cfg.APIRateLimit < 0, cfg.APIRateLimitBurst < 0:
+		return ErrInvalidAPIRateLimit
+	case cfg.StateTopic != "" && !cfg.KafkaNativeMode:
+		return ErrStateTopicWithoutKafkaNative
+	case cfg.StateTopic != "" && len(cfg.ConfigZnodeACL) > 0:
+		return ErrStateTopicWithACL
+	case cfg.StateTopic != "" && cfg.StateStore.backends() > 0, cfg.StateStore.backends() > 1:
+		return ErrMultipleStateStores
+	case cfg.StateStore.backends() > 0 && len(cfg.ConfigZnodeACL) > 0:
+		return ErrStateStoreWithACL
+	case cfg.DecisionTopic != "" && !cfg.KafkaNativeMode:
+		return ErrDecisionTopicWithoutKafkaNative
+	case cfg.ControlTopic != "" && !cfg.KafkaNativeMode:
+		return ErrControlTopicWithoutKafkaNative
+	case cfg.ControlTopic != "" && cfg.Kubernetes.ConfigMap != "":
+		return ErrControlTopicWithKubernetes
+	case cfg.PauseOnBrokerLoss && cfg.BrokerWatchInterval <= 0:
+		return ErrPauseOnBrokerLossWithoutWatch
+	case cfg.RemoveOrphanedThrottles && cfg.OrphanScanIntervals <= 0:
+		return ErrOrphanRemovalWithoutScan
+	case cfg.HistoryFile != "" && cfg.HistoryRetention <= 0:
+		return ErrHistoryFileWithoutRetention
+	case cfg.SubmitMetrics && !canPostMetrics(cfg.Metrics):
+		return ErrMetricsSubmissionUnsupported
+	case cfg.ManagerLock.Enabled && cfg.ManagerLock.TTL != 0 && cfg.ManagerLock.TTL <= cfg.Interval:
+		return ErrInvalidManagerLockTTL
+	case cfg.CruiseControl.Mode != "" && cfg.CruiseControl.Mode != CruiseControlDefer && cfg.CruiseControl.Mode != CruiseControlManage:
+		return ErrInvalidCruiseControlMode
+	case cfg.KRaftMode && !cfg.KafkaNativeMode:
+		return ErrKRaftModeWithoutKafkaNative
+	case cfg.KRaftMode && cfg.StateTopic == "" && cfg.StateStore.backends() == 0:
+		return ErrKRaftModeWithoutStateStore
+	case cfg.KRaftMode && cfg.ManagerLock.Enabled:
+		return ErrManagerLockInKRaftMode
+	}
+
+	if cfg.ManagerLock.Owner == "" {
+		cfg.ManagerLock.Owner = "autothrottle"
+	}
+
+	if cfg.ManagerLock.TTL == 0 {
+		cfg.ManagerLock.TTL = 3 * cfg.Interval
+	}
+
+	if cfg.CruiseControl.Mode == "" {
+		cfg.CruiseControl.Mode = CruiseControlDefer
+	}
+
+	topicClasses, err := replication.NewTopicClasses(cfg.Limits.TopicClasses)
+	if err != nil {
+		return err
+	}
+
+	// Hold autothrottle state in a Kafka topic, etcd or Consul rather than
+	// ZooKeeper.
+	stateLog, stateDesc, err := newStateLog(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if stateLog != nil {
+		defer stateLog.Close()
+
+		store, err := kafkastate.NewStore(ctx, cfg.ZK, "/"+cfg.ConfigZKPrefix, stateLog)
+		if err != nil {
+			return err
+		}
+
+		cfg.ZK = store
+
+		log.Printf("Storing autothrottle state in %s\n", stateDesc)
+	}
+
+	zk := cfg.ZK
+
+	if cfg.Tracing.OTLPEndpoint != "" {
+		exporter, err := tracing.NewExporter(tracing.ExporterConfig{
+			Endpoint:    cfg.Tracing.OTLPEndpoint,
+			Headers:     cfg.Tracing.OTLPHeaders,
+			ServiceName: "autothrottle",
+		})
+		if err != nil {
+			return err
+		}
+
+		tracing.SetExporter(exporter)
+		defer tracing.SetExporter(nil)
+
+		log.Printf("Exporting interval traces to %s\n", cfg.Tracing.OTLPEndpoint)
+	}
+
+	if cfg.FetchTimeout <= 0 {
+		cfg.FetchTimeout = cfg.Interval / 2
+	}
+
+	events := cfg.Events
+	if events == nil {
+		events = logEvents{}
+	}
+
+	// Without ZooKeeper, reassignments are inferred from partition replica
+	// sets; see kraft.InferReassignments.
+	if cfg.KRaftMode {
+		log.Println("WARNING: running without ZooKeeper: reassignments are inferred from partition replica sets." +
+			" Replication factor increases and reassignments moving every partition of a topic (including all" +
+			" single-partition topics) aren't detected and won't be throttled. Submitting and cancelling" +
+			" reassignments and reassignment plans is unsupported.")
+	}
+
+	if cfg.ObserveOnly {
+		return runObserver(ctx, cfg, events)
+	}
+
+	trigger := make(chan struct{}, 1)
+	adminAPI := api.New(zk, cfg.ConfigZKPrefix, trigger)
+	if cfg.KRaftMode {
+		adminAPI.DisableReassignmentWrites()
+	}
+
+	// Replication state metrics are served at /metrics on the admin API.
+	registry := prometheus.NewRegistry()
+
+	// Init the admin API.
+	if cfg.APIListen != "" {
+		adminAPI.Init(&api.APIConfig{
+			Listen:         cfg.APIListen,
+			GRPCListen:     cfg.GRPCListen,
+			SocketMode:     cfg.APISocketMode,
+			ZnodeACL:       cfg.ConfigZnodeACL,
+			Debug:          cfg.APIDebug,
+			Metrics:        registry,
+			RateLimit:      cfg.APIRateLimit,
+			RateLimitBurst: cfg.APIRateLimitBurst,
+		})
+
+		log.Printf("Admin API: %s\n", cfg.APIListen)
+		if cfg.GRPCListen != "" {
+			log.Printf("Admin gRPC API: %s\n", cfg.GRPCListen)
+		}
+	} else if err := api.InitZnodes(zk, adminAPI.Paths, cfg.ConfigZnodeACL); err != nil {
+		return err
+	}
+
+	// Params for the updateReplicationThrottle request.
+
+	limitsCfg := replication.NewLimitsConfig{
+		Minimum:                      cfg.Limits.MinRate,
+		SourceMinimum:                cfg.Limits.SourceMinRate,
+		DestinationMinimum:           cfg.Limits.DestinationMinRate,
+		MinimumMap:                   cfg.Limits.MinRateMap,
+		PartitionMinimum:             cfg.Limits.PartitionMinRate,
+		SourceMaximum:                cfg.Limits.SourceMaxRate,
+		DestinationMaximum:           cfg.Limits.DestinationMaxRate,
+		SourceMaximumAbsolute:        cfg.Limits.SourceMaxRateAbs,
+		DestinationMaximumAbsolute:   cfg.Limits.DestinationMaxRateAbs,
+		RFIncreaseSourceMaximum:      cfg.Limits.RFIncreaseSourceMaxRate,
+		RFIncreaseDestinationMaximum: cfg.Limits.RFIncreaseDestMaxRate,
+		CrossAZSourceMaximum:         cfg.Limits.CrossAZSourceMaxRate,
+		CrossAZDestinationMaximum:    cfg.Limits.CrossAZDestMaxRate,
+		ClusterMaximum:               cfg.Limits.ClusterMaxRate,
+		CapacityMap:                  cfg.Limits.CapacityMap.toReplication(),
+		DefaultCapacity:              cfg.Limits.DefaultCapacity,
+	}
+
+	lim, err := replication.NewLimits(limitsCfg)
+	if err != nil {
+		return err
+	}
+
+	adminAPI.SetLimits(lim)
+
+	// Init Kubernetes operator mode.
+	var op *operator
+	if cfg.Kubernetes.ConfigMap != "" {
+		client, err := k8s.NewInClusterClient()
+		if err != nil {
+			return err
+		}
+
+		op, err = newOperator(client, cfg.Kubernetes.ConfigMap, zk, adminAPI, lim, cfg.Limits.CapacityMap.toReplication())
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Kubernetes operator mode: ConfigMap %s/%s\n", op.namespace, op.name)
+	}
+
+	tmCfg := replication.ThrottleManagerConfig{
+		Limits:                     lim,
+		FailureThreshold:           cfg.FailureThreshold,
+		ChangeThreshold:            cfg.ChangeThreshold,
+		KafkaZK:                    zk,
+		KafkaMetrics:               cfg.Metrics,
+		KafkaNativeMode:            cfg.KafkaNativeMode,
+		KafkaAPIRequestTimeout:     cfg.KafkaAPIRequestTimeout,
+		Events:                     events,
+		VerifyAttempts:             cfg.VerifyAttempts,
+		WildcardThrottledReplicas:  cfg.WildcardThrottledReplicas,
+		MetricsTimeout:             cfg.FetchTimeout,
+		MetricsInterpolationMaxAge: cfg.MetricsInterpolationMaxAge,
+		FairShare:                  cfg.Limits.FairShare,
+		TopicClasses:               topicClasses,
+		CalibrationWindow:          cfg.Limits.CalibrationWindow,
+		ZnodePaths:                 adminAPI.Paths,
+		Guardrails: replication.GuardrailsConfig{
+			Enabled:            cfg.Guardrails.Enabled,
+			MaxUnderReplicated: cfg.Guardrails.MaxUnderReplicated,
+			MaxOffline:         cfg.Guardrails.MaxOffline,
+			MaxISRShrinks:      cfg.Guardrails.MaxISRShrinks,
+		},
+	}
+
+	if !cfg.SkipConfigSnapshot {
+		tmCfg.ConfigSnapshotPath = adminAPI.Paths.ConfigSnapshot
+	}
+
+	// Write throttle decisions to a Kafka topic.
+	if cfg.DecisionTopic != "" {
+		decisions, err := decisionlog.NewKafkaWriter(ctx, decisionlog.KafkaWriterConfig{
+			Topic: cfg.DecisionTopic,
+			Admin: cfg.KafkaAdmin,
+		})
+		if err != nil {
+			return err
+		}
+		defer decisions.Close()
+
+		tmCfg.Decisions = decisions
+
+		log.Printf("Writing throttle decisions to Kafka topic %s\n", cfg.DecisionTopic)
+	}
+
+	throttleManager, err := replication.NewThrottleManager(tmCfg)
+	if err != nil {
+		return err
+	}
+
+	// Init the reassignment plan orchestrator. Plans can't be submitted
+	// without ZooKeeper.
+	var orch *orchestrator.Orchestrator
+	if !cfg.KRaftMode {
+		orch = orchestrator.NewOrchestrator(orchestrator.Config{
+			ZK:            zk,
+			PlanZnodePath: adminAPI.Paths.ReassignmentPlan,
+			Events:        events,
+		})
+	}
+
+	// Init a KafkaAdmin Client if needed.
+	if cfg.KafkaNativeMode {
+		if err := throttleManager.InitKafkaAdmin(cfg.KafkaAdmin); err != nil {
+			return err
+		}
+		log.Printf("Connected to Kafka: %s\n", cfg.KafkaAdmin.BootstrapServers)
+	}
+
+	c := newController(cfg, throttleManager, orch, adminAPI, events, op)
+	c.registry = registry
+
+	// Acquire the throttle manager lock ahead of any throttle writes.
+	if cfg.ManagerLock.Enabled {
+		c.lock = &managerLock{
+			zk:    zk,
+			owner: cfg.ManagerLock.Owner,
+			ttl:   cfg.ManagerLock.TTL,
+			now:   time.Now,
+		}
+		c.checkManagerLock()
+
+		defer func() {
+			if err := c.lock.release(); err != nil {
+				log.Printf("Error releasing the throttle manager lock: %s\n", err)
+			}
+		}()
+	}
+
+	// Check for a Cruise Control execution ahead of any throttle writes.
+	if cfg.CruiseControl.URL != "" {
+		c.cruiseControl = cruisecontrol.NewClient(cruisecontrol.Config{URL: cfg.CruiseControl.URL})
+		c.cruiseControlMode = cfg.CruiseControl.Mode
+		c.checkCruiseControl(ctx)
+	}
+
+	// Reconcile any throttles set prior to startup, e.g. by a previous
+	// autothrottle process or manually.
+	c.knownThrottles = reconcileExistingThrottles(cfg, throttleManager, adminAPI, events, c.suspendedReason())
+
+	// Read the pause state and throttle overrides declared in a Kafka topic.
+	if cfg.ControlTopic != "" {
+		controlLog, err := kafkastate.NewKafkaLog(ctx, kafkastate.KafkaLogConfig{
+			Topic: cfg.ControlTopic,
+			Admin: cfg.KafkaAdmin,
+		})
+		if err != nil {
+			return err
+		}
+		defer controlLog.Close()
+
+		c.control = &controlTopic{log: controlLog, zk: zk, paths: adminAPI.Paths}
+
+		log.Printf("Reading commands from Kafka control topic %s\n", cfg.ControlTopic)
+	}
+
+	// Retain the history of each interval, sized to hold an entry per
+	// Interval over the retention.
+	if cfg.HistoryRetention > 0 {
+		h, err := history.New(history.Config{
+			Size: int((cfg.HistoryRetention + cfg.Interval - 1) / cfg.Interval),
+			File: cfg.HistoryFile,
+		})
+		if err != nil {
+			return err
+		}
+		defer h.Close()
+
+		c.history = h
+		adminAPI.SetHistory(h)
+
+		if cfg.HistoryFile != "" {
+			log.Printf("Writing interval history to %s\n", cfg.HistoryFile)
+		}
+	}
+
+	// Watch for lost brokers between intervals.
+	var brokerChanges <-chan brokerChange
+	if cfg.BrokerWatchInterval > 0 {
+		brokerChanges = watchBrokers(ctx, zk, cfg.BrokerWatchInterval)
+	}
+
+	// Run.
+	timer := newIntervalTimer(cfg.Interval, cfg.IntervalJitter, cfg.AlignInterval)
+	defer timer.stop()
+
+	for {
+		// Failed intervals are retried at the next tick.
+		if err := c.tick(ctx); err != nil {
+			log.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C():
+			timer.reset()
+		case <-trigger:
+		case change := <-brokerChanges:
+			c.brokersChanged(change)
+		}
+	}
+}
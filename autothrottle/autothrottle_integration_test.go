@@ -0,0 +1,215 @@
+//go:build integration
+
+package autothrottle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// The integration test runs against the docker compose environment; see the
+// integration-test Makefile target.
+var (
+	integrationZKAddr           = "zookeeper:2181"
+	integrationBootstrapServers = "kafka:9094"
+	integrationConfigPrefix     = "autothrottle_integration_test"
+	integrationTopic            = "autothrottle_integration_test"
+)
+
+// TestRunReassignment runs autothrottle against a live cluster, submits a
+// reassignment moving a partition from broker 1001 to 1002 and checks that
+// throttles are applied while it runs and removed once it completes.
+func TestRunReassignment(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	zk, err := kafkazk.NewHandler(&kafkazk.Config{Connect: integrationZKAddr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zk.Close()
+
+	ka, err := kafkaadmin.NewClient(kafkaadmin.Config{BootstrapServers: integrationBootstrapServers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ka.Close()
+
+	// Populate a single replica topic on broker 1001. The partition holds
+	// enough data that, throttled, the reassignment spans several intervals.
+	err = ka.CreateTopic(ctx, kafkaadmin.CreateTopicConfig{
+		Name:              integrationTopic,
+		ReplicaAssignment: kafkaadmin.ReplicaAssignment{{1001}},
+	})
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		t.Fatal(err)
+	}
+	defer ka.DeleteTopic(context.Background(), integrationTopic)
+
+	produceIntegrationData(t, 40, 256*1024)
+
+	// Set a global override so that the applied rate is deterministic, and
+	// removed once the reassignment completes.
+	paths := api.NewZnodePaths(integrationConfigPrefix)
+	if err := api.InitZnodes(zk, paths, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	override := throttlestore.ThrottleOverrideConfig{Rate: 2, AutoRemove: true}
+	if err := throttlestore.StoreThrottleOverride(zk, paths.OverrideRate, override); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run autothrottle.
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, Config{
+			ZK:             zk,
+			Metrics:        kafkametrics.NewStub(),
+			ConfigZKPrefix: integrationConfigPrefix,
+			Interval:       time.Second,
+			Limits: LimitsConfig{
+				MinRate:            1,
+				SourceMaxRate:      90,
+				DestinationMaxRate: 90,
+				CapacityMap:        CapacityMap{"stub": {TX: 200, RX: 200}},
+			},
+			ChangeThreshold:  10,
+			FailureThreshold: 1,
+			CleanupAfter:     60,
+		})
+	}()
+
+	// Let autothrottle reconcile any throttles found at startup, then throttle
+	// the reassignment ahead of its first interval so that it doesn't complete
+	// before autothrottle sees it.
+	time.Sleep(2 * time.Second)
+
+	err = ka.SetThrottle(ctx, kafkaadmin.SetThrottleConfig{
+		Topics: []string{integrationTopic},
+		Brokers: map[int]kafkaadmin.BrokerThrottleConfig{
+			1001: {OutboundLimitBytes: 1000000},
+			1002: {InboundLimitBytes: 1000000},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pm := mapper.NewPartitionMap()
+	pm.Partitions = mapper.PartitionList{{Topic: integrationTopic, Partition: 0, Replicas: []int{1002}}}
+	if err := zk.SubmitReassignment(pm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Throttles are set to the override rate: outbound on the source broker and
+	// inbound on the destination broker.
+	waitForThrottles(t, ka, 30*time.Second, map[string]map[string]string{
+		"1001": {"leader.replication.throttled.rate": "2000000"},
+		"1002": {"follower.replication.throttled.rate": "2000000"},
+	})
+
+	// Throttles are removed once the reassignment completes.
+	waitFor(t, 60*time.Second, "reassignment completion", func() bool {
+		r, err := zk.ListReassignments()
+		return err == nil && len(r) == 0
+	})
+
+	waitForThrottles(t, ka, 30*time.Second, map[string]map[string]string{
+		"1001": {"leader.replication.throttled.rate": "", "follower.replication.throttled.rate": ""},
+		"1002": {"leader.replication.throttled.rate": "", "follower.replication.throttled.rate": ""},
+	})
+
+	waitFor(t, 10*time.Second, "topic throttled replicas removal", func() bool {
+		configs, err := ka.GetDynamicConfigs(ctx, "topic", []string{integrationTopic})
+		if err != nil {
+			return false
+		}
+		c := configs[integrationTopic]
+		return c["leader.replication.throttled.replicas"] == "" && c["follower.replication.throttled.replicas"] == ""
+	})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+// produceIntegrationData writes n messages of size bytes to the integration
+// test topic.
+func produceIntegrationData(t *testing.T, n, size int) {
+	p, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": integrationBootstrapServers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	topic := integrationTopic
+	value := make([]byte, size)
+
+	for i := 0; i < n; i++ {
+		err := p.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+			Value:          value,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if remaining := p.Flush(30000); remaining > 0 {
+		t.Fatalf("%d messages not delivered", remaining)
+	}
+}
+
+// waitForThrottles waits until the throttle configs of each broker match the
+// expected values, where an empty value means unset.
+func waitForThrottles(t *testing.T, ka kafkaadmin.KafkaAdmin, timeout time.Duration, expected map[string]map[string]string) {
+	var ids []string
+	for id := range expected {
+		ids = append(ids, id)
+	}
+
+	waitFor(t, timeout, fmt.Sprintf("broker throttles %v", expected), func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		configs, err := ka.GetDynamicConfigs(ctx, "broker", ids)
+		if err != nil {
+			return false
+		}
+
+		for id, kvs := range expected {
+			for k, v := range kvs {
+				if configs[id][k] != v {
+					return false
+				}
+			}
+		}
+
+		return true
+	})
+}
+
+// waitFor polls fn until it returns true, failing the test after timeout.
+func waitFor(t *testing.T, timeout time.Duration, desc string, fn func() bool) {
+	deadline := time.Now().Add(timeout)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", desc)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -0,0 +1,82 @@
+package autothrottle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestRunInvalidConfig(t *testing.T) {
+	ctx := context.Background()
+	zk := kafkazk.NewZooKeeperStub()
+
+	tests := []struct {
+		cfg      Config
+		expected error
+	}{
+		{Config{}, ErrNoZK},
+		{Config{ZK: zk}, ErrNoMetrics},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub()}, ErrInvalidInterval},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, IntervalJitter: time.Second}, ErrInvalidIntervalJitter},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, APIListen: "localhost:8080", Kubernetes: KubernetesConfig{ConfigMap: "kafka/autothrottle"}}, ErrKubernetesWithAPI},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, APIListen: "localhost:8080", ObserveOnly: true}, ErrObserveOnlyConflict},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, Limits: LimitsConfig{FairShare: true}}, ErrFairShareWithoutBudget},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, Limits: LimitsConfig{TopicClasses: map[string]string{"high": "^orders$"}}}, ErrTopicClassesWithoutFairShare},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, APIDebug: true}, ErrDebugWithoutAPI},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, APIRateLimit: -1}, ErrInvalidAPIRateLimit},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, StateTopic: "autothrottle-state"}, ErrStateTopicWithoutKafkaNative},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, DecisionTopic: "autothrottle-decisions"}, ErrDecisionTopicWithoutKafkaNative},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, ControlTopic: "autothrottle-control"}, ErrControlTopicWithoutKafkaNative},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, ControlTopic: "autothrottle-control", KafkaNativeMode: true, Kubernetes: KubernetesConfig{ConfigMap: "kafka/autothrottle"}}, ErrControlTopicWithKubernetes},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, PauseOnBrokerLoss: true}, ErrPauseOnBrokerLossWithoutWatch},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, RemoveOrphanedThrottles: true}, ErrOrphanRemovalWithoutScan},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, HistoryFile: "/var/lib/autothrottle/history"}, ErrHistoryFileWithoutRetention},
+		{Config{ZK: zk, Metrics: struct{ kafkametrics.Handler }{kafkametrics.NewStub()}, Interval: time.Second, SubmitMetrics: true}, ErrMetricsSubmissionUnsupported},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Minute, ManagerLock: ManagerLockConfig{Enabled: true, TTL: time.Minute}}, ErrInvalidManagerLockTTL},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, CruiseControl: CruiseControlConfig{URL: "http://cruise-control:9090/kafkacruisecontrol", Mode: "override"}}, ErrInvalidCruiseControlMode},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, StateTopic: "autothrottle-state", KafkaNativeMode: true, ConfigZnodeACL: kafkazk.WorldACL(kafkazk.PermAll)}, ErrStateTopicWithACL},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, StateTopic: "autothrottle-state", KafkaNativeMode: true, StateStore: StateStoreConfig{ConsulAddress: "http://localhost:8500"}}, ErrMultipleStateStores},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, StateStore: StateStoreConfig{EtcdEndpoints: []string{"http://localhost:2379"}, ConsulAddress: "http://localhost:8500"}}, ErrMultipleStateStores},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, StateStore: StateStoreConfig{EtcdEndpoints: []string{"http://localhost:2379"}}, ConfigZnodeACL: kafkazk.WorldACL(kafkazk.PermAll)}, ErrStateStoreWithACL},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, KRaftMode: true}, ErrKRaftModeWithoutKafkaNative},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, KRaftMode: true, KafkaNativeMode: true}, ErrKRaftModeWithoutStateStore},
+		{Config{ZK: zk, Metrics: kafkametrics.NewStub(), Interval: time.Second, KRaftMode: true, KafkaNativeMode: true, StateTopic: "autothrottle-state", ManagerLock: ManagerLockConfig{Enabled: true}}, ErrManagerLockInKRaftMode},
+	}
+
+	for i, test := range tests {
+		if err := Run(ctx, test.cfg); err != test.expected {
+			t.Errorf("[test %d] Expected error %v, got %v", i, test.expected, err)
+		}
+	}
+}
+
+func TestRunContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, Config{
+			ZK:             kafkazk.NewZooKeeperStub(),
+			Metrics:        kafkametrics.NewStub(),
+			ConfigZKPrefix: "autothrottle",
+			Interval:       time.Hour,
+			Limits:         LimitsConfig{MinRate: 10, SourceMaxRate: 90, DestinationMaxRate: 90},
+			// Throttles are retained so that the stub is left unmodified.
+			SkipAutoDeleteThrottles: true,
+		})
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil error, got %s", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run didn't return after the context was cancelled")
+	}
+}
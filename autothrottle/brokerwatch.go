@@ -0,0 +1,165 @@
+package autothrottle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+// brokerChange describes a change in the registered brokers.
+type brokerChange struct {
+	// Brokers that were unregistered since the previous change.
+	lost []int
+	// Previously lost brokers that were registered again.
+	registered []int
+	// All brokers that are currently lost.
+	allLost []int
+}
+
+// brokerWatcher tracks broker registrations across polls.
+type brokerWatcher struct {
+	// Brokers seen registered at any point.
+	known map[int]struct{}
+	// Brokers currently lost.
+	lost map[int]struct{}
+}
+
+func newBrokerWatcher() *brokerWatcher {
+	return &brokerWatcher{
+		known: map[int]struct{}{},
+		lost:  map[int]struct{}{},
+	}
+}
+
+// observe takes the currently registered brokers and returns the change
+// relative to the previous call, along with whether anything changed. The
+// first call establishes the baseline and never reports a change.
+func (w *brokerWatcher) observe(bmm mapper.BrokerMetaMap) (brokerChange, bool) {
+	var change brokerChange
+
+	for id := range w.known {
+		_, registered := bmm[id]
+		_, lost := w.lost[id]
+
+		switch {
+		case !registered && !lost:
+			w.lost[id] = struct{}{}
+			change.lost = append(change.lost, id)
+		case registered && lost:
+			delete(w.lost, id)
+			change.registered = append(change.registered, id)
+		}
+	}
+
+	for id := range bmm {
+		w.known[id] = struct{}{}
+	}
+
+	for id := range w.lost {
+		change.allLost = append(change.allLost, id)
+	}
+
+	sort.Ints(change.lost)
+	sort.Ints(change.registered)
+	sort.Ints(change.allLost)
+
+	return change, len(change.lost) > 0 || len(change.registered) > 0
+}
+
+// watchBrokers polls the broker registrations at the interval and sends any
+// changes on the returned channel until the context is cancelled. Failed polls
+// are logged and don't affect the tracked registrations.
+func watchBrokers(ctx context.Context, zk kafkazk.Handler, interval time.Duration) <-chan brokerChange {
+	changes := make(chan brokerChange)
+	w := newBrokerWatcher()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			bmm, errs := zk.GetAllBrokerMeta(false)
+			if len(errs) > 0 {
+				log.Printf("Error fetching broker registrations: %v\n", errs)
+			} else if change, changed := w.observe(bmm); changed {
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return changes
+}
+
+// brokersChanged handles a change in the registered brokers. If a
+// reassignment destination was lost, the throttles of brokers replicating to
+// it are reduced immediately and a critical event is written. The reassignment
+// is also paused if configured.
+func (c *controller) brokersChanged(change brokerChange) {
+	c.tm.SetLostBrokers(change.allLost)
+
+	if len(change.registered) > 0 {
+		m := fmt.Sprintf("Previously lost brokers registered again: %v", change.registered)
+		log.Println(m)
+		c.events.Write("Brokers registered", m)
+	}
+
+	if len(change.lost) == 0 {
+		return
+	}
+
+	log.Printf("Brokers no longer registered: %v\n", change.lost)
+
+	lost := map[int]struct{}{}
+	for _, id := range change.lost {
+		lost[id] = struct{}{}
+	}
+
+	var destinations []int
+	for _, id := range c.tm.LostDestinations() {
+		if _, ok := lost[id]; ok {
+			destinations = append(destinations, id)
+		}
+	}
+
+	if len(destinations) == 0 {
+		return
+	}
+
+	m := fmt.Sprintf("Reassignment destination brokers %v are no longer registered", destinations)
+
+	reduced, err := c.tm.ReduceLostDestinationThrottles()
+	switch {
+	case err != nil:
+		m = fmt.Sprintf("%s; error reducing throttles: %s", m, err)
+	case len(reduced) > 0:
+		m = fmt.Sprintf("%s; throttles reduced to the minimum rates for brokers %v", m, reduced)
+	}
+
+	if c.pauseOnBrokerLoss && !c.paused {
+		pc := throttlestore.PauseConfig{Paused: true, Since: c.now().Unix()}
+		if err := throttlestore.StorePauseConfig(c.zk, c.api.Paths.Pause, pc); err != nil {
+			m = fmt.Sprintf("%s; error pausing autothrottle: %s", m, err)
+		} else {
+			m = fmt.Sprintf("%s; autothrottle paused", m)
+		}
+	}
+
+	log.Println(m)
+	c.events.WriteCritical("Reassignment destination broker lost", m)
+}
@@ -0,0 +1,104 @@
+package autothrottle
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+func brokerMeta(ids ...int) mapper.BrokerMetaMap {
+	bmm := mapper.BrokerMetaMap{}
+	for _, id := range ids {
+		bmm[id] = &mapper.BrokerMeta{}
+	}
+
+	return bmm
+}
+
+func TestBrokerWatcherObserve(t *testing.T) {
+	w := newBrokerWatcher()
+
+	tests := []struct {
+		registered mapper.BrokerMetaMap
+		expected   string
+		changed    bool
+	}{
+		// The baseline.
+		{brokerMeta(1001, 1002, 1003), "{[] [] []}", false},
+		{brokerMeta(1001, 1002, 1003, 1004), "{[] [] []}", false},
+		{brokerMeta(1001, 1004), "{[1002 1003] [] [1002 1003]}", true},
+		{brokerMeta(1001, 1004), "{[] [] [1002 1003]}", false},
+		{brokerMeta(1001, 1002), "{[1004] [1002] [1003 1004]}", true},
+	}
+
+	for i, test := range tests {
+		change, changed := w.observe(test.registered)
+		if got := fmt.Sprint(change); got != test.expected {
+			t.Errorf("[test %d] Expected change %s, got %s", i, test.expected, got)
+		}
+		if changed != test.changed {
+			t.Errorf("[test %d] Expected changed %v, got %v", i, test.changed, changed)
+		}
+	}
+}
+
+func TestControllerBrokersChanged(t *testing.T) {
+	tc := newTestController(t, Config{PauseOnBrokerLoss: true})
+
+	tc.tickAfter(t, 0, "test1")
+
+	// Losing a broker that isn't a reassignment destination is only logged.
+	tc.events.reset()
+	tc.brokersChanged(brokerChange{lost: []int{1007}, allLost: []int{1007}})
+
+	if len(tc.events.titles) != 0 {
+		t.Errorf("Expected no events, got %v", tc.events.titles)
+	}
+
+	dst := tc.tm.ReassigningBrokerIDs()
+	if len(dst) == 0 {
+		t.Fatal("Expected reassigning brokers")
+	}
+
+	lost := append([]int{1007}, dst...)
+	tc.brokersChanged(brokerChange{lost: lost[1:], allLost: lost})
+
+	if len(tc.tm.LostDestinations()) == 0 {
+		t.Fatal("Expected lost reassignment destinations")
+	}
+
+	if !tc.events.has("Reassignment destination broker lost") {
+		t.Errorf("Expected a broker lost event, got %v", tc.events.titles)
+	}
+
+	pc, err := throttlestore.FetchPauseConfig(tc.zk, tc.api.Paths.Pause)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pc.Paused || pc.Since != tc.clock.Unix() {
+		t.Errorf("Expected autothrottle to be paused, got %+v", pc)
+	}
+
+	// The pause is applied in the next interval.
+	tc.tickAfter(t, time.Minute, "test1")
+
+	if !tc.events.has("Autothrottle paused") {
+		t.Errorf("Expected a paused event, got %v", tc.events.titles)
+	}
+
+	// Registered brokers are no longer considered lost.
+	tc.events.reset()
+	tc.brokersChanged(brokerChange{registered: lost})
+
+	if !tc.events.has("Brokers registered") {
+		t.Errorf("Expected a brokers registered event, got %v", tc.events.titles)
+	}
+
+	if d := tc.tm.LostDestinations(); len(d) != 0 {
+		t.Errorf("Expected no lost destinations, got %v", d)
+	}
+}
@@ -0,0 +1,55 @@
+package autothrottle
+
+import (
+	"encoding/json"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+)
+
+// Capacity is the network capacity of an instance type in MB/s, outbound (TX)
+// and inbound (RX). In JSON, it's either a number for symmetric capacities or
+// an object with distinct capacities, e.g. {"tx": 220, "rx": 180}.
+type Capacity struct {
+	TX float64 `json:"tx"`
+	RX float64 `json:"rx"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Capacity) UnmarshalJSON(b []byte) error {
+	var rc replication.Capacity
+	if err := json.Unmarshal(b, &rc); err != nil {
+		return err
+	}
+
+	*c = Capacity(rc)
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. Symmetric capacities
+// are written as a single number.
+func (c Capacity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(replication.Capacity(c))
+}
+
+// CapacityMap is a map of instance type to Capacity.
+type CapacityMap map[string]Capacity
+
+// Validate returns an error if any capacity isn't > 0.
+func (m CapacityMap) Validate() error {
+	return m.toReplication().Validate()
+}
+
+// toReplication returns the CapacityMap as a replication.CapacityMap.
+func (m CapacityMap) toReplication() replication.CapacityMap {
+	if m == nil {
+		return nil
+	}
+
+	rm := make(replication.CapacityMap, len(m))
+	for k, c := range m {
+		rm[k] = replication.Capacity(c)
+	}
+
+	return rm
+}
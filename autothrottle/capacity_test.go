@@ -0,0 +1,32 @@
+package autothrottle
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCapacityMap(t *testing.T) {
+	var m CapacityMap
+	if err := json.Unmarshal([]byte(`{"a": 100, "b": {"tx": 220, "rx": 180}}`), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	rm := m.toReplication()
+
+	if rm["a"].TX != 100 || rm["a"].RX != 100 || rm["b"].TX != 220 || rm["b"].RX != 180 {
+		t.Errorf("Unexpected capacities %v", rm)
+	}
+
+	if err := m.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	m["c"] = Capacity{TX: 100}
+	if err := m.Validate(); err == nil {
+		t.Error("Expected a validation error")
+	}
+
+	if CapacityMap(nil).toReplication() != nil {
+		t.Error("Expected a nil map")
+	}
+}
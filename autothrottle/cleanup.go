@@ -0,0 +1,99 @@
+package autothrottle
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+// cleanupDue returns whether the cleanup period has elapsed since the most
+// recent global throttle unset. The time of the most recent unset is loaded
+// from ZooKeeper in the first interval so that cleanup timing survives
+// restarts; if no unset was recorded, the period starts now. If the state
+// can't be read, cleanup isn't due and the read is retried in the next
+// interval.
+func (c *controller) cleanupDue() bool {
+	now := c.now()
+
+	if c.lastCleanup.IsZero() {
+		state, err := throttlestore.FetchCleanupState(c.zk, c.api.Paths.Cleanup)
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+
+		if state.Last == 0 {
+			c.resetCleanup()
+		} else {
+			c.lastCleanup = time.Unix(state.Last, 0)
+		}
+	}
+
+	return now.Sub(c.lastCleanup) >= c.cleanupPeriod
+}
+
+// resetCleanup starts a new cleanup period and stores its start time.
+func (c *controller) resetCleanup() {
+	c.lastCleanup = c.now()
+
+	state := throttlestore.CleanupState{Last: c.lastCleanup.Unix()}
+	if err := throttlestore.StoreCleanupState(c.zk, c.api.Paths.Cleanup, state); err != nil {
+		log.Println(err)
+	}
+}
+
+// maxRemovalEventConfigs is the number of configs listed in throttle removal
+// events; the full list is logged.
+const maxRemovalEventConfigs = 50
+
+// removeAllThrottles logs and writes an event listing the broker and topic
+// throttle configs to be deleted, then removes all throttles.
+func (c *controller) removeAllThrottles() error {
+	if err := c.reportThrottleRemoval(false); err != nil {
+		return err
+	}
+
+	return c.tm.RemoveAllThrottles()
+}
+
+// reportThrottleRemoval logs and writes an event listing the broker and topic
+// throttle configs that removing all throttles deletes, or would delete in a
+// dry run. Nothing is written if no throttle configs are set.
+func (c *controller) reportThrottleRemoval(dryRun bool) error {
+	plan, err := c.tm.PlanRemoveAllThrottles()
+	if err != nil {
+		return fmt.Errorf("error listing throttle configs to remove: %s", err)
+	}
+
+	if plan.Empty() {
+		log.Println("No throttle configs set to remove")
+		return nil
+	}
+
+	title, verb := "Removing replication throttle configs", "will be"
+	if dryRun {
+		title, verb = "Replication throttle removal dry run", "would be"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d throttle configs %s removed:", len(plan.Configs), verb)
+
+	for n, r := range plan.Configs {
+		log.Printf("Throttle config %s removed: %s\n", verb, r)
+
+		if n < maxRemovalEventConfigs {
+			fmt.Fprintf(&b, "\n- %s", r)
+		}
+	}
+
+	if n := len(plan.Configs) - maxRemovalEventConfigs; n > 0 {
+		fmt.Fprintf(&b, "\n- and %d more (see the autothrottle log)", n)
+	}
+
+	c.events.Write(title, b.String())
+
+	return nil
+}
@@ -0,0 +1,255 @@
+// Package client provides a typed Go client for the autothrottle admin API,
+// allowing other services to manage throttle overrides and query autothrottle
+// state programmatically. It uses the autothrottle gRPC API, which must be
+// enabled with the autothrottle -grpc-listen flag.
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "github.com/DataDog/kafka-kit/v4/proto/autothrottlepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	// ErrNoAddr is returned when a Config has no Addr.
+	ErrNoAddr = errors.New("an autothrottle gRPC address must be specified")
+	// ErrInvalidRate is returned when an Override rate is <= 0.
+	ErrInvalidRate = errors.New("override rate must be > 0")
+	// ErrInvalidTTL is returned when an Override TTL is < 0.
+	ErrInvalidTTL = errors.New("override TTL must be >= 0")
+)
+
+// Config holds Client configurations.
+type Config struct {
+	// The autothrottle gRPC API address:port.
+	Addr string
+	// Options used when dialing Addr. If unset, an insecure connection is used.
+	DialOptions []grpc.DialOption
+	// Optional name that the Client's throttle overrides are set and removed
+	// under. Overrides of different requesters don't clobber each other; the
+	// lowest rate override is in effect.
+	Requester string
+}
+
+// Client is an autothrottle admin API client.
+type Client struct {
+	conn *grpc.ClientConn
+	c    pb.AutothrottleClient
+}
+
+// Override is a throttle override.
+type Override struct {
+	// The override rate in MB/s.
+	Rate int
+	// Whether the override is removed once no reassignments are running.
+	AutoRemove bool
+	// How long the override is in effect. If 0, the override doesn't expire.
+	TTL time.Duration
+	// Apply the override even if the rate exceeds the known network capacity
+	// of the brokers it applies to.
+	Force bool
+}
+
+// Throttle is a configured throttle override.
+type Throttle struct {
+	// The broker ID for a broker-specific override; nil for the global override.
+	BrokerID *int
+	// The override rate in MB/s; 0 if no override is set.
+	Rate       int
+	AutoRemove bool
+	// The override expiry; zero if the override doesn't expire.
+	Expires time.Time
+	// Whether the broker is participating in an ongoing reassignment.
+	Reassigning bool
+}
+
+// New takes a Config and returns a *Client.
+func New(cfg Config) (*Client, error) {
+	if cfg.Addr == "" {
+		return nil, ErrNoAddr
+	}
+
+	opts := append([]grpc.DialOption{}, cfg.DialOptions...)
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	if cfg.Requester != "" {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(requesterInterceptor(cfg.Requester)))
+	}
+
+	conn, err := grpc.Dial(cfg.Addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn, c: pb.NewAutothrottleClient(conn)}, nil
+}
+
+// requesterInterceptor returns a grpc.UnaryClientInterceptor that names the
+// requester in the metadata of each request.
+func requesterInterceptor(requester string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, "requester", requester)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// Close closes the Client connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// GetThrottle returns the global throttle override.
+func (c *Client) GetThrottle(ctx context.Context) (Throttle, error) {
+	return c.getThrottle(ctx, nil)
+}
+
+// GetBrokerThrottle returns the throttle override for the broker ID.
+func (c *Client) GetBrokerThrottle(ctx context.Context, id int) (Throttle, error) {
+	return c.getThrottle(ctx, brokerID(id))
+}
+
+// SetThrottle sets the global throttle override.
+func (c *Client) SetThrottle(ctx context.Context, o Override) (Throttle, error) {
+	return c.setThrottle(ctx, nil, o)
+}
+
+// SetBrokerThrottle sets the throttle override for the broker ID.
+func (c *Client) SetBrokerThrottle(ctx context.Context, id int, o Override) (Throttle, error) {
+	return c.setThrottle(ctx, brokerID(id), o)
+}
+
+// RemoveThrottle removes the global throttle override.
+func (c *Client) RemoveThrottle(ctx context.Context) error {
+	_, err := c.c.RemoveThrottle(ctx, &pb.ThrottleRequest{})
+	return err
+}
+
+// RemoveBrokerThrottle removes the throttle override for the broker ID.
+func (c *Client) RemoveBrokerThrottle(ctx context.Context, id int) error {
+	_, err := c.c.RemoveThrottle(ctx, &pb.ThrottleRequest{BrokerId: brokerID(id)})
+	return err
+}
+
+// ListBrokerThrottles returns all broker-specific throttle overrides, sorted
+// by broker ID.
+func (c *Client) ListBrokerThrottles(ctx context.Context) ([]Throttle, error) {
+	resp, err := c.c.ListBrokerThrottles(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return throttlesFromPB(resp.Throttles), nil
+}
+
+// RemoveBrokerThrottles removes all broker-specific throttle overrides and
+// returns the overrides that were removed.
+func (c *Client) RemoveBrokerThrottles(ctx context.Context) ([]Throttle, error) {
+	resp, err := c.c.RemoveBrokerThrottles(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return throttlesFromPB(resp.Throttles), nil
+}
+
+// Status returns the autothrottle status as of the most recent interval.
+func (c *Client) Status(ctx context.Context) (*pb.StatusResponse, error) {
+	return c.c.GetStatus(ctx, &pb.Empty{})
+}
+
+// Capacity returns the configured throttle rate limits and instance-type
+// network capacities.
+func (c *Client) Capacity(ctx context.Context) (*pb.CapacityResponse, error) {
+	return c.c.GetCapacity(ctx, &pb.Empty{})
+}
+
+// Pause pauses autothrottle.
+func (c *Client) Pause(ctx context.Context) error {
+	_, err := c.c.Pause(ctx, &pb.Empty{})
+	return err
+}
+
+// Resume resumes a paused autothrottle.
+func (c *Client) Resume(ctx context.Context) error {
+	_, err := c.c.Resume(ctx, &pb.Empty{})
+	return err
+}
+
+func (c *Client) getThrottle(ctx context.Context, id *uint32) (Throttle, error) {
+	resp, err := c.c.GetThrottle(ctx, &pb.ThrottleRequest{BrokerId: id})
+	if err != nil {
+		return Throttle{}, err
+	}
+
+	return throttleFromPB(resp.Throttle), nil
+}
+
+func (c *Client) setThrottle(ctx context.Context, id *uint32, o Override) (Throttle, error) {
+	if o.Rate <= 0 {
+		return Throttle{}, ErrInvalidRate
+	}
+
+	if o.TTL < 0 {
+		return Throttle{}, ErrInvalidTTL
+	}
+
+	req := &pb.ThrottleRequest{
+		BrokerId:   id,
+		Rate:       uint32(o.Rate),
+		Autoremove: o.AutoRemove,
+		TtlSeconds: uint64(o.TTL / time.Second),
+	}
+
+	if o.Force {
+		ctx = metadata.AppendToOutgoingContext(ctx, "force", "true")
+	}
+
+	resp, err := c.c.SetThrottle(ctx, req)
+	if err != nil {
+		return Throttle{}, err
+	}
+
+	return throttleFromPB(resp.Throttle), nil
+}
+
+func brokerID(id int) *uint32 {
+	bid := uint32(id)
+	return &bid
+}
+
+// throttleFromPB takes a *pb.Throttle and returns a Throttle.
+func throttleFromPB(t *pb.Throttle) Throttle {
+	th := Throttle{
+		Rate:        int(t.GetRate()),
+		AutoRemove:  t.GetAutoremove(),
+		Reassigning: t.GetReassigning(),
+	}
+
+	if t.BrokerId != nil {
+		id := int(*t.BrokerId)
+		th.BrokerID = &id
+	}
+
+	if t.GetExpires() != 0 {
+		th.Expires = time.Unix(t.GetExpires(), 0)
+	}
+
+	return th
+}
+
+func throttlesFromPB(ts []*pb.Throttle) []Throttle {
+	var throttles []Throttle
+	for _, t := range ts {
+		throttles = append(throttles, throttleFromPB(t))
+	}
+
+	return throttles
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	pb "github.com/DataDog/kafka-kit/v4/proto/autothrottlepb"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func testClient(t *testing.T) *Client {
+	return testClients(t, "")[0]
+}
+
+// testClients returns a Client for each requester, all connected to the same
+// server.
+func testClients(t *testing.T, requesters ...string) []*Client {
+	// Overrides signal a trigger; drain it.
+	trigger := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-trigger:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	l := bufconn.Listen(1024 * 1024)
+	srvr := grpc.NewServer()
+	pb.RegisterAutothrottleServer(srvr, api.NewRPCServer(api.New(kafkazk.NewZooKeeperStub(), "autothrottle", trigger)))
+	go srvr.Serve(l)
+
+	var clients []*Client
+
+	for _, r := range requesters {
+		c, err := New(Config{
+			Addr: "bufconn",
+			DialOptions: []grpc.DialOption{
+				grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			},
+			Requester: r,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clients = append(clients, c)
+	}
+
+	t.Cleanup(func() {
+		for _, c := range clients {
+			c.Close()
+		}
+		srvr.Stop()
+		close(done)
+	})
+
+	return clients
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.Equal(t, ErrNoAddr, err)
+}
+
+func TestSetGetRemoveThrottle(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	th, err := c.SetThrottle(ctx, Override{Rate: 50, AutoRemove: true})
+	assert.Nil(t, err)
+	assert.Nil(t, th.BrokerID)
+	assert.Equal(t, 50, th.Rate)
+	assert.True(t, th.AutoRemove)
+	assert.True(t, th.Expires.IsZero())
+
+	th, err = c.GetThrottle(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, th.Rate)
+
+	assert.Nil(t, c.RemoveThrottle(ctx))
+
+	th, err = c.GetThrottle(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, th.Rate)
+}
+
+func TestSetThrottleInvalid(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	_, err := c.SetThrottle(ctx, Override{})
+	assert.Equal(t, ErrInvalidRate, err)
+
+	_, err = c.SetBrokerThrottle(ctx, 1001, Override{Rate: 10, TTL: -time.Second})
+	assert.Equal(t, ErrInvalidTTL, err)
+}
+
+func TestBrokerThrottles(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	th, err := c.SetBrokerThrottle(ctx, 1002, Override{Rate: 20, TTL: time.Hour})
+	assert.Nil(t, err)
+	assert.Equal(t, 1002, *th.BrokerID)
+	assert.True(t, th.Expires.After(time.Now()))
+
+	_, err = c.SetBrokerThrottle(ctx, 1001, Override{Rate: 10})
+	assert.Nil(t, err)
+
+	th, err = c.GetBrokerThrottle(ctx, 1001)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, th.Rate)
+
+	list, err := c.ListBrokerThrottles(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, 1001, *list[0].BrokerID)
+	assert.Equal(t, 1002, *list[1].BrokerID)
+
+	assert.Nil(t, c.RemoveBrokerThrottle(ctx, 1001))
+
+	removed, err := c.RemoveBrokerThrottles(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, removed, 1)
+	assert.Equal(t, 1002, *removed[0].BrokerID)
+
+	list, err = c.ListBrokerThrottles(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestRequesters(t *testing.T) {
+	clients := testClients(t, "ci", "operator")
+	ci, operator := clients[0], clients[1]
+	ctx := context.Background()
+
+	_, err := ci.SetThrottle(ctx, Override{Rate: 100})
+	assert.Nil(t, err)
+	_, err = operator.SetThrottle(ctx, Override{Rate: 50})
+	assert.Nil(t, err)
+
+	// The lowest rate is in effect.
+	th, err := ci.GetThrottle(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, th.Rate)
+
+	// Removing an override leaves the other requester's in place.
+	assert.Nil(t, operator.RemoveThrottle(ctx))
+
+	th, err = ci.GetThrottle(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, th.Rate)
+
+	assert.Nil(t, ci.RemoveThrottle(ctx))
+
+	th, err = ci.GetThrottle(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, th.Rate)
+}
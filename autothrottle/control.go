@@ -0,0 +1,242 @@
+package autothrottle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// Control topic record keys. Broker override keys are suffixed with the
+// broker ID, e.g. override/1001.
+const (
+	controlKeyPause          = "pause"
+	controlKeyOverride       = "override"
+	controlKeyBrokerOverride = "override/"
+)
+
+// controlRequester is the requester that control topic overrides are set
+// under, so that they don't clobber overrides set through the admin API.
+const controlRequester = "control-topic"
+
+// controlTopicTimeout bounds each read of the control topic.
+var controlTopicTimeout = 30 * time.Second
+
+// controlLog reads the latest value of each key of the control topic.
+type controlLog interface {
+	Load(context.Context) (map[string][]byte, error)
+}
+
+// controlPause is the value of a control topic pause record.
+type controlPause struct {
+	Paused bool `json:"paused"`
+}
+
+// controlOverride is the value of a control topic override record.
+type controlOverride struct {
+	// Rate in MB/s.
+	Rate int `json:"rate"`
+}
+
+// controlTopic syncs the desired state declared in a compacted Kafka topic
+// into the autothrottle state store. Overrides are declared by the latest
+// record of the override and override/<broker ID> keys and removed when the
+// key is deleted. The pause state is set by the latest pause record, if any.
+type controlTopic struct {
+	log   controlLog
+	zk    kafkazk.Handler
+	paths api.ZnodePaths
+}
+
+// sync reads the control topic and applies the desired state it declares.
+// Invalid records are logged and skipped; on a failure to read the topic, the
+// previously applied state remains in effect.
+func (ct *controlTopic) sync(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, controlTopicTimeout)
+	defer cancel()
+
+	records, err := ct.log.Load(ctx)
+	if err != nil {
+		log.Printf("Error reading the control topic: %s\n", err)
+		return
+	}
+
+	if err := ct.apply(records); err != nil {
+		log.Printf("Error applying the control topic: %s\n", err)
+	}
+}
+
+// apply stores the pause state and throttle overrides declared by the records.
+// Only state that differs from the records is written.
+func (ct *controlTopic) apply(records map[string][]byte) error {
+	var override *controlOverride
+	brokerOverrides := map[int]controlOverride{}
+
+	// Keys are applied in order for deterministic logging.
+	var keys []string
+	for k := range records {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := records[k]
+
+		switch {
+		case k == controlKeyPause:
+			var p controlPause
+			if err := json.Unmarshal(v, &p); err != nil {
+				log.Printf("Ignoring invalid control topic record %s: %s\n", k, err)
+				continue
+			}
+
+			if err := ct.applyPause(p); err != nil {
+				return err
+			}
+		case k == controlKeyOverride:
+			o, err := parseControlOverride(v)
+			if err != nil {
+				log.Printf("Ignoring invalid control topic record %s: %s\n", k, err)
+				continue
+			}
+			override = &o
+		case strings.HasPrefix(k, controlKeyBrokerOverride):
+			id, err := strconv.Atoi(strings.TrimPrefix(k, controlKeyBrokerOverride))
+			if err != nil || id < 0 {
+				log.Printf("Ignoring invalid control topic record %s: invalid broker ID\n", k)
+				continue
+			}
+
+			o, err := parseControlOverride(v)
+			if err != nil {
+				log.Printf("Ignoring invalid control topic record %s: %s\n", k, err)
+				continue
+			}
+			brokerOverrides[id] = o
+		default:
+			log.Printf("Ignoring unknown control topic record %s\n", k)
+		}
+	}
+
+	// Global throttle override.
+	var rate int
+	if override != nil {
+		rate = override.Rate
+	}
+
+	if err := ct.applyOverride(ct.paths.OverrideRate, "global throttle override", rate); err != nil {
+		return err
+	}
+
+	// Broker-specific throttle overrides.
+	bo, err := throttlestore.FetchBrokerOverrides(ct.zk, ct.paths.OverrideRate)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for id := range brokerOverrides {
+		ids = append(ids, id)
+	}
+
+	// Overrides no longer declared are removed.
+	for id, current := range bo {
+		if _, declared := brokerOverrides[id]; declared {
+			continue
+		}
+		if _, exists := current.Config.RequesterOverrides()[controlRequester]; exists {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		path := fmt.Sprintf("%s/%d", ct.paths.OverrideRate, id)
+		name := fmt.Sprintf("throttle override for broker %d", id)
+		if err := ct.applyOverride(path, name, brokerOverrides[id].Rate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyPause stores the pause state if it differs from p.
+func (ct *controlTopic) applyPause(p controlPause) error {
+	pauseCfg, err := throttlestore.FetchPauseConfig(ct.zk, ct.paths.Pause)
+	if err != nil {
+		return err
+	}
+
+	if pauseCfg.Paused == p.Paused {
+		return nil
+	}
+
+	c := throttlestore.PauseConfig{Paused: p.Paused}
+	if p.Paused {
+		c.Since = time.Now().Unix()
+	}
+
+	if err := throttlestore.StorePauseConfig(ct.zk, ct.paths.Pause, c); err != nil {
+		return err
+	}
+
+	log.Printf("Control topic pause state set to %t\n", p.Paused)
+
+	return nil
+}
+
+// applyOverride sets the control topic requester override at path p, described
+// by name in logs, to the rate, or removes it if the rate is 0, if it differs
+// from the stored override.
+func (ct *controlTopic) applyOverride(p, name string, rate int) error {
+	current := &throttlestore.ThrottleOverrideConfig{}
+
+	if exists, _ := ct.zk.Exists(p); exists {
+		var err error
+		if current, err = throttlestore.FetchThrottleOverride(ct.zk, p); err != nil {
+			return err
+		}
+	}
+
+	if current.RequesterOverrides()[controlRequester].Rate == rate {
+		return nil
+	}
+
+	c := throttlestore.ThrottleOverrideConfig{Rate: rate}
+	if _, err := throttlestore.SetRequesterOverride(ct.zk, p, controlRequester, c); err != nil {
+		return err
+	}
+
+	if rate == 0 {
+		log.Printf("Control topic %s removed\n", name)
+	} else {
+		log.Printf("Control topic %s set to %dMB/s\n", name, rate)
+	}
+
+	return nil
+}
+
+// parseControlOverride unmarshals and validates an override record value. A
+// rate of 0 removes the override, as does deleting the key.
+func parseControlOverride(v []byte) (controlOverride, error) {
+	var o controlOverride
+	if err := json.Unmarshal(v, &o); err != nil {
+		return o, err
+	}
+
+	if o.Rate < 0 {
+		return o, fmt.Errorf("rate must be >= 0")
+	}
+
+	return o, nil
+}
@@ -0,0 +1,101 @@
+package autothrottle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// controlLogStub is a controlLog that serves a fixed set of records.
+type controlLogStub struct {
+	records map[string][]byte
+	err     error
+}
+
+func (l *controlLogStub) Load(context.Context) (map[string][]byte, error) {
+	return l.records, l.err
+}
+
+func newTestControlTopic(t *testing.T, l controlLog) (*controlTopic, kafkazk.Handler) {
+	zk := kafkazk.NewZooKeeperStub()
+	paths := api.NewZnodePaths("autothrottle")
+	if err := api.InitZnodes(zk, paths, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return &controlTopic{log: l, zk: zk, paths: paths}, zk
+}
+
+func TestControlTopicSync(t *testing.T) {
+	l := &controlLogStub{records: map[string][]byte{
+		"pause":         []byte(`{"paused": true}`),
+		"override":      []byte(`{"rate": 50}`),
+		"override/1001": []byte(`{"rate": 20}`),
+		"override/1002": []byte(`{"rate": -1}`),
+		"override/x":    []byte(`{"rate": 10}`),
+	}}
+
+	ct, zk := newTestControlTopic(t, l)
+
+	// An override set through the admin API.
+	path := ct.paths.OverrideRate + "/1003"
+	if _, err := throttlestore.SetRequesterOverride(zk, path, "", throttlestore.ThrottleOverrideConfig{Rate: 40}); err != nil {
+		t.Fatal(err)
+	}
+
+	ct.sync(context.Background())
+
+	pause, _ := throttlestore.FetchPauseConfig(zk, ct.paths.Pause)
+	if !pause.Paused {
+		t.Error("Expected paused")
+	}
+
+	override, _ := throttlestore.FetchThrottleOverride(zk, ct.paths.OverrideRate)
+	if override.Rate != 50 || override.Requesters[controlRequester].Rate != 50 {
+		t.Errorf("Expected global control override rate 50, got %+v", override)
+	}
+
+	// Invalid records are skipped.
+	bo, _ := throttlestore.FetchBrokerOverrides(zk, ct.paths.OverrideRate)
+	if len(bo) != 2 || bo[1001].Config.Rate != 20 || bo[1003].Config.Rate != 40 {
+		t.Errorf("Unexpected broker overrides %v", bo)
+	}
+
+	// Deleted keys remove the control topic overrides only.
+	l.records = map[string][]byte{"override/1003": []byte(`{"rate": 30}`)}
+	ct.sync(context.Background())
+
+	// The pause state is retained without a pause record.
+	pause, _ = throttlestore.FetchPauseConfig(zk, ct.paths.Pause)
+	if !pause.Paused {
+		t.Error("Expected paused")
+	}
+
+	override, _ = throttlestore.FetchThrottleOverride(zk, ct.paths.OverrideRate)
+	if override.Rate != 0 {
+		t.Errorf("Expected global override rate 0, got %d", override.Rate)
+	}
+
+	bo, _ = throttlestore.FetchBrokerOverrides(zk, ct.paths.OverrideRate)
+	if bo[1001].Config.Rate != 0 {
+		t.Errorf("Expected broker 1001 override marked for removal, got %+v", bo[1001].Config)
+	}
+
+	// The lowest requester override is in effect.
+	if c := bo[1003].Config; c.Rate != 30 || c.RequesterOverrides()[""].Rate != 40 {
+		t.Errorf("Unexpected broker 1003 override %+v", c)
+	}
+
+	// A failed read leaves the previously applied state in place.
+	l.err = errors.New("unavailable")
+	ct.sync(context.Background())
+
+	bo, _ = throttlestore.FetchBrokerOverrides(zk, ct.paths.OverrideRate)
+	if bo[1003].Config.Rate != 30 {
+		t.Errorf("Expected broker 1003 override rate 30, got %d", bo[1003].Config.Rate)
+	}
+}
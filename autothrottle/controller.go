@@ -0,0 +1,766 @@
+package autothrottle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/history"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/orchestrator"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/prometheus"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/tracing"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// controller runs autothrottle intervals. Each call to tick observes ongoing
+// reassignments and applies or removes throttles accordingly; state carried
+// across intervals is held in the controller.
+type controller struct {
+	zk     kafkazk.Handler
+	tm     *replication.ThrottleManager
+	orch   *orchestrator.Orchestrator
+	events EventWriter
+	// The admin API that runtime state is reported to.
+	api *api.API
+	// Optional Kubernetes operator.
+	op *operator
+	// Optional control topic.
+	control *controlTopic
+	// Optional history of each interval's rates.
+	history *history.History
+	// Optional throttle manager lock, whether it was held as of the most
+	// recent interval and, if not, the owner of the lease.
+	lock       *managerLock
+	lockHeld   bool
+	lockHolder string
+	// Optional Cruise Control executor state source, the integration mode and
+	// the execution in progress as of the most recent interval.
+	cruiseControl          executorStateGetter
+	cruiseControlMode      string
+	cruiseControlExecution string
+	// Returns all ongoing reassignments.
+	getReassignments func() (kafkazk.Reassignments, error)
+	// Returns the current time.
+	now func() time.Time
+	// The timeout for each read made at the start of an interval.
+	fetchTimeout time.Duration
+	// The number of consecutive intervals ZooKeeper can be unreachable before
+	// a critical event is written, and the current count.
+	zkUnreachableIntervals int
+	zkUnreachable          int
+
+	// The period after which to issue a global throttle unset if no
+	// replication is running, and the start of the current period.
+	cleanupPeriod time.Duration
+	lastCleanup   time.Time
+	// Skip automatic throttle removal.
+	skipAutoDeleteThrottles bool
+	// Report the throttle configs that automatic removal would delete rather
+	// than deleting them.
+	cleanupDryRun bool
+	// Completed reassignments awaiting ISR sync before throttle removal.
+	isrSync *isrSyncTracker
+	// Pause autothrottle when a reassignment destination broker is lost.
+	pauseOnBrokerLoss bool
+	// New topics whose replica catch-up is throttled.
+	newTopics *newTopicTracker
+	// The number of intervals between orphaned topic throttle scans, the
+	// count since the last scan and the topics found.
+	orphanScanIntervals int
+	orphanScanCount     int
+	orphanedTopics      []string
+	// Remove orphaned topic throttles found by the scan.
+	removeOrphanedThrottles bool
+
+	// Whether autothrottle was paused as of the previous interval.
+	paused bool
+	// Whether any throttles may be set.
+	knownThrottles bool
+	// Topic replication states across intervals.
+	topicsReplicatingPreviously set
+	sessions                    *reassignmentSessions
+	// Replication state transitions, published to the optional registry.
+	transitions *replicationTransitions
+	registry    *prometheus.Registry
+	// Optional metrics submission and the tags applied to submitted metrics.
+	metrics     kafkametrics.MetricsPoster
+	metricsTags []string
+}
+
+// newController takes a Config and the initialized dependencies and returns a
+// *controller.
+func newController(cfg Config, tm *replication.ThrottleManager, orch *orchestrator.Orchestrator, a *api.API, events EventWriter, op *operator) *controller {
+	c := &controller{
+		zk:     cfg.ZK,
+		tm:     tm,
+		orch:   orch,
+		api:    a,
+		events: events,
+		op:     op,
+		getReassignments: func() (kafkazk.Reassignments, error) {
+			return getReassignments(cfg.ZK, cfg.KafkaNativeMode)
+		},
+		now:                         time.Now,
+		fetchTimeout:                cfg.FetchTimeout,
+		zkUnreachableIntervals:      cfg.ZKUnreachableIntervals,
+		cleanupPeriod:               time.Duration(cfg.CleanupAfter) * cfg.Interval,
+		skipAutoDeleteThrottles:     cfg.SkipAutoDeleteThrottles,
+		cleanupDryRun:               cfg.CleanupDryRun,
+		isrSync:                     newISRSyncTracker(cfg.ISRSyncGracePeriod, cfg.ZK.GetTopicStateISR),
+		pauseOnBrokerLoss:           cfg.PauseOnBrokerLoss,
+		newTopics:                   newNewTopicTracker(cfg.NewTopicCatchUp, cfg.ZK),
+		orphanScanIntervals:         cfg.OrphanScanIntervals,
+		removeOrphanedThrottles:     cfg.RemoveOrphanedThrottles,
+		topicsReplicatingPreviously: newSet(),
+		sessions:                    newReassignmentSessions(),
+		transitions:                 newReplicationTransitions(),
+	}
+
+	if p, ok := cfg.Metrics.(kafkametrics.MetricsPoster); ok && cfg.SubmitMetrics {
+		c.metrics = p
+		c.metricsTags = cfg.MetricsTags
+	}
+
+	return c
+}
+
+// tick runs a single autothrottle interval. An error is returned if ongoing
+// reassignments couldn't be fetched, in which case no other action is taken.
+func (c *controller) tick(ctx context.Context) error {
+	zk, throttleManager, events := c.zk, c.tm, c.events
+	start := c.now()
+
+	// Each interval is a trace.
+	ctx, span := tracing.Start(ctx, "interval")
+	defer span.End()
+
+	throttleManager.SetTraceContext(ctx)
+
+	// Apply the desired state declared in the Kubernetes ConfigMap.
+	if c.op != nil {
+		c.op.sync(ctx)
+	}
+
+	// Apply the desired state declared in the control topic.
+	if c.control != nil {
+		c.control.sync(ctx)
+	}
+
+	// Get topics undergoing reassignment along with the stored autothrottle
+	// configs. If topics were reassigning in the previous interval, broker
+	// metrics are fetched concurrently in anticipation of a throttle update.
+	// Unused metrics are discarded at the end of the interval.
+	defer throttleManager.ClearPrefetchedMetrics()
+
+	state, err := c.fetchIntervalState(ctx, len(c.topicsReplicatingPreviously) > 0)
+	c.checkZKReachable(err)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	reassignments := state.reassignments
+
+	// Check whether autothrottle is paused. While paused, reassignments are
+	// still observed and reported but no throttle changes are written. If the
+	// pause config couldn't be read, the previous state is retained.
+	pauseCfg := state.pauseCfg
+	if state.pauseErr != nil {
+		log.Println(state.pauseErr)
+		pauseCfg.Paused = c.paused
+	}
+
+	if pauseCfg.Paused != c.paused {
+		c.paused = pauseCfg.Paused
+
+		if c.paused {
+			m := "Autothrottle paused; no throttle changes will be applied until resumed"
+			log.Println(m)
+			events.Write("Autothrottle paused", m)
+		} else {
+			m := "Autothrottle resumed"
+			log.Println(m)
+			events.Write("Autothrottle resumed", m)
+		}
+	} else if c.paused {
+		log.Println("Autothrottle is paused")
+	}
+
+	// Throttle changes are also suspended while another throttle manager holds
+	// the manager lock.
+	if c.lock != nil {
+		c.checkManagerLock()
+	}
+
+	// In defer mode, throttle changes are suspended while Cruise Control is
+	// executing.
+	if c.cruiseControl != nil {
+		c.checkCruiseControl(ctx)
+	}
+
+	paused := c.paused || c.suspendedReason() != ""
+	throttleManager.SetPaused(paused)
+
+	// Restore the config snapshot if requested through the admin API. This is
+	// an explicit operator action and is performed even while paused.
+	if state.restoreErr != nil {
+		log.Println(state.restoreErr)
+	}
+
+	if state.restore.Requested {
+		c.restoreConfigSnapshot()
+	}
+
+	// Advance any batched reassignment plan. If the next batch was submitted,
+	// refresh the reassignments so that it's throttled in this interval.
+	// Batches aren't submitted while paused, nor without an orchestrator.
+	var submitted bool
+	if !paused && c.orch != nil {
+		submitted, err = c.orch.Advance(reassignments)
+		if err != nil {
+			log.Printf("Error advancing reassignment plan: %s\n", err)
+		}
+	}
+
+	if submitted {
+		reassignments, err = c.getReassignments()
+		if err != nil {
+			err = fmt.Errorf("error fetching reassignments: %s", err)
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	// Throttle the replica catch-up of new topics as if they were reassigning.
+	// Topics with ongoing reassignments are left as they are.
+	var catchUp []string
+	for t, partitions := range c.newTopics.lagging(c.now()) {
+		if _, exists := reassignments[t]; exists {
+			continue
+		}
+		if reassignments == nil {
+			reassignments = kafkazk.Reassignments{}
+		}
+		reassignments[t] = partitions
+		catchUp = append(catchUp, t)
+	}
+
+	if len(catchUp) > 0 {
+		sort.Strings(catchUp)
+		log.Printf("New topics with replicas catching up: %s\n", catchUp)
+	}
+
+	topicsReplicatingNow := newSet()
+	for t := range reassignments {
+		topicsReplicatingNow.add(t)
+	}
+
+	span.SetAttributes(tracing.Int("reassigning_topics", len(topicsReplicatingNow)), tracing.Bool("paused", paused))
+
+	// Update the reassignment sessions. Topics that started reassigning in
+	// this interval are assigned a new session correlation ID. Topics that
+	// were previously seen replicating, but are no longer in this interval,
+	// are done.
+	su := c.sessions.update(topicsReplicatingNow, c.now())
+
+	su.write(events, c.now())
+
+	// Track completed reassignments until the moved replicas are in sync.
+	c.isrSync.update(reassignments, c.now())
+
+	// Topic throttled replicas lists are only written for topics with lists
+	// that changed since they were last written, minimizing state that must be
+	// propagated through the cluster. When new topics start replicating, all
+	// lists are written again in case they were set by other tools.
+	if !topicsReplicatingNow.isSubSet(c.topicsReplicatingPreviously) {
+		throttleManager.ResetTopicThrottles()
+		// Unset any previously stored throttle rates. This is done to avoid a
+		// scenario that results in autothrottle being unaware of externally
+		// specified throttles and failing to override them. The condition can be
+		// triggered when two subsequent reassignments involving the same broker
+		// set are handled by autothrottle. The error condition is as follows:
+		//
+		// - Autothrottle sees reassignment 1 involving brokers 1001, 1002
+		//   and determines a throttle rate of 100MB/s.
+		// - Reassignment 1 completes, reassignment 2 is started in-between
+		//   autothrottle intervals and a manual rate of 25MB/s is specified from
+		//   the reassignment tool.
+		// - Autothrottle sees reassignment 2, revisits throughput and determines
+		//   the rate for brokers 1001 and 1002 should be 105MB/s, below the
+		//   ChangeThreshold of 10% when compared to the last known rates set;
+		//   throttle updates are skipped.
+		// - The reassignment is now stuck at 25MB/s.
+		//
+		// There's two solutions considered to reconcile the stale state:
+		// - Reset all previously stored rates when the current reassigning
+		//   topic list is not a subset of the previous reassigning topic list.
+		// - Force throttle updates every so many intervals, regardless of the
+		//   required ChangeThreshold.
+		//
+		// Ensure we're doing option 1 right here:
+		throttleManager.ResetPreviousThrottles()
+	}
+
+	// Rebuild topicsReplicatingPreviously with the current replications
+	// for the next check iteration.
+	c.topicsReplicatingPreviously = topicsReplicatingNow.copy()
+
+	// Check if a global throttle override was configured.
+	overrideCfg := state.overrideCfg
+	if state.overrideErr != nil {
+		log.Println(state.overrideErr)
+	}
+
+	// Remove any global throttle overrides whose TTL has expired.
+	if overrideCfg.Expired() && !paused {
+		remaining, _ := overrideCfg.Remove(throttlestore.ThrottleOverrideConfig.Expired)
+		err := throttlestore.StoreThrottleOverride(zk, c.api.Paths.OverrideRate, remaining)
+		if err != nil {
+			log.Println(err)
+		} else {
+			m := fmt.Sprintf("Global throttle override of %dMB/s expired and was removed", overrideCfg.Rate)
+			if remaining.Rate != 0 {
+				m = fmt.Sprintf("Global throttle overrides expired and were removed; the override is now %dMB/s", remaining.Rate)
+			}
+			log.Println(m)
+			events.Write("Global throttle override expired", m)
+			overrideCfg = &remaining
+		}
+	}
+
+	// Global throttle overrides scheduled to start later aren't applied until
+	// they start.
+	activeOverride := overrideCfg.Active()
+
+	// Broker-specific overrides.
+	bo := state.brokerOverrides
+	if state.brokerOverridesErr != nil {
+		log.Println(state.brokerOverridesErr)
+	}
+
+	// Mark any broker-specific overrides with an expired TTL for removal.
+	var expired []int
+	if !paused {
+		expired, err = throttlestore.ExpireBrokerOverrides(zk, c.api.Paths.OverrideRate, bo)
+		if err != nil {
+			log.Println(err)
+		}
+
+		if len(expired) > 0 {
+			m := fmt.Sprintf("Broker throttle overrides expired and marked for removal: %v", expired)
+			log.Println(m)
+			events.Write("Broker throttle overrides expired", m)
+		}
+	}
+
+	// Broker-specific overrides scheduled to start later aren't applied until
+	// they start.
+	bo.RemovePending(expired)
+
+	// Pinned broker throttles. Pins take precedence over any broker-specific
+	// overrides.
+	pins := state.pins
+	if state.pinsErr != nil {
+		log.Println(state.pinsErr)
+	}
+
+	if bo == nil {
+		bo = throttlestore.BrokerOverrides{}
+	}
+
+	bo.ApplyPins(pins)
+
+	// Get the maps of brokers handling reassignments.
+	rb, err := replication.GetReassigningBrokers(reassignments, zk)
+	if err != nil {
+		log.Println(err)
+	}
+
+	// Topic priorities for fair-share allocation.
+	if state.prioritiesErr != nil {
+		log.Println(state.prioritiesErr)
+	}
+
+	throttleManager.SetBrokerOverrides(bo)
+	throttleManager.SetReassigningBrokers(rb)
+	throttleManager.SetReassignments(reassignments)
+	throttleManager.SetTopicPriorities(state.priorities)
+
+	// Clear the throttles of reassignments cancelled through the admin API.
+	// The record is retained until the throttles are removed.
+	if state.cancelledErr != nil {
+		log.Println(state.cancelledErr)
+	}
+
+	if cancelled := state.cancelled.Topics; len(cancelled) > 0 && !paused {
+		topics, brokers, err := throttleManager.ClearCancelledThrottles(cancelled)
+		if err != nil {
+			log.Printf("Error clearing throttles for cancelled reassignments: %s\n", err)
+		} else {
+			m := fmt.Sprintf("Throttles cleared for cancelled reassignments of topics %v (brokers: %v)", topics, brokers)
+			log.Println(m)
+			events.Write("Cancelled reassignment throttles cleared", m)
+
+			if err := throttlestore.RemoveCancelledReassignments(zk, c.api.Paths.Cancelled); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+
+	// Check the cluster health guardrails. If tripped, throttles for any
+	// reassigning brokers are set to the min-rate.
+	if status, err := throttleManager.CheckGuardrails(); err != nil {
+		log.Printf("Error checking cluster health guardrails: %s\n", err)
+	} else if status.Tripped {
+		log.Printf("Cluster health guardrails tripped: %s\n", strings.Join(status.Reasons, ", "))
+	}
+
+	// If topics are being reassigned, update the replication throttle.
+	if len(topicsReplicatingNow) > 0 {
+		log.Printf("Topics with ongoing reassignments: %s\n", topicsReplicatingNow.keys())
+		for _, s := range c.sessions.list() {
+			log.Printf("Reassignment session %s: topics %s\n", s.id, s.sortedTopics())
+		}
+
+		// Update the throttleManager.
+		throttleManager.SetOverrideRate(activeOverride.Rate)
+		throttleManager.SetReassignments(reassignments)
+
+		err = c.traced(ctx, "throttle update", throttleManager.UpdateReplicationThrottle)
+		if err != nil {
+			log.Println(err)
+		} else {
+			// Set knownThrottles.
+			c.knownThrottles = true
+		}
+	}
+
+	// Get brokers with active overrides, ie where the override rate is non-0,
+	// that are also not part of a reassignment.
+	fn := replication.NotReassignmentParticipant
+	activeOverrideBrokers := throttleManager.GetBrokerOverrides().Filter(fn)
+
+	// Apply any additional broker-specific throttles that were not applied as
+	// part of a reassignment.
+	if len(throttleManager.GetBrokerOverrides()) > 0 {
+		// Find all topics that include brokers with static overrides
+		// configured that aren't being reassigned. In order for broker-specific
+		// throttles to be applied, topics being replicated by those brokers
+		// must include them in the follower.replication.throttled.replicas
+		// dynamic configuration parameter. It's clumsy, but this is the way
+		// Kafka was designed.
+		// TODO(jamie): is there a scenario where we should exclude topics
+		// have also have a reassignment? We're discovering topics here by
+		// reverse lookup of brokers that are not reassignment participants.
+		var err error
+		otl, err := throttleManager.GetTopicsWithThrottledBrokers()
+		if err != nil {
+			log.Printf("Error fetching topic states: %s\n", err)
+		}
+
+		throttleManager.SetOverrideThrottleLists(otl)
+
+		// Update throttles.
+		if err := c.traced(ctx, "override throttle update", throttleManager.UpdateOverrideThrottles); err != nil {
+			log.Println(err)
+		}
+
+		// If we're updating throttles and the active count (those not marked for
+		// removal) is > 0, we should set the knownThrottles to true.
+		if len(activeOverrideBrokers) > 0 {
+			c.knownThrottles = true
+		}
+	}
+
+	// Remove and delete any broker-specific overrides set to 0.
+	if errs := throttleManager.PurgeOverrideThrottles(); errs != nil {
+		log.Println("Error removing persisted broker throttle overrides")
+		for i := range errs {
+			log.Println(errs[i])
+		}
+	}
+
+	// If there's no topics being reassigned, clear any throttles marked
+	// for automatic removal. Also, check if there's any broker throttles set.
+	// There's a somewhat complicated state problem here; if we previously
+	// set a broker throttle override but there's no reassignment, we'll
+	// immediately clear it here. There's two options:
+	//
+	// 1) Simply hold up clearing throttles if there's a broker throttle
+	//   override set.
+	// 2) Fetch all topics where any brokers with overrides are assigned
+	//   replicas, fetch all topic ISR states, diff the ISR states and the
+	//   replica assignments to track under-replicated topics, then adding
+	//   an under-replicated == 0 condition here.
+	//
+	// We're going with option 1 for now.
+
+	// Capture all the current conditions:
+
+	// Are there throttles eligible to be cleared?
+	var throttlesToClear = c.knownThrottles || c.cleanupDue()
+
+	// Are any topics being reassigned?
+	var topicsReassigning bool
+	if len(topicsReplicatingNow) > 0 {
+		topicsReassigning = true
+	}
+
+	// Do any brokers have throttle overrides set?
+	var brokerOverridesSet bool
+	if len(activeOverrideBrokers) > 0 {
+		brokerOverridesSet = true
+	}
+
+	// Next steps according to the various conditions:
+
+	if !topicsReassigning {
+		log.Println("No topics undergoing reassignment")
+	}
+
+	if !topicsReassigning && throttlesToClear && brokerOverridesSet {
+		log.Println("One or more brokers level override are set; automatic throttle removal will be skipped")
+	}
+
+	// If there's previously set throttles but no topics reassigning nor
+	// broker overrides set, we can issue a global throttle removal.
+	if !topicsReassigning && throttlesToClear && !brokerOverridesSet {
+		// Start a new cleanup period.
+		c.resetCleanup()
+
+		if paused {
+			log.Println("There may be throttles eligible for removal, but skipping automatic removal since autothrottle is paused")
+		} else if c.skipAutoDeleteThrottles {
+			log.Println("There may be throttles eligible for removal, but skipping automatic removal since skip-auto-delete-throttles is set")
+		} else if unsynced := c.isrSync.outOfSync(c.now()); len(unsynced) > 0 {
+			log.Printf("Deferring throttle removal until reassigned replicas are in sync for topics %v\n", unsynced)
+			// Retry the removal in the next interval.
+			c.knownThrottles = true
+		} else if c.cleanupDryRun {
+			// Only report the configs that would be removed; the report is
+			// repeated in the next cleanup period.
+			if err := c.reportThrottleRemoval(true); err != nil {
+				log.Printf("Error reporting throttle removal: %s\n", err)
+			} else {
+				c.knownThrottles = false
+			}
+		} else {
+			// Remove all the broker + topic throttle configs.
+			err := c.traced(ctx, "throttle removal", c.removeAllThrottles)
+			if err != nil {
+				log.Printf("Error removing throttles: %s\n", err.Error())
+			} else {
+				// Only set knownThrottles to false if we've removed all
+				// without error.
+				c.knownThrottles = false
+			}
+
+			// Remove any configured throttle overrides where AutoRemove is
+			// true, other than those yet to start.
+			remaining, removed := overrideCfg.Remove(func(c throttlestore.ThrottleOverrideConfig) bool { return c.AutoRemove && !c.Pending() })
+			if removed > 0 {
+				err := throttlestore.StoreThrottleOverride(zk, c.api.Paths.OverrideRate, remaining)
+				if err != nil {
+					log.Println(err)
+				} else {
+					log.Println("Global throttle override removed")
+				}
+			}
+		}
+	}
+
+	// Scan for topic throttles left behind by finished reassignments.
+	c.scanOrphanedThrottles(paused)
+
+	// Publish the topics and brokers entering and leaving replication.
+	c.transitions.update(topicsReplicatingNow, throttleManager.ReassigningBrokerIDs())
+	if c.registry != nil {
+		families := c.transitions.families()
+		if d, ok := events.(DroppedEventsCounter); ok {
+			families = append(families, droppedEventsFamily(d))
+		}
+		c.registry.Update(families)
+	}
+
+	// Update the status exposed through the admin API.
+	topics := topicsReplicatingNow.keys()
+	sort.Strings(topics)
+
+	applied := map[int][2]*float64{}
+	for id, rates := range throttleManager.GetPreviousThrottles() {
+		applied[id] = rates
+	}
+
+	overrides := map[int]api.BrokerOverrideStatus{}
+	resolutions := map[int]api.BrokerResolution{}
+	if len(topics) > 0 {
+		for id, o := range throttleManager.OverrideRates() {
+			overrides[id] = api.BrokerOverrideStatus{
+				Rate:       o.Rate,
+				Precedence: o.Precedence,
+				Pinned:     o.Pinned,
+				Effective:  o.Effective,
+			}
+		}
+
+		for id, r := range throttleManager.Resolutions() {
+			steps := make([]api.ResolutionStep, 0, len(r.Steps))
+			for _, s := range r.Steps {
+				steps = append(steps, api.ResolutionStep{
+					Source:  s.Source,
+					Applied: s.Applied,
+					Detail:  s.Detail,
+					Rates:   s.Rates,
+				})
+			}
+
+			resolutions[id] = api.BrokerResolution{Rates: r.Rates, Reason: r.Reason, Steps: steps}
+		}
+	}
+
+	status := api.Status{
+		ReassigningTopics:       topics,
+		RFIncreaseTopics:        throttleManager.RFIncreaseTopics(),
+		ReassigningBrokers:      throttleManager.ReassigningBrokerIDs(),
+		GuardrailsTripped:       throttleManager.GuardrailsTripped(),
+		Paused:                  c.paused,
+		ManagerLockHolder:       c.lockHolder,
+		CruiseControlExecution:  c.cruiseControlExecution,
+		Throttles:               applied,
+		Overrides:               overrides,
+		Resolutions:             resolutions,
+		Sessions:                c.sessions.status(),
+		FallbackCapacityBrokers: throttleManager.FallbackCapacityBrokers(),
+		BrokerInstanceTypes:     throttleManager.BrokerInstanceTypes(),
+		OrphanedThrottleTopics:  c.orphanedTopics,
+		InterpolatedBrokers:     throttleManager.InterpolatedBrokers(),
+		Updated:                 c.now(),
+	}
+
+	if d, ok := events.(DroppedEventsCounter); ok {
+		status.DroppedEvents = d.DroppedEvents()
+	}
+
+	c.api.SetStatus(status)
+
+	if c.history != nil {
+		c.recordHistory(applied)
+	}
+
+	if c.metrics != nil {
+		c.postMetrics(start)
+	}
+
+	// Write the status back to the Kubernetes ConfigMap.
+	if c.op != nil {
+		c.op.writeStatus(ctx, status)
+	}
+
+	return nil
+}
+
+// recordHistory records the applied throttle rates and the network
+// utilization of the reassigning brokers measured in the interval.
+func (c *controller) recordHistory(applied map[int][2]*float64) {
+	brokers := map[int]history.BrokerRates{}
+
+	for id, rates := range applied {
+		if rates[0] != nil || rates[1] != nil {
+			brokers[id] = history.BrokerRates{Leader: rates[0], Follower: rates[1]}
+		}
+	}
+
+	for id, bw := range c.tm.TakeMeasuredBandwidth() {
+		r := brokers[id]
+		tx, rx := bw[0], bw[1]
+		r.NetTX, r.NetRX = &tx, &rx
+		brokers[id] = r
+	}
+
+	c.history.Record(history.Entry{Time: c.now(), Brokers: brokers})
+}
+
+// checkZKReachable takes the error, if any, from fetching the interval state
+// and tracks the number of consecutive intervals where ZooKeeper was
+// unreachable. A critical event is written once the count reaches
+// zkUnreachableIntervals.
+func (c *controller) checkZKReachable(err error) {
+	if err == nil && c.zk.Ready() {
+		if c.zkUnreachableIntervals > 0 && c.zkUnreachable >= c.zkUnreachableIntervals {
+			m := fmt.Sprintf("ZooKeeper reachable after %d intervals", c.zkUnreachable)
+			log.Println(m)
+			c.events.Write("ZooKeeper reachable", m)
+		}
+		c.zkUnreachable = 0
+		return
+	}
+
+	c.zkUnreachable++
+
+	if c.zkUnreachableIntervals > 0 && c.zkUnreachable == c.zkUnreachableIntervals {
+		m := fmt.Sprintf("ZooKeeper unreachable for %d consecutive intervals", c.zkUnreachable)
+		if err != nil {
+			m = fmt.Sprintf("%s: %s", m, err)
+		}
+		log.Println(m)
+		c.events.WriteCritical("ZooKeeper unreachable", m)
+	}
+}
+
+// restoreConfigSnapshot restores the config snapshot and removes the restore
+// request. A failed restore is retried in the next interval.
+func (c *controller) restoreConfigSnapshot() {
+	s, err := c.tm.RestoreConfigSnapshot()
+	switch err {
+	case nil:
+		m := fmt.Sprintf("Config snapshot restored for brokers %v and topics %v", sortedKeys(s.Brokers), sortedKeys(s.Topics))
+		log.Println(m)
+		c.events.Write("Config snapshot restored", m)
+		// Throttles set by autothrottle were reverted.
+		c.knownThrottles = false
+	case replication.ErrConfigSnapshotsDisabled:
+		log.Printf("Ignoring config snapshot restore request: %s\n", err)
+	default:
+		m := fmt.Sprintf("Error restoring config snapshot: %s", err)
+		log.Println(m)
+		c.events.WriteCritical("Config snapshot restore failed", m)
+		return
+	}
+
+	if err := throttlestore.RemoveConfigRestoreRequest(c.zk, c.api.Paths.ConfigRestore); err != nil {
+		log.Println(err)
+	}
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string]map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// traced calls fn within a span named name, recording any error returned. Spans
+// recorded by the ThrottleManager during fn are children of the span.
+func (c *controller) traced(ctx context.Context, name string, fn func() error) error {
+	sctx, span := tracing.Start(ctx, name)
+	defer span.End()
+
+	c.tm.SetTraceContext(sctx)
+	defer c.tm.SetTraceContext(ctx)
+
+	err := fn()
+	span.RecordError(err)
+
+	return err
+}
@@ -0,0 +1,790 @@
+package autothrottle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/history"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/orchestrator"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/tracing"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// eventsStub is an EventWriter that records events.
+type eventsStub struct {
+	titles   []string
+	messages []string
+}
+
+func (e *eventsStub) Write(t string, m string) {
+	e.titles = append(e.titles, t)
+	e.messages = append(e.messages, m)
+}
+
+func (e *eventsStub) WriteCritical(t string, m string) { e.Write(t, m) }
+
+func (e *eventsStub) WriteTagged(t string, m string, _ ...string) { e.Write(t, m) }
+
+// has returns whether an event with the title was written.
+func (e *eventsStub) has(title string) bool {
+	for _, t := range e.titles {
+		if t == title {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reset clears the recorded events.
+func (e *eventsStub) reset() {
+	e.titles, e.messages = nil, nil
+}
+
+// testController is a controller with scriptable reassignments and a manual
+// clock.
+type testController struct {
+	*controller
+	zk            *kafkazk.Stub
+	events        *eventsStub
+	reassignments kafkazk.Reassignments
+	clock         time.Time
+}
+
+func newTestController(t *testing.T, cfg Config) *testController {
+	zk := kafkazk.NewZooKeeperStub()
+	a := api.New(zk, "autothrottle", nil)
+	if err := api.InitZnodes(zk, a.Paths, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	lim, err := replication.NewLimits(replication.NewLimitsConfig{
+		Minimum:            10,
+		SourceMaximum:      90,
+		DestinationMaximum: 90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Metrics are unavailable and the failure threshold is never exceeded;
+	// throttle updates retain the previous throttles without writing configs.
+	km := kafkametrics.NewStub()
+	km.Script(kafkametrics.StubResponse{Errors: []error{errors.New("unavailable")}})
+
+	events := &eventsStub{}
+
+	tmCfg := replication.ThrottleManagerConfig{
+		Limits:           lim,
+		FailureThreshold: 1000,
+		KafkaZK:          zk,
+		KafkaMetrics:     km,
+		Events:           events,
+		ZnodePaths:       a.Paths,
+	}
+
+	if !cfg.SkipConfigSnapshot {
+		tmCfg.ConfigSnapshotPath = a.Paths.ConfigSnapshot
+	}
+
+	tm, err := replication.NewThrottleManager(tmCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orch := orchestrator.NewOrchestrator(orchestrator.Config{
+		ZK:            zk,
+		PlanZnodePath: a.Paths.ReassignmentPlan,
+		Events:        events,
+	})
+
+	cfg.ZK = zk
+	if cfg.Interval == 0 {
+		cfg.Interval = time.Minute
+	}
+
+	tc := &testController{
+		controller: newController(cfg, tm, orch, a, events, nil),
+		zk:         zk,
+		events:     events,
+		clock:      time.Unix(1700000000, 0),
+	}
+
+	tc.getReassignments = func() (kafkazk.Reassignments, error) {
+		return tc.reassignments, nil
+	}
+	tc.now = func() time.Time {
+		return tc.clock
+	}
+
+	return tc
+}
+
+// tickAfter advances the clock by d, sets the reassigning topics and runs an
+// interval.
+func (tc *testController) tickAfter(t *testing.T, d time.Duration, topics ...string) {
+	tc.clock = tc.clock.Add(d)
+
+	tc.reassignments = kafkazk.Reassignments{}
+	for _, topic := range topics {
+		tc.reassignments[topic] = map[int][]int{0: {1001, 1002}}
+	}
+
+	if err := tc.tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestControllerTopicsDoneReplicating(t *testing.T) {
+	tc := newTestController(t, Config{SkipAutoDeleteThrottles: true})
+
+	tc.tickAfter(t, 0, "test1", "test2")
+
+	if !tc.events.has("Reassignment session started") {
+		t.Errorf("Expected a session started event, got %v", tc.events.titles)
+	}
+
+	if !tc.knownThrottles {
+		t.Error("Expected knownThrottles to be true")
+	}
+
+	if topics := tc.topicsReplicatingPreviously; len(topics) != 2 {
+		t.Errorf("Expected 2 reassigning topics, got %v", topics.keys())
+	}
+
+	// test1 finishes.
+	tc.events.reset()
+	tc.tickAfter(t, 3*time.Minute, "test2")
+
+	if !tc.events.has("Topics done reassigning") || tc.events.has("Reassignment session complete") {
+		t.Errorf("Unexpected events %v", tc.events.titles)
+	}
+
+	if !strings.Contains(tc.events.messages[0], "[test1]") {
+		t.Errorf("Expected test1 done, got %s", tc.events.messages[0])
+	}
+
+	// test2 finishes, completing the session.
+	tc.events.reset()
+	tc.tickAfter(t, 3*time.Minute)
+
+	if !tc.events.has("Topics done reassigning") || !tc.events.has("Reassignment session complete") {
+		t.Errorf("Unexpected events %v", tc.events.titles)
+	}
+
+	expected := "complete after 6m0s"
+	if m := tc.events.messages[len(tc.events.messages)-1]; !strings.HasSuffix(m, expected) {
+		t.Errorf("Expected message ending with %q, got %q", expected, m)
+	}
+}
+
+func TestControllerCleanupAfter(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 2, SkipAutoDeleteThrottles: true})
+
+	lastCleanup := func() int64 {
+		state, err := throttlestore.FetchCleanupState(tc.zk, tc.api.Paths.Cleanup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return state.Last
+	}
+
+	// With no recorded cleanup, the cleanup period starts in the first
+	// interval.
+	tc.tickAfter(t, 0)
+	start := tc.clock.Unix()
+
+	if last := lastCleanup(); last != start {
+		t.Errorf("Expected last cleanup %d, got %d", start, last)
+	}
+
+	// With no known throttles, removal isn't attempted until the cleanup
+	// period elapses.
+	tc.tickAfter(t, time.Minute)
+
+	if !tc.lastCleanup.Equal(time.Unix(start, 0)) {
+		t.Errorf("Expected last cleanup %d, got %v", start, tc.lastCleanup)
+	}
+
+	// The cleanup period elapsing starts a new period.
+	tc.tickAfter(t, time.Minute)
+
+	if last := lastCleanup(); last != tc.clock.Unix() {
+		t.Errorf("Expected last cleanup %d, got %d", tc.clock.Unix(), last)
+	}
+
+	// Known throttles are eligible for removal in any interval once
+	// reassignments complete.
+	tc.tickAfter(t, time.Minute, "test1")
+	tc.tickAfter(t, time.Minute)
+
+	if last := lastCleanup(); last != tc.clock.Unix() {
+		t.Errorf("Expected last cleanup %d, got %d", tc.clock.Unix(), last)
+	}
+
+	// A restarted controller resumes the stored cleanup period rather than
+	// starting a new one.
+	stored := lastCleanup()
+	tc.controller = newController(Config{ZK: tc.zk, Interval: time.Minute, CleanupAfter: 2, SkipAutoDeleteThrottles: true}, tc.tm, tc.orch, tc.api, tc.events, nil)
+	tc.getReassignments = func() (kafkazk.Reassignments, error) { return tc.reassignments, nil }
+	tc.now = func() time.Time { return tc.clock }
+
+	tc.tickAfter(t, time.Minute)
+
+	if last := lastCleanup(); last != stored {
+		t.Errorf("Expected last cleanup %d, got %d", stored, last)
+	}
+
+	tc.tickAfter(t, time.Minute)
+
+	if last := lastCleanup(); last != tc.clock.Unix() {
+		t.Errorf("Expected last cleanup %d, got %d", tc.clock.Unix(), last)
+	}
+}
+
+func TestControllerRemoveThrottles(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60})
+
+	tc.tickAfter(t, 0, "test1")
+	tc.zk.ResetKafkaConfigUpdates()
+
+	// Throttles are removed once the reassignment is done.
+	tc.tickAfter(t, time.Minute)
+
+	if len(tc.zk.KafkaConfigUpdates()) == 0 {
+		t.Error("Expected throttle removal config updates")
+	}
+
+	if !tc.events.has("Removing replication throttle configs") {
+		t.Errorf("Expected a throttle removal event, got %v", tc.events.titles)
+	}
+
+	if tc.knownThrottles {
+		t.Error("Expected knownThrottles to be false")
+	}
+
+	// Nothing is removed in subsequent intervals.
+	tc.zk.ResetKafkaConfigUpdates()
+	tc.tickAfter(t, time.Minute)
+
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+}
+
+func TestControllerCleanupDryRun(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60, CleanupDryRun: true})
+
+	tc.tickAfter(t, 0, "test1")
+	tc.zk.ResetKafkaConfigUpdates()
+	tc.events.reset()
+
+	// The throttle configs are reported rather than removed once the
+	// reassignment is done.
+	tc.tickAfter(t, time.Minute)
+
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	if !tc.events.has("Replication throttle removal dry run") {
+		t.Fatalf("Expected a dry run event, got %v", tc.events.titles)
+	}
+
+	if tc.knownThrottles {
+		t.Error("Expected knownThrottles to be false")
+	}
+
+	for _, m := range tc.events.messages {
+		if strings.Contains(m, "would be removed") && !strings.Contains(m, "topic test_topic2 leader.replication.throttled.replicas") {
+			t.Errorf("Expected test_topic2 listed, got %s", m)
+		}
+	}
+}
+
+func TestControllerHistory(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60})
+
+	h, err := history.New(history.Config{Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.history = h
+
+	tc.tickAfter(t, 0, "test1")
+	tc.tickAfter(t, time.Minute)
+
+	entries := h.Since(time.Time{})
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 history entries, got %d", len(entries))
+	}
+
+	if !entries[1].Time.Equal(tc.clock) {
+		t.Errorf("Expected the entry at %s, got %s", tc.clock, entries[1].Time)
+	}
+
+	// Throttles were removed once the reassignment was done.
+	if len(entries[1].Brokers) != 0 {
+		t.Errorf("Expected no broker rates, got %v", entries[1].Brokers)
+	}
+}
+
+func TestControllerISRSync(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60, ISRSyncGracePeriod: 5 * time.Minute})
+
+	// Replica 1001 of the test reassignment hasn't joined the ISR.
+	isr := []int{1002}
+	tc.isrSync.getTopicStateISR = func(string) (kafkazk.TopicStateISR, error) {
+		return kafkazk.TopicStateISR{"0": {Leader: 1002, ISR: isr}}, nil
+	}
+
+	tc.tickAfter(t, 0, "test1")
+	tc.zk.ResetKafkaConfigUpdates()
+
+	// Removal is deferred while the moved replicas are out of sync.
+	tc.tickAfter(t, time.Minute)
+
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	if !tc.knownThrottles {
+		t.Error("Expected knownThrottles to be true")
+	}
+
+	// Throttles are removed once the replicas are in sync.
+	isr = []int{1002, 1001}
+	tc.tickAfter(t, time.Minute)
+
+	if len(tc.zk.KafkaConfigUpdates()) == 0 {
+		t.Error("Expected throttle removal config updates")
+	}
+
+	if tc.knownThrottles {
+		t.Error("Expected knownThrottles to be false")
+	}
+
+	// Throttles are removed regardless once the grace period elapses.
+	isr = []int{1002}
+	tc.tickAfter(t, time.Minute, "test1")
+	tc.tickAfter(t, time.Minute)
+	tc.tickAfter(t, 4*time.Minute)
+	tc.zk.ResetKafkaConfigUpdates()
+
+	if !tc.knownThrottles {
+		t.Error("Expected knownThrottles to be true")
+	}
+
+	tc.tickAfter(t, time.Minute)
+
+	if len(tc.zk.KafkaConfigUpdates()) == 0 {
+		t.Error("Expected throttle removal config updates")
+	}
+
+	if len(tc.isrSync.pending) != 0 {
+		t.Errorf("Expected no pending topics, got %v", tc.isrSync.pending)
+	}
+}
+
+func TestControllerConfigSnapshotRestore(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60})
+
+	// The broker configs are recorded ahead of the throttle removal.
+	tc.tickAfter(t, 0, "test1")
+	tc.tickAfter(t, time.Minute)
+
+	s, err := throttlestore.FetchConfigSnapshot(tc.zk, tc.api.Paths.ConfigSnapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Brokers) == 0 {
+		t.Fatal("Expected a broker config snapshot")
+	}
+
+	// Restore the snapshot.
+	if err := throttlestore.RequestConfigRestore(tc.zk, tc.api.Paths.ConfigRestore); err != nil {
+		t.Fatal(err)
+	}
+
+	tc.zk.ResetKafkaConfigUpdates()
+	tc.tickAfter(t, time.Minute)
+
+	if !tc.events.has("Config snapshot restored") {
+		t.Errorf("Expected a config snapshot restored event, got %v", tc.events.titles)
+	}
+
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != len(s.Brokers)+len(s.Topics) {
+		t.Errorf("Expected %d config updates, got %v", len(s.Brokers)+len(s.Topics), updates)
+	}
+
+	for _, p := range []string{tc.api.Paths.ConfigSnapshot, tc.api.Paths.ConfigRestore} {
+		if exists, _ := tc.zk.Exists(p); exists {
+			t.Errorf("Expected %s to be removed", p)
+		}
+	}
+
+	// Restore requests are discarded with snapshots disabled.
+	tc = newTestController(t, Config{SkipConfigSnapshot: true})
+
+	if err := throttlestore.RequestConfigRestore(tc.zk, tc.api.Paths.ConfigRestore); err != nil {
+		t.Fatal(err)
+	}
+
+	tc.tickAfter(t, 0)
+
+	if exists, _ := tc.zk.Exists(tc.api.Paths.ConfigRestore); exists {
+		t.Error("Expected the restore request to be removed")
+	}
+}
+
+func TestControllerRequesterOverrides(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60})
+
+	overrides := map[string]throttlestore.ThrottleOverrideConfig{
+		"ci":       {Rate: 50, AutoRemove: true},
+		"operator": {Rate: 100},
+		"batch":    {Rate: 80, Expires: tc.clock.Add(-time.Minute).Unix()},
+	}
+
+	for r, o := range overrides {
+		if _, err := throttlestore.SetRequesterOverride(tc.zk, tc.api.Paths.OverrideRate, r, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Expired overrides are removed, leaving the others in place.
+	tc.tickAfter(t, 0, "test1")
+
+	if !tc.events.has("Global throttle override expired") {
+		t.Errorf("Expected an expiry event, got %v", tc.events.titles)
+	}
+
+	c, err := throttlestore.FetchThrottleOverride(tc.zk, tc.api.Paths.OverrideRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, exists := c.Requesters["batch"]; exists || c.Rate != 50 || len(c.Requesters) != 2 {
+		t.Errorf("Unexpected override %+v", c)
+	}
+
+	// Only autoremove overrides are removed once the reassignment is done.
+	tc.tickAfter(t, time.Minute)
+
+	c, err = throttlestore.FetchThrottleOverride(tc.zk, tc.api.Paths.OverrideRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, exists := c.Requesters["operator"]; !exists || c.Rate != 100 || len(c.Requesters) != 1 {
+		t.Errorf("Unexpected override %+v", c)
+	}
+}
+
+func TestControllerScheduledOverrides(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60})
+
+	// Schedules are relative to the wall clock.
+	later := time.Now().Add(time.Hour).Unix()
+
+	global := throttlestore.ThrottleOverrideConfig{Rate: 50, AutoRemove: true, StartAt: later}
+	if err := throttlestore.StoreThrottleOverride(tc.zk, tc.api.Paths.OverrideRate, global); err != nil {
+		t.Fatal(err)
+	}
+
+	broker := throttlestore.ThrottleOverrideConfig{Rate: 20, StartAt: later}
+	brokerPath := tc.api.Paths.OverrideRate + "/1001"
+	if err := throttlestore.StoreThrottleOverride(tc.zk, brokerPath, broker); err != nil {
+		t.Fatal(err)
+	}
+
+	tc.tickAfter(t, 0, "test1")
+
+	if bo := tc.tm.GetBrokerOverrides(); len(bo) != 0 {
+		t.Errorf("Expected no broker overrides applied, got %v", bo)
+	}
+
+	// Pending overrides are retained once the reassignment is done.
+	tc.tickAfter(t, time.Minute)
+
+	c, err := throttlestore.FetchThrottleOverride(tc.zk, tc.api.Paths.OverrideRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Rate != 50 || c.StartAt != later {
+		t.Errorf("Expected the global override to be retained, got %+v", c)
+	}
+
+	c, err = throttlestore.FetchThrottleOverride(tc.zk, brokerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Rate != 20 {
+		t.Errorf("Expected the broker override to be retained, got %+v", c)
+	}
+}
+
+func TestControllerPaused(t *testing.T) {
+	tc := newTestController(t, Config{})
+
+	tc.tickAfter(t, 0, "test1")
+
+	if err := tc.zk.Set(tc.api.Paths.Pause, `{"paused": true}`); err != nil {
+		t.Fatal(err)
+	}
+
+	tc.events.reset()
+	tc.zk.ResetKafkaConfigUpdates()
+	tc.tickAfter(t, time.Minute)
+
+	if !tc.events.has("Autothrottle paused") {
+		t.Errorf("Expected a paused event, got %v", tc.events.titles)
+	}
+
+	// Throttles aren't removed while paused.
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	if !tc.knownThrottles {
+		t.Error("Expected knownThrottles to be true")
+	}
+}
+
+func TestControllerCancelledReassignments(t *testing.T) {
+	tc := newTestController(t, Config{})
+
+	tc.tickAfter(t, 0, "test1", "test2")
+
+	// test1 is cancelled through the admin API.
+	if err := throttlestore.AddCancelledReassignments(tc.zk, tc.api.Paths.Cancelled, []string{"test1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tc.events.reset()
+	tc.zk.ResetKafkaConfigUpdates()
+	tc.tickAfter(t, time.Minute, "test2")
+
+	if !tc.events.has("Cancelled reassignment throttles cleared") {
+		t.Errorf("Expected a cancelled reassignment event, got %v", tc.events.titles)
+	}
+
+	var cleared bool
+	for _, c := range tc.zk.KafkaConfigUpdates() {
+		if c.Type == "topic" && c.Name == "test1" {
+			cleared = true
+		}
+	}
+
+	if !cleared {
+		t.Errorf("Expected test1 topic throttles to be removed, got %v", tc.zk.KafkaConfigUpdates())
+	}
+
+	// The record is removed once the throttles are cleared.
+	c, err := throttlestore.FetchCancelledReassignments(tc.zk, tc.api.Paths.Cancelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Topics) != 0 {
+		t.Errorf("Expected no cancelled reassignments, got %v", c.Topics)
+	}
+}
+
+func TestControllerReassignmentsError(t *testing.T) {
+	tc := newTestController(t, Config{})
+	tc.getReassignments = func() (kafkazk.Reassignments, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	if err := tc.tick(context.Background()); err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
+
+func TestControllerZKUnreachable(t *testing.T) {
+	tc := newTestController(t, Config{ZKUnreachableIntervals: 2})
+
+	unavailable := true
+	tc.getReassignments = func() (kafkazk.Reassignments, error) {
+		if unavailable {
+			return nil, errors.New("unavailable")
+		}
+		return kafkazk.Reassignments{}, nil
+	}
+
+	// The event is written once the unreachable count reaches the threshold.
+	for i, expected := range []bool{false, true} {
+		tc.events.reset()
+		tc.tick(context.Background())
+
+		if got := tc.events.has("ZooKeeper unreachable"); got != expected {
+			t.Errorf("[interval %d] Expected unreachable event %v, got %v", i, expected, got)
+		}
+	}
+
+	// It's not repeated while ZooKeeper remains unreachable.
+	tc.events.reset()
+	tc.tick(context.Background())
+	if tc.events.has("ZooKeeper unreachable") {
+		t.Error("Unexpected repeated unreachable event")
+	}
+
+	// Recovery is noted.
+	unavailable = false
+	tc.events.reset()
+	if err := tc.tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !tc.events.has("ZooKeeper reachable") {
+		t.Errorf("Expected reachable event, got %v", tc.events.titles)
+	}
+}
+
+func TestControllerFetchTimeout(t *testing.T) {
+	tc := newTestController(t, Config{})
+	tc.fetchTimeout = 10 * time.Millisecond
+
+	// Reassignments are unavailable until released.
+	release := make(chan struct{})
+	defer close(release)
+
+	tc.getReassignments = func() (kafkazk.Reassignments, error) {
+		<-release
+		return kafkazk.Reassignments{}, nil
+	}
+
+	err := tc.tick(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestControllerTracing(t *testing.T) {
+	type span struct {
+		SpanID       string `json:"spanId"`
+		ParentSpanID string `json:"parentSpanId"`
+		Name         string `json:"name"`
+		Status       struct {
+			Message string `json:"message"`
+		} `json:"status"`
+	}
+
+	var spans []span
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []span `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		spans = append(spans, req.ResourceSpans[0].ScopeSpans[0].Spans...)
+	}))
+	defer ts.Close()
+
+	exporter, err := tracing.NewExporter(tracing.ExporterConfig{Endpoint: ts.URL, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracing.SetExporter(exporter)
+	t.Cleanup(func() { tracing.SetExporter(nil) })
+
+	tc := newTestController(t, Config{SkipAutoDeleteThrottles: true})
+	tc.tickAfter(t, 0, "test1")
+
+	if err := exporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]span{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+
+	// Each span is a child of the span named by its parent.
+	parents := map[string]string{
+		"interval state fetch":   "interval",
+		"reassignments request":  "interval state fetch",
+		"pause config read":      "interval state fetch",
+		"throttle update":        "interval",
+		"broker metrics request": "throttle update",
+	}
+
+	if s, exists := byName["interval"]; !exists || s.ParentSpanID != "" {
+		t.Errorf("Expected a root interval span, got %v", spans)
+	}
+
+	for name, parent := range parents {
+		s, exists := byName[name]
+		if !exists {
+			t.Errorf("Expected span %s, got %v", name, spans)
+			continue
+		}
+
+		if s.ParentSpanID != byName[parent].SpanID {
+			t.Errorf("Expected span %s to be a child of %s", name, parent)
+		}
+	}
+
+	// No broker overrides are set.
+	if _, exists := byName["override throttle update"]; exists {
+		t.Error("Unexpected override throttle update span")
+	}
+
+	if msg := byName["broker metrics request"].Status.Message; msg != "unavailable" {
+		t.Errorf("Expected the metrics error to be recorded, got %q", msg)
+	}
+}
+
+func TestControllerOrphanedThrottles(t *testing.T) {
+	tc := newTestController(t, Config{OrphanScanIntervals: 2, RemoveOrphanedThrottles: true})
+
+	// The stub has throttled replicas set on topics test_topic and test_topic2.
+	tc.tickAfter(t, 0, "test1")
+
+	if tc.events.has("Orphaned topic throttles found") {
+		t.Error("Expected no scan in the first interval")
+	}
+
+	tc.zk.ResetKafkaConfigUpdates()
+	tc.tickAfter(t, time.Minute, "test1")
+
+	if !tc.events.has("Orphaned topic throttles found") {
+		t.Errorf("Expected an orphaned throttles event, got %v", tc.events.titles)
+	}
+
+	removed := map[string]bool{}
+	for _, u := range tc.zk.KafkaConfigUpdates() {
+		if u.Type == "topic" {
+			removed[u.Name] = true
+		}
+	}
+
+	if !removed["test_topic"] || !removed["test_topic2"] {
+		t.Errorf("Expected throttles removed from test_topic and test_topic2, got %v", removed)
+	}
+
+	if tc.orphanedTopics != nil {
+		t.Errorf("Expected no orphaned topics after removal, got %v", tc.orphanedTopics)
+	}
+}
@@ -0,0 +1,91 @@
+package autothrottle
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/cruisecontrol"
+)
+
+// Cruise Control integration modes.
+const (
+	// CruiseControlDefer suspends throttle changes while Cruise Control is
+	// executing, leaving its reassignments to be throttled by Cruise Control.
+	CruiseControlDefer = "defer"
+	// CruiseControlManage throttles Cruise Control's reassignments like any
+	// other, rewriting the throttles Cruise Control set.
+	CruiseControlManage = "manage"
+)
+
+// CruiseControlConfig holds Cruise Control integration configurations. When
+// configured, the Cruise Control executor state is queried each interval so
+// that autothrottle and Cruise Control don't make conflicting broker config
+// writes during an execution, e.g. a rebalance.
+type CruiseControlConfig struct {
+	// The Cruise Control API URL including the path prefix, e.g.
+	// http://cruise-control:9090/kafkacruisecontrol. Disabled if unset.
+	URL string
+	// Either CruiseControlDefer or CruiseControlManage. Defaults to
+	// CruiseControlDefer.
+	Mode string
+}
+
+// executorStateGetter returns the Cruise Control executor state.
+type executorStateGetter interface {
+	ExecutorState(context.Context) (cruisecontrol.ExecutorState, error)
+}
+
+// checkCruiseControl queries the Cruise Control executor state, updating the
+// execution in progress. Events are written when an execution starts and
+// finishes. In manage mode, the throttles previously set are forgotten when
+// an execution starts so that any Cruise Control throttles are rewritten. If
+// the state can't be read, the previous state is retained.
+func (c *controller) checkCruiseControl(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.fetchTimeout)
+	defer cancel()
+
+	state, err := c.cruiseControl.ExecutorState(ctx)
+	if err != nil {
+		log.Printf("Error fetching the Cruise Control executor state: %s\n", err)
+		return
+	}
+
+	switch {
+	case state.InProgress() && c.cruiseControlExecution == "":
+		var m string
+		if c.cruiseControlMode == CruiseControlManage {
+			c.tm.ResetPreviousThrottles()
+			c.tm.ResetTopicThrottles()
+			m = fmt.Sprintf("Cruise Control execution %s started; its reassignments will be throttled by autothrottle", state)
+		} else {
+			m = fmt.Sprintf("Cruise Control execution %s started; no throttle changes will be applied until it finishes", state)
+		}
+		log.Println(m)
+		c.events.Write("Cruise Control execution started", m)
+	case !state.InProgress() && c.cruiseControlExecution != "":
+		m := fmt.Sprintf("Cruise Control execution %s finished", c.cruiseControlExecution)
+		log.Println(m)
+		c.events.Write("Cruise Control execution finished", m)
+	case state.InProgress():
+		log.Printf("Cruise Control execution %s in progress\n", state)
+	}
+
+	c.cruiseControlExecution = ""
+	if state.InProgress() {
+		c.cruiseControlExecution = state.String()
+	}
+}
+
+// suspendedReason returns why throttle changes are suspended aside from
+// autothrottle being paused, or an empty string if they aren't.
+func (c *controller) suspendedReason() string {
+	switch {
+	case c.lock != nil && !c.lockHeld:
+		return "throttle manager lock held by another manager"
+	case c.cruiseControlMode == CruiseControlDefer && c.cruiseControlExecution != "":
+		return "deferring to a Cruise Control execution"
+	}
+
+	return ""
+}
@@ -0,0 +1,90 @@
+package autothrottle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/cruisecontrol"
+)
+
+type stubExecutorState struct {
+	state cruisecontrol.ExecutorState
+}
+
+func (s *stubExecutorState) ExecutorState(context.Context) (cruisecontrol.ExecutorState, error) {
+	return s.state, nil
+}
+
+var (
+	noExecution         = cruisecontrol.ExecutorState{State: cruisecontrol.StateNoTaskInProgress}
+	rebalanceInProgress = cruisecontrol.ExecutorState{State: "INTER_BROKER_REPLICA_MOVEMENT_TASK_IN_PROGRESS", TriggeredUserTaskID: "5b0c9f3e"}
+)
+
+func TestControllerCruiseControlDefer(t *testing.T) {
+	cc := &stubExecutorState{state: noExecution}
+
+	tc := newTestController(t, Config{CleanupAfter: 60})
+	tc.cruiseControl = cc
+	tc.cruiseControlMode = CruiseControlDefer
+
+	tc.tickAfter(t, 0, "test1")
+
+	cc.state = rebalanceInProgress
+	tc.zk.ResetKafkaConfigUpdates()
+
+	// Throttles aren't removed during an execution.
+	tc.tickAfter(t, time.Minute)
+
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	if !tc.events.has("Cruise Control execution started") {
+		t.Errorf("Expected an execution started event, got %v", tc.events.titles)
+	}
+
+	if tc.suspendedReason() == "" {
+		t.Error("Expected throttle changes to be suspended")
+	}
+
+	// Throttles are removed once the execution finishes.
+	cc.state = noExecution
+	tc.tickAfter(t, time.Minute)
+
+	if !tc.events.has("Cruise Control execution finished") {
+		t.Errorf("Expected an execution finished event, got %v", tc.events.titles)
+	}
+
+	if len(tc.zk.KafkaConfigUpdates()) == 0 {
+		t.Error("Expected throttle removal config updates")
+	}
+}
+
+func TestControllerCruiseControlManage(t *testing.T) {
+	cc := &stubExecutorState{state: noExecution}
+
+	tc := newTestController(t, Config{CleanupAfter: 60})
+	tc.cruiseControl = cc
+	tc.cruiseControlMode = CruiseControlManage
+
+	tc.tickAfter(t, 0, "test1")
+
+	cc.state = rebalanceInProgress
+	tc.zk.ResetKafkaConfigUpdates()
+
+	// Throttle changes continue during an execution.
+	tc.tickAfter(t, time.Minute)
+
+	if !tc.events.has("Cruise Control execution started") {
+		t.Errorf("Expected an execution started event, got %v", tc.events.titles)
+	}
+
+	if tc.suspendedReason() != "" {
+		t.Errorf("Expected throttle changes not to be suspended, got %q", tc.suspendedReason())
+	}
+
+	if len(tc.zk.KafkaConfigUpdates()) == 0 {
+		t.Error("Expected throttle removal config updates")
+	}
+}
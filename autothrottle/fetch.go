@@ -0,0 +1,173 @@
+package autothrottle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/tracing"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// intervalState holds the state read at the start of each interval. Errors
+// other than a failure to fetch reassignments are held for the caller to
+// handle where the value is used.
+type intervalState struct {
+	reassignments      kafkazk.Reassignments
+	pauseCfg           throttlestore.PauseConfig
+	pauseErr           error
+	overrideCfg        *throttlestore.ThrottleOverrideConfig
+	overrideErr        error
+	brokerOverrides    throttlestore.BrokerOverrides
+	brokerOverridesErr error
+	pins               throttlestore.BrokerOverrides
+	pinsErr            error
+	priorities         throttlestore.TopicPriorities
+	prioritiesErr      error
+	cancelled          throttlestore.CancelledReassignments
+	cancelledErr       error
+	restore            throttlestore.ConfigRestoreRequest
+	restoreErr         error
+}
+
+// fetchIntervalState concurrently reads the ongoing reassignments and the
+// autothrottle configuration stored in ZooKeeper. If prefetchMetrics is true,
+// broker metrics are fetched alongside so that a slow metrics request doesn't
+// delay the rest of the interval. Each read is subject to the controller's
+// fetch timeout. An error is returned if reassignments couldn't be fetched.
+func (c *controller) fetchIntervalState(ctx context.Context, prefetchMetrics bool) (intervalState, error) {
+	var s intervalState
+
+	ctx, span := tracing.Start(ctx, "interval state fetch")
+	defer span.End()
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	// Reads that time out continue in the background; they must not reference
+	// any state that may change in the meantime.
+	zk := c.zk
+	getReassignments := c.getReassignments
+	pausePath, overridePath, pinnedPath := c.api.Paths.Pause, c.api.Paths.OverrideRate, c.api.Paths.PinnedRate
+	priorityPath, cancelledPath, restorePath := c.api.Paths.Priority, c.api.Paths.Cancelled, c.api.Paths.ConfigRestore
+
+	g.Go(func() error {
+		r, err := withTimeout(ctx, c.fetchTimeout, "reassignments request", getReassignments)
+		if err != nil {
+			return fmt.Errorf("error fetching reassignments: %s", err)
+		}
+		s.reassignments = r
+		return nil
+	})
+
+	g.Go(func() error {
+		s.pauseCfg, s.pauseErr = withTimeout(ctx, c.fetchTimeout, "pause config read", func() (throttlestore.PauseConfig, error) {
+			return throttlestore.FetchPauseConfig(zk, pausePath)
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		s.overrideCfg, s.overrideErr = withTimeout(ctx, c.fetchTimeout, "throttle override read", func() (*throttlestore.ThrottleOverrideConfig, error) {
+			return throttlestore.FetchThrottleOverride(zk, overridePath)
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		s.brokerOverrides, s.brokerOverridesErr = withTimeout(ctx, c.fetchTimeout, "broker overrides read", func() (throttlestore.BrokerOverrides, error) {
+			return throttlestore.FetchBrokerOverrides(zk, overridePath)
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		s.pins, s.pinsErr = withTimeout(ctx, c.fetchTimeout, "pinned throttles read", func() (throttlestore.BrokerOverrides, error) {
+			return throttlestore.FetchBrokerOverrides(zk, pinnedPath)
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		s.priorities, s.prioritiesErr = withTimeout(ctx, c.fetchTimeout, "topic priorities read", func() (throttlestore.TopicPriorities, error) {
+			return throttlestore.FetchTopicPriorities(zk, priorityPath)
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		s.cancelled, s.cancelledErr = withTimeout(ctx, c.fetchTimeout, "cancelled reassignments read", func() (throttlestore.CancelledReassignments, error) {
+			return throttlestore.FetchCancelledReassignments(zk, cancelledPath)
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		s.restore, s.restoreErr = withTimeout(ctx, c.fetchTimeout, "config restore request read", func() (throttlestore.ConfigRestoreRequest, error) {
+			return throttlestore.FetchConfigRestoreRequest(zk, restorePath)
+		})
+		return nil
+	})
+
+	// The metrics request enforces its own timeout.
+	if prefetchMetrics {
+		g.Go(func() error {
+			c.tm.PrefetchMetrics()
+			return nil
+		})
+	}
+
+	err := g.Wait()
+
+	// A timed out read returns a nil override config.
+	if s.overrideCfg == nil {
+		s.overrideCfg = &throttlestore.ThrottleOverrideConfig{}
+	}
+
+	return s, err
+}
+
+// withTimeout calls fn, returning its results or an error naming desc if it
+// doesn't complete before the timeout or the context is cancelled. fn continues
+// running in the background on a timeout; its results are discarded. A
+// timeout of 0 disables the timeout. Each call is recorded as a span named desc.
+func withTimeout[T any](ctx context.Context, timeout time.Duration, desc string, fn func() (T, error)) (v T, err error) {
+	ctx, span := tracing.Start(ctx, desc)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	if timeout <= 0 {
+		return fn()
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+
+	// Buffered so that fn completing after the timeout doesn't block.
+	results := make(chan result, 1)
+
+	go func() {
+		v, err := fn()
+		results <- result{v, err}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case r := <-results:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		if ctx.Err() == context.DeadlineExceeded {
+			return zero, fmt.Errorf("%s timed out after %s", desc, timeout)
+		}
+		return zero, ctx.Err()
+	}
+}
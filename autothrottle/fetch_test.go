@@ -0,0 +1,50 @@
+package autothrottle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	v, err := withTimeout(ctx, time.Second, "test", func() (int, error) {
+		return 1, nil
+	})
+	if v != 1 || err != nil {
+		t.Errorf("Expected 1, nil, got %d, %v", v, err)
+	}
+
+	expectedErr := errors.New("error")
+	if _, err := withTimeout(ctx, time.Second, "test", func() (int, error) {
+		return 0, expectedErr
+	}); err != expectedErr {
+		t.Errorf("Expected %v, got %v", expectedErr, err)
+	}
+
+	// Calls that don't complete in time return a zero value.
+	release := make(chan struct{})
+	defer close(release)
+
+	v, err = withTimeout(ctx, 10*time.Millisecond, "test", func() (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	if v != 0 || err == nil || err.Error() != "test timed out after 10ms" {
+		t.Errorf("Expected 0, timeout error, got %d, %v", v, err)
+	}
+
+	// A cancelled context returns its error.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := withTimeout(cctx, time.Second, "test", func() (int, error) {
+		<-release
+		return 1, nil
+	}); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,136 @@
+package autothrottle
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// completedReassignment is a topic that's no longer reassigning but whose
+// moved replicas have not yet been observed in sync.
+type completedReassignment struct {
+	// The reassignment target replicas by partition.
+	partitions map[int][]int
+	// When the reassignment was first seen complete.
+	completed time.Time
+}
+
+// isrSyncTracker tracks completed reassignments until every target replica is
+// a member of the partition ISR. A reassignment is removed from the
+// reassign_partitions znode once the controller has finished the move, which
+// doesn't guarantee that replication traffic has ceased; removing throttles
+// beforehand lets any remaining catch-up run unthrottled.
+type isrSyncTracker struct {
+	// The maximum time to wait for replicas to join the ISR; checks are
+	// disabled if 0.
+	gracePeriod time.Duration
+	// Returns the ISR state for a topic.
+	getTopicStateISR func(string) (kafkazk.TopicStateISR, error)
+
+	previous kafkazk.Reassignments
+	pending  map[string]completedReassignment
+}
+
+func newISRSyncTracker(gracePeriod time.Duration, fn func(string) (kafkazk.TopicStateISR, error)) *isrSyncTracker {
+	return &isrSyncTracker{
+		gracePeriod:      gracePeriod,
+		getTopicStateISR: fn,
+		pending:          map[string]completedReassignment{},
+	}
+}
+
+// update takes the current reassignments and the current time. Topics
+// reassigning in the previous call but not in r are tracked as completed.
+func (s *isrSyncTracker) update(r kafkazk.Reassignments, t time.Time) {
+	if s.gracePeriod <= 0 {
+		return
+	}
+
+	for topic, partitions := range s.previous {
+		if _, exists := r[topic]; !exists {
+			s.pending[topic] = completedReassignment{partitions: partitions, completed: t}
+		}
+	}
+
+	// A topic that's reassigning again is tracked anew once done.
+	for topic := range r {
+		delete(s.pending, topic)
+	}
+
+	s.previous = r
+}
+
+// tracked returns whether the topic completed reassigning and is awaiting ISR
+// sync.
+func (s *isrSyncTracker) tracked(topic string) bool {
+	_, exists := s.pending[topic]
+	return exists
+}
+
+// outOfSync takes the current time and returns the completed topics with any
+// target replicas not yet in the ISR. Topics found in sync, along with those
+// past the grace period, are no longer tracked.
+func (s *isrSyncTracker) outOfSync(t time.Time) []string {
+	var topics []string
+
+	for topic, c := range s.pending {
+		err := s.inSync(topic, c.partitions)
+		if err == nil {
+			log.Printf("Replicas of topic %s are in sync\n", topic)
+			delete(s.pending, topic)
+			continue
+		}
+
+		if t.Sub(c.completed) >= s.gracePeriod {
+			log.Printf("Grace period elapsed waiting for replicas of topic %s to sync: %s\n", topic, err)
+			delete(s.pending, topic)
+			continue
+		}
+
+		log.Printf("Topic %s: %s\n", topic, err)
+		topics = append(topics, topic)
+	}
+
+	sort.Strings(topics)
+
+	return topics
+}
+
+// inSync returns an error describing the first partition found with target
+// replicas missing from its ISR, or if the ISR state couldn't be fetched.
+func (s *isrSyncTracker) inSync(topic string, partitions map[int][]int) error {
+	state, err := s.getTopicStateISR(topic)
+	if err != nil {
+		return fmt.Errorf("error fetching ISR state: %s", err)
+	}
+
+	var ids []int
+	for p := range partitions {
+		ids = append(ids, p)
+	}
+	sort.Ints(ids)
+
+	for _, p := range ids {
+		isr := state[strconv.Itoa(p)].ISR
+		for _, b := range partitions[p] {
+			if !inInts(b, isr) {
+				return fmt.Errorf("partition %d replica %d not in ISR %v", p, b, isr)
+			}
+		}
+	}
+
+	return nil
+}
+
+func inInts(i int, s []int) bool {
+	for _, v := range s {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package autothrottle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/k8s"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+var (
+	// kubernetesRequestTimeout is the timeout for Kubernetes API requests.
+	kubernetesRequestTimeout = 30 * time.Second
+)
+
+// KubernetesConfig holds Kubernetes operator mode configurations. In operator
+// mode, the pause state, throttle overrides and capacity map are read from a
+// ConfigMap at each interval rather than set through the admin API, and the
+// autothrottle status is written back to the ConfigMap.
+type KubernetesConfig struct {
+	// The ConfigMap in namespace/name form. If the namespace is omitted, the
+	// namespace autothrottle is running in is used. Operator mode is disabled
+	// if unset.
+	ConfigMap string
+}
+
+// configMapClient reads and updates ConfigMaps.
+type configMapClient interface {
+	GetConfigMap(ctx context.Context, namespace, name string) (*k8s.ConfigMap, error)
+	PatchConfigMapData(ctx context.Context, namespace, name string, data map[string]string) error
+}
+
+// operator syncs the desired state declared in a ConfigMap into the
+// autothrottle state store and writes status back to the ConfigMap.
+type operator struct {
+	client          configMapClient
+	namespace, name string
+	zk              kafkazk.Handler
+	// The admin API that the declared capacity map is reported to.
+	api *api.API
+	// The Limits used by the ThrottleManager, updated in place with the
+	// declared capacity map.
+	lim replication.Limits
+	// The statically configured capacity map, restored for instance types
+	// removed from the declared capacity map.
+	baseCapacity replication.CapacityMap
+	// Instance types set from the declared capacity map.
+	capacityKeys map[string]struct{}
+	// The time the ConfigMap was most recently applied without error.
+	applied time.Time
+	// The error from the most recent sync, if any.
+	err error
+}
+
+// newOperator takes a configMapClient, a ConfigMap in namespace/name form, a
+// kafkazk.Handler, the admin API, the replication.Limits in use and the
+// statically configured capacity map and returns an *operator.
+func newOperator(c configMapClient, configMap string, zk kafkazk.Handler, a *api.API, lim replication.Limits, capacity replication.CapacityMap) (*operator, error) {
+	namespace, name := "", configMap
+	if i := strings.Index(configMap, "/"); i >= 0 {
+		namespace, name = configMap[:i], configMap[i+1:]
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("invalid ConfigMap %q", configMap)
+	}
+
+	if namespace == "" {
+		ns, err := k8s.InClusterNamespace()
+		if err != nil {
+			return nil, err
+		}
+		namespace = ns
+	}
+
+	return &operator{
+		client:       c,
+		namespace:    namespace,
+		name:         name,
+		zk:           zk,
+		api:          a,
+		lim:          lim,
+		baseCapacity: capacity,
+		capacityKeys: map[string]struct{}{},
+	}, nil
+}
+
+// sync fetches the ConfigMap and applies the Spec it declares. On a failure,
+// the error is logged and reported in the next status update; the previously
+// applied state remains in effect.
+func (o *operator) sync(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, kubernetesRequestTimeout)
+	defer cancel()
+
+	o.err = nil
+
+	cm, err := o.client.GetConfigMap(ctx, o.namespace, o.name)
+	if err != nil {
+		o.err = fmt.Errorf("error fetching ConfigMap %s/%s: %s", o.namespace, o.name, err)
+		log.Println(o.err)
+		return
+	}
+
+	spec, err := k8s.ParseSpec(cm.Data)
+	if err != nil {
+		o.err = fmt.Errorf("error parsing ConfigMap %s/%s: %s", o.namespace, o.name, err)
+		log.Println(o.err)
+		return
+	}
+
+	if err := o.apply(spec); err != nil {
+		o.err = fmt.Errorf("error applying ConfigMap %s/%s: %s", o.namespace, o.name, err)
+		log.Println(o.err)
+		return
+	}
+
+	o.applied = time.Now()
+}
+
+// apply stores the Spec pause state and throttle overrides and updates the
+// Limits capacities. Only state that differs from the Spec is written.
+func (o *operator) apply(s k8s.Spec) error {
+	// Pause state.
+	pauseCfg, err := throttlestore.FetchPauseConfig(o.zk, o.api.Paths.Pause)
+	if err != nil {
+		return err
+	}
+
+	if pauseCfg.Paused != s.Paused {
+		c := throttlestore.PauseConfig{Paused: s.Paused}
+		if s.Paused {
+			c.Since = time.Now().Unix()
+		}
+
+		if err := throttlestore.StorePauseConfig(o.zk, o.api.Paths.Pause, c); err != nil {
+			return err
+		}
+		log.Printf("ConfigMap pause state set to %t\n", s.Paused)
+	}
+
+	// Global throttle override.
+	overrideCfg, err := throttlestore.FetchThrottleOverride(o.zk, o.api.Paths.OverrideRate)
+	if err != nil && err != throttlestore.ErrNoOverrideSet {
+		return err
+	}
+
+	var override throttlestore.ThrottleOverrideConfig
+	if s.Override != nil {
+		override.Rate = s.Override.Rate
+	}
+
+	if !reflect.DeepEqual(*overrideCfg, override) {
+		if err := throttlestore.StoreThrottleOverride(o.zk, o.api.Paths.OverrideRate, override); err != nil {
+			return err
+		}
+		log.Printf("ConfigMap global throttle override set to %dMB/s\n", override.Rate)
+	}
+
+	// Broker-specific throttle overrides.
+	bo, err := throttlestore.FetchBrokerOverrides(o.zk, o.api.Paths.OverrideRate)
+	if err != nil {
+		return err
+	}
+
+	for id, so := range s.BrokerOverrides {
+		c := throttlestore.ThrottleOverrideConfig{Rate: so.Rate}
+		if current, exists := bo[id]; exists && reflect.DeepEqual(current.Config, c) {
+			continue
+		}
+
+		path := fmt.Sprintf("%s/%d", o.api.Paths.OverrideRate, id)
+		if err := throttlestore.StoreThrottleOverride(o.zk, path, c); err != nil {
+			return err
+		}
+		log.Printf("ConfigMap throttle override for broker %d set to %dMB/s\n", id, c.Rate)
+	}
+
+	// Overrides no longer declared are set to 0, marking them for removal.
+	for id, current := range bo {
+		if _, declared := s.BrokerOverrides[id]; declared || current.Config.Rate == 0 {
+			continue
+		}
+
+		path := fmt.Sprintf("%s/%d", o.api.Paths.OverrideRate, id)
+		if err := throttlestore.StoreThrottleOverride(o.zk, path, throttlestore.ThrottleOverrideConfig{}); err != nil {
+			return err
+		}
+		log.Printf("ConfigMap throttle override for broker %d removed\n", id)
+	}
+
+	// Capacities.
+	var changed bool
+
+	for k := range o.capacityKeys {
+		if _, declared := s.CapacityMap[k]; declared {
+			continue
+		}
+
+		if v, exists := o.baseCapacity[k]; exists {
+			o.lim.SetCapacity(k, v)
+		} else {
+			o.lim.DeleteCapacity(k)
+		}
+
+		delete(o.capacityKeys, k)
+		changed = true
+	}
+
+	for k, v := range s.CapacityMap {
+		if current, exists := o.lim.Capacity(k); !exists || current != v {
+			o.lim.SetCapacity(k, v)
+			changed = true
+		}
+		o.capacityKeys[k] = struct{}{}
+	}
+
+	if changed {
+		log.Println("ConfigMap capacity map applied")
+		o.api.SetLimits(o.lim)
+	}
+
+	return nil
+}
+
+// writeStatus writes the api.Status and any sync error to the ConfigMap.
+func (o *operator) writeStatus(ctx context.Context, s api.Status) {
+	ctx, cancel := context.WithTimeout(ctx, kubernetesRequestTimeout)
+	defer cancel()
+
+	status := k8s.Status{
+		Applied:            o.applied,
+		Paused:             s.Paused,
+		ReassigningTopics:  s.ReassigningTopics,
+		ReassigningBrokers: s.ReassigningBrokers,
+		GuardrailsTripped:  s.GuardrailsTripped,
+		Throttles:          s.Throttles,
+		DroppedEvents:      s.DroppedEvents,
+		Updated:            s.Updated,
+	}
+
+	if o.err != nil {
+		status.Error = o.err.Error()
+	}
+
+	for _, rs := range s.Sessions {
+		status.Sessions = append(status.Sessions, k8s.ReassignmentSession{
+			ID:      rs.ID,
+			Started: rs.Started,
+			Topics:  rs.Topics,
+		})
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		log.Printf("Error marshalling ConfigMap status: %s\n", err)
+		return
+	}
+
+	if err := o.client.PatchConfigMapData(ctx, o.namespace, o.name, map[string]string{k8s.KeyStatus: string(data)}); err != nil {
+		log.Printf("Error writing ConfigMap %s/%s status: %s\n", o.namespace, o.name, err)
+	}
+}
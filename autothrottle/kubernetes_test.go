@@ -0,0 +1,202 @@
+package autothrottle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/k8s"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// configMapStub is a configMapClient that serves a single ConfigMap.
+type configMapStub struct {
+	data map[string]string
+	err  error
+}
+
+func (c *configMapStub) GetConfigMap(_ context.Context, namespace, name string) (*k8s.ConfigMap, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	data := map[string]string{}
+	for k, v := range c.data {
+		data[k] = v
+	}
+
+	return &k8s.ConfigMap{
+		Metadata: k8s.ObjectMeta{Name: name, Namespace: namespace},
+		Data:     data,
+	}, nil
+}
+
+func (c *configMapStub) PatchConfigMapData(_ context.Context, _, _ string, data map[string]string) error {
+	for k, v := range data {
+		c.data[k] = v
+	}
+
+	return nil
+}
+
+func newTestOperator(t *testing.T, c configMapClient) (*operator, kafkazk.Handler) {
+	zk := kafkazk.NewZooKeeperStub()
+	a := api.New(zk, "autothrottle", nil)
+	if err := api.InitZnodes(zk, a.Paths, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	lim := replication.Limits{"minimum": 10, "srcMax": 90, "dstMax": 90, "base": 100}
+
+	op, err := newOperator(c, "kafka/autothrottle", zk, a, lim, replication.CapacityMap{"base": {TX: 100, RX: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return op, zk
+}
+
+func TestNewOperatorInvalidConfigMap(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	if _, err := newOperator(&configMapStub{}, "kafka/", zk, api.New(zk, "autothrottle", nil), replication.Limits{}, nil); err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
+
+func TestOperatorSync(t *testing.T) {
+	cm := &configMapStub{data: map[string]string{
+		k8s.KeyPaused:          "true",
+		k8s.KeyOverride:        `{"rate": 50}`,
+		k8s.KeyBrokerOverrides: `{"1001": {"rate": 20}, "1002": {"rate": 30}}`,
+		k8s.KeyCapacityMap:     `{"base": {"tx": 200, "rx": 150}, "new": 300}`,
+	}}
+
+	op, zk := newTestOperator(t, cm)
+	op.sync(context.Background())
+
+	if op.err != nil {
+		t.Fatalf("Unexpected error: %s", op.err)
+	}
+
+	pause, _ := throttlestore.FetchPauseConfig(zk, op.api.Paths.Pause)
+	if !pause.Paused {
+		t.Error("Expected paused")
+	}
+
+	override, _ := throttlestore.FetchThrottleOverride(zk, op.api.Paths.OverrideRate)
+	if override.Rate != 50 {
+		t.Errorf("Expected global override rate 50, got %d", override.Rate)
+	}
+
+	bo, _ := throttlestore.FetchBrokerOverrides(zk, op.api.Paths.OverrideRate)
+	if bo[1001].Config.Rate != 20 || bo[1002].Config.Rate != 30 {
+		t.Errorf("Unexpected broker overrides %v", bo)
+	}
+
+	if c, _ := op.lim.Capacity("base"); c.TX != 200 || c.RX != 150 {
+		t.Errorf("Unexpected limits %v", op.lim)
+	}
+
+	if op.lim["new"] != 300 {
+		t.Errorf("Unexpected limits %v", op.lim)
+	}
+
+	// Remove everything from the ConfigMap.
+	cm.data = map[string]string{}
+	op.sync(context.Background())
+
+	pause, _ = throttlestore.FetchPauseConfig(zk, op.api.Paths.Pause)
+	if pause.Paused {
+		t.Error("Expected unpaused")
+	}
+
+	override, _ = throttlestore.FetchThrottleOverride(zk, op.api.Paths.OverrideRate)
+	if override.Rate != 0 {
+		t.Errorf("Expected global override rate 0, got %d", override.Rate)
+	}
+
+	// Undeclared broker overrides are marked for removal.
+	bo, _ = throttlestore.FetchBrokerOverrides(zk, op.api.Paths.OverrideRate)
+	if bo[1001].Config.Rate != 0 || bo[1002].Config.Rate != 0 {
+		t.Errorf("Expected broker overrides marked for removal, got %v", bo)
+	}
+
+	// Statically configured capacities are restored.
+	if c, _ := op.lim.Capacity("base"); c.TX != 100 || c.RX != 100 {
+		t.Errorf("Expected base capacity 100, got %v", c)
+	}
+
+	if _, exists := op.lim["new"]; exists {
+		t.Error("Expected new capacity to be removed")
+	}
+}
+
+func TestOperatorSyncError(t *testing.T) {
+	cm := &configMapStub{data: map[string]string{k8s.KeyPaused: "true"}}
+
+	op, zk := newTestOperator(t, cm)
+	op.sync(context.Background())
+
+	// An invalid spec leaves the previously applied state in place.
+	cm.data[k8s.KeyPaused] = "maybe"
+	op.sync(context.Background())
+
+	if op.err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	pause, _ := throttlestore.FetchPauseConfig(zk, op.api.Paths.Pause)
+	if !pause.Paused {
+		t.Error("Expected paused")
+	}
+
+	cm.err = errors.New("unavailable")
+	op.sync(context.Background())
+
+	if op.err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
+
+func TestOperatorWriteStatus(t *testing.T) {
+	cm := &configMapStub{data: map[string]string{k8s.KeyPaused: "maybe"}}
+
+	op, _ := newTestOperator(t, cm)
+	op.sync(context.Background())
+
+	rate := 50.0
+	op.writeStatus(context.Background(), api.Status{
+		ReassigningTopics:  []string{"test1"},
+		ReassigningBrokers: []int{1001},
+		Throttles:          map[int][2]*float64{1001: {&rate, nil}},
+		Sessions: []api.ReassignmentSession{
+			{ID: "abc", Started: time.Unix(0, 0), Topics: []string{"test1"}},
+		},
+	})
+
+	var status k8s.Status
+	if err := json.Unmarshal([]byte(cm.data[k8s.KeyStatus]), &status); err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Error == "" {
+		t.Error("Expected status error")
+	}
+
+	if len(status.ReassigningTopics) != 1 || status.ReassigningTopics[0] != "test1" {
+		t.Errorf("Unexpected reassigning topics %v", status.ReassigningTopics)
+	}
+
+	if r := status.Throttles[1001]; r[0] == nil || *r[0] != 50 || r[1] != nil {
+		t.Errorf("Unexpected throttles %v", status.Throttles)
+	}
+
+	if len(status.Sessions) != 1 || status.Sessions[0].ID != "abc" {
+		t.Errorf("Unexpected sessions %v", status.Sessions)
+	}
+}
@@ -0,0 +1,162 @@
+package autothrottle
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// managerLockPath is the znode of the advisory lock shared with other
+// throttle managers.
+const managerLockPath = "/throttle-manager-lock"
+
+// managerLease is the value of the manager lock znode. Other throttle
+// managers participate by writing a lease under their own owner name while
+// the lock is free or expired, renewing it while they manage throttles, and
+// deleting it when done.
+type managerLease struct {
+	Owner string `json:"owner"`
+	// The Unix time in seconds that the lease expires unless renewed.
+	Expires int64 `json:"expires"`
+}
+
+// expired returns whether the lease has expired as of the time.
+func (l managerLease) expired(now time.Time) bool {
+	return now.Unix() >= l.Expires
+}
+
+// managerLock is an advisory lock, held as a lease in ZooKeeper, that
+// autothrottle must hold to write throttles.
+type managerLock struct {
+	zk    kafkazk.Handler
+	owner string
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// acquire acquires the lock if it's free, expired or already held by the
+// owner, renewing the lease. Leases are replaced conditionally on the znode
+// version that was read, so that a lease written concurrently by another
+// manager is never overwritten. It returns whether the lock is held and, if
+// not, the lease of the current holder.
+func (l *managerLock) acquire() (bool, managerLease, error) {
+	now := l.now()
+	lease := managerLease{Owner: l.owner, Expires: now.Add(l.ttl).Unix()}
+
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return false, managerLease{}, err
+	}
+
+	exists, err := l.zk.Exists(managerLockPath)
+	if err != nil {
+		return false, managerLease{}, err
+	}
+
+	// The lock is free. Creating the znode fails if another manager created it
+	// in the meantime.
+	if !exists {
+		if err := l.zk.Create(managerLockPath, string(data)); err != nil {
+			return false, managerLease{}, err
+		}
+		return true, managerLease{}, nil
+	}
+
+	current, version, err := l.current()
+	if err != nil {
+		return false, managerLease{}, err
+	}
+
+	if current.Owner != l.owner && !current.expired(now) {
+		return false, current, nil
+	}
+
+	err = l.zk.SetWithVersion(managerLockPath, string(data), version)
+	switch err.(type) {
+	case nil:
+		return true, managerLease{}, nil
+	case kafkazk.ErrBadVersion:
+		// The lease was replaced since it was read; report the new holder.
+		current, _, err := l.current()
+		return false, current, err
+	default:
+		return false, managerLease{}, err
+	}
+}
+
+// release deletes the lease if it's held by the owner. A lease replaced since
+// it was read isn't deleted.
+func (l *managerLock) release() error {
+	if exists, err := l.zk.Exists(managerLockPath); err != nil || !exists {
+		return err
+	}
+
+	current, version, err := l.current()
+	if err != nil {
+		return err
+	}
+
+	if current.Owner != l.owner {
+		return nil
+	}
+
+	err = l.zk.DeleteWithVersion(managerLockPath, version)
+	switch err.(type) {
+	case nil, kafkazk.ErrNoNode, kafkazk.ErrBadVersion:
+		return nil
+	default:
+		return err
+	}
+}
+
+// current returns the stored lease and the znode version. An error is
+// returned for leases that can't be decoded, so that an unrecognized writer
+// is never overridden.
+func (l *managerLock) current() (managerLease, int32, error) {
+	data, version, err := l.zk.GetWithVersion(managerLockPath)
+	if err != nil {
+		return managerLease{}, 0, err
+	}
+
+	var lease managerLease
+	if err := json.Unmarshal(data, &lease); err != nil || lease.Owner == "" {
+		return managerLease{}, 0, fmt.Errorf("unrecognized lock value %q", data)
+	}
+
+	return lease, version, nil
+}
+
+// checkManagerLock acquires or renews the throttle manager lock, updating
+// whether throttle writes are suspended. A critical event is written when
+// another throttle manager is found holding the lock, and an event when the
+// lock is acquired after being held by another. If the lock state can't be
+// read, writes are suspended without an event.
+func (c *controller) checkManagerLock() {
+	held, holder, err := c.lock.acquire()
+
+	switch {
+	case err != nil:
+		log.Printf("Error acquiring the throttle manager lock, throttle changes are suspended: %s\n", err)
+		c.lockHeld = false
+		return
+	case held && c.lockHolder != "":
+		m := fmt.Sprintf("Throttle manager lock acquired after being held by %s; throttle changes are resumed", c.lockHolder)
+		log.Println(m)
+		c.events.Write("Throttle manager lock acquired", m)
+	case held && !c.lockHeld:
+		log.Println("Throttle manager lock acquired")
+	case !held && holder.Owner != c.lockHolder:
+		m := fmt.Sprintf("The throttle manager lock is held by %s until %s; no throttle changes will be applied until it's released",
+			holder.Owner, time.Unix(holder.Expires, 0).UTC().Format(time.RFC3339))
+		log.Println(m)
+		c.events.WriteCritical("Throttle manager lock held by another manager", m)
+	case !held:
+		log.Printf("The throttle manager lock is held by %s, throttle changes are suspended\n", holder.Owner)
+	}
+
+	c.lockHeld = held
+	c.lockHolder = holder.Owner
+}
@@ -0,0 +1,171 @@
+package autothrottle
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestManagerLock(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	now := time.Unix(1700000000, 0)
+	l := &managerLock{zk: zk, owner: "autothrottle", ttl: time.Minute, now: func() time.Time { return now }}
+
+	setLease := func(owner string, expires time.Time) {
+		data, _ := json.Marshal(managerLease{Owner: owner, Expires: expires.Unix()})
+		zk.Set(managerLockPath, string(data))
+	}
+
+	tests := []struct {
+		lease    func()
+		held     bool
+		holder   string
+		hasError bool
+	}{
+		// Free.
+		{func() {}, true, "", false},
+		// Held by the owner.
+		{func() { setLease("autothrottle", now.Add(time.Second)) }, true, "", false},
+		// Held by another.
+		{func() { setLease("cruise-control", now.Add(time.Second)) }, false, "cruise-control", false},
+		// Expired.
+		{func() { setLease("cruise-control", now) }, true, "", false},
+		// Unrecognized.
+		{func() { zk.Set(managerLockPath, "locked") }, false, "", true},
+	}
+
+	for i, test := range tests {
+		test.lease()
+
+		held, holder, err := l.acquire()
+		if (err != nil) != test.hasError {
+			t.Fatalf("[test %d] Unexpected error: %v", i, err)
+		}
+
+		if held != test.held || holder.Owner != test.holder {
+			t.Errorf("[test %d] Expected held==%v by %q, got held==%v by %q", i, test.held, test.holder, held, holder.Owner)
+		}
+
+		if held {
+			lease, _, _ := l.current()
+			if lease.Owner != "autothrottle" || lease.Expires != now.Add(time.Minute).Unix() {
+				t.Errorf("[test %d] Expected the lease to be renewed, got %+v", i, lease)
+			}
+		}
+	}
+
+	// Leases of other owners aren't released.
+	setLease("cruise-control", now.Add(time.Second))
+	if err := l.release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, _ := zk.Exists(managerLockPath); !exists {
+		t.Error("Expected the lease of another owner to be retained")
+	}
+}
+
+// racingZK is a Handler that runs race once after the first versioned read,
+// simulating a concurrent write by another manager.
+type racingZK struct {
+	*kafkazk.Stub
+	race func()
+}
+
+func (z *racingZK) GetWithVersion(p string) ([]byte, int32, error) {
+	d, v, err := z.Stub.GetWithVersion(p)
+	if z.race != nil {
+		z.race()
+		z.race = nil
+	}
+
+	return d, v, err
+}
+
+func TestManagerLockConcurrentWrites(t *testing.T) {
+	stub := kafkazk.NewZooKeeperStub()
+	zk := &racingZK{Stub: stub}
+	now := time.Unix(1700000000, 0)
+	l := &managerLock{zk: zk, owner: "autothrottle", ttl: time.Minute, now: func() time.Time { return now }}
+
+	setLease := func(owner string, expires time.Time) {
+		data, _ := json.Marshal(managerLease{Owner: owner, Expires: expires.Unix()})
+		stub.Set(managerLockPath, string(data))
+	}
+
+	// Another manager renews its expired lease between the read and write.
+	setLease("cruise-control", now)
+	zk.race = func() { setLease("cruise-control", now.Add(time.Minute)) }
+
+	held, holder, err := l.acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if held || holder.Owner != "cruise-control" {
+		t.Errorf("Expected the lock held by cruise-control, got held==%v by %q", held, holder.Owner)
+	}
+
+	if lease, _, _ := l.current(); lease.Owner != "cruise-control" {
+		t.Errorf("Expected the lease of cruise-control to be retained, got %+v", lease)
+	}
+
+	// Another manager acquires the lock between the read and delete.
+	setLease("autothrottle", now)
+	zk.race = func() { setLease("cruise-control", now.Add(time.Minute)) }
+
+	if err := l.release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if lease, _, _ := l.current(); lease.Owner != "cruise-control" {
+		t.Errorf("Expected the lease of cruise-control to be retained, got %+v", lease)
+	}
+}
+
+func TestControllerManagerLock(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60})
+	tc.lock = &managerLock{zk: tc.zk, owner: "autothrottle", ttl: time.Hour, now: func() time.Time { return tc.clock }}
+
+	tc.tickAfter(t, 0, "test1")
+
+	if !tc.lockHeld {
+		t.Fatal("Expected the lock to be held")
+	}
+
+	data, _ := json.Marshal(managerLease{Owner: "cruise-control", Expires: tc.clock.Add(5 * time.Minute).Unix()})
+	tc.zk.Set(managerLockPath, string(data))
+	tc.zk.ResetKafkaConfigUpdates()
+
+	// Throttles aren't removed while another manager holds the lock.
+	tc.tickAfter(t, time.Minute)
+
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	if !tc.events.has("Throttle manager lock held by another manager") {
+		t.Errorf("Expected a lock held event, got %v", tc.events.titles)
+	}
+
+	// The event isn't repeated.
+	tc.events.reset()
+	tc.tickAfter(t, time.Minute)
+
+	if tc.events.has("Throttle manager lock held by another manager") {
+		t.Error("Expected no repeated lock held event")
+	}
+
+	// The lock is acquired once the lease expires.
+	tc.tickAfter(t, 5*time.Minute)
+
+	if !tc.lockHeld || !tc.events.has("Throttle manager lock acquired") {
+		t.Errorf("Expected the lock to be acquired, got events %v", tc.events.titles)
+	}
+
+	if len(tc.zk.KafkaConfigUpdates()) == 0 {
+		t.Error("Expected throttle removal config updates")
+	}
+}
@@ -0,0 +1,63 @@
+package autothrottle
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// canPostMetrics returns whether the metrics handler can submit metrics.
+func canPostMetrics(h kafkametrics.Handler) bool {
+	_, ok := h.(kafkametrics.MetricsPoster)
+	return ok
+}
+
+// postMetrics takes the start time of the interval and submits the applied
+// throttle rates, the replication headroom of each broker and the interval
+// duration. Failed submissions are logged and not retried.
+func (c *controller) postMetrics(start time.Time) {
+	now := c.now()
+
+	metric := func(name string, v float64, tags ...string) *kafkametrics.Metric {
+		return &kafkametrics.Metric{
+			Name:  name,
+			Value: v,
+			Time:  now,
+			Tags:  append(append([]string{}, c.metricsTags...), tags...),
+		}
+	}
+
+	var metrics []*kafkametrics.Metric
+
+	for _, rates := range []struct {
+		name  string
+		rates replication.ReplicationCapacityByBroker
+	}{
+		{"autothrottle.throttle.rate", c.tm.GetPreviousThrottles()},
+		{"autothrottle.replication.headroom", c.tm.Headroom()},
+	} {
+		ids := make([]int, 0, len(rates.rates))
+		for id := range rates.rates {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+
+		for _, id := range ids {
+			for i, role := range []string{"leader", "follower"} {
+				if r := rates.rates[id][i]; r != nil {
+					metrics = append(metrics, metric(rates.name, *r, fmt.Sprintf("broker_id:%d", id), "role:"+role))
+				}
+			}
+		}
+	}
+
+	metrics = append(metrics, metric("autothrottle.interval.duration", now.Sub(start).Seconds()))
+
+	if err := c.metrics.PostMetrics(metrics); err != nil {
+		log.Printf("Error submitting metrics: %s\n", err)
+	}
+}
@@ -0,0 +1,66 @@
+package autothrottle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+func TestControllerPostMetrics(t *testing.T) {
+	km := kafkametrics.NewStub()
+	tc := newTestController(t, Config{
+		CleanupAfter:  60,
+		Metrics:       km,
+		SubmitMetrics: true,
+		MetricsTags:   []string{"name:kafka-autothrottle"},
+	})
+
+	override := throttlestore.ThrottleOverrideConfig{Rate: 50}
+	if err := throttlestore.StoreThrottleOverride(tc.zk, tc.api.Paths.OverrideRate, override); err != nil {
+		t.Fatal(err)
+	}
+
+	tc.tickAfter(t, 0, "test1")
+
+	var rates, durations int
+	for _, m := range km.Metrics() {
+		if !m.Time.Equal(tc.clock) {
+			t.Errorf("Expected metric %s at %s, got %s", m.Name, tc.clock, m.Time)
+		}
+
+		if len(m.Tags) == 0 || m.Tags[0] != "name:kafka-autothrottle" {
+			t.Errorf("Expected metric %s tagged name:kafka-autothrottle, got %v", m.Name, m.Tags)
+		}
+
+		switch m.Name {
+		case "autothrottle.throttle.rate":
+			rates++
+			if m.Value != 50 || len(m.Tags) != 3 {
+				t.Errorf("Expected a rate of 50 tagged by broker and role, got %.2f %v", m.Value, m.Tags)
+			}
+		case "autothrottle.interval.duration":
+			durations++
+		case "autothrottle.replication.headroom":
+			// Headroom isn't computed while an override is set.
+			t.Errorf("Unexpected headroom metric %+v", m)
+		}
+	}
+
+	if rates == 0 {
+		t.Error("Expected applied rate metrics")
+	}
+
+	if durations != 1 {
+		t.Errorf("Expected 1 interval duration metric, got %d", durations)
+	}
+
+	// Metrics are submitted each interval.
+	n := len(km.Metrics())
+	tc.tickAfter(t, time.Minute)
+
+	if len(km.Metrics()) <= n {
+		t.Error("Expected metrics submitted in the second interval")
+	}
+}
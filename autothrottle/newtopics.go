@@ -0,0 +1,132 @@
+package autothrottle
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+var allTopics = []*regexp.Regexp{regexp.MustCompile(".*")}
+
+// newTopicTracker detects topics created since startup and, for the first
+// window after each is first seen, returns the partitions with replicas not
+// yet in the ISR. New topics created with replicas on busy brokers can
+// saturate networks during the initial replica catch-up in the same way as
+// reassignments; the lagging partitions are throttled as if they were being
+// reassigned to their current replicas.
+type newTopicTracker struct {
+	// The period after creation that new topics are throttled; detection is
+	// disabled if 0.
+	window time.Duration
+	// Returns all topic names.
+	getTopics func() ([]string, error)
+	// Returns the replica assignments and the ISR state for a topic.
+	getTopicMetadata func(string) (kafkazk.TopicMetadata, error)
+	getTopicStateISR func(string) (kafkazk.TopicStateISR, error)
+
+	// The topics seen as of the previous call; nil until the first call.
+	known set
+	// When each new topic within the window was first seen.
+	created map[string]time.Time
+}
+
+func newNewTopicTracker(window time.Duration, zk kafkazk.Handler) *newTopicTracker {
+	return &newTopicTracker{
+		window:           window,
+		getTopics:        func() ([]string, error) { return zk.GetTopics(allTopics) },
+		getTopicMetadata: zk.GetTopicMetadata,
+		getTopicStateISR: zk.GetTopicStateISR,
+		created:          map[string]time.Time{},
+	}
+}
+
+// lagging takes the current time and returns the partitions of new topics
+// with replicas not in the ISR as a kafkazk.Reassignments, where the target
+// replicas are the current replicas. Topics existing in the first call are
+// never considered new.
+func (n *newTopicTracker) lagging(t time.Time) kafkazk.Reassignments {
+	r := kafkazk.Reassignments{}
+
+	if n.window <= 0 {
+		return r
+	}
+
+	topics, err := n.getTopics()
+	if err != nil {
+		log.Printf("Error listing topics for new topic detection: %s\n", err)
+		return r
+	}
+
+	current := newSet()
+	for _, topic := range topics {
+		current.add(topic)
+	}
+
+	// Topics existing at startup are the baseline.
+	if n.known == nil {
+		n.known = current
+		return r
+	}
+
+	for _, topic := range current.diff(n.known).keys() {
+		n.created[topic] = t
+	}
+
+	// Deleted topics are considered new if recreated.
+	n.known = current
+
+	var names []string
+	for topic, c := range n.created {
+		if !current.has(topic) || t.Sub(c) >= n.window {
+			delete(n.created, topic)
+			continue
+		}
+		names = append(names, topic)
+	}
+	sort.Strings(names)
+
+	for _, topic := range names {
+		partitions, err := n.laggingPartitions(topic)
+		if err != nil {
+			log.Printf("Error checking replicas of new topic %s: %s\n", topic, err)
+			continue
+		}
+
+		if len(partitions) > 0 {
+			r[topic] = partitions
+		}
+	}
+
+	return r
+}
+
+// laggingPartitions returns the replicas of each partition of the topic with
+// any replicas not in the ISR.
+func (n *newTopicTracker) laggingPartitions(topic string) (map[int][]int, error) {
+	meta, err := n.getTopicMetadata(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := n.getTopicStateISR(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := map[int][]int{}
+	for p, replicas := range meta.Partitions {
+		isr := state[strconv.Itoa(p)].ISR
+		for _, b := range replicas {
+			if !inInts(b, isr) {
+				partitions[p] = replicas
+				break
+			}
+		}
+	}
+
+	return partitions, nil
+}
@@ -0,0 +1,81 @@
+package autothrottle
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestControllerNewTopicCatchUp(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60, NewTopicCatchUp: 10 * time.Minute})
+
+	topics := []string{"existing"}
+	isr := []int{1001}
+
+	tc.newTopics.getTopics = func() ([]string, error) { return topics, nil }
+	tc.newTopics.getTopicMetadata = func(string) (kafkazk.TopicMetadata, error) {
+		return kafkazk.TopicMetadata{Partitions: map[int][]int{0: {1001, 1002}, 1: {1002, 1001}}}, nil
+	}
+	tc.newTopics.getTopicStateISR = func(string) (kafkazk.TopicStateISR, error) {
+		return kafkazk.TopicStateISR{
+			"0": {Leader: 1001, ISR: isr},
+			"1": {Leader: 1002, ISR: []int{1002, 1001}},
+		}, nil
+	}
+
+	// Topics existing at startup aren't considered new.
+	tc.tickAfter(t, 0)
+
+	if len(tc.topicsReplicatingPreviously) != 0 {
+		t.Errorf("Expected no replicating topics, got %v", tc.topicsReplicatingPreviously.keys())
+	}
+
+	// A new topic with replicas not in the ISR is throttled.
+	topics = append(topics, "new")
+	tc.tickAfter(t, time.Minute)
+
+	if !tc.topicsReplicatingPreviously.has("new") || len(tc.topicsReplicatingPreviously) != 1 {
+		t.Errorf("Expected topic new replicating, got %v", tc.topicsReplicatingPreviously.keys())
+	}
+
+	expected := kafkazk.Reassignments{"new": {0: {1001, 1002}}}
+	if r := tc.newTopics.lagging(tc.clock); !reflect.DeepEqual(r, expected) {
+		t.Errorf("Expected lagging partitions %v, got %v", expected, r)
+	}
+
+	if !tc.knownThrottles {
+		t.Error("Expected knownThrottles to be true")
+	}
+
+	// Once the replicas are in sync, the topic is done replicating.
+	isr = []int{1001, 1002}
+	tc.tickAfter(t, time.Minute)
+
+	if len(tc.topicsReplicatingPreviously) != 0 {
+		t.Errorf("Expected no replicating topics, got %v", tc.topicsReplicatingPreviously.keys())
+	}
+
+	// New topics are no longer tracked once the window elapses.
+	isr = []int{1001}
+	tc.tickAfter(t, 9*time.Minute)
+
+	if len(tc.topicsReplicatingPreviously) != 0 {
+		t.Errorf("Expected no replicating topics, got %v", tc.topicsReplicatingPreviously.keys())
+	}
+
+	if len(tc.newTopics.created) != 0 {
+		t.Errorf("Expected no tracked topics, got %v", tc.newTopics.created)
+	}
+
+	// Deleted topics are new if recreated.
+	topics = []string{"existing"}
+	tc.tickAfter(t, time.Minute)
+	topics = []string{"existing", "new"}
+	tc.tickAfter(t, time.Minute)
+
+	if !tc.topicsReplicatingPreviously.has("new") {
+		t.Errorf("Expected topic new replicating, got %v", tc.topicsReplicatingPreviously.keys())
+	}
+}
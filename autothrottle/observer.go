@@ -0,0 +1,300 @@
+package autothrottle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/prometheus"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// observer runs observe-only intervals. Each call to tick publishes the
+// reassignment state, broker metrics and configured throttles as Prometheus
+// metrics and events; throttles are never computed or applied.
+type observer struct {
+	zk       kafkazk.Handler
+	tm       *replication.ThrottleManager
+	events   EventWriter
+	registry *prometheus.Registry
+	// Returns all ongoing reassignments.
+	getReassignments func() (kafkazk.Reassignments, error)
+	// Returns the current time.
+	now func() time.Time
+
+	sessions    *reassignmentSessions
+	transitions *replicationTransitions
+	// Configured broker throttles as of the previous interval.
+	throttlesPreviously string
+}
+
+// newObserver takes a Config and the initialized dependencies and returns an
+// *observer.
+func newObserver(cfg Config, tm *replication.ThrottleManager, events EventWriter, registry *prometheus.Registry) *observer {
+	return &observer{
+		zk:       cfg.ZK,
+		tm:       tm,
+		events:   events,
+		registry: registry,
+		getReassignments: func() (kafkazk.Reassignments, error) {
+			return getReassignments(cfg.ZK, cfg.KafkaNativeMode)
+		},
+		now:         time.Now,
+		sessions:    newReassignmentSessions(),
+		transitions: newReplicationTransitions(),
+	}
+}
+
+// runObserver takes a Config and runs observe-only intervals until the context
+// is cancelled.
+func runObserver(ctx context.Context, cfg Config, events EventWriter) error {
+	tm, err := replication.NewThrottleManager(replication.ThrottleManagerConfig{
+		KafkaZK:                cfg.ZK,
+		KafkaMetrics:           cfg.Metrics,
+		KafkaNativeMode:        cfg.KafkaNativeMode,
+		KafkaAPIRequestTimeout: cfg.KafkaAPIRequestTimeout,
+		Events:                 events,
+		MetricsTimeout:         cfg.FetchTimeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.KafkaNativeMode {
+		if err := tm.InitKafkaAdmin(cfg.KafkaAdmin); err != nil {
+			return err
+		}
+		log.Printf("Connected to Kafka: %s\n", cfg.KafkaAdmin.BootstrapServers)
+	}
+
+	registry := prometheus.NewRegistry()
+
+	if cfg.MetricsListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", registry)
+		srv := &http.Server{Addr: cfg.MetricsListen, Handler: mux}
+
+		go func() {
+			if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+				log.Printf("Metrics listener error: %s\n", err)
+			}
+		}()
+		defer srv.Close()
+
+		log.Printf("Prometheus metrics: %s/metrics\n", cfg.MetricsListen)
+	}
+
+	log.Println("Observe-only mode; throttles will not be computed or applied")
+
+	o := newObserver(cfg, tm, events, registry)
+
+	timer := newIntervalTimer(cfg.Interval, cfg.IntervalJitter, cfg.AlignInterval)
+	defer timer.stop()
+
+	for {
+		if err := o.tick(); err != nil {
+			log.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C():
+			timer.reset()
+		}
+	}
+}
+
+// tick runs a single observe-only interval. An error is returned if ongoing
+// reassignments couldn't be fetched, in which case nothing is published.
+func (o *observer) tick() error {
+	reassignments, err := o.getReassignments()
+	if err != nil {
+		return fmt.Errorf("error fetching reassignments: %s", err)
+	}
+
+	topicsReplicatingNow := newSet()
+	for t := range reassignments {
+		topicsReplicatingNow.add(t)
+	}
+
+	o.sessions.update(topicsReplicatingNow, o.now()).write(o.events, o.now())
+
+	rb, err := replication.GetReassigningBrokers(reassignments, o.zk)
+	if err != nil {
+		log.Println(err)
+	}
+
+	o.tm.SetReassigningBrokers(rb)
+	o.tm.SetReassignments(reassignments)
+
+	obs, err := o.tm.Observe()
+	if err != nil {
+		log.Printf("Error fetching throttle configs: %s\n", err)
+	}
+
+	if obs.MetricsErrors != nil {
+		log.Printf("Errors fetching metrics: %s\n", obs.MetricsErrors)
+	}
+
+	if len(topicsReplicatingNow) > 0 {
+		log.Printf("Topics with ongoing reassignments: %s\n", topicsReplicatingNow.keys())
+		log.Printf("Source brokers participating in replication: %v\n", obs.SourceBrokers)
+		log.Printf("Destination brokers participating in replication: %v\n", obs.DestinationBrokers)
+	} else {
+		log.Println("No topics undergoing reassignment")
+	}
+
+	// Write an event when the configured throttles change.
+	if throttles := obs.BrokerThrottles.String(); err == nil && throttles != o.throttlesPreviously {
+		m := "No broker throttles configured"
+		if throttles != "" {
+			m = fmt.Sprintf("Configured broker throttles [ID, leader MB/s, follower MB/s]: %s", throttles)
+		}
+
+		log.Println(m)
+		o.events.Write("Observed broker throttles changed", m)
+		o.throttlesPreviously = throttles
+	}
+
+	o.transitions.update(topicsReplicatingNow, o.tm.ReassigningBrokerIDs())
+
+	families := observationFamilies(topicsReplicatingNow, obs, o.now())
+	families = append(families, o.transitions.families()...)
+
+	if d, ok := o.events.(DroppedEventsCounter); ok {
+		families = append(families, droppedEventsFamily(d))
+	}
+
+	o.registry.Update(families)
+
+	return nil
+}
+
+// droppedEventsFamily returns the Prometheus metric family of the events
+// dropped by the EventWriter.
+func droppedEventsFamily(d DroppedEventsCounter) prometheus.Family {
+	return prometheus.Family{
+		Name:    "autothrottle_events_dropped_total",
+		Help:    "The number of events dropped because the event buffer was full.",
+		Type:    "counter",
+		Samples: []prometheus.Sample{{Value: float64(d.DroppedEvents())}},
+	}
+}
+
+// observationFamilies takes the set of reassigning topics, a
+// replication.Observation and the current time and returns the Prometheus
+// metric families to publish.
+func observationFamilies(topics set, obs replication.Observation, t time.Time) []prometheus.Family {
+	reassigningTopics := prometheus.Family{
+		Name: "autothrottle_topic_reassigning",
+		Help: "Topics undergoing reassignment.",
+	}
+
+	for _, topic := range topics.keys() {
+		reassigningTopics.Samples = append(reassigningTopics.Samples, prometheus.Sample{
+			Labels: prometheus.Labels{"topic": topic},
+			Value:  1,
+		})
+	}
+
+	reassigningBrokers := prometheus.Family{
+		Name: "autothrottle_broker_reassigning",
+		Help: "Brokers participating in reassignments by role.",
+	}
+
+	for role, ids := range map[string][]int{"source": obs.SourceBrokers, "destination": obs.DestinationBrokers} {
+		for _, id := range ids {
+			reassigningBrokers.Samples = append(reassigningBrokers.Samples, prometheus.Sample{
+				Labels: prometheus.Labels{"broker": strconv.Itoa(id), "role": role},
+				Value:  1,
+			})
+		}
+	}
+
+	tx := prometheus.Family{
+		Name: "autothrottle_broker_network_transmit_bytes_per_second",
+		Help: "Measured broker outbound network throughput.",
+	}
+	rx := prometheus.Family{
+		Name: "autothrottle_broker_network_receive_bytes_per_second",
+		Help: "Measured broker inbound network throughput.",
+	}
+
+	for id, b := range obs.Metrics {
+		labels := prometheus.Labels{"broker": strconv.Itoa(id), "instance_type": b.InstanceType}
+		tx.Samples = append(tx.Samples, prometheus.Sample{Labels: labels, Value: b.NetTX * 1000000.00})
+		rx.Samples = append(rx.Samples, prometheus.Sample{Labels: labels, Value: b.NetRX * 1000000.00})
+	}
+
+	throttles := prometheus.Family{
+		Name: "autothrottle_broker_throttle_rate_bytes_per_second",
+		Help: "Configured broker replication throttle rates by role.",
+	}
+
+	for id, rates := range obs.BrokerThrottles {
+		for i, role := range []string{"leader", "follower"} {
+			if rates[i] == nil {
+				continue
+			}
+
+			throttles.Samples = append(throttles.Samples, prometheus.Sample{
+				Labels: prometheus.Labels{"broker": strconv.Itoa(id), "role": role},
+				Value:  *rates[i] * 1000000.00,
+			})
+		}
+	}
+
+	throttledReplicas := prometheus.Family{
+		Name: "autothrottle_topic_throttled_replicas_configured",
+		Help: "Whether reassigning topics have throttled replicas configured by role.",
+	}
+
+	var throttledTopics []string
+	for topic := range obs.TopicThrottledReplicas {
+		throttledTopics = append(throttledTopics, topic)
+	}
+	sort.Strings(throttledTopics)
+
+	for _, topic := range throttledTopics {
+		for i, role := range []string{"leader", "follower"} {
+			var v float64
+			if obs.TopicThrottledReplicas[topic][i] != "" {
+				v = 1
+			}
+
+			throttledReplicas.Samples = append(throttledReplicas.Samples, prometheus.Sample{
+				Labels: prometheus.Labels{"topic": topic, "role": role},
+				Value:  v,
+			})
+		}
+	}
+
+	metricsErrors := prometheus.Family{
+		Name:    "autothrottle_metrics_errors",
+		Help:    "The number of errors fetching broker metrics in the last interval.",
+		Samples: []prometheus.Sample{{Value: float64(len(obs.MetricsErrors))}},
+	}
+
+	updated := prometheus.Family{
+		Name:    "autothrottle_last_observation_timestamp_seconds",
+		Help:    "The time of the last observation.",
+		Samples: []prometheus.Sample{{Value: float64(t.Unix())}},
+	}
+
+	return []prometheus.Family{
+		reassigningTopics,
+		reassigningBrokers,
+		tx,
+		rx,
+		throttles,
+		throttledReplicas,
+		metricsErrors,
+		updated,
+	}
+}
@@ -0,0 +1,133 @@
+package autothrottle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/prometheus"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestObserverTick(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	events := &eventsStub{}
+
+	tm, err := replication.NewThrottleManager(replication.ThrottleManagerConfig{
+		KafkaZK:      zk,
+		KafkaMetrics: kafkametrics.NewStub(),
+		Events:       events,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	o := newObserver(Config{ZK: zk}, tm, events, registry)
+
+	reassignments := kafkazk.Reassignments{"test1": {0: {1001, 1002}}}
+	o.getReassignments = func() (kafkazk.Reassignments, error) {
+		return reassignments, nil
+	}
+	o.now = func() time.Time {
+		return time.Unix(1700000000, 0)
+	}
+
+	if err := o.tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !events.has("Reassignment session started") || !events.has("Observed broker throttles changed") {
+		t.Errorf("Unexpected events %v", events.titles)
+	}
+
+	metrics := string(registry.Bytes())
+	expected := []string{
+		`autothrottle_topic_reassigning{topic="test1"} 1`,
+		`autothrottle_broker_reassigning{broker="1000",role="source"} 1`,
+		`autothrottle_broker_network_transmit_bytes_per_second{broker="1001",instance_type="stub"} 1.01e+08`,
+		`autothrottle_broker_throttle_rate_bytes_per_second{broker="1001",role="leader"} 1e+08`,
+		`autothrottle_topic_throttled_replicas_configured{role="leader",topic="test1"} 1`,
+		`autothrottle_metrics_errors 0`,
+		`autothrottle_last_observation_timestamp_seconds 1.7e+09`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(metrics, line+"\n") {
+			t.Errorf("Expected metric %s, got:\n%s", line, metrics)
+		}
+	}
+
+	// Observation never writes configs.
+	if updates := zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	// Unchanged throttles aren't written as events again.
+	events.reset()
+	if err := o.tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events.titles) != 0 {
+		t.Errorf("Expected no events, got %v", events.titles)
+	}
+
+	// The reassignment completes.
+	reassignments = kafkazk.Reassignments{}
+	if err := o.tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !events.has("Reassignment session complete") {
+		t.Errorf("Expected a session complete event, got %v", events.titles)
+	}
+
+	if metrics := string(registry.Bytes()); strings.Contains(metrics, "test1") {
+		t.Errorf("Expected test1 series to be dropped, got:\n%s", metrics)
+	}
+}
+
+// droppingEventsStub is an eventsStub that reports dropped events.
+type droppingEventsStub struct {
+	*eventsStub
+	dropped uint64
+}
+
+func (e droppingEventsStub) DroppedEvents() uint64 { return e.dropped }
+
+func TestObserverDroppedEvents(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	events := droppingEventsStub{eventsStub: &eventsStub{}, dropped: 3}
+
+	tm, err := replication.NewThrottleManager(replication.ThrottleManagerConfig{
+		KafkaZK:      zk,
+		KafkaMetrics: kafkametrics.NewStub(),
+		Events:       events,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	o := newObserver(Config{ZK: zk}, tm, events, registry)
+	o.getReassignments = func() (kafkazk.Reassignments, error) {
+		return kafkazk.Reassignments{}, nil
+	}
+
+	if err := o.tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := string(registry.Bytes())
+	for _, line := range []string{
+		"# TYPE autothrottle_events_dropped_total counter\n",
+		"autothrottle_events_dropped_total 3\n",
+	} {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("Expected %s, got:\n%s", line, metrics)
+		}
+	}
+}
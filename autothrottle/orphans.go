@@ -0,0 +1,69 @@
+package autothrottle
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// scanOrphanedThrottles scans all topic configs for throttled replicas
+// orphaned by finished reassignments every orphanScanIntervals calls. Topics
+// awaiting ISR sync after a reassignment aren't considered orphaned. Orphaned
+// throttles are removed if configured, unless paused. An event is written
+// when throttles are removed or the orphaned topics found change.
+func (c *controller) scanOrphanedThrottles(paused bool) {
+	if c.orphanScanIntervals <= 0 {
+		return
+	}
+
+	c.orphanScanCount++
+	if c.orphanScanCount < c.orphanScanIntervals {
+		return
+	}
+
+	c.orphanScanCount = 0
+
+	found, err := c.tm.FindOrphanedTopicThrottles()
+	if err != nil {
+		log.Printf("Error scanning for orphaned topic throttles: %s\n", err)
+		return
+	}
+
+	var orphaned []string
+	for _, topic := range found {
+		if !c.isrSync.tracked(topic) {
+			orphaned = append(orphaned, topic)
+		}
+	}
+
+	previous := c.orphanedTopics
+	c.orphanedTopics = orphaned
+
+	if len(orphaned) == 0 {
+		log.Println("No orphaned topic throttles found")
+		return
+	}
+
+	var action string
+	switch {
+	case !c.removeOrphanedThrottles:
+		action = "retained"
+	case paused:
+		action = "retained (paused)"
+	default:
+		if err := c.tm.RemoveTopicThrottles(orphaned); err != nil {
+			log.Printf("Error removing orphaned topic throttles: %s\n", err)
+			action = "removal failed"
+		} else {
+			action = "removed"
+			c.orphanedTopics = nil
+		}
+	}
+
+	m := fmt.Sprintf("Orphaned throttled replicas configs found on topics %v were %s", orphaned, action)
+	log.Println(m)
+
+	if action == "removed" || strings.Join(orphaned, ",") != strings.Join(previous, ",") {
+		c.events.Write("Orphaned topic throttles found", m)
+	}
+}
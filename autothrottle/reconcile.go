@@ -0,0 +1,115 @@
+package autothrottle
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// getReassignments returns all ongoing reassignments.
+func getReassignments(zk kafkazk.Handler, kafkaNativeMode bool) (kafkazk.Reassignments, error) {
+	if !kafkaNativeMode {
+		return zk.GetReassignments(), nil
+	}
+
+	// KIP-455 compatible reassignments lookup.
+	return zk.ListReassignments()
+}
+
+// reconcileExistingThrottles finds replication throttles set prior to startup
+// that autothrottle has no record of, reports them through the admin API and
+// either adopts or removes them according to the AdoptExisting setting. They're
+// retained while throttle changes are suspended for the suspended reason, if
+// any. A bool is returned indicating whether any throttles may remain set.
+func reconcileExistingThrottles(cfg Config, tm *replication.ThrottleManager, a *api.API, events EventWriter, suspended string) bool {
+	zk := cfg.ZK
+
+	// Populate the state that existing throttles are checked against.
+	reassignments, err := getReassignments(zk, cfg.KafkaNativeMode)
+	if err != nil {
+		log.Printf("Error fetching reassignments: %s\n", err)
+		return true
+	}
+
+	rb, err := replication.GetReassigningBrokers(reassignments, zk)
+	if err != nil {
+		log.Println(err)
+		return true
+	}
+
+	bo, err := throttlestore.FetchBrokerOverrides(zk, a.Paths.OverrideRate)
+	if err != nil {
+		log.Println(err)
+		return true
+	}
+
+	pins, err := throttlestore.FetchBrokerOverrides(zk, a.Paths.PinnedRate)
+	if err != nil {
+		log.Println(err)
+		return true
+	}
+
+	bo.ApplyPins(pins)
+
+	tm.SetBrokerOverrides(bo)
+	tm.SetReassigningBrokers(rb)
+	tm.SetReassignments(reassignments)
+
+	// If the lookup fails, assume throttles may be set.
+	unknown, err := tm.FindUnknownThrottles()
+	if err != nil {
+		log.Printf("Error looking up existing throttles: %s\n", err)
+		return true
+	}
+
+	if unknown.Empty() {
+		log.Println("No unknown replication throttles found")
+		return false
+	}
+
+	// If the pause state can't be read, throttles are retained and removal is
+	// retried later.
+	pauseCfg, pauseErr := throttlestore.FetchPauseConfig(zk, a.Paths.Pause)
+	if pauseErr != nil {
+		log.Println(pauseErr)
+	}
+
+	var action string
+	switch {
+	case cfg.AdoptExisting:
+		tm.AdoptThrottles(unknown)
+		action = "adopted"
+	case pauseErr != nil:
+		action = "retained (pause state unknown)"
+	case pauseCfg.Paused:
+		action = "retained (paused)"
+	case suspended != "":
+		action = fmt.Sprintf("retained (%s)", suspended)
+	case cfg.SkipAutoDeleteThrottles:
+		action = "retained (skip-auto-delete-throttles)"
+	default:
+		if err := tm.RemoveThrottles(unknown); err != nil {
+			log.Printf("Error removing unknown throttles: %s\n", err)
+			action = "removal failed"
+		} else {
+			action = "removed"
+		}
+	}
+
+	a.SetUnknownThrottles(api.UnknownThrottles{
+		Brokers: unknown.BrokerIDs(),
+		Topics:  unknown.Topics,
+		Action:  action,
+	})
+
+	m := fmt.Sprintf("Unknown replication throttles found on brokers %v and topics %v were %s",
+		unknown.BrokerIDs(), unknown.Topics, action)
+	log.Println(m)
+	events.Write("Unknown replication throttles found", m)
+
+	return action != "removed"
+}
@@ -0,0 +1,28 @@
+package autothrottle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReconcileExistingThrottlesPauseUnknown(t *testing.T) {
+	tc := newTestController(t, Config{})
+
+	// An unreadable pause config must not be treated as un-paused.
+	if err := tc.zk.Set(tc.api.Paths.Pause, "{"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stub has throttled replicas set on topics test_topic and test_topic2.
+	if !reconcileExistingThrottles(Config{ZK: tc.zk}, tc.tm, tc.api, tc.events, "") {
+		t.Error("Expected throttles to be reported as possibly remaining")
+	}
+
+	if updates := tc.zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	if len(tc.events.messages) != 1 || !strings.Contains(tc.events.messages[0], "retained (pause state unknown)") {
+		t.Errorf("Expected a retained event, got %v", tc.events.messages)
+	}
+}
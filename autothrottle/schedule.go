@@ -0,0 +1,81 @@
+package autothrottle
+
+import (
+	"math/rand"
+	"time"
+)
+
+// intervalSchedule determines when intervals start. Intervals start every
+// interval from the base time, which is either the start time or, if aligned,
+// the start time rounded down to a multiple of the interval on the wall
+// clock. Each interval start is delayed by a random duration of up to the
+// jitter so that multiple autothrottle instances don't synchronize their
+// metrics requests and ZooKeeper writes.
+type intervalSchedule struct {
+	interval time.Duration
+	jitter   time.Duration
+	base     time.Time
+	// Returns a random int64 in [0, n).
+	randInt63n func(n int64) int64
+}
+
+func newIntervalSchedule(start time.Time, interval, jitter time.Duration, align bool) *intervalSchedule {
+	if align {
+		start = start.Truncate(interval)
+	}
+
+	return &intervalSchedule{
+		interval:   interval,
+		jitter:     jitter,
+		base:       start,
+		randInt63n: rand.Int63n,
+	}
+}
+
+// next takes the current time and returns the start of the next interval.
+// Intervals that have already passed, e.g. because the previous interval ran
+// longer than the interval duration, are skipped.
+func (s *intervalSchedule) next(now time.Time) time.Time {
+	s.base = s.base.Add(s.interval)
+	for !s.base.After(now) {
+		s.base = s.base.Add(s.interval)
+	}
+
+	if s.jitter <= 0 {
+		return s.base
+	}
+
+	return s.base.Add(time.Duration(s.randInt63n(int64(s.jitter))))
+}
+
+// intervalTimer fires at the start of each interval of an intervalSchedule.
+type intervalTimer struct {
+	schedule *intervalSchedule
+	timer    *time.Timer
+}
+
+func newIntervalTimer(interval, jitter time.Duration, align bool) *intervalTimer {
+	now := time.Now()
+	s := newIntervalSchedule(now, interval, jitter, align)
+
+	return &intervalTimer{
+		schedule: s,
+		timer:    time.NewTimer(s.next(now).Sub(now)),
+	}
+}
+
+// C returns the channel the timer fires on. After receiving from it, reset
+// must be called to schedule the next interval.
+func (t *intervalTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// reset schedules the next interval.
+func (t *intervalTimer) reset() {
+	now := time.Now()
+	t.timer.Reset(t.schedule.next(now).Sub(now))
+}
+
+func (t *intervalTimer) stop() {
+	t.timer.Stop()
+}
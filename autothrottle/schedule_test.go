@@ -0,0 +1,35 @@
+package autothrottle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalSchedule(t *testing.T) {
+	start := time.Date(2020, 2, 28, 0, 1, 17, 0, time.UTC)
+
+	tests := []struct {
+		jitter   time.Duration
+		align    bool
+		expected []string
+	}{
+		{0, false, []string{"00:04:17", "00:07:17", "00:13:17"}},
+		{0, true, []string{"00:03:00", "00:06:00", "00:12:00"}},
+		// The stub random source returns half the jitter.
+		{30 * time.Second, true, []string{"00:03:15", "00:06:15", "00:12:15"}},
+	}
+
+	for i, test := range tests {
+		s := newIntervalSchedule(start, 3*time.Minute, test.jitter, test.align)
+		s.randInt63n = func(n int64) int64 { return n / 2 }
+
+		// The last interval ran long; the interval starting at 00:09 is skipped.
+		now := []time.Time{start, start.Add(3 * time.Minute), start.Add(9 * time.Minute)}
+
+		for j, n := range now {
+			if got := s.next(n).Format("15:04:05"); got != test.expected[j] {
+				t.Errorf("[test %d] Expected interval %d to start at %s, got %s", i, j, test.expected[j], got)
+			}
+		}
+	}
+}
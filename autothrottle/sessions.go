@@ -0,0 +1,183 @@
+package autothrottle
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+)
+
+// reassignmentSession is a set of topics that started reassigning together,
+// identified by a correlation ID.
+type reassignmentSession struct {
+	id      string
+	started time.Time
+	topics  set
+}
+
+// sessionUpdate describes the changes to reassignment sessions between
+// intervals.
+type sessionUpdate struct {
+	// Sessions started in this interval.
+	started []*reassignmentSession
+	// Topics done reassigning by session ID.
+	done map[string][]string
+	// Sessions where all topics are done reassigning.
+	completed []*reassignmentSession
+}
+
+// write logs and writes events for the sessionUpdate; t is the current time.
+func (u sessionUpdate) write(events EventWriter, t time.Time) {
+	for _, s := range u.started {
+		m := fmt.Sprintf("Reassignment session %s started: topics %s", s.id, s.sortedTopics())
+		log.Println(m)
+		events.WriteTagged("Reassignment session started", m, s.tag())
+	}
+
+	var doneIDs []string
+	for id := range u.done {
+		doneIDs = append(doneIDs, id)
+	}
+	sort.Strings(doneIDs)
+
+	for _, id := range doneIDs {
+		m := fmt.Sprintf("Topics done reassigning [session %s]: %s", id, u.done[id])
+		log.Println(m)
+		events.WriteTagged("Topics done reassigning", m, sessionTag(id))
+	}
+
+	for _, s := range u.completed {
+		m := fmt.Sprintf("Reassignment session %s complete after %s", s.id, t.Sub(s.started).Round(time.Second))
+		log.Println(m)
+		events.WriteTagged("Reassignment session complete", m, s.tag())
+	}
+}
+
+// reassignmentSessions tracks reassignment sessions. Topics seen reassigning
+// for the first time in an interval are grouped into a new session, allowing
+// overlapping reassignments to be tracked distinctly in logs, events and the
+// admin API.
+type reassignmentSessions struct {
+	sessions map[string]*reassignmentSession
+	byTopic  map[string]*reassignmentSession
+	newID    func() string
+}
+
+func newReassignmentSessions() *reassignmentSessions {
+	return &reassignmentSessions{
+		sessions: map[string]*reassignmentSession{},
+		byTopic:  map[string]*reassignmentSession{},
+		newID:    newSessionID,
+	}
+}
+
+// update takes the set of topics currently reassigning and returns a
+// sessionUpdate.
+func (r *reassignmentSessions) update(now set, t time.Time) sessionUpdate {
+	u := sessionUpdate{done: map[string][]string{}}
+
+	// Topics no longer reassigning.
+	for topic, s := range r.byTopic {
+		if now.has(topic) {
+			continue
+		}
+
+		delete(r.byTopic, topic)
+		delete(s.topics, topic)
+		u.done[s.id] = append(u.done[s.id], topic)
+
+		if len(s.topics) == 0 {
+			delete(r.sessions, s.id)
+			u.completed = append(u.completed, s)
+		}
+	}
+
+	for id := range u.done {
+		sort.Strings(u.done[id])
+	}
+
+	// Newly reassigning topics.
+	var s *reassignmentSession
+	for topic := range now {
+		if _, exists := r.byTopic[topic]; exists {
+			continue
+		}
+
+		if s == nil {
+			s = &reassignmentSession{id: r.newID(), started: t, topics: newSet()}
+			r.sessions[s.id] = s
+			u.started = append(u.started, s)
+		}
+
+		s.topics.add(topic)
+		r.byTopic[topic] = s
+	}
+
+	sort.Slice(u.completed, func(i, j int) bool {
+		return u.completed[i].id < u.completed[j].id
+	})
+
+	return u
+}
+
+// list returns all active sessions ordered by start time and ID.
+func (r *reassignmentSessions) list() []*reassignmentSession {
+	var l []*reassignmentSession
+	for _, s := range r.sessions {
+		l = append(l, s)
+	}
+
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].started.Equal(l[j].started) {
+			return l[i].id < l[j].id
+		}
+		return l[i].started.Before(l[j].started)
+	})
+
+	return l
+}
+
+// status returns the active sessions as a []api.ReassignmentSession.
+func (r *reassignmentSessions) status() []api.ReassignmentSession {
+	var sessions []api.ReassignmentSession
+	for _, s := range r.list() {
+		sessions = append(sessions, api.ReassignmentSession{
+			ID:      s.id,
+			Started: s.started,
+			Topics:  s.sortedTopics(),
+		})
+	}
+
+	return sessions
+}
+
+// sortedTopics returns the session topics in sorted order.
+func (s *reassignmentSession) sortedTopics() []string {
+	topics := s.topics.keys()
+	sort.Strings(topics)
+	return topics
+}
+
+// tag returns the session event tag.
+func (s *reassignmentSession) tag() string {
+	return sessionTag(s.id)
+}
+
+func sessionTag(id string) string {
+	return fmt.Sprintf("reassignment_session:%s", id)
+}
+
+// newSessionID returns a random 8 character hex ID.
+func newSessionID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to a time based ID.
+		return fmt.Sprintf("%08x", uint32(time.Now().UnixNano()))
+	}
+
+	return hex.EncodeToString(b)
+}
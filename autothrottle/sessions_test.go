@@ -0,0 +1,84 @@
+package autothrottle
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReassignmentSessions(t *testing.T) {
+	r := newReassignmentSessions()
+	var n int
+	r.newID = func() string {
+		n++
+		return fmt.Sprintf("s%d", n)
+	}
+
+	t0 := time.Date(2020, 2, 28, 0, 0, 0, 0, time.UTC)
+	now := newSet()
+	now.add("a")
+	now.add("b")
+
+	// Topics starting together share a session.
+	u := r.update(now, t0)
+
+	if len(u.started) != 1 || u.started[0].id != "s1" || len(u.started[0].topics) != 2 {
+		t.Fatalf("Expected session s1 with 2 topics, got %+v", u.started)
+	}
+
+	// An overlapping reassignment is a distinct session.
+	now.add("c")
+	u = r.update(now, t0.Add(time.Minute))
+
+	if len(u.started) != 1 || u.started[0].id != "s2" || !u.started[0].topics.has("c") {
+		t.Fatalf("Expected session s2 with topic c, got %+v", u.started)
+	}
+
+	if l := r.list(); len(l) != 2 || l[0].id != "s1" || l[1].id != "s2" {
+		t.Errorf("Expected sessions [s1 s2], got %+v", l)
+	}
+
+	// A topic finishing from the first session.
+	delete(now, "a")
+	u = r.update(now, t0.Add(2*time.Minute))
+
+	if len(u.started) != 0 || len(u.completed) != 0 {
+		t.Errorf("Unexpected started or completed sessions: %+v", u)
+	}
+
+	if done := u.done["s1"]; len(done) != 1 || done[0] != "a" {
+		t.Errorf("Expected topic a done for s1, got %v", u.done)
+	}
+
+	// All remaining topics finishing.
+	u = r.update(newSet(), t0.Add(3*time.Minute))
+
+	if len(u.completed) != 2 || u.completed[0].id != "s1" || u.completed[1].id != "s2" {
+		t.Errorf("Expected sessions s1, s2 completed, got %+v", u.completed)
+	}
+
+	if len(r.list()) != 0 {
+		t.Errorf("Expected no active sessions, got %+v", r.list())
+	}
+}
+
+func TestReassignmentSessionsStatus(t *testing.T) {
+	r := newReassignmentSessions()
+	r.newID = func() string { return "a1b2c3d4" }
+
+	now := newSet()
+	now.add("b")
+	now.add("a")
+	r.update(now, time.Now())
+
+	st := r.status()
+	if len(st) != 1 || st[0].ID != "a1b2c3d4" || len(st[0].Topics) != 2 || st[0].Topics[0] != "a" {
+		t.Errorf("Unexpected status %+v", st)
+	}
+}
+
+func TestNewSessionID(t *testing.T) {
+	if id := newSessionID(); len(id) != 8 {
+		t.Errorf("Expected an 8 character ID, got %s", id)
+	}
+}
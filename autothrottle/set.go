@@ -1,4 +1,4 @@
-package main
+package autothrottle
 
 type set map[string]struct{}
 
@@ -0,0 +1,60 @@
+package autothrottle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/kafkastate"
+)
+
+// backends returns the number of state stores configured.
+func (c StateStoreConfig) backends() int {
+	var n int
+	if len(c.EtcdEndpoints) > 0 {
+		n++
+	}
+	if c.ConsulAddress != "" {
+		n++
+	}
+
+	return n
+}
+
+// newStateLog takes a Config and returns the kafkastate.Log that autothrottle
+// state is held in, along with a description of it for logging. A nil Log is
+// returned if state is held in ZooKeeper.
+func newStateLog(ctx context.Context, cfg Config) (kafkastate.Log, string, error) {
+	switch {
+	case cfg.StateTopic != "":
+		l, err := kafkastate.NewKafkaLog(ctx, kafkastate.KafkaLogConfig{
+			Topic: cfg.StateTopic,
+			Admin: cfg.KafkaAdmin,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return l, fmt.Sprintf("Kafka topic %s", cfg.StateTopic), nil
+	case len(cfg.StateStore.EtcdEndpoints) > 0:
+		l, err := kafkastate.NewEtcdLog(kafkastate.EtcdLogConfig{
+			Endpoints: cfg.StateStore.EtcdEndpoints,
+			Prefix:    cfg.StateStore.KeyPrefix,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return l, fmt.Sprintf("etcd (%s) beneath %s", strings.Join(cfg.StateStore.EtcdEndpoints, ","), cfg.StateStore.KeyPrefix), nil
+	case cfg.StateStore.ConsulAddress != "":
+		l, err := kafkastate.NewConsulLog(kafkastate.ConsulLogConfig{
+			Address: cfg.StateStore.ConsulAddress,
+			Token:   cfg.StateStore.ConsulToken,
+			Prefix:  cfg.StateStore.KeyPrefix,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return l, fmt.Sprintf("Consul (%s) beneath %s", cfg.StateStore.ConsulAddress, cfg.StateStore.KeyPrefix), nil
+	}
+
+	return nil, "", nil
+}
@@ -0,0 +1,89 @@
+package autothrottle
+
+import (
+	"strconv"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/prometheus"
+)
+
+// replicationTransitions tracks topics and brokers entering and leaving the
+// replication state across intervals so that reassignment churn can be
+// charted from Prometheus metrics.
+type replicationTransitions struct {
+	// The topics and broker IDs replicating as of the previous interval.
+	topics  set
+	brokers set
+	// The number of topics and brokers that started and finished replicating
+	// in the most recent interval.
+	interval transitionCounts
+	// The cumulative counts since startup.
+	total transitionCounts
+}
+
+// transitionCounts are numbers of replication state transitions.
+type transitionCounts struct {
+	topicsStarted  int
+	topicsDone     int
+	brokersStarted int
+	brokersDone    int
+}
+
+func newReplicationTransitions() *replicationTransitions {
+	return &replicationTransitions{
+		topics:  newSet(),
+		brokers: newSet(),
+	}
+}
+
+// update takes the topics and broker IDs replicating in the current interval
+// and counts those that started or finished replicating since the previous
+// interval.
+func (t *replicationTransitions) update(topics set, brokerIDs []int) {
+	brokers := newSet()
+	for _, id := range brokerIDs {
+		brokers.add(strconv.Itoa(id))
+	}
+
+	t.interval = transitionCounts{
+		topicsStarted:  len(topics.diff(t.topics)),
+		topicsDone:     len(t.topics.diff(topics)),
+		brokersStarted: len(brokers.diff(t.brokers)),
+		brokersDone:    len(t.brokers.diff(brokers)),
+	}
+
+	t.total.topicsStarted += t.interval.topicsStarted
+	t.total.topicsDone += t.interval.topicsDone
+	t.total.brokersStarted += t.interval.brokersStarted
+	t.total.brokersDone += t.interval.brokersDone
+
+	t.topics = topics.copy()
+	t.brokers = brokers
+}
+
+// families returns the Prometheus metric families for the replication state:
+// gauges of the topics and brokers replicating and of the transitions in the
+// most recent interval, and counters of the transitions since startup.
+func (t *replicationTransitions) families() []prometheus.Family {
+	gauge := func(name, help string, v int) prometheus.Family {
+		return prometheus.Family{Name: name, Help: help, Samples: []prometheus.Sample{{Value: float64(v)}}}
+	}
+
+	counter := func(name, help string, v int) prometheus.Family {
+		f := gauge(name, help, v)
+		f.Type = "counter"
+		return f
+	}
+
+	return []prometheus.Family{
+		gauge("autothrottle_reassigning_topics", "The number of topics undergoing reassignment.", len(t.topics)),
+		gauge("autothrottle_reassigning_brokers", "The number of brokers participating in reassignments.", len(t.brokers)),
+		gauge("autothrottle_topics_started_reassigning", "The number of topics that started reassigning in the most recent interval.", t.interval.topicsStarted),
+		gauge("autothrottle_topics_done_reassigning", "The number of topics that finished reassigning in the most recent interval.", t.interval.topicsDone),
+		gauge("autothrottle_brokers_started_reassigning", "The number of brokers that started participating in reassignments in the most recent interval.", t.interval.brokersStarted),
+		gauge("autothrottle_brokers_done_reassigning", "The number of brokers that stopped participating in reassignments in the most recent interval.", t.interval.brokersDone),
+		counter("autothrottle_topics_started_reassigning_total", "The number of topics that started reassigning.", t.total.topicsStarted),
+		counter("autothrottle_topics_done_reassigning_total", "The number of topics that finished reassigning.", t.total.topicsDone),
+		counter("autothrottle_brokers_started_reassigning_total", "The number of brokers that started participating in reassignments.", t.total.brokersStarted),
+		counter("autothrottle_brokers_done_reassigning_total", "The number of brokers that stopped participating in reassignments.", t.total.brokersDone),
+	}
+}
@@ -0,0 +1,63 @@
+package autothrottle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/prometheus"
+)
+
+func TestReplicationTransitions(t *testing.T) {
+	tr := newReplicationTransitions()
+
+	topics := func(names ...string) set {
+		s := newSet()
+		for _, n := range names {
+			s.add(n)
+		}
+		return s
+	}
+
+	tests := []struct {
+		topics   set
+		brokers  []int
+		interval transitionCounts
+		total    transitionCounts
+	}{
+		{topics("test1", "test2"), []int{1001, 1002, 1003}, transitionCounts{2, 0, 3, 0}, transitionCounts{2, 0, 3, 0}},
+		{topics("test2", "test3"), []int{1002, 1003, 1004}, transitionCounts{1, 1, 1, 1}, transitionCounts{3, 1, 4, 1}},
+		{topics("test2", "test3"), []int{1002, 1003, 1004}, transitionCounts{}, transitionCounts{3, 1, 4, 1}},
+		{topics(), nil, transitionCounts{0, 2, 0, 3}, transitionCounts{3, 3, 4, 4}},
+	}
+
+	for i, test := range tests {
+		tr.update(test.topics, test.brokers)
+
+		if tr.interval != test.interval || tr.total != test.total {
+			t.Errorf("[test %d] Expected interval %+v total %+v, got interval %+v total %+v",
+				i, test.interval, test.total, tr.interval, tr.total)
+		}
+	}
+}
+
+func TestControllerTransitionMetrics(t *testing.T) {
+	tc := newTestController(t, Config{CleanupAfter: 60})
+	tc.registry = prometheus.NewRegistry()
+
+	tc.tickAfter(t, 0, "test1")
+	tc.tickAfter(t, time.Minute)
+
+	out := string(tc.registry.Bytes())
+
+	for _, line := range []string{
+		"autothrottle_reassigning_topics 0\n",
+		"autothrottle_topics_done_reassigning 1\n",
+		"autothrottle_topics_started_reassigning_total 1\n",
+		"autothrottle_topics_done_reassigning_total 1\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected %q in:\n%s", line, out)
+		}
+	}
+}
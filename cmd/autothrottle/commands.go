@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/autothrottle/client"
+
+	"github.com/spf13/cobra"
+)
+
+// rootCmd runs the autothrottle daemon if no subcommand is given, so that
+// existing invocations with only daemon flags keep working.
+var rootCmd = &cobra.Command{
+	Use:   "autothrottle",
+	Short: "Dynamically set Kafka replication throttles",
+	Long: `Autothrottle dynamically sets Kafka replication throttles during partition
+reassignments. Without a subcommand, the daemon is run with the provided
+flags as with 'autothrottle run'; see 'autothrottle run -help' for the
+daemon flags.`,
+	Args:               cobra.ArbitraryArgs,
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Flag parsing is left to the daemon, so the help flags are
+		// handled here.
+		if len(args) == 1 && isHelpFlag(args[0]) {
+			cmd.Help()
+			return
+		}
+
+		runDaemon(args)
+	},
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run [flags]",
+	Short: "Run the autothrottle daemon",
+	Long: `Run the autothrottle daemon. The daemon flags are listed with
+'autothrottle run -help' and can also be set through AUTOTHROTTLE_*
+environment variables.`,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		runDaemon(args)
+	},
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(version)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(versionCmd)
+}
+
+// Admin API client settings, populated from the flags of the commands
+// managing a running autothrottle.
+var (
+	adminAddr    string
+	adminTimeout time.Duration
+)
+
+// addAdminFlags registers the admin API client flags with the command and any
+// of its subcommands.
+func addAdminFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&adminAddr, "addr", "localhost:8090", "Autothrottle admin gRPC API address:port, or a Unix domain socket path (unix:///path/to/socket); requires the daemon -grpc-listen flag")
+	cmd.PersistentFlags().DurationVar(&adminTimeout, "timeout", 10*time.Second, "Admin API request timeout")
+}
+
+// withAdminClient calls fn with a client of the admin API at adminAddr and a
+// context bounded by adminTimeout. Overrides set by the client are named after
+// the requester, if set.
+func withAdminClient(requester string, fn func(context.Context, *client.Client) error) error {
+	c, err := client.New(client.Config{
+		Addr:      adminAddr,
+		Requester: requester,
+	})
+	if err != nil {
+		return err
+	}
+
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminTimeout)
+	defer cancel()
+
+	return fn(ctx, c)
+}
+
+func isHelpFlag(arg string) bool {
+	switch arg {
+	case "-h", "-help", "--help", "help":
+		return true
+	}
+
+	return false
+}
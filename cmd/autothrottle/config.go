@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/kafka-kit/v4/internal/secrets"
+)
+
+// envPrefix is the prefix of the environment variable for each flag.
+const envPrefix = "AUTOTHROTTLE"
+
+// envVarName returns the environment variable name for the flag name; e.g.
+// cap-map is AUTOTHROTTLE_CAP_MAP.
+func envVarName(prefix, name string) string {
+	return strings.ReplaceAll(fmt.Sprintf("%s_%s", prefix, strings.ToUpper(name)), "-", "_")
+}
+
+// parseEnv sets each flag in fs that hasn't been explicitly set to the value of
+// its environment variable, if set, and appends the variable name to the flag
+// usage. It must be called before fs is parsed so that the usage is shown with
+// -help and command line flags take precedence.
+//
+// Unlike envy, invalid values aren't ignored; they're returned as an error so
+// that a misconfigured environment-only deployment fails at startup. Variables
+// with the prefix that don't correspond to any flag are returned as unknown
+// since they're commonly typos, but aren't treated as errors since
+// orchestrators may set their own (e.g. Kubernetes service env vars).
+func parseEnv(prefix string, fs *flag.FlagSet, environ []string) (unknown []string, err error) {
+	set := map[string]struct{}{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+
+	env := map[string]string{}
+	for _, kv := range environ {
+		if k, v, found := strings.Cut(kv, "="); found && strings.HasPrefix(k, prefix+"_") {
+			env[k] = v
+		}
+	}
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		name := envVarName(prefix, f.Name)
+		f.Usage = fmt.Sprintf("%s [%s]", f.Usage, name)
+
+		val, exists := env[name]
+		delete(env, name)
+
+		if _, explicit := set[f.Name]; !exists || val == "" || explicit {
+			return
+		}
+
+		if err := fs.Set(f.Name, val); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for %s: %s", val, name, err))
+		}
+	})
+
+	for k := range env {
+		unknown = append(unknown, k)
+	}
+	sort.Strings(unknown)
+
+	return unknown, errors.Join(errs...)
+}
+
+// secretFlag is a flag value that may alternatively be read from a file, e.g.
+// a Kubernetes secret volume mount, or fetched from a secrets backend.
+type secretFlag struct {
+	// The flag names.
+	name, fileName, refName string
+	value                   *string
+	file                    string
+	// A secrets.Ref string.
+	ref string
+}
+
+// readSecretFiles sets the value of each secretFlag with a file to the file
+// contents. Surrounding whitespace, including a trailing newline, is trimmed.
+// Setting both a value and a file is an error.
+func readSecretFiles(flags []secretFlag) error {
+	for _, s := range flags {
+		if s.file == "" {
+			continue
+		}
+
+		if *s.value != "" {
+			return fmt.Errorf("%s and %s are mutually exclusive", s.name, s.fileName)
+		}
+
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %s", s.fileName, err)
+		}
+
+		v := strings.TrimSpace(string(data))
+		if v == "" {
+			return fmt.Errorf("%s %s is empty", s.fileName, s.file)
+		}
+
+		*s.value = v
+	}
+
+	return nil
+}
+
+// fetchSecretRefs sets the value of each secretFlag with a ref to the secret
+// fetched with the client. The secretFlags fetched are returned along with
+// their refs. Setting a ref along with a value or file is an error.
+func fetchSecretRefs(ctx context.Context, c *secrets.Client, flags []secretFlag) ([]secretFlag, []secrets.Ref, error) {
+	var fetched []secretFlag
+	var refs []secrets.Ref
+
+	for _, s := range flags {
+		if s.ref == "" {
+			continue
+		}
+
+		if *s.value != "" {
+			return nil, nil, fmt.Errorf("%s is mutually exclusive with %s and %s", s.refName, s.name, s.fileName)
+		}
+
+		r, err := secrets.ParseRef(s.ref)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing %s: %s", s.refName, err)
+		}
+
+		v, err := c.Get(ctx, r)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		*s.value = v
+		fetched = append(fetched, s)
+		refs = append(refs, r)
+	}
+
+	return fetched, refs, nil
+}
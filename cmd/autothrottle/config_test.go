@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/secrets"
+)
+
+func TestParseEnv(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	capMap := fs.String("cap-map", "", "cap map")
+	tags := fs.String("dd-event-tags", "", "tags")
+	interval := fs.Int("interval", 180, "interval")
+	listen := fs.String("api-listen", "localhost:8080", "listen")
+
+	environ := []string{
+		`AUTOTHROTTLE_CAP_MAP={"d2.2xlarge":120}`,
+		"AUTOTHROTTLE_DD_EVENT_TAGS=team:kafka,env:prod",
+		"AUTOTHROTTLE_INTERVAL=60",
+		"AUTOTHROTTLE_API_LISTEN=",
+		"AUTOTHROTTLE_INTERVALL=60",
+		"PATH=/bin",
+	}
+
+	unknown, err := parseEnv("AUTOTHROTTLE", fs, environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *capMap != `{"d2.2xlarge":120}` || *tags != "team:kafka,env:prod" || *interval != 60 {
+		t.Errorf("Unexpected flag values: %s, %s, %d", *capMap, *tags, *interval)
+	}
+
+	// Empty values are ignored.
+	if *listen != "localhost:8080" {
+		t.Errorf("Expected default api-listen, got %s", *listen)
+	}
+
+	if expected := []string{"AUTOTHROTTLE_INTERVALL"}; !reflect.DeepEqual(unknown, expected) {
+		t.Errorf("Expected unknown env vars %v, got %v", expected, unknown)
+	}
+
+	if u := fs.Lookup("cap-map").Usage; u != "cap map [AUTOTHROTTLE_CAP_MAP]" {
+		t.Errorf("Unexpected usage: %s", u)
+	}
+
+	// Command line flags take precedence.
+	if err := fs.Parse([]string{"-interval", "30"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if *interval != 30 {
+		t.Errorf("Expected interval 30, got %d", *interval)
+	}
+}
+
+func TestParseEnvInvalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("interval", 180, "interval")
+
+	_, err := parseEnv("AUTOTHROTTLE", fs, []string{"AUTOTHROTTLE_INTERVAL=3m"})
+	if err == nil || !strings.Contains(err.Error(), "AUTOTHROTTLE_INTERVAL") {
+		t.Errorf("Expected an invalid AUTOTHROTTLE_INTERVAL error, got %v", err)
+	}
+}
+
+func TestReadSecretFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(path, []byte("secret\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file contents are read with whitespace trimmed.
+	var apiKey string
+	err := readSecretFiles([]secretFlag{{name: "api-key", fileName: "api-key-file", value: &apiKey, file: path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if apiKey != "secret" {
+		t.Errorf("Expected api-key secret, got %q", apiKey)
+	}
+
+	// A value and a file can't both be set.
+	err = readSecretFiles([]secretFlag{{name: "api-key", fileName: "api-key-file", value: &apiKey, file: path}})
+	if err == nil || err.Error() != "api-key and api-key-file are mutually exclusive" {
+		t.Errorf("Expected a mutually exclusive error, got %v", err)
+	}
+
+	// Missing files are an error.
+	var appKey string
+	err = readSecretFiles([]secretFlag{{name: "app-key", fileName: "app-key-file", value: &appKey, file: path + "-missing"}})
+	if err == nil {
+		t.Error("Expected an error reading a missing file")
+	}
+}
+
+func TestFetchSecretRefs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"data": {"api_key": "api", "app_key": "app"}, "metadata": {}}}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("VAULT_TOKEN", "token")
+	c := secrets.NewClient(secrets.Config{Vault: secrets.VaultConfig{Address: srv.URL}})
+
+	var apiKey, zkAuth string
+	flags := []secretFlag{
+		{name: "api-key", fileName: "api-key-file", refName: "api-key-secret", value: &apiKey, ref: "vault://secret/data/datadog#api_key"},
+		{name: "zk-auth", fileName: "zk-auth-file", value: &zkAuth},
+	}
+
+	fetched, refs, err := fetchSecretRefs(context.Background(), c, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if apiKey != "api" {
+		t.Errorf("Expected api-key api, got %s", apiKey)
+	}
+
+	if len(fetched) != 1 || fetched[0].name != "api-key" || len(refs) != 1 || refs[0].Key != "api_key" {
+		t.Errorf("Unexpected fetched secrets %v, %v", fetched, refs)
+	}
+
+	// A ref can't be set along with a value.
+	_, _, err = fetchSecretRefs(context.Background(), c, flags)
+	if err == nil || err.Error() != "api-key-secret is mutually exclusive with api-key and api-key-file" {
+		t.Errorf("Expected a mutually exclusive error, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// maxQueuedEvents is the maximum number of events held in an eventQueue. The
+// oldest events are dropped beyond this.
+const maxQueuedEvents = 1000
+
+// eventQueue is a small on-disk queue of events that haven't yet been written
+// to the Datadog API. The queue is stored as a JSON array and rewritten on
+// each change.
+type eventQueue struct {
+	path   string
+	events []*kafkametrics.Event
+}
+
+// newEventQueue takes a file path and returns an *eventQueue holding any
+// events previously stored at the path.
+func newEventQueue(path string) (*eventQueue, error) {
+	q := &eventQueue{path: path}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return q, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return q, nil
+	}
+
+	if err := json.Unmarshal(data, &q.events); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// pending returns the queued events, oldest first.
+func (q *eventQueue) pending() []*kafkametrics.Event {
+	return append([]*kafkametrics.Event{}, q.events...)
+}
+
+// add appends events to the queue.
+func (q *eventQueue) add(events ...*kafkametrics.Event) error {
+	q.events = append(q.events, events...)
+
+	if n := len(q.events) - maxQueuedEvents; n > 0 {
+		q.events = q.events[n:]
+	}
+
+	return q.write()
+}
+
+// remove removes an event from the queue.
+func (q *eventQueue) remove(e *kafkametrics.Event) error {
+	for i, qe := range q.events {
+		if qe == e {
+			q.events = append(q.events[:i], q.events[i+1:]...)
+			return q.write()
+		}
+	}
+
+	return nil
+}
+
+// write stores the queue, replacing the file atomically.
+func (q *eventQueue) write() error {
+	data, err := json.Marshal(q.events)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), q.path)
+}
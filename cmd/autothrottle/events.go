@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"log"
+	"sync/atomic"
+	"time"
 
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
 )
@@ -10,32 +12,197 @@ import (
 // Events configs.
 var eventTitlePrefix = "kafka-autothrottle"
 
+// Event buffer overflow policies.
+const (
+	// Drop the oldest buffered event to make room for the new event.
+	overflowDropOldest = "drop-oldest"
+	// Write the new event to the log rather than the buffer.
+	overflowLog = "log"
+)
+
 // DDEventWriter wraps a channel where *kafkametrics.Event are written
 // to along with any defaults configs, such as tags to apply to each event.
+// Writes never block; if the channel is full, the overflow policy determines
+// which event is dropped.
 type DDEventWriter struct {
 	c           chan *kafkametrics.Event
 	tags        []string
 	titlePrefix string
+	overflow    string
+	dropped     uint64
 }
 
 // Write takes an event title and message string and writes a
 // *kafkametrics.Event to the event channel, formatted with
 // the configured title and tags.
 func (e *DDEventWriter) Write(t string, m string) {
-	e.c <- &kafkametrics.Event{
+	e.write(&kafkametrics.Event{
 		Title: fmt.Sprintf("[%s] %s", e.titlePrefix, t),
 		Text:  m,
 		Tags:  e.tags,
+	})
+}
+
+// WriteTagged is the same as Write, but the provided tags are applied in
+// addition to the configured tags.
+func (e *DDEventWriter) WriteTagged(t string, m string, tags ...string) {
+	e.write(&kafkametrics.Event{
+		Title: fmt.Sprintf("[%s] %s", e.titlePrefix, t),
+		Text:  m,
+		Tags:  append(append([]string{}, e.tags...), tags...),
+	})
+}
+
+// WriteCritical is the same as Write, but the event is flagged as an error
+// alert type.
+func (e *DDEventWriter) WriteCritical(t string, m string) {
+	e.write(&kafkametrics.Event{
+		Title:     fmt.Sprintf("[%s] %s", e.titlePrefix, t),
+		Text:      m,
+		Tags:      e.tags,
+		AlertType: "error",
+	})
+}
+
+// DroppedEvents returns the number of events dropped because the event
+// channel was full.
+func (e *DDEventWriter) DroppedEvents() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// write writes the event to the event channel, applying the overflow policy
+// if the channel is full.
+func (e *DDEventWriter) write(ev *kafkametrics.Event) {
+	for {
+		select {
+		case e.c <- ev:
+			return
+		default:
+		}
+
+		if e.overflow == overflowLog {
+			atomic.AddUint64(&e.dropped, 1)
+			log.Printf("Event buffer full, event not sent: %s: %s\n", ev.Title, ev.Text)
+			return
+		}
+
+		// Drop the oldest event. The channel may have been drained in the
+		// meantime, in which case the write is simply retried.
+		select {
+		case <-e.c:
+			atomic.AddUint64(&e.dropped, 1)
+		default:
+		}
 	}
 }
 
-// eventWriter reads from a channel of *kafkametrics.Event and writes
-// them to the Datadog API.
-func eventWriter(k kafkametrics.Handler, c chan *kafkametrics.Event) {
+// eventWriterConfig holds eventWriter configuration parameters.
+type eventWriterConfig struct {
+	// The maximum number of buffered events written per batch.
+	batchSize int
+	// The number of times a failed event write is retried.
+	retries int
+	// The backoff before the first retry, doubled for each subsequent retry
+	// up to maxBackoff.
+	backoff    time.Duration
+	maxBackoff time.Duration
+	// Optional queue where critical events are persisted until written.
+	queue *eventQueue
+	// Sleeps for the duration.
+	sleep func(time.Duration)
+}
+
+// eventWriter reads from a channel of *kafkametrics.Event and writes them to
+// the Datadog API in batches of all buffered events, up to the batch size.
+// Failed writes are retried with backoff. Critical events are persisted to the
+// queue, if configured, until written; any left over from a previous run are
+// written first and retried with each batch.
+func eventWriter(k kafkametrics.Handler, c chan *kafkametrics.Event, cfg eventWriterConfig) {
+	if cfg.queue != nil && len(cfg.queue.pending()) > 0 {
+		writeEvents(k, nil, cfg)
+	}
+
 	for e := range c {
-		err := k.PostEvent(e)
-		if err != nil {
-			log.Printf("Error writing event: %s\n", err)
+		batch := []*kafkametrics.Event{e}
+
+	drain:
+		for len(batch) < cfg.batchSize {
+			select {
+			case e, ok := <-c:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, e)
+			default:
+				break drain
+			}
 		}
+
+		writeEvents(k, batch, cfg)
 	}
 }
+
+// writeEvents writes a batch of events, along with any critical events
+// queued from previous batches. Once a write fails after all retries, the
+// remaining events are attempted once without retries.
+func writeEvents(k kafkametrics.Handler, batch []*kafkametrics.Event, cfg eventWriterConfig) {
+	var queued []*kafkametrics.Event
+	if cfg.queue != nil {
+		queued = cfg.queue.pending()
+
+		var critical []*kafkametrics.Event
+		for _, e := range batch {
+			if e.AlertType == "error" {
+				critical = append(critical, e)
+			}
+		}
+
+		if len(critical) > 0 {
+			if err := cfg.queue.add(critical...); err != nil {
+				log.Printf("Error persisting critical events: %s\n", err)
+			}
+		}
+	}
+
+	retries := cfg.retries
+
+	for _, e := range append(queued, batch...) {
+		if err := postEvent(k, e, retries, cfg); err != nil {
+			retries = 0
+
+			if cfg.queue != nil && e.AlertType == "error" {
+				log.Printf("Error writing critical event, will retry: %s\n", err)
+			} else {
+				log.Printf("Error writing event: %s\n", err)
+			}
+			continue
+		}
+
+		if cfg.queue != nil && e.AlertType == "error" {
+			if err := cfg.queue.remove(e); err != nil {
+				log.Printf("Error removing persisted critical event: %s\n", err)
+			}
+		}
+	}
+}
+
+// postEvent writes an event, retrying failures with backoff.
+func postEvent(k kafkametrics.Handler, e *kafkametrics.Event, retries int, cfg eventWriterConfig) error {
+	backoff := cfg.backoff
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			cfg.sleep(backoff)
+			if backoff *= 2; backoff > cfg.maxBackoff {
+				backoff = cfg.maxBackoff
+			}
+		}
+
+		if err = k.PostEvent(e); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
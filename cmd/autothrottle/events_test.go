@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+func TestDDEventWriterOverflow(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected []string
+	}{
+		// The oldest event is dropped.
+		{overflowDropOldest, []string{"[test] b", "[test] c"}},
+		// The new event is logged rather than buffered.
+		{overflowLog, []string{"[test] a", "[test] b"}},
+	}
+
+	for _, test := range tests {
+		e := &DDEventWriter{
+			c:           make(chan *kafkametrics.Event, 2),
+			titlePrefix: "test",
+			overflow:    test.policy,
+		}
+
+		e.Write("a", "")
+		e.WriteTagged("b", "", "tag:b")
+		e.WriteCritical("c", "")
+
+		if n := e.DroppedEvents(); n != 1 {
+			t.Errorf("[%s] Expected 1 dropped event, got %d", test.policy, n)
+		}
+
+		close(e.c)
+
+		var titles []string
+		for ev := range e.c {
+			titles = append(titles, ev.Title)
+		}
+
+		if len(titles) != len(test.expected) {
+			t.Fatalf("[%s] Expected %v, got %v", test.policy, test.expected, titles)
+		}
+
+		for i := range titles {
+			if titles[i] != test.expected[i] {
+				t.Errorf("[%s] Expected %v, got %v", test.policy, test.expected, titles)
+			}
+		}
+	}
+}
+
+// failingEvents is a kafkametrics.Handler where the first failures PostEvent
+// calls fail.
+type failingEvents struct {
+	*kafkametrics.Stub
+	failures int
+	attempts int
+}
+
+func (f *failingEvents) PostEvent(e *kafkametrics.Event) error {
+	f.attempts++
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unavailable")
+	}
+
+	return f.Stub.PostEvent(e)
+}
+
+func TestEventWriterRetries(t *testing.T) {
+	k := &failingEvents{Stub: kafkametrics.NewStub(), failures: 2}
+
+	var sleeps []time.Duration
+	cfg := eventWriterConfig{
+		batchSize:  10,
+		retries:    3,
+		backoff:    time.Second,
+		maxBackoff: 90 * time.Second,
+		sleep:      func(d time.Duration) { sleeps = append(sleeps, d) },
+	}
+
+	c := make(chan *kafkametrics.Event, 3)
+	c <- &kafkametrics.Event{Title: "a"}
+	c <- &kafkametrics.Event{Title: "b"}
+	close(c)
+
+	eventWriter(k, c, cfg)
+
+	if events := k.Events(); len(events) != 2 || events[0].Title != "a" || events[1].Title != "b" {
+		t.Errorf("Expected events a, b, got %v", events)
+	}
+
+	if len(sleeps) != 2 || sleeps[0] != time.Second || sleeps[1] != 2*time.Second {
+		t.Errorf("Expected backoffs [1s 2s], got %v", sleeps)
+	}
+
+	// Once retries are exhausted, the remaining events aren't retried.
+	k = &failingEvents{Stub: kafkametrics.NewStub(), failures: 100}
+
+	c = make(chan *kafkametrics.Event, 3)
+	c <- &kafkametrics.Event{Title: "a"}
+	c <- &kafkametrics.Event{Title: "b"}
+	close(c)
+
+	eventWriter(k, c, cfg)
+
+	if k.attempts != 5 {
+		t.Errorf("Expected 5 attempts, got %d", k.attempts)
+	}
+}
+
+func TestEventWriterQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+
+	q, err := newEventQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := eventWriterConfig{
+		batchSize: 10,
+		queue:     q,
+		sleep:     func(time.Duration) {},
+	}
+
+	// The API is unavailable; the critical event is persisted.
+	k := &failingEvents{Stub: kafkametrics.NewStub(), failures: 100}
+
+	c := make(chan *kafkametrics.Event, 2)
+	c <- &kafkametrics.Event{Title: "info"}
+	c <- &kafkametrics.Event{Title: "critical", AlertType: "error"}
+	close(c)
+
+	eventWriter(k, c, cfg)
+
+	// The queue is read after a restart and written first.
+	q, err = newEventQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pending := q.pending(); len(pending) != 1 || pending[0].Title != "critical" {
+		t.Fatalf("Expected the critical event to be queued, got %v", pending)
+	}
+
+	cfg.queue = q
+	k = &failingEvents{Stub: kafkametrics.NewStub()}
+
+	c = make(chan *kafkametrics.Event, 1)
+	c <- &kafkametrics.Event{Title: "new"}
+	close(c)
+
+	eventWriter(k, c, cfg)
+
+	if events := k.Events(); len(events) != 2 || events[0].Title != "critical" || events[1].Title != "new" {
+		t.Errorf("Expected events critical, new, got %v", events)
+	}
+
+	if q, _ = newEventQueue(path); len(q.pending()) != 0 {
+		t.Errorf("Expected an empty queue, got %v", q.pending())
+	}
+}
+
+func TestEventQueueMax(t *testing.T) {
+	q, err := newEventQueue(filepath.Join(t.TempDir(), "events.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxQueuedEvents+1; i++ {
+		if err := q.add(&kafkametrics.Event{Text: "event"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := len(q.pending()); n != maxQueuedEvents {
+		t.Errorf("Expected %d queued events, got %d", maxQueuedEvents, n)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -10,15 +11,15 @@ import (
 	"strings"
 	"time"
 
-	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
-	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
-	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
-
+	"github.com/DataDog/kafka-kit/v4/autothrottle"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/kraft"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/tracing"
+	"github.com/DataDog/kafka-kit/v4/internal/secrets"
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
 	"github.com/DataDog/kafka-kit/v4/kafkametrics/datadog"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics/instancetype"
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
-
-	"github.com/jamiealquiza/envy"
 )
 
 var (
@@ -33,434 +34,814 @@ var (
 		AppKey                  string
 		NetworkTXQuery          string
 		NetworkRXQuery          string
+		NetworkTXUnits          string
+		NetworkRXUnits          string
+		TieredUploadQuery       string
+		TieredUploadUnits       string
 		BrokerIDTag             string
 		InstanceTypeTag         string
+		CapacityTag             string
 		MetricsWindow           int
+		MetricsAggregation      string
+		MetricsInterpolation    int
 		BootstrapServers        string
+		KafkaAdmin              kafkaadmin.Config
 		ZKAddr                  string
 		ZKPrefix                string
+		ZKCreateChroot          bool
+		ZKReconnectMaxBackoff   int
+		ZKReconnectMaxRetries   int
+		ZKSessionTimeout        int
+		ZKConnectTimeout        int
+		ZKReadTimeout           int
+		ZKWriteTimeout          int
+		ZKAuth                  string
+		ConfigZnodeACL          []kafkazk.ACL
 		Interval                int
+		IntervalJitter          int
+		AlignInterval           bool
+		FetchTimeout            int
 		APIListen               string
+		GRPCListen              string
+		APIDebug                bool
+		APIRateLimit            float64
+		APIRateLimitBurst       int
+		HistoryRetention        int
+		HistoryFile             string
+		APISocketMode           os.FileMode
 		ConfigZKPrefix          string
+		StateTopic              string
+		StateEtcdEndpoints      string
+		StateConsulAddr         string
+		StateConsulToken        string
+		StateKeyPrefix          string
+		DecisionTopic           string
+		ControlTopic            string
 		DDEventTags             string
+		DDMetrics               bool
+		EventBufferSize         int
+		EventOverflowPolicy     string
+		EventBatchSize          int
+		EventRetries            int
+		EventQueuePath          string
+		PagerDutyRoutingKey     string
+		PagerDutySeverities     map[string]string
+		ZKUnreachableIntervals  int
 		MinRate                 float64
+		SourceMinRate           float64
+		DestinationMinRate      float64
+		MinRateMap              map[string]float64
+		PartitionMinRate        float64
 		SourceMaxRate           float64
 		DestinationMaxRate      float64
+		SourceMaxRateAbs        float64
+		DestinationMaxRateAbs   float64
+		RFIncreaseSourceMaxRate float64
+		RFIncreaseDestMaxRate   float64
+		CrossAZSourceMaxRate    float64
+		CrossAZDestMaxRate      float64
+		ClusterMaxRate          float64
+		FairShare               bool
+		TopicClasses            map[string]string
 		ChangeThreshold         float64
 		FailureThreshold        int
-		CapMap                  map[string]float64
+		CapMap                  autothrottle.CapacityMap
+		DefaultCapacity         float64
+		CalibrationWindow       int
 		CleanupAfter            int64
 		SkipAutoDeleteThrottles bool
+		CleanupDryRun           bool
+		SkipConfigSnapshot      bool
+		ISRSyncGracePeriod      int
+		NewTopicCatchUp         int
+		BrokerWatchInterval     int
+		PauseOnBrokerLoss       bool
+		AdoptExisting           bool
+		OrphanScanIntervals     int
+		RemoveOrphanedThrottles bool
+		Guardrails              bool
+		GuardrailMaxURP         int
+		GuardrailMaxOffline     int
+		GuardrailMaxISRShrinks  int
+		ManagerLock             bool
+		ManagerLockOwner        string
+		ManagerLockTTL          int
+		CruiseControlURL        string
+		CruiseControlMode       string
+		VerifyAttempts          int
+		WildcardReplicas        bool
+		K8sConfigMap            string
+		ObserveOnly             bool
+		SelfTest                bool
+		InstanceTypeSource      string
+		EC2Region               string
+		EC2LookupTag            string
+		GCEProject              string
+		InstanceTypeCacheTTL    int
+		MetricsListen           string
+		OTLPEndpoint            string
+		OTLPHeaders             map[string]string
+		SecretsRefreshInterval  int
+		VaultAddr               string
+		VaultTokenFile          string
 	}
 )
 
 func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// runDaemon parses the daemon flags from args and runs autothrottle until an
+// unrecoverable error occurs.
+func runDaemon(args []string) {
 	v := flag.Bool("version", false, "version")
 	flag.BoolVar(&Config.KafkaNativeMode, "kafka-native-mode", false, "Favor native Kafka RPCs over ZooKeeper metadata access")
 	flag.IntVar(&Config.KafkaAPIRequestTimeout, "kafka-api-request-timeout", 15, "Kafka API request timeout (seconds)")
 	flag.StringVar(&Config.APIKey, "api-key", "", "Datadog API key")
 	flag.StringVar(&Config.AppKey, "app-key", "", "Datadog app key")
+	apiKeyFile := flag.String("api-key-file", "", "File containing the Datadog API key (e.g. a mounted secret); mutually exclusive with api-key")
+	appKeyFile := flag.String("app-key-file", "", "File containing the Datadog app key (e.g. a mounted secret); mutually exclusive with app-key")
+	apiKeySecret := flag.String("api-key-secret", "", "Secrets backend reference for the Datadog API key (vault://<path>#<key> or aws-sm://<secret-id>[#<key>]); mutually exclusive with api-key and api-key-file")
+	appKeySecret := flag.String("app-key-secret", "", "Secrets backend reference for the Datadog app key (vault://<path>#<key> or aws-sm://<secret-id>[#<key>]); mutually exclusive with app-key and app-key-file")
+	flag.IntVar(&Config.SecretsRefreshInterval, "secrets-refresh-interval", 300, "Interval at which secrets backend references are fetched to pick up rotated secrets (seconds; 0 disables)")
+	flag.StringVar(&Config.VaultAddr, "vault-addr", "", "Vault address for vault:// secret references (defaults to the VAULT_ADDR env var)")
+	flag.StringVar(&Config.VaultTokenFile, "vault-token-file", "", "File containing the Vault token, re-read for each request (defaults to the VAULT_TOKEN env var)")
 	flag.StringVar(&Config.NetworkTXQuery, "net-tx-query", "avg:system.net.bytes_sent{service:kafka} by {host}", "Datadog query for broker outbound bandwidth by host")
 	flag.StringVar(&Config.NetworkRXQuery, "net-rx-query", "avg:system.net.bytes_rcvd{service:kafka} by {host}", "Datadog query for broker inbound bandwidth by host")
+	flag.StringVar(&Config.NetworkTXUnits, "net-tx-units", kafkametrics.UnitBytes, "Units of the per-second values returned by the net-tx-query (bytes, bits, kb)")
+	flag.StringVar(&Config.NetworkRXUnits, "net-rx-units", kafkametrics.UnitBytes, "Units of the per-second values returned by the net-rx-query (bytes, bits, kb)")
+	flag.StringVar(&Config.TieredUploadQuery, "tiered-upload-query", "", "Datadog query for broker tiered storage segment upload bandwidth by host, subtracted from outbound replication headroom (disabled if unset)")
+	flag.StringVar(&Config.TieredUploadUnits, "tiered-upload-units", kafkametrics.UnitBytes, "Units of the per-second values returned by the tiered-upload-query (bytes, bits, kb)")
 	flag.StringVar(&Config.BrokerIDTag, "broker-id-tag", "broker_id", "Datadog host tag for broker ID")
 	flag.StringVar(&Config.InstanceTypeTag, "instance-type-tag", "instance-type", "Datadog tag for instance type")
+	flag.StringVar(&Config.CapacityTag, "capacity-tag", "", "Datadog host tag holding each broker's network capacity in MB/s, used for brokers with an instance type missing from the cap-map (disabled if unset)")
+	flag.StringVar(&Config.InstanceTypeSource, "instance-type-source", "datadog", "Broker instance type source (datadog, ec2, gce)")
+	flag.StringVar(&Config.EC2Region, "ec2-region", "", "AWS region for EC2 instance type lookups (defaults to the instance's region)")
+	flag.StringVar(&Config.EC2LookupTag, "ec2-lookup-tag", "", "EC2 instance tag matched against broker hosts (defaults to matching by instance ID or private DNS name)")
+	flag.StringVar(&Config.GCEProject, "gce-project", "", "GCP project for GCE machine type lookups (defaults to the instance's project)")
+	flag.IntVar(&Config.InstanceTypeCacheTTL, "instance-type-cache-ttl", 3600, "Time to cache instance types resolved from cloud provider APIs (seconds)")
 	flag.IntVar(&Config.MetricsWindow, "metrics-window", 120, "Time span of metrics required (seconds)")
+	flag.StringVar(&Config.MetricsAggregation, "metrics-aggregation", datadog.AggregationAvg, "Function used to reduce the metrics window to a single bandwidth value per broker (avg, max, p95)")
+	flag.IntVar(&Config.MetricsInterpolation, "metrics-interpolation-max-age", 0, "Maximum age of the previous observations that the metrics of reassigning brokers missing from a metrics fetch are interpolated from (seconds; disabled if 0)")
 	flag.StringVar(&Config.BootstrapServers, "bootstrap-servers", "localhost:9092", "Kafka bootstrap servers")
-	flag.StringVar(&Config.ZKAddr, "zk-addr", "localhost:2181", "ZooKeeper connect string (for broker metadata or rebuild-topic lookups)")
-	flag.StringVar(&Config.ZKPrefix, "zk-prefix", "", "ZooKeeper namespace prefix")
+	Config.KafkaAdmin.RegisterFlags(flag.CommandLine)
+	saslPasswordFile := flag.String("kafka-sasl-password-file", "", "File containing the SASL password (e.g. a mounted secret); mutually exclusive with kafka-sasl-password")
+	flag.StringVar(&Config.ZKAddr, "zk-addr", "localhost:2181", "ZooKeeper connect string in host:port[,host:port...][/chroot] form (for broker metadata or rebuild-topic lookups); if empty, cluster metadata is read through the Kafka Admin API (requires kafka-native-mode and a state store)")
+	flag.StringVar(&Config.ZKPrefix, "zk-prefix", "", "ZooKeeper namespace prefix (derived from the zk-addr chroot if set)")
+	flag.BoolVar(&Config.ZKCreateChroot, "zk-create-chroot", false, "Create the zk-addr chroot if it doesn't exist")
+	flag.IntVar(&Config.ZKReconnectMaxBackoff, "zk-reconnect-max-backoff", 30, "Maximum backoff between ZooKeeper connection attempts (seconds)")
+	flag.IntVar(&Config.ZKReconnectMaxRetries, "zk-reconnect-max-retries", 0, "Consecutive failed ZooKeeper connection attempts before exiting (0 retries indefinitely)")
+	flag.IntVar(&Config.ZKSessionTimeout, "zk-session-timeout", 10, "ZooKeeper session timeout; a server unresponsive for two thirds of it is disconnected from (seconds)")
+	flag.IntVar(&Config.ZKConnectTimeout, "zk-connect-timeout", 1, "Timeout for establishing a connection to a ZooKeeper server (seconds)")
+	flag.IntVar(&Config.ZKReadTimeout, "zk-read-timeout", 30, "Timeout for each ZooKeeper read request (seconds; unbounded if 0)")
+	flag.IntVar(&Config.ZKWriteTimeout, "zk-write-timeout", 30, "Timeout for each ZooKeeper write request (seconds; unbounded if 0)")
+	flag.StringVar(&Config.ZKAuth, "zk-auth", "", "ZooKeeper session credentials in scheme:credentials form (e.g. digest:user:password)")
+	zkAuthFile := flag.String("zk-auth-file", "", "File containing the zk-auth credentials (e.g. a mounted secret); mutually exclusive with zk-auth")
+	zkACL := flag.String("zk-config-acl", "", "Comma-delimited list of ACLs in scheme:id:perms form applied to the autothrottle config znodes (e.g. digest:user:<hash>:cdrwa,world:anyone:r)")
 	flag.IntVar(&Config.Interval, "interval", 180, "Autothrottle check interval (seconds)")
-	flag.StringVar(&Config.APIListen, "api-listen", "localhost:8080", "Admin API listen address:port")
+	flag.IntVar(&Config.IntervalJitter, "interval-jitter", 0, "Maximum random delay added to the start of each interval, so that multiple autothrottle instances don't synchronize metrics requests and ZooKeeper writes (seconds; must be < interval; disabled if 0)")
+	flag.BoolVar(&Config.AlignInterval, "align-interval", false, "Align interval starts to multiples of the interval on the wall clock")
+	flag.IntVar(&Config.FetchTimeout, "fetch-timeout", 0, "Timeout for each ZooKeeper read and metrics request per interval (seconds); defaults to half the interval if 0")
+	flag.StringVar(&Config.APIListen, "api-listen", "localhost:8080", "Admin API listen address:port, or a Unix domain socket path (unix:///path/to/socket)")
+	flag.StringVar(&Config.GRPCListen, "grpc-listen", "", "Admin gRPC API listen address:port, or a Unix domain socket path (unix:///path/to/socket) (disabled if unset)")
+	socketMode := flag.String("api-socket-mode", "0660", "File mode (octal) of admin API Unix domain sockets")
+	flag.BoolVar(&Config.APIDebug, "api-debug", false, "Serve pprof profiling and expvar endpoints beneath /debug on the admin API listener")
+	flag.Float64Var(&Config.APIRateLimit, "api-rate-limit", 0, "Admin API requests per second allowed per client address; requests beyond the rate are rejected with a 429 (disabled if 0)")
+	flag.IntVar(&Config.APIRateLimitBurst, "api-rate-limit-burst", 10, "Admin API requests a client can make at once before being limited to the api-rate-limit")
+	flag.IntVar(&Config.HistoryRetention, "history-retention", 1440, "Time to retain each interval's throttle rates and measured network utilization, served by the admin API /history endpoint (minutes; disabled if 0)")
+	flag.StringVar(&Config.HistoryFile, "history-file", "", "File to append each interval's history to as JSON lines and load it from at startup, so that it survives restarts (requires history-retention)")
 	flag.StringVar(&Config.ConfigZKPrefix, "zk-config-prefix", "autothrottle", "ZooKeeper prefix to store autothrottle configuration")
+	flag.StringVar(&Config.StateTopic, "state-topic", "", "Compacted Kafka topic to store autothrottle state (overrides, pins, pause state, etc.) in rather than ZooKeeper; created if it doesn't exist (requires kafka-native-mode)")
+	flag.StringVar(&Config.StateEtcdEndpoints, "state-etcd-endpoints", "", "Comma-delimited etcd client URLs (e.g. http://etcd-0:2379) to store autothrottle state in rather than ZooKeeper")
+	flag.StringVar(&Config.StateConsulAddr, "state-consul-addr", "", "Consul HTTP API address (e.g. http://localhost:8500) to store autothrottle state in the KV store of rather than ZooKeeper")
+	flag.StringVar(&Config.StateConsulToken, "state-consul-token", "", "Consul ACL token used with state-consul-addr")
+	stateConsulTokenFile := flag.String("state-consul-token-file", "", "File containing the state-consul-token (e.g. a mounted secret); mutually exclusive with state-consul-token")
+	flag.StringVar(&Config.StateKeyPrefix, "state-key-prefix", "kafka-kit", "Key prefix that autothrottle state is stored beneath in etcd or Consul")
+	flag.StringVar(&Config.DecisionTopic, "decision-topic", "", "Kafka topic to write each throttle decision to as a JSON message; created if it doesn't exist (requires kafka-native-mode)")
+	flag.StringVar(&Config.ControlTopic, "control-topic", "", "Compacted Kafka topic to read the pause state and throttle overrides from at each interval, in addition to the admin API; created if it doesn't exist (requires kafka-native-mode)")
 	flag.StringVar(&Config.DDEventTags, "dd-event-tags", "", "Comma-delimited list of Datadog event tags")
+	flag.BoolVar(&Config.DDMetrics, "dd-metrics", false, "Submit the applied throttle rates, broker replication headroom and interval duration as Datadog metrics, tagged with the dd-event-tags")
+	flag.IntVar(&Config.EventBufferSize, "event-buffer-size", 100, "Number of events buffered for writing to Datadog")
+	flag.StringVar(&Config.EventOverflowPolicy, "event-overflow-policy", overflowDropOldest, "Policy when the event buffer is full (drop-oldest: drop the oldest buffered event; log: write the new event to the log)")
+	flag.IntVar(&Config.EventBatchSize, "event-batch-size", 20, "Maximum number of buffered events written to Datadog per batch")
+	flag.IntVar(&Config.EventRetries, "event-retries", 3, "Number of times a failed Datadog event write is retried with backoff")
+	flag.StringVar(&Config.PagerDutyRoutingKey, "pagerduty-routing-key", "", "PagerDuty Events API v2 routing key; critical events also trigger PagerDuty alerts (disabled if unset)")
+	pagerDutyRoutingKeyFile := flag.String("pagerduty-routing-key-file", "", "File containing the pagerduty-routing-key (e.g. a mounted secret); mutually exclusive with pagerduty-routing-key")
+	pagerDutySeverityMap := flag.String("pagerduty-severity-map", "", "JSON map of critical event titles to PagerDuty severities (critical, error, warning, info); unmapped events are critical")
+	flag.IntVar(&Config.ZKUnreachableIntervals, "zk-unreachable-intervals", 3, "Number of consecutive intervals ZooKeeper can be unreachable before a critical event is written (0 disables)")
+	flag.StringVar(&Config.EventQueuePath, "event-queue-path", "", "File where critical events are persisted until written to Datadog, surviving restarts (disabled if unset)")
 	flag.Float64Var(&Config.MinRate, "min-rate", 10, "Minimum replication throttle rate (MB/s)")
+	flag.Float64Var(&Config.SourceMinRate, "min-tx-rate", 0, "Minimum outbound replication throttle rate (MB/s; defaults to min-rate if unset)")
+	flag.Float64Var(&Config.DestinationMinRate, "min-rx-rate", 0, "Minimum inbound replication throttle rate (MB/s; defaults to min-rate if unset)")
+	flag.Float64Var(&Config.PartitionMinRate, "partition-min-rate", 0, "Minimum replication throttle rate for each partition a broker is actively moving; broker rates are raised up to max-tx-rate and max-rx-rate to meet it (MB/s; disabled if unset)")
+	mm := flag.String("min-rate-map", "", "JSON map of instance types to minimum replication throttle rates in MB/s; takes precedence over min-rate, min-tx-rate and min-rx-rate")
 	flag.Float64Var(&Config.SourceMaxRate, "max-tx-rate", 90, "Maximum outbound replication throttle rate (as a percentage of available capacity)")
 	flag.Float64Var(&Config.DestinationMaxRate, "max-rx-rate", 90, "Maximum inbound replication throttle rate (as a percentage of available capacity)")
+	flag.Float64Var(&Config.SourceMaxRateAbs, "max-tx-rate-abs", 0, "Maximum outbound replication throttle rate (MB/s); the lower of this and max-tx-rate applies (disabled if unset)")
+	flag.Float64Var(&Config.DestinationMaxRateAbs, "max-rx-rate-abs", 0, "Maximum inbound replication throttle rate (MB/s); the lower of this and max-rx-rate applies (disabled if unset)")
+	flag.Float64Var(&Config.RFIncreaseSourceMaxRate, "rf-increase-max-tx-rate", 0, "Maximum outbound replication throttle rate for brokers exclusively handling replication factor increases (as a percentage of available capacity; defaults to max-tx-rate if unset)")
+	flag.Float64Var(&Config.RFIncreaseDestMaxRate, "rf-increase-max-rx-rate", 0, "Maximum inbound replication throttle rate for brokers exclusively handling replication factor increases (as a percentage of available capacity; defaults to max-rx-rate if unset)")
+	flag.Float64Var(&Config.CrossAZSourceMaxRate, "cross-az-max-tx-rate", 0, "Maximum outbound replication throttle rate for brokers replicating to brokers in a different rack/AZ (as a percentage of available capacity; disabled if unset)")
+	flag.Float64Var(&Config.CrossAZDestMaxRate, "cross-az-max-rx-rate", 0, "Maximum inbound replication throttle rate for brokers replicating from brokers in a different rack/AZ (as a percentage of available capacity; disabled if unset)")
+	flag.Float64Var(&Config.ClusterMaxRate, "cluster-max-rate", 0, "Maximum total outbound and inbound replication throttle rates across all reassigning brokers (MB/s; disabled if unset)")
+	flag.BoolVar(&Config.FairShare, "fair-share", false, "Allocate the cluster-max-rate among concurrent reassignments weighted by topic priority (set via the admin API) rather than by broker headroom")
+	tc := flag.String("topic-classes", "", "JSON map of topic priority classes (high, normal, low) to topic name regex; high and low priority topics are weighted higher and lower in fair-share allocations (requires fair-share)")
 	flag.Float64Var(&Config.ChangeThreshold, "change-threshold", 10, "Required change in replication throttle to trigger an update (percent)")
 	flag.IntVar(&Config.FailureThreshold, "failure-threshold", 1, "Number of iterations that throttle determinations can fail before reverting to the min-rate")
-	m := flag.String("cap-map", "", "JSON map of instance types to network capacity in MB/s")
+	m := flag.String("cap-map", "", "JSON map of instance types to network capacity in MB/s; either a number or an object with distinct tx and rx capacities")
+	flag.Float64Var(&Config.DefaultCapacity, "default-capacity", 0, "Network capacity in MB/s used for brokers with an instance type missing from the cap-map (disabled if unset)")
+	flag.IntVar(&Config.CalibrationWindow, "capacity-calibration-window", 0, "Trailing window over which each broker's peak network throughput is tracked; broker capacities are the greater of the observed peak and the cap-map (hours; disabled if 0)")
 	flag.Int64Var(&Config.CleanupAfter, "cleanup-after", 60, "Number of intervals after which to issue a global throttle unset if no replication is running")
 	flag.BoolVar(&Config.SkipAutoDeleteThrottles, "skip-auto-delete-throttles", false, "Skip automatic throttle removal")
-
-	envy.Parse("AUTOTHROTTLE")
-	flag.Parse()
+	flag.BoolVar(&Config.CleanupDryRun, "cleanup-dry-run", false, "Log and write an event listing the broker and topic throttle configs that automatic throttle removal would delete, without deleting them")
+	flag.BoolVar(&Config.SkipConfigSnapshot, "skip-config-snapshot", false, "Skip recording broker and topic throttle config values in ZooKeeper ahead of autothrottle's first write to them")
+	flag.IntVar(&Config.ISRSyncGracePeriod, "isr-sync-grace-period", 600, "Maximum time to defer throttle removal after a reassignment completes until the moved replicas have joined the ISR (seconds; verification disabled if 0)")
+	flag.IntVar(&Config.NewTopicCatchUp, "new-topic-catch-up", 0, "Period after creation that the replica catch-up of new topics is throttled for partitions with replicas not in the ISR (minutes; disabled if 0)")
+	flag.IntVar(&Config.BrokerWatchInterval, "broker-watch-interval", 10, "Interval at which broker registrations are checked; throttles of brokers replicating to a lost reassignment destination are reduced to the min-rate immediately (seconds; disabled if 0)")
+	flag.BoolVar(&Config.PauseOnBrokerLoss, "pause-on-broker-loss", false, "Pause autothrottle when a reassignment destination broker is lost (requires broker-watch-interval)")
+	flag.IntVar(&Config.OrphanScanIntervals, "orphan-scan-intervals", 0, "Number of intervals between scans of all topic configs for throttled replicas left behind by finished reassignments (0 disables)")
+	flag.BoolVar(&Config.RemoveOrphanedThrottles, "remove-orphaned-throttles", false, "Remove orphaned topic throttled replicas found by the orphan scan (requires orphan-scan-intervals)")
+	flag.BoolVar(&Config.AdoptExisting, "adopt-existing", false, "Adopt replication throttles found at startup that autothrottle has no record of, rather than removing them")
+	flag.BoolVar(&Config.Guardrails, "guardrails", false, "Drop throttles to the min-rate when cluster health checks fail")
+	flag.IntVar(&Config.GuardrailMaxURP, "guardrail-max-urp", 0, "Max under-replicated partitions outside of ongoing reassignments before guardrails trip")
+	flag.IntVar(&Config.GuardrailMaxOffline, "guardrail-max-offline", 0, "Max offline partitions before guardrails trip")
+	flag.IntVar(&Config.GuardrailMaxISRShrinks, "guardrail-max-isr-shrinks", 10, "Max partitions with ISR shrinks per interval before guardrails trip")
+	flag.BoolVar(&Config.ManagerLock, "manager-lock", false, "Hold a lease in the /throttle-manager-lock znode while writing throttles; no throttle changes are applied while another throttle manager holds it (requires zk-addr)")
+	flag.StringVar(&Config.ManagerLockOwner, "manager-lock-owner", "", "Name the throttle manager lock is held under (defaults to autothrottle@<hostname>)")
+	flag.IntVar(&Config.ManagerLockTTL, "manager-lock-ttl", 0, "Time the throttle manager lock is held without renewal (seconds; must be > interval; defaults to three intervals if 0)")
+	flag.StringVar(&Config.CruiseControlURL, "cruise-control-url", "", "Cruise Control API URL including the path prefix, e.g. http://cruise-control:9090/kafkacruisecontrol, queried each interval for in-progress executions (disabled if unset)")
+	flag.StringVar(&Config.CruiseControlMode, "cruise-control-mode", "defer", "How Cruise Control executions are handled: defer (no throttle changes are applied while Cruise Control is executing) or manage (Cruise Control's reassignments are throttled by autothrottle)")
+	flag.IntVar(&Config.VerifyAttempts, "verify-attempts", 3, "Number of times throttle config writes are read back, verified and retried on a mismatch (0 disables verification)")
+	flag.BoolVar(&Config.WildcardReplicas, "wildcard-throttled-replicas", false, "Set topic throttled replicas lists to '*' (all replicas) rather than enumerating reassigning replicas")
+
+	flag.StringVar(&Config.K8sConfigMap, "k8s-configmap", "", "Kubernetes ConfigMap (namespace/name) to read pause state, throttle overrides and capacities from and write status to; enables operator mode and disables the admin API")
+
+	flag.BoolVar(&Config.SelfTest, "self-test", false, "Check ZooKeeper connectivity and the required znodes, the metrics queries, broker ID tag resolution for all live brokers and cap-map coverage, print a report and exit (non-zero if any check fails)")
+	flag.BoolVar(&Config.ObserveOnly, "observe-only", false, "Publish reassignment state, broker metrics and configured throttles as Prometheus metrics and events without computing or applying throttles; disables the admin API")
+	flag.StringVar(&Config.MetricsListen, "metrics-listen", "localhost:9100", "Prometheus metrics listen address:port (observe-only mode)")
+
+	flag.StringVar(&Config.OTLPEndpoint, "otlp-endpoint", "", "OpenTelemetry OTLP/HTTP endpoint that interval traces are exported to, e.g. http://localhost:4318 (disabled if unset)")
+	otlpHeaders := flag.String("otlp-headers", "", "Comma-delimited list of key=value headers sent with OTLP trace exports (e.g. DD-API-KEY=<key>)")
+
+	unknownEnv, envErr := parseEnv(envPrefix, flag.CommandLine, os.Environ())
+	flag.CommandLine.Parse(args)
 
 	if *v {
 		fmt.Println(version)
 		os.Exit(0)
 	}
 
-	// Deserialize instance-type capacity map.
-	Config.CapMap = map[string]float64{}
-	if len(*m) > 0 {
-		err := json.Unmarshal([]byte(*m), &Config.CapMap)
-		if err != nil {
-			fmt.Printf("Error parsing cap-map flag: %s\n", err)
-			os.Exit(1)
-		}
+	if flag.NArg() > 0 {
+		fmt.Printf("Unknown command or argument: %s\n", flag.Arg(0))
+		os.Exit(1)
 	}
 
-	log.Println("Autothrottle Running")
-	// Lazily prevent a tight restart loop from thrashing ZK.
-	time.Sleep(1 * time.Second)
+	if envErr != nil {
+		fmt.Println(envErr)
+		os.Exit(1)
+	}
+
+	for _, name := range unknownEnv {
+		log.Printf("Ignoring environment variable %s; no corresponding flag\n", name)
+	}
+
+	// Read any secrets provided as files or held in a secrets backend.
+	secretFlags := []secretFlag{
+		{name: "api-key", fileName: "api-key-file", refName: "api-key-secret", value: &Config.APIKey, file: *apiKeyFile, ref: *apiKeySecret},
+		{name: "app-key", fileName: "app-key-file", refName: "app-key-secret", value: &Config.AppKey, file: *appKeyFile, ref: *appKeySecret},
+		{name: "zk-auth", fileName: "zk-auth-file", value: &Config.ZKAuth, file: *zkAuthFile},
+		{name: "pagerduty-routing-key", fileName: "pagerduty-routing-key-file", value: &Config.PagerDutyRoutingKey, file: *pagerDutyRoutingKeyFile},
+		{name: "kafka-sasl-password", fileName: "kafka-sasl-password-file", value: &Config.KafkaAdmin.SASLPassword, file: *saslPasswordFile},
+		{name: "state-consul-token", fileName: "state-consul-token-file", value: &Config.StateConsulToken, file: *stateConsulTokenFile},
+	}
 
-	// Init ZK.
-	zk, err := kafkazk.NewHandler(&kafkazk.Config{
-		Connect: Config.ZKAddr,
-		Prefix:  Config.ZKPrefix,
+	if err := readSecretFiles(secretFlags); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	secretsClient := secrets.NewClient(secrets.Config{
+		Vault: secrets.VaultConfig{
+			Address:   Config.VaultAddr,
+			TokenFile: Config.VaultTokenFile,
+		},
 	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	watchedSecrets, secretRefs, err := fetchSecretRefs(ctx, secretsClient, secretFlags)
+	cancel()
 	if err != nil {
-		log.Fatal(err)
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
-	defer zk.Close()
+	Config.KafkaAdmin.BootstrapServers = Config.BootstrapServers
+	if Config.KafkaNativeMode {
+		if err := Config.KafkaAdmin.Validate(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
 
-	// Init the admin API.
-	apiConfig := &api.APIConfig{
-		Listen:   Config.APIListen,
-		ZKPrefix: Config.ConfigZKPrefix,
+	mode, err := strconv.ParseUint(*socketMode, 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		fmt.Printf("Invalid api-socket-mode: %s\n", *socketMode)
+		os.Exit(1)
 	}
+	Config.APISocketMode = os.FileMode(mode)
 
-	trigger := make(chan struct{}, 1)
-	api.Init(apiConfig, zk, trigger)
-	log.Printf("Admin API: %s\n", Config.APIListen)
+	// The ConfigMap replaces the admin API in Kubernetes operator mode.
+	if Config.K8sConfigMap != "" {
+		Config.APIListen, Config.GRPCListen = "", ""
+	}
 
-	// Init a Kafka metrics fetcher.
-	km, err := datadog.NewHandler(&datadog.Config{
-		APIKey:          Config.APIKey,
-		AppKey:          Config.AppKey,
-		NetworkTXQuery:  Config.NetworkTXQuery,
-		NetworkRXQuery:  Config.NetworkRXQuery,
-		BrokerIDTag:     Config.BrokerIDTag,
-		InstanceTypeTag: Config.InstanceTypeTag,
-		MetricsWindow:   Config.MetricsWindow,
-	})
-	if err != nil {
-		log.Fatal(err)
+	// Throttles can't be managed through the admin API in observe-only mode.
+	if Config.ObserveOnly {
+		Config.APIListen, Config.GRPCListen = "", ""
 	}
 
-	// Get optional Datadog event tags.
-	t := strings.Split(Config.DDEventTags, ",")
-	tags := []string{"name:kafka-autothrottle"}
-	for _, tag := range t {
-		tags = append(tags, tag)
+	switch Config.EventOverflowPolicy {
+	case overflowDropOldest, overflowLog:
+	default:
+		fmt.Printf("Invalid event-overflow-policy: %s\n", Config.EventOverflowPolicy)
+		os.Exit(1)
 	}
 
-	// Init the Datadog event writer.
-	echan := make(chan *kafkametrics.Event, 100)
-	go eventWriter(km, echan)
+	if Config.EventBufferSize < 1 {
+		fmt.Println("event-buffer-size must be > 0")
+		os.Exit(1)
+	}
 
-	// Init an DDEventWriter.
-	events := &DDEventWriter{
-		c:           echan,
-		titlePrefix: eventTitlePrefix,
-		tags:        tags,
+	if Config.EventBatchSize < 1 {
+		fmt.Println("event-batch-size must be > 0")
+		os.Exit(1)
 	}
 
-	// Default to true on startup in case throttles were set in an autothrottle
-	// process other than the current one.
-	knownThrottles := true
+	Config.PagerDutySeverities, err = parsePagerDutySeverityMap(*pagerDutySeverityMap)
+	if err != nil {
+		fmt.Printf("Error parsing pagerduty-severity-map flag: %s\n", err)
+		os.Exit(1)
+	}
 
-	var reassignments kafkazk.Reassignments
+	if !datadog.ValidAggregation(Config.MetricsAggregation) {
+		fmt.Println("metrics-aggregation must be one of avg, max, p95")
+		os.Exit(1)
+	}
 
-	// Track topic replication states across intervals.
-	var topicsReplicatingNow = newSet()
-	var topicsReplicatingPreviously = newSet()
+	for name, u := range map[string]string{"net-tx-units": Config.NetworkTXUnits, "net-rx-units": Config.NetworkRXUnits, "tiered-upload-units": Config.TieredUploadUnits} {
+		if !kafkametrics.ValidUnit(u) {
+			fmt.Printf("%s must be one of bytes, bits, kb\n", name)
+			os.Exit(1)
+		}
+	}
 
-	// Track override broker states.
-	var brokersThrottledPreviously = newSet()
+	if Config.MetricsInterpolation < 0 {
+		fmt.Println("metrics-interpolation-max-age must be >= 0")
+		os.Exit(1)
+	}
 
-	// Params for the updateReplicationThrottle request.
+	if Config.IntervalJitter < 0 || Config.IntervalJitter >= Config.Interval {
+		fmt.Println("interval-jitter must be >= 0 and < interval")
+		os.Exit(1)
+	}
 
-	limitsCfg := replication.NewLimitsConfig{
-		Minimum:            Config.MinRate,
-		SourceMaximum:      Config.SourceMaxRate,
-		DestinationMaximum: Config.DestinationMaxRate,
-		CapacityMap:        Config.CapMap,
+	if Config.ZKUnreachableIntervals < 0 {
+		fmt.Println("zk-unreachable-intervals must be >= 0")
+		os.Exit(1)
 	}
 
-	lim, err := replication.NewLimits(limitsCfg)
-	if err != nil {
-		log.Fatal(err)
+	// Without ZooKeeper, cluster metadata is read through the Kafka Admin API
+	// and state is stored in a Kafka topic, etcd or Consul.
+	stateStored := Config.StateTopic != "" || Config.StateEtcdEndpoints != "" || Config.StateConsulAddr != ""
+	if Config.ZKAddr == "" && (!Config.KafkaNativeMode || !stateStored) {
+		fmt.Println("No cluster metadata source: zk-addr is unset; running without ZooKeeper (e.g. against KRaft clusters) requires kafka-native-mode and one of state-topic, state-etcd-endpoints or state-consul-addr")
+		os.Exit(1)
+	}
+
+	if Config.ManagerLock && Config.ZKAddr == "" {
+		fmt.Println("manager-lock requires zk-addr")
+		os.Exit(1)
 	}
 
-	tmCfg := replication.ThrottleManagerConfig{
-		Limits:                 lim,
-		FailureThreshold:       Config.FailureThreshold,
-		ChangeThreshold:        Config.ChangeThreshold,
-		KafkaZK:                zk,
-		KafkaMetrics:           km,
-		KafkaNativeMode:        Config.KafkaNativeMode,
-		KafkaAPIRequestTimeout: Config.KafkaAPIRequestTimeout,
-		Events:                 events,
+	if Config.ManagerLockOwner == "" {
+		hostname, _ := os.Hostname()
+		Config.ManagerLockOwner = "autothrottle@" + hostname
 	}
 
-	throttleManager, err := replication.NewThrottleManager(tmCfg)
+	Config.OTLPHeaders, err = tracing.ParseHeaders(*otlpHeaders)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Printf("Error parsing otlp-headers flag: %s\n", err)
+		os.Exit(1)
 	}
 
-	// Init a KafkaAdmin Client if needed.
-	if Config.KafkaNativeMode {
-		if err := throttleManager.InitKafkaAdmin(Config.BootstrapServers); err != nil {
-			log.Fatal(err)
-		}
-		log.Printf("Connected to Kafka: %s\n", Config.BootstrapServers)
+	if Config.CalibrationWindow < 0 {
+		fmt.Println("capacity-calibration-window must be >= 0")
+		os.Exit(1)
 	}
 
-	// Run.
-	var interval int64
-	var ticker = time.NewTicker(time.Duration(Config.Interval) * time.Second)
-
-	// TODO(jamie): refactor this loop.
-	for {
-
-		// Get topics undergoing reassignment.
-		if !Config.KafkaNativeMode {
-			reassignments = zk.GetReassignments()
-		} else {
-			// KIP-455 compatible reassignments lookup.
-			reassignments, err = zk.ListReassignments()
-			if err != nil {
-				fmt.Printf("error fetching reassignments: %s\n", err)
-				continue
-			}
-		}
+	if Config.NewTopicCatchUp < 0 {
+		fmt.Println("new-topic-catch-up must be >= 0")
+		os.Exit(1)
+	}
 
-		topicsReplicatingNow = newSet()
-		for t := range reassignments {
-			topicsReplicatingNow.add(t)
-		}
+	if Config.OrphanScanIntervals < 0 {
+		fmt.Println("orphan-scan-intervals must be >= 0")
+		os.Exit(1)
+	}
 
-		// Check for topics that were previously seen replicating, but are no
-		// longer in this interval.
-		topicsDoneReplicating := topicsReplicatingPreviously.diff(topicsReplicatingNow)
+	if Config.PartitionMinRate < 0 {
+		fmt.Println("partition-min-rate must be >= 0")
+		os.Exit(1)
+	}
 
-		// Log and write event.
-		if len(topicsDoneReplicating) > 0 {
-			m := fmt.Sprintf("Topics done reassigning: %s", topicsDoneReplicating.keys())
-			log.Println(m)
-			events.Write("Topics done reassigning", m)
-		}
+	if Config.DefaultCapacity < 0 {
+		fmt.Println("default-capacity must be >= 0")
+		os.Exit(1)
+	}
 
-		// If all of the currently replicating topics are a subset
-		// of the previously replicating topics, we can stop updating
-		// the Kafka topic throttled replicas list. This minimizes
-		// state that must be propagated through the cluster.
-		if topicsReplicatingNow.isSubSet(topicsReplicatingPreviously) {
-			throttleManager.DisableTopicUpdates()
-		} else {
-			throttleManager.EnableTopicUpdates()
-			// Unset any previously stored throttle rates. This is done to avoid a
-			// scenario that results in autothrottle being unaware of externally
-			// specified throttles and failing to override them. The condition can be
-			// triggered when two subsequent reassignments involving the same broker
-			// set are handled by autothrottle. The error condition is as follows:
-			//
-			// - Autothrottle sees reassignment 1 involving brokers 1001, 1002
-			//   and determines a throttle rate of 100MB/s.
-			// - Reassignment 1 completes, reassignment 2 is started in-between
-			//   autothrottle intervals and a manual rate of 25MB/s is specified from
-			//   the reassignment tool.
-			// - Autothrottle sees reassignment 2, revisits throughput and determines
-			//   the rate for brokers 1001 and 1002 should be 105MB/s, below the
-			//   ChangeThreshold of 10% when compared to the last known rates set;
-			//   throttle updates are skipped.
-			// - The reassignment is now stuck at 25MB/s.
-			//
-			// There's two solutions considered to reconcile the stale state:
-			// - Reset all previously stored rates when the current reassigning
-			//   topic list is not a subset of the previous reassigning topic list.
-			// - Force throttle updates every so many intervals, regardless of the
-			//   required ChangeThreshold.
-			//
-			// Ensure we're doing option 1 right here:
-			throttleManager.ResetPreviousThrottles()
+	// Deserialize instance-type capacity map.
+	Config.CapMap = autothrottle.CapacityMap{}
+	if len(*m) > 0 {
+		err := json.Unmarshal([]byte(*m), &Config.CapMap)
+		if err == nil {
+			err = Config.CapMap.Validate()
+		}
+		if err != nil {
+			fmt.Printf("Error parsing cap-map flag: %s\n", err)
+			os.Exit(1)
 		}
+	}
 
-		// Rebuild topicsReplicatingPreviously with the current replications
-		// for the next check iteration.
-		topicsReplicatingPreviously = topicsReplicatingNow.copy()
+	// Deserialize instance-type minimum rate map.
+	Config.MinRateMap = map[string]float64{}
+	if len(*mm) > 0 {
+		err := json.Unmarshal([]byte(*mm), &Config.MinRateMap)
+		if err != nil {
+			fmt.Printf("Error parsing min-rate-map flag: %s\n", err)
+			os.Exit(1)
+		}
+	}
 
-		// Check if a global throttle override was configured.
-		overrideCfg, err := throttlestore.FetchThrottleOverride(zk, api.OverrideRateZnodePath)
+	// Deserialize topic priority classes.
+	if len(*tc) > 0 {
+		err := json.Unmarshal([]byte(*tc), &Config.TopicClasses)
 		if err != nil {
-			log.Println(err)
+			fmt.Printf("Error parsing topic-classes flag: %s\n", err)
+			os.Exit(1)
 		}
+	}
 
-		// Fetch all broker-specific overrides.
-		bo, err := throttlestore.FetchBrokerOverrides(zk, api.OverrideRateZnodePath)
+	if len(*zkACL) > 0 {
+		acl, err := kafkazk.ParseACL(*zkACL)
 		if err != nil {
-			log.Println(err)
+			fmt.Printf("Error parsing zk-config-acl flag: %s\n", err)
+			os.Exit(1)
 		}
+		Config.ConfigZnodeACL = acl
+	}
 
-		// Get the maps of brokers handling reassignments.
-		rb, err := replication.GetReassigningBrokers(reassignments, zk)
+	log.Println("Autothrottle Running")
+	// Lazily prevent a tight restart loop from thrashing ZK.
+	time.Sleep(1 * time.Second)
+
+	// Init the cluster metadata handler: ZooKeeper, or the Kafka Admin API if
+	// zk-addr is unset.
+	var zk kafkazk.Handler
+	if Config.ZKAddr == "" {
+		zk, err = newKRaftHandler()
 		if err != nil {
-			log.Println(err)
+			log.Fatal(err)
 		}
+	} else {
+		zk, err = kafkazk.NewHandler(&kafkazk.Config{
+			Connect:      Config.ZKAddr,
+			Prefix:       Config.ZKPrefix,
+			CreateChroot: Config.ZKCreateChroot,
+			Auth:         Config.ZKAuth,
+			Reconnect: kafkazk.ReconnectConfig{
+				InitialBackoff: kafkazk.DefaultReconnectConfig().InitialBackoff,
+				MaxBackoff:     time.Duration(Config.ZKReconnectMaxBackoff) * time.Second,
+				Jitter:         kafkazk.DefaultReconnectConfig().Jitter,
+				MaxRetries:     Config.ZKReconnectMaxRetries,
+			},
+			Timeouts: kafkazk.TimeoutConfig{
+				Session: time.Duration(Config.ZKSessionTimeout) * time.Second,
+				Connect: time.Duration(Config.ZKConnectTimeout) * time.Second,
+				Read:    time.Duration(Config.ZKReadTimeout) * time.Second,
+				Write:   time.Duration(Config.ZKWriteTimeout) * time.Second,
+			},
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Exit if ZooKeeper can't be reached within the reconnect retry budget.
+		go func() {
+			if err := <-zk.Fatal(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		// The Kafka prefix is the connect string chroot, if set. NewHandler has
+		// already rejected any conflicting -zk-prefix.
+		if _, chroot, _ := kafkazk.ParseConnect(Config.ZKAddr); chroot != "" {
+			Config.ZKPrefix = chroot
+		}
+	}
 
-		throttleManager.SetBrokerOverrides(bo)
-		throttleManager.SetReassigningBrokers(rb)
+	defer zk.Close()
 
-		// If topics are being reassigned, update the replication throttle.
-		if len(topicsReplicatingNow) > 0 {
-			log.Printf("Topics with ongoing reassignments: %s\n", topicsReplicatingNow.keys())
+	// Instance types are resolved from cloud provider APIs rather than Datadog
+	// host tags if configured.
+	var resolver instancetype.Resolver
+	switch Config.InstanceTypeSource {
+	case "datadog":
+	case "ec2":
+		resolver = instancetype.NewEC2Resolver(instancetype.EC2Config{
+			Region:    Config.EC2Region,
+			LookupTag: Config.EC2LookupTag,
+		})
+	case "gce":
+		resolver = instancetype.NewGCEResolver(instancetype.GCEConfig{
+			Project: Config.GCEProject,
+		})
+	default:
+		log.Fatalf("Invalid instance-type-source %s\n", Config.InstanceTypeSource)
+	}
 
-			// Update the throttleManager.
-			throttleManager.SetOverrideRate(overrideCfg.Rate)
-			throttleManager.SetReassignments(reassignments)
+	if resolver != nil {
+		Config.InstanceTypeTag = ""
+	}
 
-			err = throttleManager.UpdateReplicationThrottle()
-			if err != nil {
-				log.Println(err)
-			} else {
-				// Set knownThrottles.
-				knownThrottles = true
-			}
+	// Init a Kafka metrics fetcher.
+	km, err := datadog.NewHandler(&datadog.Config{
+		APIKey:            Config.APIKey,
+		AppKey:            Config.AppKey,
+		NetworkTXQuery:    Config.NetworkTXQuery,
+		NetworkRXQuery:    Config.NetworkRXQuery,
+		NetworkTXUnits:    Config.NetworkTXUnits,
+		NetworkRXUnits:    Config.NetworkRXUnits,
+		TieredUploadQuery: Config.TieredUploadQuery,
+		TieredUploadUnits: Config.TieredUploadUnits,
+		BrokerIDTag:       Config.BrokerIDTag,
+		InstanceTypeTag:   Config.InstanceTypeTag,
+		CapacityTag:       Config.CapacityTag,
+		MetricsWindow:     Config.MetricsWindow,
+		Aggregation:       Config.MetricsAggregation,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Apply rotated Datadog keys.
+	if len(secretRefs) > 0 && Config.SecretsRefreshInterval > 0 {
+		updater := km.(kafkametrics.CredentialsUpdater)
+
+		var values []string
+		for _, s := range watchedSecrets {
+			values = append(values, *s.value)
 		}
 
-		// Get brokers with active overrides, ie where the override rate is non-0,
-		// that are also not part of a reassignment.
-		fn := replication.NotReassignmentParticipant
-		activeOverrideBrokers := throttleManager.GetBrokerOverrides().Filter(fn)
-
-		// Apply any additional broker-specific throttles that were not applied as
-		// part of a reassignment.
-		if len(throttleManager.GetBrokerOverrides()) > 0 {
-			// Find all topics that include brokers with static overrides
-			// configured that aren't being reassigned. In order for broker-specific
-			// throttles to be applied, topics being replicated by those brokers
-			// must include them in the follower.replication.throttled.replicas
-			// dynamic configuration parameter. It's clumsy, but this is the way
-			// Kafka was designed.
-			// TODO(jamie): is there a scenario where we should exclude topics
-			// have also have a reassignment? We're discovering topics here by
-			// reverse lookup of brokers that are not reassignment participants.
-			var err error
-			otl, err := throttleManager.GetTopicsWithThrottledBrokers()
-			if err != nil {
-				log.Printf("Error fetching topic states: %s\n", err)
-			}
+		go secretsClient.Watch(context.Background(), secrets.WatchConfig{
+			Refs:     secretRefs,
+			Values:   values,
+			Interval: time.Duration(Config.SecretsRefreshInterval) * time.Second,
+			OnChange: func(values []string) error {
+				apiKey, appKey := Config.APIKey, Config.AppKey
+				for i, s := range watchedSecrets {
+					switch s.name {
+					case "api-key":
+						apiKey = values[i]
+					case "app-key":
+						appKey = values[i]
+					}
+				}
 
-			throttleManager.SetOverrideThrottleLists(otl)
+				if err := updater.UpdateCredentials(apiKey, appKey); err != nil {
+					return err
+				}
 
-			// Determine whether we need to propagate topic throttle replica
-			// list configs. If the brokers with overrides remains the same,
-			// we don't need to need to update those configs.
-			var brokersThrottledNow = newSet()
-			for broker := range activeOverrideBrokers {
-				brokersThrottledNow.add(strconv.Itoa(broker))
-			}
+				Config.APIKey, Config.AppKey = apiKey, appKey
 
-			if brokersThrottledNow.equal(brokersThrottledPreviously) {
-				throttleManager.DisableOverrideTopicUpdates()
-			} else {
-				throttleManager.EnableOverrideTopicUpdates()
-			}
+				return nil
+			},
+		})
 
-			brokersThrottledPreviously = brokersThrottledNow.copy()
+		log.Printf("Refreshing secrets every %ds\n", Config.SecretsRefreshInterval)
+	}
 
-			// Update throttles.
-			if err := throttleManager.UpdateOverrideThrottles(); err != nil {
-				log.Println(err)
-			}
+	if resolver != nil {
+		km = instancetype.NewHandler(instancetype.Config{
+			Handler:  km,
+			Resolver: resolver,
+			TTL:      time.Duration(Config.InstanceTypeCacheTTL) * time.Second,
+		})
+		log.Printf("Resolving broker instance types from %s\n", Config.InstanceTypeSource)
+	}
 
-			// If we're updating throttles and the active count (those not marked for
-			// removal) is > 0, we should set the knownThrottles to true.
-			if len(activeOverrideBrokers) > 0 {
-				knownThrottles = true
-			}
+	// Run the startup checks and exit.
+	if Config.SelfTest {
+		results := runSelfTest(zk, km, selfTestConfig{
+			zkMode:          Config.ZKAddr != "",
+			zkPrefix:        Config.ZKPrefix,
+			zkConfigPrefix:  Config.ConfigZKPrefix,
+			capMap:          Config.CapMap,
+			defaultCapacity: Config.DefaultCapacity,
+		})
+
+		if !writeSelfTestReport(os.Stdout, results) {
+			zk.Close()
+			os.Exit(1)
 		}
+		return
+	}
 
-		// Remove and delete any broker-specific overrides set to 0.
-		if errs := throttleManager.PurgeOverrideThrottles(); errs != nil {
-			log.Println("Error removing persisted broker throttle overrides")
-			for i := range errs {
-				log.Println(errs[i])
-			}
-		}
+	// Get optional Datadog event tags.
+	t := strings.Split(Config.DDEventTags, ",")
+	tags := []string{"name:kafka-autothrottle"}
+	for _, tag := range t {
+		tags = append(tags, tag)
+	}
 
-		// If there's no topics being reassigned, clear any throttles marked
-		// for automatic removal. Also, check if there's any broker throttles set.
-		// There's a somewhat complicated state problem here; if we previously
-		// set a broker throttle override but there's no reassignment, we'll
-		// immediately clear it here. There's two options:
-		//
-		// 1) Simply hold up clearing throttles if there's a broker throttle
-		//   override set.
-		// 2) Fetch all topics where any brokers with overrides are assigned
-		//   replicas, fetch all topic ISR states, diff the ISR states and the
-		//   replica assignments to track under-replicated topics, then adding
-		//   an under-replicated == 0 condition here.
-		//
-		// We're going with option 1 for now.
-
-		// Capture all the current conditions:
-
-		// Are there throttles eligible to be cleared?
-		var throttlesToClear = knownThrottles || interval == Config.CleanupAfter
-
-		// Are any topics being reassigned?
-		var topicsReassigning bool
-		if len(topicsReplicatingNow) > 0 {
-			topicsReassigning = true
+	// Init the Datadog event writer.
+	ewCfg := eventWriterConfig{
+		batchSize:  Config.EventBatchSize,
+		retries:    Config.EventRetries,
+		backoff:    time.Second,
+		maxBackoff: 30 * time.Second,
+		sleep:      time.Sleep,
+	}
+
+	if Config.EventQueuePath != "" {
+		ewCfg.queue, err = newEventQueue(Config.EventQueuePath)
+		if err != nil {
+			log.Fatalf("Error reading event queue: %s", err)
 		}
 
-		// Do any brokers have throttle overrides set?
-		var brokerOverridesSet bool
-		if len(activeOverrideBrokers) > 0 {
-			brokerOverridesSet = true
+		if n := len(ewCfg.queue.pending()); n > 0 {
+			log.Printf("Found %d unsent critical events in %s\n", n, Config.EventQueuePath)
 		}
+	}
 
-		// Next steps according to the various conditions:
+	echan := make(chan *kafkametrics.Event, Config.EventBufferSize)
+	go eventWriter(km, echan, ewCfg)
 
-		if !topicsReassigning {
-			log.Println("No topics undergoing reassignment")
-		}
+	// Init an DDEventWriter.
+	ddEvents := &DDEventWriter{
+		c:           echan,
+		titlePrefix: eventTitlePrefix,
+		tags:        tags,
+		overflow:    Config.EventOverflowPolicy,
+	}
 
-		if !topicsReassigning && throttlesToClear && brokerOverridesSet {
-			log.Println("One or more brokers level override are set; automatic throttle removal will be skipped")
+	var events autothrottle.EventWriter = ddEvents
+
+	// Critical events also page through PagerDuty if configured.
+	if Config.PagerDutyRoutingKey != "" {
+		hostname, _ := os.Hostname()
+		events = newPagerDutyEventWriter(ddEvents, pagerDutyConfig{
+			routingKey: Config.PagerDutyRoutingKey,
+			severities: Config.PagerDutySeverities,
+			source:     hostname,
+			bufferSize: 100,
+			retries:    3,
+			backoff:    time.Second,
+		})
+		log.Println("Critical events will trigger PagerDuty alerts")
+	}
+
+	// Get the optional etcd or Consul state store.
+	stateStore := autothrottle.StateStoreConfig{
+		ConsulAddress: Config.StateConsulAddr,
+		ConsulToken:   Config.StateConsulToken,
+		KeyPrefix:     Config.StateKeyPrefix,
+	}
+	for _, e := range strings.Split(Config.StateEtcdEndpoints, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			stateStore.EtcdEndpoints = append(stateStore.EtcdEndpoints, e)
 		}
+	}
 
-		// If there's previously set throttles but no topics reassigning nor
-		// broker overrides set, we can issue a global throttle removal.
-		if !topicsReassigning && throttlesToClear && !brokerOverridesSet {
-			// Reset the interval count.
-			interval = 0
-
-			if Config.SkipAutoDeleteThrottles {
-				log.Println("There may be throttles eligible for removal, but skipping automatic removal since skip-auto-delete-throttles is set")
-			} else {
-				// Remove all the broker + topic throttle configs.
-				err := throttleManager.RemoveAllThrottles()
-				if err != nil {
-					log.Printf("Error removing throttles: %s\n", err.Error())
-				} else {
-					// Only set knownThrottles to false if we've removed all
-					// without error.
-					knownThrottles = false
-				}
+	// Run.
+	err = autothrottle.Run(context.Background(), autothrottle.Config{
+		ZK:                         zk,
+		KRaftMode:                  Config.ZKAddr == "",
+		Metrics:                    km,
+		Events:                     events,
+		SubmitMetrics:              Config.DDMetrics,
+		MetricsTags:                tags,
+		KafkaNativeMode:            Config.KafkaNativeMode,
+		KafkaAdmin:                 Config.KafkaAdmin,
+		KafkaAPIRequestTimeout:     Config.KafkaAPIRequestTimeout,
+		ConfigZKPrefix:             Config.ConfigZKPrefix,
+		StateTopic:                 Config.StateTopic,
+		StateStore:                 stateStore,
+		DecisionTopic:              Config.DecisionTopic,
+		ControlTopic:               Config.ControlTopic,
+		ConfigZnodeACL:             Config.ConfigZnodeACL,
+		APIListen:                  Config.APIListen,
+		GRPCListen:                 Config.GRPCListen,
+		APIDebug:                   Config.APIDebug,
+		APIRateLimit:               Config.APIRateLimit,
+		APIRateLimitBurst:          Config.APIRateLimitBurst,
+		HistoryRetention:           time.Duration(Config.HistoryRetention) * time.Minute,
+		HistoryFile:                Config.HistoryFile,
+		APISocketMode:              Config.APISocketMode,
+		Interval:                   time.Duration(Config.Interval) * time.Second,
+		IntervalJitter:             time.Duration(Config.IntervalJitter) * time.Second,
+		AlignInterval:              Config.AlignInterval,
+		FetchTimeout:               time.Duration(Config.FetchTimeout) * time.Second,
+		MetricsInterpolationMaxAge: time.Duration(Config.MetricsInterpolation) * time.Second,
+		ZKUnreachableIntervals:     Config.ZKUnreachableIntervals,
+		Limits: autothrottle.LimitsConfig{
+			MinRate:                 Config.MinRate,
+			SourceMinRate:           Config.SourceMinRate,
+			DestinationMinRate:      Config.DestinationMinRate,
+			MinRateMap:              Config.MinRateMap,
+			PartitionMinRate:        Config.PartitionMinRate,
+			SourceMaxRate:           Config.SourceMaxRate,
+			DestinationMaxRate:      Config.DestinationMaxRate,
+			SourceMaxRateAbs:        Config.SourceMaxRateAbs,
+			DestinationMaxRateAbs:   Config.DestinationMaxRateAbs,
+			RFIncreaseSourceMaxRate: Config.RFIncreaseSourceMaxRate,
+			RFIncreaseDestMaxRate:   Config.RFIncreaseDestMaxRate,
+			CrossAZSourceMaxRate:    Config.CrossAZSourceMaxRate,
+			CrossAZDestMaxRate:      Config.CrossAZDestMaxRate,
+			ClusterMaxRate:          Config.ClusterMaxRate,
+			FairShare:               Config.FairShare,
+			TopicClasses:            Config.TopicClasses,
+			CapacityMap:             Config.CapMap,
+			DefaultCapacity:         Config.DefaultCapacity,
+			CalibrationWindow:       time.Duration(Config.CalibrationWindow) * time.Hour,
+		},
+		ChangeThreshold:         Config.ChangeThreshold,
+		FailureThreshold:        Config.FailureThreshold,
+		CleanupAfter:            Config.CleanupAfter,
+		SkipAutoDeleteThrottles: Config.SkipAutoDeleteThrottles,
+		CleanupDryRun:           Config.CleanupDryRun,
+		ISRSyncGracePeriod:      time.Duration(Config.ISRSyncGracePeriod) * time.Second,
+		NewTopicCatchUp:         time.Duration(Config.NewTopicCatchUp) * time.Minute,
+		BrokerWatchInterval:     time.Duration(Config.BrokerWatchInterval) * time.Second,
+		PauseOnBrokerLoss:       Config.PauseOnBrokerLoss,
+		AdoptExisting:           Config.AdoptExisting,
+		OrphanScanIntervals:     Config.OrphanScanIntervals,
+		RemoveOrphanedThrottles: Config.RemoveOrphanedThrottles,
+		Guardrails: autothrottle.GuardrailsConfig{
+			Enabled:            Config.Guardrails,
+			MaxUnderReplicated: Config.GuardrailMaxURP,
+			MaxOffline:         Config.GuardrailMaxOffline,
+			MaxISRShrinks:      Config.GuardrailMaxISRShrinks,
+		},
+		ManagerLock: autothrottle.ManagerLockConfig{
+			Enabled: Config.ManagerLock,
+			Owner:   Config.ManagerLockOwner,
+			TTL:     time.Duration(Config.ManagerLockTTL) * time.Second,
+		},
+		CruiseControl: autothrottle.CruiseControlConfig{
+			URL:  Config.CruiseControlURL,
+			Mode: Config.CruiseControlMode,
+		},
+		VerifyAttempts:            Config.VerifyAttempts,
+		WildcardThrottledReplicas: Config.WildcardReplicas,
+		SkipConfigSnapshot:        Config.SkipConfigSnapshot,
+		Kubernetes: autothrottle.KubernetesConfig{
+			ConfigMap: Config.K8sConfigMap,
+		},
+		ObserveOnly:   Config.ObserveOnly,
+		MetricsListen: Config.MetricsListen,
+		Tracing: autothrottle.TracingConfig{
+			OTLPEndpoint: Config.OTLPEndpoint,
+			OTLPHeaders:  Config.OTLPHeaders,
+		},
+	})
 
-				// Ensure topic throttle updates are re-enabled.
-				throttleManager.EnableTopicUpdates()
-				throttleManager.EnableOverrideTopicUpdates()
-
-				// Remove any configured throttle overrides if AutoRemove is true.
-				if overrideCfg.AutoRemove {
-					err := throttlestore.StoreThrottleOverride(zk, api.OverrideRateZnodePath, throttlestore.ThrottleOverrideConfig{})
-					if err != nil {
-						log.Println(err)
-					} else {
-						log.Println("Global throttle override removed")
-					}
-				}
-			}
-		}
-		select {
-		case <-ticker.C:
-			interval++
-		case <-trigger:
-		}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newKRaftHandler returns a kafkazk.Handler that reads cluster metadata through
+// the Kafka Admin API.
+func newKRaftHandler() (kafkazk.Handler, error) {
+	ka, err := kafkaadmin.NewClient(Config.KafkaAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", kraft.ErrNoMetadataSource, err)
+	}
+
+	timeout := time.Duration(Config.KafkaAPIRequestTimeout) * time.Second
+	h, err := kraft.NewHandler(context.Background(), ka, timeout)
+	if err != nil {
+		ka.Close()
+		return nil, err
+	}
+
+	mode := "ZooKeeper"
+	if h.Features().KRaft {
+		mode = "KRaft"
 	}
+	log.Printf("Reading cluster metadata through the Kafka Admin API (%d brokers, %s mode)\n", h.Features().Brokers, mode)
 
+	return h, nil
 }
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/autothrottle/client"
+
+	"github.com/spf13/cobra"
+)
+
+// Override command flags.
+var (
+	overrideBroker     int
+	overrideRequester  string
+	overrideAutoRemove bool
+	overrideTTL        time.Duration
+	overrideForce      bool
+)
+
+var overrideCmd = &cobra.Command{
+	Use:   "override",
+	Short: "Manage the throttle overrides of a running autothrottle",
+	Long: `Manage the throttle overrides of a running autothrottle. The global override
+is managed unless a broker is specified with --broker.`,
+}
+
+var overrideGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Print the throttle override",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminClient(overrideRequester, func(ctx context.Context, c *client.Client) error {
+			var t client.Throttle
+			var err error
+
+			if overrideBrokerSet(cmd) {
+				t, err = c.GetBrokerThrottle(ctx, overrideBroker)
+			} else {
+				t, err = c.GetThrottle(ctx)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(formatThrottle(t))
+
+			return nil
+		})
+	},
+}
+
+var overrideSetCmd = &cobra.Command{
+	Use:   "set <rate>",
+	Short: "Set the throttle override to a rate in MB/s",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rate, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid rate %q: must be an integer in MB/s", args[0])
+		}
+
+		o := client.Override{
+			Rate:       rate,
+			AutoRemove: overrideAutoRemove,
+			TTL:        overrideTTL,
+			Force:      overrideForce,
+		}
+
+		return withAdminClient(overrideRequester, func(ctx context.Context, c *client.Client) error {
+			var t client.Throttle
+			var err error
+
+			if overrideBrokerSet(cmd) {
+				t, err = c.SetBrokerThrottle(ctx, overrideBroker, o)
+			} else {
+				t, err = c.SetThrottle(ctx, o)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(formatThrottle(t))
+
+			return nil
+		})
+	},
+}
+
+var overrideClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove the throttle override",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminClient(overrideRequester, func(ctx context.Context, c *client.Client) error {
+			if overrideBrokerSet(cmd) {
+				if err := c.RemoveBrokerThrottle(ctx, overrideBroker); err != nil {
+					return err
+				}
+				fmt.Printf("broker %d: throttle override removed\n", overrideBroker)
+				return nil
+			}
+
+			if err := c.RemoveThrottle(ctx); err != nil {
+				return err
+			}
+			fmt.Println("throttle override removed")
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	addAdminFlags(overrideCmd)
+	overrideCmd.PersistentFlags().IntVar(&overrideBroker, "broker", 0, "Broker ID of a broker-specific override (the global override if unset)")
+	overrideCmd.PersistentFlags().StringVar(&overrideRequester, "requester", "", "Name that the override is set and removed under; the lowest rate override of all requesters is in effect")
+
+	overrideSetCmd.Flags().BoolVar(&overrideAutoRemove, "autoremove", false, "Remove the override once no reassignments are running")
+	overrideSetCmd.Flags().DurationVar(&overrideTTL, "ttl", 0, "How long the override is in effect (doesn't expire if 0)")
+	overrideSetCmd.Flags().BoolVar(&overrideForce, "force", false, "Set the override even if the rate exceeds the known network capacity of the brokers")
+
+	overrideCmd.AddCommand(overrideGetCmd)
+	overrideCmd.AddCommand(overrideSetCmd)
+	overrideCmd.AddCommand(overrideClearCmd)
+	rootCmd.AddCommand(overrideCmd)
+}
+
+// overrideBrokerSet returns whether the command targets a broker-specific
+// override.
+func overrideBrokerSet(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("broker")
+}
+
+// formatThrottle returns a description of the throttle override in the format
+// of the admin API throttle endpoints.
+func formatThrottle(t client.Throttle) string {
+	var prefix string
+	if t.BrokerID != nil {
+		prefix = fmt.Sprintf("broker %d: ", *t.BrokerID)
+	}
+
+	if t.Rate == 0 {
+		return prefix + "no throttle override is set"
+	}
+
+	var expires string
+	if !t.Expires.IsZero() {
+		expires = fmt.Sprintf(", expires==%s", t.Expires.UTC().Format(time.RFC3339))
+	}
+
+	m := fmt.Sprintf("%sa throttle override is configured at %dMB/s, autoremove==%v%s", prefix, t.Rate, t.AutoRemove, expires)
+
+	if t.BrokerID != nil {
+		m = fmt.Sprintf("%s, reassigning==%v", m, t.Reassigning)
+	}
+
+	return m
+}
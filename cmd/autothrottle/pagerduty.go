@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+var pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty event severities.
+const pagerDutyDefaultSeverity = "critical"
+
+var pagerDutySeverities = map[string]struct{}{
+	"critical": {},
+	"error":    {},
+	"warning":  {},
+	"info":     {},
+}
+
+// parsePagerDutySeverityMap takes a JSON map of event title to PagerDuty
+// severity and returns the map, or an error if any severity is invalid.
+func parsePagerDutySeverityMap(s string) (map[string]string, error) {
+	m := map[string]string{}
+	if s == "" {
+		return m, nil
+	}
+
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil, err
+	}
+
+	for title, severity := range m {
+		if _, valid := pagerDutySeverities[severity]; !valid {
+			return nil, fmt.Errorf("invalid severity %q for %q; must be one of critical, error, warning, info", severity, title)
+		}
+	}
+
+	return m, nil
+}
+
+// pagerDutyEvent is a PagerDuty Events API v2 event.
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// pagerDutyConfig holds pagerDutyEventWriter configuration parameters.
+type pagerDutyConfig struct {
+	// The Events API v2 integration routing key.
+	routingKey string
+	// Map of event title to severity; unmapped events are critical.
+	severities map[string]string
+	// The event source, e.g. the hostname.
+	source string
+	// The number of events buffered for sending.
+	bufferSize int
+	// The number of times a failed send is retried.
+	retries int
+	backoff time.Duration
+}
+
+// pagerDutyEventWriter is a DDEventWriter that also triggers a PagerDuty alert
+// for each critical event. Alerts are sent in the background and never block
+// the caller; if the buffer is full, the alert is logged and dropped. Each
+// alert's dedup key is the event title, so a condition recurring across
+// intervals doesn't open additional incidents while one is open.
+type pagerDutyEventWriter struct {
+	*DDEventWriter
+	cfg    pagerDutyConfig
+	c      chan pagerDutyEvent
+	client *http.Client
+}
+
+// newPagerDutyEventWriter takes a *DDEventWriter and a pagerDutyConfig and
+// returns a *pagerDutyEventWriter, starting the background sender.
+func newPagerDutyEventWriter(dd *DDEventWriter, cfg pagerDutyConfig) *pagerDutyEventWriter {
+	e := &pagerDutyEventWriter{
+		DDEventWriter: dd,
+		cfg:           cfg,
+		c:             make(chan pagerDutyEvent, cfg.bufferSize),
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+
+	go e.run()
+
+	return e
+}
+
+// WriteCritical writes the event and triggers a PagerDuty alert.
+func (e *pagerDutyEventWriter) WriteCritical(t string, m string) {
+	e.DDEventWriter.WriteCritical(t, m)
+
+	severity, exists := e.cfg.severities[t]
+	if !exists {
+		severity = pagerDutyDefaultSeverity
+	}
+
+	ev := pagerDutyEvent{
+		RoutingKey:  e.cfg.routingKey,
+		EventAction: "trigger",
+		DedupKey:    fmt.Sprintf("%s: %s", e.titlePrefix, t),
+		Payload: pagerDutyPayload{
+			Summary:       truncate(fmt.Sprintf("[%s] %s: %s", e.titlePrefix, t, m), 1024),
+			Source:        e.cfg.source,
+			Severity:      severity,
+			Component:     e.titlePrefix,
+			CustomDetails: map[string]string{"message": m},
+		},
+	}
+
+	select {
+	case e.c <- ev:
+	default:
+		log.Printf("PagerDuty alert buffer full, alert not sent: %s: %s\n", t, m)
+	}
+}
+
+// run sends buffered alerts until the channel is closed.
+func (e *pagerDutyEventWriter) run() {
+	for ev := range e.c {
+		backoff := e.cfg.backoff
+
+		var err error
+		for attempt := 0; attempt <= e.cfg.retries; attempt++ {
+			if attempt > 0 {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+
+			if err = e.send(ev); err == nil {
+				break
+			}
+		}
+
+		if err != nil {
+			log.Printf("Error sending PagerDuty alert: %s\n", err)
+		}
+	}
+}
+
+// send posts the event to the PagerDuty Events API.
+func (e *pagerDutyEventWriter) send(ev pagerDutyEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Post(pagerDutyEventsURL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
+
+// truncate returns s truncated to n bytes.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
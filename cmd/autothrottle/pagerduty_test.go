@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+func TestParsePagerDutySeverityMap(t *testing.T) {
+	m, err := parsePagerDutySeverityMap(`{"ZooKeeper unreachable": "error"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m["ZooKeeper unreachable"] != "error" {
+		t.Errorf("Expected severity error, got %v", m)
+	}
+
+	if _, err := parsePagerDutySeverityMap(`{"ZooKeeper unreachable": "high"}`); err == nil {
+		t.Error("Expected non-nil error for invalid severity")
+	}
+
+	if m, err := parsePagerDutySeverityMap(""); err != nil || len(m) != 0 {
+		t.Errorf("Expected empty map, got %v, %v", m, err)
+	}
+}
+
+func TestPagerDutyEventWriter(t *testing.T) {
+	received := make(chan pagerDutyEvent, 10)
+	failures := 1
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first request fails and is retried.
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var ev pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Error(err)
+		}
+
+		received <- ev
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	defer func(u string) { pagerDutyEventsURL = u }(pagerDutyEventsURL)
+	pagerDutyEventsURL = ts.URL
+
+	dd := &DDEventWriter{
+		c:           make(chan *kafkametrics.Event, 10),
+		titlePrefix: "test",
+	}
+
+	e := newPagerDutyEventWriter(dd, pagerDutyConfig{
+		routingKey: "key",
+		severities: map[string]string{"b": "warning"},
+		source:     "host",
+		bufferSize: 10,
+		retries:    1,
+		backoff:    time.Millisecond,
+	})
+
+	// Only critical events are sent.
+	e.Write("a", "")
+	e.WriteCritical("b", "message")
+	e.WriteCritical("c", "")
+
+	// All events are still written to Datadog.
+	if len(dd.c) != 3 {
+		t.Errorf("Expected 3 Datadog events, got %d", len(dd.c))
+	}
+
+	expected := []pagerDutyEvent{
+		{
+			RoutingKey:  "key",
+			EventAction: "trigger",
+			DedupKey:    "test: b",
+			Payload: pagerDutyPayload{
+				Summary:       "[test] b: message",
+				Source:        "host",
+				Severity:      "warning",
+				Component:     "test",
+				CustomDetails: map[string]string{"message": "message"},
+			},
+		},
+		{
+			RoutingKey:  "key",
+			EventAction: "trigger",
+			DedupKey:    "test: c",
+			Payload: pagerDutyPayload{
+				Summary:       "[test] c: ",
+				Source:        "host",
+				Severity:      "critical",
+				Component:     "test",
+				CustomDetails: map[string]string{"message": ""},
+			},
+		},
+	}
+
+	for _, exp := range expected {
+		select {
+		case got := <-received:
+			if got.DedupKey != exp.DedupKey || got.Payload.Summary != exp.Payload.Summary ||
+				got.Payload.Severity != exp.Payload.Severity || got.RoutingKey != exp.RoutingKey ||
+				got.Payload.Source != exp.Payload.Source || got.EventAction != exp.EventAction ||
+				got.Payload.CustomDetails["message"] != exp.Payload.CustomDetails["message"] {
+				t.Errorf("Expected %+v, got %+v", exp, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s", exp.DedupKey)
+		}
+	}
+
+	select {
+	case ev := <-received:
+		t.Errorf("Unexpected event %+v", ev)
+	default:
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/autothrottle"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// Self-test check statuses. Only failed checks fail the self-test.
+const (
+	selfTestOK   = "ok"
+	selfTestWarn = "warn"
+	selfTestFail = "fail"
+	selfTestSkip = "skip"
+)
+
+// requiredZnodes are the Kafka znodes, relative to the Kafka prefix, that
+// autothrottle reads.
+var requiredZnodes = []string{"/brokers/ids", "/brokers/topics", "/config/brokers", "/config/topics"}
+
+// selfTestResult is the outcome of a self-test check.
+type selfTestResult struct {
+	check   string
+	status  string
+	message string
+}
+
+// selfTestConfig holds the configuration checked by the self-test.
+type selfTestConfig struct {
+	// Whether cluster metadata is read from ZooKeeper. Znodes aren't checked
+	// otherwise.
+	zkMode bool
+	// The Kafka and autothrottle ZooKeeper prefixes.
+	zkPrefix       string
+	zkConfigPrefix string
+	// The instance type capacities and the default capacity, if any.
+	capMap          autothrottle.CapacityMap
+	defaultCapacity float64
+}
+
+// runSelfTest verifies cluster metadata access and the required znodes, runs
+// the metrics queries, checks that broker metrics are resolved for every live
+// broker and that each broker's instance type has a configured capacity.
+// Nothing is written.
+func runSelfTest(zk kafkazk.Handler, km kafkametrics.Handler, cfg selfTestConfig) []selfTestResult {
+	var results []selfTestResult
+	add := func(check, status, format string, args ...interface{}) {
+		results = append(results, selfTestResult{check: check, status: status, message: fmt.Sprintf(format, args...)})
+	}
+
+	// Live brokers.
+	var live []int
+	brokers, errs := zk.GetAllBrokerMeta(false)
+	switch {
+	case errs != nil:
+		add("cluster metadata", selfTestFail, "error fetching brokers: %v", errs)
+	case len(brokers) == 0:
+		add("cluster metadata", selfTestFail, "no live brokers registered")
+	default:
+		for id := range brokers {
+			live = append(live, id)
+		}
+		sort.Ints(live)
+		add("cluster metadata", selfTestOK, "%d live brokers: %v", len(live), live)
+	}
+
+	// Required znodes.
+	if cfg.zkMode {
+		results = append(results, checkZnodes(zk, cfg))
+	} else {
+		add("znodes", selfTestSkip, "cluster metadata isn't read from ZooKeeper")
+	}
+
+	// Metrics queries.
+	bm, errs := km.GetMetrics()
+	switch {
+	case len(bm) == 0:
+		add("metrics", selfTestFail, "no broker metrics returned: %v", errs)
+	case errs != nil:
+		add("metrics", selfTestWarn, "%d brokers returned with errors: %v", len(bm), errs)
+	default:
+		add("metrics", selfTestOK, "%d brokers returned", len(bm))
+	}
+
+	if len(bm) == 0 {
+		add("broker IDs", selfTestSkip, "no broker metrics")
+		add("cap-map", selfTestSkip, "no broker metrics")
+		return results
+	}
+
+	// Broker ID tag resolution.
+	if live == nil {
+		add("broker IDs", selfTestSkip, "no live brokers")
+	} else {
+		var missing []int
+		for _, id := range live {
+			if _, exists := bm[id]; !exists {
+				missing = append(missing, id)
+			}
+		}
+
+		if len(missing) > 0 {
+			add("broker IDs", selfTestFail, "no metrics resolved for live brokers %v; check that their hosts are tagged with the broker ID", missing)
+		} else {
+			add("broker IDs", selfTestOK, "metrics resolved for all %d live brokers", len(live))
+		}
+	}
+
+	results = append(results, checkCapMapCoverage(bm, cfg))
+
+	return results
+}
+
+// checkZnodes checks that the znodes autothrottle reads exist. The
+// autothrottle config znode is created at startup if it doesn't exist.
+func checkZnodes(zk kafkazk.Handler, cfg selfTestConfig) selfTestResult {
+	var prefix string
+	if cfg.zkPrefix != "" {
+		prefix = "/" + cfg.zkPrefix
+	}
+
+	var missing []string
+	for _, p := range requiredZnodes {
+		exists, err := zk.Exists(prefix + p)
+		if err != nil {
+			return selfTestResult{check: "znodes", status: selfTestFail, message: err.Error()}
+		}
+		if !exists {
+			missing = append(missing, prefix+p)
+		}
+	}
+
+	if len(missing) > 0 {
+		return selfTestResult{check: "znodes", status: selfTestFail, message: fmt.Sprintf("missing %v", missing)}
+	}
+
+	configPath := "/" + cfg.zkConfigPrefix
+	exists, err := zk.Exists(configPath)
+	switch {
+	case err != nil:
+		return selfTestResult{check: "znodes", status: selfTestFail, message: err.Error()}
+	case !exists:
+		return selfTestResult{check: "znodes", status: selfTestWarn, message: fmt.Sprintf("%s doesn't exist and will be created at startup", configPath)}
+	}
+
+	return selfTestResult{check: "znodes", status: selfTestOK, message: "all required znodes exist"}
+}
+
+// checkCapMapCoverage checks that the instance type of each broker without a
+// tagged capacity is resolved and has a capacity in the cap-map. Instance types
+// without a capacity fail the check unless a default capacity is configured.
+func checkCapMapCoverage(bm kafkametrics.BrokerMetrics, cfg selfTestConfig) selfTestResult {
+	var unresolved []int
+	uncovered := map[string][]int{}
+	types := map[string]struct{}{}
+
+	for id, b := range bm {
+		switch {
+		case b.Capacity > 0:
+			// Brokers with a tagged capacity don't require a cap-map entry.
+			continue
+		case b.InstanceType == "":
+			unresolved = append(unresolved, id)
+		default:
+			types[b.InstanceType] = struct{}{}
+			if _, exists := cfg.capMap[b.InstanceType]; !exists {
+				uncovered[b.InstanceType] = append(uncovered[b.InstanceType], id)
+			}
+		}
+	}
+
+	if len(unresolved) > 0 {
+		sort.Ints(unresolved)
+		return selfTestResult{check: "cap-map", status: selfTestFail, message: fmt.Sprintf("instance types not resolved for brokers %v", unresolved)}
+	}
+
+	if len(uncovered) == 0 {
+		return selfTestResult{check: "cap-map", status: selfTestOK, message: fmt.Sprintf("all %d instance types have a capacity", len(types))}
+	}
+
+	var names []string
+	for t := range uncovered {
+		sort.Ints(uncovered[t])
+		names = append(names, fmt.Sprintf("%s %v", t, uncovered[t]))
+	}
+	sort.Strings(names)
+
+	if cfg.defaultCapacity > 0 {
+		return selfTestResult{check: "cap-map", status: selfTestWarn, message: fmt.Sprintf("using the default capacity for instance types [brokers]: %v", names)}
+	}
+
+	return selfTestResult{check: "cap-map", status: selfTestFail, message: fmt.Sprintf("no capacity for instance types [brokers]: %v", names)}
+}
+
+// writeSelfTestReport writes the results and returns whether all checks
+// passed.
+func writeSelfTestReport(w io.Writer, results []selfTestResult) bool {
+	var failed int
+	for _, r := range results {
+		if r.status == selfTestFail {
+			failed++
+		}
+		fmt.Fprintf(w, "%-4s %s: %s\n", r.status, r.check, r.message)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(w, "self-test failed: %d of %d checks failed\n", failed, len(results))
+		return false
+	}
+
+	fmt.Fprintln(w, "self-test passed")
+
+	return true
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/autothrottle"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestSelfTest(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	for _, p := range requiredZnodes {
+		zk.Create(p, "")
+	}
+
+	cfg := selfTestConfig{
+		zkMode:         true,
+		zkConfigPrefix: "autothrottle",
+		capMap:         autothrottle.CapacityMap{"stub": {TX: 200, RX: 200}},
+	}
+
+	// Passing checks, with a warning for the missing config znode.
+	km := kafkametrics.NewStub()
+
+	var b strings.Builder
+	if !writeSelfTestReport(&b, runSelfTest(zk, km, cfg)) {
+		t.Errorf("Expected the self-test to pass, got:\n%s", b.String())
+	}
+
+	expected := "ok   cluster metadata: 6 live brokers: [1001 1002 1003 1004 1005 1007]\n" +
+		"warn znodes: /autothrottle doesn't exist and will be created at startup\n" +
+		"ok   metrics: 10 brokers returned\n" +
+		"ok   broker IDs: metrics resolved for all 6 live brokers\n" +
+		"ok   cap-map: all 1 instance types have a capacity\n" +
+		"self-test passed\n"
+
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	// A broker without metrics and an instance type without a capacity.
+	bm := kafkametrics.StubBrokerMetrics()
+	delete(bm, 1007)
+	bm[1005].InstanceType = "i3.xlarge"
+	km.Script(kafkametrics.StubResponse{Metrics: bm})
+
+	b.Reset()
+	if writeSelfTestReport(&b, runSelfTest(zk, km, cfg)) {
+		t.Errorf("Expected the self-test to fail, got:\n%s", b.String())
+	}
+
+	for _, line := range []string{
+		"fail broker IDs: no metrics resolved for live brokers [1007]",
+		"fail cap-map: no capacity for instance types [brokers]: [i3.xlarge [1005]]",
+		"self-test failed: 2 of 5 checks failed",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in:\n%s", line, b.String())
+		}
+	}
+
+	// Brokers with a tagged capacity don't require a cap-map entry.
+	bm[1005].Capacity = 1250
+	if r := checkCapMapCoverage(bm, cfg); r.status != selfTestOK {
+		t.Errorf("Expected the cap-map check to pass, got %+v", r)
+	}
+	bm[1005].Capacity = 0
+
+	// The default capacity applies to instance types without a capacity.
+	cfg.defaultCapacity = 100
+	if r := checkCapMapCoverage(bm, cfg); r.status != selfTestWarn {
+		t.Errorf("Expected a cap-map warning, got %+v", r)
+	}
+
+	// Failed metrics queries.
+	km.Script(kafkametrics.StubResponse{Errors: []error{errors.New("query failed")}})
+
+	b.Reset()
+	writeSelfTestReport(&b, runSelfTest(zk, km, cfg))
+
+	if !strings.Contains(b.String(), "fail metrics: no broker metrics returned: [query failed]") {
+		t.Errorf("Expected a metrics failure, got:\n%s", b.String())
+	}
+}
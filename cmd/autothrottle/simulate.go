@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DataDog/kafka-kit/v4/autothrottle/client"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	pb "github.com/DataDog/kafka-kit/v4/proto/autothrottlepb"
+
+	"github.com/spf13/cobra"
+)
+
+// Simulate command flags.
+var simulateConfig struct {
+	instanceType string
+	netTX        float64
+	netRX        float64
+	leaderRate   float64
+	followerRate float64
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Print the throttle rates a running autothrottle would set for a broker",
+	Long: `Print the leader and follower throttle rates a running autothrottle would set
+for a reassigning broker of the instance type at the given network
+utilization, using the minimum, maximum and capacity limits of the daemon.
+Other limits, such as absolute maximums, cluster maximums and overrides,
+aren't applied.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if simulateConfig.instanceType == "" {
+			return fmt.Errorf("--instance-type must be set")
+		}
+
+		return withAdminClient("", func(ctx context.Context, c *client.Client) error {
+			limits, err := c.Capacity(ctx)
+			if err != nil {
+				return err
+			}
+
+			b := &kafkametrics.Broker{
+				InstanceType: simulateConfig.instanceType,
+				NetTX:        simulateConfig.netTX,
+				NetRX:        simulateConfig.netRX,
+			}
+
+			rates, err := simulateRates(limits, b, [2]float64{simulateConfig.leaderRate, simulateConfig.followerRate})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("leader (outbound) rate: %.2fMB/s\n", rates[0])
+			fmt.Printf("follower (inbound) rate: %.2fMB/s\n", rates[1])
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	addAdminFlags(simulateCmd)
+	simulateCmd.Flags().StringVar(&simulateConfig.instanceType, "instance-type", "", "Broker instance type")
+	simulateCmd.Flags().Float64Var(&simulateConfig.netTX, "net-tx", 0, "Broker outbound network utilization (MB/s)")
+	simulateCmd.Flags().Float64Var(&simulateConfig.netRX, "net-rx", 0, "Broker inbound network utilization (MB/s)")
+	simulateCmd.Flags().Float64Var(&simulateConfig.leaderRate, "leader-rate", 0, "Leader throttle rate currently applied to the broker, included in the net-tx utilization (MB/s)")
+	simulateCmd.Flags().Float64Var(&simulateConfig.followerRate, "follower-rate", 0, "Follower throttle rate currently applied to the broker, included in the net-rx utilization (MB/s)")
+	rootCmd.AddCommand(simulateCmd)
+}
+
+// simulateRates returns the leader and follower throttle rates for the broker
+// given the limits reported by the admin API and the previously applied leader
+// and follower rates.
+func simulateRates(cr *pb.CapacityResponse, b *kafkametrics.Broker, prev [2]float64) ([2]float64, error) {
+	limits := replication.Limits{
+		"minimum": cr.Minimum,
+		"srcMax":  cr.SourceMaximum,
+		"dstMax":  cr.DestinationMaximum,
+	}
+
+	// Capacities are keyed as in a Limits, including any asymmetric "tx:" and
+	// "rx:" prefixes.
+	for k, v := range cr.Capacities {
+		limits[k] = v
+	}
+
+	var rates [2]float64
+	for n, rt := range []replication.ReplicaType{"leader", "follower"} {
+		rate, err := limits.ReplicationHeadroom(b, rt, prev[n])
+		if err != nil {
+			return rates, fmt.Errorf("%s: %s", b.InstanceType, err)
+		}
+		rates[n] = rate
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	pb "github.com/DataDog/kafka-kit/v4/proto/autothrottlepb"
+)
+
+func TestSimulateRates(t *testing.T) {
+	cr := &pb.CapacityResponse{
+		Minimum:            10,
+		SourceMaximum:      90,
+		DestinationMaximum: 80,
+		Capacities:         map[string]float64{"a": 200, "tx:b": 100, "rx:b": 50},
+	}
+
+	tests := []struct {
+		broker   *kafkametrics.Broker
+		prev     [2]float64
+		expected [2]float64
+	}{
+		{&kafkametrics.Broker{InstanceType: "a", NetTX: 100, NetRX: 150}, [2]float64{20, 0}, [2]float64{108, 40}},
+		// The leader rate is raised to the minimum.
+		{&kafkametrics.Broker{InstanceType: "b", NetTX: 100, NetRX: 20}, [2]float64{}, [2]float64{10, 24}},
+	}
+
+	for i, test := range tests {
+		rates, err := simulateRates(cr, test.broker, test.prev)
+		if err != nil {
+			t.Fatalf("[test %d] %s", i, err)
+		}
+
+		if rates != test.expected {
+			t.Errorf("[test %d] Expected rates %v, got %v", i, test.expected, rates)
+		}
+	}
+
+	if _, err := simulateRates(cr, &kafkametrics.Broker{InstanceType: "c"}, [2]float64{}); err == nil {
+		t.Error("Expected an error for an unknown instance type")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/autothrottle/client"
+	pb "github.com/DataDog/kafka-kit/v4/proto/autothrottlepb"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the status of a running autothrottle",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminClient("", func(ctx context.Context, c *client.Client) error {
+			st, err := c.Status(ctx)
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(formatStatus(st))
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	addAdminFlags(statusCmd)
+	rootCmd.AddCommand(statusCmd)
+}
+
+// formatStatus returns the status in the format of the admin API /status
+// endpoint.
+func formatStatus(st *pb.StatusResponse) string {
+	if st.Updated == 0 {
+		return "status not yet available\n"
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "updated: %s\n", time.Unix(st.Updated, 0).UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "paused: %v\n", st.Paused)
+	fmt.Fprintf(&b, "guardrails tripped: %v\n", st.GuardrailsTripped)
+	fmt.Fprintf(&b, "reassigning topics: %v\n", st.ReassigningTopics)
+	fmt.Fprintf(&b, "replication factor increase topics: %v\n", st.RfIncreaseTopics)
+	fmt.Fprintf(&b, "reassigning brokers: %v\n", st.ReassigningBrokers)
+
+	var throttles []string
+	for _, t := range st.AppliedThrottles {
+		rates := [2]string{"-", "-"}
+		for n, rate := range []*float64{t.LeaderRate, t.FollowerRate} {
+			if rate != nil {
+				rates[n] = fmt.Sprintf("%.2f", *rate)
+			}
+		}
+
+		throttles = append(throttles, fmt.Sprintf("[%d, %s, %s]", t.BrokerId, rates[0], rates[1]))
+	}
+
+	fmt.Fprintf(&b, "applied throttles [ID, leader, follower]: %s\n", strings.Join(throttles, ", "))
+
+	var sessions []string
+	for _, s := range st.Sessions {
+		started := time.Unix(s.Started, 0).UTC().Format(time.RFC3339)
+		sessions = append(sessions, fmt.Sprintf("[%s, %s, %v]", s.Id, started, s.Topics))
+	}
+
+	fmt.Fprintf(&b, "reassignment sessions: %s\n", strings.Join(sessions, ", "))
+
+	if u := st.UnknownThrottles; u != nil {
+		fmt.Fprintf(&b, "unknown throttles found at startup: brokers %v, topics %v, action==%s\n",
+			u.Brokers, u.Topics, u.Action)
+	}
+
+	return b.String()
+}
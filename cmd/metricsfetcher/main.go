@@ -8,6 +8,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
 
@@ -18,18 +19,21 @@ import (
 // Config holds
 // config parameters.
 type Config struct {
-	Client      *dd.Client
-	APIKey      string
-	AppKey      string
-	PartnQuery  string
-	BrokerQuery string
-	BrokerIDTag string
-	Span        int
-	ZKAddr      string
-	ZKPrefix    string
-	Verbose     bool
-	DryRun      bool
-	Compression bool
+	Client        *dd.Client
+	Source        string
+	APIKey        string
+	AppKey        string
+	PrometheusURL string
+	PartnQuery    string
+	BrokerQuery   string
+	BrokerIDTag   string
+	Span          int
+	Interval      int
+	ZKAddr        string
+	ZKPrefix      string
+	Verbose       bool
+	DryRun        bool
+	Compression   bool
 }
 
 var (
@@ -40,12 +44,17 @@ var (
 
 func init() {
 	v := flag.Bool("version", false, "version")
+	flag.StringVar(&config.Source, "source", "datadog", "Metrics source (datadog, prometheus)")
 	flag.StringVar(&config.APIKey, "api-key", "", "Datadog API key")
 	flag.StringVar(&config.AppKey, "app-key", "", "Datadog app key")
 	bq := flag.String("broker-storage-query", "avg:system.disk.free{service:kafka,device:/data}", "Datadog metric query to get broker storage free")
 	flag.StringVar(&config.BrokerIDTag, "broker-id-tag", "broker_id", "Datadog host tag for broker ID")
 	pq := flag.String("partition-size-query", "max:kafka.log.partition.size{service:kafka} by {topic,partition}", "Datadog metric query to get partition size by topic, partition")
+	flag.StringVar(&config.PrometheusURL, "prometheus-url", "http://localhost:9090", "Prometheus server URL")
+	pbq := flag.String("prometheus-broker-storage-query", `min(node_filesystem_avail_bytes{mountpoint="/data"}) by (broker_id)`, "Prometheus query to get broker storage free by broker ID label (-broker-id-tag)")
+	ppq := flag.String("prometheus-partition-size-query", "max(kafka_log_log_size) by (topic, partition)", "Prometheus query to get partition size by topic, partition labels")
 	flag.IntVar(&config.Span, "span", 3600, "Query range in seconds (now - span)")
+	flag.IntVar(&config.Interval, "interval", 0, "Interval in seconds to repeatedly fetch and store metrics (runs once if 0)")
 	flag.StringVar(&config.ZKAddr, "zk-addr", "localhost:2181", "ZooKeeper connect string")
 	flag.StringVar(&config.ZKPrefix, "zk-prefix", "topicmappr", "ZooKeeper namespace prefix")
 	flag.BoolVar(&config.Verbose, "verbose", false, "Verbose output")
@@ -61,18 +70,36 @@ func init() {
 	}
 
 	// Complete query string.
-	config.BrokerQuery = fmt.Sprintf("%s by {%s}.fill(last)", *bq, config.BrokerIDTag)
-	config.PartnQuery = fmt.Sprintf("%s.rollup(avg, %d)", *pq, config.Span)
+	switch config.Source {
+	case "datadog":
+		config.BrokerQuery = fmt.Sprintf("%s by {%s}.fill(last)", *bq, config.BrokerIDTag)
+		config.PartnQuery = fmt.Sprintf("%s.rollup(avg, %d)", *pq, config.Span)
+	case "prometheus":
+		config.BrokerQuery = *pbq
+		config.PartnQuery = *ppq
+	default:
+		exitOnErr(fmt.Errorf("Invalid source: %s", config.Source))
+	}
 }
 
 func main() {
-	// Init, validate dd client.
-	config.Client = dd.NewClient(config.APIKey, config.AppKey)
-	ok, err := config.Client.Validate()
-	exitOnErr(err)
+	var source metricsSource
+	var err error
+
+	switch config.Source {
+	case "datadog":
+		// Init, validate dd client.
+		config.Client = dd.NewClient(config.APIKey, config.AppKey)
+		ok, err := config.Client.Validate()
+		exitOnErr(err)
+
+		if !ok {
+			exitOnErr(errors.New("Invalid API or app key"))
+		}
 
-	if !ok {
-		exitOnErr(errors.New("Invalid API or app key"))
+		source = datadogSource{c: config}
+	case "prometheus":
+		source = newPrometheusSource(config)
 	}
 
 	// Init ZK client.
@@ -91,27 +118,57 @@ func main() {
 		exitOnErr(err)
 	}
 
+	// Trunc the paths slice if
+	// there's a prefix.
+	if len(paths) == 3 {
+		paths = paths[1:]
+	}
+
+	// Run once.
+	if config.Interval <= 0 {
+		exitOnErr(fetchAndStore(source, zk, paths))
+		return
+	}
+
+	// Run on the configured interval. Errors are logged and retried at the
+	// next interval.
+	ticker := time.NewTicker(time.Duration(config.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := fetchAndStore(source, zk, paths); err != nil {
+			fmt.Println(err)
+		}
+		<-ticker.C
+	}
+}
+
+// fetchAndStore fetches partition and broker metrics from the metricsSource
+// and writes them to the partition and broker metrics znode paths.
+func fetchAndStore(source metricsSource, zk kafkazk.Handler, paths []string) error {
 	// Fetch metrics data.
 	fmt.Printf("Submitting %s\n", config.PartnQuery)
-	pm, err := partitionMetrics(config)
-	exitOnErr(err)
+	pm, err := source.partitionMetrics()
+	if err != nil {
+		return err
+	}
 	fmt.Println("success")
 
 	partnData, err := json.Marshal(pm)
-	exitOnErr(err)
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("Submitting %s\n", config.BrokerQuery)
-	bm, err := brokerMetrics(config)
-	exitOnErr(err)
+	bm, err := source.brokerMetrics()
+	if err != nil {
+		return err
+	}
 	fmt.Println("success")
 
 	brokerData, err := json.Marshal(bm)
-	exitOnErr(err)
-
-	// Trunc the paths slice if
-	// there's a prefix.
-	if len(paths) == 3 {
-		paths = paths[1:]
+	if err != nil {
+		return err
 	}
 
 	if config.Verbose {
@@ -122,7 +179,7 @@ func main() {
 	}
 
 	if config.DryRun {
-		return
+		return nil
 	}
 
 	// Write to ZK.
@@ -132,18 +189,22 @@ func main() {
 			var buf bytes.Buffer
 			zw := gzip.NewWriter(&buf)
 
-			_, err := zw.Write(data)
-			exitOnErr(err)
+			if _, err := zw.Write(data); err != nil {
+				return err
+			}
 
 			zw.Close()
 			data = buf.Bytes()
 		}
 
-		err = zk.Set(paths[i], string(data))
-		exitOnErr(err)
+		if err := zk.Set(paths[i], string(data)); err != nil {
+			return err
+		}
 	}
 
 	fmt.Println("\nData written to ZooKeeper")
+
+	return nil
 }
 
 func zkPaths(p string) []string {
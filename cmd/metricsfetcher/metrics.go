@@ -9,6 +9,25 @@ import (
 	dd "github.com/zorkian/go-datadog-api"
 )
 
+// metricsSource fetches partition size and broker storage free metrics.
+type metricsSource interface {
+	partitionMetrics() (map[string]map[string]map[string]float64, error)
+	brokerMetrics() (map[string]map[string]float64, error)
+}
+
+// datadogSource fetches metrics from the Datadog API.
+type datadogSource struct {
+	c *Config
+}
+
+func (d datadogSource) partitionMetrics() (map[string]map[string]map[string]float64, error) {
+	return partitionMetrics(d.c)
+}
+
+func (d datadogSource) brokerMetrics() (map[string]map[string]float64, error) {
+	return brokerMetrics(d.c)
+}
+
 // Both functions here fetch 2x the span in duration to handle lagging
 // metrics from the DD API. This should result in a max of two rollup values
 // per timeseries. We then choose the latest non-nil value.
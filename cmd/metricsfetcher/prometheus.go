@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prometheusSource fetches metrics from the Prometheus HTTP API.
+type prometheusSource struct {
+	c      *Config
+	client *http.Client
+}
+
+func newPrometheusSource(c *Config) *prometheusSource {
+	return &prometheusSource{
+		c:      c,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// promResponse is a Prometheus HTTP API instant query response.
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string         `json:"resultType"`
+		Result     []promInstance `json:"result"`
+	} `json:"data"`
+}
+
+// promInstance is a single sample in a vector result.
+type promInstance struct {
+	Metric map[string]string `json:"metric"`
+	// A [<unix time>, "<value>"] pair.
+	Value []interface{} `json:"value"`
+}
+
+func (p *prometheusSource) partitionMetrics() (map[string]map[string]map[string]float64, error) {
+	samples, err := p.query(p.c.PartnQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	d := map[string]map[string]map[string]float64{}
+
+	for _, s := range samples {
+		topic, partition := s.Metric["topic"], s.Metric["partition"]
+		if topic == "" || partition == "" {
+			continue
+		}
+
+		val, err := s.value()
+		if err != nil {
+			continue
+		}
+
+		if _, exists := d[topic]; !exists {
+			d[topic] = map[string]map[string]float64{}
+		}
+
+		d[topic][partition] = map[string]float64{}
+		d[topic][partition]["Size"] = val
+	}
+
+	return d, nil
+}
+
+func (p *prometheusSource) brokerMetrics() (map[string]map[string]float64, error) {
+	samples, err := p.query(p.c.BrokerQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	d := map[string]map[string]float64{}
+
+	for _, s := range samples {
+		broker := s.Metric[p.c.BrokerIDTag]
+
+		// Check that the label value is actually a broker ID.
+		if _, err := strconv.Atoi(broker); err != nil {
+			continue
+		}
+
+		val, err := s.value()
+		if err != nil {
+			continue
+		}
+
+		d[broker] = map[string]float64{"StorageFree": val}
+	}
+
+	return d, nil
+}
+
+// query takes a PromQL query and returns the instant vector result.
+func (p *prometheusSource) query(q string) ([]promInstance, error) {
+	u := strings.TrimSuffix(p.c.PrometheusURL, "/") + "/api/v1/query"
+
+	resp, err := p.client.PostForm(u, url.Values{"query": {q}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var r promResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("error decoding Prometheus response (HTTP %d): %s", resp.StatusCode, err)
+	}
+
+	if r.Status != "success" {
+		return nil, fmt.Errorf("Prometheus query failed: %s: %s", r.ErrorType, r.Error)
+	}
+
+	if r.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("expected a vector result, got %s", r.Data.ResultType)
+	}
+
+	return r.Data.Result, nil
+}
+
+// value returns the sample value.
+func (p promInstance) value() (float64, error) {
+	if len(p.Value) != 2 {
+		return 0, fmt.Errorf("no value found")
+	}
+
+	s, ok := p.Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid value %v", p.Value[1])
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid value %s", s)
+	}
+
+	return v, nil
+}
@@ -7,7 +7,6 @@ import (
 	"log"
 	"os"
 	"os/signal"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -26,18 +25,9 @@ var version = "0.0.0"
 
 func main() {
 	serverConfig := server.Config{}
-	zkConfig := kafkazk.Config{}
+	zkConfig := kafkazk.Config{Reconnect: kafkazk.DefaultReconnectConfig()}
 	adminConfig := kafkaadmin.Config{}
 
-	securityProtocols := make([]string, 0, len(kafkaadmin.SecurityProtocolSet))
-	for k := range kafkaadmin.SecurityProtocolSet {
-		securityProtocols = append(securityProtocols, k)
-	}
-	saslMechanims := make([]string, 0, len(kafkaadmin.SASLMechanismSet))
-	for k := range kafkaadmin.SASLMechanismSet {
-		saslMechanims = append(saslMechanims, k)
-	}
-
 	v := flag.Bool("version", false, "version")
 	profiling := flag.Bool("enable-profiling", false, "Enable Datadog continuous profiling")
 	locking := flag.Bool("enable-locking", false, "Enable distributed locking for write operations")
@@ -46,14 +36,12 @@ func main() {
 	flag.IntVar(&serverConfig.ReadReqRate, "read-rate-limit", 5, "Read request rate limit (reqs/s)")
 	flag.IntVar(&serverConfig.WriteReqRate, "write-rate-limit", 1, "Write request rate limit (reqs/s)")
 	flag.StringVar(&serverConfig.ZKTagsPrefix, "zk-tags-prefix", "registry", "Tags storage ZooKeeper prefix")
-	flag.StringVar(&zkConfig.Connect, "zk-addr", "localhost:2181", "ZooKeeper connect string")
-	flag.StringVar(&zkConfig.Prefix, "zk-prefix", "", "ZooKeeper prefix (if Kafka is configured with a chroot path prefix)")
+	flag.StringVar(&zkConfig.Connect, "zk-addr", "localhost:2181", "ZooKeeper connect string in host:port[,host:port...][/chroot] form")
+	flag.DurationVar(&zkConfig.Reconnect.MaxBackoff, "zk-reconnect-max-backoff", zkConfig.Reconnect.MaxBackoff, "Maximum backoff between ZooKeeper connection attempts")
+	flag.IntVar(&zkConfig.Reconnect.MaxRetries, "zk-reconnect-max-retries", 0, "Consecutive failed ZooKeeper connection attempts before exiting (0 retries indefinitely)")
+	flag.StringVar(&zkConfig.Prefix, "zk-prefix", "", "ZooKeeper prefix (if Kafka is configured with a chroot path prefix and it's not set in the zk-addr)")
 	flag.StringVar(&adminConfig.BootstrapServers, "bootstrap-servers", "localhost", "Kafka bootstrap servers")
-	flag.StringVar(&adminConfig.SecurityProtocol, "kafka-security-protocol", "", fmt.Sprintf("Protocol used to communicate with brokers. Supported: %s", strings.Join(securityProtocols, ", ")))
-	flag.StringVar(&adminConfig.SSLCALocation, "kafka-ssl-ca-location", "", "CA certificate path (.pem/.crt) for verifying broker's identity. Needed for SSL and SASL_SSL protocols.")
-	flag.StringVar(&adminConfig.SASLMechanism, "kafka-sasl-mechanism", "", fmt.Sprintf("SASL mechanism to use for authentication. Supported: %s", strings.Join(saslMechanims, ", ")))
-	flag.StringVar(&adminConfig.SASLUsername, "kafka-sasl-username", "", "SASL username for use with the PLAIN and SASL-SCRAM-* mechanisms")
-	flag.StringVar(&adminConfig.SASLPassword, "kafka-sasl-password", "", "SASL password for use with the PLAIN and SASL-SCRAM-* mechanisms")
+	adminConfig.RegisterFlags(flag.CommandLine)
 	defaultRequestTimeout := flag.Int("default-request-timeout", 5000, "Default request API request timeout in milliseconds. API request deadlines are also automatically capped to 3x this value.")
 	flag.IntVar(&serverConfig.TagAllowedStalenessMinutes, "tag-allowed-staleness", 60, "Minutes before tags with no associated resource are deleted")
 	flag.IntVar(&serverConfig.TagCleanupFrequencyMinutes, "tag-cleanup-frequency", 20, "Minutes between runs of tag cleanup")
@@ -100,20 +88,9 @@ func main() {
 		defer profiler.Stop()
 	}
 
-	if adminConfig.SecurityProtocol != "" {
-		adminConfig.SecurityProtocol = strings.ToUpper(adminConfig.SecurityProtocol)
-		if _, validChoice := kafkaadmin.SecurityProtocolSet[adminConfig.SecurityProtocol]; !validChoice {
-			fmt.Printf("Invalid kafka security protocol. Supported protocols: %s\n", strings.Join(securityProtocols, ", "))
-			os.Exit(1)
-		}
-	}
-
-	if adminConfig.SASLMechanism != "" {
-		adminConfig.SASLMechanism = strings.ToUpper(adminConfig.SASLMechanism)
-		if _, validChoice := kafkaadmin.SASLMechanismSet[adminConfig.SASLMechanism]; !validChoice {
-			fmt.Printf("Invalid kafka SASL mechanism. Supported mechanisms: %s\n", strings.Join(saslMechanims, ", "))
-			os.Exit(1)
-		}
+	if err := adminConfig.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
 	log.Println("Registry running")
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
 
 	"github.com/spf13/cobra"
@@ -93,6 +94,19 @@ func initZooKeeper(zkAddr, kafkaPrefix, metricsPrefix string) (kafkazk.Handler,
 	return zk, nil
 }
 
+// initKafkaAdmin inits a kafkaadmin client using the --kafka-addr and Kafka
+// security flags.
+func initKafkaAdmin(bootstrapServers string) (kafkaadmin.KafkaAdmin, error) {
+	cfg := kafkaAdminConfig
+	cfg.BootstrapServers = bootstrapServers
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return kafkaadmin.NewClient(cfg)
+}
+
 // containsRegex takes a topic name reference and returns whether or not
 // it should be interpreted as regex.
 func containsRegex(t string) bool {
@@ -1,9 +1,12 @@
 package commands
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+
 	"github.com/jamiealquiza/envy"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +15,10 @@ var rootCmd = &cobra.Command{
 	Use: "topicmappr",
 }
 
+// kafkaAdminConfig holds the Kafka security settings populated from the
+// persistent kafka-* flags.
+var kafkaAdminConfig kafkaadmin.Config
+
 // Execute rootCmd.
 func Execute() {
 	envy.ParseCobra(rootCmd, envy.CobraConfig{Prefix: "TOPICMAPPR", Persistent: true, Recursive: false})
@@ -24,8 +31,11 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().String("kafka-addr", "localhost:9092", "Kafka bootstrap address")
-	rootCmd.PersistentFlags().String("zk-addr", "localhost:2181", "ZooKeeper connect string")
-	rootCmd.PersistentFlags().String("zk-prefix", "", "ZooKeeper prefix (if Kafka is configured with a chroot path prefix)")
+	kafkaFlags := flag.NewFlagSet("kafka", flag.ExitOnError)
+	kafkaAdminConfig.RegisterFlags(kafkaFlags)
+	rootCmd.PersistentFlags().AddGoFlagSet(kafkaFlags)
+	rootCmd.PersistentFlags().String("zk-addr", "localhost:2181", "ZooKeeper connect string in host:port[,host:port...][/chroot] form")
+	rootCmd.PersistentFlags().String("zk-prefix", "", "ZooKeeper prefix (if Kafka is configured with a chroot path prefix and it's not set in the zk-addr)")
 	rootCmd.PersistentFlags().String("zk-metrics-prefix", "topicmappr", "ZooKeeper namespace prefix for Kafka metrics")
 	rootCmd.PersistentFlags().Bool("ignore-warns", false, "Produce a map even if warnings are encountered")
 }
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
-
 	"github.com/spf13/cobra"
 )
 
@@ -56,7 +54,7 @@ func scale(cmd *cobra.Command, _ []string) {
 
 	// Init kafkaadmin client.
 	bs := cmd.Parent().Flag("kafka-addr").Value.String()
-	ka, err := kafkaadmin.NewClient(kafkaadmin.Config{BootstrapServers: bs})
+	ka, err := initKafkaAdmin(bs)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
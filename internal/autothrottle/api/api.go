@@ -1,13 +1,17 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
@@ -15,86 +19,166 @@ import (
 
 // APIConfig holds configuration params for the admin API.
 type APIConfig struct {
-	Listen   string
-	ZKPrefix string
+	// The listen address:port, or a Unix domain socket path prefixed with
+	// unix://.
+	Listen string
+	// Optional gRPC listen address:port or unix:// socket path. The gRPC API is
+	// disabled if unset.
+	GRPCListen string
+	// The file mode of Unix domain sockets listened on. Defaults to
+	// DefaultSocketMode.
+	SocketMode os.FileMode
+	// Optional ACL applied to the autothrottle config znodes.
+	ZnodeACL []kafkazk.ACL
+	// Serve the pprof and expvar debug endpoints beneath /debug.
+	Debug bool
+	// Optional handler serving Prometheus metrics at /metrics.
+	Metrics http.Handler
+	// Requests per second allowed per client address. Requests beyond the
+	// rate are rejected with a 429. Unlimited if 0.
+	RateLimit float64
+	// The number of requests a client can make at once before being limited
+	// to the RateLimit. Defaults to the RateLimit, rounded up.
+	RateLimitBurst int
 }
 
 var (
 	overrideRateZnode     = "override_rate"
-	OverrideRateZnodePath string
+	pinnedRateZnode       = "pinned_rate"
+	reassignmentPlanZnode = "reassignment_plan"
+	pauseZnode            = "paused"
+	priorityZnode         = "priorities"
+	cancelledZnode        = "cancelled_reassignments"
+	peaksZnode            = "capacity_peaks"
+	configSnapshotZnode   = "config_snapshot"
+	configRestoreZnode    = "config_restore"
+	cleanupZnode          = "last_cleanup"
 	incorrectMethodError  = errors.New("disallowed method")
 )
 
-func Init(c *APIConfig, zk kafkazk.Handler, trigger chan<- struct{}) {
-	chroot := fmt.Sprintf("/%s", c.ZKPrefix)
-	OverrideRateZnodePath = fmt.Sprintf("%s/%s", chroot, overrideRateZnode)
+// ZnodePaths holds the paths of the autothrottle config znodes.
+type ZnodePaths struct {
+	Chroot           string
+	OverrideRate     string
+	PinnedRate       string
+	ReassignmentPlan string
+	Pause            string
+	Priority         string
+	Cancelled        string
+	Peaks            string
+	ConfigSnapshot   string
+	ConfigRestore    string
+	Cleanup          string
+}
 
-	m := http.NewServeMux()
+// NewZnodePaths returns the ZnodePaths beneath the autothrottle ZooKeeper
+// prefix.
+func NewZnodePaths(prefix string) ZnodePaths {
+	chroot := fmt.Sprintf("/%s", prefix)
+
+	return ZnodePaths{
+		Chroot:           chroot,
+		OverrideRate:     fmt.Sprintf("%s/%s", chroot, overrideRateZnode),
+		PinnedRate:       fmt.Sprintf("%s/%s", chroot, pinnedRateZnode),
+		ReassignmentPlan: fmt.Sprintf("%s/%s", chroot, reassignmentPlanZnode),
+		Pause:            fmt.Sprintf("%s/%s", chroot, pauseZnode),
+		Priority:         fmt.Sprintf("%s/%s", chroot, priorityZnode),
+		Cancelled:        fmt.Sprintf("%s/%s", chroot, cancelledZnode),
+		Peaks:            fmt.Sprintf("%s/%s", chroot, peaksZnode),
+		ConfigSnapshot:   fmt.Sprintf("%s/%s", chroot, configSnapshotZnode),
+		ConfigRestore:    fmt.Sprintf("%s/%s", chroot, configRestoreZnode),
+		Cleanup:          fmt.Sprintf("%s/%s", chroot, cleanupZnode),
+	}
+}
 
-	// Check ZK for override rate config znode.
-	var exists bool
-	for _, path := range []string{chroot, OverrideRateZnodePath} {
-		var err error
-		exists, err = zk.Exists(path)
-		if err != nil {
-			log.Fatal(err)
-		}
+// Init creates the config znodes and starts the admin API listeners.
+func (a *API) Init(c *APIConfig) {
+	if err := InitZnodes(a.zk, a.Paths, c.ZnodeACL); err != nil {
+		log.Fatal(err)
+	}
 
-		if !exists {
-			// Create chroot.
-			err = zk.Create(path, "")
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
+	m := http.NewServeMux()
+
+	// Routes. A global rate vs broker-specific rate is distinguished in whether
+	// or not there's a trailing slash (and in a properly formed request, the
+	// addition of a broker ID in the request path). Each route is served
+	// beneath the apiVersionPrefix and unversioned.
+	routes := map[string]http.HandlerFunc{
+		"/throttle":                 a.throttleGetSet,
+		"/throttle/":                a.throttleGetSet,
+		"/throttle/remove":          a.throttleRemove,
+		"/throttle/remove/":         a.throttleRemove,
+		"/throttle/brokers":         a.brokerThrottles,
+		"/reassignments":            a.reassignmentSubmitCancel,
+		"/reassignments/":           a.reassignmentCancelTopic,
+		"/reassignment/plan":        a.reassignmentPlanGetSet,
+		"/reassignment/plan/remove": a.reassignmentPlanRemove,
+		"/reassignment/validate":    a.reassignmentValidate,
+		"/pin":                      a.pinGetSet,
+		"/pin/":                     a.pinGetSet,
+		"/pin/remove/":              a.pinRemove,
+		"/priority":                 a.priorityGetSet,
+		"/priority/":                a.priorityGetSet,
+		"/priority/remove/":         a.priorityRemove,
+		"/snapshot":                 a.snapshotGet,
+		"/snapshot/restore":         a.snapshotRestore,
+		"/snapshot/remove":          a.snapshotRemove,
+		"/status":                   a.getStatusHandler,
+		"/capacity/fallback":        a.getFallbackCapacityHandler,
+		"/explain":                  a.getExplainHandler,
+		"/explain/":                 a.getExplainHandler,
+		"/history":                  a.getHistoryHandler,
+		"/pause":                    a.pauseGetSet,
+		"/resume":                   a.resume,
+		"/recalculate":              a.recalculate,
+		"/openapi.json":             getOpenAPIHandler,
 	}
 
-	// If the znode exists, check if it's using the legacy (non-json) format.
-	// If it is, update it to the json format.
-	// TODO(jamie): we can probably remove this by now.
-	if exists {
-		r, _ := zk.Get(OverrideRateZnodePath)
-		if rate, err := strconv.Atoi(string(r)); err == nil {
-			// Populate the updated config.
-			tor := throttlestore.ThrottleOverrideConfig{Rate: rate}
-			err := throttlestore.StoreThrottleOverride(zk, OverrideRateZnodePath, tor)
-			if err != nil {
-				log.Fatal(err)
-			}
+	// Each request is logged once served, and rate limited per client if
+	// configured.
+	rl := newRateLimiter(c.RateLimit, c.RateLimitBurst)
 
-			log.Println("Throttle override config format updated")
-		}
+	for path, h := range routes {
+		h = rateLimited(h, rl)
+		m.Handle(path, logged(h))
+		m.Handle(apiVersionPrefix+path, logged(versioned(h)))
 	}
 
-	// Routes. A global rate vs broker-specific rate is distinguished in whether
-	// or not there's a trailing slash (and in a properly formed request, the
-	// addition of a broker ID in the request path).
-	m.HandleFunc("/throttle", func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
-	m.HandleFunc("/throttle/", func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
-	m.HandleFunc("/throttle/remove", func(w http.ResponseWriter, req *http.Request) { throttleRemove(w, req, zk, trigger) })
-	m.HandleFunc("/throttle/remove/", func(w http.ResponseWriter, req *http.Request) { throttleRemove(w, req, zk, trigger) })
+	if c.Debug {
+		a.registerDebugHandlers(m)
+	}
+
+	if c.Metrics != nil {
+		m.Handle("/metrics", c.Metrics)
+	}
 
 	// Start listener.
+	l, err := listen(c.Listen, c.SocketMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	go func() {
-		err := http.ListenAndServe(c.Listen, m)
-		if err != nil {
+		if err := http.Serve(l, m); err != nil {
 			log.Fatal(err)
 		}
 	}()
+
+	if c.GRPCListen != "" {
+		runRPC(c.GRPCListen, c.SocketMode, NewRPCServer(a))
+	}
 }
 
 // throttleGetSet conditionally handles the request depending on the HTTP method.
-func throttleGetSet(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler, trigger chan<- struct{}) {
-	logReq(req)
-
+func (a *API) throttleGetSet(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case http.MethodGet:
 		// Get a throttle rate.
-		getThrottle(w, req, zk)
+		a.getThrottle(w, req)
 	case http.MethodPost:
 		// Set a throttle rate.
-		setThrottle(w, req, zk)
-		trigger <- struct{}{}
+		a.setThrottle(w, req)
+		a.trigger <- struct{}{}
 	default:
 		// Invalid method.
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -104,14 +188,43 @@ func throttleGetSet(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler
 }
 
 // throttleRemove removes either the global, broker-specific throttle, or all broker-specific throttles.
-func throttleRemove(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler, trigger chan<- struct{}) {
-	logReq(req)
-
+func (a *API) throttleRemove(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case http.MethodPost:
 		// Remove the throttle.
-		removeThrottle(w, req, zk)
-		trigger <- struct{}{}
+		a.removeThrottle(w, req)
+		a.trigger <- struct{}{}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// brokerThrottles lists, bulk sets or removes all broker-specific throttle
+// overrides.
+func (a *API) brokerThrottles(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		// List all broker overrides.
+		a.getBrokerThrottles(w)
+	case http.MethodPost:
+		// Set overrides for several brokers.
+		if a.setBrokerThrottles(w, req) {
+			a.trigger <- struct{}{}
+		}
+	case http.MethodDelete:
+		// Remove all broker overrides.
+		requester, err := parseRequesterParam(req)
+		if err != nil {
+			writeNLError(w, err)
+			return
+		}
+
+		if a.removeAllBrokerThrottles(w, requester) {
+			a.trigger <- struct{}{}
+		}
 	default:
 		// Invalid method.
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -120,8 +233,188 @@ func throttleRemove(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler
 	}
 }
 
+// getBrokerThrottles writes all active broker-specific throttle overrides.
+func (a *API) getBrokerThrottles(w http.ResponseWriter) {
+	overrides, err := throttlestore.FetchBrokerOverrides(a.zk, a.Paths.OverrideRate)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	// Exclude overrides marked for removal.
+	overrides = overrides.Filter(func(bto throttlestore.BrokerThrottleOverride) bool {
+		return bto.Config.Rate != 0
+	})
+
+	if len(overrides) == 0 {
+		io.WriteString(w, "no broker throttle overrides are set\n")
+		return
+	}
+
+	ids := overrides.IDs()
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		c := overrides[id].Config
+		io.WriteString(w, fmt.Sprintf("broker %d: a throttle override is configured at %dMB/s, autoremove==%v%s%s%s, reassigning==%v%s\n",
+			id, c.Rate, c.AutoRemove, startAtMessage(c.StartAt), expiresMessage(c.Expires), precedenceMessage(c.Precedence), a.isReassigningBroker(id), requestersMessage(c)))
+	}
+}
+
+// setBrokerThrottles sets broker-specific throttle overrides from the request
+// body, which is expected to be a JSON map of broker ID to rate. The autoremove,
+// ttl, precedence and requester params apply to all brokers. Every broker ID is
+// validated against the registered brokers before any override is set, and the
+// result for each broker is written. A bool is returned indicating whether any
+// override was set.
+func (a *API) setBrokerThrottles(w http.ResponseWriter, req *http.Request) bool {
+	autoRemove, err := parseAutoRemoveParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	ttl, err := parseTTLParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	startAt, expires, err := parseScheduleParams(req, ttl)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	precedence, err := parsePrecedenceParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	requester, err := parseRequesterParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	force, err := parseForceParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	var rates map[string]int
+	if err := json.NewDecoder(req.Body).Decode(&rates); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, fmt.Errorf("error parsing broker rates: %s", err))
+		return false
+	}
+
+	byID, errs := a.validateBrokerRates(rates)
+	if errs != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, errors.Join(errs...))
+		return false
+	}
+
+	ids := make([]int, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	// Check each rate against the known broker capacity.
+	warnings := map[int]string{}
+	for _, id := range ids {
+		exceeded, warning := a.checkOverrideRate(byID[id], []int{id})
+		if len(exceeded) > 0 {
+			err := capacityExceededError(byID[id], exceeded)
+			if !force {
+				errs = append(errs, fmt.Errorf("%s; set force=true to apply it anyway", err))
+				continue
+			}
+			warning = fmt.Sprintf("warning: %s", err)
+		}
+		warnings[id] = warning
+	}
+
+	if errs != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, errors.Join(errs...))
+		return false
+	}
+
+	var set bool
+	for _, id := range ids {
+		rateCfg := throttlestore.ThrottleOverrideConfig{
+			Rate:       byID[id],
+			AutoRemove: autoRemove,
+			Expires:    expires,
+			StartAt:    startAt,
+			Precedence: precedence,
+		}
+
+		configPath := fmt.Sprintf("%s/%d", a.Paths.OverrideRate, id)
+		if _, err := throttlestore.SetRequesterOverride(a.zk, configPath, requester, rateCfg); err != nil {
+			io.WriteString(w, fmt.Sprintf("broker %d: %s\n", id, err))
+			continue
+		}
+
+		set = true
+		io.WriteString(w, fmt.Sprintf("broker %d: throttle successfully set to %dMB/s, autoremove==%v%s%s%s%s\n",
+			id, rateCfg.Rate, autoRemove, startAtMessage(startAt), expiresMessage(expires), precedenceMessage(precedence), requesterMessage(requester)))
+
+		if warnings[id] != "" {
+			io.WriteString(w, fmt.Sprintf("broker %d: %s\n", id, warnings[id]))
+		}
+	}
+
+	return set
+}
+
+// validateBrokerRates takes a map of broker ID to rate and checks that each
+// broker ID is an integer referencing a registered broker and that each rate
+// is >0. The rates are returned keyed by the integer broker ID along with an
+// error for each invalid entry.
+func (a *API) validateBrokerRates(rates map[string]int) (map[int]int, []error) {
+	if len(rates) == 0 {
+		return nil, []error{errors.New("no broker rates provided")}
+	}
+
+	brokers, errs := a.zk.GetAllBrokerMeta(false)
+	if errs != nil {
+		return nil, []error{fmt.Errorf("error fetching brokers: %s", errs)}
+	}
+
+	keys := make([]string, 0, len(rates))
+	for k := range rates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	byID := map[int]int{}
+	var invalid []error
+
+	for _, k := range keys {
+		id, err := strconv.Atoi(k)
+		switch {
+		case err != nil:
+			invalid = append(invalid, fmt.Errorf("broker %s: broker ID must be an integer", k))
+		case brokers[id] == nil:
+			invalid = append(invalid, fmt.Errorf("broker %d: broker doesn't exist", id))
+		case rates[k] < 1:
+			invalid = append(invalid, fmt.Errorf("broker %d: rate must be >0", id))
+		default:
+			byID[id] = rates[k]
+		}
+	}
+
+	return byID, invalid
+}
+
 // getThrottle returns the throttle rate applied to all brokers.
-func getThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
+func (a *API) getThrottle(w http.ResponseWriter, req *http.Request) {
 	// Determine whether this is a global or broker-specific throttle lookup.
 	var id string
 	paths := parsePaths(req)
@@ -134,7 +427,7 @@ func getThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
 		}
 	}
 
-	configPath := OverrideRateZnodePath
+	configPath := a.Paths.OverrideRate
 
 	// A non-0 ID means that this is broker specific.
 	if id != "" {
@@ -142,9 +435,9 @@ func getThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
 		configPath = fmt.Sprintf("%s/%s", configPath, id)
 	}
 
-	r, err := throttlestore.FetchThrottleOverride(zk, configPath)
+	r, err := throttlestore.FetchThrottleOverride(a.zk, configPath)
 
-	respMessage := fmt.Sprintf("a throttle override is configured at %dMB/s, autoremove==%v\n", r.Rate, r.AutoRemove)
+	respMessage := fmt.Sprintf("a throttle override is configured at %dMB/s, autoremove==%v%s%s%s%s\n", r.Rate, r.AutoRemove, startAtMessage(r.StartAt), expiresMessage(r.Expires), precedenceMessage(r.Precedence), requestersMessage(*r))
 	noOverrideMessage := "no throttle override is set\n"
 
 	// Update the response message.
@@ -174,7 +467,7 @@ func getThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
 }
 
 // setThrottle sets a throtle rate that applies to all brokers.
-func setThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
+func (a *API) setThrottle(w http.ResponseWriter, req *http.Request) {
 	// Check rate param.
 	rate, err := parseRateParam(req)
 	if err != nil {
@@ -189,10 +482,48 @@ func setThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
 		return
 	}
 
+	// Check ttl param.
+	ttl, err := parseTTLParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	// Check start_at and end_at params.
+	startAt, expires, err := parseScheduleParams(req, ttl)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	// Check precedence param.
+	precedence, err := parsePrecedenceParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	// Check requester param.
+	requester, err := parseRequesterParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	// Check force param.
+	force, err := parseForceParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
 	// Populate configs.
 	rateCfg := throttlestore.ThrottleOverrideConfig{
 		Rate:       rate,
 		AutoRemove: autoRemove,
+		Expires:    expires,
+		StartAt:    startAt,
+		Precedence: precedence,
 	}
 
 	// Determine whether this is a global or broker-specific override.
@@ -206,20 +537,56 @@ func setThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
 		}
 	}
 
-	updateMessage := fmt.Sprintf("throttle successfully set to %dMB/s, autoremove==%v\n", rate, autoRemove)
-	configPath := OverrideRateZnodePath
+	if id == "" && precedence != "" {
+		writeNLError(w, errPrecedenceGlobal)
+		return
+	}
+
+	// Check the rate against the known capacity of the brokers the override
+	// applies to.
+	var ids []int
+	if id != "" {
+		ids = []int{}
+		if n, err := strconv.Atoi(id); err == nil {
+			ids = append(ids, n)
+		}
+	}
 
-	writeOverride(w, id, configPath, updateMessage, err, zk, rateCfg)
+	exceeded, warning := a.checkOverrideRate(rate, ids)
+	if len(exceeded) > 0 {
+		err := capacityExceededError(rate, exceeded)
+		if !force {
+			w.WriteHeader(http.StatusBadRequest)
+			writeNLError(w, fmt.Errorf("%s; set force=true to apply it anyway", err))
+			return
+		}
+		warning = fmt.Sprintf("warning: %s", err)
+	}
+
+	updateMessage := fmt.Sprintf("throttle successfully set to %dMB/s, autoremove==%v%s%s%s%s\n", rate, autoRemove, startAtMessage(startAt), expiresMessage(expires), precedenceMessage(precedence), requesterMessage(requester))
+	if warning != "" {
+		updateMessage = fmt.Sprintf("%s%s\n", updateMessage, warning)
+	}
+
+	configPath := a.Paths.OverrideRate
+
+	a.writeOverride(w, id, configPath, updateMessage, err, requester, rateCfg)
 }
 
 // removeThrottle removes the throttle rate for a specific broker, the global rate, or for all brokers.
-func removeThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler) {
+func (a *API) removeThrottle(w http.ResponseWriter, req *http.Request) {
 	// Removing a rate means setting it to 0.
 	c := throttlestore.ThrottleOverrideConfig{
 		Rate:       0,
 		AutoRemove: false,
 	}
 
+	requester, err := parseRequesterParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
 	// Determine whether this is a global or broker-specific throttle lookup.
 	var id string
 	paths := parsePaths(req)
@@ -232,39 +599,65 @@ func removeThrottle(w http.ResponseWriter, req *http.Request, zk kafkazk.Handler
 		}
 	}
 
-	configPath := OverrideRateZnodePath
-	updateMessage := "throttle removed\n"
-
-	var err error
+	configPath := a.Paths.OverrideRate
+	updateMessage := fmt.Sprintf("throttle removed%s\n", requesterMessage(requester))
 
 	if id == "all" {
 		// Instead of specifying a broker, the string 'all' means clear all overrides we have by setting to 0.
-		var children []string
-		var parentPath = OverrideRateZnodePath
-		children, err = zk.Children(parentPath)
+		a.removeAllBrokerThrottles(w, requester)
+	} else {
+		a.writeOverride(w, id, configPath, updateMessage, err, requester, c)
+	}
+}
+
+// removeAllBrokerThrottles removes the broker-specific throttle overrides of
+// requester for all brokers. A bool is returned indicating whether any
+// override was removed.
+func (a *API) removeAllBrokerThrottles(w http.ResponseWriter, requester string) bool {
+	// Removing a rate means setting it to 0.
+	c := throttlestore.ThrottleOverrideConfig{
+		Rate:       0,
+		AutoRemove: false,
+	}
 
-		sort.Strings(children)
+	configPath := a.Paths.OverrideRate
+	updateMessage := fmt.Sprintf("throttle removed%s\n", requesterMessage(requester))
 
-		// iterate through all broker ids we have under the parent
-		for _, childId := range children {
-			if _, err := strconv.Atoi(childId); err != nil {
-				var invalidBrokerMsg = fmt.Sprintf("invalid node %q is not an integer under path %q", childId, parentPath)
-				io.WriteString(w, invalidBrokerMsg)
-			}
-			writeOverride(w, childId, configPath, updateMessage, err, zk, c)
+	var parentPath = a.Paths.OverrideRate
+	children, err := a.zk.Children(parentPath)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	sort.Strings(children)
+
+	// iterate through all broker ids we have under the parent
+	var removed bool
+	for _, childId := range children {
+		if _, err := strconv.Atoi(childId); err != nil {
+			var invalidBrokerMsg = fmt.Sprintf("invalid node %q is not an integer under path %q\n", childId, parentPath)
+			io.WriteString(w, invalidBrokerMsg)
+			continue
+		}
+		if a.writeOverride(w, childId, configPath, updateMessage, err, requester, c) {
+			removed = true
 		}
-	} else {
-		writeOverride(w, id, configPath, updateMessage, err, zk, c)
 	}
+
+	return removed
 }
 
-func writeOverride(w http.ResponseWriter, id string, configPath string, updateMessage string, err error, zk kafkazk.Handler, c throttlestore.ThrottleOverrideConfig) {
+// writeOverride sets the override of requester to c, leaving the overrides
+// of any other requesters in place. A bool is returned indicating whether the
+// override was written.
+func (a *API) writeOverride(w http.ResponseWriter, id string, configPath string, updateMessage string, err error, requester string, c throttlestore.ThrottleOverrideConfig) bool {
 	// A non-0 ID means that this is broker specific.
 	if id != "" {
 		configPath, updateMessage = formatConfigAndMessage(configPath, id, updateMessage)
 	}
 
-	err = throttlestore.StoreThrottleOverride(zk, configPath, c)
+	_, err = throttlestore.SetRequesterOverride(a.zk, configPath, requester, c)
 
 	if err != nil {
 		switch err {
@@ -272,11 +665,80 @@ func writeOverride(w http.ResponseWriter, id string, configPath string, updateMe
 			// Do nothing.
 		default:
 			writeNLError(w, err)
-			return
+			return false
 		}
 	}
 
 	io.WriteString(w, updateMessage)
+
+	return err == nil
+}
+
+// expiresMessage takes an override expiry Unix timestamp and returns a message
+// suffix describing it, or an empty string if no expiry is set.
+func expiresMessage(expires int64) string {
+	if expires == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(", expires==%s", time.Unix(expires, 0).UTC().Format(time.RFC3339))
+}
+
+// startAtMessage takes an override start Unix timestamp and returns a message
+// suffix describing it, or an empty string if no start is set.
+func startAtMessage(startAt int64) string {
+	if startAt == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(", starts==%s", time.Unix(startAt, 0).UTC().Format(time.RFC3339))
+}
+
+// precedenceMessage takes a broker override precedence and returns a message
+// suffix describing it, or an empty string if unset.
+func precedenceMessage(precedence string) string {
+	if precedence == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", precedence==%s", precedence)
+}
+
+// requesterMessage takes an override requester and returns a message suffix
+// describing it, or an empty string if unset.
+func requesterMessage(requester string) string {
+	if requester == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", requester==%s", requester)
+}
+
+// requestersMessage takes a ThrottleOverrideConfig and returns a message suffix
+// listing the override of each requester, or an empty string if the override
+// wasn't set by requester. Overrides set without a requester are listed as
+// "-".
+func requestersMessage(c throttlestore.ThrottleOverrideConfig) string {
+	if c.Requesters == nil {
+		return ""
+	}
+
+	var requesters []string
+	for r := range c.Requesters {
+		requesters = append(requesters, r)
+	}
+	sort.Strings(requesters)
+
+	var s []string
+	for _, r := range requesters {
+		name := r
+		if name == "" {
+			name = "-"
+		}
+		s = append(s, fmt.Sprintf("%s:%dMB/s", name, c.Requesters[r].Rate))
+	}
+
+	return fmt.Sprintf(", requesters==[%s]", strings.Join(s, " "))
 }
 
 func formatConfigAndMessage(configPath string, id string, updateMessage string) (string, string) {
@@ -284,3 +746,78 @@ func formatConfigAndMessage(configPath string, id string, updateMessage string)
 	updateMessage = fmt.Sprintf("broker %s: %s", id, updateMessage)
 	return configPath, updateMessage
 }
+
+// InitZnodes takes a kafkazk.Handler and the autothrottle config ZnodePaths and
+// creates the override and pinned rate, topic priority and capacity peaks
+// config znodes if they don't exist. If acl is non-empty, it's applied to the
+// chroot and any existing config znodes so that they can't be modified by other
+// ZooKeeper clients.
+func InitZnodes(zk kafkazk.Handler, p ZnodePaths, acl []kafkazk.ACL) error {
+	// Check ZK for the priority, capacity peaks, pinned rate and override rate
+	// config znodes.
+	var exists bool
+	for _, path := range []string{p.Chroot, p.Priority, p.Peaks, p.PinnedRate, p.OverrideRate} {
+		var err error
+		exists, err = zk.Exists(path)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !exists && len(acl) > 0:
+			err = zk.CreateWithACL(path, "", acl)
+		case !exists:
+			err = zk.Create(path, "")
+		case len(acl) > 0:
+			err = zk.SetACL(path, acl)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// The pause, reassignment plan, cancelled reassignments, config snapshot,
+	// cleanup state and per-broker and per-topic config znodes are created as needed with the
+	// ACL of their parent; protect any that already exist.
+	if len(acl) > 0 {
+		paths := []string{p.Pause, p.ReassignmentPlan, p.Cancelled, p.ConfigSnapshot, p.ConfigRestore, p.Cleanup}
+		for _, parent := range []string{p.OverrideRate, p.PinnedRate, p.Priority, p.Peaks} {
+			children, err := zk.Children(parent)
+			if err != nil {
+				return err
+			}
+			for _, c := range children {
+				paths = append(paths, fmt.Sprintf("%s/%s", parent, c))
+			}
+		}
+
+		for _, path := range paths {
+			if e, _ := zk.Exists(path); !e {
+				continue
+			}
+			if err := zk.SetACL(path, acl); err != nil {
+				return err
+			}
+		}
+	}
+
+	// If the znode exists, check if it's using the legacy (non-json) format.
+	// If it is, update it to the json format.
+	// TODO(jamie): we can probably remove this by now.
+	if exists {
+		r, _ := zk.Get(p.OverrideRate)
+		if rate, err := strconv.Atoi(string(r)); err == nil {
+			// Populate the updated config.
+			tor := throttlestore.ThrottleOverrideConfig{Rate: rate}
+			err := throttlestore.StoreThrottleOverride(zk, p.OverrideRate, tor)
+			if err != nil {
+				return err
+			}
+
+			log.Println("Throttle override config format updated")
+		}
+	}
+
+	return nil
+}
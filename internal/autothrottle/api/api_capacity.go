@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// checkOverrideRate takes an override rate and the IDs of the brokers the
+// override applies to, or nil for the global override, which applies to all
+// brokers. The rate is checked against the network capacity of each broker
+// with a known instance type, as last observed in broker metrics, and against
+// the configured minimum rate. A description of each broker whose capacity
+// the rate exceeds is returned, along with a warning if the rate is below
+// the minimum.
+func (a *API) checkOverrideRate(rate int, ids []int) ([]string, string) {
+	limits := a.getLimits()
+	instanceTypes := a.getStatus().BrokerInstanceTypes
+
+	if ids == nil {
+		for id := range instanceTypes {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+
+	var exceeded []string
+	for _, id := range ids {
+		instanceType, known := instanceTypes[id]
+		if !known {
+			continue
+		}
+
+		capacity, known := instanceTypeCapacity(limits, instanceType)
+		if known && float64(rate) > capacity {
+			exceeded = append(exceeded, fmt.Sprintf("broker %d (%s, %.2fMB/s)", id, instanceType, capacity))
+		}
+	}
+
+	var warning string
+	if min, exists := limits["minimum"]; exists && float64(rate) < min {
+		warning = fmt.Sprintf("warning: the rate is below the min-rate of %.2fMB/s", min)
+	}
+
+	return exceeded, warning
+}
+
+// capacityExceededError returns an error describing the brokers whose
+// capacity an override rate exceeds.
+func capacityExceededError(rate int, exceeded []string) error {
+	return fmt.Errorf("rate %dMB/s exceeds the network capacity of %s", rate, strings.Join(exceeded, ", "))
+}
+
+// instanceTypeCapacity returns the network capacity in MB/s of the instance
+// type in the limits, or the default capacity if the instance type has none
+// configured, and whether a capacity is known. For instance types with
+// distinct outbound and inbound capacities, the lower is returned.
+func instanceTypeCapacity(limits map[string]float64, instanceType string) (float64, bool) {
+	if v, exists := limits[instanceType]; exists {
+		return v, true
+	}
+
+	tx, txExists := limits["tx:"+instanceType]
+	rx, rxExists := limits["rx:"+instanceType]
+	if txExists && rxExists {
+		return math.Min(tx, rx), true
+	}
+
+	v, exists := limits["defaultCapacity"]
+
+	return v, exists
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+)
+
+var publishDebugVars sync.Once
+
+// registerDebugHandlers registers the pprof profiling endpoints beneath
+// /debug/pprof/ and the expvar variables at /debug/vars on the ServeMux. In
+// addition to the memstats and cmdline variables published by expvar, the
+// goroutine count and the number of reassigning topics and brokers as of the
+// most recent interval are served.
+func (a *API) registerDebugHandlers(m *http.ServeMux) {
+	publishDebugVars.Do(func() {
+		expvar.Publish("goroutines", expvar.Func(func() interface{} {
+			return runtime.NumGoroutine()
+		}))
+	})
+
+	// pprof.Index also serves the named runtime profiles, e.g.
+	// /debug/pprof/heap and /debug/pprof/goroutine.
+	m.HandleFunc("/debug/pprof/", pprof.Index)
+	m.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	m.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	m.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	m.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	m.HandleFunc("/debug/vars", a.debugVarsHandler)
+}
+
+// debugVarsHandler serves the published expvar variables along with the
+// reassignment variables of the API, which aren't published since expvar
+// variables are process-wide.
+func (a *API) debugVarsHandler(w http.ResponseWriter, req *http.Request) {
+	st := a.getStatus()
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, "{\n")
+	expvar.Do(func(kv expvar.KeyValue) {
+		fmt.Fprintf(w, "%q: %s,\n", kv.Key, kv.Value)
+	})
+	fmt.Fprintf(w, "%q: %d,\n", "reassigning_topics", len(st.ReassigningTopics))
+	fmt.Fprintf(w, "%q: %d\n", "reassigning_brokers", len(st.ReassigningBrokers))
+	fmt.Fprintf(w, "}\n")
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// getExplainHandler writes how the throttle rates of each broker participating
+// in a reassignment were resolved as of the most recent interval, or of a
+// single broker with /explain/<ID>.
+func (a *API) getExplainHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	resolutions := a.getStatus().Resolutions
+
+	var ids []int
+	if paths := parsePaths(req); len(paths) > 1 && paths[1] != "" {
+		id, err := strconv.Atoi(paths[1])
+		if err != nil {
+			writeNLError(w, errPinBrokerIDNotInt)
+			return
+		}
+
+		if _, exists := resolutions[id]; !exists {
+			io.WriteString(w, fmt.Sprintf("broker %d isn't participating in a reassignment\n", id))
+			return
+		}
+
+		ids = []int{id}
+	} else {
+		for id := range resolutions {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+	}
+
+	if len(ids) == 0 {
+		io.WriteString(w, "no brokers participating in a reassignment\n")
+		return
+	}
+
+	var b strings.Builder
+
+	for _, id := range ids {
+		r := resolutions[id]
+		fmt.Fprintf(&b, "broker %d: %s, source==%s\n", id, formatRoleRates(r.Rates), r.Reason)
+
+		for _, s := range r.Steps {
+			if s.Applied {
+				fmt.Fprintf(&b, "  applied %s: %s (%s)\n", s.Source, s.Detail, formatRoleRates(s.Rates))
+			} else {
+				fmt.Fprintf(&b, "  skipped %s: %s\n", s.Source, s.Detail)
+			}
+		}
+	}
+
+	io.WriteString(w, b.String())
+}
+
+// formatRoleRates returns leader and follower rates as "leader <rate>,
+// follower <rate>". Roles without a rate are shown as "-".
+func formatRoleRates(rates [2]*float64) string {
+	s := [2]string{"-", "-"}
+	for n, rate := range rates {
+		if rate != nil {
+			s[n] = fmt.Sprintf("%.2f", *rate)
+		}
+	}
+
+	return fmt.Sprintf("leader %s, follower %s", s[0], s[1])
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/history"
+)
+
+// defaultHistoryWindow is the window of history returned if the minutes
+// param is unspecified.
+var defaultHistoryWindow = 60 * time.Minute
+
+// getHistoryHandler writes the throttle rates and measured network utilization
+// of each broker for the intervals within the requested number of minutes.
+func (a *API) getHistoryHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	window, err := parseMinutesParam(req, defaultHistoryWindow)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, err)
+		return
+	}
+
+	h := a.getHistory()
+	if h == nil {
+		io.WriteString(w, "history is disabled\n")
+		return
+	}
+
+	entries := h.Since(time.Now().Add(-window))
+	if len(entries) == 0 {
+		io.WriteString(w, "no history in the window\n")
+		return
+	}
+
+	var b strings.Builder
+
+	b.WriteString("interval: [ID, leader, follower, net tx, net rx] (MB/s)\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s: %s\n", e.Time.UTC().Format(time.RFC3339), formatHistoryBrokers(e.Brokers))
+	}
+
+	io.WriteString(w, b.String())
+}
+
+// formatHistoryBrokers returns the broker rates as a list of [ID, leader rate,
+// follower rate, net tx, net rx] sorted by broker ID. Rates that weren't
+// applied or measured are shown as "-".
+func formatHistoryBrokers(brokers map[int]history.BrokerRates) string {
+	if len(brokers) == 0 {
+		return "-"
+	}
+
+	var ids []int
+	for id := range brokers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var entries []string
+	for _, id := range ids {
+		r := brokers[id]
+
+		var rates []string
+		for _, rate := range []*float64{r.Leader, r.Follower, r.NetTX, r.NetRX} {
+			if rate == nil {
+				rates = append(rates, "-")
+				continue
+			}
+			rates = append(rates, fmt.Sprintf("%.2f", *rate))
+		}
+
+		entries = append(entries, fmt.Sprintf("[%d, %s]", id, strings.Join(rates, ", ")))
+	}
+
+	return strings.Join(entries, ", ")
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var errRateLimited = errors.New("rate limit exceeded; retry later")
+
+// maxRateLimitClients is the number of clients tracked by a rateLimiter
+// before clients with a full bucket are dropped.
+const maxRateLimitClients = 1024
+
+// rateLimiter is a token bucket rate limiter per client. Each client's bucket
+// holds up to burst tokens and is refilled at rate tokens per second.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	clients map[string]*tokenBucket
+	now     func() time.Time
+}
+
+// tokenBucket holds the tokens available to a client as of the last request.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter takes a rate in requests per second and a burst size and
+// returns a *rateLimiter. A burst of less than 1 is set to the rate, rounded
+// up. A nil *rateLimiter is returned if the rate is 0, allowing all requests.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	b := float64(burst)
+	if b < 1 {
+		b = math.Ceil(rate)
+	}
+
+	return &rateLimiter{
+		rate:    rate,
+		burst:   b,
+		clients: map[string]*tokenBucket{},
+		now:     time.Now,
+	}
+}
+
+// allow returns whether a request from the client is allowed. If not, the
+// time until a request would be allowed is also returned.
+func (r *rateLimiter) allow(client string) (bool, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	b, exists := r.clients[client]
+	if !exists {
+		if len(r.clients) >= maxRateLimitClients {
+			r.prune(now)
+		}
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.clients[client] = b
+	}
+
+	b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*r.rate, r.burst)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / r.rate * float64(time.Second))
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// prune drops the clients whose buckets would be full as of now, since
+// they're indistinguishable from new clients.
+func (r *rateLimiter) prune(now time.Time) {
+	for client, b := range r.clients {
+		if b.tokens+now.Sub(b.last).Seconds()*r.rate >= r.burst {
+			delete(r.clients, client)
+		}
+	}
+}
+
+// rateLimited takes an http.HandlerFunc and returns one where requests are
+// rejected with a 429 once the client exceeds the rateLimiter's rate. The
+// http.HandlerFunc is returned as-is if the rateLimiter is nil.
+func rateLimited(h http.HandlerFunc, rl *rateLimiter) http.HandlerFunc {
+	if rl == nil {
+		return h
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		if ok, wait := rl.allow(clientAddr(req)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			writeNLError(w, errRateLimited)
+			return
+		}
+
+		h(w, req)
+	}
+}
+
+// statusRecorder is an http.ResponseWriter that records the response status.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes it.
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records an implicit 200 status if none was written and writes b.
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// logged takes an http.Handler and returns one that logs the method, path,
+// caller, response status and latency of each request once served.
+func logged(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		h.ServeHTTP(rec, req)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		log.Printf("[API] %s %s %s %d %s\n", req.Method, req.RequestURI, caller(req), rec.status, time.Since(start).Round(time.Microsecond))
+	})
+}
+
+// clientAddr returns the address of the client that made the request, without
+// the port. Requests over Unix domain sockets return "unix".
+func clientAddr(req *http.Request) string {
+	if req.RemoteAddr == "" || req.RemoteAddr == "@" {
+		return "unix"
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
+
+// caller returns the client address of the request, along with the value of
+// the 'requester' param if set.
+func caller(req *http.Request) string {
+	if r := req.URL.Query().Get("requester"); r != "" && requesterPattern.MatchString(r) {
+		return fmt.Sprintf("%s (requester %s)", clientAddr(req), r)
+	}
+
+	return clientAddr(req)
+}
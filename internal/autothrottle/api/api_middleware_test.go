@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(0, 10) != nil {
+		t.Error("Expected a nil rateLimiter with a rate of 0")
+	}
+
+	now := time.Unix(1700000000, 0)
+	rl := newRateLimiter(1, 2)
+	rl.now = func() time.Time { return now }
+
+	// The burst is allowed at once.
+	for i := 0; i < 2; i++ {
+		if ok, _ := rl.allow("10.0.0.1"); !ok {
+			t.Fatalf("[request %d] Expected request allowed", i)
+		}
+	}
+
+	ok, wait := rl.allow("10.0.0.1")
+	if ok {
+		t.Fatal("Expected request limited")
+	}
+
+	if wait != time.Second {
+		t.Errorf("Expected a wait of 1s, got %s", wait)
+	}
+
+	// Other clients have their own bucket.
+	if ok, _ := rl.allow("10.0.0.2"); !ok {
+		t.Error("Expected request from another client allowed")
+	}
+
+	// Tokens are refilled at the rate.
+	now = now.Add(time.Second)
+	if ok, _ := rl.allow("10.0.0.1"); !ok {
+		t.Error("Expected request allowed after refill")
+	}
+
+	if ok, _ := rl.allow("10.0.0.1"); ok {
+		t.Error("Expected request limited")
+	}
+
+	// Clients with a full bucket are pruned.
+	now = now.Add(time.Minute)
+	rl.prune(now)
+	if len(rl.clients) != 0 {
+		t.Errorf("Expected 0 clients, got %d", len(rl.clients))
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	rl := newRateLimiter(1, 1)
+	h := rateLimited(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok\n"))
+	}, rl)
+
+	req, err := http.NewRequest("GET", "/status", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.RemoteAddr = "10.0.0.1:50000"
+
+	rr := httptest.NewRecorder()
+	h(rr, req)
+	checkResults(http.StatusOK, "ok\n", rr, t)
+
+	rr = httptest.NewRecorder()
+	h(rr, req)
+	checkResults(http.StatusTooManyRequests, "rate limit exceeded; retry later\n", rr, t)
+
+	if ra := rr.Header().Get("Retry-After"); ra != "1" {
+		t.Errorf("Expected Retry-After 1, got %s", ra)
+	}
+
+	// Versioned routes write the error as JSON.
+	rr = httptest.NewRecorder()
+	req.URL.Path = apiVersionPrefix + "/status"
+	versioned(h).ServeHTTP(rr, req)
+	checkResults(http.StatusTooManyRequests, "{\"code\":429,\"message\":\"rate limit exceeded; retry later\"}\n", rr, t)
+}
+
+func TestLogged(t *testing.T) {
+	var status int
+	h := logged(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		status = w.(*statusRecorder).status
+	}))
+
+	req, err := http.NewRequest("POST", "/status", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if status != http.StatusMethodNotAllowed || rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d recorded, got %d", http.StatusMethodNotAllowed, status)
+	}
+}
+
+func TestCaller(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		query      string
+		expected   string
+	}{
+		{"10.0.0.1:50000", "", "10.0.0.1"},
+		{"10.0.0.1:50000", "requester=alice", "10.0.0.1 (requester alice)"},
+		{"10.0.0.1:50000", "requester=not%20valid", "10.0.0.1"},
+		{"@", "", "unix"},
+		{"", "", "unix"},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest("GET", "/throttle?"+test.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = test.remoteAddr
+
+		if c := caller(req); c != test.expected {
+			t.Errorf("[%s?%s] Expected caller %s, got %s", test.remoteAddr, test.query, test.expected, c)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the OpenAPI document describing the admin API. It must be
+// updated along with any route changes.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// getOpenAPIHandler writes the admin API OpenAPI document.
+func getOpenAPIHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
@@ -3,18 +3,37 @@ package api
 import (
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
 )
 
 var (
 	errBrokerIDNotProvided  = errors.New("broker ID not provided")
+	errBrokerIDInvalid      = errors.New("broker param must be provided as integer or the string 'all'")
 	errRateParamUnspecified = errors.New("rate param must be specified")
 	errRateParamIsZero      = errors.New("rate param must be >0")
 	errRateParamNotInt      = errors.New("rate param must be supplied as an integer")
 	errAutoRemoveNotBool    = errors.New("autoremove param must be a bool")
+	errForceNotBool         = errors.New("force param must be a bool")
+	errTTLInvalid           = errors.New("ttl param must be a duration >0 (e.g. 30m, 2h)")
+	errPrecedenceInvalid    = errors.New("precedence param must be one of override, min, max")
+	errPrecedenceGlobal     = errors.New("precedence param is only valid for broker-specific throttles")
+	errBatchSizeUnspecified = errors.New("batch_size param must be specified")
+	errBatchSizeInvalid     = errors.New("batch_size param must be an integer >0")
+	errWeightInvalid        = errors.New("weight param must be an integer >0")
+	errRequesterInvalid     = errors.New("requester param must be at most 64 letters, digits, '.', '_' or '-'")
+	errStartAtInvalid       = errors.New("start_at param must be an RFC3339 timestamp or Unix seconds")
+	errEndAtInvalid         = errors.New("end_at param must be an RFC3339 timestamp or Unix seconds in the future")
+	errEndAtTTL             = errors.New("end_at and ttl params are mutually exclusive")
+	errEndAtBeforeStartAt   = errors.New("end_at param must be after start_at")
+	errMinutesInvalid       = errors.New("minutes param must be an integer >0")
+
+	requesterPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
 )
 
 // parseRateParam takes a *http.Request and returns the specified
@@ -52,6 +71,159 @@ func parseAutoRemoveParam(req *http.Request) (bool, error) {
 	return autoRemove, nil
 }
 
+// parseForceParam takes a *http.Request and returns the specified force
+// parameter as a bool.
+func parseForceParam(req *http.Request) (bool, error) {
+	f := req.URL.Query().Get("force")
+	if f == "" {
+		return false, nil
+	}
+
+	force, err := strconv.ParseBool(f)
+	if err != nil {
+		return false, errForceNotBool
+	}
+
+	return force, nil
+}
+
+// parseTTLParam takes a *http.Request and returns the specified 'ttl' request
+// parameter as a time.Duration. A 0 duration is returned if unspecified.
+func parseTTLParam(req *http.Request) (time.Duration, error) {
+	t := req.URL.Query().Get("ttl")
+	if t == "" {
+		return 0, nil
+	}
+
+	ttl, err := time.ParseDuration(t)
+	if err != nil || ttl <= 0 {
+		return 0, errTTLInvalid
+	}
+
+	return ttl, nil
+}
+
+// parseScheduleParams takes a *http.Request and the parsed ttl param and
+// returns the override start and expiry Unix timestamps from the 'start_at'
+// and 'end_at' request parameters, which are RFC3339 timestamps or Unix
+// seconds. If end_at is unspecified, the expiry is determined by the ttl,
+// relative to the start if specified. Unset timestamps are returned as 0.
+func parseScheduleParams(req *http.Request, ttl time.Duration) (int64, int64, error) {
+	var startAt, expires int64
+
+	start := time.Now()
+	if s := req.URL.Query().Get("start_at"); s != "" {
+		t, ok := parseTimestamp(s)
+		if !ok {
+			return 0, 0, errStartAtInvalid
+		}
+		start, startAt = t, t.Unix()
+	}
+
+	if s := req.URL.Query().Get("end_at"); s != "" {
+		if ttl > 0 {
+			return 0, 0, errEndAtTTL
+		}
+
+		t, ok := parseTimestamp(s)
+		if !ok || !t.After(time.Now()) {
+			return 0, 0, errEndAtInvalid
+		}
+
+		if !t.After(start) {
+			return 0, 0, errEndAtBeforeStartAt
+		}
+
+		return startAt, t.Unix(), nil
+	}
+
+	if ttl > 0 {
+		expires = start.Add(ttl).Unix()
+	}
+
+	return startAt, expires, nil
+}
+
+// parseTimestamp parses an RFC3339 timestamp or Unix seconds.
+func parseTimestamp(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil && secs > 0 {
+		return time.Unix(secs, 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// parsePrecedenceParam takes a *http.Request and returns the specified
+// 'precedence' request parameter. An empty string is returned if unspecified.
+func parsePrecedenceParam(req *http.Request) (string, error) {
+	p := req.URL.Query().Get("precedence")
+	if !throttlestore.ValidPrecedence(p) {
+		return "", errPrecedenceInvalid
+	}
+
+	return p, nil
+}
+
+// parseRequesterParam takes a *http.Request and returns the specified
+// 'requester' request parameter. An empty string is returned if unspecified.
+func parseRequesterParam(req *http.Request) (string, error) {
+	r := req.URL.Query().Get("requester")
+	if r != "" && !requesterPattern.MatchString(r) {
+		return "", errRequesterInvalid
+	}
+
+	return r, nil
+}
+
+// parseBatchSizeParam takes a *http.Request and returns the specified
+// 'batch_size' request parameter formatted as a int.
+func parseBatchSizeParam(req *http.Request) (int, error) {
+	b := req.URL.Query().Get("batch_size")
+	if b == "" {
+		return 0, errBatchSizeUnspecified
+	}
+
+	size, err := strconv.Atoi(b)
+	if err != nil || size < 1 {
+		return 0, errBatchSizeInvalid
+	}
+
+	return size, nil
+}
+
+// parseWeightParam takes a *http.Request and returns the specified 'weight'
+// request parameter formatted as a int.
+func parseWeightParam(req *http.Request) (int, error) {
+	w := req.URL.Query().Get("weight")
+
+	weight, err := strconv.Atoi(w)
+	if err != nil || weight < 1 {
+		return 0, errWeightInvalid
+	}
+
+	return weight, nil
+}
+
+// parseMinutesParam takes a *http.Request and returns the specified 'minutes'
+// request parameter as a time.Duration, or the default if unspecified.
+func parseMinutesParam(req *http.Request, def time.Duration) (time.Duration, error) {
+	m := req.URL.Query().Get("minutes")
+	if m == "" {
+		return def, nil
+	}
+
+	minutes, err := strconv.Atoi(m)
+	if err != nil || minutes < 1 {
+		return 0, errMinutesInvalid
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
+
 // parsePaths takes a *http.Request and returns a []string elements of the full
 // request path, stripped of all '/' chars.
 func parsePaths(req *http.Request) []string {
@@ -88,19 +260,20 @@ func brokerIDFromPath(req *http.Request) (string, error) {
 
 	_, err := strconv.Atoi(idStr)
 	if err != nil {
-		return "", errors.New("broker param must be provided as integer or the string 'all'")
+		return "", errBrokerIDInvalid
 	}
 
 	return idStr, nil
 }
 
 // writeNLError writes the provided error with an appended newline to the
-// provided http.ResponseWriter.
+// provided http.ResponseWriter. On versioned routes, the error is written as
+// an errorResponse unless part of the response was already written.
 func writeNLError(w http.ResponseWriter, err error) {
-	fmt.Fprintf(w, "%s\n", err)
-}
+	if jw, ok := w.(*jsonErrorWriter); ok && !jw.wrote {
+		jw.writeError(err)
+		return
+	}
 
-// logReq logs *http.Request parameters.
-func logReq(req *http.Request) {
-	log.Printf("[API] %s %s %s\n", req.Method, req.RequestURI, req.RemoteAddr)
+	fmt.Fprintf(w, "%s\n", err)
 }
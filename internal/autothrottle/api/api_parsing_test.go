@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestParseRateParam(t *testing.T) {
@@ -144,3 +145,108 @@ func TestBrokerIDFromPath(t *testing.T) {
 		t.Errorf("Expected broker ID '%s', got '%s'", expected, out)
 	}
 }
+
+func TestParseTTLParam(t *testing.T) {
+	type response struct {
+		ttl time.Duration
+		err error
+	}
+
+	tests := map[string]response{
+		"":     {ttl: 0, err: nil},
+		"text": {ttl: 0, err: errTTLInvalid},
+		"-1h":  {ttl: 0, err: errTTLInvalid},
+		"30m":  {ttl: 30 * time.Minute, err: nil},
+	}
+
+	for param, expected := range tests {
+		url := fmt.Sprintf("http://localhost?ttl=%s", param)
+		req, _ := http.NewRequest("POST", url, nil)
+
+		ttl, err := parseTTLParam(req)
+
+		if ttl != expected.ttl {
+			t.Errorf("Expected ttl '%s', got '%s'", expected.ttl, ttl)
+		}
+
+		if err != expected.err {
+			t.Errorf("Expected error '%s', got '%s'", expected.err, err)
+		}
+	}
+}
+
+func TestParseScheduleParams(t *testing.T) {
+	now := time.Now()
+	start := now.Add(time.Hour).Truncate(time.Second)
+	end := now.Add(2 * time.Hour).Truncate(time.Second)
+
+	type expected struct {
+		startAt, expires int64
+		err              error
+	}
+
+	tests := []struct {
+		params   string
+		ttl      time.Duration
+		expected expected
+	}{
+		{params: "", expected: expected{}},
+		{
+			params:   fmt.Sprintf("start_at=%s&end_at=%d", start.Format(time.RFC3339), end.Unix()),
+			expected: expected{startAt: start.Unix(), expires: end.Unix()},
+		},
+		// The ttl is relative to the start.
+		{
+			params:   fmt.Sprintf("start_at=%d", start.Unix()),
+			ttl:      time.Hour,
+			expected: expected{startAt: start.Unix(), expires: end.Unix()},
+		},
+		{params: "start_at=soon", expected: expected{err: errStartAtInvalid}},
+		{params: fmt.Sprintf("end_at=%d", now.Add(-time.Hour).Unix()), expected: expected{err: errEndAtInvalid}},
+		{params: fmt.Sprintf("end_at=%d", end.Unix()), ttl: time.Hour, expected: expected{err: errEndAtTTL}},
+		{
+			params:   fmt.Sprintf("start_at=%d&end_at=%d", end.Unix(), start.Unix()),
+			expected: expected{err: errEndAtBeforeStartAt},
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "http://localhost?"+test.params, nil)
+
+		startAt, expires, err := parseScheduleParams(req, test.ttl)
+
+		if err != test.expected.err {
+			t.Errorf("[%s] Expected error '%v', got '%v'", test.params, test.expected.err, err)
+		}
+
+		if startAt != test.expected.startAt || expires != test.expected.expires {
+			t.Errorf("[%s] Expected start %d expiry %d, got start %d expiry %d",
+				test.params, test.expected.startAt, test.expected.expires, startAt, expires)
+		}
+	}
+}
+
+func TestParsePrecedenceParam(t *testing.T) {
+	tests := map[string]error{
+		"":         nil,
+		"override": nil,
+		"min":      nil,
+		"max":      nil,
+		"avg":      errPrecedenceInvalid,
+	}
+
+	for param, expected := range tests {
+		url := fmt.Sprintf("http://localhost?precedence=%s", param)
+		req, _ := http.NewRequest("POST", url, nil)
+
+		p, err := parsePrecedenceParam(req)
+
+		if err != expected {
+			t.Errorf("Expected error '%s', got '%s'", expected, err)
+		}
+
+		if err == nil && p != param {
+			t.Errorf("Expected precedence '%s', got '%s'", param, p)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+// pauseGetSet conditionally handles the request depending on the HTTP method.
+func (a *API) pauseGetSet(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		// Get the pause state.
+		a.getPause(w)
+	case http.MethodPost:
+		// Pause autothrottle.
+		if a.setPause(w, true) {
+			a.trigger <- struct{}{}
+		}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// resume resumes a paused autothrottle.
+func (a *API) resume(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		if a.setPause(w, false) {
+			a.trigger <- struct{}{}
+		}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// getPause writes the pause state.
+func (a *API) getPause(w http.ResponseWriter) {
+	c, err := throttlestore.FetchPauseConfig(a.zk, a.Paths.Pause)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	io.WriteString(w, pauseMessage(c))
+}
+
+// setPause stores the pause state. A bool is returned indicating whether the
+// state was stored.
+func (a *API) setPause(w http.ResponseWriter, paused bool) bool {
+	c, err := a.storePause(paused)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	io.WriteString(w, pauseMessage(c))
+
+	return true
+}
+
+// storePause stores the pause state and returns the resulting PauseConfig.
+// Pausing an already paused autothrottle retains the original pause time.
+func (a *API) storePause(paused bool) (throttlestore.PauseConfig, error) {
+	c, err := throttlestore.FetchPauseConfig(a.zk, a.Paths.Pause)
+	if err != nil {
+		return c, err
+	}
+
+	switch {
+	case paused && !c.Paused:
+		c = throttlestore.PauseConfig{Paused: true, Since: time.Now().Unix()}
+	case !paused:
+		c = throttlestore.PauseConfig{}
+	}
+
+	return c, throttlestore.StorePauseConfig(a.zk, a.Paths.Pause, c)
+}
+
+// pauseMessage returns a message describing the pause state.
+func pauseMessage(c throttlestore.PauseConfig) string {
+	if !c.Paused {
+		return "autothrottle is running\n"
+	}
+
+	return fmt.Sprintf("autothrottle is paused since %s\n", time.Unix(c.Since, 0).UTC().Format(time.RFC3339))
+}
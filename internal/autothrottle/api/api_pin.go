@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+var errPinBrokerIDNotInt = errors.New("broker param must be provided as integer")
+
+// pinGetSet conditionally handles the request depending on the HTTP method.
+func (a *API) pinGetSet(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		// List all pins or get a broker pin.
+		if len(parsePaths(req)) > 1 {
+			a.getPin(w, req)
+		} else {
+			a.getPins(w)
+		}
+	case http.MethodPost:
+		// Pin a broker throttle.
+		if a.setPin(w, req) {
+			a.trigger <- struct{}{}
+		}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// pinRemove removes a broker pin.
+func (a *API) pinRemove(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		id, err := pinBrokerIDFromPath(req)
+		if err != nil {
+			writeNLError(w, err)
+			return
+		}
+
+		// Removing a pin means setting it to 0; the throttle is removed and the
+		// pin purged in the next interval.
+		path := fmt.Sprintf("%s/%s", a.Paths.PinnedRate, id)
+		if err := throttlestore.StoreThrottleOverride(a.zk, path, throttlestore.ThrottleOverrideConfig{}); err != nil {
+			writeNLError(w, err)
+			return
+		}
+
+		io.WriteString(w, fmt.Sprintf("broker %s: pinned throttle removed\n", id))
+		a.trigger <- struct{}{}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// getPins writes all pinned broker throttles.
+func (a *API) getPins(w http.ResponseWriter) {
+	pins, err := throttlestore.FetchBrokerOverrides(a.zk, a.Paths.PinnedRate)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	// Exclude pins marked for removal.
+	pins = pins.Filter(func(bto throttlestore.BrokerThrottleOverride) bool {
+		return bto.Config.Rate != 0
+	})
+
+	if len(pins) == 0 {
+		io.WriteString(w, "no pinned throttles are set\n")
+		return
+	}
+
+	ids := pins.IDs()
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		io.WriteString(w, fmt.Sprintf("broker %d: throttle pinned at %dMB/s\n", id, pins[id].Config.Rate))
+	}
+}
+
+// getPin writes the pinned throttle for a broker.
+func (a *API) getPin(w http.ResponseWriter, req *http.Request) {
+	id, err := pinBrokerIDFromPath(req)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	path := fmt.Sprintf("%s/%s", a.Paths.PinnedRate, id)
+	c := &throttlestore.ThrottleOverrideConfig{}
+
+	// Brokers without a pin have no znode.
+	if exists, err := a.zk.Exists(path); err != nil {
+		writeNLError(w, err)
+		return
+	} else if exists {
+		if c, err = throttlestore.FetchThrottleOverride(a.zk, path); err != nil {
+			writeNLError(w, err)
+			return
+		}
+	}
+
+	if c.Rate == 0 {
+		io.WriteString(w, fmt.Sprintf("broker %s: no pinned throttle is set\n", id))
+		return
+	}
+
+	io.WriteString(w, fmt.Sprintf("broker %s: throttle pinned at %dMB/s\n", id, c.Rate))
+}
+
+// setPin pins a broker throttle. A bool is returned indicating whether the pin
+// was stored.
+func (a *API) setPin(w http.ResponseWriter, req *http.Request) bool {
+	id, err := pinBrokerIDFromPath(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	rate, err := parseRateParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	path := fmt.Sprintf("%s/%s", a.Paths.PinnedRate, id)
+	if err := throttlestore.StoreThrottleOverride(a.zk, path, throttlestore.ThrottleOverrideConfig{Rate: rate}); err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	io.WriteString(w, fmt.Sprintf("broker %s: throttle pinned at %dMB/s\n", id, rate))
+
+	return true
+}
+
+// pinBrokerIDFromPath returns the broker ID from the request path. Unlike
+// overrides, pins can only be managed for individual brokers.
+func pinBrokerIDFromPath(req *http.Request) (string, error) {
+	id, err := brokerIDFromPath(req)
+	if err != nil {
+		return "", err
+	}
+
+	if id == "all" {
+		return "", errPinBrokerIDNotInt
+	}
+
+	return id, nil
+}
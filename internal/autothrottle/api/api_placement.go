@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+// Reassignment placement checks. Blocking checks are those the reassignments
+// endpoint rejects a reassignment for.
+const (
+	checkPartitionDuplicate = "partition_duplicate"
+	checkNoReplicas         = "no_replicas"
+	checkBrokerNotFound     = "broker_not_found"
+	checkBrokerDuplicate    = "broker_duplicate"
+	checkTopicState         = "topic_state"
+	checkPartitionNotFound  = "partition_not_found"
+	checkReplicasInPlace    = "replicas_in_place"
+	checkRackShared         = "rack_shared"
+	checkCapacityUnknown    = "capacity_unknown"
+	checkCapacityMissing    = "capacity_missing"
+)
+
+// placementWarning is a problem found in a reassignment.
+type placementWarning struct {
+	// The check that found the problem, e.g. broker_not_found.
+	Check string `json:"check"`
+	// The partition and broker concerned, where applicable.
+	Topic     string `json:"topic,omitempty"`
+	Partition *int   `json:"partition,omitempty"`
+	Broker    *int   `json:"broker,omitempty"`
+	// Whether the reassignment would be rejected if submitted.
+	Blocking bool   `json:"blocking"`
+	Message  string `json:"message"`
+}
+
+// placementReport is the response of the reassignment validation endpoint.
+type placementReport struct {
+	// Whether the reassignment would be accepted if submitted.
+	Valid    bool               `json:"valid"`
+	Warnings []placementWarning `json:"warnings"`
+}
+
+// reassignmentValidate handles validating a reassignment against live broker
+// metadata without submitting it. The request body is expected to be a
+// partition map in the standard Kafka reassignment JSON format.
+func (a *API) reassignmentValidate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	pm := mapper.NewPartitionMap()
+	if err := json.NewDecoder(req.Body).Decode(pm); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, fmt.Errorf("error parsing reassignment: %s", err))
+		return
+	}
+
+	warnings, err := a.checkReassignment(pm)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, err)
+		return
+	}
+
+	report := placementReport{Valid: true, Warnings: []placementWarning{}}
+	for _, pw := range warnings {
+		if pw.Blocking {
+			report.Valid = false
+		}
+		report.Warnings = append(report.Warnings, pw)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// checkReassignment checks a reassignment against the current topic state and
+// live broker metadata, returning a placementWarning for each problem found.
+// Besides the blocking checks that the reassignment only references existing
+// topics, partitions and brokers and that each partition's target replicas
+// differ from its current replicas, it checks that no two replicas of a
+// partition share a rack and that each broker receiving new replicas has a
+// known network capacity in the cap-map.
+func (a *API) checkReassignment(pm *mapper.PartitionMap) ([]placementWarning, error) {
+	if len(pm.Partitions) == 0 {
+		return nil, fmt.Errorf("reassignment contains no partitions")
+	}
+
+	brokers, errs := a.zk.GetAllBrokerMeta(false)
+	if errs != nil {
+		return nil, fmt.Errorf("error fetching brokers: %s", errs)
+	}
+
+	var warnings []placementWarning
+	add := func(check, topic string, partition, broker *int, blocking bool, format string, args ...interface{}) {
+		warnings = append(warnings, placementWarning{
+			Check:     check,
+			Topic:     topic,
+			Partition: partition,
+			Broker:    broker,
+			Blocking:  blocking,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	// The current replicas by topic and partition, fetched as needed.
+	current := map[string]map[int][]int{}
+	seen := map[string]map[int]struct{}{}
+	// Brokers receiving new replicas.
+	destinations := map[int]struct{}{}
+
+	for _, p := range pm.Partitions {
+		name := fmt.Sprintf("%s p%d", p.Topic, p.Partition)
+		partition := p.Partition
+
+		if _, exists := seen[p.Topic][p.Partition]; exists {
+			add(checkPartitionDuplicate, p.Topic, &partition, nil, true, "%s: listed more than once", name)
+			continue
+		}
+		if seen[p.Topic] == nil {
+			seen[p.Topic] = map[int]struct{}{}
+		}
+		seen[p.Topic][p.Partition] = struct{}{}
+
+		if len(p.Replicas) == 0 {
+			add(checkNoReplicas, p.Topic, &partition, nil, true, "%s: no replicas specified", name)
+			continue
+		}
+
+		ids := map[int]struct{}{}
+		racks := map[string]int{}
+		for _, id := range p.Replicas {
+			id := id
+
+			meta, exists := brokers[id]
+			if !exists {
+				add(checkBrokerNotFound, p.Topic, &partition, &id, true, "%s: broker %d doesn't exist", name, id)
+				continue
+			}
+			if _, exists := ids[id]; exists {
+				add(checkBrokerDuplicate, p.Topic, &partition, &id, true, "%s: broker %d listed more than once", name, id)
+				continue
+			}
+			ids[id] = struct{}{}
+
+			if meta.Rack == "" {
+				continue
+			}
+			if other, exists := racks[meta.Rack]; exists {
+				add(checkRackShared, p.Topic, &partition, &id, false, "%s: brokers %d and %d share rack %s", name, other, id, meta.Rack)
+				continue
+			}
+			racks[meta.Rack] = id
+		}
+
+		if _, fetched := current[p.Topic]; !fetched {
+			current[p.Topic] = nil
+
+			state, err := a.zk.GetPartitionMap(p.Topic)
+			if err != nil {
+				add(checkTopicState, p.Topic, nil, nil, true, "%s: error fetching topic state: %s", p.Topic, err)
+				continue
+			}
+
+			current[p.Topic] = map[int][]int{}
+			for _, cp := range state.Partitions {
+				current[p.Topic][cp.Partition] = cp.Replicas
+			}
+		}
+
+		// The topic state couldn't be fetched.
+		if current[p.Topic] == nil {
+			continue
+		}
+
+		replicas, exists := current[p.Topic][p.Partition]
+		if !exists {
+			add(checkPartitionNotFound, p.Topic, &partition, nil, true, "%s: partition doesn't exist", name)
+			continue
+		}
+
+		if equalReplicas(replicas, p.Replicas) {
+			add(checkReplicasInPlace, p.Topic, &partition, nil, true, "%s: replicas %v are already in place", name, replicas)
+			continue
+		}
+
+		for id := range ids {
+			if !containsReplica(replicas, id) {
+				destinations[id] = struct{}{}
+			}
+		}
+	}
+
+	var ids []int
+	for id := range destinations {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	limits := a.getLimits()
+	instanceTypes := a.getStatus().BrokerInstanceTypes
+
+	for _, id := range ids {
+		id := id
+
+		instanceType, known := instanceTypes[id]
+		if !known {
+			add(checkCapacityUnknown, "", nil, &id, false, "broker %d: instance type not yet observed in broker metrics; its capacity can't be checked", id)
+			continue
+		}
+
+		if _, known := instanceTypeCapacity(limits, instanceType); !known {
+			add(checkCapacityMissing, "", nil, &id, false, "broker %d: no capacity configured for instance type %s in the cap-map", id, instanceType)
+		}
+	}
+
+	return warnings, nil
+}
+
+// containsReplica returns whether the broker ID is in the replica list.
+func containsReplica(replicas []int, id int) bool {
+	for _, r := range replicas {
+		if r == id {
+			return true
+		}
+	}
+
+	return false
+}
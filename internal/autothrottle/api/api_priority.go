@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+var errTopicNotProvided = errors.New("topic not provided")
+
+// priorityGetSet conditionally handles the request depending on the HTTP
+// method.
+func (a *API) priorityGetSet(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		// List all priorities or get a topic priority.
+		if len(parsePaths(req)) > 1 {
+			a.getPriority(w, req)
+		} else {
+			a.getPriorities(w)
+		}
+	case http.MethodPost:
+		// Set a topic priority.
+		if a.setPriority(w, req) {
+			a.trigger <- struct{}{}
+		}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// priorityRemove removes a topic priority.
+func (a *API) priorityRemove(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		topic, err := topicFromPath(req)
+		if err != nil {
+			writeNLError(w, err)
+			return
+		}
+
+		path := fmt.Sprintf("%s/%s", a.Paths.Priority, topic)
+		if err := throttlestore.RemoveTopicPriority(a.zk, path); err != nil {
+			writeNLError(w, err)
+			return
+		}
+
+		io.WriteString(w, fmt.Sprintf("topic %s: priority removed\n", topic))
+		a.trigger <- struct{}{}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// getPriorities writes all topic priorities.
+func (a *API) getPriorities(w http.ResponseWriter) {
+	priorities, err := throttlestore.FetchTopicPriorities(a.zk, a.Paths.Priority)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	if len(priorities) == 0 {
+		io.WriteString(w, "no topic priorities are set\n")
+		return
+	}
+
+	var topics []string
+	for t := range priorities {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+
+	for _, t := range topics {
+		io.WriteString(w, fmt.Sprintf("topic %s: priority weight %d\n", t, priorities[t]))
+	}
+}
+
+// getPriority writes the priority for a topic.
+func (a *API) getPriority(w http.ResponseWriter, req *http.Request) {
+	topic, err := topicFromPath(req)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	priorities, err := throttlestore.FetchTopicPriorities(a.zk, a.Paths.Priority)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	if _, exists := priorities[topic]; !exists {
+		io.WriteString(w, fmt.Sprintf("topic %s: no priority is set (default weight %d)\n", topic, throttlestore.DefaultPriority))
+		return
+	}
+
+	io.WriteString(w, fmt.Sprintf("topic %s: priority weight %d\n", topic, priorities[topic]))
+}
+
+// setPriority sets a topic priority. A bool is returned indicating whether the
+// priority was stored.
+func (a *API) setPriority(w http.ResponseWriter, req *http.Request) bool {
+	topic, err := topicFromPath(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	weight, err := parseWeightParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	path := fmt.Sprintf("%s/%s", a.Paths.Priority, topic)
+	if err := throttlestore.StoreTopicPriority(a.zk, path, throttlestore.PriorityConfig{Weight: weight}); err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	io.WriteString(w, fmt.Sprintf("topic %s: priority weight %d\n", topic, weight))
+
+	return true
+}
+
+// topicFromPath takes a *http.Request and returns a topic name from the path
+// elements, i.e. /priority/<topic> or /priority/remove/<topic>.
+func topicFromPath(req *http.Request) (string, error) {
+	paths := parsePaths(req)
+
+	if len(paths) > 1 && paths[1] == "remove" {
+		paths = paths[1:]
+	}
+
+	if len(paths) < 2 || paths[1] == "" {
+		return "", errTopicNotProvided
+	}
+
+	return paths[1], nil
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/orchestrator"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+// errReassignmentWritesUnsupported is returned for requests that submit or
+// cancel reassignments when the kafkazk.Handler can't write reassignments.
+var errReassignmentWritesUnsupported = errors.New("reassignments can't be submitted or cancelled without ZooKeeper")
+
+// reassignmentSubmitCancel handles submitting a reassignment or cancelling
+// all in-flight reassignments depending on the HTTP method.
+func (a *API) reassignmentSubmitCancel(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		if a.submitReassignment(w, req) {
+			a.trigger <- struct{}{}
+		}
+	case http.MethodDelete:
+		if a.cancelReassignments(w, nil) {
+			a.trigger <- struct{}{}
+		}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// reassignmentCancelTopic handles cancelling the in-flight reassignment of a
+// single topic.
+func (a *API) reassignmentCancelTopic(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	topic, err := topicFromPath(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, err)
+		return
+	}
+
+	if a.cancelReassignments(w, []string{topic}) {
+		a.trigger <- struct{}{}
+	}
+}
+
+// reassignmentPlanGetSet conditionally handles the request depending on the
+// HTTP method.
+func (a *API) reassignmentPlanGetSet(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		// Get the plan status.
+		a.getReassignmentPlan(w)
+	case http.MethodPost:
+		// Submit a plan.
+		if a.setReassignmentPlan(w, req) {
+			a.trigger <- struct{}{}
+		}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// reassignmentPlanRemove removes a stored reassignment plan.
+func (a *API) reassignmentPlanRemove(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		if err := orchestrator.RemovePlan(a.zk, a.Paths.ReassignmentPlan); err != nil {
+			writeNLError(w, err)
+			return
+		}
+		io.WriteString(w, "reassignment plan removed\n")
+		a.trigger <- struct{}{}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
+
+// getReassignmentPlan returns the status of the stored reassignment plan.
+func (a *API) getReassignmentPlan(w http.ResponseWriter) {
+	plan, err := orchestrator.FetchPlan(a.zk, a.Paths.ReassignmentPlan)
+	switch err {
+	case nil:
+		io.WriteString(w, fmt.Sprintf("reassignment plan: %s\n", plan))
+	case orchestrator.ErrNoPlan:
+		io.WriteString(w, "no reassignment plan is set\n")
+	default:
+		writeNLError(w, err)
+	}
+}
+
+// setReassignmentPlan stores a reassignment plan from the request body, which
+// is expected to be a partition map in the standard Kafka reassignment JSON
+// format. A bool is returned indicating whether the plan was stored.
+func (a *API) setReassignmentPlan(w http.ResponseWriter, req *http.Request) bool {
+	if !a.checkReassignmentWrites(w) {
+		return false
+	}
+
+	// Check batch size param.
+	batchSize, err := parseBatchSizeParam(req)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	// Only one plan may be in progress.
+	if _, err := orchestrator.FetchPlan(a.zk, a.Paths.ReassignmentPlan); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		writeNLError(w, orchestrator.ErrPlanExists)
+		return false
+	}
+
+	pm := mapper.NewPartitionMap()
+	if err := json.NewDecoder(req.Body).Decode(pm); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, fmt.Errorf("error parsing reassignment plan: %s", err))
+		return false
+	}
+
+	plan, err := orchestrator.NewPlan(pm, batchSize)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, err)
+		return false
+	}
+
+	if err := orchestrator.StorePlan(a.zk, a.Paths.ReassignmentPlan, plan); err != nil {
+		if err == orchestrator.ErrPlanTooLarge {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+		writeNLError(w, err)
+		return false
+	}
+
+	io.WriteString(w, fmt.Sprintf("reassignment plan set: %d partitions in batches of %d\n", plan.Total, plan.BatchSize))
+
+	return true
+}
+
+// submitReassignment validates and submits a reassignment from the request
+// body, which is expected to be a partition map in the standard Kafka
+// reassignment JSON format. A bool is returned indicating whether the
+// reassignment was submitted.
+func (a *API) submitReassignment(w http.ResponseWriter, req *http.Request) bool {
+	if !a.checkReassignmentWrites(w) {
+		return false
+	}
+
+	pm := mapper.NewPartitionMap()
+	if err := json.NewDecoder(req.Body).Decode(pm); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, fmt.Errorf("error parsing reassignment: %s", err))
+		return false
+	}
+
+	// Only one reassignment may be in progress.
+	if running, err := a.zk.ListReassignments(); err != nil {
+		writeNLError(w, err)
+		return false
+	} else if len(running) > 0 {
+		w.WriteHeader(http.StatusConflict)
+		writeNLError(w, kafkazk.ErrReassignmentInProgress)
+		return false
+	}
+
+	if err := a.validateReassignment(pm); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, err)
+		return false
+	}
+
+	// Submit partitions in a stable order.
+	sort.Sort(pm.Partitions)
+
+	switch err := a.zk.SubmitReassignment(pm); err {
+	case nil:
+	case kafkazk.ErrReassignmentInProgress:
+		w.WriteHeader(http.StatusConflict)
+		writeNLError(w, err)
+		return false
+	default:
+		writeNLError(w, err)
+		return false
+	}
+
+	topics := map[string]struct{}{}
+	for _, p := range pm.Partitions {
+		topics[p.Topic] = struct{}{}
+	}
+
+	io.WriteString(w, fmt.Sprintf("reassignment submitted: %d partitions across %d topics\n", len(pm.Partitions), len(topics)))
+
+	return true
+}
+
+// cancelReassignments cancels the in-flight reassignments for the topics, or
+// all reassignments if no topics are provided. The cancelled topics are
+// recorded so that autothrottle clears their throttles. Returns true if any
+// reassignments were cancelled.
+func (a *API) cancelReassignments(w http.ResponseWriter, topics []string) bool {
+	if !a.checkReassignmentWrites(w) {
+		return false
+	}
+
+	cancelled, err := a.zk.CancelReassignments(topics)
+	if err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	if len(cancelled) == 0 {
+		if len(topics) > 0 {
+			w.WriteHeader(http.StatusNotFound)
+			writeNLError(w, fmt.Errorf("topic %s: no reassignment in progress", topics[0]))
+			return false
+		}
+		io.WriteString(w, "no reassignments in progress\n")
+		return false
+	}
+
+	var names []string
+	for t := range cancelled {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+
+	if err := throttlestore.AddCancelledReassignments(a.zk, a.Paths.Cancelled, names); err != nil {
+		writeNLError(w, err)
+		return false
+	}
+
+	io.WriteString(w, fmt.Sprintf("reassignments cancelled for topics: %v\n", names))
+
+	return true
+}
+
+// checkReassignmentWrites returns whether reassignments can be written,
+// writing an error to w if not.
+func (a *API) checkReassignmentWrites(w http.ResponseWriter) bool {
+	if a.reassignmentWritesDisabled() {
+		w.WriteHeader(http.StatusBadRequest)
+		writeNLError(w, errReassignmentWritesUnsupported)
+		return false
+	}
+
+	return true
+}
+
+// validateReassignment checks that a reassignment only references existing
+// topics, partitions and brokers, and that each partition's target replicas
+// differ from its current replicas. The first blocking problem found by
+// checkReassignment is returned.
+func (a *API) validateReassignment(pm *mapper.PartitionMap) error {
+	warnings, err := a.checkReassignment(pm)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		if w.Blocking {
+			return errors.New(w.Message)
+		}
+	}
+
+	return nil
+}
+
+// equalReplicas returns whether two replica lists are identical, including the
+// order, which determines the preferred leader.
+func equalReplicas(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"io"
+	"net/http"
+)
+
+// recalculate triggers an immediate throttle evaluation rather than waiting
+// for the next interval. Requests made while an evaluation is already pending
+// are coalesced.
+func (a *API) recalculate(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		select {
+		case a.trigger <- struct{}{}:
+			io.WriteString(w, "throttle recalculation triggered\n")
+		default:
+			io.WriteString(w, "a throttle recalculation is already pending\n")
+		}
+	default:
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+}
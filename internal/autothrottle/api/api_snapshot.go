@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+var errNoConfigSnapshot = errors.New("no config snapshot")
+
+// snapshotGet writes the config snapshot.
+func (a *API) snapshotGet(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	s, err := throttlestore.FetchConfigSnapshot(a.zk, a.Paths.ConfigSnapshot)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	r, err := throttlestore.FetchConfigRestoreRequest(a.zk, a.Paths.ConfigRestore)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	io.WriteString(w, snapshotMessage(s, r))
+}
+
+// snapshotRestore requests that the config snapshot be restored. The restore
+// is performed by autothrottle in the next interval.
+func (a *API) snapshotRestore(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	s, err := throttlestore.FetchConfigSnapshot(a.zk, a.Paths.ConfigSnapshot)
+	if err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	if s.Empty() {
+		w.WriteHeader(http.StatusNotFound)
+		writeNLError(w, errNoConfigSnapshot)
+		return
+	}
+
+	if err := throttlestore.RequestConfigRestore(a.zk, a.Paths.ConfigRestore); err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	io.WriteString(w, fmt.Sprintf("config snapshot restore requested for %d brokers and %d topics\n", len(s.Brokers), len(s.Topics)))
+
+	a.trigger <- struct{}{}
+}
+
+// snapshotRemove discards the config snapshot along with any pending restore
+// request. A new snapshot is taken ahead of autothrottle's next config write.
+func (a *API) snapshotRemove(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	if err := throttlestore.RemoveConfigRestoreRequest(a.zk, a.Paths.ConfigRestore); err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	if err := throttlestore.RemoveConfigSnapshot(a.zk, a.Paths.ConfigSnapshot); err != nil {
+		writeNLError(w, err)
+		return
+	}
+
+	io.WriteString(w, "config snapshot removed\n")
+}
+
+// snapshotMessage returns a message listing the config snapshot values by
+// broker and topic.
+func snapshotMessage(s throttlestore.ConfigSnapshot, r throttlestore.ConfigRestoreRequest) string {
+	if s.Empty() {
+		return "no config snapshot\n"
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "config snapshot taken %s\n", time.Unix(s.Created, 0).UTC().Format(time.RFC3339))
+
+	for _, kind := range []string{"broker", "topic"} {
+		resources := s.Resources(kind)
+
+		var names []string
+		for name := range resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			var configs []string
+			for k, v := range resources[name] {
+				if v == "" {
+					v = "<unset>"
+				}
+				configs = append(configs, fmt.Sprintf("%s=%s", k, v))
+			}
+			sort.Strings(configs)
+
+			fmt.Fprintf(&b, "%s %s: %s\n", kind, name, strings.Join(configs, " "))
+		}
+	}
+
+	if r.Requested {
+		fmt.Fprintf(&b, "restore requested %s\n", time.Unix(r.Since, 0).UTC().Format(time.RFC3339))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// getStatusHandler writes the autothrottle Status as of the most recent interval.
+func (a *API) getStatusHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	st := a.getStatus()
+
+	if st.Updated.IsZero() {
+		io.WriteString(w, "status not yet available\n")
+		return
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "updated: %s\n", st.Updated.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "paused: %v\n", st.Paused)
+	fmt.Fprintf(&b, "guardrails tripped: %v\n", st.GuardrailsTripped)
+	fmt.Fprintf(&b, "reassigning topics: %v\n", st.ReassigningTopics)
+	fmt.Fprintf(&b, "replication factor increase topics: %v\n", st.RFIncreaseTopics)
+	fmt.Fprintf(&b, "reassigning brokers: %v\n", st.ReassigningBrokers)
+	fmt.Fprintf(&b, "applied throttles [ID, leader, follower]: %s\n", formatThrottles(st.Throttles))
+	fmt.Fprintf(&b, "reassigning broker overrides [ID, rate, precedence, leader, follower]: %s\n", formatOverrides(st.Overrides))
+	fmt.Fprintf(&b, "reassignment sessions: %s\n", formatSessions(st.Sessions))
+	fmt.Fprintf(&b, "dropped events: %d\n", st.DroppedEvents)
+
+	if st.ManagerLockHolder != "" {
+		fmt.Fprintf(&b, "throttle manager lock held by: %s\n", st.ManagerLockHolder)
+	}
+
+	if st.CruiseControlExecution != "" {
+		fmt.Fprintf(&b, "cruise control execution: %s\n", st.CruiseControlExecution)
+	}
+
+	if len(st.OrphanedThrottleTopics) > 0 {
+		fmt.Fprintf(&b, "orphaned topic throttles: %v\n", st.OrphanedThrottleTopics)
+	}
+
+	if len(st.InterpolatedBrokers) > 0 {
+		fmt.Fprintf(&b, "interpolated broker metrics: %v\n", st.InterpolatedBrokers)
+	}
+
+	if u := a.getUnknownThrottles(); len(u.Brokers) > 0 || len(u.Topics) > 0 {
+		fmt.Fprintf(&b, "unknown throttles found at startup: brokers %v, topics %v, action==%s\n",
+			u.Brokers, u.Topics, u.Action)
+	}
+
+	io.WriteString(w, b.String())
+}
+
+// getFallbackCapacityHandler writes the brokers using the default capacity as
+// of the most recent interval.
+func (a *API) getFallbackCapacityHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		// Invalid method.
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeNLError(w, incorrectMethodError)
+		return
+	}
+
+	c, exists := a.getLimits()["defaultCapacity"]
+	if !exists {
+		io.WriteString(w, "no default capacity configured\n")
+		return
+	}
+
+	brokers := a.getStatus().FallbackCapacityBrokers
+
+	var ids []int
+	for id := range brokers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var entries []string
+	for _, id := range ids {
+		entries = append(entries, fmt.Sprintf("[%d, %s]", id, brokers[id]))
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "default capacity: %.2fMB/s\n", c)
+	fmt.Fprintf(&b, "brokers using the default capacity [ID, instance type]: %s\n", strings.Join(entries, ", "))
+
+	io.WriteString(w, b.String())
+}
+
+// formatThrottles returns the throttle rates as a list of [ID, leader rate,
+// follower rate] sorted by broker ID. Roles without a rate are shown as "-".
+func formatThrottles(throttles map[int][2]*float64) string {
+	var ids []int
+	for id := range throttles {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var entries []string
+	for _, id := range ids {
+		rates := [2]string{"-", "-"}
+		for n, rate := range throttles[id] {
+			if rate != nil {
+				rates[n] = fmt.Sprintf("%.2f", *rate)
+			}
+		}
+
+		entries = append(entries, fmt.Sprintf("[%d, %s, %s]", id, rates[0], rates[1]))
+	}
+
+	return strings.Join(entries, ", ")
+}
+
+// formatOverrides returns the broker overrides as a list of [ID, override
+// rate, precedence, effective leader rate, effective follower rate] sorted by
+// broker ID. Pinned throttles are shown with a precedence of "pinned".
+func formatOverrides(overrides map[int]BrokerOverrideStatus) string {
+	var ids []int
+	for id := range overrides {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var entries []string
+	for _, id := range ids {
+		o := overrides[id]
+
+		precedence := o.Precedence
+		switch {
+		case o.Pinned:
+			precedence = "pinned"
+		case precedence == "":
+			precedence = "override"
+		}
+
+		rates := [2]string{"-", "-"}
+		for n, rate := range o.Effective {
+			if rate != nil {
+				rates[n] = fmt.Sprintf("%.2f", *rate)
+			}
+		}
+
+		entries = append(entries, fmt.Sprintf("[%d, %d, %s, %s, %s]", id, o.Rate, precedence, rates[0], rates[1]))
+	}
+
+	return strings.Join(entries, ", ")
+}
+
+// formatSessions returns the reassignment sessions as a list of [ID, started,
+// topics].
+func formatSessions(sessions []ReassignmentSession) string {
+	var entries []string
+	for _, s := range sessions {
+		entries = append(entries, fmt.Sprintf("[%s, %s, %v]", s.ID, s.Started.UTC().Format(time.RFC3339), s.Topics))
+	}
+
+	return strings.Join(entries, ", ")
+}
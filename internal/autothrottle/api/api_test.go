@@ -1,11 +1,17 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/history"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
 )
 
@@ -17,13 +23,14 @@ func TestSetThrottle(t *testing.T) {
 	t.Cleanup(clearTrigger)
 	// GIVEN
 	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
 	req, err := http.NewRequest("POST", "/throttle?rate=5&autoremove=false", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	responseRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
+	handler := http.HandlerFunc(a.throttleGetSet)
 
 	// WHEN
 	handler.ServeHTTP(responseRecorder, req)
@@ -39,13 +46,14 @@ func TestSetBrokerThrottle(t *testing.T) {
 	t.Cleanup(clearTrigger)
 	// GIVEN
 	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
 	req, err := http.NewRequest("POST", "/throttle/123?rate=5&autoremove=false", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	responseRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
+	handler := http.HandlerFunc(a.throttleGetSet)
 
 	// WHEN
 	handler.ServeHTTP(responseRecorder, req)
@@ -57,11 +65,95 @@ func TestSetBrokerThrottle(t *testing.T) {
 	}
 }
 
+func TestSetBrokerThrottlePrecedence(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	handler := http.HandlerFunc(a.throttleGetSet)
+
+	tests := []struct {
+		method, url, expected string
+	}{
+		{"POST", "/throttle/123?rate=5&precedence=min", "broker 123: throttle successfully set to 5MB/s, autoremove==false, precedence==min\n"},
+		{"GET", "/throttle/123", "broker 123: a throttle override is configured at 5MB/s, autoremove==false, precedence==min\n"},
+		{"POST", "/throttle/123?rate=5&precedence=avg", errPrecedenceInvalid.Error() + "\n"},
+		{"POST", "/throttle?rate=5&precedence=max", errPrecedenceGlobal.Error() + "\n"},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, test.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responseRecorder := httptest.NewRecorder()
+
+		// WHEN
+		handler.ServeHTTP(responseRecorder, req)
+
+		// THEN
+		checkResults(http.StatusOK, test.expected, responseRecorder, t)
+	}
+}
+
+func TestSetThrottleCapacity(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	handler := http.HandlerFunc(a.throttleGetSet)
+	bulkHandler := http.HandlerFunc(a.brokerThrottles)
+
+	a.SetLimits(map[string]float64{"minimum": 10, "i3.xlarge": 100, "tx:m5.large": 50, "rx:m5.large": 80})
+	a.SetStatus(Status{BrokerInstanceTypes: map[int]string{1001: "i3.xlarge", 1002: "m5.large", 1003: "unknown"}})
+
+	tests := []struct {
+		handler  http.Handler
+		url      string
+		body     string
+		status   int
+		expected string
+	}{
+		{handler, "/throttle/1001?rate=150", "", http.StatusBadRequest,
+			"rate 150MB/s exceeds the network capacity of broker 1001 (i3.xlarge, 100.00MB/s); set force=true to apply it anyway\n"},
+		{handler, "/throttle/1001?rate=150&force=true", "", http.StatusOK,
+			"broker 1001: throttle successfully set to 150MB/s, autoremove==false\n" +
+				"warning: rate 150MB/s exceeds the network capacity of broker 1001 (i3.xlarge, 100.00MB/s)\n"},
+		// The lower of asymmetric capacities applies.
+		{handler, "/throttle?rate=60", "", http.StatusBadRequest,
+			"rate 60MB/s exceeds the network capacity of broker 1002 (m5.large, 50.00MB/s); set force=true to apply it anyway\n"},
+		// Brokers without a known capacity aren't checked.
+		{handler, "/throttle/1003?rate=1000", "", http.StatusOK, "broker 1003: throttle successfully set to 1000MB/s, autoremove==false\n"},
+		{handler, "/throttle/1001?rate=5", "", http.StatusOK,
+			"broker 1001: throttle successfully set to 5MB/s, autoremove==false\nwarning: the rate is below the min-rate of 10.00MB/s\n"},
+		{bulkHandler, "/throttle/brokers", `{"1001": 90, "1002": 60}`, http.StatusBadRequest,
+			"rate 60MB/s exceeds the network capacity of broker 1002 (m5.large, 50.00MB/s); set force=true to apply it anyway\n"},
+	}
+
+	for i, test := range tests {
+		req, err := http.NewRequest("POST", test.url, strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		test.handler.ServeHTTP(rr, req)
+
+		// THEN
+		if rr.Code != test.status || rr.Body.String() != test.expected {
+			t.Errorf("[test %d] Expected %d '%s', got %d '%s'", i, test.status, test.expected, rr.Code, rr.Body.String())
+		}
+	}
+}
+
 func TestGetThrottle(t *testing.T) {
 	t.Cleanup(clearTrigger)
 	// GIVEN
 	overrideRateZnode = "override_rate"
 	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
 
 	setReq, err := http.NewRequest("POST", "/throttle?rate=5&autoremove=false", nil)
 	getReq, err := http.NewRequest("GET", "/throttle", nil)
@@ -71,7 +163,7 @@ func TestGetThrottle(t *testing.T) {
 
 	setRecorder := httptest.NewRecorder()
 	getRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
+	handler := http.HandlerFunc(a.throttleGetSet)
 
 	// WHEN
 	handler.ServeHTTP(setRecorder, setReq)
@@ -86,6 +178,7 @@ func TestGetBrokerThrottle(t *testing.T) {
 	// GIVEN
 	overrideRateZnode = "override_rate"
 	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
 
 	setReq, err := http.NewRequest("POST", "/throttle/123?rate=5&autoremove=false", nil)
 	getReq, err := http.NewRequest("GET", "/throttle/123", nil)
@@ -95,7 +188,7 @@ func TestGetBrokerThrottle(t *testing.T) {
 
 	setRecorder := httptest.NewRecorder()
 	getRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
+	handler := http.HandlerFunc(a.throttleGetSet)
 
 	// WHEN
 	handler.ServeHTTP(setRecorder, setReq)
@@ -110,6 +203,7 @@ func TestRemoveThrottle(t *testing.T) {
 	// GIVEN
 	overrideRateZnode = "override_rate"
 	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
 
 	setReq, err := http.NewRequest("POST", "/throttle?rate=5&autoremove=false", nil)
 	removeReq, err := http.NewRequest("POST", "/throttle/remove", nil)
@@ -121,8 +215,8 @@ func TestRemoveThrottle(t *testing.T) {
 	setRecorder := httptest.NewRecorder()
 	getRecorder := httptest.NewRecorder()
 	removeRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
-	removeHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleRemove(w, req, zk, trigger) })
+	handler := http.HandlerFunc(a.throttleGetSet)
+	removeHandler := http.HandlerFunc(a.throttleRemove)
 
 	// WHEN
 	handler.ServeHTTP(setRecorder, setReq)
@@ -143,6 +237,7 @@ func TestRemoveBrokerThrottle(t *testing.T) {
 	// GIVEN
 	overrideRateZnode = "override_rate"
 	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
 
 	setReq, err := http.NewRequest("POST", "/throttle/123?rate=5&autoremove=false", nil)
 	removeReq, err := http.NewRequest("POST", "/throttle/remove/123", nil)
@@ -154,8 +249,8 @@ func TestRemoveBrokerThrottle(t *testing.T) {
 	setRecorder := httptest.NewRecorder()
 	getRecorder := httptest.NewRecorder()
 	removeRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
-	removeHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleRemove(w, req, zk, trigger) })
+	handler := http.HandlerFunc(a.throttleGetSet)
+	removeHandler := http.HandlerFunc(a.throttleRemove)
 
 	// WHEN
 	handler.ServeHTTP(setRecorder, setReq)
@@ -175,8 +270,8 @@ func TestRemoveAllBrokerThrottle(t *testing.T) {
 	t.Cleanup(clearTrigger)
 	// GIVEN
 	overrideRateZnode = "override_rate"
-	OverrideRateZnodePath = fmt.Sprintf("%s/%s", "zkChroot", overrideRateZnode)
 	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
 
 	setReq, err := http.NewRequest("POST", "/throttle/123?rate=5&autoremove=false", nil)
 	setReq2, err := http.NewRequest("POST", "/throttle/456?rate=10&autoremove=false", nil)
@@ -191,8 +286,8 @@ func TestRemoveAllBrokerThrottle(t *testing.T) {
 	getRecorder := httptest.NewRecorder()
 	getRecorder2 := httptest.NewRecorder()
 	removeRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleGetSet(w, req, zk, trigger) })
-	removeHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { throttleRemove(w, req, zk, trigger) })
+	handler := http.HandlerFunc(a.throttleGetSet)
+	removeHandler := http.HandlerFunc(a.throttleRemove)
 
 	// WHEN
 	handler.ServeHTTP(setRecorder, setReq)
@@ -207,6 +302,937 @@ func TestRemoveAllBrokerThrottle(t *testing.T) {
 	checkResults(http.StatusOK, "broker 456: no throttle override is set\n", getRecorder2, t)
 }
 
+func TestRemoveAllBrokerThrottleChildrenError(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.Delete(a.Paths.OverrideRate)
+
+	removeReq, err := http.NewRequest("POST", "/throttle/remove/all", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removeRecorder := httptest.NewRecorder()
+	removeHandler := http.HandlerFunc(a.throttleRemove)
+
+	// WHEN
+	removeHandler.ServeHTTP(removeRecorder, removeReq)
+
+	// THEN
+	checkResults(http.StatusOK, "znode doesn't exist\n", removeRecorder, t)
+}
+
+func TestRemoveAllBrokerThrottlesInvalidNode(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.Create(a.Paths.OverrideRate+"/invalid", "")
+
+	deleteReq, err := http.NewRequest("DELETE", "/throttle/brokers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleteRecorder := httptest.NewRecorder()
+	brokersHandler := http.HandlerFunc(a.brokerThrottles)
+
+	// WHEN
+	brokersHandler.ServeHTTP(deleteRecorder, deleteReq)
+
+	// THEN
+	expected := fmt.Sprintf("invalid node %q is not an integer under path %q\n", "invalid", a.Paths.OverrideRate)
+	checkResults(http.StatusOK, expected, deleteRecorder, t)
+	if triggered := countTrigger(); triggered != 0 {
+		t.Errorf("Expected no triggers, got %d", triggered)
+	}
+}
+
+func TestRemoveAllBrokerThrottlesChildrenErrorNoTrigger(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.Delete(a.Paths.OverrideRate)
+
+	deleteReq, err := http.NewRequest("DELETE", "/throttle/brokers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleteRecorder := httptest.NewRecorder()
+	brokersHandler := http.HandlerFunc(a.brokerThrottles)
+
+	// WHEN
+	brokersHandler.ServeHTTP(deleteRecorder, deleteReq)
+
+	// THEN
+	checkResults(http.StatusOK, "znode doesn't exist\n", deleteRecorder, t)
+	if triggered := countTrigger(); triggered != 0 {
+		t.Errorf("Expected no triggers, got %d", triggered)
+	}
+}
+
+func TestListRemoveBrokerThrottles(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	overrideRateZnode = "override_rate"
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	a.SetStatus(Status{ReassigningBrokers: []int{456}})
+
+	setReq, err := http.NewRequest("POST", "/throttle/123?rate=5&autoremove=false", nil)
+	setReq2, err := http.NewRequest("POST", "/throttle/456?rate=10&autoremove=true", nil)
+	listReq, err := http.NewRequest("GET", "/throttle/brokers", nil)
+	deleteReq, err := http.NewRequest("DELETE", "/throttle/brokers", nil)
+	listReq2, err := http.NewRequest("GET", "/throttle/brokers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setRecorder := httptest.NewRecorder()
+	listRecorder := httptest.NewRecorder()
+	deleteRecorder := httptest.NewRecorder()
+	listRecorder2 := httptest.NewRecorder()
+	handler := http.HandlerFunc(a.throttleGetSet)
+	brokersHandler := http.HandlerFunc(a.brokerThrottles)
+
+	// WHEN
+	handler.ServeHTTP(setRecorder, setReq)
+	handler.ServeHTTP(setRecorder, setReq2)
+	brokersHandler.ServeHTTP(listRecorder, listReq)
+	brokersHandler.ServeHTTP(deleteRecorder, deleteReq)
+	brokersHandler.ServeHTTP(listRecorder2, listReq2)
+
+	// THEN
+	checkResults(http.StatusOK, "broker 123: a throttle override is configured at 5MB/s, autoremove==false, reassigning==false\n"+
+		"broker 456: a throttle override is configured at 10MB/s, autoremove==true, reassigning==true\n", listRecorder, t)
+	checkResults(http.StatusOK, "broker 123: throttle removed\nbroker 456: throttle removed\n", deleteRecorder, t)
+	checkResults(http.StatusOK, "no broker throttle overrides are set\n", listRecorder2, t)
+	// 3 = 2 set + 1 delete
+	if triggered := countTrigger(); triggered != 3 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestSetBrokerThrottles(t *testing.T) {
+	t.Cleanup(clearTrigger)
+
+	tests := []struct {
+		body     string
+		status   int
+		expected string
+	}{
+		{`{}`, http.StatusBadRequest, "no broker rates provided\n"},
+		{`{"1001": 50`, http.StatusBadRequest, "error parsing broker rates: unexpected EOF\n"},
+		// Nothing is applied if any broker is invalid.
+		{`{"1001": 50, "9999": 50, "a": 50, "1002": 0}`, http.StatusBadRequest,
+			"broker 1002: rate must be >0\nbroker 9999: broker doesn't exist\nbroker a: broker ID must be an integer\n"},
+		{`{"1002": 80, "1001": 50}`, http.StatusOK, "broker 1001: throttle successfully set to 50MB/s, autoremove==false, precedence==min\n" +
+			"broker 1002: throttle successfully set to 80MB/s, autoremove==false, precedence==min\n"},
+	}
+
+	// GIVEN
+	overrideRateZnode = "override_rate"
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	handler := http.HandlerFunc(a.brokerThrottles)
+
+	for i, test := range tests {
+		req, err := http.NewRequest("POST", "/throttle/brokers?precedence=min", strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		// THEN
+		if rr.Code != test.status || rr.Body.String() != test.expected {
+			t.Errorf("[test %d] Expected %d '%s', got %d '%s'", i, test.status, test.expected, rr.Code, rr.Body.String())
+		}
+	}
+
+	overrides, err := throttlestore.FetchBrokerOverrides(zk, a.Paths.OverrideRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id, rate := range map[int]int{1001: 50, 1002: 80} {
+		if c := overrides[id].Config; c.Rate != rate || c.Precedence != "min" {
+			t.Errorf("Expected broker %d override at %dMB/s with min precedence, got %+v", id, rate, c)
+		}
+	}
+
+	if len(overrides) != 2 {
+		t.Errorf("Expected 2 broker overrides, got %d", len(overrides))
+	}
+
+	if triggered := countTrigger(); triggered != 1 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestThrottleRequesters(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	overrideRateZnode = "override_rate"
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+
+	handler := http.HandlerFunc(a.throttleGetSet)
+	removeHandler := http.HandlerFunc(a.throttleRemove)
+
+	steps := []struct {
+		handler  http.Handler
+		method   string
+		url      string
+		expected string
+	}{
+		{handler, "POST", "/throttle?rate=200", "throttle successfully set to 200MB/s, autoremove==false\n"},
+		{handler, "POST", "/throttle?rate=100&requester=ci", "throttle successfully set to 100MB/s, autoremove==false, requester==ci\n"},
+		{handler, "POST", "/throttle?rate=150&requester=operator", "throttle successfully set to 150MB/s, autoremove==false, requester==operator\n"},
+		// The lowest rate override is in effect.
+		{handler, "GET", "/throttle", "a throttle override is configured at 100MB/s, autoremove==false, requesters==[-:200MB/s ci:100MB/s operator:150MB/s]\n"},
+		// Removals only remove the requester's override.
+		{removeHandler, "POST", "/throttle/remove?requester=ci", "throttle removed, requester==ci\n"},
+		{handler, "GET", "/throttle", "a throttle override is configured at 150MB/s, autoremove==false, requesters==[-:200MB/s operator:150MB/s]\n"},
+		{removeHandler, "POST", "/throttle/remove", "throttle removed\n"},
+		{handler, "GET", "/throttle", "a throttle override is configured at 150MB/s, autoremove==false, requesters==[operator:150MB/s]\n"},
+		{removeHandler, "POST", "/throttle/remove?requester=operator", "throttle removed, requester==operator\n"},
+		{handler, "GET", "/throttle", "no throttle override is set\n"},
+		{handler, "POST", "/throttle?rate=100&requester=ci/cd", errRequesterInvalid.Error() + "\n"},
+	}
+
+	for _, step := range steps {
+		req, err := http.NewRequest(step.method, step.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		step.handler.ServeHTTP(rr, req)
+
+		// THEN
+		checkResults(http.StatusOK, step.expected, rr, t)
+	}
+}
+
+func TestSetReassignmentPlan(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+
+	plan := `{"version":1,"partitions":[{"topic":"test","partition":0,"replicas":[1001,1002]},{"topic":"test","partition":1,"replicas":[1002,1001]}]}`
+	setReq, err := http.NewRequest("POST", "/reassignment/plan?batch_size=1", strings.NewReader(plan))
+	setReq2, err := http.NewRequest("POST", "/reassignment/plan?batch_size=1", strings.NewReader(plan))
+	getReq, err := http.NewRequest("GET", "/reassignment/plan", nil)
+	removeReq, err := http.NewRequest("POST", "/reassignment/plan/remove", nil)
+	getReq2, err := http.NewRequest("GET", "/reassignment/plan", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setRecorder := httptest.NewRecorder()
+	setRecorder2 := httptest.NewRecorder()
+	getRecorder := httptest.NewRecorder()
+	removeRecorder := httptest.NewRecorder()
+	getRecorder2 := httptest.NewRecorder()
+	handler := http.HandlerFunc(a.reassignmentPlanGetSet)
+	removeHandler := http.HandlerFunc(a.reassignmentPlanRemove)
+
+	// WHEN
+	handler.ServeHTTP(setRecorder, setReq)
+	handler.ServeHTTP(setRecorder2, setReq2)
+	handler.ServeHTTP(getRecorder, getReq)
+	removeHandler.ServeHTTP(removeRecorder, removeReq)
+	handler.ServeHTTP(getRecorder2, getReq2)
+
+	// THEN
+	checkResults(http.StatusOK, "reassignment plan set: 2 partitions in batches of 1\n", setRecorder, t)
+	checkResults(http.StatusConflict, "a reassignment plan is already in progress\n", setRecorder2, t)
+	checkResults(http.StatusOK, "reassignment plan: batch 0, 0/2 partitions completed, 0 in progress, 2 pending\n", getRecorder, t)
+	checkResults(http.StatusOK, "reassignment plan removed\n", removeRecorder, t)
+	checkResults(http.StatusOK, "no reassignment plan is set\n", getRecorder2, t)
+	// 2 = 1 set + 1 remove
+	if triggered := countTrigger(); triggered != 2 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestSubmitReassignment(t *testing.T) {
+	t.Cleanup(clearTrigger)
+
+	tests := []struct {
+		body     string
+		status   int
+		expected string
+	}{
+		{`{"version":1,"partitions":[]}`, http.StatusBadRequest, "reassignment contains no partitions\n"},
+		{`{"version":1,"partitions":[{"topic":"test","partition":0,"replicas":[1001,9999]}]}`, http.StatusBadRequest, "test p0: broker 9999 doesn't exist\n"},
+		{`{"version":1,"partitions":[{"topic":"test","partition":0,"replicas":[1001,1001]}]}`, http.StatusBadRequest, "test p0: broker 1001 listed more than once\n"},
+		{`{"version":1,"partitions":[{"topic":"test","partition":9,"replicas":[1001,1002]}]}`, http.StatusBadRequest, "test p9: partition doesn't exist\n"},
+		{`{"version":1,"partitions":[{"topic":"test","partition":0,"replicas":[1001,1002]}]}`, http.StatusBadRequest, "test p0: replicas [1001 1002] are already in place\n"},
+		{`{"version":1,"partitions":[{"topic":"test","partition":0,"replicas":[1003,1004]},{"topic":"test","partition":0,"replicas":[1003,1005]}]}`, http.StatusBadRequest, "test p0: listed more than once\n"},
+		{`{"version":1,"partitions":[{"topic":"test","partition":1,"replicas":[1003,1004]},{"topic":"test","partition":0,"replicas":[1002,1001]}]}`, http.StatusOK, "reassignment submitted: 2 partitions across 1 topics\n"},
+		// The previous reassignment is in progress.
+		{`{"version":1,"partitions":[{"topic":"test","partition":2,"replicas":[1003,1005]}]}`, http.StatusConflict, "a reassignment is already in progress\n"},
+	}
+
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.SetReassignments(kafkazk.Reassignments{})
+	handler := http.HandlerFunc(a.reassignmentSubmitCancel)
+
+	for i, test := range tests {
+		req, err := http.NewRequest("POST", "/reassignments", strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		// THEN
+		if rr.Code != test.status || rr.Body.String() != test.expected {
+			t.Errorf("[test %d] Expected %d '%s', got %d '%s'", i, test.status, test.expected, rr.Code, rr.Body.String())
+		}
+	}
+
+	expected := kafkazk.Reassignments{"test": {0: {1002, 1001}, 1: {1003, 1004}}}
+	if r := zk.GetReassignments(); fmt.Sprint(r) != fmt.Sprint(expected) {
+		t.Errorf("Expected reassignments %v, got %v", expected, r)
+	}
+
+	if triggered := countTrigger(); triggered != 1 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestValidateReassignment(t *testing.T) {
+	tests := []struct {
+		method   string
+		body     string
+		status   int
+		expected string
+	}{
+		{"GET", "", http.StatusMethodNotAllowed, "disallowed method\n"},
+		{"POST", `{"version":1,"partitions":[]}`, http.StatusBadRequest, "reassignment contains no partitions\n"},
+		{"POST", `{"version":1,"partitions":[{"topic":"test","partition":0,"replicas":[1001,1004,1005]}]}`, http.StatusOK,
+			`{"valid":true,"warnings":[` +
+				`{"check":"rack_shared","topic":"test","partition":0,"broker":1004,"blocking":false,"message":"test p0: brokers 1001 and 1004 share rack a"},` +
+				`{"check":"capacity_missing","broker":1004,"blocking":false,"message":"broker 1004: no capacity configured for instance type i3.xlarge in the cap-map"},` +
+				`{"check":"capacity_unknown","broker":1005,"blocking":false,"message":"broker 1005: instance type not yet observed in broker metrics; its capacity can't be checked"}]}` + "\n"},
+		{"POST", `{"version":1,"partitions":[{"topic":"test","partition":9,"replicas":[1003,9999]},{"topic":"test","partition":1,"replicas":[1002,1003]}]}`, http.StatusOK,
+			`{"valid":false,"warnings":[` +
+				`{"check":"broker_not_found","topic":"test","partition":9,"broker":9999,"blocking":true,"message":"test p9: broker 9999 doesn't exist"},` +
+				`{"check":"partition_not_found","topic":"test","partition":9,"blocking":true,"message":"test p9: partition doesn't exist"},` +
+				`{"check":"capacity_unknown","broker":1003,"blocking":false,"message":"broker 1003: instance type not yet observed in broker metrics; its capacity can't be checked"}]}` + "\n"},
+	}
+
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	a.SetLimits(map[string]float64{"minimum": 10, "i3.2xlarge": 200})
+	a.SetStatus(Status{BrokerInstanceTypes: map[int]string{1004: "i3.xlarge"}})
+	zk.SetReassignments(kafkazk.Reassignments{})
+	handler := http.HandlerFunc(a.reassignmentValidate)
+
+	for i, test := range tests {
+		req, err := http.NewRequest(test.method, "/reassignment/validate", strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		// THEN
+		if rr.Code != test.status || rr.Body.String() != test.expected {
+			t.Errorf("[test %d] Expected %d '%s', got %d '%s'", i, test.status, test.expected, rr.Code, rr.Body.String())
+		}
+	}
+
+	// Nothing is submitted.
+	if r := zk.GetReassignments(); len(r) != 0 {
+		t.Errorf("Expected no reassignments, got %v", r)
+	}
+}
+
+func TestCancelReassignments(t *testing.T) {
+	t.Cleanup(clearTrigger)
+
+	tests := []struct {
+		method   string
+		path     string
+		status   int
+		expected string
+	}{
+		{"GET", "/reassignments", http.StatusMethodNotAllowed, "disallowed method\n"},
+		{"POST", "/reassignments/a", http.StatusMethodNotAllowed, "disallowed method\n"},
+		{"DELETE", "/reassignments/", http.StatusBadRequest, "topic not provided\n"},
+		{"DELETE", "/reassignments/a", http.StatusOK, "reassignments cancelled for topics: [a]\n"},
+		{"DELETE", "/reassignments/a", http.StatusNotFound, "topic a: no reassignment in progress\n"},
+		{"DELETE", "/reassignments", http.StatusOK, "reassignments cancelled for topics: [b c]\n"},
+		{"DELETE", "/reassignments", http.StatusOK, "no reassignments in progress\n"},
+	}
+
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.Create("/autothrottle", "")
+	zk.SetReassignments(kafkazk.Reassignments{
+		"a": {0: {1001, 1002}},
+		"b": {0: {1003, 1004}},
+		"c": {1: {1004, 1005}},
+	})
+
+	handler := http.HandlerFunc(a.reassignmentSubmitCancel)
+	topicHandler := http.HandlerFunc(a.reassignmentCancelTopic)
+
+	for i, test := range tests {
+		req, err := http.NewRequest(test.method, test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		if test.path == "/reassignments" {
+			handler.ServeHTTP(rr, req)
+		} else {
+			topicHandler.ServeHTTP(rr, req)
+		}
+
+		// THEN
+		if rr.Code != test.status || rr.Body.String() != test.expected {
+			t.Errorf("[test %d] Expected %d '%s', got %d '%s'", i, test.status, test.expected, rr.Code, rr.Body.String())
+		}
+	}
+
+	// The cancelled topics are recorded for throttle removal.
+	c, err := throttlestore.FetchCancelledReassignments(zk, a.Paths.Cancelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"a", "b", "c"}; fmt.Sprint(c.Topics) != fmt.Sprint(expected) {
+		t.Errorf("Expected cancelled topics %v, got %v", expected, c.Topics)
+	}
+
+	if triggered := countTrigger(); triggered != 2 {
+		t.Errorf("Expected 2 triggers, got %d", triggered)
+	}
+}
+
+func TestReassignmentWritesDisabled(t *testing.T) {
+	t.Cleanup(clearTrigger)
+
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	a.DisableReassignmentWrites()
+
+	body := `{"version":1,"partitions":[{"topic":"test","partition":0,"replicas":[1001,1002]}]}`
+	tests := []struct {
+		handler http.HandlerFunc
+		method  string
+		path    string
+	}{
+		{a.reassignmentSubmitCancel, "POST", "/reassignments"},
+		{a.reassignmentSubmitCancel, "DELETE", "/reassignments"},
+		{a.reassignmentCancelTopic, "DELETE", "/reassignments/test"},
+		{a.reassignmentPlanGetSet, "POST", "/reassignment/plan?batch_size=1"},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, test.path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		test.handler.ServeHTTP(rr, req)
+
+		// THEN
+		checkResults(http.StatusBadRequest, errReassignmentWritesUnsupported.Error()+"\n", rr, t)
+	}
+
+	if exists, _ := zk.Exists(a.Paths.ReassignmentPlan); exists {
+		t.Error("Expected no reassignment plan to be stored")
+	}
+
+	if triggered := countTrigger(); triggered != 0 {
+		t.Errorf("Expected no triggers, got %d", triggered)
+	}
+}
+
+func TestPinThrottle(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+
+	setReq, err := http.NewRequest("POST", "/pin/1001?rate=50", nil)
+	getReq, err := http.NewRequest("GET", "/pin/1001", nil)
+	getReq2, err := http.NewRequest("GET", "/pin/1002", nil)
+	listReq, err := http.NewRequest("GET", "/pin", nil)
+	removeReq, err := http.NewRequest("POST", "/pin/remove/1001", nil)
+	listReq2, err := http.NewRequest("GET", "/pin", nil)
+	allReq, err := http.NewRequest("POST", "/pin/all?rate=50", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setRecorder := httptest.NewRecorder()
+	getRecorder := httptest.NewRecorder()
+	getRecorder2 := httptest.NewRecorder()
+	listRecorder := httptest.NewRecorder()
+	removeRecorder := httptest.NewRecorder()
+	listRecorder2 := httptest.NewRecorder()
+	allRecorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(a.pinGetSet)
+	removeHandler := http.HandlerFunc(a.pinRemove)
+
+	// WHEN
+	handler.ServeHTTP(setRecorder, setReq)
+	handler.ServeHTTP(getRecorder, getReq)
+	handler.ServeHTTP(getRecorder2, getReq2)
+	handler.ServeHTTP(listRecorder, listReq)
+	removeHandler.ServeHTTP(removeRecorder, removeReq)
+	handler.ServeHTTP(listRecorder2, listReq2)
+	handler.ServeHTTP(allRecorder, allReq)
+
+	// THEN
+	checkResults(http.StatusOK, "broker 1001: throttle pinned at 50MB/s\n", setRecorder, t)
+	checkResults(http.StatusOK, "broker 1001: throttle pinned at 50MB/s\n", getRecorder, t)
+	checkResults(http.StatusOK, "broker 1002: no pinned throttle is set\n", getRecorder2, t)
+	checkResults(http.StatusOK, "broker 1001: throttle pinned at 50MB/s\n", listRecorder, t)
+	checkResults(http.StatusOK, "broker 1001: pinned throttle removed\n", removeRecorder, t)
+	checkResults(http.StatusOK, "no pinned throttles are set\n", listRecorder2, t)
+	checkResults(http.StatusOK, "broker param must be provided as integer\n", allRecorder, t)
+	// 2 = 1 set + 1 remove
+	if triggered := countTrigger(); triggered != 2 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	// GIVEN
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	rate := 50.0
+	a.SetStatus(Status{
+		ReassigningTopics:  []string{"test", "test2"},
+		RFIncreaseTopics:   []string{"test2"},
+		ReassigningBrokers: []int{1001, 1002},
+		Throttles:          map[int][2]*float64{1001: {&rate, nil}, 1002: {nil, &rate}},
+		Overrides: map[int]BrokerOverrideStatus{
+			1001: {Rate: 80, Precedence: "min", Effective: [2]*float64{&rate, nil}},
+			1002: {Rate: 50, Pinned: true, Effective: [2]*float64{nil, &rate}},
+		},
+		Sessions: []ReassignmentSession{
+			{ID: "a1b2c3d4", Started: time.Date(2020, 2, 27, 0, 0, 0, 0, time.UTC), Topics: []string{"test", "test2"}},
+		},
+		DroppedEvents:          2,
+		OrphanedThrottleTopics: []string{"test3"},
+		InterpolatedBrokers:    []int{1002},
+		Updated:                time.Date(2020, 2, 28, 0, 0, 0, 0, time.UTC),
+	})
+	a.SetUnknownThrottles(UnknownThrottles{Brokers: []int{1003}, Action: "removed"})
+
+	req, err := http.NewRequest("GET", "/status", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(a.getStatusHandler)
+
+	// WHEN
+	handler.ServeHTTP(responseRecorder, req)
+
+	// THEN
+	expected := "updated: 2020-02-28T00:00:00Z\n" +
+		"paused: false\n" +
+		"guardrails tripped: false\n" +
+		"reassigning topics: [test test2]\n" +
+		"replication factor increase topics: [test2]\n" +
+		"reassigning brokers: [1001 1002]\n" +
+		"applied throttles [ID, leader, follower]: [1001, 50.00, -], [1002, -, 50.00]\n" +
+		"reassigning broker overrides [ID, rate, precedence, leader, follower]: [1001, 80, min, 50.00, -], [1002, 50, pinned, -, 50.00]\n" +
+		"reassignment sessions: [a1b2c3d4, 2020-02-27T00:00:00Z, [test test2]]\n" +
+		"dropped events: 2\n" +
+		"orphaned topic throttles: [test3]\n" +
+		"interpolated broker metrics: [1002]\n" +
+		"unknown throttles found at startup: brokers [1003], topics [], action==removed\n"
+	checkResults(http.StatusOK, expected, responseRecorder, t)
+}
+
+func TestFallbackCapacity(t *testing.T) {
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	handler := http.HandlerFunc(a.getFallbackCapacityHandler)
+
+	get := func() *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/capacity/fallback", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	// GIVEN
+	a.SetLimits(map[string]float64{"minimum": 10})
+
+	// THEN
+	checkResults(http.StatusOK, "no default capacity configured\n", get(), t)
+
+	// GIVEN
+	a.SetLimits(map[string]float64{"minimum": 10, "defaultCapacity": 200})
+	a.SetStatus(Status{
+		FallbackCapacityBrokers: map[int]string{1003: "i3.xlarge", 1001: "m5.large"},
+		Updated:                 time.Date(2020, 2, 28, 0, 0, 0, 0, time.UTC),
+	})
+
+	// THEN
+	expected := "default capacity: 200.00MB/s\n" +
+		"brokers using the default capacity [ID, instance type]: [1001, m5.large], [1003, i3.xlarge]\n"
+	checkResults(http.StatusOK, expected, get(), t)
+}
+
+func TestExplain(t *testing.T) {
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	handler := http.HandlerFunc(a.getExplainHandler)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	// THEN
+	checkResults(http.StatusOK, "no brokers participating in a reassignment\n", get("/explain"), t)
+
+	// GIVEN
+	computed, override := 96.0, 50.0
+	a.SetStatus(Status{
+		Resolutions: map[int]BrokerResolution{
+			1002: {
+				Rates:  [2]*float64{&computed, nil},
+				Reason: "computed",
+				Steps: []ResolutionStep{
+					{Source: "computed", Applied: true, Detail: "determined from broker metrics", Rates: [2]*float64{&computed, nil}},
+				},
+			},
+			1001: {
+				Rates:  [2]*float64{&override, &override},
+				Reason: "broker_override",
+				Steps: []ResolutionStep{
+					{Source: "guardrails_tripped", Applied: true, Detail: "guardrails tripped", Rates: [2]*float64{&computed, nil}},
+					{Source: "broker_override", Detail: "ignored"},
+				},
+			},
+		},
+		Updated: time.Date(2020, 2, 28, 0, 0, 0, 0, time.UTC),
+	})
+
+	// THEN
+	expected1001 := "broker 1001: leader 50.00, follower 50.00, source==broker_override\n" +
+		"  applied guardrails_tripped: guardrails tripped (leader 96.00, follower -)\n" +
+		"  skipped broker_override: ignored\n"
+	expected1002 := "broker 1002: leader 96.00, follower -, source==computed\n" +
+		"  applied computed: determined from broker metrics (leader 96.00, follower -)\n"
+
+	checkResults(http.StatusOK, expected1001+expected1002, get("/explain"), t)
+	checkResults(http.StatusOK, expected1002, get("/explain/1002"), t)
+	checkResults(http.StatusOK, "broker 1003 isn't participating in a reassignment\n", get("/explain/1003"), t)
+	checkResults(http.StatusOK, errPinBrokerIDNotInt.Error()+"\n", get("/explain/all"), t)
+}
+
+func TestHistory(t *testing.T) {
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	handler := http.HandlerFunc(a.getHistoryHandler)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	// THEN
+	checkResults(http.StatusOK, "history is disabled\n", get("/history"), t)
+
+	// GIVEN
+	h, err := history.New(history.Config{Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SetHistory(h)
+
+	leader, tx, rx := 50.0, 120.4, 95.1
+	now := time.Now().Truncate(time.Second)
+	h.Record(history.Entry{Time: now.Add(-90 * time.Minute)})
+	h.Record(history.Entry{Time: now.Add(-30 * time.Minute), Brokers: map[int]history.BrokerRates{
+		1002: {NetTX: &tx, NetRX: &rx},
+		1001: {Leader: &leader},
+	}})
+	h.Record(history.Entry{Time: now})
+
+	// THEN
+	expected := "interval: [ID, leader, follower, net tx, net rx] (MB/s)\n" +
+		fmt.Sprintf("%s: [1001, 50.00, -, -, -], [1002, -, -, 120.40, 95.10]\n", now.Add(-30*time.Minute).UTC().Format(time.RFC3339)) +
+		fmt.Sprintf("%s: -\n", now.UTC().Format(time.RFC3339))
+	checkResults(http.StatusOK, expected, get("/history"), t)
+
+	if body := get("/history?minutes=120").Body.String(); strings.Count(body, "\n") != 4 {
+		t.Errorf("Expected 3 intervals in the window, got '%s'", body)
+	}
+
+	checkResults(http.StatusBadRequest, "minutes param must be an integer >0\n", get("/history?minutes=0"), t)
+}
+
+func TestPauseResume(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+
+	getReq, err := http.NewRequest("GET", "/pause", nil)
+	pauseReq, err := http.NewRequest("POST", "/pause", nil)
+	getReq2, err := http.NewRequest("GET", "/pause", nil)
+	resumeReq, err := http.NewRequest("POST", "/resume", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getRecorder := httptest.NewRecorder()
+	pauseRecorder := httptest.NewRecorder()
+	getRecorder2 := httptest.NewRecorder()
+	resumeRecorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(a.pauseGetSet)
+	resumeHandler := http.HandlerFunc(a.resume)
+
+	// WHEN
+	handler.ServeHTTP(getRecorder, getReq)
+	handler.ServeHTTP(pauseRecorder, pauseReq)
+	handler.ServeHTTP(getRecorder2, getReq2)
+	resumeHandler.ServeHTTP(resumeRecorder, resumeReq)
+
+	// THEN
+	checkResults(http.StatusOK, "autothrottle is running\n", getRecorder, t)
+	checkResults(http.StatusOK, "autothrottle is running\n", resumeRecorder, t)
+
+	for _, rr := range []*httptest.ResponseRecorder{pauseRecorder, getRecorder2} {
+		if !strings.HasPrefix(rr.Body.String(), "autothrottle is paused since ") {
+			t.Errorf("handler returned unexpected body: %s", rr.Body.String())
+		}
+	}
+
+	// 2 = 1 pause + 1 resume
+	if triggered := countTrigger(); triggered != 2 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestRecalculate(t *testing.T) {
+	trigger := make(chan struct{}, 1)
+	a := New(kafkazk.NewZooKeeperStub(), "autothrottle", trigger)
+	handler := http.HandlerFunc(a.recalculate)
+
+	tests := []struct {
+		method   string
+		status   int
+		expected string
+	}{
+		{"POST", http.StatusOK, "throttle recalculation triggered\n"},
+		// Coalesced with the pending evaluation.
+		{"POST", http.StatusOK, "a throttle recalculation is already pending\n"},
+		{"GET", http.StatusMethodNotAllowed, "disallowed method\n"},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, "/recalculate", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		checkResults(test.status, test.expected, rr, t)
+	}
+
+	if len(trigger) != 1 {
+		t.Errorf("Expected 1 pending trigger, got %d", len(trigger))
+	}
+}
+
+func TestConfigSnapshot(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.Create("/autothrottle", "")
+
+	do := func(method, path string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	get := a.snapshotGet
+	restore := a.snapshotRestore
+	remove := a.snapshotRemove
+
+	// WHEN / THEN
+	checkResults(http.StatusOK, "no config snapshot\n", do("GET", "/snapshot", get), t)
+	checkResults(http.StatusNotFound, "no config snapshot\n", do("POST", "/snapshot/restore", restore), t)
+
+	s := throttlestore.ConfigSnapshot{
+		Brokers: map[string]map[string]string{
+			"1001": {"leader.replication.throttled.rate": "", "follower.replication.throttled.rate": "100"},
+		},
+		Topics: map[string]map[string]string{
+			"test": {"leader.replication.throttled.replicas": "0:1001"},
+		},
+		Created: 1700000000,
+	}
+	if err := throttlestore.StoreConfigSnapshot(zk, a.Paths.ConfigSnapshot, s); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "config snapshot taken 2023-11-14T22:13:20Z\n" +
+		"broker 1001: follower.replication.throttled.rate=100 leader.replication.throttled.rate=<unset>\n" +
+		"topic test: leader.replication.throttled.replicas=0:1001\n"
+	checkResults(http.StatusOK, expected, do("GET", "/snapshot", get), t)
+
+	checkResults(http.StatusOK, "config snapshot restore requested for 1 brokers and 1 topics\n", do("POST", "/snapshot/restore", restore), t)
+
+	if rr := do("GET", "/snapshot", get); !strings.Contains(rr.Body.String(), "restore requested ") {
+		t.Errorf("Expected a pending restore, got %s", rr.Body.String())
+	}
+
+	checkResults(http.StatusMethodNotAllowed, "disallowed method\n", do("GET", "/snapshot/remove", remove), t)
+	checkResults(http.StatusOK, "config snapshot removed\n", do("POST", "/snapshot/remove", remove), t)
+	checkResults(http.StatusOK, "no config snapshot\n", do("GET", "/snapshot", get), t)
+
+	if r, _ := throttlestore.FetchConfigRestoreRequest(zk, a.Paths.ConfigRestore); r.Requested {
+		t.Error("Expected the restore request to be removed")
+	}
+
+	if triggered := countTrigger(); triggered != 1 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestTopicPriority(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.Create("/autothrottle", "")
+	zk.Create(a.Paths.Priority, "")
+
+	setReq, err := http.NewRequest("POST", "/priority/orders?weight=3", nil)
+	getReq, err := http.NewRequest("GET", "/priority/orders", nil)
+	getReq2, err := http.NewRequest("GET", "/priority/clicks", nil)
+	listReq, err := http.NewRequest("GET", "/priority", nil)
+	removeReq, err := http.NewRequest("POST", "/priority/remove/orders", nil)
+	listReq2, err := http.NewRequest("GET", "/priority", nil)
+	invalidReq, err := http.NewRequest("POST", "/priority/orders?weight=0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setRecorder := httptest.NewRecorder()
+	getRecorder := httptest.NewRecorder()
+	getRecorder2 := httptest.NewRecorder()
+	listRecorder := httptest.NewRecorder()
+	removeRecorder := httptest.NewRecorder()
+	listRecorder2 := httptest.NewRecorder()
+	invalidRecorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(a.priorityGetSet)
+	removeHandler := http.HandlerFunc(a.priorityRemove)
+
+	// WHEN
+	handler.ServeHTTP(setRecorder, setReq)
+	handler.ServeHTTP(getRecorder, getReq)
+	handler.ServeHTTP(getRecorder2, getReq2)
+	handler.ServeHTTP(listRecorder, listReq)
+	removeHandler.ServeHTTP(removeRecorder, removeReq)
+	handler.ServeHTTP(listRecorder2, listReq2)
+	handler.ServeHTTP(invalidRecorder, invalidReq)
+
+	// THEN
+	checkResults(http.StatusOK, "topic orders: priority weight 3\n", setRecorder, t)
+	checkResults(http.StatusOK, "topic orders: priority weight 3\n", getRecorder, t)
+	checkResults(http.StatusOK, "topic clicks: no priority is set (default weight 1)\n", getRecorder2, t)
+	checkResults(http.StatusOK, "topic orders: priority weight 3\n", listRecorder, t)
+	checkResults(http.StatusOK, "topic orders: priority removed\n", removeRecorder, t)
+	checkResults(http.StatusOK, "no topic priorities are set\n", listRecorder2, t)
+	checkResults(http.StatusOK, "weight param must be an integer >0\n", invalidRecorder, t)
+	// 2 = 1 set + 1 remove
+	if triggered := countTrigger(); triggered != 2 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+}
+
+func TestInitZnodesACL(t *testing.T) {
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	zk.Create("/autothrottle/override_rate/1001", `{"rate":10}`)
+	acl := kafkazk.DigestACL(kafkazk.PermAll, "autothrottle", "secret")
+
+	// WHEN
+	if err := InitZnodes(zk, a.Paths, acl); err != nil {
+		t.Fatal(err)
+	}
+
+	err := throttlestore.StorePauseConfig(zk, a.Paths.Pause, throttlestore.PauseConfig{Paused: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// THEN
+	for _, p := range []string{"/autothrottle", a.Paths.OverrideRate, a.Paths.PinnedRate, a.Paths.OverrideRate + "/1001", a.Paths.Pause} {
+		got, err := zk.GetACL(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0] != acl[0] {
+			t.Errorf("[%s] expected ACL %v, got %v", p, acl, got)
+		}
+	}
+}
+
 func checkResults(statusCode int, expectedMessage string, rr *httptest.ResponseRecorder, t *testing.T) {
 	if status := rr.Code; status != statusCode {
 		t.Errorf("handler returned wrong status code: got %v want %v",
@@ -220,6 +1246,12 @@ func checkResults(statusCode int, expectedMessage string, rr *httptest.ResponseR
 	}
 }
 
+// newTestAPI returns an *API with the autothrottle config znodes beneath the
+// autothrottle prefix.
+func newTestAPI(zk kafkazk.Handler) *API {
+	return New(zk, "autothrottle", trigger)
+}
+
 func clearTrigger() {
 	countTrigger()
 }
@@ -235,3 +1267,126 @@ func countTrigger() int {
 		}
 	}
 }
+
+func TestVersionedRoutes(t *testing.T) {
+	t.Cleanup(clearTrigger)
+
+	tests := []struct {
+		method, path string
+		status       int
+		contentType  string
+		expected     string
+	}{
+		{"POST", "/v1/throttle/123?rate=5", http.StatusOK, "", "broker 123: throttle successfully set to 5MB/s, autoremove==false\n"},
+		{"GET", "/v1/throttle/123", http.StatusOK, "", "broker 123: a throttle override is configured at 5MB/s, autoremove==false\n"},
+		{"POST", "/v1/throttle/123", http.StatusBadRequest, "application/json", `{"code":400,"message":"rate param must be specified"}` + "\n"},
+		{"POST", "/v1/throttle/abc?rate=5", http.StatusBadRequest, "application/json", `{"code":400,"message":"broker param must be provided as integer or the string 'all'"}` + "\n"},
+		{"PUT", "/v1/throttle", http.StatusMethodNotAllowed, "application/json", `{"code":405,"message":"disallowed method"}` + "\n"},
+		// Unversioned routes write plain text errors.
+		{"POST", "/throttle/123", http.StatusOK, "", "rate param must be specified\n"},
+	}
+
+	// GIVEN
+	zk := kafkazk.NewZooKeeperStub()
+	a := newTestAPI(zk)
+	h := a.throttleGetSet
+
+	m := http.NewServeMux()
+	m.HandleFunc("/throttle", h)
+	m.HandleFunc("/throttle/", h)
+	m.Handle("/v1/throttle", versioned(h))
+	m.Handle("/v1/throttle/", versioned(h))
+
+	for i, test := range tests {
+		req, err := http.NewRequest(test.method, test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WHEN
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, req)
+
+		// THEN
+		if rr.Code != test.status || rr.Body.String() != test.expected {
+			t.Errorf("[test %d] Expected %d '%s', got %d '%s'", i, test.status, test.expected, rr.Code, rr.Body.String())
+		}
+
+		if test.contentType != "" && rr.Header().Get("Content-Type") != test.contentType {
+			t.Errorf("[test %d] Expected content type %s, got %s", i, test.contentType, rr.Header().Get("Content-Type"))
+		}
+	}
+
+	if status := errorStatus(errors.New("zk: connection closed")); status != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 for non-request errors, got %d", status)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	req, err := http.NewRequest("GET", "/openapi.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(getOpenAPIHandler)
+
+	// WHEN
+	handler.ServeHTTP(responseRecorder, req)
+
+	// THEN
+	if ct := responseRecorder.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json content type, got %s", ct)
+	}
+
+	var spec struct {
+		OpenAPI string
+		Paths   map[string]interface{}
+	}
+
+	if err := json.Unmarshal(responseRecorder.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/throttle", "/throttle/{broker}", "/throttle/remove", "/pin", "/priority", "/reassignments", "/reassignments/{topic}", "/reassignment/validate", "/status", "/capacity/fallback", "/explain", "/explain/{broker}", "/history", "/pause", "/resume", "/recalculate", "/snapshot", "/snapshot/restore"} {
+		if _, exists := spec.Paths[path]; !exists {
+			t.Errorf("Expected path %s in OpenAPI spec", path)
+		}
+	}
+}
+
+func TestDebugHandlers(t *testing.T) {
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	m := http.NewServeMux()
+	a.registerDebugHandlers(m)
+	// Registering the handlers again doesn't publish the variables again.
+	newTestAPI(kafkazk.NewZooKeeperStub()).registerDebugHandlers(http.NewServeMux())
+
+	a.SetStatus(Status{ReassigningTopics: []string{"test"}, ReassigningBrokers: []int{1001, 1002}})
+
+	rr := httptest.NewRecorder()
+	m.ServeHTTP(rr, httptest.NewRequest("GET", "/debug/vars", nil))
+
+	var vars map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &vars); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"memstats", "goroutines"} {
+		if _, exists := vars[v]; !exists {
+			t.Errorf("Expected var %s", v)
+		}
+	}
+
+	if vars["reassigning_topics"] != 1.0 || vars["reassigning_brokers"] != 2.0 {
+		t.Errorf("Unexpected reassignment vars %v, %v", vars["reassigning_topics"], vars["reassigning_brokers"])
+	}
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap?debug=1", "/debug/pprof/goroutine?debug=1"} {
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("[%s] Expected status 200, got %d", path, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// apiVersionPrefix is the path prefix of the current admin API version. Routes
+// are also served unversioned for compatibility with existing clients; errors
+// on unversioned routes are written as plain text rather than errorResponses.
+const apiVersionPrefix = "/v1"
+
+// badRequestErrors are errors caused by invalid request parameters. Errors
+// written on versioned routes without an explicit error status are reported
+// as bad requests if they're one of these, otherwise as internal errors.
+var badRequestErrors = []error{
+	errBrokerIDNotProvided,
+	errBrokerIDInvalid,
+	errRateParamUnspecified,
+	errRateParamIsZero,
+	errRateParamNotInt,
+	errAutoRemoveNotBool,
+	errTTLInvalid,
+	errStartAtInvalid,
+	errEndAtInvalid,
+	errEndAtTTL,
+	errEndAtBeforeStartAt,
+	errPrecedenceInvalid,
+	errPrecedenceGlobal,
+	errBatchSizeUnspecified,
+	errBatchSizeInvalid,
+	errWeightInvalid,
+	errRequesterInvalid,
+	errPinBrokerIDNotInt,
+	errTopicNotProvided,
+}
+
+// errorResponse is the JSON error written on versioned routes.
+type errorResponse struct {
+	// The HTTP status code.
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// versioned takes a route handler and returns a handler for the route beneath
+// the apiVersionPrefix.
+func versioned(h http.HandlerFunc) http.Handler {
+	return http.StripPrefix(apiVersionPrefix, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		jw := &jsonErrorWriter{ResponseWriter: w}
+		h(jw, req)
+		jw.flush()
+	}))
+}
+
+// jsonErrorWriter is an http.ResponseWriter for versioned routes. Errors
+// written with writeNLError before any other response are written as an
+// errorResponse. The status code is held until the response is written so
+// that the error status can be determined.
+type jsonErrorWriter struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+// WriteHeader holds the status code until the response is written.
+func (w *jsonErrorWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.status = code
+	}
+}
+
+// Write writes the held status code, if any, and b.
+func (w *jsonErrorWriter) Write(b []byte) (int, error) {
+	w.flush()
+	return w.ResponseWriter.Write(b)
+}
+
+// flush writes the held status code if the response hasn't been written.
+func (w *jsonErrorWriter) flush() {
+	if w.wrote {
+		return
+	}
+
+	w.wrote = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// writeError writes err as an errorResponse. The held status code is used if
+// it's an error status, otherwise the status is determined from err.
+func (w *jsonErrorWriter) writeError(err error) {
+	code := w.status
+	if code < http.StatusBadRequest {
+		code = errorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.status = code
+	w.flush()
+
+	json.NewEncoder(w.ResponseWriter).Encode(errorResponse{Code: code, Message: err.Error()})
+}
+
+// errorStatus returns the HTTP status code for an error written without an
+// explicit error status.
+func errorStatus(err error) int {
+	for _, e := range badRequestErrors {
+		if errors.Is(err, e) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
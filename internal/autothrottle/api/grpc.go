@@ -0,0 +1,427 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	pb "github.com/DataDog/kafka-kit/v4/proto/autothrottlepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrRateIsZero is returned when setting an override without a rate.
+	ErrRateIsZero = status.Error(codes.InvalidArgument, "rate field must be >0")
+	// ErrFetchingOverrides is returned when throttle overrides can't be fetched.
+	ErrFetchingOverrides = status.Error(codes.Internal, "error fetching throttle overrides")
+	// ErrStoringOverride is returned when a throttle override can't be stored.
+	ErrStoringOverride = status.Error(codes.Internal, "error storing throttle override")
+	// ErrStoringPause is returned when the pause state can't be stored.
+	ErrStoringPause = status.Error(codes.Internal, "error storing pause state")
+	// ErrRequesterInvalid is returned when the requester metadata is invalid.
+	ErrRequesterInvalid = status.Error(codes.InvalidArgument, errRequesterInvalid.Error())
+)
+
+// RequesterMetadataKey is the gRPC metadata key holding the name of the
+// requester that throttle overrides are set and removed for.
+const RequesterMetadataKey = "requester"
+
+// ForceMetadataKey is the gRPC metadata key that, if set to true, applies
+// throttle overrides that exceed the known capacity of the brokers.
+const ForceMetadataKey = "force"
+
+// RPCServer implements the autothrottle gRPC admin API. It operates on the same
+// throttle override state as the HTTP admin API.
+type RPCServer struct {
+	pb.UnimplementedAutothrottleServer
+	api *API
+}
+
+// NewRPCServer takes an *API and returns an *RPCServer serving it.
+func NewRPCServer(a *API) *RPCServer {
+	return &RPCServer{api: a}
+}
+
+// runRPC starts a gRPC listener for the RPCServer.
+func runRPC(addr string, mode os.FileMode, s *RPCServer) {
+	l, err := listen(addr, mode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srvr := grpc.NewServer()
+	pb.RegisterAutothrottleServer(srvr, s)
+
+	go func() {
+		if err := srvr.Serve(l); err != nil {
+			log.Fatal(err)
+		}
+	}()
+}
+
+// GetThrottle returns the global or a broker-specific throttle override.
+func (s *RPCServer) GetThrottle(ctx context.Context, req *pb.ThrottleRequest) (*pb.ThrottleResponse, error) {
+	log.Printf("[gRPC] GetThrottle %s\n", req)
+
+	c, err := throttlestore.FetchThrottleOverride(s.api.zk, s.api.overridePath(req.BrokerId))
+	if err != nil && err != throttlestore.ErrNoOverrideSet {
+		log.Println(err)
+		return nil, ErrFetchingOverrides
+	}
+
+	resp := &pb.ThrottleResponse{
+		Throttle: s.api.throttleFromConfig(req.BrokerId, *c),
+		Message:  "no throttle override is set",
+	}
+
+	if c.Rate != 0 {
+		resp.Message = fmt.Sprintf("a throttle override is configured at %dMB/s, autoremove==%v%s%s",
+			c.Rate, c.AutoRemove, expiresMessage(c.Expires), requestersMessage(*c))
+	}
+
+	return resp, nil
+}
+
+// SetThrottle sets the global or a broker-specific throttle override.
+func (s *RPCServer) SetThrottle(ctx context.Context, req *pb.ThrottleRequest) (*pb.ThrottleResponse, error) {
+	log.Printf("[gRPC] SetThrottle %s\n", req)
+
+	if req.Rate == 0 {
+		return nil, ErrRateIsZero
+	}
+
+	requester, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := throttlestore.ThrottleOverrideConfig{
+		Rate:       int(req.Rate),
+		AutoRemove: req.Autoremove,
+	}
+
+	if req.TtlSeconds > 0 {
+		c.Expires = time.Now().Add(time.Duration(req.TtlSeconds) * time.Second).Unix()
+	}
+
+	// Check the rate against the known capacity of the brokers the override
+	// applies to.
+	var ids []int
+	if req.BrokerId != nil {
+		ids = []int{int(*req.BrokerId)}
+	}
+
+	exceeded, warning := s.api.checkOverrideRate(c.Rate, ids)
+	if len(exceeded) > 0 {
+		err := capacityExceededError(c.Rate, exceeded)
+		if !forceFromContext(ctx) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s; set the force metadata to apply it anyway", err)
+		}
+		warning = fmt.Sprintf("warning: %s", err)
+	}
+
+	if err := s.storeOverride(req.BrokerId, requester, c); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("throttle successfully set to %dMB/s, autoremove==%v%s%s",
+		c.Rate, c.AutoRemove, expiresMessage(c.Expires), requesterMessage(requester))
+	if warning != "" {
+		message = fmt.Sprintf("%s; %s", message, warning)
+	}
+
+	return &pb.ThrottleResponse{
+		Throttle: s.api.throttleFromConfig(req.BrokerId, c),
+		Message:  message,
+	}, nil
+}
+
+// RemoveThrottle removes the global or a broker-specific throttle override.
+func (s *RPCServer) RemoveThrottle(ctx context.Context, req *pb.ThrottleRequest) (*pb.ThrottleResponse, error) {
+	log.Printf("[gRPC] RemoveThrottle %s\n", req)
+
+	requester, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Removing a rate means setting it to 0.
+	c := throttlestore.ThrottleOverrideConfig{}
+
+	if err := s.storeOverride(req.BrokerId, requester, c); err != nil {
+		return nil, err
+	}
+
+	return &pb.ThrottleResponse{
+		Throttle: s.api.throttleFromConfig(req.BrokerId, c),
+		Message:  "throttle removed" + requesterMessage(requester),
+	}, nil
+}
+
+// ListBrokerThrottles returns all broker-specific throttle overrides.
+func (s *RPCServer) ListBrokerThrottles(ctx context.Context, _ *pb.Empty) (*pb.BrokerThrottlesResponse, error) {
+	log.Println("[gRPC] ListBrokerThrottles")
+
+	overrides, err := s.activeBrokerOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BrokerThrottlesResponse{Throttles: s.api.brokerThrottlesFromOverrides(overrides)}, nil
+}
+
+// RemoveBrokerThrottles removes all broker-specific throttle overrides.
+func (s *RPCServer) RemoveBrokerThrottles(ctx context.Context, _ *pb.Empty) (*pb.BrokerThrottlesResponse, error) {
+	log.Println("[gRPC] RemoveBrokerThrottles")
+
+	requester, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.activeBrokerOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range overrides.IDs() {
+		path := fmt.Sprintf("%s/%d", s.api.Paths.OverrideRate, id)
+		if _, err := throttlestore.SetRequesterOverride(s.api.zk, path, requester, throttlestore.ThrottleOverrideConfig{}); err != nil {
+			log.Println(err)
+			return nil, ErrStoringOverride
+		}
+	}
+
+	if len(overrides) > 0 {
+		s.api.trigger <- struct{}{}
+	}
+
+	return &pb.BrokerThrottlesResponse{Throttles: s.api.brokerThrottlesFromOverrides(overrides)}, nil
+}
+
+// GetStatus returns the autothrottle Status as of the most recent interval.
+func (s *RPCServer) GetStatus(ctx context.Context, _ *pb.Empty) (*pb.StatusResponse, error) {
+	log.Println("[gRPC] GetStatus")
+
+	st := s.api.getStatus()
+
+	resp := &pb.StatusResponse{
+		ReassigningTopics: st.ReassigningTopics,
+		GuardrailsTripped: st.GuardrailsTripped,
+		Paused:            st.Paused,
+		RfIncreaseTopics:  st.RFIncreaseTopics,
+	}
+
+	if !st.Updated.IsZero() {
+		resp.Updated = st.Updated.Unix()
+	}
+
+	for _, id := range st.ReassigningBrokers {
+		resp.ReassigningBrokers = append(resp.ReassigningBrokers, uint32(id))
+	}
+
+	if u := s.api.getUnknownThrottles(); len(u.Brokers) > 0 || len(u.Topics) > 0 {
+		resp.UnknownThrottles = &pb.UnknownThrottles{Topics: u.Topics, Action: u.Action}
+		for _, id := range u.Brokers {
+			resp.UnknownThrottles.Brokers = append(resp.UnknownThrottles.Brokers, uint32(id))
+		}
+	}
+
+	for _, s := range st.Sessions {
+		resp.Sessions = append(resp.Sessions, &pb.ReassignmentSession{
+			Id:      s.ID,
+			Started: s.Started.Unix(),
+			Topics:  s.Topics,
+		})
+	}
+
+	var ids []int
+	for id := range st.Throttles {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		rates := st.Throttles[id]
+		resp.AppliedThrottles = append(resp.AppliedThrottles, &pb.AppliedThrottle{
+			BrokerId:     uint32(id),
+			LeaderRate:   rates[0],
+			FollowerRate: rates[1],
+		})
+	}
+
+	return resp, nil
+}
+
+// GetCapacity returns the configured throttle rate limits and instance-type
+// network capacities.
+func (s *RPCServer) GetCapacity(ctx context.Context, _ *pb.Empty) (*pb.CapacityResponse, error) {
+	log.Println("[gRPC] GetCapacity")
+
+	limits := s.api.getLimits()
+
+	resp := &pb.CapacityResponse{
+		Minimum:                      limits["minimum"],
+		SourceMaximum:                limits["srcMax"],
+		DestinationMaximum:           limits["dstMax"],
+		RfIncreaseSourceMaximum:      limits["rfSrcMax"],
+		RfIncreaseDestinationMaximum: limits["rfDstMax"],
+		Capacities:                   map[string]float64{},
+	}
+
+	for k, v := range limits {
+		switch k {
+		case "minimum", "srcMin", "dstMin", "srcMax", "dstMax", "srcMaxAbs", "dstMaxAbs", "rfSrcMax", "rfDstMax", "crossAZSrcMax", "crossAZDstMax", "clusterMax", "defaultCapacity":
+		default:
+			// Instance-type minimums aren't capacities.
+			if strings.HasPrefix(k, "minimum:") {
+				continue
+			}
+			// Asymmetric capacities are reported with their "tx:" and "rx:"
+			// prefixes.
+			resp.Capacities[k] = v
+		}
+	}
+
+	return resp, nil
+}
+
+// Pause pauses autothrottle.
+func (s *RPCServer) Pause(ctx context.Context, _ *pb.Empty) (*pb.PauseResponse, error) {
+	log.Println("[gRPC] Pause")
+	return s.setPause(true)
+}
+
+// Resume resumes a paused autothrottle.
+func (s *RPCServer) Resume(ctx context.Context, _ *pb.Empty) (*pb.PauseResponse, error) {
+	log.Println("[gRPC] Resume")
+	return s.setPause(false)
+}
+
+// setPause stores the pause state and signals the trigger.
+func (s *RPCServer) setPause(paused bool) (*pb.PauseResponse, error) {
+	c, err := s.api.storePause(paused)
+	if err != nil {
+		log.Println(err)
+		return nil, ErrStoringPause
+	}
+
+	s.api.trigger <- struct{}{}
+
+	return &pb.PauseResponse{
+		Paused:  c.Paused,
+		Since:   c.Since,
+		Message: strings.TrimSuffix(pauseMessage(c), "\n"),
+	}, nil
+}
+
+// storeOverride stores the throttle override config c of requester for the
+// global or broker-specific override and signals the trigger.
+func (s *RPCServer) storeOverride(id *uint32, requester string, c throttlestore.ThrottleOverrideConfig) error {
+	if _, err := throttlestore.SetRequesterOverride(s.api.zk, s.api.overridePath(id), requester, c); err != nil {
+		log.Println(err)
+		return ErrStoringOverride
+	}
+
+	s.api.trigger <- struct{}{}
+
+	return nil
+}
+
+// activeBrokerOverrides returns all broker-specific overrides not marked for
+// removal.
+func (s *RPCServer) activeBrokerOverrides() (throttlestore.BrokerOverrides, error) {
+	overrides, err := throttlestore.FetchBrokerOverrides(s.api.zk, s.api.Paths.OverrideRate)
+	if err != nil {
+		log.Println(err)
+		return nil, ErrFetchingOverrides
+	}
+
+	return overrides.Filter(func(bto throttlestore.BrokerThrottleOverride) bool {
+		return bto.Config.Rate != 0
+	}), nil
+}
+
+// requesterFromContext returns the requester named in the incoming request
+// metadata, or an empty string if unset.
+func requesterFromContext(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(RequesterMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", nil
+	}
+
+	if !requesterPattern.MatchString(values[0]) {
+		return "", ErrRequesterInvalid
+	}
+
+	return values[0], nil
+}
+
+// forceFromContext returns whether the force metadata of the request is set to
+// true.
+func forceFromContext(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(ForceMetadataKey)
+	if len(values) == 0 {
+		return false
+	}
+
+	force, _ := strconv.ParseBool(values[0])
+
+	return force
+}
+
+// overridePath returns the override config path for the broker ID, or the
+// global override path if the ID is nil.
+func (a *API) overridePath(id *uint32) string {
+	if id == nil {
+		return a.Paths.OverrideRate
+	}
+
+	return fmt.Sprintf("%s/%d", a.Paths.OverrideRate, *id)
+}
+
+// throttleFromConfig takes an optional broker ID and a throttle override
+// config and returns a *pb.Throttle.
+func (a *API) throttleFromConfig(id *uint32, c throttlestore.ThrottleOverrideConfig) *pb.Throttle {
+	t := &pb.Throttle{
+		BrokerId:   id,
+		Rate:       uint32(c.Rate),
+		Autoremove: c.AutoRemove,
+		Expires:    c.Expires,
+	}
+
+	if id != nil {
+		t.Reassigning = a.isReassigningBroker(int(*id))
+	}
+
+	return t
+}
+
+// brokerThrottlesFromOverrides takes a throttlestore.BrokerOverrides and
+// returns a []*pb.Throttle sorted by broker ID.
+func (a *API) brokerThrottlesFromOverrides(overrides throttlestore.BrokerOverrides) []*pb.Throttle {
+	ids := overrides.IDs()
+	sort.Ints(ids)
+
+	var throttles []*pb.Throttle
+	for _, id := range ids {
+		bid := uint32(id)
+		throttles = append(throttles, a.throttleFromConfig(&bid, overrides[id].Config))
+	}
+
+	return throttles
+}
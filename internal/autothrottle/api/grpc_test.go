@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	pb "github.com/DataDog/kafka-kit/v4/proto/autothrottlepb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRPCSetGetRemoveThrottle(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	s := NewRPCServer(a)
+	ctx := context.Background()
+	id := uint32(123)
+
+	// WHEN
+	_, err := s.SetThrottle(ctx, &pb.ThrottleRequest{BrokerId: &id, Rate: 5, TtlSeconds: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := s.GetThrottle(ctx, &pb.ThrottleRequest{BrokerId: &id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// THEN
+	if resp.Throttle.Rate != 5 || resp.Throttle.GetBrokerId() != 123 {
+		t.Errorf("Unexpected throttle %s", resp.Throttle)
+	}
+
+	if resp.Throttle.Expires < time.Now().Unix() {
+		t.Errorf("Expected a future expiry, got %d", resp.Throttle.Expires)
+	}
+
+	// WHEN
+	if _, err := s.RemoveThrottle(ctx, &pb.ThrottleRequest{BrokerId: &id}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, _ = s.GetThrottle(ctx, &pb.ThrottleRequest{BrokerId: &id})
+
+	// THEN
+	if resp.Throttle.Rate != 0 {
+		t.Errorf("Expected throttle to be removed, got rate %d", resp.Throttle.Rate)
+	}
+
+	// 2 = 1 set + 1 remove
+	if triggered := countTrigger(); triggered != 2 {
+		t.Errorf("mutation did not trigger config application, trigger channel length: %d", triggered)
+	}
+
+	if _, err := s.SetThrottle(ctx, &pb.ThrottleRequest{}); err != ErrRateIsZero {
+		t.Errorf("Expected ErrRateIsZero, got %v", err)
+	}
+}
+
+func TestRPCSetThrottleCapacity(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	s := NewRPCServer(a)
+	id := uint32(1001)
+
+	a.SetLimits(map[string]float64{"minimum": 10, "i3.xlarge": 100})
+	a.SetStatus(Status{BrokerInstanceTypes: map[int]string{1001: "i3.xlarge"}})
+
+	// WHEN
+	_, err := s.SetThrottle(context.Background(), &pb.ThrottleRequest{BrokerId: &id, Rate: 150})
+
+	// THEN
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected a FailedPrecondition error, got %v", err)
+	}
+
+	// WHEN
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ForceMetadataKey, "true"))
+	resp, err := s.SetThrottle(ctx, &pb.ThrottleRequest{BrokerId: &id, Rate: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// THEN
+	expected := "throttle successfully set to 150MB/s, autoremove==false; " +
+		"warning: rate 150MB/s exceeds the network capacity of broker 1001 (i3.xlarge, 100.00MB/s)"
+	if resp.Message != expected {
+		t.Errorf("Expected message '%s', got '%s'", expected, resp.Message)
+	}
+}
+
+func TestRPCListRemoveBrokerThrottles(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	s := NewRPCServer(a)
+	ctx := context.Background()
+	a.SetStatus(Status{ReassigningBrokers: []int{456}})
+
+	for id, rate := range map[uint32]uint32{123: 5, 456: 10} {
+		id := id
+		if _, err := s.SetThrottle(ctx, &pb.ThrottleRequest{BrokerId: &id, Rate: rate}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// WHEN
+	list, err := s.ListBrokerThrottles(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// THEN
+	if len(list.Throttles) != 2 {
+		t.Fatalf("Expected 2 broker throttles, got %d", len(list.Throttles))
+	}
+
+	expected := []struct {
+		id          uint32
+		rate        uint32
+		reassigning bool
+	}{
+		{123, 5, false},
+		{456, 10, true},
+	}
+
+	for i, e := range expected {
+		got := list.Throttles[i]
+		if got.GetBrokerId() != e.id || got.Rate != e.rate || got.Reassigning != e.reassigning {
+			t.Errorf("Expected broker %d rate %d reassigning %v, got %s", e.id, e.rate, e.reassigning, got)
+		}
+	}
+
+	// WHEN
+	if _, err := s.RemoveBrokerThrottles(ctx, &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, _ = s.ListBrokerThrottles(ctx, &pb.Empty{})
+
+	// THEN
+	if len(list.Throttles) != 0 {
+		t.Errorf("Expected no broker throttles, got %d", len(list.Throttles))
+	}
+}
+
+func TestRPCGetStatusCapacity(t *testing.T) {
+	// GIVEN
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	s := NewRPCServer(a)
+	ctx := context.Background()
+	rate := 50.0
+
+	a.SetStatus(Status{
+		ReassigningTopics:  []string{"test"},
+		ReassigningBrokers: []int{1001, 1002},
+		GuardrailsTripped:  true,
+		Throttles:          map[int][2]*float64{1001: {&rate, nil}},
+		Sessions:           []ReassignmentSession{{ID: "a1b2c3d4", Started: time.Now(), Topics: []string{"test"}}},
+		Updated:            time.Now(),
+	})
+
+	a.SetLimits(map[string]float64{"minimum": 10, "srcMin": 10, "dstMin": 15, "srcMax": 80, "dstMax": 90, "rfSrcMax": 40, "rfDstMax": 90, "crossAZSrcMax": 50, "d2.2xlarge": 120, "minimum:d2.2xlarge": 20})
+
+	// WHEN
+	st, err := s.GetStatus(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	capacity, err := s.GetCapacity(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// THEN
+	if len(st.ReassigningTopics) != 1 || len(st.ReassigningBrokers) != 2 || !st.GuardrailsTripped {
+		t.Errorf("Unexpected status %s", st)
+	}
+
+	if len(st.AppliedThrottles) != 1 || st.AppliedThrottles[0].GetLeaderRate() != 50 || st.AppliedThrottles[0].FollowerRate != nil {
+		t.Errorf("Unexpected applied throttles %s", st.AppliedThrottles)
+	}
+
+	if len(st.Sessions) != 1 || st.Sessions[0].Id != "a1b2c3d4" || len(st.Sessions[0].Topics) != 1 {
+		t.Errorf("Unexpected sessions %s", st.Sessions)
+	}
+
+	if capacity.Minimum != 10 || capacity.SourceMaximum != 80 || capacity.DestinationMaximum != 90 ||
+		capacity.RfIncreaseSourceMaximum != 40 || capacity.RfIncreaseDestinationMaximum != 90 {
+		t.Errorf("Unexpected limits %s", capacity)
+	}
+
+	if len(capacity.Capacities) != 1 || capacity.Capacities["d2.2xlarge"] != 120 {
+		t.Errorf("Unexpected capacities %v", capacity.Capacities)
+	}
+}
+
+func TestRPCPauseResume(t *testing.T) {
+	t.Cleanup(clearTrigger)
+	// GIVEN
+	a := newTestAPI(kafkazk.NewZooKeeperStub())
+	s := NewRPCServer(a)
+	ctx := context.Background()
+
+	// WHEN
+	paused, err := s.Pause(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pausedAgain, _ := s.Pause(ctx, &pb.Empty{})
+	resumed, err := s.Resume(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// THEN
+	if !paused.Paused || paused.Since == 0 {
+		t.Errorf("Unexpected pause response %s", paused)
+	}
+
+	if pausedAgain.Since != paused.Since {
+		t.Errorf("Expected pause time %d to be retained, got %d", paused.Since, pausedAgain.Since)
+	}
+
+	if resumed.Paused || resumed.Message != "autothrottle is running" {
+		t.Errorf("Unexpected resume response %s", resumed)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// unixScheme prefixes listen addresses that are Unix domain socket paths.
+const unixScheme = "unix://"
+
+// DefaultSocketMode is the file mode of Unix domain sockets that the admin
+// APIs listen on, unless otherwise configured.
+const DefaultSocketMode os.FileMode = 0660
+
+// listen returns a listener for addr, which is either a TCP address:port or a
+// Unix domain socket path prefixed with unix:// (e.g.
+// unix:///var/run/autothrottle.sock). Sockets are created with the file mode
+// mode, replacing any socket left at the path by a previous process.
+func listen(addr string, mode os.FileMode) (net.Listener, error) {
+	path, isUnix := strings.CutPrefix(addr, unixScheme)
+	if !isUnix {
+		return net.Listen("tcp", addr)
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("invalid listen address %q: no socket path", addr)
+	}
+
+	// A socket is left behind if the previous process didn't exit cleanly.
+	// Anything else at the path is left alone.
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("cannot listen on %s: file exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == 0 {
+		mode = DefaultSocketMode
+	}
+
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autothrottle.sock")
+
+	l, err := listen(unixScheme+path, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := fi.Mode(); mode&os.ModeSocket == 0 || mode.Perm() != 0600 {
+		t.Errorf("Expected a socket with mode 0600, got %s", mode)
+	}
+
+	go http.Serve(l, http.HandlerFunc(newTestAPI(kafkazk.NewZooKeeperStub()).getStatusHandler))
+
+	client := http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+
+	resp, err := client.Get("http://autothrottle/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	l.Close()
+}
+
+func TestListenUnixStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autothrottle.sock")
+
+	// Leave a socket behind, as an unclean exit would.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := listen(unixScheme+path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	fi, _ := os.Stat(path)
+	if mode := fi.Mode().Perm(); mode != DefaultSocketMode {
+		t.Errorf("Expected mode %s, got %s", DefaultSocketMode, mode)
+	}
+}
+
+func TestListenInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autothrottle.sock")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files that aren't sockets aren't removed.
+	for _, addr := range []string{unixScheme, unixScheme + path} {
+		if _, err := listen(addr, 0); err == nil {
+			t.Errorf("[%s] Expected an error", addr)
+		}
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected %s to exist: %s", path, err)
+	}
+}
+
+func TestListenTCP(t *testing.T) {
+	l, err := listen("localhost:0", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if l.Addr().Network() != "tcp" {
+		t.Errorf("Expected a tcp listener, got %s", l.Addr().Network())
+	}
+}
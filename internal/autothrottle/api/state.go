@@ -0,0 +1,247 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/history"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// Status describes autothrottle runtime state as of the most recent interval.
+type Status struct {
+	// Topics undergoing a reassignment.
+	ReassigningTopics []string
+	// Topics where all reassigning partitions are replication factor increases.
+	RFIncreaseTopics []string
+	// Brokers participating in a reassignment.
+	ReassigningBrokers []int
+	// Whether the cluster health guardrails are tripped.
+	GuardrailsTripped bool
+	// Whether autothrottle is paused.
+	Paused bool
+	// The throttle manager holding the manager lock, if another than
+	// autothrottle. No throttle changes are applied while it's held.
+	ManagerLockHolder string
+	// The Cruise Control execution in progress, if any.
+	CruiseControlExecution string
+	// Map of broker ID to the most recently applied leader and follower
+	// throttle rates in MB/s, in respective order to index. A nil value means
+	// no throttle was applied for the role.
+	Throttles map[int][2]*float64
+	// Map of broker ID to the broker override applied to brokers participating
+	// in a reassignment.
+	Overrides map[int]BrokerOverrideStatus
+	// Map of broker ID to how the throttle rates were resolved for brokers
+	// participating in a reassignment.
+	Resolutions map[int]BrokerResolution
+	// Active reassignment sessions.
+	Sessions []ReassignmentSession
+	// Map of broker ID to instance type for brokers using the default
+	// capacity because their instance type has no configured capacity.
+	FallbackCapacityBrokers map[int]string
+	// Map of broker ID to the instance type last observed in broker metrics,
+	// used to check override rates against broker capacities.
+	BrokerInstanceTypes map[int]string
+	// Topics with throttled replicas configs orphaned by finished
+	// reassignments, as of the most recent scan.
+	OrphanedThrottleTopics []string
+	// Brokers with metrics interpolated from previous observations in the most
+	// recent throttle update because they were missing from the metrics fetch.
+	InterpolatedBrokers []int
+	// The number of events dropped since startup because the event buffer
+	// was full.
+	DroppedEvents uint64
+	// The time the status was set.
+	Updated time.Time
+}
+
+// BrokerOverrideStatus describes a broker override applied to a broker
+// participating in a reassignment.
+type BrokerOverrideStatus struct {
+	// The override rate in MB/s.
+	Rate int
+	// How the override rate is combined with the determined rate; empty if
+	// the override rate is used as-is.
+	Precedence string
+	// Whether the override is a pinned throttle.
+	Pinned bool
+	// The effective leader and follower rates in MB/s, in respective order to
+	// index.
+	Effective [2]*float64
+}
+
+// BrokerResolution describes how the throttle rates of a broker participating
+// in a reassignment were resolved from the available rate sources.
+type BrokerResolution struct {
+	// The resolved leader and follower rates in MB/s, in respective order to
+	// index.
+	Rates [2]*float64
+	// The source of the rates.
+	Reason string
+	// Each rate source considered, in order.
+	Steps []ResolutionStep
+}
+
+// ResolutionStep describes a rate source considered for a broker.
+type ResolutionStep struct {
+	Source  string
+	Applied bool
+	// Why the source was or wasn't applied.
+	Detail string
+	// The leader and follower rates after the step, if applied.
+	Rates [2]*float64
+}
+
+// ReassignmentSession is a set of topics that started reassigning together,
+// identified by a correlation ID included in logs and events.
+type ReassignmentSession struct {
+	ID      string
+	Started time.Time
+	// Topics from the session still being reassigned.
+	Topics []string
+}
+
+// UnknownThrottles describes replication throttles found at startup that
+// autothrottle had no record of.
+type UnknownThrottles struct {
+	Brokers []int
+	Topics  []string
+	// The action taken on the throttles, e.g. adopted or removed.
+	Action string
+}
+
+// API is the autothrottle admin API. It holds the autothrottle runtime state
+// that's updated each interval and exposed through the HTTP and gRPC admin
+// APIs.
+type API struct {
+	// The autothrottle config znode paths.
+	Paths ZnodePaths
+
+	zk      kafkazk.Handler
+	trigger chan<- struct{}
+
+	mu                 sync.RWMutex
+	status             Status
+	reassigningBrokers map[int]struct{}
+	limits             map[string]float64
+	unknownThrottles   UnknownThrottles
+	history            *history.History
+	// Whether requests to write reassignments are rejected.
+	noReassignmentWrites bool
+}
+
+// New takes a kafkazk.Handler, the autothrottle ZooKeeper prefix and a trigger
+// channel that's signalled on any override changes and returns an *API.
+func New(zk kafkazk.Handler, prefix string, trigger chan<- struct{}) *API {
+	return &API{
+		Paths:   NewZnodePaths(prefix),
+		zk:      zk,
+		trigger: trigger,
+	}
+}
+
+// SetStatus sets the autothrottle Status.
+func (a *API) SetStatus(s Status) {
+	brokers := make(map[int]struct{}, len(s.ReassigningBrokers))
+	for _, id := range s.ReassigningBrokers {
+		brokers[id] = struct{}{}
+	}
+
+	a.mu.Lock()
+	a.status = s
+	a.reassigningBrokers = brokers
+	a.mu.Unlock()
+}
+
+// getStatus returns the most recently set Status.
+func (a *API) getStatus() Status {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.status
+}
+
+// SetLimits takes a map of the configured min/max throttle rates and
+// instance-type capacities, as populated in a replication.Limits.
+func (a *API) SetLimits(l map[string]float64) {
+	limits := make(map[string]float64, len(l))
+	for k, v := range l {
+		limits[k] = v
+	}
+
+	a.mu.Lock()
+	a.limits = limits
+	a.mu.Unlock()
+}
+
+// getLimits returns a copy of the configured limits.
+func (a *API) getLimits() map[string]float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	limits := make(map[string]float64, len(a.limits))
+	for k, v := range a.limits {
+		limits[k] = v
+	}
+
+	return limits
+}
+
+// SetUnknownThrottles sets the UnknownThrottles found at startup.
+func (a *API) SetUnknownThrottles(u UnknownThrottles) {
+	a.mu.Lock()
+	a.unknownThrottles = u
+	a.mu.Unlock()
+}
+
+// getUnknownThrottles returns the UnknownThrottles found at startup.
+func (a *API) getUnknownThrottles() UnknownThrottles {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.unknownThrottles
+}
+
+// SetHistory sets the History served by the admin API. History is
+// unavailable if nil.
+func (a *API) SetHistory(h *history.History) {
+	a.mu.Lock()
+	a.history = h
+	a.mu.Unlock()
+}
+
+// getHistory returns the History, if set.
+func (a *API) getHistory() *history.History {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.history
+}
+
+// DisableReassignmentWrites rejects requests to submit or cancel
+// reassignments and to set reassignment plans, for kafkazk.Handlers that can't
+// write reassignments.
+func (a *API) DisableReassignmentWrites() {
+	a.mu.Lock()
+	a.noReassignmentWrites = true
+	a.mu.Unlock()
+}
+
+// reassignmentWritesDisabled returns whether reassignment writes are disabled.
+func (a *API) reassignmentWritesDisabled() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.noReassignmentWrites
+}
+
+// isReassigningBroker returns whether the broker ID was participating in a
+// reassignment as of the most recent interval.
+func (a *API) isReassigningBroker(id int) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, exists := a.reassigningBrokers[id]
+	return exists
+}
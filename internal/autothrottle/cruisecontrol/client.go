@@ -0,0 +1,126 @@
+// Package cruisecontrol provides a minimal Cruise Control REST API client for
+// checking whether Cruise Control is executing a proposal, e.g. a rebalance.
+package cruisecontrol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StateNoTaskInProgress is the executor state while no execution is in
+// progress.
+const StateNoTaskInProgress = "NO_TASK_IN_PROGRESS"
+
+// Config holds Client configurations.
+type Config struct {
+	// The Cruise Control API URL including the path prefix, e.g.
+	// http://cruise-control:9090/kafkacruisecontrol. Basic auth credentials
+	// can be included in the URL.
+	URL string
+	// The HTTP client used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Client is a Cruise Control API client.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// ExecutorState is the state of the Cruise Control executor.
+type ExecutorState struct {
+	// The executor state, e.g. NO_TASK_IN_PROGRESS or
+	// INTER_BROKER_REPLICA_MOVEMENT_TASK_IN_PROGRESS.
+	State string `json:"state"`
+	// The IDs of the user or self-healing task that triggered the execution,
+	// if any.
+	TriggeredUserTaskID        string `json:"triggeredUserTaskId"`
+	TriggeredSelfHealingTaskID string `json:"triggeredSelfHealingTaskId"`
+	// The reason the execution was triggered, if known.
+	TriggeredTaskReason string `json:"triggeredTaskReason"`
+}
+
+// InProgress returns whether an execution is in progress.
+func (s ExecutorState) InProgress() bool {
+	return s.State != "" && s.State != StateNoTaskInProgress
+}
+
+// TaskID returns the ID of the task that triggered the execution, if any.
+func (s ExecutorState) TaskID() string {
+	if s.TriggeredUserTaskID != "" {
+		return s.TriggeredUserTaskID
+	}
+
+	return s.TriggeredSelfHealingTaskID
+}
+
+// String returns a description of the execution.
+func (s ExecutorState) String() string {
+	if id := s.TaskID(); id != "" {
+		return fmt.Sprintf("%s (task %s)", s.State, id)
+	}
+
+	return s.State
+}
+
+// NewClient takes a Config and returns a *Client.
+func NewClient(c Config) *Client {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	return &Client{
+		url:  strings.TrimSuffix(c.URL, "/"),
+		http: hc,
+	}
+}
+
+// ExecutorState returns the state of the Cruise Control executor.
+func (c *Client) ExecutorState(ctx context.Context) (ExecutorState, error) {
+	var resp struct {
+		ExecutorState ExecutorState `json:"ExecutorState"`
+	}
+
+	if err := c.get(ctx, "/state?substates=executor&json=true", &resp); err != nil {
+		return ExecutorState{}, err
+	}
+
+	if resp.ExecutorState.State == "" {
+		return ExecutorState{}, fmt.Errorf("no executor state in the Cruise Control response")
+	}
+
+	return resp.ExecutorState, nil
+}
+
+// get makes a GET request and unmarshals the response into out.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, bytes.TrimSpace(data))
+	}
+
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,63 @@
+package cruisecontrol
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExecutorState(t *testing.T) {
+	var response string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path != "/kafkacruisecontrol/state" || r.URL.Query().Get("substates") != "executor" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(response))
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{URL: "http://admin:secret@" + srv.Listener.Addr().String() + "/kafkacruisecontrol/"})
+
+	tests := []struct {
+		response   string
+		inProgress bool
+		expected   string
+	}{
+		{`{"ExecutorState": {"state": "NO_TASK_IN_PROGRESS"}, "version": 1}`, false, "NO_TASK_IN_PROGRESS"},
+		{`{"ExecutorState": {"state": "INTER_BROKER_REPLICA_MOVEMENT_TASK_IN_PROGRESS", "triggeredUserTaskId": "5b0c9f3e"}, "version": 1}`,
+			true, "INTER_BROKER_REPLICA_MOVEMENT_TASK_IN_PROGRESS (task 5b0c9f3e)"},
+	}
+
+	for i, test := range tests {
+		response = test.response
+
+		state, err := c.ExecutorState(context.Background())
+		if err != nil {
+			t.Fatalf("[test %d] %s", i, err)
+		}
+
+		if state.InProgress() != test.inProgress || state.String() != test.expected {
+			t.Errorf("[test %d] Expected %s (in progress==%v), got %s (in progress==%v)",
+				i, test.expected, test.inProgress, state, state.InProgress())
+		}
+	}
+
+	response = `{"version": 1}`
+	if _, err := c.ExecutorState(context.Background()); err == nil {
+		t.Error("Expected an error for a response without an executor state")
+	}
+
+	c = NewClient(Config{URL: srv.URL + "/kafkacruisecontrol"})
+	if _, err := c.ExecutorState(context.Background()); err == nil {
+		t.Error("Expected an error for an unauthorized request")
+	}
+}
@@ -0,0 +1,133 @@
+// Package decisionlog writes autothrottle throttle decisions to a Kafka topic,
+// providing a durable stream of throttle changes for analytics and replay.
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// KafkaWriterConfig holds KafkaWriter configuration parameters.
+type KafkaWriterConfig struct {
+	// The decision topic. It's created if it doesn't exist.
+	Topic string
+	// The partitions and replication factor the topic is created with. Default
+	// to 1 and 3.
+	Partitions        int
+	ReplicationFactor int
+	// The Kafka client config.
+	Admin kafkaadmin.Config
+}
+
+// KafkaWriter is a replication.DecisionWriter that produces each Decision as
+// a JSON message to a Kafka topic, keyed by broker ID. Messages are produced
+// asynchronously; delivery failures are logged.
+type KafkaWriter struct {
+	topic     string
+	producer  *kafkaadmin.Producer
+	timeoutMs int
+	done      chan struct{}
+}
+
+// NewKafkaWriter takes a KafkaWriterConfig and returns a *KafkaWriter,
+// creating the topic if it doesn't exist.
+func NewKafkaWriter(ctx context.Context, cfg KafkaWriterConfig) (*KafkaWriter, error) {
+	if cfg.Partitions == 0 {
+		cfg.Partitions = 1
+	}
+
+	if cfg.ReplicationFactor == 0 {
+		cfg.ReplicationFactor = 3
+	}
+
+	ka, err := kafkaadmin.NewClient(cfg.Admin)
+	if err != nil {
+		return nil, err
+	}
+	defer ka.Close()
+
+	// Topics that already exist are left as is.
+	_, err = ka.CreateTopicIfNotExists(ctx, kafkaadmin.CreateTopicConfig{
+		Name:              cfg.Topic,
+		Partitions:        cfg.Partitions,
+		ReplicationFactor: cfg.ReplicationFactor,
+	}, kafkaadmin.TopicExistsIgnore)
+	if err != nil {
+		return nil, fmt.Errorf("error creating decision topic: %s", err)
+	}
+
+	producer, err := kafkaadmin.NewProducer(cfg.Admin)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutMs := cfg.Admin.DefaultTimeoutMs
+	if timeoutMs == 0 {
+		timeoutMs = 5000
+	}
+
+	w := &KafkaWriter{
+		topic:     cfg.Topic,
+		producer:  producer,
+		timeoutMs: timeoutMs,
+		done:      make(chan struct{}),
+	}
+
+	go w.handleEvents()
+
+	return w, nil
+}
+
+// WriteDecision produces the Decision to the topic.
+func (w *KafkaWriter) WriteDecision(d replication.Decision) {
+	value, err := json.Marshal(d)
+	if err != nil {
+		log.Printf("Error encoding throttle decision: %s\n", err)
+		return
+	}
+
+	err = w.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &w.topic, Partition: kafka.PartitionAny},
+		Key:            []byte(strconv.Itoa(d.Broker)),
+		Value:          value,
+		Timestamp:      d.Time,
+	}, nil)
+	if err != nil {
+		log.Printf("Error writing throttle decision: %s\n", err)
+	}
+}
+
+// handleEvents logs delivery failures and producer errors until the producer
+// is closed.
+func (w *KafkaWriter) handleEvents() {
+	defer close(w.done)
+
+	for e := range w.producer.Events() {
+		switch ev := e.(type) {
+		case *kafka.Message:
+			if ev.TopicPartition.Error != nil {
+				log.Printf("Error writing throttle decision: %s\n", ev.TopicPartition.Error)
+			}
+		case kafka.Error:
+			log.Printf("Decision producer error: %s\n", ev)
+		}
+	}
+}
+
+// Close flushes any outstanding Decisions and closes the KafkaWriter.
+func (w *KafkaWriter) Close() {
+	if n := w.producer.Flush(w.timeoutMs); n > 0 {
+		log.Printf("%d throttle decisions weren't written before closing\n", n)
+	}
+
+	w.producer.Close()
+	<-w.done
+}
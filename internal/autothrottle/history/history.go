@@ -0,0 +1,262 @@
+// Package history retains the replication throttle rates and measured network
+// utilization of each autothrottle interval for post-incident analysis,
+// independently of whether events reached an external system.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is the state of an autothrottle interval.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Map of broker ID to the broker's rates as of the interval.
+	Brokers map[int]BrokerRates `json:"brokers"`
+}
+
+// BrokerRates holds the replication throttle rates applied to a broker and
+// its measured network utilization, in MB/s. Nil values weren't applied or
+// measured in the interval.
+type BrokerRates struct {
+	Leader   *float64 `json:"leader,omitempty"`
+	Follower *float64 `json:"follower,omitempty"`
+	NetTX    *float64 `json:"net_tx,omitempty"`
+	NetRX    *float64 `json:"net_rx,omitempty"`
+}
+
+// Config holds History configuration parameters.
+type Config struct {
+	// The maximum number of entries retained.
+	Size int
+	// Optional file that each entry is appended to as a JSON line. Entries
+	// in the file are loaded when the History is created, so that history
+	// survives restarts. The file is compacted to the retained entries at
+	// startup and whenever it holds twice the Size.
+	File string
+}
+
+// History is a fixed size ring buffer of Entries, oldest first. It's safe for
+// concurrent use.
+type History struct {
+	mu      sync.RWMutex
+	entries []Entry
+	// The index the next Entry is written to.
+	next int
+	full bool
+	// The optional persistence file and the number of lines it holds.
+	path  string
+	file  *os.File
+	lines int
+}
+
+// New takes a Config and returns a *History. If a file is configured, the
+// retained entries are loaded from it, creating it if it doesn't exist.
+func New(cfg Config) (*History, error) {
+	if cfg.Size < 1 {
+		return nil, fmt.Errorf("history size must be > 0")
+	}
+
+	h := &History{
+		entries: make([]Entry, cfg.Size),
+		path:    cfg.File,
+	}
+
+	if h.path == "" {
+		return h, nil
+	}
+
+	if err := h.load(); err != nil {
+		return nil, fmt.Errorf("error loading history file %s: %s", h.path, err)
+	}
+
+	if err := h.compact(); err != nil {
+		return nil, fmt.Errorf("error writing history file %s: %s", h.path, err)
+	}
+
+	return h, nil
+}
+
+// Record adds the Entry, replacing the oldest if the History is full. Errors
+// writing the Entry to the file are logged; the Entry is retained in memory
+// regardless.
+func (h *History) Record(e Entry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.add(e)
+
+	if h.file == nil {
+		return
+	}
+
+	if err := h.persist(e); err != nil {
+		log.Printf("Error writing history file %s: %s\n", h.path, err)
+	}
+}
+
+// Since returns the entries recorded at or after the time, oldest first.
+func (h *History) Since(t time.Time) []Entry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var entries []Entry
+	for _, e := range h.ordered() {
+		if !e.Time.Before(t) {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// Close closes the History file, if any.
+func (h *History) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.file == nil {
+		return nil
+	}
+
+	err := h.file.Close()
+	h.file = nil
+
+	return err
+}
+
+// add adds the Entry to the ring buffer.
+func (h *History) add(e Entry) {
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// ordered returns the retained entries, oldest first.
+func (h *History) ordered() []Entry {
+	if !h.full {
+		return h.entries[:h.next]
+	}
+
+	entries := make([]Entry, 0, len(h.entries))
+	entries = append(entries, h.entries[h.next:]...)
+
+	return append(entries, h.entries[:h.next]...)
+}
+
+// persist appends the Entry to the file, compacting the file first if it holds
+// twice the retained entries.
+func (h *History) persist(e Entry) error {
+	if h.lines >= 2*len(h.entries) {
+		// The Entry was already added to the buffer and is written with the
+		// compacted entries.
+		return h.compact()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	h.lines++
+
+	return nil
+}
+
+// load reads the entries in the file, if it exists, into the ring buffer.
+// Lines that can't be decoded, e.g. one partially written at a crash, are
+// skipped.
+func (h *History) load() error {
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var skipped int
+	for s.Scan() {
+		var e Entry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			skipped++
+			continue
+		}
+		h.add(e)
+	}
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		log.Printf("Skipped %d invalid history file entries\n", skipped)
+	}
+
+	return nil
+}
+
+// compact replaces the file with one holding only the retained entries and
+// opens it for appending.
+func (h *History) compact() error {
+	if h.file != nil {
+		h.file.Close()
+		h.file = nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+
+	entries := h.ordered()
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	h.file = f
+	h.lines = len(entries)
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func entryAt(min int) Entry {
+	rate := float64(min)
+	return Entry{
+		Time:    time.Unix(0, 0).Add(time.Duration(min) * time.Minute).UTC(),
+		Brokers: map[int]BrokerRates{1001: {Leader: &rate}},
+	}
+}
+
+func TestSince(t *testing.T) {
+	h, err := New(Config{Size: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 4; i++ {
+		h.Record(entryAt(i))
+	}
+
+	tests := []struct {
+		since    time.Time
+		expected []int
+	}{
+		// Entry 1 was replaced.
+		{time.Unix(0, 0), []int{2, 3, 4}},
+		{entryAt(3).Time, []int{3, 4}},
+		{entryAt(5).Time, nil},
+	}
+
+	for i, test := range tests {
+		entries := h.Since(test.since)
+
+		if len(entries) != len(test.expected) {
+			t.Fatalf("[test %d] Expected %d entries, got %d", i, len(test.expected), len(entries))
+		}
+
+		for n, e := range entries {
+			if !e.Time.Equal(entryAt(test.expected[n]).Time) {
+				t.Errorf("[test %d] Expected entry %d at %s, got %s", i, n, entryAt(test.expected[n]).Time, e.Time)
+			}
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h, err := New(Config{Size: 2, File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries 1 and 2 are compacted away when entry 5 is written.
+	for i := 1; i <= 5; i++ {
+		h.Record(entryAt(i))
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected 2 lines in the compacted file, got %d", lines)
+	}
+
+	// A partially written line is skipped on load.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"time":`)
+	f.Close()
+
+	h, err = New(Config{Size: 2, File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	entries := h.Since(time.Unix(0, 0))
+	if len(entries) != 2 || !entries[0].Time.Equal(entryAt(4).Time) || !entries[1].Time.Equal(entryAt(5).Time) {
+		t.Fatalf("Unexpected entries loaded: %v", entries)
+	}
+
+	if *entries[1].Brokers[1001].Leader != 5 {
+		t.Errorf("Expected leader rate 5, got %f", *entries[1].Brokers[1001].Leader)
+	}
+}
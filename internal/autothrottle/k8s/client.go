@@ -0,0 +1,198 @@
+// Package k8s provides a minimal Kubernetes API client for reading and
+// updating the ConfigMap used to configure autothrottle in Kubernetes
+// operator mode.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+var (
+	// ErrNotInCluster is returned when an in-cluster client is requested but
+	// the Kubernetes service environment variables aren't set.
+	ErrNotInCluster = errors.New("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+	// ErrNotFound is returned when a requested resource doesn't exist.
+	ErrNotFound = errors.New("resource not found")
+)
+
+// Config holds Client configurations.
+type Config struct {
+	// The API server address, e.g. https://10.0.0.1:443.
+	Host string
+	// A bearer token. Ignored if TokenFile is set.
+	Token string
+	// A path to a bearer token, read on each request so that rotated tokens
+	// are picked up.
+	TokenFile string
+	// The HTTP client used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Client is a Kubernetes API client.
+type Client struct {
+	host      string
+	token     string
+	tokenFile string
+	http      *http.Client
+}
+
+// ConfigMap is a Kubernetes ConfigMap.
+type ConfigMap struct {
+	Metadata ObjectMeta        `json:"metadata"`
+	Data     map[string]string `json:"data"`
+}
+
+// ObjectMeta holds Kubernetes object metadata.
+type ObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// NewClient takes a Config and returns a *Client.
+func NewClient(c Config) *Client {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	return &Client{
+		host:      strings.TrimSuffix(c.Host, "/"),
+		token:     c.Token,
+		tokenFile: c.TokenFile,
+		http:      hc,
+	}
+}
+
+// NewInClusterClient returns a *Client configured with the service account
+// credentials mounted into pods.
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("error reading service account CA: %s", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no valid certificates found in service account CA")
+	}
+
+	hc := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}
+
+	return NewClient(Config{
+		Host:       "https://" + net.JoinHostPort(host, port),
+		TokenFile:  serviceAccountDir + "/token",
+		HTTPClient: hc,
+	}), nil
+}
+
+// InClusterNamespace returns the namespace of the pod the process is running
+// in, as mounted from the service account.
+func InClusterNamespace() (string, error) {
+	ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+	if err != nil {
+		return "", fmt.Errorf("error reading service account namespace: %s", err)
+	}
+
+	return strings.TrimSpace(string(ns)), nil
+}
+
+// GetConfigMap returns the named ConfigMap.
+func (c *Client) GetConfigMap(ctx context.Context, namespace, name string) (*ConfigMap, error) {
+	var cm ConfigMap
+	if err := c.do(ctx, http.MethodGet, configMapPath(namespace, name), "", nil, &cm); err != nil {
+		return nil, err
+	}
+
+	return &cm, nil
+}
+
+// PatchConfigMapData merges data into the data of the named ConfigMap.
+func (c *Client) PatchConfigMapData(ctx context.Context, namespace, name string, data map[string]string) error {
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodPatch, configMapPath(namespace, name), "application/merge-patch+json", body, nil)
+}
+
+func configMapPath(namespace, name string) string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/configmaps/%s", url.PathEscape(namespace), url.PathEscape(name))
+}
+
+// do makes a request and unmarshals the response into out, if non-nil.
+func (c *Client) do(ctx context.Context, method, path, contentType string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.host+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	token := c.token
+	if c.tokenFile != "" {
+		t, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return fmt.Errorf("error reading token: %s", err)
+		}
+		token = strings.TrimSpace(string(t))
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(data))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
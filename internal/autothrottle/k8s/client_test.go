@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetConfigMap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v1/namespaces/kafka/configmaps/autothrottle":
+			w.Write([]byte(`{"metadata": {"name": "autothrottle", "namespace": "kafka", "resourceVersion": "10"}, "data": {"paused": "true"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{Host: srv.URL, Token: "token"})
+
+	cm, err := c.GetConfigMap(context.Background(), "kafka", "autothrottle")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cm.Metadata.ResourceVersion != "10" || cm.Data[KeyPaused] != "true" {
+		t.Errorf("Unexpected ConfigMap %+v", cm)
+	}
+
+	if _, err := c.GetConfigMap(context.Background(), "kafka", "missing"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	c = NewClient(Config{Host: srv.URL})
+	if _, err := c.GetConfigMap(context.Background(), "kafka", "autothrottle"); err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
+
+func TestPatchConfigMapData(t *testing.T) {
+	var contentType string
+	var patch map[string]map[string]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &patch)
+
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{Host: srv.URL})
+
+	err := c.PatchConfigMapData(context.Background(), "kafka", "autothrottle", map[string]string{KeyStatus: "{}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "application/merge-patch+json" {
+		t.Errorf("Unexpected content type %s", contentType)
+	}
+
+	if patch["data"][KeyStatus] != "{}" {
+		t.Errorf("Unexpected patch %v", patch)
+	}
+}
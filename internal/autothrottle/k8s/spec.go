@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/replication"
+)
+
+// ConfigMap data keys.
+const (
+	// KeyPaused holds "true" or "false".
+	KeyPaused = "paused"
+	// KeyOverride holds a JSON Override for the global throttle override.
+	KeyOverride = "override"
+	// KeyBrokerOverrides holds a JSON map of broker ID to Override.
+	KeyBrokerOverrides = "broker-overrides"
+	// KeyCapacityMap holds a JSON map of instance type to network capacity in
+	// MB/s.
+	KeyCapacityMap = "capacity-map"
+	// KeyStatus holds the JSON Status written back by autothrottle.
+	KeyStatus = "status"
+)
+
+// Spec is the desired autothrottle state declared in a ConfigMap.
+type Spec struct {
+	Paused bool
+	// The global throttle override. Nil if unset.
+	Override *Override
+	// Map of broker ID to broker-specific throttle override.
+	BrokerOverrides map[int]Override
+	// Map of instance type to network capacity in MB/s, merged over the
+	// statically configured capacity map.
+	CapacityMap replication.CapacityMap
+}
+
+// Override is a throttle override.
+type Override struct {
+	// Rate in MB/s.
+	Rate int `json:"rate"`
+}
+
+// Status is the autothrottle state written back to the ConfigMap.
+type Status struct {
+	// The time the spec was most recently applied without error.
+	Applied time.Time `json:"applied"`
+	// Error parsing or applying the spec, if any. The previously applied spec
+	// remains in effect.
+	Error              string                `json:"error,omitempty"`
+	Paused             bool                  `json:"paused"`
+	ReassigningTopics  []string              `json:"reassigningTopics"`
+	ReassigningBrokers []int                 `json:"reassigningBrokers"`
+	GuardrailsTripped  bool                  `json:"guardrailsTripped"`
+	Throttles          map[int][2]*float64   `json:"throttles"`
+	Sessions           []ReassignmentSession `json:"sessions,omitempty"`
+	DroppedEvents      uint64                `json:"droppedEvents,omitempty"`
+	Updated            time.Time             `json:"updated"`
+}
+
+// ReassignmentSession describes an active reassignment session.
+type ReassignmentSession struct {
+	ID      string    `json:"id"`
+	Started time.Time `json:"started"`
+	Topics  []string  `json:"topics"`
+}
+
+// ParseSpec takes ConfigMap data and returns the Spec it declares. Unset keys
+// are zero values.
+func ParseSpec(data map[string]string) (Spec, error) {
+	var s Spec
+
+	if v := strings.TrimSpace(data[KeyPaused]); v != "" {
+		paused, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("invalid %s value %q", KeyPaused, v)
+		}
+		s.Paused = paused
+	}
+
+	if v := strings.TrimSpace(data[KeyOverride]); v != "" {
+		var o Override
+		if err := json.Unmarshal([]byte(v), &o); err != nil {
+			return s, fmt.Errorf("invalid %s value: %s", KeyOverride, err)
+		}
+		if o.Rate < 0 {
+			return s, fmt.Errorf("invalid %s rate %d", KeyOverride, o.Rate)
+		}
+		if o.Rate > 0 {
+			s.Override = &o
+		}
+	}
+
+	if v := strings.TrimSpace(data[KeyBrokerOverrides]); v != "" {
+		var bo map[string]Override
+		if err := json.Unmarshal([]byte(v), &bo); err != nil {
+			return s, fmt.Errorf("invalid %s value: %s", KeyBrokerOverrides, err)
+		}
+
+		s.BrokerOverrides = make(map[int]Override, len(bo))
+		for k, o := range bo {
+			id, err := strconv.Atoi(k)
+			if err != nil {
+				return s, fmt.Errorf("invalid %s broker ID %q", KeyBrokerOverrides, k)
+			}
+			if o.Rate <= 0 {
+				return s, fmt.Errorf("invalid %s rate %d for broker %d", KeyBrokerOverrides, o.Rate, id)
+			}
+			s.BrokerOverrides[id] = o
+		}
+	}
+
+	if v := strings.TrimSpace(data[KeyCapacityMap]); v != "" {
+		if err := json.Unmarshal([]byte(v), &s.CapacityMap); err != nil {
+			return s, fmt.Errorf("invalid %s value: %s", KeyCapacityMap, err)
+		}
+		for k, c := range s.CapacityMap {
+			if c.TX <= 0 || c.RX <= 0 {
+				return s, fmt.Errorf("invalid %s capacity %v for %s", KeyCapacityMap, c, k)
+			}
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	s, err := ParseSpec(map[string]string{
+		KeyPaused:          "true",
+		KeyOverride:        `{"rate": 50}`,
+		KeyBrokerOverrides: `{"1001": {"rate": 20}}`,
+		KeyCapacityMap:     `{"d2.2xlarge": 118, "i3.2xlarge": {"tx": 220, "rx": 180}}`,
+		KeyStatus:          `{"paused": false}`,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Paused {
+		t.Error("Expected paused")
+	}
+
+	if s.Override == nil || s.Override.Rate != 50 {
+		t.Errorf("Unexpected override %v", s.Override)
+	}
+
+	if s.BrokerOverrides[1001].Rate != 20 {
+		t.Errorf("Unexpected broker overrides %v", s.BrokerOverrides)
+	}
+
+	if c := s.CapacityMap["d2.2xlarge"]; c.TX != 118 || c.RX != 118 {
+		t.Errorf("Unexpected capacity map %v", s.CapacityMap)
+	}
+
+	if c := s.CapacityMap["i3.2xlarge"]; c.TX != 220 || c.RX != 180 {
+		t.Errorf("Unexpected capacity map %v", s.CapacityMap)
+	}
+}
+
+func TestParseSpecEmpty(t *testing.T) {
+	s, err := ParseSpec(map[string]string{KeyOverride: `{"rate": 0}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Paused || s.Override != nil || s.BrokerOverrides != nil || s.CapacityMap != nil {
+		t.Errorf("Expected empty spec, got %+v", s)
+	}
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	tests := []map[string]string{
+		{KeyPaused: "maybe"},
+		{KeyOverride: `{"rate": -1}`},
+		{KeyOverride: `rate`},
+		{KeyBrokerOverrides: `{"a": {"rate": 20}}`},
+		{KeyBrokerOverrides: `{"1001": {"rate": 0}}`},
+		{KeyCapacityMap: `{"d2.2xlarge": 0}`},
+		{KeyCapacityMap: `{"d2.2xlarge": {"tx": 100, "rx": 0}}`},
+		{KeyCapacityMap: `[]`},
+	}
+
+	for i, data := range tests {
+		if _, err := ParseSpec(data); err == nil {
+			t.Errorf("[test %d] Expected non-nil error", i)
+		}
+	}
+}
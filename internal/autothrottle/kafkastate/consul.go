@@ -0,0 +1,145 @@
+package kafkastate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNoConsulAddress is returned when a ConsulLog is configured without an
+// address.
+var ErrNoConsulAddress = errors.New("a Consul address must be specified")
+
+// ConsulLogConfig holds ConsulLog configuration parameters.
+type ConsulLogConfig struct {
+	// The Consul HTTP API URL, e.g. http://localhost:8500.
+	Address string
+	// The optional ACL token sent with requests.
+	Token string
+	// The key prefix that state is stored beneath.
+	Prefix string
+	// The HTTP client used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// ConsulLog is a Log stored in the Consul KV store. Each Log key is stored as
+// a Consul key beneath the prefix; Consul keys don't begin with a '/'.
+type ConsulLog struct {
+	address string
+	token   string
+	prefix  string
+	http    *http.Client
+}
+
+// NewConsulLog takes a ConsulLogConfig and returns a *ConsulLog.
+func NewConsulLog(cfg ConsulLogConfig) (*ConsulLog, error) {
+	if cfg.Address == "" {
+		return nil, ErrNoConsulAddress
+	}
+
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	return &ConsulLog{
+		address: strings.TrimSuffix(cfg.Address, "/"),
+		token:   cfg.Token,
+		prefix:  strings.Trim(cfg.Prefix, "/"),
+		http:    hc,
+	}, nil
+}
+
+// Load returns the value of each key beneath the prefix.
+func (l *ConsulLog) Load(ctx context.Context) (map[string][]byte, error) {
+	r, err := l.do(ctx, http.MethodGet, l.key("/")+"?recurse=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	values := map[string][]byte{}
+
+	// No keys are set.
+	if r.StatusCode == http.StatusNotFound {
+		return values, nil
+	}
+
+	if err := checkConsulResponse(r); err != nil {
+		return nil, err
+	}
+
+	var kvs []struct {
+		Key   string
+		Value []byte
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&kvs); err != nil {
+		return nil, fmt.Errorf("error decoding Consul response: %s", err)
+	}
+
+	for _, kv := range kvs {
+		values["/"+strings.TrimPrefix(strings.TrimPrefix(kv.Key, l.prefix), "/")] = kv.Value
+	}
+
+	return values, nil
+}
+
+// Write writes the value of a key. A nil value deletes the key.
+func (l *ConsulLog) Write(ctx context.Context, k string, v []byte) error {
+	method := http.MethodPut
+	if v == nil {
+		method = http.MethodDelete
+	}
+
+	r, err := l.do(ctx, method, l.key(k), v)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	return checkConsulResponse(r)
+}
+
+// key returns the Consul key of the Log key k.
+func (l *ConsulLog) key(k string) string {
+	return strings.TrimPrefix(l.prefix+k, "/")
+}
+
+// Close is a no-op; ConsulLog holds no connections.
+func (l *ConsulLog) Close() {}
+
+// do makes a request to the KV API for the key k, which may include query
+// params.
+func (l *ConsulLog) do(ctx context.Context, method string, k string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, l.address+"/v1/kv/"+k, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if l.token != "" {
+		req.Header.Set("X-Consul-Token", l.token)
+	}
+
+	r, err := l.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Consul request failed: %s", err)
+	}
+
+	return r, nil
+}
+
+// checkConsulResponse returns an error if the response status isn't OK.
+func checkConsulResponse(r *http.Response) error {
+	if r.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	b, _ := io.ReadAll(io.LimitReader(r.Body, 512))
+	return fmt.Errorf("Consul request to %s returned %s: %s", r.Request.URL.Path, r.Status, strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,120 @@
+package kafkastate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConsul is a Consul KV API server holding keys in memory.
+type fakeConsul struct {
+	mu    sync.Mutex
+	kvs   map[string][]byte
+	token string
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("X-Consul-Token") != f.token {
+		http.Error(w, "Permission denied", http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+	switch r.Method {
+	case http.MethodGet:
+		type kv struct {
+			Key   string
+			Value []byte
+		}
+
+		var kvs []kv
+		for k, v := range f.kvs {
+			if strings.HasPrefix(k, key) {
+				kvs = append(kvs, kv{Key: k, Value: v})
+			}
+		}
+
+		if len(kvs) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		json.NewEncoder(w).Encode(kvs)
+	case http.MethodPut:
+		v, _ := io.ReadAll(r.Body)
+		f.kvs[key] = v
+		w.Write([]byte("true"))
+	case http.MethodDelete:
+		delete(f.kvs, key)
+		w.Write([]byte("true"))
+	}
+}
+
+func TestConsulLog(t *testing.T) {
+	if _, err := NewConsulLog(ConsulLogConfig{}); err != ErrNoConsulAddress {
+		t.Errorf("Expected error %s, got %v", ErrNoConsulAddress, err)
+	}
+
+	consul := &fakeConsul{kvs: map[string][]byte{}, token: "secret"}
+	srv := httptest.NewServer(consul)
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	// Requests without the token are rejected.
+	l, err := NewConsulLog(ConsulLogConfig{Address: srv.URL, Prefix: "kafka-kit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Write(ctx, "/autothrottle", []byte("{}")); err == nil {
+		t.Error("Expected an error")
+	}
+
+	l, err = NewConsulLog(ConsulLogConfig{Address: srv.URL, Token: "secret", Prefix: "/kafka-kit/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No keys are set.
+	values, err := l.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(values) != 0 {
+		t.Errorf("Expected no values, got %v", values)
+	}
+
+	for k, v := range map[string]string{"/autothrottle": "{}", "/autothrottle/pause": `{"paused":true}`} {
+		if err := l.Write(ctx, k, []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, exists := consul.kvs["kafka-kit/autothrottle/pause"]; !exists {
+		t.Errorf("Expected key kafka-kit/autothrottle/pause, got %v", consul.kvs)
+	}
+
+	if err := l.Write(ctx, "/autothrottle/pause", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err = l.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(values) != 1 || values["/autothrottle"] == nil {
+		t.Errorf("Expected only /autothrottle, got %v", values)
+	}
+}
@@ -0,0 +1,153 @@
+package kafkastate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNoEtcdEndpoints is returned when an EtcdLog is configured without any
+// endpoints.
+var ErrNoEtcdEndpoints = errors.New("at least one etcd endpoint must be specified")
+
+// EtcdLogConfig holds EtcdLog configuration parameters.
+type EtcdLogConfig struct {
+	// The etcd client URLs, e.g. http://etcd-0:2379. Requests are made to
+	// each in order until one succeeds.
+	Endpoints []string
+	// The key prefix that state is stored beneath.
+	Prefix string
+	// The HTTP client used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// EtcdLog is a Log stored in etcd, using the etcd v3 JSON API. Each Log key
+// is stored as an etcd key beneath the prefix.
+type EtcdLog struct {
+	endpoints []string
+	prefix    string
+	http      *http.Client
+}
+
+// etcdKV is an etcd key-value pair. Keys and values are base64 encoded in the
+// JSON API, as []byte fields are marshalled.
+type etcdKV struct {
+	Key      []byte `json:"key"`
+	Value    []byte `json:"value,omitempty"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+}
+
+// NewEtcdLog takes an EtcdLogConfig and returns an *EtcdLog.
+func NewEtcdLog(cfg EtcdLogConfig) (*EtcdLog, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, ErrNoEtcdEndpoints
+	}
+
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	var endpoints []string
+	for _, e := range cfg.Endpoints {
+		endpoints = append(endpoints, strings.TrimSuffix(e, "/"))
+	}
+
+	return &EtcdLog{
+		endpoints: endpoints,
+		prefix:    strings.TrimSuffix(cfg.Prefix, "/"),
+		http:      hc,
+	}, nil
+}
+
+// Load returns the value of each key beneath the prefix.
+func (l *EtcdLog) Load(ctx context.Context) (map[string][]byte, error) {
+	start := []byte(l.prefix + "/")
+	// The range end is the prefix with its last byte incremented, which
+	// covers all keys beginning with the prefix.
+	end := append([]byte{}, start...)
+	end[len(end)-1]++
+
+	var resp struct {
+		KVs []etcdKV `json:"kvs"`
+	}
+
+	if err := l.post(ctx, "/v3/kv/range", etcdKV{Key: start, RangeEnd: end}, &resp); err != nil {
+		return nil, err
+	}
+
+	values := map[string][]byte{}
+	for _, kv := range resp.KVs {
+		values[strings.TrimPrefix(string(kv.Key), l.prefix)] = kv.Value
+	}
+
+	return values, nil
+}
+
+// Write writes the value of a key. A nil value deletes the key.
+func (l *EtcdLog) Write(ctx context.Context, k string, v []byte) error {
+	key := []byte(l.prefix + k)
+
+	if v == nil {
+		return l.post(ctx, "/v3/kv/deleterange", etcdKV{Key: key}, nil)
+	}
+
+	return l.post(ctx, "/v3/kv/put", etcdKV{Key: key, Value: v}, nil)
+}
+
+// Close is a no-op; EtcdLog holds no connections.
+func (l *EtcdLog) Close() {}
+
+// post posts the JSON encoded request to the API path p of each endpoint in
+// order until one succeeds, decoding the response into resp if non-nil.
+func (l *EtcdLog) post(ctx context.Context, p string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range l.endpoints {
+		err = l.postEndpoint(ctx, e+p, body, resp)
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return err
+}
+
+// postEndpoint posts the body to the URL u, decoding the response into resp
+// if non-nil.
+func (l *EtcdLog) postEndpoint(ctx context.Context, u string, body []byte, resp interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := l.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("etcd request failed: %s", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(r.Body, 512))
+		return fmt.Errorf("etcd request to %s returned %s: %s", u, r.Status, strings.TrimSpace(string(b)))
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(resp); err != nil {
+		return fmt.Errorf("error decoding etcd response: %s", err)
+	}
+
+	return nil
+}
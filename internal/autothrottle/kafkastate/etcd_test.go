@@ -0,0 +1,104 @@
+package kafkastate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeEtcd is an etcd v3 JSON API server holding keys in memory.
+type fakeEtcd struct {
+	mu   sync.Mutex
+	kvs  map[string][]byte
+	fail bool
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req etcdKV
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var kvs []etcdKV
+		for k, v := range f.kvs {
+			if bytes.Compare([]byte(k), req.Key) >= 0 && bytes.Compare([]byte(k), req.RangeEnd) < 0 {
+				kvs = append(kvs, etcdKV{Key: []byte(k), Value: v})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"kvs": kvs})
+	case "/v3/kv/put":
+		f.kvs[string(req.Key)] = req.Value
+		w.Write([]byte("{}"))
+	case "/v3/kv/deleterange":
+		delete(f.kvs, string(req.Key))
+		w.Write([]byte("{}"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestEtcdLog(t *testing.T) {
+	if _, err := NewEtcdLog(EtcdLogConfig{}); err != ErrNoEtcdEndpoints {
+		t.Errorf("Expected error %s, got %v", ErrNoEtcdEndpoints, err)
+	}
+
+	etcd := &fakeEtcd{kvs: map[string][]byte{"other/key": []byte("x")}}
+	srv := httptest.NewServer(etcd)
+	defer srv.Close()
+
+	down := httptest.NewServer(&fakeEtcd{fail: true})
+	defer down.Close()
+
+	// Requests fall through to the next endpoint on failure.
+	l, err := NewEtcdLog(EtcdLogConfig{Endpoints: []string{down.URL, srv.URL + "/"}, Prefix: "kafka-kit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	for k, v := range map[string]string{"/autothrottle": "{}", "/autothrottle/pause": `{"paused":true}`} {
+		if err := l.Write(ctx, k, []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, exists := etcd.kvs["kafka-kit/autothrottle/pause"]; !exists {
+		t.Errorf("Expected key kafka-kit/autothrottle/pause, got %v", etcd.kvs)
+	}
+
+	if err := l.Write(ctx, "/autothrottle/pause", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys outside the prefix aren't loaded.
+	values, err := l.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(values) != 1 || values["/autothrottle"] == nil {
+		t.Errorf("Expected only /autothrottle, got %v", values)
+	}
+
+	// Errors are returned once all endpoints fail.
+	etcd.fail = true
+	if _, err := l.Load(ctx); err == nil {
+		t.Error("Expected an error")
+	}
+}
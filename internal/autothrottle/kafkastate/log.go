@@ -0,0 +1,186 @@
+package kafkastate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// Log is a keyed log that state is persisted to, where only the latest value
+// of each key is retained.
+type Log interface {
+	// Load returns the latest value of each key.
+	Load(context.Context) (map[string][]byte, error)
+	// Write writes the value of a key. A nil value deletes the key.
+	Write(context.Context, string, []byte) error
+	// Close closes the Log.
+	Close()
+}
+
+// KafkaLogConfig holds KafkaLog configuration parameters.
+type KafkaLogConfig struct {
+	// The topic. It's created as a single partition, compacted topic if it
+	// doesn't exist.
+	Topic string
+	// The replication factor the topic is created with. Defaults to 3.
+	ReplicationFactor int
+	// The Kafka client config.
+	Admin kafkaadmin.Config
+}
+
+// KafkaLog is a Log stored in a compacted Kafka topic.
+type KafkaLog struct {
+	topic     string
+	producer  *kafkaadmin.Producer
+	consumer  *kafkaadmin.Consumer
+	timeoutMs int
+}
+
+// NewKafkaLog takes a KafkaLogConfig and returns a *KafkaLog, creating the
+// topic if it doesn't exist.
+func NewKafkaLog(ctx context.Context, cfg KafkaLogConfig) (*KafkaLog, error) {
+	if cfg.ReplicationFactor == 0 {
+		cfg.ReplicationFactor = 3
+	}
+
+	ka, err := kafkaadmin.NewClient(cfg.Admin)
+	if err != nil {
+		return nil, err
+	}
+	defer ka.Close()
+
+	// Topics that already exist are left as is.
+	_, err = ka.CreateTopicIfNotExists(ctx, kafkaadmin.CreateTopicConfig{
+		Name:              cfg.Topic,
+		Partitions:        1,
+		ReplicationFactor: cfg.ReplicationFactor,
+		Config:            map[string]string{"cleanup.policy": "compact"},
+	}, kafkaadmin.TopicExistsIgnore)
+	if err != nil {
+		return nil, fmt.Errorf("error creating topic %s: %s", cfg.Topic, err)
+	}
+
+	producer, err := kafkaadmin.NewProducer(cfg.Admin)
+	if err != nil {
+		return nil, err
+	}
+
+	// The consumer is manually assigned partitions and never commits offsets,
+	// but librdkafka requires a group ID.
+	if cfg.Admin.GroupId == "" {
+		cfg.Admin.GroupId = "autothrottle-state"
+	}
+
+	consumer, err := kafkaadmin.NewConsumer(cfg.Admin)
+	if err != nil {
+		producer.Close()
+		return nil, err
+	}
+
+	timeoutMs := cfg.Admin.DefaultTimeoutMs
+	if timeoutMs == 0 {
+		timeoutMs = 5000
+	}
+
+	return &KafkaLog{
+		topic:     cfg.Topic,
+		producer:  producer,
+		consumer:  consumer,
+		timeoutMs: timeoutMs,
+	}, nil
+}
+
+// Load reads the topic from the beginning through to its current end and
+// returns the latest value of each key.
+func (l *KafkaLog) Load(ctx context.Context) (map[string][]byte, error) {
+	md, err := l.consumer.GetMetadata(&l.topic, false, l.timeoutMs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The offset each partition must be read through.
+	ends := map[int32]int64{}
+	var assignment []kafka.TopicPartition
+
+	for _, p := range md.Topics[l.topic].Partitions {
+		low, high, err := l.consumer.QueryWatermarkOffsets(l.topic, p.ID, l.timeoutMs)
+		if err != nil {
+			return nil, err
+		}
+
+		if high > low {
+			ends[p.ID] = high - 1
+			assignment = append(assignment, kafka.TopicPartition{Topic: &l.topic, Partition: p.ID, Offset: kafka.OffsetBeginning})
+		}
+	}
+
+	values := map[string][]byte{}
+	if len(assignment) == 0 {
+		return values, nil
+	}
+
+	if err := l.consumer.Assign(assignment); err != nil {
+		return nil, err
+	}
+	defer l.consumer.Unassign()
+
+	for len(ends) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		switch e := l.consumer.Poll(100).(type) {
+		case *kafka.Message:
+			if e.Value == nil {
+				delete(values, string(e.Key))
+			} else {
+				values[string(e.Key)] = e.Value
+			}
+
+			p := e.TopicPartition
+			if end, exists := ends[p.Partition]; exists && int64(p.Offset) >= end {
+				delete(ends, p.Partition)
+			}
+		case kafka.Error:
+			return nil, e
+		}
+	}
+
+	return values, nil
+}
+
+// Write writes the value of a key and waits for it to be acknowledged.
+func (l *KafkaLog) Write(ctx context.Context, key string, value []byte) error {
+	deliveries := make(chan kafka.Event, 1)
+
+	err := l.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &l.topic, Partition: kafka.PartitionAny},
+		Key:            []byte(key),
+		Value:          value,
+		Timestamp:      time.Now(),
+	}, deliveries)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case e := <-deliveries:
+		if m, ok := e.(*kafka.Message); ok {
+			return m.TopicPartition.Error
+		}
+		return fmt.Errorf("unexpected delivery event: %s", e)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the KafkaLog.
+func (l *KafkaLog) Close() {
+	l.producer.Flush(l.timeoutMs)
+	l.producer.Close()
+	l.consumer.Close()
+}
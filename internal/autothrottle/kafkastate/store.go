@@ -0,0 +1,332 @@
+// Package kafkastate stores autothrottle state in a Log, such as a compacted
+// Kafka topic, etcd or the Consul KV store, rather than ZooKeeper. This allows
+// autothrottle to run without write access to ZooKeeper, e.g. against managed
+// Kafka services, or in environments standardizing tool state elsewhere.
+package kafkastate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+var (
+	// ErrACLUnsupported is returned when setting znode ACLs on state paths.
+	ErrACLUnsupported = errors.New("ACLs aren't supported for state stored outside ZooKeeper")
+	// ErrUnsupported is returned for unsupported operations on state paths.
+	ErrUnsupported = errors.New("operation isn't supported for state stored outside ZooKeeper")
+)
+
+// writeTimeout bounds each Log write.
+const writeTimeout = 10 * time.Second
+
+// Store is a kafkazk.Handler that holds the znodes beneath a path prefix, the
+// autothrottle state, in a Log. All other calls, e.g. for cluster metadata, are
+// passed to the wrapped Handler. Store assumes it's the Log's only writer; the
+// state is read from the Log once, when the Store is created. Closing the Store
+// closes the wrapped Handler but not the Log.
+type Store struct {
+	kafkazk.Handler
+	prefix string
+	log    Log
+
+	mu    sync.RWMutex
+	nodes map[string]string
+}
+
+// record is the Log value of a znode. Values are wrapped so that an empty
+// znode is distinguishable from a deleted one.
+type record struct {
+	Data string `json:"data"`
+}
+
+// NewStore takes a kafkazk.Handler, the path prefix that state is held beneath
+// (e.g. /autothrottle) and a Log, and returns a *Store populated with the
+// state in the Log.
+func NewStore(ctx context.Context, h kafkazk.Handler, prefix string, l Log) (*Store, error) {
+	values, err := l.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading state: %s", err)
+	}
+
+	s := &Store{
+		Handler: h,
+		prefix:  path.Clean(prefix),
+		log:     l,
+		nodes:   map[string]string{},
+	}
+
+	for p, v := range values {
+		var r record
+		if err := json.Unmarshal(v, &r); err != nil {
+			return nil, fmt.Errorf("error unmarshalling state for %s: %s", p, err)
+		}
+		s.nodes[p] = r.Data
+	}
+
+	return s, nil
+}
+
+// owns returns whether the path p is held by the Store.
+func (s *Store) owns(p string) bool {
+	return p == s.prefix || strings.HasPrefix(p, s.prefix+"/")
+}
+
+// write writes the data of the znode at path p, or deletes it if data is nil.
+// The caller must hold the write lock.
+func (s *Store) write(p string, data *string) error {
+	var v []byte
+	if data != nil {
+		v, _ = json.Marshal(record{Data: *data})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if err := s.log.Write(ctx, p, v); err != nil {
+		return fmt.Errorf("error writing state for %s: %s", p, err)
+	}
+
+	if data == nil {
+		delete(s.nodes, p)
+	} else {
+		s.nodes[p] = *data
+	}
+
+	return nil
+}
+
+// Exists returns whether the znode at path p exists.
+func (s *Store) Exists(p string) (bool, error) {
+	if !s.owns(p) {
+		return s.Handler.Exists(p)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.nodes[p]
+	return exists, nil
+}
+
+// Create creates a znode at path p with the data d. The parent znode must
+// exist, unless it's outside of the Store.
+func (s *Store) Create(p string, d string) error {
+	if !s.owns(p) {
+		return s.Handler.Create(p, d)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.nodes[p]; exists {
+		return fmt.Errorf("[%s] node already exists", p)
+	}
+
+	if parent := path.Dir(p); s.owns(parent) {
+		if _, exists := s.nodes[parent]; !exists {
+			return kafkazk.NewErrNoNode(parent)
+		}
+	}
+
+	return s.write(p, &d)
+}
+
+// CreateWithACL creates a znode at path p with the data d. ACLs don't apply to
+// the Store and are ignored.
+func (s *Store) CreateWithACL(p string, d string, acl []kafkazk.ACL) error {
+	if !s.owns(p) {
+		return s.Handler.CreateWithACL(p, d, acl)
+	}
+
+	return s.Create(p, d)
+}
+
+// CreateSequential is unsupported for paths held by the Store.
+func (s *Store) CreateSequential(p string, d string) error {
+	if !s.owns(p) {
+		return s.Handler.CreateSequential(p, d)
+	}
+
+	return ErrUnsupported
+}
+
+// Set sets the data of the znode at path p to d.
+func (s *Store) Set(p string, d string) error {
+	if !s.owns(p) {
+		return s.Handler.Set(p, d)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.nodes[p]; !exists {
+		return kafkazk.NewErrNoNode(p)
+	}
+
+	return s.write(p, &d)
+}
+
+// Get returns the data of the znode at path p.
+func (s *Store) Get(p string) ([]byte, error) {
+	if !s.owns(p) {
+		return s.Handler.Get(p)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, exists := s.nodes[p]
+	if !exists {
+		return nil, kafkazk.NewErrNoNode(p)
+	}
+
+	return []byte(d), nil
+}
+
+// GetWithVersion is unsupported for paths held by the Store.
+func (s *Store) GetWithVersion(p string) ([]byte, int32, error) {
+	if !s.owns(p) {
+		return s.Handler.GetWithVersion(p)
+	}
+
+	return nil, 0, ErrUnsupported
+}
+
+// SetWithVersion is unsupported for paths held by the Store.
+func (s *Store) SetWithVersion(p string, d string, v int32) error {
+	if !s.owns(p) {
+		return s.Handler.SetWithVersion(p, d, v)
+	}
+
+	return ErrUnsupported
+}
+
+// DeleteWithVersion is unsupported for paths held by the Store.
+func (s *Store) DeleteWithVersion(p string, v int32) error {
+	if !s.owns(p) {
+		return s.Handler.DeleteWithVersion(p, v)
+	}
+
+	return ErrUnsupported
+}
+
+// GetACL returns an empty ACL for znodes held by the Store.
+func (s *Store) GetACL(p string) ([]kafkazk.ACL, error) {
+	if !s.owns(p) {
+		return s.Handler.GetACL(p)
+	}
+
+	if exists, _ := s.Exists(p); !exists {
+		return nil, kafkazk.NewErrNoNode(p)
+	}
+
+	return nil, nil
+}
+
+// SetACL is unsupported for paths held by the Store.
+func (s *Store) SetACL(p string, acl []kafkazk.ACL) error {
+	if !s.owns(p) {
+		return s.Handler.SetACL(p, acl)
+	}
+
+	return ErrACLUnsupported
+}
+
+// Delete deletes the znode at path p. Znodes with children can't be deleted.
+func (s *Store) Delete(p string) error {
+	if !s.owns(p) {
+		return s.Handler.Delete(p)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.nodes[p]; !exists {
+		return kafkazk.NewErrNoNode(p)
+	}
+
+	if len(s.children(p)) > 0 {
+		return fmt.Errorf("[%s] node has children", p)
+	}
+
+	return s.write(p, nil)
+}
+
+// Children returns the names of the children of the znode at path p.
+func (s *Store) Children(p string) ([]string, error) {
+	if !s.owns(p) {
+		return s.Handler.Children(p)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.nodes[p]; !exists {
+		return nil, kafkazk.NewErrNoNode(p)
+	}
+
+	return s.children(p), nil
+}
+
+// children returns the sorted names of the children of the znode at path p.
+// The caller must hold the lock.
+func (s *Store) children(p string) []string {
+	var children []string
+
+	for n := range s.nodes {
+		if path.Dir(n) == p {
+			children = append(children, path.Base(n))
+		}
+	}
+
+	sort.Strings(children)
+
+	return children
+}
+
+// NextInt is unsupported for paths held by the Store.
+func (s *Store) NextInt(p string) (int32, error) {
+	if !s.owns(p) {
+		return s.Handler.NextInt(p)
+	}
+
+	return 0, ErrUnsupported
+}
+
+// RecursiveDelete deletes the znode at path p and all of its descendants.
+func (s *Store) RecursiveDelete(p string) error {
+	if !s.owns(p) {
+		return s.Handler.RecursiveDelete(p)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Delete descendants first so that an interrupted delete doesn't leave
+	// orphaned znodes.
+	var paths []string
+	for n := range s.nodes {
+		if n == p || strings.HasPrefix(n, p+"/") {
+			paths = append(paths, n)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+
+	for _, n := range paths {
+		if err := s.write(n, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
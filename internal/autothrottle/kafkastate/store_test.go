@@ -0,0 +1,173 @@
+package kafkastate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// memLog is an in-memory Log.
+type memLog struct {
+	values map[string][]byte
+	err    error
+}
+
+func newMemLog() *memLog {
+	return &memLog{values: map[string][]byte{}}
+}
+
+func (l *memLog) Load(context.Context) (map[string][]byte, error) {
+	values := map[string][]byte{}
+	for k, v := range l.values {
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+func (l *memLog) Write(_ context.Context, k string, v []byte) error {
+	if l.err != nil {
+		return l.err
+	}
+
+	if v == nil {
+		delete(l.values, k)
+	} else {
+		l.values[k] = v
+	}
+
+	return nil
+}
+
+func (l *memLog) Close() {}
+
+func newTestStore(t *testing.T, l Log) (*Store, *kafkazk.Stub) {
+	zk := kafkazk.NewZooKeeperStub()
+
+	s, err := NewStore(context.Background(), zk, "/autothrottle", l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, zk
+}
+
+func TestStore(t *testing.T) {
+	l := newMemLog()
+	s, zk := newTestStore(t, l)
+
+	if err := s.Create("/autothrottle/override_rate", ""); err == nil {
+		t.Error("Expected an error creating a znode without a parent")
+	}
+
+	for _, p := range []string{"/autothrottle", "/autothrottle/override_rate", "/autothrottle/override_rate/1001"} {
+		if err := s.Create(p, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Create("/autothrottle", ""); err == nil {
+		t.Error("Expected an error creating an existing znode")
+	}
+
+	if err := s.Set("/autothrottle/override_rate/1001", `{"rate":10}`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Get("/autothrottle/paused"); !errors.As(err, &kafkazk.ErrNoNode{}) {
+		t.Errorf("Expected ErrNoNode, got %v", err)
+	}
+
+	if err := s.Delete("/autothrottle/override_rate"); err == nil {
+		t.Error("Expected an error deleting a znode with children")
+	}
+
+	// State is loaded from the Log.
+	s, zk = newTestStore(t, l)
+
+	d, err := s.Get("/autothrottle/override_rate/1001")
+	if err != nil || string(d) != `{"rate":10}` {
+		t.Errorf("Unexpected data %q, err %v", d, err)
+	}
+
+	children, _ := s.Children("/autothrottle")
+	if len(children) != 1 || children[0] != "override_rate" {
+		t.Errorf("Unexpected children %v", children)
+	}
+
+	if err := s.RecursiveDelete("/autothrottle/override_rate"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(l.values) != 1 {
+		t.Errorf("Expected only /autothrottle in the Log, got %v", l.values)
+	}
+
+	// Paths outside of the prefix are passed to the wrapped Handler.
+	if err := s.Create("/kafka/config", "{}"); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, _ := zk.Exists("/kafka/config"); !exists {
+		t.Error("Expected /kafka/config in the wrapped Handler")
+	}
+
+	if _, exists := l.values["/kafka/config"]; exists {
+		t.Error("Unexpected /kafka/config in the Log")
+	}
+}
+
+func TestStoreWriteError(t *testing.T) {
+	l := newMemLog()
+	s, _ := newTestStore(t, l)
+
+	l.err = errors.New("broker unavailable")
+
+	if err := s.Create("/autothrottle", ""); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	// Failed writes aren't applied.
+	if exists, _ := s.Exists("/autothrottle"); exists {
+		t.Error("Expected /autothrottle to not exist")
+	}
+}
+
+func TestStoreState(t *testing.T) {
+	l := newMemLog()
+	s, zk := newTestStore(t, l)
+
+	paths := api.NewZnodePaths("autothrottle")
+	if err := api.InitZnodes(s, paths, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := throttlestore.SetRequesterOverride(s, paths.OverrideRate, "", throttlestore.ThrottleOverrideConfig{Rate: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := throttlestore.StorePauseConfig(s, paths.Pause, throttlestore.PauseConfig{Paused: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is stored in ZooKeeper.
+	if exists, _ := zk.Exists("/autothrottle"); exists {
+		t.Error("Unexpected state in ZooKeeper")
+	}
+
+	s, _ = newTestStore(t, l)
+
+	c, err := throttlestore.FetchThrottleOverride(s, paths.OverrideRate)
+	if err != nil || c.Rate != 50 {
+		t.Errorf("Unexpected override %+v, err %v", c, err)
+	}
+
+	p, err := throttlestore.FetchPauseConfig(s, paths.Pause)
+	if err != nil || !p.Paused {
+		t.Errorf("Unexpected pause config %+v, err %v", p, err)
+	}
+}
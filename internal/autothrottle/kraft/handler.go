@@ -0,0 +1,515 @@
+// Package kraft provides a kafkazk.Handler that reads cluster metadata through
+// the Kafka Admin API rather than ZooKeeper. This allows autothrottle to run
+// against KRaft clusters, where ZooKeeper isn't available. Autothrottle state
+// must be stored elsewhere, e.g. with a kafkastate.Store wrapping the Handler.
+package kraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+var (
+	// ErrNoMetadataSource is returned when cluster metadata can't be read
+	// through the Kafka Admin API.
+	ErrNoMetadataSource = errors.New("no cluster metadata source: ZooKeeper isn't configured and the Kafka Admin API is unavailable")
+	// ErrUnsupported is returned for operations that require ZooKeeper.
+	ErrUnsupported = errors.New("operation isn't supported without ZooKeeper")
+)
+
+// DefaultRequestTimeout is the Admin API request timeout used if unset.
+const DefaultRequestTimeout = 10 * time.Second
+
+// Features describes the cluster features detected through the Admin API.
+type Features struct {
+	// Whether the brokers run in KRaft mode, i.e. process.roles is set.
+	KRaft bool
+	// The number of live brokers.
+	Brokers int
+}
+
+// Handler is a kafkazk.Handler that reads cluster metadata through the Kafka
+// Admin API. Znodes are reported as nonexistent; writing znodes or Kafka
+// configs and managing reassignments return ErrUnsupported.
+//
+// The client autothrottle is built against doesn't support listing partition
+// reassignments through the Admin API, so reassignments are inferred from
+// topic metadata; see InferReassignments.
+type Handler struct {
+	ka       kafkaadmin.KafkaAdmin
+	timeout  time.Duration
+	features Features
+}
+
+// NewHandler takes a KafkaAdmin and a request timeout and returns a *Handler.
+// Cluster features are detected at creation; ErrNoMetadataSource is returned
+// if the cluster can't be described.
+func NewHandler(ctx context.Context, ka kafkaadmin.KafkaAdmin, timeout time.Duration) (*Handler, error) {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+
+	h := &Handler{ka: ka, timeout: timeout}
+
+	features, err := h.detect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrNoMetadataSource, err)
+	}
+
+	h.features = features
+
+	return h, nil
+}
+
+// detect describes the live brokers and returns the detected Features.
+func (h *Handler) detect(ctx context.Context) (Features, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	brokers, err := h.ka.DescribeBrokers(ctx, true)
+	if err != nil {
+		return Features{}, err
+	}
+
+	if len(brokers) == 0 {
+		return Features{}, errors.New("no live brokers")
+	}
+
+	features := Features{Brokers: len(brokers)}
+	for _, b := range brokers {
+		if b.FullData["process.roles"] != "" {
+			features.KRaft = true
+			break
+		}
+	}
+
+	return features, nil
+}
+
+// Features returns the Features detected when the Handler was created.
+func (h *Handler) Features() Features {
+	return h.features
+}
+
+// context returns a context with the request timeout applied.
+func (h *Handler) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), h.timeout)
+}
+
+// describeTopics returns the TopicStates for topics matching any of the
+// regex, or an empty TopicStates if none match.
+func (h *Handler) describeTopics(topics []string) (kafkaadmin.TopicStates, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	states, err := h.ka.DescribeTopics(ctx, topics)
+	switch err {
+	case nil:
+		return states, nil
+	case kafkaadmin.ErrNoData:
+		return kafkaadmin.NewTopicStates(), nil
+	default:
+		return nil, err
+	}
+}
+
+// describeTopic returns the TopicState for the topic t.
+func (h *Handler) describeTopic(t string) (kafkaadmin.TopicState, error) {
+	states, err := h.describeTopics([]string{regexp.QuoteMeta(t)})
+	if err != nil {
+		return kafkaadmin.TopicState{}, err
+	}
+
+	state, exists := states[t]
+	if !exists {
+		return state, fmt.Errorf("topic %s not found", t)
+	}
+
+	return state, nil
+}
+
+// Close closes the KafkaAdmin client.
+func (h *Handler) Close() {
+	h.ka.Close()
+}
+
+// Ready returns true; the Admin API client reconnects on its own.
+func (h *Handler) Ready() bool {
+	return true
+}
+
+// Fatal returns a nil channel; the Handler doesn't fail fatally.
+func (h *Handler) Fatal() <-chan error {
+	return nil
+}
+
+// Exists returns false for all znodes.
+func (h *Handler) Exists(string) (bool, error) {
+	return false, nil
+}
+
+// Get returns an ErrNoNode for all znodes.
+func (h *Handler) Get(p string) ([]byte, error) {
+	return nil, kafkazk.NewErrNoNode(p)
+}
+
+// GetWithVersion returns an ErrNoNode for all znodes.
+func (h *Handler) GetWithVersion(p string) ([]byte, int32, error) {
+	return nil, 0, kafkazk.NewErrNoNode(p)
+}
+
+// GetACL returns an ErrNoNode for all znodes.
+func (h *Handler) GetACL(p string) ([]kafkazk.ACL, error) {
+	return nil, kafkazk.NewErrNoNode(p)
+}
+
+// Children returns an ErrNoNode for all znodes.
+func (h *Handler) Children(p string) ([]string, error) {
+	return nil, kafkazk.NewErrNoNode(p)
+}
+
+// Znode writes return ErrUnsupported.
+
+func (h *Handler) Create(string, string) error                       { return ErrUnsupported }
+func (h *Handler) CreateWithACL(string, string, []kafkazk.ACL) error { return ErrUnsupported }
+func (h *Handler) CreateSequential(string, string) error             { return ErrUnsupported }
+func (h *Handler) Set(string, string) error                          { return ErrUnsupported }
+func (h *Handler) SetWithVersion(string, string, int32) error        { return ErrUnsupported }
+func (h *Handler) SetACL(string, []kafkazk.ACL) error                { return ErrUnsupported }
+func (h *Handler) Delete(string) error                               { return ErrUnsupported }
+func (h *Handler) DeleteWithVersion(string, int32) error             { return ErrUnsupported }
+func (h *Handler) NextInt(string) (int32, error)                     { return 0, ErrUnsupported }
+func (h *Handler) RecursiveDelete(string) error                      { return ErrUnsupported }
+func (h *Handler) ExportTree(string) (*kafkazk.Znode, error)         { return nil, ErrUnsupported }
+
+// UpdateKafkaConfig returns ErrUnsupported; configs are written through the
+// Admin API in Kafka native mode.
+func (h *Handler) UpdateKafkaConfig(kafkazk.KafkaConfig) ([]bool, error) {
+	return nil, ErrUnsupported
+}
+
+// GetBrokerMetrics returns ErrUnsupported; broker metrics are stored in
+// ZooKeeper by metricsfetcher.
+func (h *Handler) GetBrokerMetrics() (mapper.BrokerMetricsMap, error) {
+	return nil, ErrUnsupported
+}
+
+// GetAllPartitionMeta returns ErrUnsupported; partition metadata is stored in
+// ZooKeeper by metricsfetcher.
+func (h *Handler) GetAllPartitionMeta() (mapper.PartitionMetaMap, error) {
+	return nil, ErrUnsupported
+}
+
+// MaxMetaAge returns ErrUnsupported; see GetBrokerMetrics.
+func (h *Handler) MaxMetaAge() (time.Duration, error) {
+	return 0, ErrUnsupported
+}
+
+// SubmitReassignment returns ErrUnsupported.
+func (h *Handler) SubmitReassignment(*mapper.PartitionMap) error {
+	return ErrUnsupported
+}
+
+// CancelReassignments returns ErrUnsupported.
+func (h *Handler) CancelReassignments([]string) (kafkazk.Reassignments, error) {
+	return nil, ErrUnsupported
+}
+
+// GetReassignments returns the inferred ongoing reassignments, or an empty
+// Reassignments if the topics can't be described.
+func (h *Handler) GetReassignments() kafkazk.Reassignments {
+	r, err := h.ListReassignments()
+	if err != nil {
+		return kafkazk.Reassignments{}
+	}
+
+	return r
+}
+
+// ListReassignments returns the ongoing reassignments inferred from the
+// metadata of all topics.
+func (h *Handler) ListReassignments() (kafkazk.Reassignments, error) {
+	states, err := h.describeTopics([]string{".*"})
+	if err != nil {
+		return nil, err
+	}
+
+	return InferReassignments(states), nil
+}
+
+// GetPendingDeletion returns an empty list; topic deletions aren't staged
+// through the Admin API.
+func (h *Handler) GetPendingDeletion() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetUnderReplicated returns a []string of all under-replicated topics.
+func (h *Handler) GetUnderReplicated() ([]string, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	states, err := h.ka.UnderReplicatedTopics(ctx)
+	switch err {
+	case nil:
+	case kafkaadmin.ErrNoData:
+		return []string{}, nil
+	default:
+		return nil, err
+	}
+
+	topics := states.List()
+	sort.Strings(topics)
+
+	return topics, nil
+}
+
+// GetTopics takes a []*regexp.Regexp and returns a []string of all topic names
+// that match any of the provided regex.
+func (h *Handler) GetTopics(ts []*regexp.Regexp) ([]string, error) {
+	var patterns []string
+	for _, re := range ts {
+		patterns = append(patterns, re.String())
+	}
+
+	states, err := h.describeTopics(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := states.List()
+	sort.Strings(topics)
+
+	return topics, nil
+}
+
+// ListTopics returns the states of the topics matching any of the provided
+// regex in chunks of up to size topics. The channel is closed once all topics
+// have been sent, an error is sent or the context is cancelled.
+func (h *Handler) ListTopics(ctx context.Context, ts []*regexp.Regexp, size int) <-chan kafkazk.TopicChunk {
+	chunks := make(chan kafkazk.TopicChunk)
+
+	go func() {
+		defer close(chunks)
+
+		send := func(c kafkazk.TopicChunk) bool {
+			select {
+			case chunks <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		topics, err := h.GetTopics(ts)
+		if err != nil {
+			send(kafkazk.TopicChunk{Err: err})
+			return
+		}
+
+		if size <= 0 {
+			size = len(topics)
+		}
+
+		for len(topics) > 0 {
+			n := size
+			if n > len(topics) {
+				n = len(topics)
+			}
+
+			chunk := kafkazk.TopicChunk{States: map[string]*mapper.TopicState{}}
+			for _, t := range topics[:n] {
+				state, err := h.GetTopicState(t)
+				if err != nil {
+					send(kafkazk.TopicChunk{Err: err})
+					return
+				}
+				chunk.States[t] = state
+			}
+
+			if !send(chunk) {
+				return
+			}
+
+			topics = topics[n:]
+		}
+	}()
+
+	return chunks
+}
+
+// GetTopicState takes a topic name. If the topic exists, the topic state is
+// returned as a *mapper.TopicState.
+func (h *Handler) GetTopicState(t string) (*mapper.TopicState, error) {
+	state, err := h.describeTopic(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &mapper.TopicState{Partitions: map[string][]int{}}
+	for id, partn := range state.PartitionStates {
+		ts.Partitions[strconv.Itoa(id)] = int32sToInts(partn.Replicas)
+	}
+
+	return ts, nil
+}
+
+// GetTopicStateISR takes a topic name. If the topic exists, the leader and ISR
+// of each partition is returned as a TopicStateISR.
+func (h *Handler) GetTopicStateISR(t string) (kafkazk.TopicStateISR, error) {
+	state, err := h.describeTopic(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := kafkazk.TopicStateISR{}
+	for id, partn := range state.PartitionStates {
+		ts[strconv.Itoa(id)] = kafkazk.PartitionState{
+			Leader: int(partn.Leader),
+			ISR:    int32sToInts(partn.ISR),
+		}
+	}
+
+	return ts, nil
+}
+
+// GetTopicMetadata takes a topic name. If the topic exists, the topic metadata
+// is returned as a TopicMetadata. The adding and removing replicas are those
+// of any inferred reassignments.
+func (h *Handler) GetTopicMetadata(t string) (kafkazk.TopicMetadata, error) {
+	state, err := h.describeTopic(t)
+	if err != nil {
+		return kafkazk.TopicMetadata{}, err
+	}
+
+	md := kafkazk.TopicMetadata{
+		Version:          3,
+		Name:             t,
+		Partitions:       map[int][]int{},
+		AddingReplicas:   map[int][]int{},
+		RemovingReplicas: map[int][]int{},
+	}
+
+	rf := replicationFactor(state)
+
+	for id, partn := range state.PartitionStates {
+		md.Partitions[id] = int32sToInts(partn.Replicas)
+
+		if target, removing, reassigning := inferTarget(partn, rf); reassigning {
+			md.RemovingReplicas[id] = removing
+			if adding := outOfSync(target, partn.ISR); len(adding) > 0 {
+				md.AddingReplicas[id] = adding
+			}
+		}
+	}
+
+	return md, nil
+}
+
+// GetPartitionMap takes a topic name. If the topic exists, the state of the
+// topic is fetched and returned as a *PartitionMap.
+func (h *Handler) GetPartitionMap(t string) (*mapper.PartitionMap, error) {
+	state, err := h.describeTopic(t)
+	if err != nil {
+		return nil, err
+	}
+
+	pm := mapper.NewPartitionMap()
+	for id, partn := range state.PartitionStates {
+		pm.Partitions = append(pm.Partitions, mapper.Partition{
+			Topic:     t,
+			Partition: id,
+			Replicas:  int32sToInts(partn.Replicas),
+		})
+	}
+
+	sort.Sort(pm.Partitions)
+
+	return pm, nil
+}
+
+// GetTopicConfig takes a topic name and returns its dynamic configs as a
+// *TopicConfig.
+func (h *Handler) GetTopicConfig(t string) (*kafkazk.TopicConfig, error) {
+	config, err := h.dynamicConfigs("topic", t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafkazk.TopicConfig{Version: 1, Config: config}, nil
+}
+
+// GetBrokerConfig takes a broker ID and returns its dynamic configs as a
+// *BrokerConfig.
+func (h *Handler) GetBrokerConfig(id int) (*kafkazk.BrokerConfig, error) {
+	config, err := h.dynamicConfigs("broker", strconv.Itoa(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafkazk.BrokerConfig{Version: 1, Config: config}, nil
+}
+
+// dynamicConfigs returns the dynamic configs of the named resource.
+func (h *Handler) dynamicConfigs(kind, name string) (map[string]string, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	configs, err := h.ka.GetDynamicConfigs(ctx, kind, []string{name})
+	if err != nil {
+		return nil, err
+	}
+
+	if configs[name] == nil {
+		return map[string]string{}, nil
+	}
+
+	return configs[name], nil
+}
+
+// GetAllBrokerMeta returns the metadata of all live brokers as a
+// mapper.BrokerMetaMap. Broker metrics are stored in ZooKeeper and can't be
+// included; ErrUnsupported is returned if withMetrics is set.
+func (h *Handler) GetAllBrokerMeta(withMetrics bool) (mapper.BrokerMetaMap, []error) {
+	if withMetrics {
+		return nil, []error{ErrUnsupported}
+	}
+
+	ctx, cancel := h.context()
+	defer cancel()
+
+	states, err := h.ka.DescribeBrokers(ctx, false)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	bmm := mapper.BrokerMetaMap{}
+	for id, s := range states {
+		bmm[id] = &mapper.BrokerMeta{
+			Host:                       s.Host,
+			Port:                       s.Port,
+			Rack:                       s.Rack,
+			LogMessageFormat:           s.LogMessageFormat,
+			InterBrokerProtocolVersion: s.InterBrokerProtocolVersion,
+			Endpoints:                  s.Endpoints,
+		}
+	}
+
+	return bmm, nil
+}
+
+func int32sToInts(s []int32) []int {
+	ints := make([]int, len(s))
+	for i, v := range s {
+		ints[i] = int(v)
+	}
+	return ints
+}
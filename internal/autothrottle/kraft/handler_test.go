@@ -0,0 +1,197 @@
+package kraft
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin/stub"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	h, err := NewHandler(context.Background(), stub.NewClient(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestNewHandler(t *testing.T) {
+	h := newTestHandler(t)
+
+	// The stub brokers have no process.roles config.
+	if f := h.Features(); f != (Features{KRaft: false, Brokers: 6}) {
+		t.Errorf("Unexpected features %+v", f)
+	}
+}
+
+func TestGetAllBrokerMeta(t *testing.T) {
+	h := newTestHandler(t)
+
+	bmm, errs := h.GetAllBrokerMeta(false)
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	if len(bmm) != 6 {
+		t.Errorf("Expected 6 brokers, got %d", len(bmm))
+	}
+
+	if bmm[1001].Host != "1001" || bmm[1001].Rack != "a" {
+		t.Errorf("Unexpected broker 1001 meta %+v", bmm[1001])
+	}
+
+	// Broker metrics are stored in ZooKeeper.
+	_, errs = h.GetAllBrokerMeta(true)
+	if len(errs) != 1 || errs[0] != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", errs)
+	}
+}
+
+func TestGetTopics(t *testing.T) {
+	h := newTestHandler(t)
+
+	topics, err := h.GetTopics([]*regexp.Regexp{regexp.MustCompile("test.*")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(topics, []string{"test1", "test2"}) {
+		t.Errorf("Expected topics [test1 test2], got %v", topics)
+	}
+
+	topics, err = h.GetTopics([]*regexp.Regexp{regexp.MustCompile("nonexistent")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(topics) != 0 {
+		t.Errorf("Expected no topics, got %v", topics)
+	}
+}
+
+func TestGetTopicState(t *testing.T) {
+	h := newTestHandler(t)
+
+	state, err := h.GetTopicState("test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]int{"0": {1001, 1002}, "1": {1002}}
+	if !reflect.DeepEqual(state.Partitions, expected) {
+		t.Errorf("Expected partitions %v, got %v", expected, state.Partitions)
+	}
+
+	isr, err := h.GetTopicStateISR("test2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedISR := kafkazk.PartitionState{Leader: 1003, ISR: []int{1003, 1002}}
+	if !reflect.DeepEqual(isr["0"], expectedISR) {
+		t.Errorf("Expected partition state %v, got %v", expectedISR, isr["0"])
+	}
+
+	if _, err := h.GetTopicState("nonexistent"); err == nil {
+		t.Error("Expected an error for a nonexistent topic")
+	}
+}
+
+func TestListReassignments(t *testing.T) {
+	h := newTestHandler(t)
+
+	// Partition 0 of test1 has a larger replica set than partition 1.
+	r, err := h.ListReassignments()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := kafkazk.Reassignments{"test1": {0: {1001}}}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("Expected reassignments %v, got %v", expected, r)
+	}
+
+	md, err := h.GetTopicMetadata("test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := map[int][]int{0: {1001, 1002}, 1: {1002}}; !reflect.DeepEqual(md.Partitions, expected) {
+		t.Errorf("Expected partitions %v, got %v", expected, md.Partitions)
+	}
+
+	if expected := map[int][]int{0: {1002}}; !reflect.DeepEqual(md.RemovingReplicas, expected) {
+		t.Errorf("Expected removing replicas %v, got %v", expected, md.RemovingReplicas)
+	}
+
+	if len(md.AddingReplicas) != 0 {
+		t.Errorf("Expected no adding replicas, got %v", md.AddingReplicas)
+	}
+}
+
+func TestZnodes(t *testing.T) {
+	h := newTestHandler(t)
+
+	exists, err := h.Exists("/admin/reassign_partitions")
+	if exists || err != nil {
+		t.Errorf("Unexpected Exists result: %v, %v", exists, err)
+	}
+
+	if _, err := h.Get("/admin/reassign_partitions"); err == nil {
+		t.Error("Expected an ErrNoNode")
+	} else if _, ok := err.(kafkazk.ErrNoNode); !ok {
+		t.Errorf("Expected an ErrNoNode, got %T", err)
+	}
+
+	if err := h.Create("/autothrottle", ""); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestInferReassignments(t *testing.T) {
+	partition := func(id int32, replicas, isr []int32) kafkaadmin.PartitionState {
+		return kafkaadmin.PartitionState{ID: id, Leader: replicas[0], Replicas: replicas, ISR: isr}
+	}
+
+	states := kafkaadmin.TopicStates{
+		// Partition 1 is moving from 1003 to 1004; 1004 is the leading replica
+		// and isn't yet in the ISR.
+		"moving": {
+			Name: "moving",
+			PartitionStates: map[int]kafkaadmin.PartitionState{
+				0: partition(0, []int32{1001, 1002}, []int32{1001, 1002}),
+				1: partition(1, []int32{1002, 1004, 1003}, []int32{1002, 1003}),
+				2: partition(2, []int32{1003, 1001}, []int32{1003, 1001}),
+			},
+		},
+		"steady": {
+			Name: "steady",
+			PartitionStates: map[int]kafkaadmin.PartitionState{
+				0: partition(0, []int32{1001, 1002, 1003}, []int32{1001, 1002}),
+			},
+		},
+	}
+
+	expected := kafkazk.Reassignments{"moving": {1: {1002, 1004}}}
+	if got := InferReassignments(states); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected reassignments %v, got %v", expected, got)
+	}
+
+	target, removing, reassigning := inferTarget(states["moving"].PartitionStates[1], 2)
+	if !reassigning {
+		t.Error("Expected partition 1 to be reassigning")
+	}
+
+	if !reflect.DeepEqual(target, []int{1002, 1004}) || !reflect.DeepEqual(removing, []int{1003}) {
+		t.Errorf("Unexpected target %v and removing replicas %v", target, removing)
+	}
+
+	if oos := outOfSync(target, states["moving"].PartitionStates[1].ISR); !reflect.DeepEqual(oos, []int{1004}) {
+		t.Errorf("Expected out of sync replicas [1004], got %v", oos)
+	}
+}
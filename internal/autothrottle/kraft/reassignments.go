@@ -0,0 +1,80 @@
+package kraft
+
+import (
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// InferReassignments returns the ongoing reassignments inferred from the
+// TopicStates.
+//
+// While a partition is being reassigned, Kafka reports its replica set as the
+// target replicas followed by the replicas being removed. The replication
+// factor of a topic is taken to be the smallest replica set of any of its
+// partitions; partitions with larger replica sets are reassigning to the
+// leading replicas. This can't detect replication factor increases, nor
+// reassignments where every partition of a topic is moving by the same number
+// of replicas at once.
+func InferReassignments(states kafkaadmin.TopicStates) kafkazk.Reassignments {
+	reassignments := kafkazk.Reassignments{}
+
+	for name, state := range states {
+		rf := replicationFactor(state)
+
+		for id, partn := range state.PartitionStates {
+			target, _, reassigning := inferTarget(partn, rf)
+			if !reassigning {
+				continue
+			}
+
+			if reassignments[name] == nil {
+				reassignments[name] = map[int][]int{}
+			}
+			reassignments[name][id] = target
+		}
+	}
+
+	return reassignments
+}
+
+// replicationFactor returns the smallest replica set size of any partition in
+// the TopicState.
+func replicationFactor(state kafkaadmin.TopicState) int {
+	var rf int
+	for _, partn := range state.PartitionStates {
+		if n := len(partn.Replicas); rf == 0 || n < rf {
+			rf = n
+		}
+	}
+
+	return rf
+}
+
+// inferTarget returns the target and removing replicas of a partition and
+// whether it's being reassigned, given the replication factor.
+func inferTarget(partn kafkaadmin.PartitionState, rf int) ([]int, []int, bool) {
+	if rf == 0 || len(partn.Replicas) <= rf {
+		return nil, nil, false
+	}
+
+	replicas := int32sToInts(partn.Replicas)
+
+	return replicas[:rf], replicas[rf:], true
+}
+
+// outOfSync returns the replicas that aren't in the ISR.
+func outOfSync(replicas []int, isr []int32) []int {
+	in := map[int]struct{}{}
+	for _, id := range isr {
+		in[int(id)] = struct{}{}
+	}
+
+	var out []int
+	for _, id := range replicas {
+		if _, exists := in[id]; !exists {
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
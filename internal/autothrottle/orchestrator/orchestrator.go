@@ -0,0 +1,149 @@
+// Package orchestrator executes large reassignment plans in batches. Each
+// batch is submitted only once the previous batch has completed, allowing
+// autothrottle to manage replication throttles for the entire plan.
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+const waitingMsg = "Reassignment plan waiting for unrelated reassignments to complete"
+
+// EventWriter for writing event key values.
+type EventWriter interface {
+	Write(string, string)
+}
+
+// Config configures an Orchestrator.
+type Config struct {
+	// The ZooKeeper handler.
+	ZK kafkazk.Handler
+	// The znode path where the reassignment plan is stored.
+	PlanZnodePath string
+	Events        EventWriter
+}
+
+// Orchestrator submits batches from a stored Plan.
+type Orchestrator struct {
+	zk       kafkazk.Handler
+	planPath string
+	events   EventWriter
+}
+
+// NewOrchestrator takes a Config and returns an *Orchestrator.
+func NewOrchestrator(c Config) *Orchestrator {
+	return &Orchestrator{
+		zk:       c.ZK,
+		planPath: c.PlanZnodePath,
+		events:   c.Events,
+	}
+}
+
+// Advance takes the currently running reassignments and checks the progress of
+// any stored Plan. If the current batch has completed and no other
+// reassignments are running, the next batch is submitted. The batch is
+// persisted as submitting before it's submitted, so that a submission
+// interrupted by an error or restart is retried rather than skipped. A bool is
+// returned indicating whether a new batch was submitted.
+func (o *Orchestrator) Advance(running kafkazk.Reassignments) (bool, error) {
+	plan, err := FetchPlan(o.zk, o.planPath)
+	switch err {
+	case nil:
+	case ErrNoPlan:
+		return false, nil
+	default:
+		return false, err
+	}
+
+	// Check whether any partitions in the current batch are still reassigning.
+	for _, p := range plan.Current {
+		if _, reassigning := running[p.Topic][p.Partition]; reassigning {
+			log.Printf("Reassignment plan in progress: %s\n", plan)
+
+			// The batch was submitted; clear an interrupted submission.
+			if plan.Submitting {
+				plan.Submitting = false
+				return false, StorePlan(o.zk, o.planPath, plan)
+			}
+
+			return false, nil
+		}
+	}
+
+	// The current batch is complete, unless its submission was interrupted. In
+	// that case it's resubmitted; resubmitting a batch that did complete is a
+	// no-op reassignment.
+	if len(plan.Current) > 0 && !plan.Submitting {
+		plan.Completed += len(plan.Current)
+		plan.Current = nil
+
+		m := fmt.Sprintf("Reassignment plan batch %d complete: %s", plan.Batches, plan)
+		log.Println(m)
+		o.events.Write("Reassignment plan batch complete", m)
+	}
+
+	if plan.Done() {
+		if err := RemovePlan(o.zk, o.planPath); err != nil {
+			return false, err
+		}
+
+		m := fmt.Sprintf("Reassignment plan complete: %d partitions reassigned in %d batches", plan.Total, plan.Batches)
+		log.Println(m)
+		o.events.Write("Reassignment plan complete", m)
+
+		return false, nil
+	}
+
+	// Some other reassignment is running; wait for it to finish.
+	if len(running) > 0 {
+		log.Println(waitingMsg)
+		return false, StorePlan(o.zk, o.planPath, plan)
+	}
+
+	// Persist the next batch as submitting, then submit it.
+	if plan.Submitting {
+		log.Printf("Resubmitting interrupted reassignment plan batch %d\n", plan.Batches)
+	} else {
+		plan.nextBatch()
+		plan.Submitting = true
+
+		if err := StorePlan(o.zk, o.planPath, plan); err != nil {
+			return false, err
+		}
+	}
+
+	// The handler rejects the submission if a reassignment started since
+	// running was fetched. The batch remains persisted as submitting and is
+	// resubmitted on the next call.
+	switch err := o.submit(plan.Current); err {
+	case nil:
+	case kafkazk.ErrReassignmentInProgress:
+		log.Println(waitingMsg)
+		return false, nil
+	default:
+		return false, fmt.Errorf("error submitting reassignment: %s", err)
+	}
+
+	plan.Submitting = false
+	if err := StorePlan(o.zk, o.planPath, plan); err != nil {
+		return true, err
+	}
+
+	m := fmt.Sprintf("Reassignment plan batch %d submitted: %s", plan.Batches, plan)
+	log.Println(m)
+	o.events.Write("Reassignment plan batch submitted", m)
+
+	return true, nil
+}
+
+// submit submits a reassignment for the provided partitions.
+func (o *Orchestrator) submit(pl mapper.PartitionList) error {
+	pm := mapper.NewPartitionMap()
+	pm.Partitions = pl
+
+	return o.zk.SubmitReassignment(pm)
+}
@@ -0,0 +1,222 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+type eventsStub struct{ titles []string }
+
+func (e *eventsStub) Write(t string, _ string) { e.titles = append(e.titles, t) }
+
+func testPartitionMap() *mapper.PartitionMap {
+	pm := mapper.NewPartitionMap()
+	for i := 0; i < 5; i++ {
+		pm.Partitions = append(pm.Partitions, mapper.Partition{Topic: "test", Partition: i, Replicas: []int{1001, 1002}})
+	}
+	return pm
+}
+
+func TestNewPlan(t *testing.T) {
+	if _, err := NewPlan(testPartitionMap(), 0); err != ErrInvalidBatchSize {
+		t.Errorf("Expected ErrInvalidBatchSize, got %v", err)
+	}
+
+	if _, err := NewPlan(mapper.NewPartitionMap(), 2); err != ErrEmptyPlan {
+		t.Errorf("Expected ErrEmptyPlan, got %v", err)
+	}
+
+	plan, err := NewPlan(testPartitionMap(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Total != 5 || len(plan.Pending) != 5 {
+		t.Errorf("Expected 5 pending partitions, got %d", len(plan.Pending))
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.SetReassignments(kafkazk.Reassignments{})
+	events := &eventsStub{}
+	planPath := "/autothrottle/reassignment_plan"
+
+	o := NewOrchestrator(Config{
+		ZK:            zk,
+		PlanZnodePath: planPath,
+		Events:        events,
+	})
+
+	// No plan set.
+	if submitted, err := o.Advance(nil); submitted || err != nil {
+		t.Fatalf("Unexpected Advance result: %v, %v", submitted, err)
+	}
+
+	plan, _ := NewPlan(testPartitionMap(), 2)
+	if err := StorePlan(zk, planPath, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedBatches := [][]int{{0, 1}, {2, 3}, {4}}
+
+	for i, expected := range expectedBatches {
+		submitted, err := o.Advance(kafkazk.Reassignments{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !submitted {
+			t.Fatalf("[batch %d] Expected a batch submission", i)
+		}
+
+		// Check the submitted reassignment.
+		running := zk.GetReassignments()
+
+		if len(running["test"]) != len(expected) {
+			t.Fatalf("[batch %d] Expected %d partitions, got %d", i, len(expected), len(running["test"]))
+		}
+
+		for _, p := range expected {
+			if _, exists := running["test"][p]; !exists {
+				t.Errorf("[batch %d] Expected partition %d to be submitted", i, p)
+			}
+		}
+
+		// While the batch is running, no further batches should be submitted.
+		if submitted, _ := o.Advance(running); submitted {
+			t.Errorf("[batch %d] Unexpected batch submission", i)
+		}
+
+		// Simulate Kafka completing the reassignment.
+		zk.SetReassignments(kafkazk.Reassignments{})
+	}
+
+	// The final call completes the plan.
+	if submitted, err := o.Advance(kafkazk.Reassignments{}); submitted || err != nil {
+		t.Fatalf("Unexpected Advance result: %v, %v", submitted, err)
+	}
+
+	if _, err := FetchPlan(zk, planPath); err != ErrNoPlan {
+		t.Errorf("Expected plan to be removed, got %v", err)
+	}
+
+	if last := events.titles[len(events.titles)-1]; last != "Reassignment plan complete" {
+		t.Errorf("Unexpected last event %s", last)
+	}
+}
+
+func TestAdvanceUnrelatedReassignment(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	planPath := "/autothrottle/reassignment_plan"
+
+	o := NewOrchestrator(Config{
+		ZK:            zk,
+		PlanZnodePath: planPath,
+		Events:        &eventsStub{},
+	})
+
+	plan, _ := NewPlan(testPartitionMap(), 2)
+	StorePlan(zk, planPath, plan)
+
+	running := kafkazk.Reassignments{"other": map[int][]int{0: {1001}}}
+
+	if submitted, _ := o.Advance(running); submitted {
+		t.Error("Unexpected batch submission while other reassignments are running")
+	}
+}
+
+func TestAdvanceReassignmentInProgress(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	planPath := "/autothrottle/reassignment_plan"
+
+	o := NewOrchestrator(Config{
+		ZK:            zk,
+		PlanZnodePath: planPath,
+		Events:        &eventsStub{},
+	})
+
+	plan, _ := NewPlan(testPartitionMap(), 2)
+	StorePlan(zk, planPath, plan)
+
+	// A reassignment started after running was fetched.
+	zk.SetReassignments(kafkazk.Reassignments{"other": map[int][]int{0: {1001}}})
+
+	if submitted, err := o.Advance(kafkazk.Reassignments{}); submitted || err != nil {
+		t.Fatalf("Unexpected Advance result: %v, %v", submitted, err)
+	}
+
+	// The batch remains persisted as submitting.
+	stored, _ := FetchPlan(zk, planPath)
+	if !stored.Submitting || stored.Batches != 1 || len(stored.Current) != 2 {
+		t.Errorf("Unexpected plan state: %s, submitting: %v", stored, stored.Submitting)
+	}
+}
+
+func TestAdvanceInterruptedSubmission(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	planPath := "/autothrottle/reassignment_plan"
+
+	o := NewOrchestrator(Config{
+		ZK:            zk,
+		PlanZnodePath: planPath,
+		Events:        &eventsStub{},
+	})
+
+	// A batch persisted as submitting, but not submitted.
+	zk.SetReassignments(kafkazk.Reassignments{})
+	plan, _ := NewPlan(testPartitionMap(), 2)
+	plan.nextBatch()
+	plan.Submitting = true
+	StorePlan(zk, planPath, plan)
+
+	submitted, err := o.Advance(kafkazk.Reassignments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !submitted {
+		t.Fatal("Expected the interrupted batch to be resubmitted")
+	}
+
+	resubmitted := zk.GetReassignments()["test"]
+	if _, ok := resubmitted[0]; !ok || len(resubmitted) != 2 {
+		t.Errorf("Expected partitions 0 and 1 to be resubmitted, got %v", resubmitted)
+	} else if _, ok := resubmitted[1]; !ok {
+		t.Errorf("Expected partitions 0 and 1 to be resubmitted, got %v", resubmitted)
+	}
+
+	stored, _ := FetchPlan(zk, planPath)
+	if stored.Submitting || stored.Batches != 1 || stored.Completed != 0 || len(stored.Pending) != 3 {
+		t.Errorf("Unexpected plan state: %s, submitting: %v", stored, stored.Submitting)
+	}
+
+	// A batch persisted as submitting that's running was submitted.
+	stored.Submitting = true
+	StorePlan(zk, planPath, stored)
+
+	running := kafkazk.Reassignments{"test": map[int][]int{0: {1001, 1002}}}
+	if submitted, err := o.Advance(running); submitted || err != nil {
+		t.Fatalf("Unexpected Advance result: %v, %v", submitted, err)
+	}
+
+	if stored, _ := FetchPlan(zk, planPath); stored.Submitting {
+		t.Error("Expected the submitting state to be cleared")
+	}
+}
+
+func TestStorePlanTooLarge(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+
+	pm := mapper.NewPartitionMap()
+	for i := 0; i < 50000; i++ {
+		pm.Partitions = append(pm.Partitions, mapper.Partition{Topic: "test", Partition: i, Replicas: []int{1001, 1002, 1003}})
+	}
+
+	plan, _ := NewPlan(pm, 10)
+	if err := StorePlan(zk, "/autothrottle/reassignment_plan", plan); err != ErrPlanTooLarge {
+		t.Errorf("Expected ErrPlanTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+var (
+	// ErrNoPlan is returned when no reassignment plan is stored.
+	ErrNoPlan = errors.New("no reassignment plan is set")
+	// ErrPlanExists is returned when attempting to store a plan while one is
+	// already in progress.
+	ErrPlanExists = errors.New("a reassignment plan is already in progress")
+	// ErrInvalidBatchSize is returned for batch sizes < 1.
+	ErrInvalidBatchSize = errors.New("batch size must be >0")
+	// ErrEmptyPlan is returned for plans without any partitions.
+	ErrEmptyPlan = errors.New("reassignment plan has no partitions")
+	// ErrPlanTooLarge is returned when storing a plan that exceeds
+	// maxPlanSize.
+	ErrPlanTooLarge = fmt.Errorf("reassignment plan exceeds the max size of %d bytes; split it into smaller plans", maxPlanSize)
+)
+
+// maxPlanSize is the max size in bytes of a stored plan. Plans are stored in a
+// single znode, which ZooKeeper limits to its jute.maxbuffer size (1MB by
+// default); some headroom is left for the request overhead.
+const maxPlanSize = 1000 * 1000
+
+// Plan is a reassignment plan that's submitted to Kafka in batches.
+type Plan struct {
+	// The max number of partitions per batch.
+	BatchSize int `json:"batch_size"`
+	// The number of batches submitted so far.
+	Batches int `json:"batches"`
+	// The total number of partitions in the plan.
+	Total int `json:"total"`
+	// The number of partitions in batches that have completed.
+	Completed int `json:"completed"`
+	// The partitions in the currently running batch.
+	Current mapper.PartitionList `json:"current"`
+	// The partitions that have yet to be submitted.
+	Pending mapper.PartitionList `json:"pending"`
+	// Whether the current batch is being submitted. It's set before the batch
+	// is submitted and cleared once the submission succeeds.
+	Submitting bool `json:"submitting,omitempty"`
+}
+
+// NewPlan takes a *mapper.PartitionMap and batch size and returns a *Plan.
+func NewPlan(pm *mapper.PartitionMap, batchSize int) (*Plan, error) {
+	if batchSize < 1 {
+		return nil, ErrInvalidBatchSize
+	}
+
+	if pm == nil || len(pm.Partitions) == 0 {
+		return nil, ErrEmptyPlan
+	}
+
+	pending := make(mapper.PartitionList, len(pm.Partitions))
+	copy(pending, pm.Partitions)
+
+	return &Plan{
+		BatchSize: batchSize,
+		Total:     len(pending),
+		Pending:   pending,
+	}, nil
+}
+
+// Done returns whether all partitions in the plan have been reassigned.
+func (p *Plan) Done() bool {
+	return len(p.Current) == 0 && len(p.Pending) == 0
+}
+
+// String returns a summary of the plan progress.
+func (p *Plan) String() string {
+	return fmt.Sprintf("batch %d, %d/%d partitions completed, %d in progress, %d pending",
+		p.Batches, p.Completed, p.Total, len(p.Current), len(p.Pending))
+}
+
+// nextBatch pops up to BatchSize partitions from the pending list and sets
+// them as the current batch.
+func (p *Plan) nextBatch() mapper.PartitionList {
+	n := p.BatchSize
+	if n > len(p.Pending) {
+		n = len(p.Pending)
+	}
+
+	p.Current = p.Pending[:n]
+	p.Pending = p.Pending[n:]
+	p.Batches++
+
+	return p.Current
+}
+
+// FetchPlan gets a reassignment plan from path p.
+func FetchPlan(zk kafkazk.Handler, p string) (*Plan, error) {
+	if exists, err := zk.Exists(p); err != nil {
+		return nil, fmt.Errorf("error getting reassignment plan: %s", err)
+	} else if !exists {
+		return nil, ErrNoPlan
+	}
+
+	data, err := zk.Get(p)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reassignment plan: %s", err)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrNoPlan
+	}
+
+	plan := &Plan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("error unmarshalling reassignment plan: %s", err)
+	}
+
+	return plan, nil
+}
+
+// StorePlan sets a reassignment plan to path p. An ErrPlanTooLarge is returned
+// if the plan exceeds maxPlanSize.
+func StorePlan(zk kafkazk.Handler, p string, plan *Plan) error {
+	d, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("error marshalling reassignment plan: %s", err)
+	}
+
+	if len(d) > maxPlanSize {
+		return ErrPlanTooLarge
+	}
+
+	exists, _ := zk.Exists(p)
+
+	if exists {
+		err = zk.Set(p, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting reassignment plan: %s", err)
+	}
+
+	return nil
+}
+
+// RemovePlan deletes a reassignment plan at path p. Any batch already
+// submitted to Kafka is unaffected.
+func RemovePlan(zk kafkazk.Handler, p string) error {
+	exists, err := zk.Exists(p)
+	if !exists && err == nil {
+		return nil
+	}
+
+	if err := zk.Delete(p); err != nil {
+		return fmt.Errorf("error removing reassignment plan: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+// Package prometheus serves gauges and counters in the Prometheus text
+// exposition format. Metrics are published as complete snapshots; each Update
+// replaces all previously published samples so that series for topics and
+// brokers no longer present are dropped.
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Family is a metric family.
+type Family struct {
+	Name string
+	Help string
+	// The metric type, either "gauge" or "counter". Defaults to "gauge".
+	Type    string
+	Samples []Sample
+}
+
+// Sample is a single metric value.
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// Labels are sample label names and values.
+type Labels map[string]string
+
+// Registry holds the most recently published metric families. It implements
+// http.Handler.
+type Registry struct {
+	mu       sync.RWMutex
+	families []Family
+}
+
+// NewRegistry returns a *Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Update replaces all published families.
+func (r *Registry) Update(f []Family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.families = f
+}
+
+// ServeHTTP writes the published families in the text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(r.Bytes())
+}
+
+// Bytes returns the published families in the text exposition format. Families
+// are sorted by name and samples by labels.
+func (r *Registry) Bytes() []byte {
+	r.mu.RLock()
+	families := make([]Family, len(r.families))
+	copy(families, r.families)
+	r.mu.RUnlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+
+	var b bytes.Buffer
+	for _, f := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.Name, escape(f.Help, false))
+		typ := f.Type
+		if typ == "" {
+			typ = "gauge"
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.Name, typ)
+
+		lines := make([]string, 0, len(f.Samples))
+		for _, s := range f.Samples {
+			lines = append(lines, fmt.Sprintf("%s%s %s\n",
+				f.Name, s.Labels.String(), strconv.FormatFloat(s.Value, 'g', -1, 64)))
+		}
+		sort.Strings(lines)
+
+		for _, l := range lines {
+			b.WriteString(l)
+		}
+	}
+
+	return b.Bytes()
+}
+
+// String returns the labels in the exposition format, sorted by name, e.g.
+// {broker="1001",role="leader"}. An empty string is returned if there are no
+// labels.
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	var names []string
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escape(l[name], true)))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escape escapes backslashes and newlines, and double quotes in label values.
+func escape(s string, quotes bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quotes {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+
+	return s
+}
@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Update([]Family{
+		{
+			Name: "b_gauge",
+			Help: "B gauge.",
+			Samples: []Sample{
+				{Labels: Labels{"role": "leader", "broker": "1002"}, Value: 2},
+				{Labels: Labels{"role": "leader", "broker": "1001"}, Value: 1.5},
+			},
+		},
+		{
+			Name:    "a_gauge",
+			Help:    "A gauge\nwith a newline.",
+			Samples: []Sample{{Value: 3}},
+		},
+		{
+			Name:    "c_total",
+			Help:    "C counter.",
+			Type:    "counter",
+			Samples: []Sample{{Value: 4}},
+		},
+	})
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %s", ct)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	expected := `# HELP a_gauge A gauge\nwith a newline.
+# TYPE a_gauge gauge
+a_gauge 3
+# HELP b_gauge B gauge.
+# TYPE b_gauge gauge
+b_gauge{broker="1001",role="leader"} 1.5
+b_gauge{broker="1002",role="leader"} 2
+# HELP c_total C counter.
+# TYPE c_total counter
+c_total 4
+`
+
+	if string(body) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, body)
+	}
+
+	// Updates replace all families.
+	r.Update(nil)
+	if b := r.Bytes(); len(b) != 0 {
+		t.Errorf("Expected no output, got %s", b)
+	}
+}
+
+func TestLabelsString(t *testing.T) {
+	l := Labels{"topic": `a"b\c`}
+	if s := l.String(); s != `{topic="a\"b\\c"}` {
+		t.Errorf("Unexpected labels %s", s)
+	}
+}
@@ -0,0 +1,98 @@
+package replication
+
+import (
+	"errors"
+	"log"
+	"sort"
+)
+
+// SetLostBrokers sets the IDs of brokers that are no longer registered. While
+// lost, brokers transferring replicas to a lost reassignment destination are
+// limited to the minimum rates in throttle updates.
+func (tm *ThrottleManager) SetLostBrokers(ids []int) {
+	tm.lostBrokers = make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		tm.lostBrokers[id] = struct{}{}
+	}
+}
+
+// LostDestinations returns a sorted []int of lost brokers that are
+// reassignment destinations.
+func (tm *ThrottleManager) LostDestinations() []int {
+	var ids []int
+	for id := range tm.lostBrokers {
+		if _, exists := tm.reassigningBrokers.dst[id]; exists {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+
+	return ids
+}
+
+// lostDestinationSources returns a sorted []int of the brokers that aren't
+// lost and are transferring replicas to a lost reassignment destination.
+func (tm *ThrottleManager) lostDestinationSources() []int {
+	seen := map[int]struct{}{}
+	var ids []int
+
+	for _, t := range tm.reassigningBrokers.transfers {
+		src, dst := t[0], t[1]
+		if _, lost := tm.lostBrokers[dst]; !lost {
+			continue
+		}
+		if _, lost := tm.lostBrokers[src]; lost {
+			continue
+		}
+		if _, exists := seen[src]; !exists {
+			seen[src] = struct{}{}
+			ids = append(ids, src)
+		}
+	}
+
+	sort.Ints(ids)
+
+	return ids
+}
+
+// isLostDestinationSource returns whether the broker is transferring replicas
+// to a lost reassignment destination.
+func (tm *ThrottleManager) isLostDestinationSource(id int) bool {
+	for _, src := range tm.lostDestinationSources() {
+		if src == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReduceLostDestinationThrottles applies the minimum rates to the brokers
+// transferring replicas to lost reassignment destinations, without waiting
+// for the next throttle update. The brokers with reduced throttles are
+// returned. This is a no-op while paused.
+func (tm *ThrottleManager) ReduceLostDestinationThrottles() ([]int, error) {
+	ids := tm.lostDestinationSources()
+	if tm.paused || len(ids) == 0 {
+		return nil, nil
+	}
+
+	bs := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		bs[id] = struct{}{}
+	}
+
+	events, errs := tm.applyBrokerThrottles(bs, tm.minimumRates(ids))
+	for e := range events {
+		tm.writeDecision(e, ReasonBrokerLost)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	log.Printf("Throttles reduced to the minimum rates for brokers replicating to lost brokers: %v\n", ids)
+
+	return ids, nil
+}
@@ -0,0 +1,94 @@
+package replication
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestReduceLostDestinationThrottles(t *testing.T) {
+	interval := zkWriteInterval
+	zkWriteInterval = 0
+	t.Cleanup(func() { zkWriteInterval = interval })
+
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(kafkametrics.StubResponse{Metrics: stubBrokerMetrics()})
+
+	// 1000 replicates to 1003, 1002 replicates to 1005 and 1010.
+	tm := newTestThrottleManager(t, zk, km)
+	decisions := &decisionsStub{}
+	tm.decisions = decisions
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	decisions.byBrokerRole()
+	zk.ResetKafkaConfigUpdates()
+
+	// 1007 isn't a reassignment destination.
+	tm.SetLostBrokers([]int{1003, 1007})
+	if lost := tm.LostDestinations(); !reflect.DeepEqual(lost, []int{1003}) {
+		t.Errorf("Expected lost destinations [1003], got %v", lost)
+	}
+
+	// Nothing is written while paused.
+	tm.SetPaused(true)
+	reduced, err := tm.ReduceLostDestinationThrottles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reduced) != 0 {
+		t.Errorf("Expected no reduced brokers while paused, got %v", reduced)
+	}
+
+	if updates := brokerConfigUpdates(zk); len(updates) != 0 {
+		t.Errorf("Expected no config updates while paused, got %v", updates)
+	}
+
+	tm.SetPaused(false)
+	reduced, err = tm.ReduceLostDestinationThrottles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reduced, []int{1000}) {
+		t.Errorf("Expected reduced brokers [1000], got %v", reduced)
+	}
+
+	expected := throttleUpdates(map[string][2]string{"1000": {"20000000", "20000000"}})
+	if updates := brokerConfigUpdates(zk); !reflect.DeepEqual(updates, expected) {
+		t.Errorf("Expected config updates %v, got %v", expected, updates)
+	}
+
+	expectedDecisions := map[string]string{
+		"1000 leader":   "108, 20, broker_lost",
+		"1000 follower": "0, 20, broker_lost",
+	}
+	assertDecisions(t, "reduced", expectedDecisions, decisions.byBrokerRole())
+
+	// Subsequent throttle updates retain the minimum rates for 1000.
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range decisions.decisions {
+		if d.Broker == 1000 {
+			t.Errorf("Unexpected decision for broker 1000: %v", d)
+		}
+	}
+
+	// Once the broker is registered again, rates are computed as usual.
+	tm.SetLostBrokers(nil)
+	if lost := tm.LostDestinations(); len(lost) != 0 {
+		t.Errorf("Expected no lost destinations, got %v", lost)
+	}
+
+	if sources := tm.lostDestinationSources(); len(sources) != 0 {
+		t.Errorf("Expected no lost destination sources, got %v", sources)
+	}
+}
@@ -2,6 +2,7 @@ package replication
 
 import (
 	"fmt"
+	"log"
 	"sort"
 	"strconv"
 
@@ -16,6 +17,18 @@ type reassigningBrokers struct {
 	dst               map[int]struct{}
 	all               map[int]struct{}
 	throttledReplicas TopicThrottledReplicas
+	// Brokers exclusively handling replication factor increases in the
+	// respective role, i.e. not also handling partition movements.
+	rfIncreaseSrc map[int]struct{}
+	rfIncreaseDst map[int]struct{}
+	// Topics where all reassigning partitions are replication factor increases.
+	rfIncreaseTopics []string
+	// Source and destination broker ID pairs for each reassigning replica.
+	transfers [][2]int
+	// Brokers replicating to or from a broker in a different rack in the
+	// respective role. Populated by setRacks.
+	crossAZSrc map[int]struct{}
+	crossAZDst map[int]struct{}
 }
 
 // lists returns a sorted []int of broker IDs for the src, dst
@@ -46,10 +59,93 @@ func (bm reassigningBrokers) lists() ([]int, []int, []int) {
 	return srcBrokers, dstBrokers, allBrokers
 }
 
+// rfIncreaseOnly returns whether the broker exclusively handles replication
+// factor increases in the specified role.
+func (bm reassigningBrokers) rfIncreaseOnly(id int, role ReplicaType) bool {
+	var exists bool
+	switch role {
+	case "leader":
+		_, exists = bm.rfIncreaseSrc[id]
+	case "follower":
+		_, exists = bm.rfIncreaseDst[id]
+	}
+
+	return exists
+}
+
+// setRacks takes a map of broker ID to rack and populates the brokers
+// replicating across racks. Transfers where either rack is unknown aren't
+// considered cross-rack.
+func (bm *reassigningBrokers) setRacks(racks map[int]string) {
+	bm.crossAZSrc, bm.crossAZDst = map[int]struct{}{}, map[int]struct{}{}
+
+	for _, t := range bm.transfers {
+		src, dst := racks[t[0]], racks[t[1]]
+		if src != "" && dst != "" && src != dst {
+			bm.crossAZSrc[t[0]] = struct{}{}
+			bm.crossAZDst[t[1]] = struct{}{}
+		}
+	}
+}
+
+// updateRacks fetches the rack of each broker and populates the reassigning
+// brokers replicating across racks. Brokers whose rack couldn't be fetched
+// aren't considered to be replicating across racks.
+func (tm *ThrottleManager) updateRacks() {
+	meta, errs := tm.zk.GetAllBrokerMeta(false)
+	for _, err := range errs {
+		log.Printf("Error fetching broker racks: %s\n", err)
+	}
+
+	racks := map[int]string{}
+	for id, m := range meta {
+		racks[id] = m.Rack
+	}
+
+	tm.reassigningBrokers.setRacks(racks)
+}
+
+// crossAZ returns whether the broker replicates to or from a broker in a
+// different rack in the specified role.
+func (bm reassigningBrokers) crossAZ(id int, role ReplicaType) bool {
+	var exists bool
+	switch role {
+	case "leader":
+		_, exists = bm.crossAZSrc[id]
+	case "follower":
+		_, exists = bm.crossAZDst[id]
+	}
+
+	return exists
+}
+
+// limits takes the configured Limits, a broker ID and role and returns the
+// Limits that apply to the broker in that role, along with a description of
+// any adjustments for logging. Brokers exclusively handling replication factor
+// increases use the replication factor increase maximums, and brokers
+// replicating across racks are capped at the cross-AZ maximums.
+func (bm reassigningBrokers) limits(l Limits, id int, role ReplicaType) (Limits, string) {
+	var desc string
+
+	if bm.rfIncreaseOnly(id, role) {
+		l = l.rfIncreaseLimits()
+		desc += ", replication factor increase"
+	}
+
+	if bm.crossAZ(id, role) {
+		l = l.crossAZLimits()
+		desc += ", cross-AZ"
+	}
+
+	return l, desc
+}
+
 // GetReassigningBrokers takes a kafakzk.Reassignments and returns a reassigningBrokers,
 // which includes a broker list for source, destination, and all brokers
 // handling any ongoing reassignments. Additionally, a map of throttled
-// replicas by topic is included.
+// replicas by topic is included. Partitions where no current replicas are being
+// removed are considered replication factor increases; brokers exclusively
+// handling these and topics exclusively made up of these are tracked separately.
 func GetReassigningBrokers(r kafkazk.Reassignments, zk kafkazk.Handler) (reassigningBrokers, error) {
 	lb := reassigningBrokers{
 		// Maps of src and dst brokers used as sets.
@@ -59,8 +155,13 @@ func GetReassigningBrokers(r kafkazk.Reassignments, zk kafkazk.Handler) (reassig
 		// A map for each topic with a list throttled leaders and followers.
 		// This is used to write the topic config throttled brokers lists.
 		throttledReplicas: TopicThrottledReplicas{},
+		rfIncreaseSrc:     map[int]struct{}{},
+		rfIncreaseDst:     map[int]struct{}{},
 	}
 
+	// Src and dst brokers handling partition movements.
+	moveSrc, moveDst := map[int]struct{}{}, map[int]struct{}{}
+
 	// Get topic data for each topic undergoing a reassignment.
 	for t := range r {
 		topic := Topic(t)
@@ -72,6 +173,14 @@ func GetReassigningBrokers(r kafkazk.Reassignments, zk kafkazk.Handler) (reassig
 			return lb, fmt.Errorf("Error fetching topic data: %s", err.Error())
 		}
 
+		meta, err := zk.GetTopicMetadata(t)
+		if err != nil {
+			return lb, fmt.Errorf("Error fetching topic metadata: %s", err.Error())
+		}
+
+		// Whether any partitions are replication factor increases or movements.
+		var rfIncrease, moving bool
+
 		// For each partition, compare the current ISR leader to the brokers being
 		// assigned in the reassignments. The current leaders will be sources,
 		// new brokers in the assignment list (but not in the current ISR state)
@@ -81,11 +190,22 @@ func GetReassigningBrokers(r kafkazk.Reassignments, zk kafkazk.Handler) (reassig
 		for p := range tstate {
 			partn, _ := strconv.Atoi(p)
 			if reassigning, exists := r[t][partn]; exists {
+				// Brokers for the partition are tracked by whether it's a
+				// replication factor increase or a movement.
+				src, dst := moveSrc, moveDst
+				if isRFIncrease(meta.Partitions[partn], reassigning) {
+					src, dst = lb.rfIncreaseSrc, lb.rfIncreaseDst
+					rfIncrease = true
+				} else {
+					moving = true
+				}
+
 				// Source brokers.
 				leader := tstate[p].Leader
 				// In offline partitions, the leader value is set to -1. Skip.
 				if leader != -1 {
 					lb.src[leader] = struct{}{}
+					src[leader] = struct{}{}
 					// Append to the throttle list.
 					leaders := lb.throttledReplicas[topic]["leaders"]
 					lb.throttledReplicas[topic]["leaders"] = append(leaders, fmt.Sprintf("%d:%d", partn, leader))
@@ -99,19 +219,61 @@ func GetReassigningBrokers(r kafkazk.Reassignments, zk kafkazk.Handler) (reassig
 					// be dynamically throttled as if they're part of a reassignemnt.
 					if b != -1 && !inSlice(b, tstate[p].ISR) {
 						lb.dst[b] = struct{}{}
+						dst[b] = struct{}{}
 						followers := lb.throttledReplicas[topic]["followers"]
 						lb.throttledReplicas[topic]["followers"] = append(followers, fmt.Sprintf("%d:%d", partn, b))
+
+						// New replicas fetch from the leader.
+						if leader != -1 {
+							lb.transfers = append(lb.transfers, [2]int{leader, b})
+						}
 					}
 				}
 			}
 		}
+
+		if rfIncrease && !moving {
+			lb.rfIncreaseTopics = append(lb.rfIncreaseTopics, t)
+		}
 	}
 
+	// Brokers also handling partition movements aren't exclusively handling
+	// replication factor increases.
+	for id := range moveSrc {
+		delete(lb.rfIncreaseSrc, id)
+	}
+
+	for id := range moveDst {
+		delete(lb.rfIncreaseDst, id)
+	}
+
+	sort.Strings(lb.rfIncreaseTopics)
+
 	lb.all = mergeMaps(lb.src, lb.dst)
 
 	return lb, nil
 }
 
+// isRFIncrease takes the current replica set and the reassignment target replica
+// set for a partition and returns whether the reassignment is a replication
+// factor increase; the target adds replicas without removing any current ones.
+// During a reassignment, Kafka sets the current replica set as the union of the
+// original and target replicas, so any replica being moved away will be missing
+// from the target.
+func isRFIncrease(current, target []int) bool {
+	if len(current) == 0 {
+		return false
+	}
+
+	for _, id := range current {
+		if !inSlice(id, target) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // mergeMaps takes two maps and merges them.
 func mergeMaps(a map[int]struct{}, b map[int]struct{}) map[int]struct{} {
 	m := map[int]struct{}{}
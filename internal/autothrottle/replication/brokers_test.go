@@ -78,6 +78,91 @@ func TestGetReassigningBrokers(t *testing.T) {
 			t.Errorf("Expected follower string '%s', got '%s'", expectedThrottledFollowers[n], s)
 		}
 	}
+	// Check transfers; destinations fetch from the partition leader.
+	sort.Slice(bmaps.transfers, func(i, j int) bool {
+		return bmaps.transfers[i][1] < bmaps.transfers[j][1]
+	})
+
+	expectedTransfers := [][2]int{{1000, 1003}, {1002, 1005}, {1002, 1010}}
+	if len(bmaps.transfers) != len(expectedTransfers) {
+		t.Fatalf("Expected transfers %v, got %v", expectedTransfers, bmaps.transfers)
+	}
+
+	for n, tr := range bmaps.transfers {
+		if tr != expectedTransfers[n] {
+			t.Errorf("Expected transfers %v, got %v", expectedTransfers, bmaps.transfers)
+		}
+	}
+}
+
+func TestGetReassigningBrokersRFIncrease(t *testing.T) {
+	zk := &kafkazk.Stub{}
+
+	// The stub topic metadata has current replicas [1001, 1003, 1002] for
+	// partition 0 and [1002, 1001] for partition 1. The target replicas for
+	// rf_topic retain all current replicas; partition 2 has no current
+	// replicas and is treated as a movement.
+	re := kafkazk.Reassignments{
+		"rf_topic": map[int][]int{
+			0: {1001, 1003, 1002, 1004},
+			1: {1002, 1001, 1005},
+		},
+		"moving_topic": map[int][]int{
+			2: {1004, 1006},
+		},
+	}
+
+	bmaps, err := GetReassigningBrokers(re, zk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bmaps.rfIncreaseTopics) != 1 || bmaps.rfIncreaseTopics[0] != "rf_topic" {
+		t.Errorf("Expected replication factor increase topics [rf_topic], got %v", bmaps.rfIncreaseTopics)
+	}
+
+	expected := []struct {
+		id       int
+		role     ReplicaType
+		expected bool
+	}{
+		{1000, "leader", true},
+		{1002, "leader", true},
+		{1001, "follower", true},
+		{1003, "follower", true},
+		{1005, "follower", true},
+		// 1004 is a follower for rf_topic and a leader for moving_topic.
+		{1004, "follower", true},
+		{1004, "leader", false},
+		{1006, "follower", false},
+	}
+
+	for _, e := range expected {
+		if got := bmaps.rfIncreaseOnly(e.id, e.role); got != e.expected {
+			t.Errorf("Expected broker %d [%s] replication factor increase only %v, got %v",
+				e.id, e.role, e.expected, got)
+		}
+	}
+}
+
+func TestIsRFIncrease(t *testing.T) {
+	tests := []struct {
+		current  []int
+		target   []int
+		expected bool
+	}{
+		{[]int{1001, 1002}, []int{1001, 1002, 1003}, true},
+		{[]int{1001, 1002, 1003}, []int{1001, 1002, 1003}, true},
+		{[]int{1001, 1002, 1003}, []int{1002, 1003}, false},
+		{[]int{1001, 1002}, []int{1003, 1004}, false},
+		{nil, []int{1001, 1002}, false},
+	}
+
+	for n, test := range tests {
+		if got := isRFIncrease(test.current, test.target); got != test.expected {
+			t.Errorf("[test index %d] Expected %v, got %v", n, test.expected, got)
+		}
+	}
 }
 
 func TestIncompleteBrokerMetrics(t *testing.T) {
@@ -243,3 +328,51 @@ func stubBrokerMetrics() kafkametrics.BrokerMetrics {
 		},
 	}
 }
+
+func TestReassigningBrokersCrossAZ(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	tm := newTestThrottleManager(t, zk, kafkametrics.NewStub())
+
+	// Stub racks: 1001 a, 1002 b, 1003 unknown, 1004 a.
+	tm.reassigningBrokers = reassigningBrokers{
+		transfers: [][2]int{{1001, 1002}, {1001, 1004}, {1003, 1004}},
+	}
+
+	tm.updateRacks()
+
+	rb := tm.reassigningBrokers
+
+	expected := []struct {
+		id       int
+		role     ReplicaType
+		expected bool
+	}{
+		{1001, "leader", true},
+		{1002, "follower", true},
+		// Replicating within rack a.
+		{1004, "follower", false},
+		// The rack is unknown.
+		{1003, "leader", false},
+	}
+
+	for _, e := range expected {
+		if got := rb.crossAZ(e.id, e.role); got != e.expected {
+			t.Errorf("Expected broker %d [%s] cross-AZ %v, got %v", e.id, e.role, e.expected, got)
+		}
+	}
+
+	l, _ := NewLimits(NewLimitsConfig{
+		Minimum:                   10,
+		SourceMaximum:             80,
+		DestinationMaximum:        80,
+		CrossAZDestinationMaximum: 30,
+	})
+
+	if lim, desc := rb.limits(l, 1002, "follower"); lim["dstMax"] != 30 || desc != ", cross-AZ" {
+		t.Errorf("Expected dstMax 30 (cross-AZ), got %.0f (%s)", lim["dstMax"], desc)
+	}
+
+	if lim, desc := rb.limits(l, 1004, "follower"); lim["dstMax"] != 80 || desc != "" {
+		t.Errorf("Expected dstMax 80, got %.0f (%s)", lim["dstMax"], desc)
+	}
+}
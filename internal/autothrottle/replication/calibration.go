@@ -0,0 +1,159 @@
+package replication
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// calibrationPeriod is the period over which each peak throughput sample is
+// taken.
+const calibrationPeriod = time.Hour
+
+// capacityCalibration tracks the peak network throughput observed for each
+// broker over a trailing window. When enabled, the capacity of a broker is the
+// greater of its observed peak and the configured capacity for its instance
+// type, so that capacities underestimated in the cap-map (e.g. for burstable
+// instances) are corrected. Peaks are persisted in ZooKeeper so that they
+// survive restarts.
+type capacityCalibration struct {
+	// The trailing window over which peaks are retained. Calibration is
+	// disabled if 0.
+	window time.Duration
+	peaks  throttlestore.BrokerPeaks
+	// Whether the peaks have been loaded from ZooKeeper.
+	loaded bool
+	now    func() time.Time
+}
+
+func (c *capacityCalibration) enabled() bool {
+	return c.window > 0
+}
+
+// recordPeaks takes a kafkametrics.BrokerMetrics and records the throughput of
+// each broker as a peak sample for the current period. Samples older than the
+// calibration window are discarded and any changed peaks are persisted.
+func (tm *ThrottleManager) recordPeaks(bm kafkametrics.BrokerMetrics) {
+	c := &tm.calibration
+	if !c.enabled() {
+		return
+	}
+
+	// Load any peaks recorded prior to a restart. If this fails, recording is
+	// retried in the next interval rather than overwriting the stored peaks.
+	if !c.loaded {
+		peaks, err := throttlestore.FetchBrokerPeaks(tm.zk, tm.paths.Peaks)
+		if err != nil {
+			log.Printf("Error fetching capacity peaks: %s\n", err)
+			return
+		}
+		c.peaks, c.loaded = peaks, true
+	}
+
+	now := c.now()
+	start := now.Truncate(calibrationPeriod).Unix()
+	expiry := now.Add(-c.window).Truncate(calibrationPeriod).Unix()
+
+	for id, b := range bm {
+		// Interpolated metrics aren't observed throughput.
+		if tm.isInterpolated(id) {
+			continue
+		}
+
+		peak, changed := observePeak(c.peaks[id], b, start, expiry)
+		if !changed {
+			continue
+		}
+
+		c.peaks[id] = peak
+
+		if err := throttlestore.StoreBrokerPeak(tm.zk, tm.paths.Peaks, id, peak); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// observePeak takes a BrokerPeak and returns it updated with the throughput of
+// b as a sample for the period beginning at start, excluding any samples for
+// periods beginning before expiry, along with whether it changed. Samples
+// observed on a different instance type are discarded.
+func observePeak(p throttlestore.BrokerPeak, b *kafkametrics.Broker, start, expiry int64) (throttlestore.BrokerPeak, bool) {
+	var changed bool
+
+	if p.InstanceType != b.InstanceType {
+		p = throttlestore.BrokerPeak{InstanceType: b.InstanceType}
+		changed = true
+	}
+
+	samples := make([]throttlestore.PeakSample, 0, len(p.Samples)+1)
+	for _, s := range p.Samples {
+		if s.Start < expiry {
+			changed = true
+			continue
+		}
+		samples = append(samples, s)
+	}
+
+	if n := len(samples); n > 0 && samples[n-1].Start == start {
+		last := &samples[n-1]
+		if b.NetTX > last.TX || b.NetRX > last.RX {
+			last.TX, last.RX = math.Max(last.TX, b.NetTX), math.Max(last.RX, b.NetRX)
+			changed = true
+		}
+	} else {
+		samples = append(samples, throttlestore.PeakSample{Start: start, TX: b.NetTX, RX: b.NetRX})
+		changed = true
+	}
+
+	p.Samples = samples
+
+	return p, changed
+}
+
+// calibratedCapacity takes a *kafkametrics.Broker and returns the greater of
+// its observed peaks and the configured capacity for its instance type, along
+// with whether the capacity was raised by calibration. Capacities are only
+// calibrated for instance types with a configured capacity.
+func (tm *ThrottleManager) calibratedCapacity(b *kafkametrics.Broker) (Capacity, bool) {
+	if !tm.calibration.enabled() {
+		return Capacity{}, false
+	}
+
+	configured, exists := tm.limits.Capacity(b.InstanceType)
+	if !exists {
+		return Capacity{}, false
+	}
+
+	peak, observed := tm.calibration.peaks[b.ID]
+	if !observed || peak.InstanceType != b.InstanceType {
+		return configured, false
+	}
+
+	tx, rx := peak.Max()
+	c := Capacity{TX: math.Max(configured.TX, tx), RX: math.Max(configured.RX, rx)}
+
+	if c == configured {
+		return configured, false
+	}
+
+	log.Printf("Capacity for broker %d calibrated from observed peaks: tx %.2fMB/s, rx %.2fMB/s (cap-map tx %.2fMB/s, rx %.2fMB/s)\n",
+		b.ID, c.TX, c.RX, configured.TX, configured.RX)
+
+	return c, true
+}
+
+// resetPeaks discards the peaks recorded for broker id.
+func (tm *ThrottleManager) resetPeaks(id int) {
+	if !tm.calibration.enabled() {
+		return
+	}
+
+	delete(tm.calibration.peaks, id)
+
+	if err := throttlestore.RemoveBrokerPeak(tm.zk, tm.paths.Peaks, id); err != nil {
+		log.Println(err)
+	}
+}
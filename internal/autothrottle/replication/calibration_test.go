@@ -0,0 +1,108 @@
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestObservePeak(t *testing.T) {
+	b := &kafkametrics.Broker{ID: 1000, InstanceType: "stub", NetTX: 100, NetRX: 50}
+
+	// The first sample.
+	p, changed := observePeak(throttlestore.BrokerPeak{}, b, 7200, 0)
+	if !changed || p.InstanceType != "stub" || len(p.Samples) != 1 {
+		t.Fatalf("Unexpected peak %+v", p)
+	}
+
+	// Lower throughput in the same period doesn't change the sample.
+	if _, changed = observePeak(p, &kafkametrics.Broker{InstanceType: "stub", NetTX: 90, NetRX: 40}, 7200, 0); changed {
+		t.Error("Expected no change")
+	}
+
+	// Higher throughput in either direction raises the sample.
+	p, changed = observePeak(p, &kafkametrics.Broker{InstanceType: "stub", NetTX: 90, NetRX: 60}, 7200, 0)
+	if s := p.Samples[0]; !changed || s.TX != 100 || s.RX != 60 {
+		t.Errorf("Unexpected sample %+v", s)
+	}
+
+	// A new period appends a sample.
+	p, _ = observePeak(p, b, 10800, 0)
+	if len(p.Samples) != 2 {
+		t.Errorf("Expected 2 samples, got %+v", p.Samples)
+	}
+
+	// Expired samples are discarded.
+	p, _ = observePeak(p, b, 10800, 10800)
+	if len(p.Samples) != 1 || p.Samples[0].Start != 10800 {
+		t.Errorf("Expected 1 sample, got %+v", p.Samples)
+	}
+
+	// Samples from a different instance type are discarded.
+	p, _ = observePeak(p, &kafkametrics.Broker{InstanceType: "other", NetTX: 10, NetRX: 10}, 10800, 0)
+	if p.InstanceType != "other" || len(p.Samples) != 1 || p.Samples[0].TX != 10 {
+		t.Errorf("Unexpected peak %+v", p)
+	}
+}
+
+func TestUpdateReplicationThrottleCalibration(t *testing.T) {
+	zkWriteInterval = 0
+
+	path := api.NewZnodePaths("autothrottle").Peaks
+
+	now := time.Unix(86400, 0)
+
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	zk.Create(path, "")
+
+	// Broker 1000 was previously observed sending 300MB/s, above the 200MB/s
+	// configured capacity. Broker 1002's peak has expired.
+	throttlestore.StoreBrokerPeak(zk, path, 1000, throttlestore.BrokerPeak{
+		InstanceType: "stub",
+		Samples:      []throttlestore.PeakSample{{Start: now.Add(-time.Hour).Unix(), TX: 300, RX: 100}},
+	})
+	throttlestore.StoreBrokerPeak(zk, path, 1002, throttlestore.BrokerPeak{
+		InstanceType: "stub",
+		Samples:      []throttlestore.PeakSample{{Start: now.Add(-48 * time.Hour).Unix(), TX: 300, RX: 300}},
+	})
+
+	km := kafkametrics.NewStub()
+	km.Script(kafkametrics.StubResponse{Metrics: stubBrokerMetrics()})
+	tm := newTestThrottleManager(t, zk, km)
+	tm.calibration = capacityCalibration{window: 24 * time.Hour, now: func() time.Time { return now }}
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1000: (300-80)*0.9; 1002: (200-80)*0.9.
+	got := brokerConfigUpdates(zk)
+	for id, rate := range map[string]string{"1000": "198000000", "1002": "108000000"} {
+		if r := got[id]["leader.replication.throttled.rate"]; r != rate {
+			t.Errorf("Expected broker %s leader rate %s, got %s", id, rate, r)
+		}
+	}
+
+	// The observed throughput is recorded for each broker.
+	peaks, err := throttlestore.FetchBrokerPeaks(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(peaks) != len(stubBrokerMetrics()) {
+		t.Errorf("Expected peaks for all brokers, got %v", peaks)
+	}
+
+	if s := peaks[1000].Samples; len(s) != 2 || s[1].Start != now.Unix() || s[1].TX != 80 {
+		t.Errorf("Unexpected samples for broker 1000: %+v", s)
+	}
+
+	if s := peaks[1002].Samples; len(s) != 1 || s[0].Start != now.Unix() {
+		t.Errorf("Unexpected samples for broker 1002: %+v", s)
+	}
+}
@@ -0,0 +1,53 @@
+package replication
+
+import (
+	"sort"
+)
+
+// ClearCancelledThrottles takes the topics of cancelled reassignments and
+// removes their topic throttle configs, along with the broker throttles set
+// by autothrottle on any brokers that no longer participate in a reassignment.
+// Topics that are reassigning again and brokers with overrides or pinned
+// throttles are left in place. The topics and broker IDs cleared are returned.
+func (tm *ThrottleManager) ClearCancelledThrottles(topics []string) ([]string, []int, error) {
+	var cleared []string
+	for _, t := range topics {
+		if _, reassigning := tm.reassigningBrokers.throttledReplicas[Topic(t)]; !reassigning {
+			cleared = append(cleared, t)
+		}
+	}
+
+	ids := map[int]struct{}{}
+	for id := range tm.previouslySetThrottles {
+		if _, reassigning := tm.reassigningBrokers.all[id]; reassigning {
+			continue
+		}
+		if override, exists := tm.brokerOverrides[id]; exists && override.Config.Rate != 0 {
+			continue
+		}
+		ids[id] = struct{}{}
+	}
+
+	if len(cleared) > 0 {
+		if err := tm.removeTopicThrottlesByName(cleared); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var brokers []int
+	if len(ids) > 0 {
+		if err := tm.removeBrokerThrottlesByID(ids, ReasonCancelled); err != nil {
+			return cleared, nil, err
+		}
+
+		for id := range ids {
+			delete(tm.previouslySetThrottles, id)
+			brokers = append(brokers, id)
+		}
+		sort.Ints(brokers)
+	}
+
+	sort.Strings(cleared)
+
+	return cleared, brokers, nil
+}
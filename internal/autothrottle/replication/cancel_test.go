@@ -0,0 +1,59 @@
+package replication
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestClearCancelledThrottles(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	tm := newTestThrottleManager(t, zk, kafkametrics.NewStub())
+
+	rate := 100.0
+	tm.previouslySetThrottles = ReplicationCapacityByBroker{
+		1000: {&rate, &rate},
+		2001: {&rate, &rate},
+		2002: {&rate, &rate},
+	}
+	tm.brokerOverrides = throttlestore.BrokerOverrides{
+		2002: throttlestore.BrokerThrottleOverride{
+			ID:     2002,
+			Config: throttlestore.ThrottleOverrideConfig{Rate: 50},
+		},
+	}
+
+	zk.ResetKafkaConfigUpdates()
+
+	// reassigning_topic is still reassigning and is left in place.
+	topics, brokers, err := tm.ClearCancelledThrottles([]string{"reassigning_topic", "cancelled_topic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"cancelled_topic"}; !reflect.DeepEqual(topics, expected) {
+		t.Errorf("Expected cleared topics %v, got %v", expected, topics)
+	}
+
+	// 1000 is reassigning and 2002 has an override.
+	if expected := []int{2001}; !reflect.DeepEqual(brokers, expected) {
+		t.Errorf("Expected cleared brokers %v, got %v", expected, brokers)
+	}
+
+	if _, exists := tm.previouslySetThrottles[2001]; exists {
+		t.Error("Expected broker 2001 to be removed from previously set throttles")
+	}
+
+	updated := map[string]bool{}
+	for _, c := range zk.KafkaConfigUpdates() {
+		updated[c.Type+"/"+c.Name] = true
+	}
+
+	expected := map[string]bool{"topic/cancelled_topic": true, "broker/2001": true}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Errorf("Expected config updates %v, got %v", expected, updated)
+	}
+}
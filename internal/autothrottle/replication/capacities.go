@@ -2,6 +2,9 @@ package replication
 
 import (
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
 )
@@ -48,6 +51,34 @@ func (r ReplicationCapacityByBroker) setAllRatesWithDefault(ids []int, rate floa
 	}
 }
 
+// String returns the rates as a list of [ID, leader rate, follower rate]
+// sorted by broker ID. Roles without a rate are shown as "-".
+func (r ReplicationCapacityByBroker) String() string {
+	var ids []int
+	for id := range r {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var b strings.Builder
+	for i, id := range ids {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		rates := [2]string{"-", "-"}
+		for n, rate := range r[id] {
+			if rate != nil {
+				rates[n] = fmt.Sprintf("%.2f", *rate)
+			}
+		}
+
+		fmt.Fprintf(&b, "[%d, %s, %s]", id, rates[0], rates[1])
+	}
+
+	return b.String()
+}
+
 func (r ReplicationCapacityByBroker) reset() {
 	for id := range r {
 		delete(r, id)
@@ -60,6 +91,9 @@ func (r ReplicationCapacityByBroker) reset() {
 // accordingly, returning a ReplicationCapacityByBroker and error.
 func brokerReplicationCapacities(rtc *ThrottleManager, reassigning reassigningBrokers, bm kafkametrics.BrokerMetrics) (ReplicationCapacityByBroker, error) {
 	capacities := ReplicationCapacityByBroker{}
+	headroom := ReplicationCapacityByBroker{}
+	// Brokers using the default capacity, mapped to their instance type.
+	fallback := map[int]string{}
 
 	// For each broker, check whether the it's a source and/or destination,
 	// calculating and storing the throttle for each.
@@ -72,6 +106,21 @@ func brokerReplicationCapacities(rtc *ThrottleManager, reassigning reassigningBr
 			return capacities, fmt.Errorf("Broker %d not found in broker metrics", ID)
 		}
 
+		// The capacity raised to the broker's observed peaks, if calibrated.
+		capacity, calibrated := rtc.calibratedCapacity(broker)
+
+		// Brokers with an instance type missing from the capacity map use
+		// their tagged capacity or otherwise the default capacity, if
+		// configured.
+		var useTagged, useDefault bool
+		if _, known := rtc.limits.Capacity(broker.InstanceType); !known {
+			if broker.Capacity > 0 {
+				capacity, useTagged = Capacity{TX: broker.Capacity, RX: broker.Capacity}, true
+			} else if capacity, useDefault = rtc.limits.defaultCapacity(); useDefault {
+				fallback[ID] = broker.InstanceType
+			}
+		}
+
 		// We're traversing brokers from 'all', but a broker's role is either
 		// a leader, a follower, or both. If it's exclusively one, we can
 		// skip throttle computation for that role type for the broker.
@@ -99,12 +148,35 @@ func brokerReplicationCapacities(rtc *ThrottleManager, reassigning reassigningBr
 				currThrottle = 0.00
 			}
 
+			// Brokers exclusively handling replication factor increases or
+			// replicating across racks in this role use the respective maximums.
+			limits, _ := reassigning.limits(rtc.limits, ID, role)
+			if calibrated || useTagged || useDefault {
+				limits = limits.withCapacity(broker.InstanceType, capacity)
+			}
+
 			// Calc. and store the rate.
-			rate, err := rtc.limits.replicationHeadroom(broker, role, currThrottle)
+			rate, err := limits.replicationHeadroom(broker, role, currThrottle)
 			if err != nil {
 				return capacities, err
 			}
 
+			switch role {
+			case "leader":
+				headroom.storeLeaderCapacity(ID, rate)
+			case "follower":
+				headroom.storeFollowerCapacity(ID, rate)
+			}
+
+			// Ensure each partition moving on the broker gets the per-partition
+			// minimum, if configured.
+			partitions := reassigning.movingPartitions(ID, role)
+			if estimate := limits.partitionEstimate(broker, role, rate, partitions); estimate > rate {
+				log.Printf("Raising the %s rate for broker %d from %.2fMB/s to %.2fMB/s for %d moving partitions\n",
+					role, ID, rate, estimate, partitions)
+				rate = estimate
+			}
+
 			switch role {
 			case "leader":
 				capacities.storeLeaderCapacity(ID, rate)
@@ -114,5 +186,61 @@ func brokerReplicationCapacities(rtc *ThrottleManager, reassigning reassigningBr
 		}
 	}
 
+	// Distribute the cluster budget, if configured.
+	if budget, exists := rtc.limits["clusterMax"]; exists {
+		if rtc.fairShare {
+			weights := reassigning.brokerWeights(rtc.topicWeight)
+			for i, total := range capacities.applyFairShare(budget, weights) {
+				log.Printf("Total %s replication rate of %.2fMB/s exceeds the cluster budget of %.2fMB/s, allocating %s rates by topic priority\n",
+					roleFromIndex(i), total, budget, roleFromIndex(i))
+			}
+		} else {
+			for i, total := range capacities.applyBudget(budget) {
+				log.Printf("Total %s replication rate of %.2fMB/s exceeds the cluster budget of %.2fMB/s, scaling %s rates by %.2f\n",
+					roleFromIndex(i), total, budget, roleFromIndex(i), budget/total)
+			}
+		}
+	}
+
+	rtc.setFallbackCapacityBrokers(fallback)
+	rtc.headroom = headroom
+
 	return capacities, nil
 }
+
+// applyBudget takes a total replication rate budget in MB/s and scales down
+// the rates for each role such that their sum doesn't exceed the budget. Rates
+// are scaled proportionally, so that each broker keeps the same share of the
+// total. A map of role index to the total rate prior to scaling is returned
+// for each role that exceeded the budget.
+func (r ReplicationCapacityByBroker) applyBudget(budget float64) map[int]float64 {
+	var totals [2]float64
+	for _, rates := range r {
+		for i, rate := range rates {
+			if rate != nil {
+				totals[i] += *rate
+			}
+		}
+	}
+
+	exceeded := map[int]float64{}
+
+	for i, total := range totals {
+		if total <= budget {
+			continue
+		}
+
+		exceeded[i] = total
+		scale := budget / total
+
+		for id, rates := range r {
+			if rates[i] != nil {
+				scaled := *rates[i] * scale
+				rates[i] = &scaled
+				r[id] = rates
+			}
+		}
+	}
+
+	return exceeded
+}
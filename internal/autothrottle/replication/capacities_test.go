@@ -3,6 +3,7 @@ package replication
 import (
 	"testing"
 
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
 )
 
@@ -15,41 +16,282 @@ func TestBrokerReplicationCapacities(t *testing.T) {
 		Minimum:            20,
 		SourceMaximum:      90,
 		DestinationMaximum: 80,
-		CapacityMap:        map[string]float64{"stub": 200.00},
+		CapacityMap:        CapacityMap{"stub": {TX: 200, RX: 200}},
 	})
 
+	// Metrics where broker 1010 is missing.
+	partialMetrics := stubBrokerMetrics()
+	delete(partialMetrics, 1010)
+
+	tests := []struct {
+		name      string
+		previous  ReplicationCapacityByBroker
+		metrics   kafkametrics.BrokerMetrics
+		expected  map[int][2]*float64
+		expectErr bool
+	}{
+		{
+			name:     "no previous throttles",
+			previous: ReplicationCapacityByBroker{},
+			metrics:  stubBrokerMetrics(),
+			expected: map[int][2]*float64{
+				1000: {float64ptr(108.00), nil},
+				1002: {float64ptr(108.00), nil},
+				1003: {nil, float64ptr(96.00)},
+				1005: {nil, float64ptr(20.00)},
+				1010: {nil, float64ptr(64.00)},
+			},
+		},
+		{
+			// The previous throttle is excluded from the utilization
+			// consuming headroom.
+			name:     "previous throttle",
+			previous: ReplicationCapacityByBroker{1000: ThrottleByRole{float64ptr(20)}},
+			metrics:  stubBrokerMetrics(),
+			expected: map[int][2]*float64{
+				1000: {float64ptr(126.00), nil},
+				1002: {float64ptr(108.00), nil},
+				1003: {nil, float64ptr(96.00)},
+				1005: {nil, float64ptr(20.00)},
+				1010: {nil, float64ptr(64.00)},
+			},
+		},
+		{
+			name:      "missing broker metrics",
+			previous:  ReplicationCapacityByBroker{},
+			metrics:   partialMetrics,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		rtc := &ThrottleManager{
+			reassignments:          reassignments,
+			previouslySetThrottles: test.previous,
+			limits:                 lim,
+		}
+
+		brc, err := brokerReplicationCapacities(rtc, reassigningBrokers, test.metrics)
+
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("[%s] Expected non-nil error", test.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("[%s] Unexpected error: %s", test.name, err)
+			continue
+		}
+
+		if len(brc) != len(test.expected) {
+			t.Errorf("[%s] Expected %d brokers, got %d", test.name, len(test.expected), len(brc))
+		}
+
+		for id, expected := range test.expected {
+			for i := range expected {
+				role := roleFromIndex(i)
+				got := brc[id][i]
+
+				switch {
+				case expected[i] == nil && got != nil:
+					t.Errorf("[%s] Expected nil rate, got %.2f for ID %d role %s", test.name, *got, id, role)
+				case expected[i] != nil && got == nil:
+					t.Errorf("[%s] Expected rate %.2f, got nil for ID %d role %s", test.name, *expected[i], id, role)
+				case expected[i] != nil && *got != *expected[i]:
+					t.Errorf("[%s] Expected rate %.2f, got %.2f for ID %d role %s",
+						test.name, *expected[i], *got, id, role)
+				}
+			}
+		}
+	}
+}
+
+func TestBrokerReplicationCapacitiesDefaultCapacity(t *testing.T) {
+	zk := &kafkazk.Stub{}
+	reassignments := zk.GetReassignments()
+	reassigningBrokers, _ := GetReassigningBrokers(reassignments, zk)
+
+	cfg := NewLimitsConfig{
+		Minimum:            20,
+		SourceMaximum:      90,
+		DestinationMaximum: 80,
+		CapacityMap:        CapacityMap{"stub": {TX: 200, RX: 200}},
+	}
+
+	// Broker 1003 has an instance type missing from the capacity map.
+	metrics := stubBrokerMetrics()
+	metrics[1003].InstanceType = "i3.xlarge"
+
+	// Without a default capacity, throttles can't be determined.
+	lim, _ := NewLimits(cfg)
 	rtc := &ThrottleManager{
 		reassignments:          reassignments,
-		previouslySetThrottles: ReplicationCapacityByBroker{1000: ThrottleByRole{float64ptr(20)}},
+		previouslySetThrottles: ReplicationCapacityByBroker{},
+		limits:                 lim,
+	}
+
+	if _, err := brokerReplicationCapacities(rtc, reassigningBrokers, metrics); err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	cfg.DefaultCapacity = 200
+	lim, _ = NewLimits(cfg)
+	events := &eventsStub{}
+	rtc = &ThrottleManager{
+		reassignments:          reassignments,
+		previouslySetThrottles: ReplicationCapacityByBroker{},
 		limits:                 lim,
+		events:                 events,
 	}
 
-	bm := stubBrokerMetrics()
-	brc, _ := brokerReplicationCapacities(rtc, reassigningBrokers, bm)
+	for i := 0; i < 2; i++ {
+		brc, err := brokerReplicationCapacities(rtc, reassigningBrokers, metrics)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-	expected := map[int][2]*float64{
-		1000: {float64ptr(126.00), nil},
-		1002: {float64ptr(108.00), nil},
-		1003: {nil, float64ptr(96.00)},
-		1005: {nil, float64ptr(20.00)},
-		1010: {nil, float64ptr(64.00)},
+		if rate := brc[1003][1]; rate == nil || *rate != 96.00 {
+			t.Errorf("Expected rate 96.00 for ID 1003 role follower, got %v", rate)
+		}
 	}
 
-	for id, got := range brc {
-		for i := range got {
-			role := roleFromIndex(i)
+	if brokers := rtc.FallbackCapacityBrokers(); len(brokers) != 1 || brokers[1003] != "i3.xlarge" {
+		t.Errorf("Expected broker 1003 using the default capacity, got %v", brokers)
+	}
+
+	// The event is written as the broker starts using the default capacity.
+	if len(events.titles) != 1 || events.titles[0] != "Default broker capacity applied" {
+		t.Errorf("Expected a default capacity event, got %v", events.titles)
+	}
 
-			if got[i] == nil {
-				if expected[id][i] != nil {
-					t.Errorf("Expected rate %.2f, got nil for ID %d role %s", *expected[id][i], id, role)
+	// Once the instance type is known, the default capacity isn't used.
+	metrics[1003].InstanceType = "stub"
+	if _, err := brokerReplicationCapacities(rtc, reassigningBrokers, metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	if brokers := rtc.FallbackCapacityBrokers(); len(brokers) != 0 {
+		t.Errorf("Expected no brokers using the default capacity, got %v", brokers)
+	}
+}
+
+func TestBrokerReplicationCapacitiesTaggedCapacity(t *testing.T) {
+	zk := &kafkazk.Stub{}
+	reassignments := zk.GetReassignments()
+	reassigningBrokers, _ := GetReassigningBrokers(reassignments, zk)
+
+	lim, _ := NewLimits(NewLimitsConfig{
+		Minimum:            20,
+		SourceMaximum:      90,
+		DestinationMaximum: 80,
+		CapacityMap:        CapacityMap{"stub": {TX: 200, RX: 200}},
+		DefaultCapacity:    200,
+	})
+
+	rtc := &ThrottleManager{
+		reassignments:          reassignments,
+		previouslySetThrottles: ReplicationCapacityByBroker{},
+		limits:                 lim,
+		events:                 &eventsStub{},
+	}
+
+	// Broker 1003 has an instance type missing from the capacity map and a
+	// tagged capacity, which takes precedence over the default capacity.
+	metrics := stubBrokerMetrics()
+	metrics[1003].InstanceType = "i3.xlarge"
+	metrics[1003].Capacity = 400
+
+	brc, err := brokerReplicationCapacities(rtc, reassigningBrokers, metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rate := brc[1003][1]; rate == nil || *rate != 256.00 {
+		t.Errorf("Expected rate 256.00 for ID 1003 role follower, got %v", rate)
+	}
+
+	if brokers := rtc.FallbackCapacityBrokers(); len(brokers) != 0 {
+		t.Errorf("Expected no brokers using the default capacity, got %v", brokers)
+	}
+
+	// The cap-map takes precedence over tagged capacities.
+	metrics[1003].InstanceType = "stub"
+
+	brc, err = brokerReplicationCapacities(rtc, reassigningBrokers, metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rate := brc[1003][1]; rate == nil || *rate != 96.00 {
+		t.Errorf("Expected rate 96.00 for ID 1003 role follower, got %v", rate)
+	}
+}
+
+func TestBrokerReplicationCapacitiesPartitionMinimum(t *testing.T) {
+	zk := &kafkazk.Stub{}
+	reassignments := zk.GetReassignments()
+	reassigningBrokers, _ := GetReassigningBrokers(reassignments, zk)
+
+	// Each broker is moving a single partition.
+	for _, id := range []int{1000, 1002, 1003, 1005, 1010} {
+		role := ReplicaType("follower")
+		if id == 1000 || id == 1002 {
+			role = "leader"
+		}
+		if n := reassigningBrokers.movingPartitions(id, role); n != 1 {
+			t.Errorf("Expected 1 moving partition for ID %d role %s, got %d", id, role, n)
+		}
+	}
+
+	tests := []struct {
+		partitionMin float64
+		expected     map[int][2]float64
+	}{
+		// Only 1005, with little headroom, is raised to the minimum.
+		{50, map[int][2]float64{1000: {108.00}, 1003: {0, 96.00}, 1005: {0, 50.00}, 1010: {0, 64.00}}},
+		// Raised rates are capped at the max portion of capacity.
+		{200, map[int][2]float64{1000: {180.00}, 1003: {0, 160.00}, 1005: {0, 160.00}, 1010: {0, 160.00}}},
+	}
+
+	for _, test := range tests {
+		lim, _ := NewLimits(NewLimitsConfig{
+			Minimum:            20,
+			PartitionMinimum:   test.partitionMin,
+			SourceMaximum:      90,
+			DestinationMaximum: 80,
+			CapacityMap:        CapacityMap{"stub": {TX: 200, RX: 200}},
+		})
+
+		rtc := &ThrottleManager{
+			reassignments:          reassignments,
+			previouslySetThrottles: ReplicationCapacityByBroker{},
+			limits:                 lim,
+		}
+
+		brc, err := brokerReplicationCapacities(rtc, reassigningBrokers, stubBrokerMetrics())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for id, expected := range test.expected {
+			for i, rate := range expected {
+				if rate == 0 {
+					continue
+				}
+				if got := brc[id][i]; got == nil || *got != rate {
+					t.Errorf("[partition min %.0f] Expected rate %.2f for ID %d role %s, got %v",
+						test.partitionMin, rate, id, roleFromIndex(i), got)
 				}
-				continue
 			}
+		}
 
-			if *got[i] != *expected[id][i] {
-				t.Errorf("Expected rate %.2f, got %.2f for ID %d role %s",
-					*expected[id][i], *got[i], id, role)
-			}
+		// The headroom is recorded prior to raising rates to the minimum.
+		headroom := rtc.Headroom()
+		if h := headroom[1005][1]; h == nil || *h >= 50 {
+			t.Errorf("[partition min %.0f] Expected headroom under 50.00 for ID 1005 role follower, got %v",
+				test.partitionMin, h)
 		}
 	}
 }
@@ -124,3 +366,39 @@ func TestReset(t *testing.T) {
 		t.Errorf("Expected len 0, got %d", len(capacities))
 	}
 }
+
+func TestCapacitiesString(t *testing.T) {
+	capacities := ReplicationCapacityByBroker{}
+	capacities.storeFollowerCapacity(1002, 50)
+	capacities.storeLeaderAndFollerCapacity(1001, 100)
+
+	expected := "[1001, 100.00, 100.00], [1002, -, 50.00]"
+	if s := capacities.String(); s != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, s)
+	}
+}
+
+func TestApplyBudget(t *testing.T) {
+	capacities := ReplicationCapacityByBroker{}
+	capacities.storeLeaderAndFollerCapacity(1001, 100)
+	capacities.storeLeaderCapacity(1002, 300)
+	capacities.storeFollowerCapacity(1003, 50)
+
+	// Leader rates total 400 and are scaled by 0.5; follower rates total 150
+	// and are within the budget.
+	exceeded := capacities.applyBudget(200)
+
+	if len(exceeded) != 1 || exceeded[0] != 400 {
+		t.Errorf("Expected leader total 400 to exceed the budget, got %v", exceeded)
+	}
+
+	expected := "[1001, 50.00, 100.00], [1002, 150.00, -], [1003, -, 50.00]"
+	if s := capacities.String(); s != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, s)
+	}
+
+	// Nothing exceeds a larger budget.
+	if exceeded := capacities.applyBudget(1000); len(exceeded) != 0 {
+		t.Errorf("Expected no totals to exceed the budget, got %v", exceeded)
+	}
+}
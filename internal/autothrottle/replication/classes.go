@@ -0,0 +1,147 @@
+package replication
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TopicClass is a topic priority class.
+type TopicClass string
+
+// Topic priority classes. Topics not matching any class are normal priority.
+const (
+	ClassHigh   TopicClass = "high"
+	ClassNormal TopicClass = "normal"
+	ClassLow    TopicClass = "low"
+)
+
+// topicClassOrder is the order in which classes are matched; a topic matching
+// several classes is assigned the first.
+var topicClassOrder = []TopicClass{ClassHigh, ClassNormal, ClassLow}
+
+// classWeights are the fair-share weight multipliers for each class, relative
+// to normal priority.
+var classWeights = map[TopicClass]float64{
+	ClassHigh:   2,
+	ClassNormal: 1,
+	ClassLow:    0.5,
+}
+
+// TopicClasses maps topic priority classes to the regex matching the topics in
+// each class.
+type TopicClasses map[TopicClass]*regexp.Regexp
+
+// NewTopicClasses takes a map of class name (high, normal, low) to topic name
+// regex and returns a TopicClasses.
+func NewTopicClasses(m map[string]string) (TopicClasses, error) {
+	tc := TopicClasses{}
+
+	for name, pattern := range m {
+		class := TopicClass(name)
+		if _, exists := classWeights[class]; !exists {
+			return nil, fmt.Errorf("invalid topic class '%s': must be one of high, normal, low", name)
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for topic class '%s': %s", name, err)
+		}
+
+		tc[class] = re
+	}
+
+	return tc, nil
+}
+
+// classOf returns the TopicClass for topic t.
+func (tc TopicClasses) classOf(t string) TopicClass {
+	for _, class := range topicClassOrder {
+		if re, exists := tc[class]; exists && re.MatchString(t) {
+			return class
+		}
+	}
+
+	return ClassNormal
+}
+
+// weight returns the fair-share weight multiplier for topic t.
+func (tc TopicClasses) weight(t string) float64 {
+	return classWeights[tc.classOf(t)]
+}
+
+// ClassAllocation is a mapping of topic class to the outbound and inbound
+// replication bandwidth allocated to topics in the class.
+type ClassAllocation map[TopicClass][2]float64
+
+// String returns a string representation of the ClassAllocation in the form
+// of [class, leader, follower] for each class with an allocation.
+func (ca ClassAllocation) String() string {
+	var b strings.Builder
+
+	for _, class := range topicClassOrder {
+		rates, exists := ca[class]
+		if !exists {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+
+		fmt.Fprintf(&b, "[%s, %.2f, %.2f]", class, rates[0], rates[1])
+	}
+
+	return b.String()
+}
+
+// classAllocation attributes the broker rates in capacities to the reassigning
+// topics and returns the total per topic class. Each broker's rate is divided
+// among the topics it's handling in proportion to their fair-share weights.
+func (bm reassigningBrokers) classAllocation(capacities ReplicationCapacityByBroker, topicWeight func(string) float64, tc TopicClasses) ClassAllocation {
+	ca := ClassAllocation{}
+	weights := bm.brokerWeights(topicWeight)
+
+	// Iterate topics in a stable order so that totals are deterministic.
+	topics := bm.throttledReplicas.topics()
+	sort.Strings(topics)
+
+	for _, topic := range topics {
+		class := tc.classOf(topic)
+		rates := ca[class]
+		w := topicWeight(topic)
+
+		for i, ids := range bm.topicBrokers(Topic(topic)) {
+			for id := range ids {
+				rate := capacities[id][i]
+				if rate == nil || weights[i][id] <= 0 {
+					continue
+				}
+				rates[i] += *rate * (w / float64(len(ids))) / weights[i][id]
+			}
+		}
+
+		ca[class] = rates
+	}
+
+	return ca
+}
+
+// topicBrokers returns the set of brokers handling topic t in each of the
+// leader and follower roles.
+func (bm reassigningBrokers) topicBrokers(t Topic) [2]map[int]struct{} {
+	brokers := [2]map[int]struct{}{{}, {}}
+
+	for i, role := range []ReplicaType{"leaders", "followers"} {
+		for _, replica := range bm.throttledReplicas[t][role] {
+			// Replicas are in the partition:broker ID form.
+			if id, err := strconv.Atoi(replica[strings.Index(replica, ":")+1:]); err == nil {
+				brokers[i][id] = struct{}{}
+			}
+		}
+	}
+
+	return brokers
+}
@@ -0,0 +1,70 @@
+package replication
+
+import (
+	"testing"
+)
+
+func TestNewTopicClasses(t *testing.T) {
+	tc, err := NewTopicClasses(map[string]string{"high": "^orders", "low": "^logs-|orders-debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]TopicClass{
+		"orders":       ClassHigh,
+		"orders-debug": ClassHigh, // High takes precedence.
+		"logs-app":     ClassLow,
+		"clicks":       ClassNormal,
+	}
+
+	for topic, expected := range tests {
+		if class := tc.classOf(topic); class != expected {
+			t.Errorf("[%s] Expected class %s, got %s", topic, expected, class)
+		}
+	}
+
+	if _, err := NewTopicClasses(map[string]string{"urgent": ".*"}); err == nil {
+		t.Error("Expected non-nil error for an invalid class")
+	}
+
+	if _, err := NewTopicClasses(map[string]string{"high": "("}); err == nil {
+		t.Error("Expected non-nil error for an invalid regex")
+	}
+
+	// Topics are normal priority without classes.
+	if class := TopicClasses(nil).classOf("orders"); class != ClassNormal {
+		t.Errorf("Expected class normal, got %s", class)
+	}
+}
+
+func TestClassAllocation(t *testing.T) {
+	tc, _ := NewTopicClasses(map[string]string{"high": "^a$"})
+	tm := &ThrottleManager{topicClasses: tc}
+
+	rb := reassigningBrokers{
+		throttledReplicas: TopicThrottledReplicas{
+			"a": Throttled{
+				"leaders":   BrokerIDs{"0:1001"},
+				"followers": BrokerIDs{"0:1003"},
+			},
+			"b": Throttled{
+				"leaders":   BrokerIDs{"0:1001", "1:1002"},
+				"followers": BrokerIDs{"0:1003"},
+			},
+		},
+	}
+
+	capacities := ReplicationCapacityByBroker{}
+	capacities.storeLeaderCapacity(1001, 100)
+	capacities.storeLeaderCapacity(1002, 50)
+	capacities.storeFollowerCapacity(1003, 90)
+
+	// 1001 has weights of 2 for a and 0.5 for b, so a is attributed 80 and b
+	// 20 of its rate. 1003 has weights of 2 for a and 1 for b.
+	ca := rb.classAllocation(capacities, tm.topicWeight, tm.topicClasses)
+
+	expected := "[high, 80.00, 60.00], [normal, 70.00, 30.00]"
+	if s := ca.String(); s != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, s)
+	}
+}
@@ -0,0 +1,89 @@
+package replication
+
+import (
+	"time"
+)
+
+// Decision reasons.
+const (
+	// The rate was determined from broker metrics and the configured limits.
+	ReasonComputed = "computed"
+	// The global throttle override rate was applied.
+	ReasonGlobalOverride = "global_override"
+	// A broker throttle override was applied or removed.
+	ReasonBrokerOverride = "broker_override"
+	// A pinned broker throttle was applied.
+	ReasonPinned = "pinned"
+	// The safe minimum rates were applied while the guardrails are tripped.
+	ReasonGuardrails = "guardrails_tripped"
+	// The minimum rates were applied after exceeding the metrics failure
+	// threshold.
+	ReasonMetricsFailure = "metrics_failure"
+	// The minimum rates were applied to a broker replicating to a reassignment
+	// destination broker that was lost.
+	ReasonBrokerLost = "broker_lost"
+	// The throttle was removed once no longer needed, e.g. after reassignments
+	// completed.
+	ReasonRemoved = "removed"
+	// The throttle was removed after the reassignment was cancelled.
+	ReasonCancelled = "reassignment_cancelled"
+)
+
+// Decision is a change to the replication throttle rate of a broker role.
+type Decision struct {
+	Time   time.Time `json:"time"`
+	Broker int       `json:"broker"`
+	// Either "leader" or "follower".
+	Role string `json:"role"`
+	// The rates in MB/s. The old rate is 0 if there was no throttle known to be
+	// set by autothrottle; the new rate is 0 if the throttle was removed.
+	OldRate float64 `json:"old_rate"`
+	NewRate float64 `json:"new_rate"`
+	Reason  string  `json:"reason"`
+}
+
+// DecisionWriter writes throttle Decisions.
+type DecisionWriter interface {
+	WriteDecision(Decision)
+}
+
+// writeDecision writes a Decision for a brokerChangeEvent, if a DecisionWriter
+// is configured.
+func (tm *ThrottleManager) writeDecision(e brokerChangeEvent, reason string) {
+	if tm.decisions == nil {
+		return
+	}
+
+	tm.decisions.WriteDecision(Decision{
+		Time:    time.Now(),
+		Broker:  e.id,
+		Role:    e.role,
+		OldRate: e.prev,
+		NewRate: e.rate,
+		Reason:  reason,
+	})
+}
+
+// writeRemovalDecisions writes a Decision for each role of the brokers in
+// prev that had a throttle rate set. Brokers without a throttle known to be
+// set by autothrottle are skipped, as all brokers are cleared once
+// reassignments complete.
+func (tm *ThrottleManager) writeRemovalDecisions(ids []int, prev ReplicationCapacityByBroker, reason string) {
+	for _, id := range ids {
+		for i, role := range []string{"leader", "follower"} {
+			if rate := prev[id][i]; rate != nil && *rate > 0 {
+				tm.writeDecision(brokerChangeEvent{id: id, role: role, prev: *rate}, reason)
+			}
+		}
+	}
+}
+
+// previousRate returns the throttle rate previously set by autothrottle for
+// the broker role, or 0 if none is known.
+func (tm *ThrottleManager) previousRate(id int, role int) float64 {
+	if rate := tm.previouslySetThrottles[id][role]; rate != nil {
+		return *rate
+	}
+
+	return 0
+}
@@ -0,0 +1,112 @@
+package replication
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// decisionsStub stubs a DecisionWriter, tracking written Decisions.
+type decisionsStub struct {
+	decisions []Decision
+}
+
+func (d *decisionsStub) WriteDecision(decision Decision) {
+	d.decisions = append(d.decisions, decision)
+}
+
+// byBrokerRole returns the written Decisions as "broker role" keys to
+// "old rate, new rate, reason" strings and resets the stub.
+func (d *decisionsStub) byBrokerRole() map[string]string {
+	got := map[string]string{}
+	for _, decision := range d.decisions {
+		key := fmt.Sprintf("%d %s", decision.Broker, decision.Role)
+		got[key] = fmt.Sprintf("%.0f, %.0f, %s", decision.OldRate, decision.NewRate, decision.Reason)
+	}
+
+	d.decisions = nil
+
+	return got
+}
+
+func TestDecisions(t *testing.T) {
+	zkWriteInterval = 0
+
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(kafkametrics.StubResponse{Metrics: stubBrokerMetrics()})
+
+	tm := newTestThrottleManager(t, zk, km)
+	decisions := &decisionsStub{}
+	tm.decisions = decisions
+
+	// Computed rates.
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"1000 leader":   "0, 108, computed",
+		"1002 leader":   "0, 108, computed",
+		"1003 follower": "0, 96, computed",
+		"1005 follower": "0, 20, computed",
+		"1010 follower": "0, 64, computed",
+	}
+
+	assertDecisions(t, "computed", expected, decisions.byBrokerRole())
+
+	// A global override, with a broker override for 1000.
+	tm.SetOverrideRate(50)
+	tm.SetBrokerOverrides(throttlestore.BrokerOverrides{
+		1000: {ID: 1000, Config: throttlestore.ThrottleOverrideConfig{Rate: 40}},
+	})
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = map[string]string{
+		"1000 leader":   "108, 40, broker_override",
+		"1000 follower": "0, 40, broker_override",
+		"1002 leader":   "108, 50, global_override",
+		"1002 follower": "0, 50, global_override",
+		"1003 leader":   "0, 50, global_override",
+		"1003 follower": "96, 50, global_override",
+		"1005 leader":   "0, 50, global_override",
+		"1005 follower": "20, 50, global_override",
+		"1010 leader":   "0, 50, global_override",
+		"1010 follower": "64, 50, global_override",
+	}
+
+	assertDecisions(t, "override", expected, decisions.byBrokerRole())
+
+	// Removals are written for throttles set by autothrottle.
+	tm.SetBrokerOverrides(nil)
+	if err := tm.removeBrokerThrottlesByID(map[int]struct{}{1002: {}, 1004: {}}, ReasonRemoved); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = map[string]string{
+		"1002 leader":   "50, 0, removed",
+		"1002 follower": "50, 0, removed",
+	}
+
+	assertDecisions(t, "removal", expected, decisions.byBrokerRole())
+}
+
+func assertDecisions(t *testing.T, name string, expected, got map[string]string) {
+	t.Helper()
+
+	if len(got) != len(expected) {
+		t.Errorf("[%s] Expected %d decisions, got %v", name, len(expected), got)
+	}
+
+	for key, e := range expected {
+		if got[key] != e {
+			t.Errorf("[%s] Expected decision %s: %s, got %s", name, key, e, got[key])
+		}
+	}
+}
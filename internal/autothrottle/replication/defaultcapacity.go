@@ -0,0 +1,56 @@
+package replication
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// setFallbackCapacityBrokers takes a map of broker ID to instance type for the
+// brokers using the default capacity. An event is written listing any brokers
+// that weren't using the default capacity as of the previous call.
+func (tm *ThrottleManager) setFallbackCapacityBrokers(brokers map[int]string) {
+	var added []int
+	for id, instanceType := range brokers {
+		if prev, exists := tm.fallbackCapacityBrokers[id]; !exists || prev != instanceType {
+			added = append(added, id)
+		}
+	}
+
+	tm.fallbackCapacityBrokers = brokers
+
+	if len(added) == 0 {
+		return
+	}
+
+	sort.Ints(added)
+
+	var entries []string
+	for _, id := range added {
+		instanceType := brokers[id]
+		if instanceType == "" {
+			instanceType = "unknown"
+		}
+		entries = append(entries, fmt.Sprintf("%d (%s)", id, instanceType))
+	}
+
+	c, _ := tm.limits.defaultCapacity()
+	m := fmt.Sprintf("Instance types of brokers %s have no configured capacity; using the default capacity of %.0fMB/s",
+		strings.Join(entries, ", "), c.TX)
+
+	log.Println(m)
+	tm.events.Write("Default broker capacity applied", m)
+}
+
+// FallbackCapacityBrokers returns a map of broker ID to instance type for the
+// brokers that used the default capacity in the most recent throttle
+// computation.
+func (tm *ThrottleManager) FallbackCapacityBrokers() map[int]string {
+	brokers := make(map[int]string, len(tm.fallbackCapacityBrokers))
+	for id, instanceType := range tm.fallbackCapacityBrokers {
+		brokers[id] = instanceType
+	}
+
+	return brokers
+}
@@ -0,0 +1,105 @@
+package replication
+
+import (
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+// topicWeight returns the fair-share weight for topic t; the topic's priority
+// weight multiplied by that of its class.
+func (tm *ThrottleManager) topicWeight(t string) float64 {
+	return float64(tm.topicPriorities.Weight(t)) * tm.topicClasses.weight(t)
+}
+
+// brokerWeights takes a func returning the fair-share weight for a topic and
+// returns, for each of the leader and follower roles, a map of broker ID to
+// the broker's weight in fair-share allocations. Each reassigning topic's
+// weight is divided evenly among the brokers handling it in the respective
+// role, such that a broker handling several topics accrues a share of each.
+func (bm reassigningBrokers) brokerWeights(topicWeight func(string) float64) [2]map[int]float64 {
+	weights := [2]map[int]float64{{}, {}}
+
+	for topic := range bm.throttledReplicas {
+		w := topicWeight(string(topic))
+
+		for i, ids := range bm.topicBrokers(topic) {
+			for id := range ids {
+				weights[i][id] += w / float64(len(ids))
+			}
+		}
+	}
+
+	return weights
+}
+
+// applyFairShare takes a total replication rate budget in MB/s and the broker
+// weights for each role, and lowers the rates for each role such that their
+// sum doesn't exceed the budget. Rather than scaling rates proportionally, the
+// budget is divided among brokers by weight; any share beyond what a broker is
+// able to use is redistributed to the remaining brokers. A map of role index
+// to the total rate prior to allocation is returned for each role that
+// exceeded the budget.
+func (r ReplicationCapacityByBroker) applyFairShare(budget float64, weights [2]map[int]float64) map[int]float64 {
+	exceeded := map[int]float64{}
+
+	for i := range weights {
+		// Brokers with a rate in this role that may receive a share.
+		var total float64
+		active := map[int]float64{}
+		for id, rates := range r {
+			if rates[i] == nil {
+				continue
+			}
+
+			total += *rates[i]
+
+			w := weights[i][id]
+			if w <= 0 {
+				w = throttlestore.DefaultPriority
+			}
+			active[id] = w
+		}
+
+		if total <= budget {
+			continue
+		}
+
+		exceeded[i] = total
+		remaining := budget
+
+		// Brokers whose rate is within their share keep their rate. This is
+		// repeated until all remaining brokers are able to use their share.
+		for len(active) > 0 {
+			var sum float64
+			for _, w := range active {
+				sum += w
+			}
+
+			// The budget per unit of weight in this pass.
+			perWeight := remaining / sum
+
+			var satisfied bool
+			for id, w := range active {
+				if rate := *r[id][i]; rate <= perWeight*w {
+					remaining -= rate
+					delete(active, id)
+					satisfied = true
+				}
+			}
+
+			if satisfied {
+				continue
+			}
+
+			for id, w := range active {
+				rates := r[id]
+				share := perWeight * w
+				rates[i] = &share
+				r[id] = rates
+			}
+
+			break
+		}
+	}
+
+	return exceeded
+}
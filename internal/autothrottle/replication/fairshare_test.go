@@ -0,0 +1,70 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+func TestBrokerWeights(t *testing.T) {
+	rb := reassigningBrokers{
+		throttledReplicas: TopicThrottledReplicas{
+			"a": Throttled{
+				"leaders":   BrokerIDs{"0:1001", "1:1001", "2:1002"},
+				"followers": BrokerIDs{"0:1003"},
+			},
+			"b": Throttled{
+				"leaders":   BrokerIDs{"0:1002"},
+				"followers": BrokerIDs{"0:1004"},
+			},
+		},
+	}
+
+	tp := throttlestore.TopicPriorities{"b": 3}
+	weights := rb.brokerWeights(func(t string) float64 { return float64(tp.Weight(t)) })
+
+	// Topic a has a default weight of 1 split among 1001 and 1002; 1002 also
+	// accrues the weight of topic b.
+	expected := [2]map[int]float64{
+		{1001: 0.5, 1002: 3.5},
+		{1003: 1, 1004: 3},
+	}
+
+	for i := range expected {
+		if len(weights[i]) != len(expected[i]) {
+			t.Errorf("[%s] Expected weights %v, got %v", roleFromIndex(i), expected[i], weights[i])
+		}
+		for id, w := range expected[i] {
+			if weights[i][id] != w {
+				t.Errorf("[%s] Expected broker %d weight %.2f, got %.2f", roleFromIndex(i), id, w, weights[i][id])
+			}
+		}
+	}
+}
+
+func TestApplyFairShare(t *testing.T) {
+	capacities := ReplicationCapacityByBroker{}
+	capacities.storeLeaderCapacity(1001, 100)
+	capacities.storeLeaderCapacity(1002, 100)
+	capacities.storeLeaderCapacity(1003, 10)
+	capacities.storeFollowerCapacity(1004, 50)
+
+	weights := [2]map[int]float64{
+		{1001: 3, 1002: 1, 1003: 1},
+		{1004: 1},
+	}
+
+	// The leader total of 210 exceeds the budget of 100. 1003 can't use its
+	// share of 20 and keeps its rate; the remaining 90 is split 3:1 between
+	// 1001 and 1002. The follower total is within the budget.
+	exceeded := capacities.applyFairShare(100, weights)
+
+	if len(exceeded) != 1 || exceeded[0] != 210 {
+		t.Errorf("Expected leader total 210 to exceed the budget, got %v", exceeded)
+	}
+
+	expected := "[1001, 67.50, -], [1002, 22.50, -], [1003, 10.00, -], [1004, -, 50.00]"
+	if s := capacities.String(); s != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, s)
+	}
+}
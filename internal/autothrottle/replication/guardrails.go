@@ -0,0 +1,200 @@
+package replication
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GuardrailsConfig configures cluster health checks that are evaluated each
+// interval. If any check exceeds its threshold, throttles for all reassigning
+// brokers are dropped to the configured minimum rate until the cluster recovers.
+type GuardrailsConfig struct {
+	// Whether guardrail checks are performed.
+	Enabled bool
+	// Max under-replicated partitions that are not part of an ongoing
+	// reassignment.
+	MaxUnderReplicated int
+	// Max offline partitions.
+	MaxOffline int
+	// Max partitions that had their ISR shrink since the previous check.
+	MaxISRShrinks int
+}
+
+// GuardrailsStatus holds the results of a guardrails check.
+type GuardrailsStatus struct {
+	UnderReplicated int
+	Offline         int
+	ISRShrinks      int
+	// Tripped is true if any check exceeded its threshold.
+	Tripped bool
+	// Reasons describes each check that was exceeded.
+	Reasons []string
+}
+
+// partitionHealth is a minimal, source agnostic view of a partition's state.
+type partitionHealth struct {
+	leader   int
+	replicas int
+	isr      int
+}
+
+// CheckGuardrails fetches the current partition states and evaluates them
+// against the configured GuardrailsConfig. Under-replicated partitions that
+// belong to an ongoing reassignment are excluded since they're expected to be
+// under-replicated. The guardrails state of the ThrottleManager is updated
+// according to the result; while tripped, UpdateReplicationThrottle applies
+// the minimum rate to all reassigning brokers.
+func (tm *ThrottleManager) CheckGuardrails() (GuardrailsStatus, error) {
+	var status GuardrailsStatus
+
+	if !tm.guardrails.Enabled {
+		return status, nil
+	}
+
+	states, err := tm.partitionHealthStates()
+	if err != nil {
+		return status, err
+	}
+
+	var isrSizes = make(map[string]int, len(states))
+
+	for key, state := range states {
+		isrSizes[key] = state.isr
+
+		if state.leader == -1 {
+			status.Offline++
+		}
+
+		// Partitions being reassigned are expected to have ISR changes.
+		if tm.isReassigning(key) {
+			continue
+		}
+
+		if state.isr < state.replicas {
+			status.UnderReplicated++
+		}
+
+		if prev, exists := tm.previousISRSizes[key]; exists && state.isr < prev {
+			status.ISRShrinks++
+		}
+	}
+
+	tm.previousISRSizes = isrSizes
+
+	// Evaluate the thresholds.
+	if status.UnderReplicated > tm.guardrails.MaxUnderReplicated {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d under-replicated partitions (max %d)",
+			status.UnderReplicated, tm.guardrails.MaxUnderReplicated))
+	}
+
+	if status.Offline > tm.guardrails.MaxOffline {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d offline partitions (max %d)",
+			status.Offline, tm.guardrails.MaxOffline))
+	}
+
+	if status.ISRShrinks > tm.guardrails.MaxISRShrinks {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d ISR shrinks since the last check (max %d)",
+			status.ISRShrinks, tm.guardrails.MaxISRShrinks))
+	}
+
+	status.Tripped = len(status.Reasons) > 0
+
+	// Write an event on state transitions only.
+	switch {
+	case status.Tripped && !tm.guardrailsTripped:
+		m := fmt.Sprintf("Cluster health guardrails tripped: %s. Replication throttles will be set to the configured minimum rates",
+			strings.Join(status.Reasons, ", "))
+		tm.events.WriteCritical("Cluster health guardrails tripped", m)
+	case !status.Tripped && tm.guardrailsTripped:
+		tm.events.Write("Cluster health guardrails cleared", "Cluster health checks are passing; resuming dynamic replication throttles")
+	}
+
+	tm.guardrailsTripped = status.Tripped
+
+	return status, nil
+}
+
+// GuardrailsTripped returns whether the most recent guardrails check exceeded
+// any thresholds.
+func (tm *ThrottleManager) GuardrailsTripped() bool {
+	return tm.guardrailsTripped
+}
+
+// isReassigning takes a topic:partition key and returns whether the partition
+// is part of an ongoing reassignment.
+func (tm *ThrottleManager) isReassigning(key string) bool {
+	i := strings.LastIndex(key, ":")
+	topic := key[:i]
+	partn, _ := strconv.Atoi(key[i+1:])
+
+	if _, exists := tm.reassignments[topic]; !exists {
+		return false
+	}
+
+	_, exists := tm.reassignments[topic][partn]
+	return exists
+}
+
+// partitionHealthStates returns a map of topic:partition to partitionHealth for
+// all partitions in the cluster.
+func (tm *ThrottleManager) partitionHealthStates() (map[string]partitionHealth, error) {
+	if !tm.kafkaNativeMode {
+		return tm.legacyPartitionHealthStates()
+	}
+
+	ctx, cancel := tm.kafkaRequestContext()
+	defer cancel()
+
+	tstates, err := tm.ka.DescribeTopics(ctx, []string{".*"})
+	if err != nil {
+		return nil, err
+	}
+
+	var states = make(map[string]partitionHealth)
+
+	for topic, tstate := range tstates {
+		for id, pstate := range tstate.PartitionStates {
+			states[fmt.Sprintf("%s:%d", topic, id)] = partitionHealth{
+				leader:   int(pstate.Leader),
+				replicas: len(pstate.Replicas),
+				isr:      len(pstate.ISR),
+			}
+		}
+	}
+
+	return states, nil
+}
+
+// legacyPartitionHealthStates is the ZooKeeper implementation of
+// partitionHealthStates.
+func (tm *ThrottleManager) legacyPartitionHealthStates() (map[string]partitionHealth, error) {
+	topics, err := tm.zk.GetTopics(topicsRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	var states = make(map[string]partitionHealth)
+
+	for _, topic := range topics {
+		configured, err := tm.zk.GetTopicState(topic)
+		if err != nil {
+			return nil, err
+		}
+
+		current, err := tm.zk.GetTopicStateISR(topic)
+		if err != nil {
+			return nil, err
+		}
+
+		for partn, state := range current {
+			states[fmt.Sprintf("%s:%s", topic, partn)] = partitionHealth{
+				leader:   state.Leader,
+				replicas: len(configured.Partitions[partn]),
+				isr:      len(state.ISR),
+			}
+		}
+	}
+
+	return states, nil
+}
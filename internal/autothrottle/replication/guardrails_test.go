@@ -0,0 +1,118 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin/stub"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// eventsStub stubs an EventWriter, tracking written event titles.
+type eventsStub struct {
+	titles         []string
+	criticalTitles []string
+}
+
+func (e *eventsStub) Write(t string, _ string) {
+	e.titles = append(e.titles, t)
+}
+
+func (e *eventsStub) WriteCritical(t string, _ string) {
+	e.criticalTitles = append(e.criticalTitles, t)
+}
+
+func TestCheckGuardrails(t *testing.T) {
+	events := &eventsStub{}
+	tm := &ThrottleManager{
+		kafkaNativeMode: true,
+		ka:              stub.NewClient(),
+		events:          events,
+		limits:          Limits{"minimum": 10},
+		guardrails: GuardrailsConfig{
+			Enabled: true,
+		},
+		previousISRSizes: map[string]int{},
+	}
+
+	// The stub topics are fully replicated and there's no previous state.
+	status, err := tm.CheckGuardrails()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Tripped {
+		t.Errorf("Unexpected guardrails trip: %v", status.Reasons)
+	}
+
+	// Simulate a larger ISR in the previous interval.
+	tm.previousISRSizes["test1:0"] = 3
+
+	status, err = tm.CheckGuardrails()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Tripped || status.ISRShrinks != 1 {
+		t.Errorf("Expected tripped guardrails with 1 ISR shrink, got %v with %d", status.Tripped, status.ISRShrinks)
+	}
+
+	if !tm.GuardrailsTripped() {
+		t.Error("Expected the ThrottleManager guardrails state to be tripped")
+	}
+
+	if len(events.criticalTitles) != 1 {
+		t.Errorf("Expected 1 critical event, got %d", len(events.criticalTitles))
+	}
+
+	// The next check should clear.
+	status, err = tm.CheckGuardrails()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Tripped || tm.GuardrailsTripped() {
+		t.Error("Expected guardrails to be cleared")
+	}
+
+	if len(events.titles) != 1 {
+		t.Errorf("Expected 1 cleared event, got %d", len(events.titles))
+	}
+}
+
+func TestCheckGuardrailsReassigning(t *testing.T) {
+	tm := &ThrottleManager{
+		kafkaNativeMode: true,
+		ka:              stub.NewClient(),
+		events:          &eventsStub{},
+		guardrails: GuardrailsConfig{
+			Enabled: true,
+		},
+		previousISRSizes: map[string]int{"test1:0": 3},
+		reassignments: kafkazk.Reassignments{
+			"test1": map[int][]int{0: {1001, 1002}},
+		},
+	}
+
+	// ISR changes for reassigning partitions are expected.
+	status, err := tm.CheckGuardrails()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Tripped {
+		t.Errorf("Unexpected guardrails trip: %v", status.Reasons)
+	}
+}
+
+func TestCheckGuardrailsDisabled(t *testing.T) {
+	tm := &ThrottleManager{}
+
+	status, err := tm.CheckGuardrails()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Tripped {
+		t.Error("Unexpected guardrails trip")
+	}
+}
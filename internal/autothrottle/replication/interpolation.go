@@ -0,0 +1,136 @@
+package replication
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// metricsObservation is a broker's metrics as of the time they were fetched.
+type metricsObservation struct {
+	broker kafkametrics.Broker
+	at     time.Time
+}
+
+// metricsInterpolation tracks the two most recent metrics observations of
+// each broker. When a reassigning broker is missing from a metrics fetch, e.g.
+// because of a brief metrics agent outage, its network utilization is
+// extrapolated from those observations rather than failing the throttle
+// update for all brokers.
+type metricsInterpolation struct {
+	// Observations older than maxAge aren't interpolated from. Interpolation is
+	// disabled if 0.
+	maxAge time.Duration
+	// The two most recent observations of each broker, oldest first.
+	history map[int][]metricsObservation
+	// Brokers with metrics interpolated in the most recent fetch.
+	interpolated []int
+	now          func() time.Time
+}
+
+func (m *metricsInterpolation) enabled() bool {
+	return m.maxAge > 0
+}
+
+// observe records the metrics of each broker in the kafkametrics.BrokerMetrics.
+func (m *metricsInterpolation) observe(bm kafkametrics.BrokerMetrics, at time.Time) {
+	if m.history == nil {
+		m.history = make(map[int][]metricsObservation)
+	}
+
+	for id, b := range bm {
+		h := append(m.history[id], metricsObservation{broker: *b, at: at})
+		if len(h) > 2 {
+			h = h[len(h)-2:]
+		}
+		m.history[id] = h
+	}
+}
+
+// estimate returns the metrics of the broker extrapolated from its previous
+// two observations to the time specified, and whether an estimate could be
+// made. No estimate is made if the broker has fewer than two observations or
+// the most recent is older than the maxAge.
+func (m *metricsInterpolation) estimate(id int, at time.Time) (*kafkametrics.Broker, bool) {
+	h := m.history[id]
+	if len(h) < 2 {
+		return nil, false
+	}
+
+	prev, last := h[0], h[1]
+	if at.Sub(last.at) > m.maxAge {
+		return nil, false
+	}
+
+	b := last.broker
+
+	if d := last.at.Sub(prev.at); d > 0 {
+		f := float64(at.Sub(last.at)) / float64(d)
+		b.NetTX = math.Max(last.broker.NetTX+(last.broker.NetTX-prev.broker.NetTX)*f, 0)
+		b.NetRX = math.Max(last.broker.NetRX+(last.broker.NetRX-prev.broker.NetRX)*f, 0)
+	}
+
+	return &b, true
+}
+
+// interpolateMetrics takes a list of broker IDs and the fetched
+// kafkametrics.BrokerMetrics and records the metrics of each broker. If
+// interpolation is enabled, a kafkametrics.BrokerMetrics is returned where
+// any of the brokers missing from the fetched metrics are interpolated from
+// their previous observations, where possible. Nothing is interpolated if no
+// metrics were fetched at all.
+func (tm *ThrottleManager) interpolateMetrics(ids []int, bm kafkametrics.BrokerMetrics) kafkametrics.BrokerMetrics {
+	m := &tm.interpolation
+	m.interpolated = nil
+
+	if !m.enabled() || bm == nil {
+		return bm
+	}
+
+	now := m.now()
+	m.observe(bm, now)
+
+	out := make(kafkametrics.BrokerMetrics, len(bm))
+	for id, b := range bm {
+		out[id] = b
+	}
+
+	for _, id := range ids {
+		if _, exists := bm[id]; exists {
+			continue
+		}
+
+		if b, ok := m.estimate(id, now); ok {
+			out[id] = b
+			m.interpolated = append(m.interpolated, id)
+		}
+	}
+
+	if len(m.interpolated) > 0 {
+		sort.Ints(m.interpolated)
+		log.Printf("Brokers missing from the metrics, interpolated from previous observations: %v\n", m.interpolated)
+	}
+
+	return out
+}
+
+// isInterpolated returns whether the broker's metrics were interpolated in
+// the most recent fetch.
+func (tm *ThrottleManager) isInterpolated(id int) bool {
+	for _, i := range tm.interpolation.interpolated {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InterpolatedBrokers returns a sorted []int of the brokers with metrics
+// interpolated from previous observations in the most recent throttle update.
+func (tm *ThrottleManager) InterpolatedBrokers() []int {
+	return tm.interpolation.interpolated
+}
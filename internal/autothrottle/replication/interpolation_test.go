@@ -0,0 +1,114 @@
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestInterpolateMetrics(t *testing.T) {
+	now := time.Unix(1582849692, 0)
+	tm := &ThrottleManager{
+		interpolation: metricsInterpolation{
+			maxAge: 5 * time.Minute,
+			now:    func() time.Time { return now },
+		},
+	}
+
+	metrics := func(tx, rx float64) kafkametrics.BrokerMetrics {
+		return kafkametrics.BrokerMetrics{
+			1000: &kafkametrics.Broker{ID: 1000, InstanceType: "stub", NetTX: tx, NetRX: rx},
+			1001: &kafkametrics.Broker{ID: 1001, InstanceType: "stub", NetTX: tx, NetRX: rx},
+		}
+	}
+
+	ids := []int{1000, 1001, 1002}
+
+	tm.interpolateMetrics(ids, metrics(100, 50))
+	now = now.Add(time.Minute)
+	tm.interpolateMetrics(ids, metrics(120, 20))
+
+	// 1001 is missing and extrapolated from its previous two observations;
+	// 1002 has never been observed.
+	now = now.Add(time.Minute)
+	bm := metrics(130, 30)
+	delete(bm, 1001)
+
+	got := tm.interpolateMetrics(ids, bm)
+
+	if b, exists := got[1001]; !exists {
+		t.Fatal("Expected interpolated metrics for broker 1001")
+	} else if b.NetTX != 140 || b.NetRX != 0 || b.InstanceType != "stub" {
+		t.Errorf("Expected interpolated metrics TX 140, RX 0, got %+v", b)
+	}
+
+	if _, exists := got[1002]; exists {
+		t.Error("Unexpected metrics for broker 1002")
+	}
+
+	if b := tm.InterpolatedBrokers(); len(b) != 1 || b[0] != 1001 {
+		t.Errorf("Expected interpolated brokers [1001], got %v", b)
+	}
+
+	// The fetched metrics aren't modified.
+	if _, exists := bm[1001]; exists {
+		t.Error("Unexpected interpolated metrics in the fetched metrics")
+	}
+
+	// Observations older than the max age aren't interpolated from.
+	now = now.Add(6 * time.Minute)
+	delete(bm, 1001)
+
+	if got := tm.interpolateMetrics(ids, bm); len(got) != 1 {
+		t.Errorf("Expected metrics for 1 broker, got %d", len(got))
+	}
+
+	if b := tm.InterpolatedBrokers(); len(b) != 0 {
+		t.Errorf("Expected no interpolated brokers, got %v", b)
+	}
+}
+
+func TestUpdateReplicationThrottleInterpolatedMetrics(t *testing.T) {
+	zkWriteInterval = 0
+
+	// Broker 1010 is missing from the third metrics fetch.
+	partial := stubBrokerMetrics()
+	delete(partial, 1010)
+
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(
+		kafkametrics.StubResponse{Metrics: stubBrokerMetrics()},
+		kafkametrics.StubResponse{Metrics: stubBrokerMetrics()},
+		kafkametrics.StubResponse{Metrics: partial},
+	)
+
+	tm := newTestThrottleManager(t, zk, km)
+	tm.interpolation.maxAge = 10 * time.Minute
+
+	now := time.Unix(1582849692, 0)
+	tm.interpolation.now = func() time.Time {
+		now = now.Add(3 * time.Minute)
+		return now
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := tm.UpdateReplicationThrottle(); err != nil {
+			t.Fatalf("[update %d] Unexpected error: %s", i, err)
+		}
+	}
+
+	if b := tm.InterpolatedBrokers(); len(b) != 1 || b[0] != 1010 {
+		t.Errorf("Expected interpolated brokers [1010], got %v", b)
+	}
+
+	if tm.failures != 0 {
+		t.Errorf("Expected no metrics failures, got %d", tm.failures)
+	}
+
+	if rate := tm.GetPreviousThrottles()[1010][1]; rate == nil {
+		t.Error("Expected a follower throttle for broker 1010")
+	}
+}
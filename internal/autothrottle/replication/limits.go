@@ -1,7 +1,10 @@
 package replication
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
@@ -10,17 +13,124 @@ import (
 // Limits is a map of instance-type to network bandwidth limits.
 type Limits map[string]float64
 
+// minimumKeyPrefix prefixes instance-type minimum throttle rate keys in a
+// Limits.
+const minimumKeyPrefix = "minimum:"
+
+// Instance types with asymmetric capacities are stored in a Limits as separate
+// outbound and inbound capacity keys with these prefixes. Symmetric capacities
+// are keyed by the instance type alone.
+const (
+	txCapacityKeyPrefix = "tx:"
+	rxCapacityKeyPrefix = "rx:"
+)
+
+// Capacity is the network capacity of an instance type in MB/s. In JSON, a
+// Capacity is either a single number that applies to both directions or an
+// object with distinct outbound and inbound capacities, e.g.
+// {"tx": 220, "rx": 180}.
+type Capacity struct {
+	TX float64 `json:"tx"`
+	RX float64 `json:"rx"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Capacity) UnmarshalJSON(b []byte) error {
+	var v float64
+	if err := json.Unmarshal(b, &v); err == nil {
+		c.TX, c.RX = v, v
+		return nil
+	}
+
+	var o struct {
+		TX *float64 `json:"tx"`
+		RX *float64 `json:"rx"`
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&o); err != nil || o.TX == nil || o.RX == nil {
+		return fmt.Errorf("capacity must be a number or an object with tx and rx capacities, got %s", b)
+	}
+
+	c.TX, c.RX = *o.TX, *o.RX
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. Symmetric capacities
+// are written as a single number.
+func (c Capacity) MarshalJSON() ([]byte, error) {
+	if c.TX == c.RX {
+		return json.Marshal(c.TX)
+	}
+
+	return json.Marshal(struct {
+		TX float64 `json:"tx"`
+		RX float64 `json:"rx"`
+	}{c.TX, c.RX})
+}
+
+// CapacityMap is a map of instance type to Capacity.
+type CapacityMap map[string]Capacity
+
+// Validate returns an error if any capacity isn't > 0.
+func (m CapacityMap) Validate() error {
+	for k, c := range m {
+		if c.TX <= 0 || c.RX <= 0 {
+			return fmt.Errorf("capacity for instance type %s must be > 0", k)
+		}
+	}
+
+	return nil
+}
+
 // NewLimitsConfig is used to initialize
 // a Limits.
 type NewLimitsConfig struct {
 	// Min throttle rate in MB/s.
 	Minimum float64
+	// Min source and destination broker throttle rates in MB/s. If unset, the
+	// Minimum is used.
+	SourceMinimum      float64
+	DestinationMinimum float64
+	// Map of instance-type to min throttle rate in MB/s. Instance-type minimums
+	// take precedence over the Minimum, SourceMinimum and DestinationMinimum.
+	MinimumMap map[string]float64
+	// Min throttle rate in MB/s for each partition a broker is actively moving.
+	// Where the rate determined from a broker's headroom divided among its
+	// moving partitions falls below this, the rate is raised up to the
+	// broker's maximum. Disabled if unset.
+	PartitionMinimum float64
 	// Max source broker throttle rate as a portion of capacity.
 	SourceMaximum float64
 	// Max destination broker throttle rate as a portion of capacity.
 	DestinationMaximum float64
-	// Map of instance-type to total network capacity in MB/s.
-	CapacityMap map[string]float64
+	// Max source and destination broker throttle rates in MB/s. Where set, the
+	// lower of the absolute maximum and the rate derived from the
+	// SourceMaximum or DestinationMaximum portion of capacity applies.
+	SourceMaximumAbsolute      float64
+	DestinationMaximumAbsolute float64
+	// Max source and destination broker throttle rates as a portion of capacity
+	// for brokers exclusively handling replication factor increases. If unset,
+	// the SourceMaximum and DestinationMaximum are used.
+	RFIncreaseSourceMaximum      float64
+	RFIncreaseDestinationMaximum float64
+	// Max source and destination broker throttle rates as a portion of capacity
+	// for brokers replicating to or from brokers in a different rack (i.e.
+	// availability zone). Cross-rack limits are disabled if unset.
+	CrossAZSourceMaximum      float64
+	CrossAZDestinationMaximum float64
+	// Max total replication throttle rate in MB/s across all reassigning
+	// brokers, for each of the outbound and inbound directions. The cluster
+	// budget is disabled if unset.
+	ClusterMaximum float64
+	// Map of instance-type to network capacity.
+	CapacityMap CapacityMap
+	// Network capacity in MB/s used for brokers with an instance type missing
+	// from the CapacityMap. If unset, throttles can't be determined for such
+	// brokers.
+	DefaultCapacity float64
 }
 
 // NewLimits takes a minimum float64 and a map of instance-type to
@@ -29,61 +139,303 @@ func NewLimits(c NewLimitsConfig) (Limits, error) {
 	switch {
 	case c.Minimum <= 0:
 		return nil, errors.New("minimum must be > 0")
+	case c.SourceMinimum < 0:
+		return nil, errors.New("source minimum must be >= 0")
+	case c.DestinationMinimum < 0:
+		return nil, errors.New("destination minimum must be >= 0")
+	case c.PartitionMinimum < 0:
+		return nil, errors.New("partition minimum must be >= 0")
 	case c.SourceMaximum <= 0 || c.SourceMaximum >= 100:
 		return nil, errors.New("source maximum must be > 0 and < 100")
 	case c.DestinationMaximum <= 0 || c.DestinationMaximum >= 100:
 		return nil, errors.New("destination maximum must be > 0 and < 100")
+	case c.SourceMaximumAbsolute < 0:
+		return nil, errors.New("absolute source maximum must be >= 0")
+	case c.DestinationMaximumAbsolute < 0:
+		return nil, errors.New("absolute destination maximum must be >= 0")
+	case c.RFIncreaseSourceMaximum < 0 || c.RFIncreaseSourceMaximum >= 100:
+		return nil, errors.New("replication factor increase source maximum must be >= 0 and < 100")
+	case c.RFIncreaseDestinationMaximum < 0 || c.RFIncreaseDestinationMaximum >= 100:
+		return nil, errors.New("replication factor increase destination maximum must be >= 0 and < 100")
+	case c.CrossAZSourceMaximum < 0 || c.CrossAZSourceMaximum >= 100:
+		return nil, errors.New("cross-AZ source maximum must be >= 0 and < 100")
+	case c.CrossAZDestinationMaximum < 0 || c.CrossAZDestinationMaximum >= 100:
+		return nil, errors.New("cross-AZ destination maximum must be >= 0 and < 100")
+	case c.ClusterMaximum < 0:
+		return nil, errors.New("cluster maximum must be >= 0")
+	case c.DefaultCapacity < 0:
+		return nil, errors.New("default capacity must be >= 0")
+	}
+
+	for k, v := range c.MinimumMap {
+		if v <= 0 {
+			return nil, fmt.Errorf("minimum for instance type %s must be > 0", k)
+		}
 	}
 
 	// Populate the min/max vals into the Limits map.
 	lim := Limits{
-		"minimum": c.Minimum,
-		"srcMax":  c.SourceMaximum,
-		"dstMax":  c.DestinationMaximum,
+		"minimum":  c.Minimum,
+		"srcMin":   c.Minimum,
+		"dstMin":   c.Minimum,
+		"srcMax":   c.SourceMaximum,
+		"dstMax":   c.DestinationMaximum,
+		"rfSrcMax": c.SourceMaximum,
+		"rfDstMax": c.DestinationMaximum,
+	}
+
+	if c.SourceMinimum > 0 {
+		lim["srcMin"] = c.SourceMinimum
+	}
+
+	if c.DestinationMinimum > 0 {
+		lim["dstMin"] = c.DestinationMinimum
+	}
+
+	if c.PartitionMinimum > 0 {
+		lim["partitionMin"] = c.PartitionMinimum
+	}
+
+	if c.SourceMaximumAbsolute > 0 {
+		lim["srcMaxAbs"] = c.SourceMaximumAbsolute
+	}
+
+	if c.DestinationMaximumAbsolute > 0 {
+		lim["dstMaxAbs"] = c.DestinationMaximumAbsolute
+	}
+
+	if c.RFIncreaseSourceMaximum > 0 {
+		lim["rfSrcMax"] = c.RFIncreaseSourceMaximum
+	}
+
+	if c.RFIncreaseDestinationMaximum > 0 {
+		lim["rfDstMax"] = c.RFIncreaseDestinationMaximum
+	}
+
+	if c.CrossAZSourceMaximum > 0 {
+		lim["crossAZSrcMax"] = c.CrossAZSourceMaximum
+	}
+
+	if c.CrossAZDestinationMaximum > 0 {
+		lim["crossAZDstMax"] = c.CrossAZDestinationMaximum
+	}
+
+	if c.ClusterMaximum > 0 {
+		lim["clusterMax"] = c.ClusterMaximum
+	}
+
+	if c.DefaultCapacity > 0 {
+		lim["defaultCapacity"] = c.DefaultCapacity
 	}
 
 	// Update with provided capacity map.
 	for k, v := range c.CapacityMap {
-		lim[k] = v
+		lim.SetCapacity(k, v)
+	}
+
+	for k, v := range c.MinimumMap {
+		lim[minimumKeyPrefix+k] = v
 	}
 
 	return lim, nil
 }
 
+// SetCapacity sets the network capacity for the instance type.
+func (l Limits) SetCapacity(instanceType string, c Capacity) {
+	l.DeleteCapacity(instanceType)
+
+	if c.TX == c.RX {
+		l[instanceType] = c.TX
+		return
+	}
+
+	l[txCapacityKeyPrefix+instanceType] = c.TX
+	l[rxCapacityKeyPrefix+instanceType] = c.RX
+}
+
+// Capacity returns the network capacity for the instance type and whether
+// it's set.
+func (l Limits) Capacity(instanceType string) (Capacity, bool) {
+	if v, exists := l[instanceType]; exists {
+		return Capacity{TX: v, RX: v}, true
+	}
+
+	tx, txExists := l[txCapacityKeyPrefix+instanceType]
+	rx, rxExists := l[rxCapacityKeyPrefix+instanceType]
+	if txExists && rxExists {
+		return Capacity{TX: tx, RX: rx}, true
+	}
+
+	return Capacity{}, false
+}
+
+// defaultCapacity returns the network capacity used for instance types
+// without a configured capacity and whether it's set.
+func (l Limits) defaultCapacity() (Capacity, bool) {
+	v, exists := l["defaultCapacity"]
+	return Capacity{TX: v, RX: v}, exists
+}
+
+// DeleteCapacity removes the network capacity for the instance type.
+func (l Limits) DeleteCapacity(instanceType string) {
+	delete(l, instanceType)
+	delete(l, txCapacityKeyPrefix+instanceType)
+	delete(l, rxCapacityKeyPrefix+instanceType)
+}
+
+// minimum takes an instance type and replica role and returns the min throttle
+// rate in MB/s. The instance-type minimum is returned if configured, otherwise
+// the minimum for the role. An empty instance type, e.g. where the broker's
+// instance type is unknown, uses the role minimum.
+func (l Limits) minimum(instanceType string, rt ReplicaType) float64 {
+	if instanceType != "" {
+		if v, exists := l[minimumKeyPrefix+instanceType]; exists {
+			return v
+		}
+	}
+
+	switch rt {
+	case "leader":
+		if v, exists := l["srcMin"]; exists {
+			return v
+		}
+	case "follower":
+		if v, exists := l["dstMin"]; exists {
+			return v
+		}
+	}
+
+	return l["minimum"]
+}
+
+// minimumRates takes a list of broker IDs and returns a
+// ReplicationCapacityByBroker with the leader and follower rates set to the
+// configured minimums. The instance type last observed in the metrics for each
+// broker is used to apply any instance-type minimums.
+func (tm *ThrottleManager) minimumRates(ids []int) ReplicationCapacityByBroker {
+	rates := make(ReplicationCapacityByBroker)
+	for _, id := range ids {
+		instanceType := tm.brokerIdentities[id].instanceType
+		rates.storeLeaderCapacity(id, tm.limits.minimum(instanceType, "leader"))
+		rates.storeFollowerCapacity(id, tm.limits.minimum(instanceType, "follower"))
+	}
+
+	return rates
+}
+
+// rfIncreaseLimits returns a copy of the Limits where the source and
+// destination maximums are those configured for replication factor increases.
+func (l Limits) rfIncreaseLimits() Limits {
+	lim := make(Limits, len(l))
+	for k, v := range l {
+		lim[k] = v
+	}
+
+	lim["srcMax"] = l["rfSrcMax"]
+	lim["dstMax"] = l["rfDstMax"]
+
+	return lim
+}
+
+// withCapacity returns a copy of the Limits where the capacity for the
+// instance type is c.
+func (l Limits) withCapacity(instanceType string, c Capacity) Limits {
+	lim := make(Limits, len(l))
+	for k, v := range l {
+		lim[k] = v
+	}
+
+	lim.SetCapacity(instanceType, c)
+
+	return lim
+}
+
+// crossAZEnabled returns whether cross-AZ maximums are configured.
+func (l Limits) crossAZEnabled() bool {
+	_, src := l["crossAZSrcMax"]
+	_, dst := l["crossAZDstMax"]
+	return src || dst
+}
+
+// crossAZLimits returns a copy of the Limits where the source and destination
+// maximums are capped at those configured for cross-AZ replication.
+func (l Limits) crossAZLimits() Limits {
+	lim := make(Limits, len(l))
+	for k, v := range l {
+		lim[k] = v
+	}
+
+	if v, exists := l["crossAZSrcMax"]; exists {
+		lim["srcMax"] = math.Min(l["srcMax"], v)
+	}
+
+	if v, exists := l["crossAZDstMax"]; exists {
+		lim["dstMax"] = math.Min(l["dstMax"], v)
+	}
+
+	return lim
+}
+
 // replicationHeadroom takes a *kafkametrics.Broker, what type of replica role
 // it's fulfilling, and the last set throttle rate. A replication headroom value
 // is returned based on utilization vs capacity. Headroom is determined by
 // subtracting the current throttle rate from the current network utilization.
 // This yields a crude approximation of how much non-replication throughput is
 // currently being demanded. The non-replication throughput is then subtracted
-// from the total network capacity available. This value suggests what headroom
-// is available for replication. We then use the greater of:
-// - this value * the configured portion of free bandwidth eligible for replication
-// - the configured minimum replication rate in MB/s
+// from the network capacity available in the direction of the role; outbound
+// for leaders and inbound for followers. For leaders, any tiered storage
+// upload bandwidth reported for the broker is subtracted as well, as it
+// competes with replication for outbound bandwidth. This value suggests what
+// headroom is available for replication. We then use the greater of:
+// - this value * the configured portion of free bandwidth eligible for replication, up to any absolute maximum
+// - the configured minimum replication rate in MB/s for the role and instance type
 func (l Limits) replicationHeadroom(b *kafkametrics.Broker, rt ReplicaType, prevThrottle float64) (float64, error) {
 	var currNetUtilization float64
 	var maxRatio float64
+	var absMaxKey string
+	var tieredUpload float64
+
+	c, exists := l.Capacity(b.InstanceType)
+	var capacity float64
 
 	switch rt {
 	case "leader":
 		currNetUtilization = b.NetTX
+		tieredUpload = b.TieredUpload
 		maxRatio = l["srcMax"]
+		absMaxKey = "srcMaxAbs"
+		capacity = c.TX
 	case "follower":
 		currNetUtilization = b.NetRX
 		maxRatio = l["dstMax"]
+		absMaxKey = "dstMaxAbs"
+		capacity = c.RX
 	default:
 		return 0.00, errors.New("invalid replica type")
 	}
 
-	if capacity, exists := l[b.InstanceType]; exists {
+	if exists {
 		nonThrottleUtil := math.Max(currNetUtilization-prevThrottle, 0.00)
 		// Determine if/how far over the target capacity
 		// we are. This is also subtracted from the available
 		// headroom.
 		overCap := math.Max(currNetUtilization-capacity, 0.00)
 
-		return math.Max((capacity-nonThrottleUtil-overCap)*(maxRatio/100), l["minimum"]), nil
+		rate := (capacity - nonThrottleUtil - overCap - tieredUpload) * (maxRatio / 100)
+		if v, exists := l[absMaxKey]; exists {
+			rate = math.Min(rate, v)
+		}
+
+		return math.Max(rate, l.minimum(b.InstanceType, rt)), nil
 	}
 
-	return l["minimum"], errors.New("unknown instance type")
+	return l.minimum(b.InstanceType, rt), errors.New("unknown instance type")
+}
+
+// ReplicationHeadroom returns the replication throttle rate for a broker in
+// the role as computed by a throttle update, given its network utilization and
+// previous throttle rate. It's used to simulate rates outside of a
+// ThrottleManager.
+func (l Limits) ReplicationHeadroom(b *kafkametrics.Broker, rt ReplicaType, prevThrottle float64) (float64, error) {
+	return l.replicationHeadroom(b, rt, prevThrottle)
 }
@@ -1,6 +1,8 @@
 package replication
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
@@ -37,6 +39,154 @@ func TestNewLimits(t *testing.T) {
 	if err == nil {
 		t.Error("Expected non-nil error")
 	}
+
+	c.DestinationMaximum = 80
+	c.RFIncreaseSourceMaximum = 120 // Invalid.
+
+	_, err = NewLimits(c)
+	if err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	c.RFIncreaseSourceMaximum = 0
+	c.SourceMinimum = -1 // Invalid.
+
+	_, err = NewLimits(c)
+	if err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	c.SourceMinimum = 0
+	c.MinimumMap = map[string]float64{"i3en.xlarge": 0} // Invalid.
+
+	_, err = NewLimits(c)
+	if err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	c.MinimumMap = nil
+	c.CrossAZDestinationMaximum = -1 // Invalid.
+
+	_, err = NewLimits(c)
+	if err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	c.CrossAZDestinationMaximum = 0
+	c.ClusterMaximum = -1 // Invalid.
+
+	_, err = NewLimits(c)
+	if err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	c.ClusterMaximum = 0
+	c.DefaultCapacity = -1 // Invalid.
+
+	_, err = NewLimits(c)
+	if err == nil {
+		t.Error("Expected non-nil error")
+	}
+
+	c.DefaultCapacity = 0
+	c.PartitionMinimum = -1 // Invalid.
+
+	_, err = NewLimits(c)
+	if err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
+
+func TestLimitsMinimum(t *testing.T) {
+	c := NewLimitsConfig{
+		Minimum:            10,
+		DestinationMinimum: 15,
+		SourceMaximum:      80,
+		DestinationMaximum: 80,
+		MinimumMap:         map[string]float64{"i3en.xlarge": 20},
+	}
+
+	l, _ := NewLimits(c)
+
+	tests := []struct {
+		instanceType string
+		role         ReplicaType
+		expected     float64
+	}{
+		// The source minimum falls back to the Minimum.
+		{"", "leader", 10},
+		{"", "follower", 15},
+		{"d2.xlarge", "leader", 10},
+		{"d2.xlarge", "follower", 15},
+		// Instance-type minimums take precedence.
+		{"i3en.xlarge", "leader", 20},
+		{"i3en.xlarge", "follower", 20},
+	}
+
+	for _, test := range tests {
+		if m := l.minimum(test.instanceType, test.role); m != test.expected {
+			t.Errorf("[%s %s] Expected minimum %.0f, got %.0f", test.instanceType, test.role, test.expected, m)
+		}
+	}
+
+	// Minimums aren't instance-type capacities.
+	if _, exists := l["i3en.xlarge"]; exists {
+		t.Error("Unexpected i3en.xlarge capacity")
+	}
+}
+
+func TestRFIncreaseLimits(t *testing.T) {
+	c := NewLimitsConfig{
+		Minimum:                 10,
+		SourceMaximum:           80,
+		DestinationMaximum:      60,
+		RFIncreaseSourceMaximum: 40,
+	}
+
+	l, _ := NewLimits(c)
+	rl := l.rfIncreaseLimits()
+
+	// The destination maximum falls back to the DestinationMaximum.
+	if rl["srcMax"] != 40 || rl["dstMax"] != 60 {
+		t.Errorf("Expected srcMax 40 and dstMax 60, got %.0f and %.0f", rl["srcMax"], rl["dstMax"])
+	}
+
+	// The original Limits are unchanged.
+	if l["srcMax"] != 80 {
+		t.Errorf("Expected srcMax 80, got %.0f", l["srcMax"])
+	}
+}
+
+func TestCrossAZLimits(t *testing.T) {
+	c := NewLimitsConfig{
+		Minimum:                 10,
+		SourceMaximum:           80,
+		DestinationMaximum:      60,
+		RFIncreaseSourceMaximum: 40,
+		CrossAZSourceMaximum:    50,
+	}
+
+	l, _ := NewLimits(c)
+
+	if !l.crossAZEnabled() {
+		t.Error("Expected cross-AZ limits to be enabled")
+	}
+
+	// The destination maximum is unchanged.
+	cl := l.crossAZLimits()
+	if cl["srcMax"] != 50 || cl["dstMax"] != 60 {
+		t.Errorf("Expected srcMax 50 and dstMax 60, got %.0f and %.0f", cl["srcMax"], cl["dstMax"])
+	}
+
+	// Lower maximums aren't raised.
+	if rl := l.rfIncreaseLimits().crossAZLimits(); rl["srcMax"] != 40 {
+		t.Errorf("Expected srcMax 40, got %.0f", rl["srcMax"])
+	}
+
+	c.CrossAZSourceMaximum = 0
+	if l, _ = NewLimits(c); l.crossAZEnabled() {
+		t.Error("Expected cross-AZ limits to be disabled")
+	}
 }
 
 func TestReplicationHeadroom(t *testing.T) {
@@ -44,8 +194,8 @@ func TestReplicationHeadroom(t *testing.T) {
 		Minimum:            10,
 		SourceMaximum:      80,
 		DestinationMaximum: 60,
-		CapacityMap: map[string]float64{
-			"stub": 100,
+		CapacityMap: CapacityMap{
+			"stub": {TX: 100, RX: 100},
 		},
 	}
 
@@ -89,4 +239,165 @@ func TestReplicationHeadroom(t *testing.T) {
 			t.Errorf("[test index %d] Expected headroom value of %f, got %f\n", n, params[2], h)
 		}
 	}
+
+	// Test role and instance-type minimums.
+	c.SourceMinimum = 12
+	c.MinimumMap = map[string]float64{"stub": 30}
+	l, _ = NewLimits(c)
+
+	b.NetTX, b.NetRX = 200, 200
+
+	if h, _ := l.replicationHeadroom(b, "leader", 70); h != 30 {
+		t.Errorf("Expected headroom value of 30, got %f", h)
+	}
+
+	b.InstanceType = "unknown"
+	if h, _ := l.replicationHeadroom(b, "leader", 70); h != 12 {
+		t.Errorf("Expected headroom value of 12, got %f", h)
+	}
+}
+
+func TestReplicationHeadroomAbsoluteMaximum(t *testing.T) {
+	l, err := NewLimits(NewLimitsConfig{
+		Minimum:                    10,
+		SourceMaximum:              80,
+		DestinationMaximum:         60,
+		SourceMaximumAbsolute:      50,
+		DestinationMaximumAbsolute: 5,
+		CapacityMap:                CapacityMap{"stub": {TX: 1000, RX: 1000}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &kafkametrics.Broker{InstanceType: "stub", NetTX: 900, NetRX: 0}
+
+	// (1000-0)*0.8 is capped at 50.
+	if h, _ := l.replicationHeadroom(b, "leader", 900); h != 50 {
+		t.Errorf("Expected leader headroom value of 50, got %f", h)
+	}
+
+	// (1000-960)*0.8 is below the cap.
+	b.NetTX = 960
+	if h, _ := l.replicationHeadroom(b, "leader", 0); h != 32 {
+		t.Errorf("Expected leader headroom value of 32, got %f", h)
+	}
+	b.NetTX = 900
+
+	// The minimum takes precedence over the cap.
+	if h, _ := l.replicationHeadroom(b, "follower", 0); h != 10 {
+		t.Errorf("Expected follower headroom value of 10, got %f", h)
+	}
+
+	// The caps carry over to derived limits.
+	if h, _ := l.rfIncreaseLimits().replicationHeadroom(b, "leader", 900); h != 50 {
+		t.Errorf("Expected replication factor increase leader headroom value of 50, got %f", h)
+	}
+
+	if _, err := NewLimits(NewLimitsConfig{Minimum: 10, SourceMaximum: 80, DestinationMaximum: 80, SourceMaximumAbsolute: -1}); err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
+
+func TestReplicationHeadroomAsymmetric(t *testing.T) {
+	l, _ := NewLimits(NewLimitsConfig{
+		Minimum:            10,
+		SourceMaximum:      80,
+		DestinationMaximum: 60,
+		CapacityMap:        CapacityMap{"stub": {TX: 200, RX: 100}},
+	})
+
+	b := &kafkametrics.Broker{InstanceType: "stub", NetTX: 70, NetRX: 70}
+
+	// Leaders are limited by the outbound capacity; (200-70)*0.8.
+	if h, _ := l.replicationHeadroom(b, "leader", 0); h != 104 {
+		t.Errorf("Expected leader headroom value of 104, got %f", h)
+	}
+
+	// Followers are limited by the inbound capacity; (100-70)*0.6.
+	if h, _ := l.replicationHeadroom(b, "follower", 0); h != 18 {
+		t.Errorf("Expected follower headroom value of 18, got %f", h)
+	}
+}
+
+func TestReplicationHeadroomTieredUpload(t *testing.T) {
+	l, _ := NewLimits(NewLimitsConfig{
+		Minimum:            10,
+		SourceMaximum:      80,
+		DestinationMaximum: 60,
+		CapacityMap:        CapacityMap{"stub": {TX: 100, RX: 100}},
+	})
+
+	b := &kafkametrics.Broker{InstanceType: "stub", NetTX: 50, NetRX: 50, TieredUpload: 20}
+
+	// Uploads are subtracted from the outbound headroom; (100-50-20)*0.8.
+	if h, _ := l.replicationHeadroom(b, "leader", 0); h != 24 {
+		t.Errorf("Expected leader headroom value of 24, got %f", h)
+	}
+
+	// Inbound headroom is unaffected; (100-50)*0.6.
+	if h, _ := l.replicationHeadroom(b, "follower", 0); h != 30 {
+		t.Errorf("Expected follower headroom value of 30, got %f", h)
+	}
+
+	// The minimum still applies.
+	b.TieredUpload = 60
+	if h, _ := l.replicationHeadroom(b, "leader", 0); h != 10 {
+		t.Errorf("Expected leader headroom value of 10, got %f", h)
+	}
+}
+
+func TestCapacityJSON(t *testing.T) {
+	var m CapacityMap
+	err := json.Unmarshal([]byte(`{"d2.2xlarge": 120, "i3.2xlarge": {"tx": 220, "rx": 180}, "i3.xlarge": {"tx": 100, "rx": 100}}`), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CapacityMap{
+		"d2.2xlarge": {TX: 120, RX: 120},
+		"i3.2xlarge": {TX: 220, RX: 180},
+		"i3.xlarge":  {TX: 100, RX: 100},
+	}
+
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected %v, got %v", expected, m)
+	}
+
+	// Symmetric capacities are written as numbers.
+	out, _ := json.Marshal(m)
+	if s := string(out); s != `{"d2.2xlarge":120,"i3.2xlarge":{"tx":220,"rx":180},"i3.xlarge":100}` {
+		t.Errorf("Unexpected JSON %s", s)
+	}
+
+	for _, invalid := range []string{`{"a": "120"}`, `{"a": {"tx": 100}}`, `{"a": {"tx": 100, "rx": 100, "total": 200}}`, `{"a": []}`} {
+		if err := json.Unmarshal([]byte(invalid), &m); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+
+	if err := (CapacityMap{"a": {TX: 100, RX: 0}}).Validate(); err == nil {
+		t.Error("Expected a validation error for a 0 capacity")
+	}
+}
+
+func TestLimitsCapacity(t *testing.T) {
+	l := Limits{}
+
+	l.SetCapacity("a", Capacity{TX: 200, RX: 100})
+	if c, exists := l.Capacity("a"); !exists || c.TX != 200 || c.RX != 100 {
+		t.Errorf("Unexpected capacity %v", c)
+	}
+
+	// Symmetric capacities replace asymmetric capacities and are keyed by the
+	// instance type.
+	l.SetCapacity("a", Capacity{TX: 150, RX: 150})
+	if len(l) != 1 || l["a"] != 150 {
+		t.Errorf("Unexpected limits %v", l)
+	}
+
+	l.DeleteCapacity("a")
+	if _, exists := l.Capacity("a"); exists || len(l) != 0 {
+		t.Errorf("Unexpected limits %v", l)
+	}
 }
@@ -0,0 +1,156 @@
+package replication
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/tracing"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// unitsWarningFactor is the factor by which the network utilization of a
+// broker must exceed its configured capacity for the units of the metrics
+// queries to be suspect.
+const unitsWarningFactor = 4
+
+// metricsResult holds the results of a broker metrics request.
+type metricsResult struct {
+	metrics kafkametrics.BrokerMetrics
+	errs    []error
+}
+
+// PrefetchMetrics fetches broker metrics ahead of the next
+// UpdateReplicationThrottle call, which uses them rather than making its own
+// request. This allows the metrics request to run concurrently with other
+// per-interval work. PrefetchMetrics must not be called concurrently with
+// other ThrottleManager methods.
+func (tm *ThrottleManager) PrefetchMetrics() {
+	bm, errs := tm.fetchMetrics()
+	tm.prefetchedMetrics = &metricsResult{metrics: bm, errs: errs}
+}
+
+// ClearPrefetchedMetrics discards any metrics fetched with PrefetchMetrics
+// that weren't used.
+func (tm *ThrottleManager) ClearPrefetchedMetrics() {
+	tm.prefetchedMetrics = nil
+}
+
+// getMetrics returns any prefetched broker metrics, otherwise metrics are
+// fetched. Prefetched metrics are only used once.
+func (tm *ThrottleManager) getMetrics() (kafkametrics.BrokerMetrics, []error) {
+	if r := tm.prefetchedMetrics; r != nil {
+		tm.prefetchedMetrics = nil
+		return r.metrics, r.errs
+	}
+
+	return tm.fetchMetrics()
+}
+
+// fetchMetrics fetches broker metrics. If a metrics timeout is configured and
+// the request doesn't complete in time, an error is returned in place of the
+// metrics. Requests are serialized; a request that timed out must complete
+// before another is made.
+func (tm *ThrottleManager) fetchMetrics() (bm kafkametrics.BrokerMetrics, errs []error) {
+	_, span := tracing.Start(tm.traceContext(), "broker metrics request")
+	defer func() {
+		span.SetAttributes(tracing.Int("brokers", len(bm)))
+		span.RecordError(errors.Join(errs...))
+		span.End()
+	}()
+
+	if tm.metricsTimeout <= 0 {
+		tm.metricsMu.Lock()
+		defer tm.metricsMu.Unlock()
+		return tm.km.GetMetrics()
+	}
+
+	// Buffered so that a request completing after the timeout doesn't block.
+	results := make(chan metricsResult, 1)
+
+	go func() {
+		tm.metricsMu.Lock()
+		defer tm.metricsMu.Unlock()
+		bm, errs := tm.km.GetMetrics()
+		results <- metricsResult{metrics: bm, errs: errs}
+	}()
+
+	timeout := time.NewTimer(tm.metricsTimeout)
+	defer timeout.Stop()
+
+	select {
+	case r := <-results:
+		return r.metrics, r.errs
+	case <-timeout.C:
+		return nil, []error{fmt.Errorf("metrics request timed out after %s", tm.metricsTimeout)}
+	}
+}
+
+// recordBandwidth records the network utilization of each of the brokers in
+// the kafkametrics.BrokerMetrics.
+func (tm *ThrottleManager) recordBandwidth(ids []int, bm kafkametrics.BrokerMetrics) {
+	tm.measuredBandwidth = make(map[int][2]float64, len(ids))
+
+	for _, id := range ids {
+		if b, exists := bm[id]; exists {
+			tm.measuredBandwidth[id] = [2]float64{b.NetTX, b.NetRX}
+		}
+	}
+}
+
+// TakeMeasuredBandwidth returns a map of broker ID to the outbound and inbound
+// network utilization in MB/s, in respective order to index, of the
+// reassigning brokers measured since the previous call. Brokers are only
+// measured while throttle rates are determined from metrics.
+func (tm *ThrottleManager) TakeMeasuredBandwidth() map[int][2]float64 {
+	m := tm.measuredBandwidth
+	tm.measuredBandwidth = nil
+
+	return m
+}
+
+// checkUnits takes the broker IDs and a kafkametrics.BrokerMetrics and returns
+// a warning for each broker with a network utilization exceeding the capacity
+// configured for its instance type, or otherwise its tagged capacity, by the
+// unitsWarningFactor, which suggests that the units of the metrics queries
+// are misconfigured.
+func (tm *ThrottleManager) checkUnits(ids []int, bm kafkametrics.BrokerMetrics) []string {
+	var warnings []string
+
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+
+	for _, id := range sorted {
+		b, exists := bm[id]
+		if !exists {
+			continue
+		}
+
+		c, exists := tm.limits.Capacity(b.InstanceType)
+		if !exists && b.Capacity > 0 {
+			c, exists = Capacity{TX: b.Capacity, RX: b.Capacity}, true
+		}
+
+		if !exists {
+			continue
+		}
+
+		for _, v := range []struct {
+			direction string
+			observed  float64
+			capacity  float64
+			flag      string
+		}{
+			{"outbound", b.NetTX, c.TX, "net-tx-units"},
+			{"inbound", b.NetRX, c.RX, "net-rx-units"},
+		} {
+			if v.capacity > 0 && v.observed > v.capacity*unitsWarningFactor {
+				warnings = append(warnings, fmt.Sprintf("Broker %d %s network utilization of %.2fMB/s exceeds its %.2fMB/s capacity (instance type %s) by over %dx; check the %s",
+					id, v.direction, v.observed, v.capacity, b.InstanceType, unitsWarningFactor, v.flag))
+			}
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,113 @@
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// blockingMetrics is a kafkametrics.Handler where GetMetrics blocks until
+// released.
+type blockingMetrics struct {
+	*kafkametrics.Stub
+	release chan struct{}
+}
+
+func (b *blockingMetrics) GetMetrics() (kafkametrics.BrokerMetrics, []error) {
+	<-b.release
+	return b.Stub.GetMetrics()
+}
+
+func TestPrefetchMetrics(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	tm := newTestThrottleManager(t, zk, km)
+
+	tm.PrefetchMetrics()
+
+	// The next response is an error; the prefetched metrics are used first.
+	km.Script(metricsErrResponse("unavailable"))
+
+	if bm, errs := tm.getMetrics(); len(bm) != 10 || errs != nil {
+		t.Errorf("Expected prefetched metrics, got %d brokers, errors %v", len(bm), errs)
+	}
+
+	// Prefetched metrics are only used once.
+	if _, errs := tm.getMetrics(); len(errs) != 1 {
+		t.Errorf("Expected 1 error, got %v", errs)
+	}
+
+	// Cleared metrics aren't used.
+	km.Script(kafkametrics.StubResponse{Metrics: kafkametrics.StubBrokerMetrics()}, metricsErrResponse("unavailable"))
+
+	tm.PrefetchMetrics()
+	tm.ClearPrefetchedMetrics()
+
+	if _, errs := tm.getMetrics(); len(errs) != 1 {
+		t.Errorf("Expected metrics to be fetched again, got errors %v", errs)
+	}
+}
+
+func TestFetchMetricsTimeout(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	tm := newTestThrottleManager(t, zk, kafkametrics.NewStub())
+
+	km := &blockingMetrics{Stub: kafkametrics.NewStub(), release: make(chan struct{})}
+	tm.km = km
+	tm.metricsTimeout = 10 * time.Millisecond
+
+	bm, errs := tm.fetchMetrics()
+	if bm != nil || len(errs) != 1 {
+		t.Fatalf("Expected a timeout error, got %d brokers, errors %v", len(bm), errs)
+	}
+
+	if errs[0].Error() != "metrics request timed out after 10ms" {
+		t.Errorf("Unexpected error %s", errs[0])
+	}
+
+	// A timed out fetch is treated as a metrics failure; with a failure
+	// threshold of 1, the previous throttles are retained.
+	zk.ResetKafkaConfigUpdates()
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	if updates := brokerConfigUpdates(zk); len(updates) != 0 {
+		t.Errorf("Expected no broker config updates, got %v", updates)
+	}
+
+	// Once the request completes, metrics are fetched as usual.
+	close(km.release)
+	tm.metricsTimeout = time.Second
+
+	if bm, errs := tm.fetchMetrics(); len(bm) != 10 || errs != nil {
+		t.Errorf("Expected metrics, got %d brokers, errors %v", len(bm), errs)
+	}
+}
+
+func TestCheckUnits(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	tm := newTestThrottleManager(t, zk, kafkametrics.NewStub())
+
+	bm := kafkametrics.StubBrokerMetrics()
+
+	if w := tm.checkUnits([]int{1001, 1002}, bm); len(w) != 0 {
+		t.Errorf("Expected no warnings, got %v", w)
+	}
+
+	// Values reported in bytes rather than MB/s, and those of brokers without
+	// a configured capacity.
+	bm[1001].NetRX = 819200
+	bm[1002].NetTX = 819200
+	bm[1002].InstanceType = "unknown"
+
+	w := tm.checkUnits([]int{1001, 1002}, bm)
+
+	expected := "Broker 1001 inbound network utilization of 819200.00MB/s exceeds its 200.00MB/s capacity (instance type stub) by over 4x; check the net-rx-units"
+	if len(w) != 1 || w[0] != expected {
+		t.Errorf("Expected warning %q, got %v", expected, w)
+	}
+}
@@ -0,0 +1,108 @@
+package replication
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// Observation is a read-only snapshot of the replication state for ongoing
+// reassignments.
+type Observation struct {
+	// Reassigning brokers by role.
+	SourceBrokers      []int
+	DestinationBrokers []int
+	// Broker metrics and any errors fetching them.
+	Metrics       kafkametrics.BrokerMetrics
+	MetricsErrors []error
+	// The configured leader and follower throttle rates in MB/s for reassigning
+	// brokers with any throttle set.
+	BrokerThrottles ReplicationCapacityByBroker
+	// The configured leader and follower throttled replicas lists for each
+	// reassigning topic.
+	TopicThrottledReplicas map[string][2]string
+}
+
+// Observe returns an Observation for the reassignments and reassigning brokers
+// set on the ThrottleManager. Observe never computes or applies throttles.
+func (tm *ThrottleManager) Observe() (Observation, error) {
+	src, dst, all := tm.reassigningBrokers.lists()
+
+	o := Observation{
+		SourceBrokers:          src,
+		DestinationBrokers:     dst,
+		BrokerThrottles:        make(ReplicationCapacityByBroker),
+		TopicThrottledReplicas: map[string][2]string{},
+	}
+
+	o.Metrics, o.MetricsErrors = tm.fetchMetrics()
+
+	// Broker throttle rates.
+	var brokers []string
+	for _, id := range all {
+		brokers = append(brokers, strconv.Itoa(id))
+	}
+
+	configs, err := tm.observeConfigs("broker", brokers)
+	if err != nil {
+		return o, err
+	}
+
+	for _, id := range all {
+		for i, name := range brokerThrottleCfgNames {
+			v, exists := configs[strconv.Itoa(id)][name]
+			if !exists || v == "" {
+				continue
+			}
+
+			rateBytes, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return o, fmt.Errorf("error parsing %s for broker %d: %s", name, id, err)
+			}
+
+			rate := rateBytes / 1000000.00
+			switch i {
+			case 0:
+				o.BrokerThrottles.storeLeaderCapacity(id, rate)
+			case 1:
+				o.BrokerThrottles.storeFollowerCapacity(id, rate)
+			}
+		}
+	}
+
+	// Topic throttled replicas.
+	var topics []string
+	for topic := range tm.reassignments {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	configs, err = tm.observeConfigs("topic", topics)
+	if err != nil {
+		return o, err
+	}
+
+	for _, topic := range topics {
+		o.TopicThrottledReplicas[topic] = [2]string{
+			configs[topic][topicThrottleCfgNames[0]],
+			configs[topic][topicThrottleCfgNames[1]],
+		}
+	}
+
+	return o, nil
+}
+
+// observeConfigs returns the dynamic configs for the named brokers or topics.
+func (tm *ThrottleManager) observeConfigs(kind string, names []string) (map[string]map[string]string, error) {
+	if len(names) == 0 {
+		return map[string]map[string]string{}, nil
+	}
+
+	if tm.kafkaNativeMode {
+		return tm.readConfigs(kind, names)
+	}
+
+	return tm.legacyReadConfigs(kind, names)
+}
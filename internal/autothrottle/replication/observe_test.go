@@ -0,0 +1,52 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestObserve(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(kafkametrics.StubResponse{Metrics: stubBrokerMetrics()})
+
+	tm := newTestThrottleManager(t, zk, km)
+
+	o, err := tm.Observe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(o.SourceBrokers) != 2 || len(o.DestinationBrokers) != 3 {
+		t.Errorf("Unexpected reassigning brokers %v, %v", o.SourceBrokers, o.DestinationBrokers)
+	}
+
+	if len(o.Metrics) != len(stubBrokerMetrics()) || o.MetricsErrors != nil {
+		t.Errorf("Unexpected metrics %v, errors %v", o.Metrics, o.MetricsErrors)
+	}
+
+	// The stub returns 100MB/s leader and follower throttles for all brokers.
+	if len(o.BrokerThrottles) != 5 {
+		t.Errorf("Expected throttles for 5 brokers, got %s", o.BrokerThrottles)
+	}
+
+	for id, rates := range o.BrokerThrottles {
+		for _, r := range rates {
+			if r == nil || *r != 100.00 {
+				t.Errorf("Expected 100.00 throttles for broker %d, got %s", id, o.BrokerThrottles)
+			}
+		}
+	}
+
+	expected := [2]string{"0:1001,0:1002", "0:1003,0:1004"}
+	if r := o.TopicThrottledReplicas["reassigning_topic"]; r != expected {
+		t.Errorf("Expected throttled replicas %v, got %v", expected, r)
+	}
+
+	// Nothing is written.
+	if updates := zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+}
@@ -0,0 +1,60 @@
+package replication
+
+import (
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// FindOrphanedTopicThrottles scans all topic dynamic configs and returns a
+// sorted []string of topics with throttled replicas set that are neither
+// being reassigned nor replicated by brokers with throttle overrides. Such
+// configs are typically left behind by finished reassignments, whether
+// throttled by autothrottle or other tools, and cap replication of the topic
+// indefinitely.
+func (tm *ThrottleManager) FindOrphanedTopicThrottles() ([]string, error) {
+	var topicConfigs map[string]kafkazk.TopicThrottleConfig
+	var err error
+
+	if tm.kafkaNativeMode {
+		topicConfigs, err = tm.getTopicConfigs()
+	} else {
+		topicConfigs, err = tm.legacyGetTopicConfigs()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var orphaned []string
+
+	for topic, config := range topicConfigs {
+		if _, exists := tm.reassignments[topic]; exists {
+			continue
+		}
+
+		// Topic throttles are required for broker overrides to apply.
+		if len(tm.brokerOverrides) > 0 {
+			if _, exists := tm.overrideThrottleLists[Topic(topic)]; exists {
+				continue
+			}
+		}
+
+		if config.Throttled() {
+			orphaned = append(orphaned, topic)
+		}
+	}
+
+	sort.Strings(orphaned)
+
+	return orphaned, nil
+}
+
+// RemoveTopicThrottles removes the throttled replicas configs of the topics.
+func (tm *ThrottleManager) RemoveTopicThrottles(topics []string) error {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	return tm.removeTopicThrottlesByName(topics)
+}
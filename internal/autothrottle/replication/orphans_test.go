@@ -0,0 +1,55 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestFindOrphanedTopicThrottles(t *testing.T) {
+	zkWriteInterval = 0
+
+	zk := kafkazk.NewZooKeeperStub()
+	tm := &ThrottleManager{
+		zk: zk,
+		reassignments: kafkazk.Reassignments{
+			"test_topic": map[int][]int{0: {1001, 1002}},
+		},
+	}
+
+	// The stub has throttled replicas set on all topics; reassigning topics
+	// are excluded.
+	orphaned, err := tm.FindOrphanedTopicThrottles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orphaned) != 1 || orphaned[0] != "test_topic2" {
+		t.Errorf("Expected orphaned topics [test_topic2], got %v", orphaned)
+	}
+
+	// Topics replicated by brokers with throttle overrides are excluded.
+	tm.brokerOverrides = throttlestore.BrokerOverrides{
+		1003: {ID: 1003, Config: throttlestore.ThrottleOverrideConfig{Rate: 50}},
+	}
+	tm.overrideThrottleLists = TopicThrottledReplicas{"test_topic2": Throttled{}}
+
+	orphaned, err = tm.FindOrphanedTopicThrottles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orphaned) != 0 {
+		t.Errorf("Expected no orphaned topics, got %v", orphaned)
+	}
+
+	if err := tm.RemoveTopicThrottles([]string{"test_topic2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := zk.KafkaConfigUpdates()
+	if len(updates) != 1 || updates[0].Type != "topic" || updates[0].Name != "test_topic2" {
+		t.Errorf("Expected a config update for topic test_topic2, got %v", updates)
+	}
+}
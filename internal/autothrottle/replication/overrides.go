@@ -0,0 +1,57 @@
+package replication
+
+import (
+	"math"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+// OverrideRate describes a broker override applied to a broker participating
+// in a reassignment.
+type OverrideRate struct {
+	// The override rate in MB/s.
+	Rate int
+	// The override precedence; empty is equivalent to
+	// throttlestore.PrecedenceOverride.
+	Precedence string
+	// Whether the override is a pinned throttle.
+	Pinned bool
+	// The leader and follower rates applied after combining the override with
+	// the determined rates.
+	Effective ThrottleByRole
+}
+
+// combineOverride takes the rates determined for a broker and an override
+// rate and returns the rates to apply according to the precedence. The
+// override rate is used for any role without a determined rate.
+func combineOverride(determined ThrottleByRole, rate float64, precedence string) ThrottleByRole {
+	var effective ThrottleByRole
+
+	for i := range effective {
+		r := rate
+		if d := determined[i]; d != nil {
+			switch precedence {
+			case throttlestore.PrecedenceMin:
+				r = math.Min(*d, rate)
+			case throttlestore.PrecedenceMax:
+				r = math.Max(*d, rate)
+			}
+		}
+		effective[i] = &r
+	}
+
+	return effective
+}
+
+// OverrideRates returns the broker overrides applied to brokers participating
+// in the most recent reassignment throttle update.
+func (tm *ThrottleManager) OverrideRates() map[int]OverrideRate {
+	rates := map[int]OverrideRate{}
+	for id, r := range tm.resolutions {
+		if r.Override != nil {
+			rates[id] = *r.Override
+		}
+	}
+
+	return rates
+}
@@ -0,0 +1,71 @@
+package replication
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// movingPartitions returns the number of partitions the broker is actively
+// replicating in the role; partitions it leads as a source, or new replicas
+// it's fetching as a destination.
+func (bm reassigningBrokers) movingPartitions(id int, role ReplicaType) int {
+	list := "leaders"
+	if role == "follower" {
+		list = "followers"
+	}
+
+	suffix := ":" + strconv.Itoa(id)
+
+	var n int
+	for _, throttled := range bm.throttledReplicas {
+		for _, r := range throttled[ReplicaType(list)] {
+			if strings.HasSuffix(r, suffix) {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// partitionEstimate takes a broker, role, the rate determined from the
+// broker's replication headroom and the number of partitions the broker is
+// moving in the role. If a per-partition minimum is configured, the rate
+// required to replicate each partition at the minimum is returned where it
+// exceeds the headroom rate. This is capped at the configured maximum portion
+// of the broker's capacity and any absolute maximum, but not reduced by
+// non-replication utilization; dividing the headroom of a busy broker among
+// its moving partitions would otherwise leave each at a uselessly low rate.
+func (l Limits) partitionEstimate(b *kafkametrics.Broker, rt ReplicaType, rate float64, partitions int) float64 {
+	min, exists := l["partitionMin"]
+	if !exists || partitions == 0 {
+		return rate
+	}
+
+	c, exists := l.Capacity(b.InstanceType)
+	if !exists {
+		return rate
+	}
+
+	var capacity, maxRatio float64
+	var absMaxKey string
+
+	switch rt {
+	case "leader":
+		capacity, maxRatio, absMaxKey = c.TX, l["srcMax"], "srcMaxAbs"
+	case "follower":
+		capacity, maxRatio, absMaxKey = c.RX, l["dstMax"], "dstMaxAbs"
+	default:
+		return rate
+	}
+
+	estimate := math.Min(min*float64(partitions), capacity*(maxRatio/100))
+	if v, exists := l[absMaxKey]; exists {
+		estimate = math.Min(estimate, v)
+	}
+
+	return math.Max(rate, estimate)
+}
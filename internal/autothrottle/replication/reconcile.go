@@ -0,0 +1,255 @@
+package replication
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+var (
+	brokerThrottleCfgNames = [2]string{
+		"leader.replication.throttled.rate",
+		"follower.replication.throttled.rate",
+	}
+	topicThrottleCfgNames = [2]string{
+		kafkazk.TopicThrottledLeadersConfig,
+		kafkazk.TopicThrottledFollowersConfig,
+	}
+)
+
+// UnknownThrottles describes replication throttle configs found in Kafka that
+// autothrottle has no record of.
+type UnknownThrottles struct {
+	// Brokers with leader and/or follower throttle rates set, in MB/s.
+	Brokers ReplicationCapacityByBroker
+	// Topics with leader and/or follower throttled replicas set.
+	Topics []string
+}
+
+// Empty returns whether any unknown throttles were found.
+func (u UnknownThrottles) Empty() bool {
+	return len(u.Brokers) == 0 && len(u.Topics) == 0
+}
+
+// BrokerIDs returns a sorted []int of brokers with unknown throttles.
+func (u UnknownThrottles) BrokerIDs() []int {
+	var ids []int
+	for id := range u.Brokers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
+
+// FindUnknownThrottles scans all broker and topic dynamic configs for
+// replication throttles. Throttles are excluded if they're recorded in the
+// ThrottleManager state: brokers with overrides or pinned throttles set,
+// brokers participating in a reassignment and topics being reassigned.
+func (tm *ThrottleManager) FindUnknownThrottles() (UnknownThrottles, error) {
+	var brokerConfigs map[int]map[string]string
+	var topicConfigs map[string]kafkazk.TopicThrottleConfig
+	var err error
+
+	if tm.kafkaNativeMode {
+		brokerConfigs, topicConfigs, err = tm.getThrottleConfigs()
+	} else {
+		brokerConfigs, topicConfigs, err = tm.legacyGetThrottleConfigs()
+	}
+
+	if err != nil {
+		return UnknownThrottles{}, err
+	}
+
+	unknown := UnknownThrottles{Brokers: make(ReplicationCapacityByBroker)}
+
+	for id, configs := range brokerConfigs {
+		if _, exists := tm.brokerOverrides[id]; exists {
+			continue
+		}
+		if _, exists := tm.reassigningBrokers.all[id]; exists {
+			continue
+		}
+
+		for i, name := range brokerThrottleCfgNames {
+			v, exists := configs[name]
+			if !exists || v == "" {
+				continue
+			}
+
+			rateBytes, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return unknown, fmt.Errorf("error parsing %s for broker %d: %s", name, id, err)
+			}
+
+			rate := rateBytes / 1000000.00
+			switch i {
+			case 0:
+				unknown.Brokers.storeLeaderCapacity(id, rate)
+			case 1:
+				unknown.Brokers.storeFollowerCapacity(id, rate)
+			}
+		}
+	}
+
+	for topic, config := range topicConfigs {
+		if _, exists := tm.reassignments[topic]; exists {
+			continue
+		}
+
+		if config.Throttled() {
+			unknown.Topics = append(unknown.Topics, topic)
+		}
+	}
+
+	sort.Strings(unknown.Topics)
+
+	return unknown, nil
+}
+
+// AdoptThrottles takes an UnknownThrottles and records the broker throttle
+// rates as previously set by autothrottle. Adopted throttles are subsequently
+// managed as any other throttles set by autothrottle.
+func (tm *ThrottleManager) AdoptThrottles(u UnknownThrottles) {
+	for id, rates := range u.Brokers {
+		tm.previouslySetThrottles[id] = rates
+	}
+}
+
+// RemoveThrottles takes an UnknownThrottles and removes the broker and topic
+// throttle configs.
+func (tm *ThrottleManager) RemoveThrottles(u UnknownThrottles) error {
+	if len(u.Topics) > 0 {
+		if err := tm.removeTopicThrottlesByName(u.Topics); err != nil {
+			return err
+		}
+	}
+
+	if len(u.Brokers) > 0 {
+		ids := make(map[int]struct{}, len(u.Brokers))
+		for id := range u.Brokers {
+			ids[id] = struct{}{}
+		}
+
+		if err := tm.removeBrokerThrottlesByID(ids, ReasonRemoved); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getThrottleConfigs returns the dynamic configs for all brokers and topics.
+func (tm *ThrottleManager) getThrottleConfigs() (map[int]map[string]string, map[string]kafkazk.TopicThrottleConfig, error) {
+	ctx, cancel := tm.kafkaRequestContext()
+	defer cancel()
+
+	ids, err := tm.ka.ListBrokers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var brokerConfigs = make(map[int]map[string]string)
+
+	if len(ids) > 0 {
+		ctx, cancel = tm.kafkaRequestContext()
+		defer cancel()
+
+		brokerCfgs, errs := tm.ka.BulkGetDynamicConfigs(ctx, ids)
+		if errs != nil {
+			return nil, nil, errs
+		}
+
+		for name, configs := range brokerCfgs {
+			id, err := strconv.Atoi(name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid broker ID %s", name)
+			}
+			brokerConfigs[id] = configs
+		}
+	}
+
+	topicConfigs, err := tm.getTopicConfigs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return brokerConfigs, topicConfigs, nil
+}
+
+// getTopicConfigs returns the dynamic configs for all topics.
+func (tm *ThrottleManager) getTopicConfigs() (map[string]kafkazk.TopicThrottleConfig, error) {
+	ctx, cancel := tm.kafkaRequestContext()
+	defer cancel()
+
+	states, err := tm.ka.DescribeTopics(ctx, []string{".*"})
+	if err != nil {
+		return nil, err
+	}
+
+	var topicNames []string
+	for name := range states {
+		topicNames = append(topicNames, name)
+	}
+
+	if len(topicNames) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel = tm.kafkaRequestContext()
+	defer cancel()
+
+	configs, err := tm.ka.GetDynamicConfigs(ctx, "topic", topicNames)
+	if err != nil {
+		return nil, err
+	}
+
+	topicConfigs := make(map[string]kafkazk.TopicThrottleConfig, len(configs))
+	for topic, c := range configs {
+		topicConfigs[topic] = kafkazk.NewTopicThrottleConfig(c)
+	}
+
+	return topicConfigs, nil
+}
+
+// legacyGetThrottleConfigs returns the dynamic configs for all brokers and
+// topics from ZooKeeper.
+func (tm *ThrottleManager) legacyGetThrottleConfigs() (map[int]map[string]string, map[string]kafkazk.TopicThrottleConfig, error) {
+	brokers, errs := tm.zk.GetAllBrokerMeta(false)
+	if errs != nil {
+		return nil, nil, errs[0]
+	}
+
+	var brokerConfigs = make(map[int]map[string]string)
+	for id := range brokers {
+		config, err := tm.zk.GetBrokerConfig(id)
+		switch err.(type) {
+		case nil:
+			brokerConfigs[id] = config.Config
+		case kafkazk.ErrNoNode:
+			// Brokers that never had dynamic configs set have no config znode.
+		default:
+			return nil, nil, err
+		}
+	}
+
+	topicConfigs, err := tm.legacyGetTopicConfigs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return brokerConfigs, topicConfigs, nil
+}
+
+// legacyGetTopicConfigs returns the dynamic configs for all topics from
+// ZooKeeper.
+func (tm *ThrottleManager) legacyGetTopicConfigs() (map[string]kafkazk.TopicThrottleConfig, error) {
+	topics, err := tm.zk.GetTopics(topicsRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	return kafkazk.GetTopicThrottleConfigs(tm.zk, topics)
+}
@@ -0,0 +1,61 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestFindUnknownThrottles(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	tm := &ThrottleManager{
+		zk: zk,
+		reassignments: kafkazk.Reassignments{
+			"test_topic": map[int][]int{0: {1001, 1002}},
+		},
+		reassigningBrokers: reassigningBrokers{
+			all: map[int]struct{}{1001: {}, 1002: {}},
+		},
+		brokerOverrides: throttlestore.BrokerOverrides{
+			1003: {ID: 1003, Config: throttlestore.ThrottleOverrideConfig{Rate: 50}},
+		},
+		previouslySetThrottles: make(ReplicationCapacityByBroker),
+	}
+
+	// The stub has throttles set on all brokers and topics.
+	unknown, err := tm.FindUnknownThrottles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reassigning brokers and brokers with overrides are excluded.
+	expectedBrokers := []int{1004, 1005, 1007}
+	ids := unknown.BrokerIDs()
+
+	if len(ids) != len(expectedBrokers) {
+		t.Fatalf("Expected brokers %v, got %v", expectedBrokers, ids)
+	}
+
+	for i := range ids {
+		if ids[i] != expectedBrokers[i] {
+			t.Errorf("Expected brokers %v, got %v", expectedBrokers, ids)
+		}
+	}
+
+	if rate := unknown.Brokers[1004][0]; rate == nil || *rate != 100 {
+		t.Errorf("Expected leader rate 100 for broker 1004, got %v", rate)
+	}
+
+	// Reassigning topics are excluded.
+	if len(unknown.Topics) != 1 || unknown.Topics[0] != "test_topic2" {
+		t.Errorf("Expected topics [test_topic2], got %v", unknown.Topics)
+	}
+
+	// Adopted throttles are recorded as previously set.
+	tm.AdoptThrottles(unknown)
+
+	if rate := tm.previouslySetThrottles[1005][1]; rate == nil || *rate != 100 {
+		t.Errorf("Expected adopted follower rate 100 for broker 1005, got %v", rate)
+	}
+}
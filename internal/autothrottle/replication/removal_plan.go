@@ -0,0 +1,106 @@
+package replication
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// RemovalPlan describes the throttle configs that RemoveAllThrottles deletes.
+type RemovalPlan struct {
+	// The configs currently set, ordered by broker ID and then topic name.
+	Configs []PlannedRemoval
+}
+
+// PlannedRemoval is a broker or topic throttle config to be deleted.
+type PlannedRemoval struct {
+	// The resource type, broker or topic, and the broker ID or topic name.
+	Type string
+	Name string
+	// The config name and its current value.
+	Config string
+	Value  string
+}
+
+// Empty returns whether no throttle configs would be deleted.
+func (p RemovalPlan) Empty() bool {
+	return len(p.Configs) == 0
+}
+
+// String describes the config, e.g. "broker 1001
+// leader.replication.throttled.rate=100000000". Throttled replicas lists are
+// summarized by their length, as they can be several hundred KB.
+func (r PlannedRemoval) String() string {
+	if r.Type != "topic" {
+		return fmt.Sprintf("%s %s %s=%s", r.Type, r.Name, r.Config, r.Value)
+	}
+
+	var summary string
+	switch replicas := len(kafkazk.ParseThrottledReplicas(r.Value)); {
+	case r.Value == "*":
+		summary = "all replicas"
+	case replicas == 1:
+		summary = "1 replica"
+	default:
+		summary = fmt.Sprintf("%d replicas", replicas)
+	}
+
+	return fmt.Sprintf("%s %s %s (%s)", r.Type, r.Name, r.Config, summary)
+}
+
+// PlanRemoveAllThrottles returns the RemovalPlan of broker and topic throttle
+// configs currently set that a RemoveAllThrottles call would delete. Brokers
+// with a throttle override where AutoRemove is false are excluded, as their
+// throttles are retained.
+func (tm *ThrottleManager) PlanRemoveAllThrottles() (RemovalPlan, error) {
+	var brokerConfigs map[int]map[string]string
+	var topicConfigs map[string]kafkazk.TopicThrottleConfig
+	var err error
+
+	if tm.kafkaNativeMode {
+		brokerConfigs, topicConfigs, err = tm.getThrottleConfigs()
+	} else {
+		brokerConfigs, topicConfigs, err = tm.legacyGetThrottleConfigs()
+	}
+
+	if err != nil {
+		return RemovalPlan{}, err
+	}
+
+	var plan RemovalPlan
+
+	var ids []int
+	for id := range brokerConfigs {
+		if override, exists := tm.brokerOverrides[id]; exists && !override.Config.AutoRemove {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		for _, name := range brokerThrottleCfgNames {
+			if v := brokerConfigs[id][name]; v != "" {
+				plan.Configs = append(plan.Configs, PlannedRemoval{Type: "broker", Name: strconv.Itoa(id), Config: name, Value: v})
+			}
+		}
+	}
+
+	var topics []string
+	for topic := range topicConfigs {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	for _, topic := range topics {
+		for _, name := range topicThrottleCfgNames {
+			if v := topicConfigs[topic].Config[name]; v != "" {
+				plan.Configs = append(plan.Configs, PlannedRemoval{Type: "topic", Name: topic, Config: name, Value: v})
+			}
+		}
+	}
+
+	return plan, nil
+}
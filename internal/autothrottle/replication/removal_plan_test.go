@@ -0,0 +1,54 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestPlanRemoveAllThrottles(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	tm := &ThrottleManager{
+		zk: zk,
+		brokerOverrides: throttlestore.BrokerOverrides{
+			1001: {ID: 1001, Config: throttlestore.ThrottleOverrideConfig{Rate: 50}},
+			1002: {ID: 1002, Config: throttlestore.ThrottleOverrideConfig{Rate: 50, AutoRemove: true}},
+		},
+	}
+
+	// The stub has throttles set on all brokers and topics.
+	plan, err := tm.PlanRemoveAllThrottles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Brokers with an override where AutoRemove is false are excluded.
+	for _, r := range plan.Configs {
+		if r.Type == "broker" && r.Name == "1001" {
+			t.Errorf("Unexpected planned removal %s", r)
+		}
+	}
+
+	first, last := plan.Configs[0], plan.Configs[len(plan.Configs)-1]
+
+	expected := "broker 1002 leader.replication.throttled.rate=100000000"
+	if first.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, first)
+	}
+
+	expected = "topic test_topic2 follower.replication.throttled.replicas (2 replicas)"
+	if last.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, last)
+	}
+
+	// Nothing is removed.
+	if updates := zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	wildcard := PlannedRemoval{Type: "topic", Name: "test", Config: topicThrottleCfgNames[0], Value: "*"}
+	if s := wildcard.String(); s != "topic test leader.replication.throttled.replicas (all replicas)" {
+		t.Errorf("Unexpected string %s", s)
+	}
+}
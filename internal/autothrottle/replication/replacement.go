@@ -0,0 +1,85 @@
+package replication
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// brokerIdentity is the host and instance type last observed in the metrics
+// for a broker ID.
+type brokerIdentity struct {
+	host         string
+	instanceType string
+}
+
+// replacedBrokers takes a kafkametrics.BrokerMetrics and returns the IDs of any
+// brokers whose host or instance type changed since they were last observed,
+// indicating that the broker ID was reused by a replacement broker. The
+// observed identities are recorded.
+func (tm *ThrottleManager) replacedBrokers(bm kafkametrics.BrokerMetrics) []int {
+	var replaced []int
+
+	for id, b := range bm {
+		current := brokerIdentity{host: b.Host, instanceType: b.InstanceType}
+		previous, seen := tm.brokerIdentities[id]
+		tm.brokerIdentities[id] = current
+
+		if seen && previous != current {
+			log.Printf("Broker %d replaced: host %s (%s) -> %s (%s)\n",
+				id, previous.host, previous.instanceType, current.host, current.instanceType)
+			replaced = append(replaced, id)
+		}
+	}
+
+	sort.Ints(replaced)
+
+	return replaced
+}
+
+// handleReplacedBrokers takes the IDs of replaced brokers and drops any state
+// carried over from the previous brokers: previously set throttles are cleared,
+// so that headroom isn't credited with the old broker's throttle and the rate is
+// applied again, any peaks recorded for capacity calibration are discarded, and
+// any cached metrics metadata is invalidated so that the broker's host mapping
+// and instance type are resolved again. It returns whether metrics metadata was
+// invalidated.
+func (tm *ThrottleManager) handleReplacedBrokers(ids []int) bool {
+	invalidator, canInvalidate := tm.km.(kafkametrics.BrokerCacheInvalidator)
+
+	var b bytes.Buffer
+	b.WriteString("Broker IDs reused by replacement brokers; previous throttles cleared: ")
+
+	for _, id := range ids {
+		delete(tm.previouslySetThrottles, id)
+		tm.resetPeaks(id)
+
+		if canInvalidate {
+			tm.metricsMu.Lock()
+			invalidator.InvalidateBroker(id)
+			tm.metricsMu.Unlock()
+		}
+
+		b.WriteString(fmt.Sprintf("%d ", id))
+	}
+
+	tm.events.Write("Broker replacement detected", b.String())
+
+	return canInvalidate
+}
+
+// BrokerInstanceTypes returns a map of broker ID to the instance type last
+// observed in the metrics for each broker.
+func (tm *ThrottleManager) BrokerInstanceTypes() map[int]string {
+	instanceTypes := make(map[int]string, len(tm.brokerIdentities))
+	for id, identity := range tm.brokerIdentities {
+		if identity.instanceType != "" {
+			instanceTypes[id] = identity.instanceType
+		}
+	}
+
+	return instanceTypes
+}
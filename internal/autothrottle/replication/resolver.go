@@ -0,0 +1,165 @@
+package replication
+
+import (
+	"fmt"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+// Resolver determines the throttle rates applied to a broker participating in
+// a reassignment from the available rate sources. The determined rates are,
+// in order of precedence:
+//
+//   - guardrails: while the cluster health guardrails are tripped, the
+//     minimum rates are used and broker overrides are ignored.
+//   - global override: the global throttle override rate is used for all
+//     roles.
+//   - metrics failure: once the metrics failure threshold is exceeded, the
+//     minimum rates are used.
+//   - broker lost: brokers replicating to a lost destination broker use the
+//     minimum rates.
+//   - computed: the rates determined from broker metrics and the configured
+//     limits.
+//
+// A broker override is then combined with the determined rates according to
+// its precedence: by default the override rate replaces them, whereas with
+// the min or max precedence the lower or higher rate is used for each role.
+// The override rate is used for any role without a determined rate. A pinned
+// throttle always replaces the determined rates, including while the
+// guardrails are tripped, and takes the place of any broker override.
+// Overrides scheduled to start later and overrides with a rate of 0, which
+// are pending removal, aren't applied.
+type Resolver struct {
+	// Whether the cluster health guardrails are tripped.
+	GuardrailsTripped bool
+	// The global throttle override rate in MB/s; unset if 0.
+	GlobalOverride int
+	// Whether the metrics failure threshold is exceeded.
+	MetricsFailure bool
+}
+
+// ResolverInput holds the rate sources of a broker.
+type ResolverInput struct {
+	// The rates determined prior to any broker override, according to the
+	// Resolver.
+	Determined ThrottleByRole
+	// Whether the broker is replicating to a lost destination broker.
+	LostDestinationSource bool
+	// The broker override or pinned throttle set for the broker, if any.
+	Override *throttlestore.BrokerThrottleOverride
+}
+
+// Resolution describes the throttle rates resolved for a broker.
+type Resolution struct {
+	// The rates to apply.
+	Rates ThrottleByRole
+	// The source of the rates, as a Decision reason.
+	Reason string
+	// The broker override applied, if any.
+	Override *OverrideRate
+	// Each rate source considered, in the order they were considered.
+	Steps []ResolutionStep
+}
+
+// ResolutionStep describes a rate source considered for a broker.
+type ResolutionStep struct {
+	// The rate source, as a Decision reason.
+	Source string
+	// Whether the source was applied.
+	Applied bool
+	// Why the source was or wasn't applied.
+	Detail string
+	// The rates after the step, if applied.
+	Rates ThrottleByRole
+}
+
+// Resolve takes a ResolverInput and returns the Resolution for the broker.
+func (r Resolver) Resolve(in ResolverInput) Resolution {
+	res := Resolution{Rates: in.Determined}
+
+	var detail string
+	switch {
+	case r.GuardrailsTripped:
+		res.Reason, detail = ReasonGuardrails, "the cluster health guardrails are tripped; using the minimum rates"
+	case r.GlobalOverride != 0:
+		res.Reason, detail = ReasonGlobalOverride, fmt.Sprintf("a global throttle override is set: %dMB/s", r.GlobalOverride)
+	case r.MetricsFailure:
+		res.Reason, detail = ReasonMetricsFailure, "the metrics failure threshold is exceeded; using the minimum rates"
+	case in.LostDestinationSource:
+		res.Reason, detail = ReasonBrokerLost, "replicating to a lost broker; using the minimum rates"
+	default:
+		res.Reason, detail = ReasonComputed, "determined from broker metrics and the configured limits"
+	}
+
+	res.Steps = append(res.Steps, ResolutionStep{
+		Source:  res.Reason,
+		Applied: true,
+		Detail:  detail,
+		Rates:   in.Determined,
+	})
+
+	o := in.Override
+	if o == nil {
+		return res
+	}
+
+	step := ResolutionStep{Source: ReasonBrokerOverride}
+	if o.Pinned {
+		step.Source = ReasonPinned
+	}
+
+	rate := o.Config.Active().Rate
+	// Pinned throttles are always used as-is.
+	precedence := o.Config.Precedence
+	if o.Pinned {
+		precedence = ""
+	}
+
+	switch {
+	case o.Scheduled || (rate == 0 && o.Config.Rate != 0):
+		step.Detail = "scheduled to start later"
+	case rate == 0:
+		step.Detail = "pending removal"
+	case r.GuardrailsTripped && !o.Pinned:
+		step.Detail = fmt.Sprintf("%dMB/s ignored while the cluster health guardrails are tripped", rate)
+	default:
+		effective := combineOverride(in.Determined, float64(rate), precedence)
+
+		step.Applied = true
+		step.Detail = fmt.Sprintf("%dMB/s, precedence==%s", rate, precedenceName(precedence))
+		step.Rates = effective
+
+		res.Rates = effective
+		res.Reason = step.Source
+		res.Override = &OverrideRate{
+			Rate:       rate,
+			Precedence: precedence,
+			Pinned:     o.Pinned,
+			Effective:  effective,
+		}
+	}
+
+	res.Steps = append(res.Steps, step)
+
+	return res
+}
+
+// precedenceName returns the name of an override precedence, where empty is
+// equivalent to throttlestore.PrecedenceOverride.
+func precedenceName(p string) string {
+	if p == "" {
+		return throttlestore.PrecedenceOverride
+	}
+	return p
+}
+
+// Resolutions returns the Resolution for each broker participating in the
+// most recent reassignment throttle update.
+func (tm *ThrottleManager) Resolutions() map[int]Resolution {
+	resolutions := make(map[int]Resolution, len(tm.resolutions))
+	for id, r := range tm.resolutions {
+		resolutions[id] = r
+	}
+
+	return resolutions
+}
@@ -0,0 +1,111 @@
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+)
+
+func TestResolve(t *testing.T) {
+	determined := ThrottleByRole{float64ptr(100), nil}
+	later := time.Now().Add(time.Hour).Unix()
+
+	override := func(rate int, precedence string) *throttlestore.BrokerThrottleOverride {
+		return &throttlestore.BrokerThrottleOverride{
+			ID:     1001,
+			Config: throttlestore.ThrottleOverrideConfig{Rate: rate, Precedence: precedence},
+		}
+	}
+
+	pin := func(rate int) *throttlestore.BrokerThrottleOverride {
+		o := override(rate, throttlestore.PrecedenceMin)
+		o.Pinned = true
+		return o
+	}
+
+	scheduled := override(50, "")
+	scheduled.Config.StartAt = later
+
+	expiredScheduled := override(0, "")
+	expiredScheduled.Scheduled = true
+
+	tests := []struct {
+		name     string
+		resolver Resolver
+		lost     bool
+		override *throttlestore.BrokerThrottleOverride
+		// Expected leader and follower rates; -1 if unset.
+		expected [2]float64
+		reason   string
+		steps    int
+	}{
+		{"computed", Resolver{}, false, nil, [2]float64{100, -1}, ReasonComputed, 1},
+		{"broker lost", Resolver{}, true, nil, [2]float64{100, -1}, ReasonBrokerLost, 1},
+		{"metrics failure", Resolver{MetricsFailure: true}, true, nil, [2]float64{100, -1}, ReasonMetricsFailure, 1},
+		{"global override", Resolver{GlobalOverride: 100}, true, nil, [2]float64{100, -1}, ReasonGlobalOverride, 1},
+		{"guardrails", Resolver{GuardrailsTripped: true, GlobalOverride: 100}, false, nil, [2]float64{100, -1}, ReasonGuardrails, 1},
+		// Broker overrides replace the determined rates by default, and are
+		// used for roles without a determined rate.
+		{"override", Resolver{}, false, override(50, ""), [2]float64{50, 50}, ReasonBrokerOverride, 2},
+		{"override precedence", Resolver{}, false, override(150, throttlestore.PrecedenceOverride), [2]float64{150, 150}, ReasonBrokerOverride, 2},
+		{"override min", Resolver{}, false, override(150, throttlestore.PrecedenceMin), [2]float64{100, 150}, ReasonBrokerOverride, 2},
+		{"override max", Resolver{}, false, override(50, throttlestore.PrecedenceMax), [2]float64{100, 50}, ReasonBrokerOverride, 2},
+		{"override global override", Resolver{GlobalOverride: 100}, false, override(50, ""), [2]float64{50, 50}, ReasonBrokerOverride, 2},
+		{"override metrics failure", Resolver{MetricsFailure: true}, false, override(50, throttlestore.PrecedenceMax), [2]float64{100, 50}, ReasonBrokerOverride, 2},
+		{"override broker lost", Resolver{}, true, override(50, throttlestore.PrecedenceMin), [2]float64{50, 50}, ReasonBrokerOverride, 2},
+		// Broker overrides are ignored while the guardrails are tripped.
+		{"override guardrails", Resolver{GuardrailsTripped: true}, false, override(50, ""), [2]float64{100, -1}, ReasonGuardrails, 2},
+		// Overrides pending removal or a scheduled start aren't applied.
+		{"override removal", Resolver{}, false, override(0, ""), [2]float64{100, -1}, ReasonComputed, 2},
+		{"override scheduled", Resolver{}, false, scheduled, [2]float64{100, -1}, ReasonComputed, 2},
+		{"override scheduled expired", Resolver{}, false, expiredScheduled, [2]float64{100, -1}, ReasonComputed, 2},
+		// Pins replace the determined rates regardless of their precedence and
+		// the guardrails.
+		{"pinned", Resolver{}, false, pin(150), [2]float64{150, 150}, ReasonPinned, 2},
+		{"pinned global override", Resolver{GlobalOverride: 100}, false, pin(150), [2]float64{150, 150}, ReasonPinned, 2},
+		{"pinned guardrails", Resolver{GuardrailsTripped: true}, false, pin(150), [2]float64{150, 150}, ReasonPinned, 2},
+		{"pinned removal", Resolver{GuardrailsTripped: true}, false, pin(0), [2]float64{100, -1}, ReasonGuardrails, 2},
+	}
+
+	for _, test := range tests {
+		res := test.resolver.Resolve(ResolverInput{
+			Determined:            determined,
+			LostDestinationSource: test.lost,
+			Override:              test.override,
+		})
+
+		for i, expected := range test.expected {
+			got := res.Rates[i]
+			switch {
+			case expected == -1 && got != nil:
+				t.Errorf("[%s] Expected no %s rate, got %.2f", test.name, roleFromIndex(i), *got)
+			case expected != -1 && (got == nil || *got != expected):
+				t.Errorf("[%s] Expected %s rate %.2f, got %v", test.name, roleFromIndex(i), expected, got)
+			}
+		}
+
+		if res.Reason != test.reason {
+			t.Errorf("[%s] Expected reason %s, got %s", test.name, test.reason, res.Reason)
+		}
+
+		if len(res.Steps) != test.steps {
+			t.Fatalf("[%s] Expected %d steps, got %d", test.name, test.steps, len(res.Steps))
+		}
+
+		// The determined rates are always applied first.
+		if !res.Steps[0].Applied {
+			t.Errorf("[%s] Expected the first step applied", test.name)
+		}
+
+		// The Override is set only when an override step was applied.
+		applied := test.steps == 2 && res.Steps[1].Applied
+		if applied != (res.Override != nil) {
+			t.Errorf("[%s] Expected an override %v, got %+v", test.name, applied, res.Override)
+		}
+
+		if applied && res.Override.Pinned != (res.Reason == ReasonPinned) {
+			t.Errorf("[%s] Unexpected override %+v", test.name, res.Override)
+		}
+	}
+}
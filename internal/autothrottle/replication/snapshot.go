@@ -0,0 +1,201 @@
+package replication
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// ErrConfigSnapshotsDisabled is returned when restoring a config snapshot with
+// snapshots disabled.
+var ErrConfigSnapshotsDisabled = errors.New("config snapshots are disabled")
+
+// snapshotConfigs records the current values of the configs in expected for
+// the brokers or topics (according to kind) that aren't yet in the config
+// snapshot. Values recorded earlier are never overwritten, so the snapshot
+// holds each config as it was before autothrottle first wrote to it.
+func (tm *ThrottleManager) snapshotConfigs(kind string, expected configExpectations) error {
+	if tm.snapshotPath == "" {
+		return nil
+	}
+
+	// The snapshot may be removed through the admin API at any time; it's read
+	// ahead of each write rather than cached.
+	s, err := throttlestore.FetchConfigSnapshot(tm.zk, tm.snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	resources := s.Resources(kind)
+
+	var names []string
+	for name, configs := range expected {
+		for config := range configs {
+			if _, exists := resources[name][config]; !exists {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	sort.Strings(names)
+
+	current, err := tm.currentConfigs(kind, names)
+	if err != nil {
+		return fmt.Errorf("error reading %s configs for snapshot: %s", kind, err)
+	}
+
+	for _, name := range names {
+		if resources[name] == nil {
+			resources[name] = map[string]string{}
+		}
+		for config := range expected[name] {
+			if _, exists := resources[name][config]; !exists {
+				resources[name][config] = current[name][config]
+			}
+		}
+	}
+
+	if s.Created == 0 {
+		s.Created = time.Now().Unix()
+	}
+
+	return throttlestore.StoreConfigSnapshot(tm.zk, tm.snapshotPath, s)
+}
+
+// RestoreConfigSnapshot writes the config values recorded in the config
+// snapshot back to each broker and topic, then removes the snapshot so that a
+// new one is taken ahead of subsequent writes. Previously set throttles are
+// reset. The restored snapshot is returned.
+func (tm *ThrottleManager) RestoreConfigSnapshot() (throttlestore.ConfigSnapshot, error) {
+	if tm.snapshotPath == "" {
+		return throttlestore.ConfigSnapshot{}, ErrConfigSnapshotsDisabled
+	}
+
+	s, err := throttlestore.FetchConfigSnapshot(tm.zk, tm.snapshotPath)
+	if err != nil {
+		return s, err
+	}
+
+	if err := tm.restoreConfigs("broker", s.Brokers); err != nil {
+		return s, fmt.Errorf("error restoring broker configs: %s", err)
+	}
+
+	if err := tm.restoreConfigs("topic", s.Topics); err != nil {
+		return s, fmt.Errorf("error restoring topic configs: %s", err)
+	}
+
+	tm.previouslySetThrottles.reset()
+	tm.ResetTopicThrottles()
+
+	return s, throttlestore.RemoveConfigSnapshot(tm.zk, tm.snapshotPath)
+}
+
+// restoreConfigs sets the configs of the specified kind to the provided values,
+// unsetting any configs with an empty value.
+func (tm *ThrottleManager) restoreConfigs(kind string, values map[string]map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !tm.kafkaNativeMode {
+		return tm.legacyRestoreConfigs(kind, names, values)
+	}
+
+	ctx, cancel := tm.kafkaRequestContext()
+	defer cancel()
+
+	switch kind {
+	case "broker":
+		var ids []int
+		for _, name := range names {
+			id, err := strconv.Atoi(name)
+			if err != nil {
+				return fmt.Errorf("invalid broker ID %s", name)
+			}
+			ids = append(ids, id)
+		}
+
+		// Broker dynamic configs are replaced in full.
+		current, errs := tm.ka.BulkGetDynamicConfigs(ctx, ids)
+		if errs != nil {
+			return errs
+		}
+
+		configs := kafkaadmin.ResourceConfigs{}
+		for _, name := range names {
+			configs[name] = map[string]string{}
+			for k, v := range current[name] {
+				configs[name][k] = v
+			}
+			for k, v := range values[name] {
+				if v == "" {
+					delete(configs[name], k)
+				} else {
+					configs[name][k] = v
+				}
+			}
+		}
+
+		if errs := tm.ka.BulkSetDynamicConfigs(ctx, configs); errs != nil {
+			return errs
+		}
+
+		return nil
+	default:
+		// Topic configs are merged into the existing configs and can't be unset
+		// this way; clear the throttle configs, then set any recorded values.
+		if err := tm.ka.RemoveThrottle(ctx, kafkaadmin.RemoveThrottleConfig{Topics: names}); err != nil {
+			return err
+		}
+
+		configs := kafkaadmin.ResourceConfigs{}
+		for _, name := range names {
+			for k, v := range values[name] {
+				if v != "" {
+					configs.AddConfig(name, k, v)
+				}
+			}
+		}
+
+		return tm.ka.SetTopicConfigs(ctx, configs)
+	}
+}
+
+// legacyRestoreConfigs is the ZooKeeper mode restoreConfigs.
+func (tm *ThrottleManager) legacyRestoreConfigs(kind string, names []string, values map[string]map[string]string) error {
+	for _, name := range names {
+		var keys []string
+		for k := range values[name] {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		config := kafkazk.KafkaConfig{Type: kind, Name: name}
+		for _, k := range keys {
+			config.Configs = append(config.Configs, kafkazk.KafkaConfigKV{k, values[name][k]})
+		}
+
+		if _, err := tm.zk.UpdateKafkaConfig(config); err != nil {
+			return fmt.Errorf("%s %s: %s", kind, name, err)
+		}
+	}
+
+	return nil
+}
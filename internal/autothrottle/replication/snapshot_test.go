@@ -0,0 +1,113 @@
+package replication
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestConfigSnapshot(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	path := "/autothrottle/config_snapshot"
+
+	tm := &ThrottleManager{
+		zk:                     zk,
+		events:                 &eventsStub{},
+		snapshotPath:           path,
+		previouslySetThrottles: make(ReplicationCapacityByBroker),
+	}
+
+	write := func() error { return nil }
+
+	// The stub has throttles set on all brokers and topics; these are recorded
+	// ahead of the first write.
+	expected := throttleExpectations([]string{"1001"}, brokerThrottleCfgNames, "200000000")
+	if err := tm.writeAndVerify("broker", expected, write); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = throttleExpectations([]string{"test"}, topicThrottleCfgNames, "0:1001")
+	if err := tm.writeAndVerify("topic", expected, write); err != nil {
+		t.Fatal(err)
+	}
+
+	// Values already recorded aren't overwritten by subsequent writes.
+	expected = throttleExpectations([]string{"1001", "1002"}, brokerThrottleCfgNames, "")
+	if err := tm.writeAndVerify("broker", expected, write); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := throttlestore.FetchConfigSnapshot(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rates := map[string]string{
+		"leader.replication.throttled.rate":   "100000000",
+		"follower.replication.throttled.rate": "100000000",
+	}
+
+	expectedBrokers := map[string]map[string]string{"1001": rates, "1002": rates}
+	if !reflect.DeepEqual(s.Brokers, expectedBrokers) {
+		t.Errorf("Expected broker snapshot %v, got %v", expectedBrokers, s.Brokers)
+	}
+
+	expectedTopics := map[string]map[string]string{
+		"test": {
+			"leader.replication.throttled.replicas":   "0:1001,0:1002",
+			"follower.replication.throttled.replicas": "0:1003,0:1004",
+		},
+	}
+	if !reflect.DeepEqual(s.Topics, expectedTopics) {
+		t.Errorf("Expected topic snapshot %v, got %v", expectedTopics, s.Topics)
+	}
+
+	if s.Created == 0 {
+		t.Error("Expected a snapshot creation time")
+	}
+
+	// Restore the snapshot.
+	tm.previouslySetThrottles.storeLeaderCapacity(1001, 200)
+
+	if _, err := tm.RestoreConfigSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := zk.KafkaConfigUpdates()
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 config updates, got %v", updates)
+	}
+
+	expectedUpdate := kafkazk.KafkaConfig{
+		Type: "broker",
+		Name: "1001",
+		Configs: []kafkazk.KafkaConfigKV{
+			{"follower.replication.throttled.rate", "100000000"},
+			{"leader.replication.throttled.rate", "100000000"},
+		},
+	}
+	if !reflect.DeepEqual(updates[0], expectedUpdate) {
+		t.Errorf("Expected config update %v, got %v", expectedUpdate, updates[0])
+	}
+
+	if updates[2].Type != "topic" || updates[2].Name != "test" {
+		t.Errorf("Expected a topic test config update, got %v", updates[2])
+	}
+
+	if len(tm.previouslySetThrottles) != 0 {
+		t.Errorf("Expected previously set throttles to be reset, got %v", tm.previouslySetThrottles)
+	}
+
+	if exists, _ := zk.Exists(path); exists {
+		t.Error("Expected the snapshot to be removed")
+	}
+
+	// Snapshots disabled.
+	tm.snapshotPath = ""
+	if _, err := tm.RestoreConfigSnapshot(); err != ErrConfigSnapshotsDisabled {
+		t.Errorf("Expected ErrConfigSnapshotsDisabled, got %v", err)
+	}
+}
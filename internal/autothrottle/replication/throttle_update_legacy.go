@@ -16,6 +16,9 @@ import (
 
 var (
 	topicsRegex = []*regexp.Regexp{regexp.MustCompile(".*")}
+	// zkWriteInterval is the time waited between sequential config writes to
+	// reduce ZooKeeper load.
+	zkWriteInterval = 250 * time.Millisecond
 )
 
 func (tm *ThrottleManager) legacyApplyBrokerThrottles(configs map[int]kafkazk.KafkaConfig, capacities ReplicationCapacityByBroker) (chan brokerChangeEvent, []error) {
@@ -23,7 +26,12 @@ func (tm *ThrottleManager) legacyApplyBrokerThrottles(configs map[int]kafkazk.Ka
 	var errs []error
 
 	for ID, config := range configs {
-		changes, err := tm.zk.UpdateKafkaConfig(config)
+		var changes []bool
+		err := tm.writeAndVerify("broker", kafkaConfigExpectations(config), func() error {
+			changed, err := tm.zk.UpdateKafkaConfig(config)
+			changes = mergeChanged(changes, changed)
+			return err
+		})
 		if err != nil {
 			errs = append(errs, fmt.Errorf("Error setting throttle on broker %d: %s", ID, err))
 		}
@@ -39,14 +47,15 @@ func (tm *ThrottleManager) legacyApplyBrokerThrottles(configs map[int]kafkazk.Ka
 				log.Printf("Updated throttle on broker %d [%s]\n", ID, role)
 
 				var rate *float64
+				var prev float64
 
 				// Store the configured rate.
 				switch role {
 				case "leader":
-					rate = capacities[ID][0]
+					rate, prev = capacities[ID][0], tm.previousRate(ID, 0)
 					tm.previouslySetThrottles.storeLeaderCapacity(ID, *rate)
 				case "follower":
-					rate = capacities[ID][1]
+					rate, prev = capacities[ID][1], tm.previousRate(ID, 1)
 					tm.previouslySetThrottles.storeFollowerCapacity(ID, *rate)
 				}
 
@@ -54,13 +63,13 @@ func (tm *ThrottleManager) legacyApplyBrokerThrottles(configs map[int]kafkazk.Ka
 					id:   ID,
 					role: role,
 					rate: *rate,
+					prev: prev,
 				}
 			}
 		}
 
-		// Hard coded sleep to reduce
-		// ZK load.
-		time.Sleep(250 * time.Millisecond)
+		// Sleep to reduce ZK load.
+		time.Sleep(zkWriteInterval)
 	}
 
 	close(events)
@@ -71,6 +80,13 @@ func (tm *ThrottleManager) legacyApplyBrokerThrottles(configs map[int]kafkazk.Ka
 func (tm *ThrottleManager) legacyApplyTopicThrottles(throttled TopicThrottledReplicas) []error {
 	var errs []error
 
+	// Fetch the current lists. Config writes for large topics can be several
+	// hundred KB; we only write if the set of throttled replicas changed.
+	current, err := kafkazk.GetTopicThrottleConfigs(tm.zk, throttled.topics())
+	if err != nil {
+		return []error{fmt.Errorf("Error fetching topic configs: %s\n", err)}
+	}
+
 	for t := range throttled {
 		// Generate config.
 		config := kafkazk.KafkaConfig{
@@ -79,31 +95,55 @@ func (tm *ThrottleManager) legacyApplyTopicThrottles(throttled TopicThrottledRep
 			Configs: []kafkazk.KafkaConfigKV{},
 		}
 
-		// The sort is important; it avoids unecessary config updates due to the same
-		// data but in different orders.
-		sort.Strings(throttled[t]["leaders"])
-		sort.Strings(throttled[t]["followers"])
+		leaderList := throttledReplicasList(string(t), throttled[t]["leaders"])
+		followerList := throttledReplicasList(string(t), throttled[t]["followers"])
 
-		leaderList := strings.Join(throttled[t]["leaders"], ",")
-		if leaderList != "" {
-			c := kafkazk.KafkaConfigKV{"leader.replication.throttled.replicas", leaderList}
-			config.Configs = append(config.Configs, c)
+		// In wildcard mode, all replicas are throttled rather than enumerating
+		// them; the lists for topics with many partitions can otherwise exceed
+		// practical config sizes and cause heavy ZooKeeper churn.
+		if tm.wildcardReplicas {
+			leaderList, followerList = "*", "*"
 		}
 
-		followerList := strings.Join(throttled[t]["followers"], ",")
-		if followerList != "" {
-			c := kafkazk.KafkaConfigKV{"follower.replication.throttled.replicas", followerList}
-			config.Configs = append(config.Configs, c)
+		tc := current[string(t)]
+
+		for i, kv := range []kafkazk.KafkaConfigKV{
+			{kafkazk.TopicThrottledLeadersConfig, leaderList},
+			{kafkazk.TopicThrottledFollowersConfig, followerList},
+		} {
+			if kv[1] == "" {
+				continue
+			}
+
+			currentList := tc.Leaders
+			if i == 1 {
+				currentList = tc.Followers
+			}
+
+			added, removed := replicasListDelta(currentList, kafkazk.ParseThrottledReplicas(kv[1]))
+			if len(added) == 0 && len(removed) == 0 {
+				continue
+			}
+
+			log.Printf("Updating %s on topic %s: %d added, %d removed\n", kv[0], t, len(added), len(removed))
+			config.Configs = append(config.Configs, kv)
+		}
+
+		if len(config.Configs) == 0 {
+			continue
 		}
 
 		// Write the config.
-		_, err := tm.zk.UpdateKafkaConfig(config)
+		err := tm.writeAndVerify("topic", kafkaConfigExpectations(config), func() error {
+			_, err := tm.zk.UpdateKafkaConfig(config)
+			return err
+		})
 		if err != nil {
 			errs = append(errs, fmt.Errorf("Error setting throttle list on topic %s: %s\n", t, err))
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func (tm *ThrottleManager) legacyRemoveTopicThrottles() error {
@@ -112,6 +152,10 @@ func (tm *ThrottleManager) legacyRemoveTopicThrottles() error {
 		return err
 	}
 
+	return tm.legacyRemoveTopicThrottlesByName(topics)
+}
+
+func (tm *ThrottleManager) legacyRemoveTopicThrottlesByName(topics []string) error {
 	var errTopics []string
 
 	for _, topic := range topics {
@@ -125,12 +169,15 @@ func (tm *ThrottleManager) legacyRemoveTopicThrottles() error {
 		}
 
 		// Update the config.
-		_, err := tm.zk.UpdateKafkaConfig(config)
+		err := tm.writeAndVerify("topic", kafkaConfigExpectations(config), func() error {
+			_, err := tm.zk.UpdateKafkaConfig(config)
+			return err
+		})
 		if err != nil {
 			errTopics = append(errTopics, topic)
 		}
 
-		time.Sleep(250 * time.Millisecond)
+		time.Sleep(zkWriteInterval)
 	}
 
 	if errTopics != nil {
@@ -141,7 +188,7 @@ func (tm *ThrottleManager) legacyRemoveTopicThrottles() error {
 	return nil
 }
 
-func (tm *ThrottleManager) legacyRemoveBrokerThrottlesByID(ids map[int]struct{}) error {
+func (tm *ThrottleManager) legacyRemoveBrokerThrottlesByID(ids map[int]struct{}, reason string) error {
 	var unthrottledBrokers []int
 	var errorEncountered bool
 
@@ -156,7 +203,13 @@ func (tm *ThrottleManager) legacyRemoveBrokerThrottlesByID(ids map[int]struct{})
 			},
 		}
 
-		changed, err := tm.zk.UpdateKafkaConfig(config)
+		var changed []bool
+		err := tm.writeAndVerify("broker", kafkaConfigExpectations(config), func() error {
+			c, err := tm.zk.UpdateKafkaConfig(config)
+			changed = mergeChanged(changed, c)
+			return err
+		})
+
 		switch err.(type) {
 		case nil:
 		case kafkazk.ErrNoNode:
@@ -173,13 +226,14 @@ func (tm *ThrottleManager) legacyRemoveBrokerThrottlesByID(ids map[int]struct{})
 		if changed[0] || changed[1] {
 			unthrottledBrokers = append(unthrottledBrokers, b)
 			log.Printf("Throttle removed on broker %d\n", b)
+			tm.writeRemovalDecisions([]int{b}, tm.previouslySetThrottles, reason)
 
 			// Unset the previously stored throttle rate.
 			tm.previouslySetThrottles[b] = [2]*float64{}
 		}
 
 		// Hardcoded sleep to reduce ZK load.
-		time.Sleep(250 * time.Millisecond)
+		time.Sleep(zkWriteInterval)
 	}
 
 	// Write event.
@@ -196,3 +250,72 @@ func (tm *ThrottleManager) legacyRemoveBrokerThrottlesByID(ids map[int]struct{})
 
 	return nil
 }
+
+// maxThrottledReplicasListSize is the maximum size in bytes of an enumerated
+// throttled replicas list. Topic configs are stored in a single znode, which
+// is limited in size (1MB by default via jute.maxbuffer) and also holds the
+// remaining topic configs.
+var maxThrottledReplicasListSize = 512 * 1024
+
+// throttledReplicasList takes a topic name and a list of throttled replicas in
+// the partition:broker format and returns a sorted, deduplicated config value.
+// The sort is important; it avoids unnecessary config updates due to the same
+// data but in different orders. A list exceeding maxThrottledReplicasListSize
+// can't be safely written or split across configs, so "*" (all replicas) is
+// returned in its place.
+func throttledReplicasList(topic string, replicas []string) string {
+	set := map[string]struct{}{}
+	var list []string
+
+	for _, r := range replicas {
+		if _, exists := set[r]; exists {
+			continue
+		}
+		set[r] = struct{}{}
+		list = append(list, r)
+	}
+
+	sort.Strings(list)
+	s := strings.Join(list, ",")
+
+	if len(s) > maxThrottledReplicasListSize {
+		log.Printf("Throttled replicas list for topic %s exceeds %d bytes, throttling all replicas\n",
+			topic, maxThrottledReplicasListSize)
+		return "*"
+	}
+
+	return s
+}
+
+// replicasListDelta takes the current and desired throttled replicas and
+// returns the replicas added and removed in the desired list. The order of
+// either list is insignificant.
+func replicasListDelta(current, desired []string) ([]string, []string) {
+	toSet := func(l []string) map[string]struct{} {
+		set := map[string]struct{}{}
+		for _, r := range l {
+			set[r] = struct{}{}
+		}
+		return set
+	}
+
+	c, d := toSet(current), toSet(desired)
+
+	var added, removed []string
+	for r := range d {
+		if _, exists := c[r]; !exists {
+			added = append(added, r)
+		}
+	}
+
+	for r := range c {
+		if _, exists := d[r]; !exists {
+			removed = append(removed, r)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return added, removed
+}
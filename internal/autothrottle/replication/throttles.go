@@ -2,8 +2,10 @@ package replication
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
 	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
 	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
@@ -20,18 +22,62 @@ type ThrottleManager struct {
 	kafkaNativeMode        bool
 	kafkaAPIRequestTimeout int
 	changeThreshold        float64
-	// The following three fields are for brokers with static overrides set
+	// The following two fields are for brokers with static overrides set
 	// and a TopicThrottledReplicas for topics where those brokers are assigned.
-	brokerOverrides          throttlestore.BrokerOverrides
-	overrideThrottleLists    TopicThrottledReplicas
-	skipOverrideTopicUpdates bool
-	reassigningBrokers       reassigningBrokers
-	events                   EventWriter
-	previouslySetThrottles   ReplicationCapacityByBroker
-	limits                   Limits
-	failureThreshold         int
-	failures                 int
-	skipTopicUpdates         bool
+	brokerOverrides        throttlestore.BrokerOverrides
+	overrideThrottleLists  TopicThrottledReplicas
+	reassigningBrokers     reassigningBrokers
+	events                 EventWriter
+	decisions              DecisionWriter
+	previouslySetThrottles ReplicationCapacityByBroker
+	// Hashes of the throttled replicas lists last written for each topic.
+	// Topic configs are only written when their lists change.
+	topicThrottleHashes map[Topic]uint64
+	// The rates resolved for each reassigning broker in the most recent
+	// throttle update.
+	resolutions map[int]Resolution
+	// Whether the cluster budget is allocated among reassigning topics by
+	// priority rather than by broker headroom.
+	fairShare       bool
+	topicPriorities throttlestore.TopicPriorities
+	topicClasses    TopicClasses
+	// The host and instance type last observed for each broker ID, used to
+	// detect broker replacements.
+	brokerIdentities map[int]brokerIdentity
+	// Observed peak throughput used to calibrate broker capacities.
+	calibration       capacityCalibration
+	limits            Limits
+	failureThreshold  int
+	failures          int
+	guardrails        GuardrailsConfig
+	guardrailsTripped bool
+	previousISRSizes  map[string]int
+	paused            bool
+	verifyAttempts    int
+	wildcardReplicas  bool
+	// Brokers that are no longer registered.
+	lostBrokers map[int]struct{}
+	// Brokers using the default capacity as of the most recent throttle
+	// computation, mapped to their instance type.
+	fallbackCapacityBrokers map[int]string
+	// The config snapshot znode path; snapshots are disabled if unset.
+	snapshotPath string
+	// The autothrottle config znode paths.
+	paths api.ZnodePaths
+	// Broker metrics requests time out after metricsTimeout, if set. Requests
+	// are serialized by metricsMu.
+	metricsTimeout    time.Duration
+	metricsMu         sync.Mutex
+	prefetchedMetrics *metricsResult
+	interpolation     metricsInterpolation
+	// The outbound and inbound network utilization of the reassigning brokers
+	// measured since the last call to TakeMeasuredBandwidth.
+	measuredBandwidth map[int][2]float64
+	// The replication headroom of each reassigning broker as of the most
+	// recent throttle computation from metrics.
+	headroom ReplicationCapacityByBroker
+	// Spans are recorded beneath the span held by traceCtx, if any.
+	traceCtx context.Context
 }
 
 // ThrottleManagerConfig configures a ThrottleManager.
@@ -44,11 +90,43 @@ type ThrottleManagerConfig struct {
 	KafkaNativeMode        bool
 	KafkaAPIRequestTimeout int
 	Events                 EventWriter
+	Guardrails             GuardrailsConfig
+	// The number of times throttle config writes are verified by reading back
+	// the configs and retried on a mismatch. Verification is disabled if 0.
+	VerifyAttempts int
+	// Whether topic throttled replicas lists are set to "*" (all replicas)
+	// rather than enumerating the reassigning replicas.
+	WildcardThrottledReplicas bool
+	// The broker metrics request timeout. Requests aren't timed out if 0.
+	MetricsTimeout time.Duration
+	// The maximum age of the previous metrics observations that the metrics
+	// of brokers missing from a fetch are interpolated from. Interpolation is
+	// disabled if 0.
+	MetricsInterpolationMaxAge time.Duration
+	// The znode path where broker and topic config values are recorded before
+	// autothrottle first writes to them. Snapshots are disabled if unset.
+	ConfigSnapshotPath string
+	// The autothrottle config znode paths where broker overrides and capacity
+	// peaks are stored.
+	ZnodePaths api.ZnodePaths
+	// Whether the cluster replication budget is allocated among concurrent
+	// reassignments by topic priority weight.
+	FairShare bool
+	// Topic priority classes, weighting topics in fair-share allocations.
+	TopicClasses TopicClasses
+	// The trailing window over which the peak network throughput of each
+	// broker is tracked. If set, broker capacities are the greater of the
+	// observed peaks and the configured capacities.
+	CalibrationWindow time.Duration
+	// Optional writer that each throttle rate change is written to.
+	Decisions DecisionWriter
 }
 
 // EventWriter for writing event key values.
 type EventWriter interface {
 	Write(string, string)
+	// WriteCritical writes an event flagged as a critical alert.
+	WriteCritical(string, string)
 }
 
 // NewThrottleManager takes a ThrottleManagerConfig and returns a
@@ -63,14 +141,32 @@ func NewThrottleManager(cfg ThrottleManagerConfig) (*ThrottleManager, error) {
 		kafkaNativeMode:        cfg.KafkaNativeMode,
 		kafkaAPIRequestTimeout: cfg.KafkaAPIRequestTimeout,
 		events:                 cfg.Events,
+		decisions:              cfg.Decisions,
+		guardrails:             cfg.Guardrails,
 		previouslySetThrottles: make(ReplicationCapacityByBroker),
+		brokerIdentities:       make(map[int]brokerIdentity),
+		previousISRSizes:       make(map[string]int),
+		verifyAttempts:         cfg.VerifyAttempts,
+		wildcardReplicas:       cfg.WildcardThrottledReplicas,
+		metricsTimeout:         cfg.MetricsTimeout,
+		snapshotPath:           cfg.ConfigSnapshotPath,
+		paths:                  cfg.ZnodePaths,
+		fairShare:              cfg.FairShare,
+		topicClasses:           cfg.TopicClasses,
+		calibration: capacityCalibration{
+			window: cfg.CalibrationWindow,
+			now:    time.Now,
+		},
+		interpolation: metricsInterpolation{
+			maxAge: cfg.MetricsInterpolationMaxAge,
+			now:    time.Now,
+		},
 	}, nil
 }
 
-// InitKafkaAdmin takes a csv Kafka broker list and initializes a kafkaadmin
+// InitKafkaAdmin takes a kafkaadmin.Config and initializes a kafkaadmin
 // client.
-func (tm *ThrottleManager) InitKafkaAdmin(brokers string) error {
-	cfg := kafkaadmin.Config{BootstrapServers: brokers}
+func (tm *ThrottleManager) InitKafkaAdmin(cfg kafkaadmin.Config) error {
 	ka, err := kafkaadmin.NewClient(cfg)
 	if err != nil {
 		return err
@@ -97,11 +193,51 @@ func (tm *ThrottleManager) SetBrokerOverrides(bo throttlestore.BrokerOverrides)
 	tm.brokerOverrides = bo
 }
 
+// SetTopicPriorities sets the ThrottleManager topicPriorities.
+func (tm *ThrottleManager) SetTopicPriorities(tp throttlestore.TopicPriorities) {
+	tm.topicPriorities = tp
+}
+
 // SetReassigningBrokers sets the ThrottleManager reassigningBrokers.
 func (tm *ThrottleManager) SetReassigningBrokers(rb reassigningBrokers) {
 	tm.reassigningBrokers = rb
 }
 
+// ReassigningBrokerIDs returns a sorted []int of all brokers participating in
+// ongoing reassignments.
+func (tm *ThrottleManager) ReassigningBrokerIDs() []int {
+	_, _, all := tm.reassigningBrokers.lists()
+	return all
+}
+
+// RFIncreaseTopics returns a sorted []string of topics where all reassigning
+// partitions are replication factor increases.
+func (tm *ThrottleManager) RFIncreaseTopics() []string {
+	return tm.reassigningBrokers.rfIncreaseTopics
+}
+
+// SetTraceContext sets the context holding the span that spans recorded by
+// subsequent ThrottleManager calls are children of.
+func (tm *ThrottleManager) SetTraceContext(ctx context.Context) {
+	tm.traceCtx = ctx
+}
+
+// traceContext returns the context set with SetTraceContext, or a background
+// context.
+func (tm *ThrottleManager) traceContext() context.Context {
+	if tm.traceCtx == nil {
+		return context.Background()
+	}
+
+	return tm.traceCtx
+}
+
+// SetPaused sets whether the ThrottleManager is paused. While paused, throttle
+// rates are still determined but no throttle changes are applied.
+func (tm *ThrottleManager) SetPaused(p bool) {
+	tm.paused = p
+}
+
 // SetOverrideRate sets the ThrottleManager overrideRate.
 func (tm *ThrottleManager) SetOverrideRate(r int) {
 	tm.overrideRate = r
@@ -127,9 +263,32 @@ func (tm *ThrottleManager) SetOverrideThrottleLists(t TopicThrottledReplicas) {
 	tm.overrideThrottleLists = t
 }
 
+// GetPreviousThrottles returns a copy of the most recently applied throttle
+// rates.
+func (tm *ThrottleManager) GetPreviousThrottles() ReplicationCapacityByBroker {
+	throttles := make(ReplicationCapacityByBroker, len(tm.previouslySetThrottles))
+	for id, rates := range tm.previouslySetThrottles {
+		throttles[id] = rates
+	}
+	return throttles
+}
+
+// Headroom returns a copy of the replication headroom in MB/s of each broker
+// and role, in respective order to index, as of the most recent throttle
+// computation from metrics. Headroom is the spare capacity available for
+// replication, prior to the per-partition minimums and cluster budget.
+func (tm *ThrottleManager) Headroom() ReplicationCapacityByBroker {
+	headroom := make(ReplicationCapacityByBroker, len(tm.headroom))
+	for id, rates := range tm.headroom {
+		headroom[id] = rates
+	}
+	return headroom
+}
+
 // ResetPreviousThrottles resets and previously set throttles.
 func (tm *ThrottleManager) ResetPreviousThrottles() {
 	tm.previouslySetThrottles.reset()
+	tm.headroom = nil
 }
 
 // ResetFailures resets the failures count.
@@ -137,27 +296,11 @@ func (tm *ThrottleManager) ResetFailures() {
 	tm.failures = 0
 }
 
-// DisableTopicUpdates prevents topic throttled replica lists from being
-// updated in ZooKeeper.
-func (tm *ThrottleManager) DisableTopicUpdates() {
-	tm.skipTopicUpdates = true
-}
-
-// DisableTopicUpdates allows topic throttled replica lists updates in ZooKeeper.
-func (tm *ThrottleManager) EnableTopicUpdates() {
-	tm.skipTopicUpdates = false
-}
-
-// DisableOverrideTopicUpdates prevents topic throttled replica lists for
-// topics assigned to override brokers from being updated in ZooKeeper.
-func (tm *ThrottleManager) DisableOverrideTopicUpdates() {
-	tm.skipOverrideTopicUpdates = true
-}
-
-// EnableOverrideTopicUpdates allows topic throttled replica lists for
-// topics assigned to override brokers to be updated in ZooKeeper.
-func (tm *ThrottleManager) EnableOverrideTopicUpdates() {
-	tm.skipOverrideTopicUpdates = false
+// ResetTopicThrottles forgets the throttled replicas lists last written for
+// each topic, so that all topic throttle configs are written in the next
+// update.
+func (tm *ThrottleManager) ResetTopicThrottles() {
+	tm.topicThrottleHashes = nil
 }
 
 // kafkaRequestContext returns a context and cancel func with the default
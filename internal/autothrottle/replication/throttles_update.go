@@ -2,14 +2,15 @@ package replication
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"strconv"
 	"strings"
 
-	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
 	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/tracing"
 	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
@@ -21,6 +22,8 @@ type brokerChangeEvent struct {
 	id   int
 	role string
 	rate float64
+	// The rate previously set by autothrottle, if any.
+	prev float64
 }
 
 // UpdateReplicationThrottle takes a ThrottleManager that holds topics
@@ -43,7 +46,12 @@ func (tm *ThrottleManager) UpdateReplicationThrottle() error {
 	log.Printf("Source brokers participating in replication: %v\n", srcBrokers)
 	log.Printf("Destination brokers participating in replication: %v\n", dstBrokers)
 
+	if topics := tm.reassigningBrokers.rfIncreaseTopics; len(topics) > 0 {
+		log.Printf("Topics with replication factor increases: %v\n", topics)
+	}
+
 	// Determine throttle rates.
+	tm.headroom = nil
 
 	// Use the throttle override if set. Otherwise, make a calculation using broker
 	// metrics and configured capacity values.
@@ -53,16 +61,38 @@ func (tm *ThrottleManager) UpdateReplicationThrottle() error {
 	var inFailureMode bool
 	var metricErrs []error
 
-	if tm.overrideRate != 0 {
+	// If the cluster health guardrails are tripped, all reassigning brokers are
+	// set to the minimum rate regardless of overrides or available headroom.
+	if tm.guardrailsTripped {
+		capacities = tm.minimumRates(allBrokers)
+		log.Printf("Cluster health guardrails are tripped, setting all throttles to the minimum rates: %s\n", capacities)
+	}
+
+	if tm.overrideRate != 0 && !tm.guardrailsTripped {
 		log.Printf("A global throttle override is set: %dMB/s\n", tm.overrideRate)
 		rateOverride = true
 
 		capacities.setAllRatesWithDefault(allBrokers, float64(tm.overrideRate))
 	}
 
-	if !rateOverride {
+	if !rateOverride && !tm.guardrailsTripped {
 		// Get broker metrics.
-		brokerMetrics, metricErrs = tm.km.GetMetrics()
+		brokerMetrics, metricErrs = tm.getMetrics()
+
+		// If any broker IDs were reused by replacement brokers, drop the state
+		// held for the previous brokers. Where the metrics handler caches broker
+		// metadata, metrics are fetched again with the metadata re-resolved.
+		if replaced := tm.replacedBrokers(brokerMetrics); len(replaced) > 0 {
+			if tm.handleReplacedBrokers(replaced) {
+				brokerMetrics, metricErrs = tm.fetchMetrics()
+				tm.replacedBrokers(brokerMetrics)
+			}
+		}
+
+		// Brokers missing from the metrics are interpolated from their previous
+		// observations, if enabled.
+		brokerMetrics = tm.interpolateMetrics(allBrokers, brokerMetrics)
+
 		// Even if errors are returned, we can still proceed as long as we have complete
 		// metrics data for all target brokers. If we have broker metrics for all target
 		// brokers, we can ignore any errors.
@@ -71,6 +101,16 @@ func (tm *ThrottleManager) UpdateReplicationThrottle() error {
 				inFailureMode = true
 			}
 		}
+
+		// Network utilization far beyond the configured capacities suggests
+		// that the metrics units are misconfigured.
+		for _, w := range tm.checkUnits(allBrokers, brokerMetrics) {
+			log.Println(w)
+		}
+
+		// Record peak throughput for capacity calibration.
+		tm.recordPeaks(brokerMetrics)
+		tm.recordBandwidth(allBrokers, brokerMetrics)
 	}
 
 	// If we cannot proceed normally due to missing/partial metrics data, check what
@@ -90,11 +130,17 @@ func (tm *ThrottleManager) UpdateReplicationThrottle() error {
 		}
 
 		// We're over the threshold; failback to the configured minimum.
-		log.Printf("Metrics fetch failure count %d exceeds threshold %d, reverting to min-rate %.2fMB/s\n",
-			tm.failures, tm.failureThreshold, tm.limits["minimum"])
+		// Set the failback rates.
+		capacities = tm.minimumRates(allBrokers)
+
+		log.Printf("Metrics fetch failure count %d exceeds threshold %d, reverting to the minimum rates: %s\n",
+			tm.failures, tm.failureThreshold, capacities)
 
-		// Set the failback rate.
-		capacities.setAllRatesWithDefault(allBrokers, tm.limits["minimum"])
+		// Alert once as the threshold is first exceeded.
+		if tm.failures == tm.failureThreshold+1 {
+			tm.events.WriteCritical("Metrics failure threshold exceeded",
+				fmt.Sprintf("Metrics fetch failure count %d exceeds threshold %d; reverting to the minimum rates: %s", tm.failures, tm.failureThreshold, metricErrs))
+		}
 	}
 
 	// Reset the failure counter. We may have incremented in past iterations, but if
@@ -105,32 +151,85 @@ func (tm *ThrottleManager) UpdateReplicationThrottle() error {
 
 	// If there's no override set and we're not in a failure mode, apply the
 	// calculated throttles.
-	if !rateOverride && !inFailureMode {
+	if !rateOverride && !inFailureMode && !tm.guardrailsTripped {
+		if tm.limits.crossAZEnabled() {
+			tm.updateRacks()
+		}
+
+		_, span := tracing.Start(tm.traceContext(), "throttle computation", tracing.Int("brokers", len(allBrokers)))
 		var err error
 		capacities, err = brokerReplicationCapacities(tm, tm.reassigningBrokers, brokerMetrics)
+		span.RecordError(err)
+		span.End()
 		if err != nil {
 			return err
 		}
+
+		// Brokers replicating to lost destination brokers are limited to the
+		// minimum rates.
+		if lost := tm.lostDestinationSources(); len(lost) > 0 {
+			log.Printf("Limiting brokers replicating to lost brokers %v to the minimum rates: %v\n", tm.LostDestinations(), lost)
+			for id, rates := range tm.minimumRates(lost) {
+				capacities[id] = rates
+			}
+		}
 	}
 
 	// Merge in broker-specific overrides if they're part of the reassignment.
+	resolver := Resolver{
+		GuardrailsTripped: tm.guardrailsTripped,
+		MetricsFailure:    inFailureMode,
+	}
+	if rateOverride {
+		resolver.GlobalOverride = tm.overrideRate
+	}
+
+	lost := map[int]struct{}{}
+	if !rateOverride && !inFailureMode && !tm.guardrailsTripped {
+		for _, id := range tm.lostDestinationSources() {
+			lost[id] = struct{}{}
+		}
+	}
+
+	resolutions := map[int]Resolution{}
 	for id := range tm.reassigningBrokers.all {
+		in := ResolverInput{Determined: capacities[id]}
+		_, in.LostDestinationSource = lost[id]
+
 		if override, exists := tm.brokerOverrides[id]; exists {
 			// Any brokers with throttle overrides that are being issued as part of a
 			// reassignemnt should be marked as such.
 			override.ReassignmentParticipant = true
 			tm.brokerOverrides[id] = override
+			in.Override = &override
+		}
 
-			rate := override.Config.Rate
-			// A rate of 0 means we intend to remove this throttle override. Skip.
-			if rate == 0 {
-				continue
-			}
+		res := resolver.Resolve(in)
+		resolutions[id] = res
 
-			log.Printf("A broker throttle override is set for %d: %dMB/s\n", id, rate)
-			// Store the rate for both inbound and outbound traffic.
-			capacities.storeLeaderAndFollerCapacity(id, float64(rate))
+		o := res.Override
+		if o == nil {
+			continue
+		}
+
+		if o.Pinned {
+			log.Printf("A pinned broker throttle is set for %d: %dMB/s\n", id, o.Rate)
+		} else if o.Precedence != "" && o.Precedence != throttlestore.PrecedenceOverride {
+			log.Printf("A broker throttle override is set for %d: %dMB/s, precedence==%s\n", id, o.Rate, o.Precedence)
+		} else {
+			log.Printf("A broker throttle override is set for %d: %dMB/s\n", id, o.Rate)
 		}
+
+		capacities[id] = res.Rates
+	}
+
+	tm.resolutions = resolutions
+
+	// While paused, the determined rates are reported but not applied.
+	if tm.paused {
+		log.Printf("Autothrottle is paused, skipping throttle updates; determined rates [ID, leader, follower]: %s\n",
+			capacities)
+		return nil
 	}
 
 	// Set broker throttle configs.
@@ -149,21 +248,22 @@ func (tm *ThrottleManager) UpdateReplicationThrottle() error {
 
 		for e := range events {
 			b.WriteString(fmt.Sprintf("[%d, %s, %.2f], ", e.id, e.role, e.rate))
+			tm.writeDecision(e, tm.resolutions[e.id].Reason)
 		}
 
 		b.WriteString("\n")
 	}
 
+	// Append the per-class allocation to the event.
+	if len(tm.topicClasses) > 0 {
+		ca := tm.reassigningBrokers.classAllocation(capacities, tm.topicWeight, tm.topicClasses)
+		log.Printf("Replication bandwidth by topic class [class, leader, follower]: %s\n", ca)
+		b.WriteString(fmt.Sprintf("Replication bandwidth by topic class [class, leader, follower]: %s\n", ca))
+	}
+
 	// Set topic throttle configs.
-	if !tm.skipTopicUpdates {
-		errs := tm.applyTopicThrottles(tm.reassigningBrokers.throttledReplicas)
-		for _, e := range errs {
-			log.Println(e)
-		}
-		if errs == nil {
-			topics := tm.reassigningBrokers.throttledReplicas.topics()
-			log.Printf("updated the throttle replicas configs for topics: %v\n", topics)
-		}
+	for _, e := range tm.applyTopicThrottles(tm.reassigningBrokers.throttledReplicas) {
+		log.Println(e)
 	}
 
 	// Append topic stats to event.
@@ -180,8 +280,13 @@ func (tm *ThrottleManager) UpdateReplicationThrottle() error {
 }
 
 // UpdateOverrideThrottles applies replication throttles for any brokers
-// with overrides set.
+// with overrides set. This is a no-op while paused.
 func (tm *ThrottleManager) UpdateOverrideThrottles() error {
+	if tm.paused {
+		log.Println("Autothrottle is paused, skipping broker override throttle updates")
+		return nil
+	}
+
 	// The rate spec we'll be applying, which is the override rates.
 	var capacities = make(ReplicationCapacityByBroker)
 	// Broker IDs that will have throttles set.
@@ -218,15 +323,8 @@ func (tm *ThrottleManager) UpdateOverrideThrottles() error {
 	}
 
 	// Set topic throttle configs.
-	if !tm.skipOverrideTopicUpdates {
-		errs := tm.applyTopicThrottles(tm.overrideThrottleLists)
-		for _, e := range errs {
-			log.Println(e)
-		}
-		if errs == nil {
-			topics := tm.overrideThrottleLists.topics()
-			log.Printf("updated the throttle replicas configs for topics: %v\n", topics)
-		}
+	for _, e := range tm.applyTopicThrottles(tm.overrideThrottleLists) {
+		log.Println(e)
 	}
 
 	// Append broker throttle info to event.
@@ -236,6 +334,11 @@ func (tm *ThrottleManager) UpdateOverrideThrottles() error {
 
 		for e := range events {
 			b.WriteString(fmt.Sprintf("[%d, %s, %.2f], ", e.id, e.role, e.rate))
+			reason := ReasonBrokerOverride
+			if tm.brokerOverrides[e.id].Pinned {
+				reason = ReasonPinned
+			}
+			tm.writeDecision(e, reason)
 		}
 
 		b.WriteString("\n")
@@ -245,19 +348,25 @@ func (tm *ThrottleManager) UpdateOverrideThrottles() error {
 	tm.events.Write("Broker level throttle override(s) configured", b.String())
 
 	// Unset the broker throttles marked for removal.
-	return tm.removeBrokerThrottlesByID(toRemove)
+	return tm.removeBrokerThrottlesByID(toRemove, ReasonBrokerOverride)
 }
 
-// PurgeOverrideThrottles takes a *ThrottleManager and removes
-// broker overrides from ZK that have been set to a value of 0.
+// PurgeOverrideThrottles takes a *ThrottleManager and removes broker overrides
+// and pinned throttles from ZK that have been set to a value of 0. Overrides
+// with only scheduled overrides pending are retained. This is a no-op while
+// paused.
 func (tm *ThrottleManager) PurgeOverrideThrottles() []error {
+	if tm.paused {
+		return nil
+	}
+
 	// Broker IDs that should have previously set throttles removed.
 	var toRemove = make(map[int]struct{})
 
 	for _, override := range tm.brokerOverrides {
 		rate := float64(override.Config.Rate)
 		// Rate == 0 means the rate was removed via the API.
-		if rate == 0 {
+		if rate == 0 && !override.Scheduled {
 			toRemove[override.ID] = struct{}{}
 		}
 	}
@@ -265,7 +374,12 @@ func (tm *ThrottleManager) PurgeOverrideThrottles() []error {
 	var errs []error
 
 	for id := range toRemove {
-		path := fmt.Sprintf("%s/%d", api.OverrideRateZnodePath, id)
+		parent := tm.paths.OverrideRate
+		if tm.brokerOverrides[id].Pinned {
+			parent = tm.paths.PinnedRate
+		}
+
+		path := fmt.Sprintf("%s/%d", parent, id)
 		if err := throttlestore.RemoveThrottleOverride(tm.zk, path); err != nil {
 			errs = append(errs, err)
 		}
@@ -275,7 +389,13 @@ func (tm *ThrottleManager) PurgeOverrideThrottles() []error {
 }
 
 // applyBrokerThrottles applies broker throttle configs.
-func (tm *ThrottleManager) applyBrokerThrottles(bs map[int]struct{}, capacities ReplicationCapacityByBroker) (chan brokerChangeEvent, []error) {
+func (tm *ThrottleManager) applyBrokerThrottles(bs map[int]struct{}, capacities ReplicationCapacityByBroker) (events chan brokerChangeEvent, errs []error) {
+	_, span := tracing.Start(tm.traceContext(), "broker throttle configs write", tracing.Int("brokers", len(bs)))
+	defer func() {
+		span.RecordError(errors.Join(errs...))
+		span.End()
+	}()
+
 	var configs = kafkaadmin.SetThrottleConfig{Brokers: map[int]kafkaadmin.BrokerThrottleConfig{}}
 	var legacyConfigs = make(map[int]kafkazk.KafkaConfig)
 
@@ -307,16 +427,23 @@ func (tm *ThrottleManager) applyBrokerThrottles(bs map[int]struct{}, capacities
 			}
 
 			// Get the maximum utilization value for logging purposes.
-			var max float64
+			var max, absMax float64
+			limits, desc := tm.reassigningBrokers.limits(tm.limits, ID, ReplicaType(role))
+
 			switch role {
 			case "leader":
-				max = tm.limits["srcMax"]
+				max, absMax = limits["srcMax"], limits["srcMaxAbs"]
 			case "follower":
-				max = tm.limits["dstMax"]
+				max, absMax = limits["dstMax"], limits["dstMaxAbs"]
+			}
+
+			var capped string
+			if absMax > 0 {
+				capped = fmt.Sprintf(", capped at %.0fMB/s", absMax)
 			}
 
-			log.Printf("Replication throttle rate for broker %d [%s] (based on a %.0f%% max free capacity utilization): %0.2fMB/s\n",
-				ID, role, max, *rate)
+			log.Printf("Replication throttle rate for broker %d [%s%s] (based on a %.0f%% max free capacity utilization%s): %0.2fMB/s\n",
+				ID, role, desc, max, capped, *rate)
 
 			// Check if the delta between the newly calculated throttle and the previous
 			// throttle exceeds the ChangeThreshold param.
@@ -374,11 +501,24 @@ func (tm *ThrottleManager) applyBrokerThrottlesSequential(configs kafkaadmin.Set
 				id: config,
 			}}
 
-		ctx, cancelFn := tm.kafkaRequestContext()
-		defer cancelFn()
+		// The expected rate configs; zero rates are left as-is.
+		expected := configExpectations{}
+		for i, rate := range []int{config.OutboundLimitBytes, config.InboundLimitBytes} {
+			if rate == 0 {
+				continue
+			}
+			if _, exists := expected[strconv.Itoa(id)]; !exists {
+				expected[strconv.Itoa(id)] = map[string]string{}
+			}
+			expected[strconv.Itoa(id)][brokerThrottleCfgNames[i]] = strconv.Itoa(rate)
+		}
 
 		// Apply.
-		err := tm.ka.SetThrottle(ctx, cfg)
+		err := tm.writeAndVerify("broker", expected, func() error {
+			ctx, cancel := tm.kafkaRequestContext()
+			defer cancel()
+			return tm.ka.SetThrottle(ctx, cfg)
+		})
 		if err != nil {
 			errs = append(errs, fmt.Errorf("Error setting throttle on broker %d: %s", id, err))
 			// Continue to the next broker if we encounter an error.
@@ -389,7 +529,7 @@ func (tm *ThrottleManager) applyBrokerThrottlesSequential(configs kafkaadmin.Set
 
 		// Store and log leader configs, if any.
 		if cfg.Brokers[id].OutboundLimitBytes != 0 {
-			rate := capacities[id][0]
+			rate, prev := capacities[id][0], tm.previousRate(id, 0)
 			tm.previouslySetThrottles.storeLeaderCapacity(id, *rate)
 
 			log.Printf("Updated throttle on broker %d [leader]\n", id)
@@ -397,12 +537,13 @@ func (tm *ThrottleManager) applyBrokerThrottlesSequential(configs kafkaadmin.Set
 				id:   id,
 				role: "leader",
 				rate: *rate,
+				prev: prev,
 			}
 		}
 
 		// Store and log follower configs, if any.
 		if cfg.Brokers[id].InboundLimitBytes != 0 {
-			rate := capacities[id][1]
+			rate, prev := capacities[id][1], tm.previousRate(id, 1)
 			tm.previouslySetThrottles.storeFollowerCapacity(id, *rate)
 
 			log.Printf("Updated throttle on broker %d [follower]\n", id)
@@ -410,6 +551,7 @@ func (tm *ThrottleManager) applyBrokerThrottlesSequential(configs kafkaadmin.Set
 				id:   id,
 				role: "follower",
 				rate: *rate,
+				prev: prev,
 			}
 		}
 	}
@@ -424,26 +566,110 @@ func (tm *ThrottleManager) applyBrokerThrottlesSequential(configs kafkaadmin.Set
 // TODO(jamie) review whether the throttled replicas list changes as replication
 // finishes; each time the list changes here, we probably update the config then
 // propagate a watch to all the brokers in the cluster.
-func (tm *ThrottleManager) applyTopicThrottles(throttledTopics TopicThrottledReplicas) []error {
+func (tm *ThrottleManager) applyTopicThrottles(throttled TopicThrottledReplicas) (errs []error) {
+	// Only topics with throttled replicas lists that changed since they were
+	// last written are updated.
+	throttledTopics, hashes := tm.changedTopicThrottles(throttled)
+	if len(throttledTopics) == 0 {
+		return nil
+	}
+
+	_, span := tracing.Start(tm.traceContext(), "topic throttle configs write", tracing.Int("topics", len(throttledTopics)))
+	defer func() {
+		span.RecordError(errors.Join(errs...))
+		span.End()
+	}()
+
+	defer func() {
+		if errs != nil {
+			return
+		}
+
+		if tm.topicThrottleHashes == nil {
+			tm.topicThrottleHashes = make(map[Topic]uint64)
+		}
+		for t, h := range hashes {
+			tm.topicThrottleHashes[t] = h
+		}
+
+		log.Printf("Updated the throttled replicas configs for topics: %v\n", throttledTopics.topics())
+	}()
+
 	if !tm.kafkaNativeMode {
 		// Use the direct ZooKeeper config update method.
 		return tm.legacyApplyTopicThrottles(throttledTopics)
 	}
 
 	// Populate the config with all topics named in the TopicThrottledReplicas.
-	ctx, cancel := tm.kafkaRequestContext()
-	defer cancel()
-
-	var throttleCfg = kafkaadmin.SetThrottleConfig{Topics: throttledTopics.topics()}
+	throttleCfg, expected := tm.topicThrottleConfig(throttledTopics)
 
 	// Apply the config.
-	if err := tm.ka.SetThrottle(ctx, throttleCfg); err != nil {
+	err := tm.writeAndVerify("topic", expected, func() error {
+		ctx, cancel := tm.kafkaRequestContext()
+		defer cancel()
+		return tm.ka.SetThrottle(ctx, throttleCfg)
+	})
+
+	if err != nil {
 		return []error{err}
 	}
 
 	return nil
 }
 
+// changedTopicThrottles takes a TopicThrottledReplicas and returns the subset
+// of topics with throttled replicas lists that differ from those last written,
+// along with the hashes of their lists.
+func (tm *ThrottleManager) changedTopicThrottles(throttled TopicThrottledReplicas) (TopicThrottledReplicas, map[Topic]uint64) {
+	changed := TopicThrottledReplicas{}
+	hashes := map[Topic]uint64{}
+
+	for t := range throttled {
+		h := throttled.listsHash(t, tm.wildcardReplicas)
+		if prev, exists := tm.topicThrottleHashes[t]; exists && prev == h {
+			continue
+		}
+
+		changed[t] = throttled[t]
+		hashes[t] = h
+	}
+
+	return changed, hashes
+}
+
+// topicThrottleConfig takes a TopicThrottledReplicas and returns the
+// kafkaadmin.SetThrottleConfig for the topics along with the expected configs.
+// The throttled replicas lists are scoped to the reassigning partitions and
+// replicas, or "*" (all replicas) in wildcard mode.
+func (tm *ThrottleManager) topicThrottleConfig(throttledTopics TopicThrottledReplicas) (kafkaadmin.SetThrottleConfig, configExpectations) {
+	cfg := kafkaadmin.SetThrottleConfig{Topics: throttledTopics.topics()}
+
+	if tm.wildcardReplicas {
+		return cfg, throttleExpectations(cfg.Topics, topicThrottleCfgNames, "*")
+	}
+
+	cfg.TopicReplicas = map[string]kafkaadmin.ThrottledReplicas{}
+	expected := configExpectations{}
+
+	for _, t := range cfg.Topics {
+		r := kafkaadmin.ThrottledReplicas{
+			Leaders:   throttledReplicasList(t, throttledTopics[Topic(t)]["leaders"]),
+			Followers: throttledReplicasList(t, throttledTopics[Topic(t)]["followers"]),
+		}
+		cfg.TopicReplicas[t] = r
+
+		// Empty lists aren't written.
+		expected[t] = map[string]string{}
+		for i, list := range []string{r.Leaders, r.Followers} {
+			if list != "" {
+				expected[t][topicThrottleCfgNames[i]] = list
+			}
+		}
+	}
+
+	return cfg, expected
+}
+
 // RemoveAllThrottles calls removeTopicThrottles and removeBrokerThrottles in sequence.
 func (tm *ThrottleManager) RemoveAllThrottles() error {
 	for _, fn := range []func() error{
@@ -460,6 +686,8 @@ func (tm *ThrottleManager) RemoveAllThrottles() error {
 
 // removeTopicThrottles removes all topic throttle configs.
 func (tm *ThrottleManager) removeTopicThrottles() error {
+	tm.ResetTopicThrottles()
+
 	// ZooKeeper method.
 	if !tm.kafkaNativeMode {
 		return tm.legacyRemoveTopicThrottles()
@@ -480,49 +708,77 @@ func (tm *ThrottleManager) removeTopicThrottles() error {
 		topics = append(topics, name)
 	}
 
-	ctx, cancel = tm.kafkaRequestContext()
-	defer cancel()
+	return tm.removeTopicThrottlesByName(topics)
+}
+
+// removeTopicThrottlesByName removes topic throttle configs for the specified
+// topics.
+func (tm *ThrottleManager) removeTopicThrottlesByName(topics []string) error {
+	for _, t := range topics {
+		delete(tm.topicThrottleHashes, Topic(t))
+	}
+
+	// ZooKeeper method.
+	if !tm.kafkaNativeMode {
+		return tm.legacyRemoveTopicThrottlesByName(topics)
+	}
 
 	cfg := kafkaadmin.RemoveThrottleConfig{
 		Topics: topics,
 	}
 
 	// Issue the remove.
-	if err := tm.ka.RemoveThrottle(ctx, cfg); err != nil {
+	expected := throttleExpectations(topics, topicThrottleCfgNames, "")
+	err := tm.writeAndVerify("topic", expected, func() error {
+		ctx, cancel := tm.kafkaRequestContext()
+		defer cancel()
+		return tm.ka.RemoveThrottle(ctx, cfg)
+	})
+
+	if err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// removeBrokerThrottlesByID removes broker throttle configs for the specified IDs.
-func (tm *ThrottleManager) removeBrokerThrottlesByID(ids map[int]struct{}) error {
+// removeBrokerThrottlesByID removes broker throttle configs for the specified
+// IDs. A Decision with the reason is written for each removed throttle.
+func (tm *ThrottleManager) removeBrokerThrottlesByID(ids map[int]struct{}, reason string) error {
 	// ZooKeeper method.
 	if !tm.kafkaNativeMode {
-		return tm.legacyRemoveBrokerThrottlesByID(ids)
+		return tm.legacyRemoveBrokerThrottlesByID(ids, reason)
 	}
 
 	// Set to list.
 	var brokers []int
+	var names []string
 	for id := range ids {
 		brokers = append(brokers, id)
+		names = append(names, strconv.Itoa(id))
 	}
 
-	ctx, cancel := tm.kafkaRequestContext()
-	defer cancel()
-
 	cfg := kafkaadmin.RemoveThrottleConfig{
 		Brokers: brokers,
 	}
 
 	// Issue the remove.
-	if err := tm.ka.RemoveThrottle(ctx, cfg); err != nil {
+	expected := throttleExpectations(names, brokerThrottleCfgNames, "")
+	err := tm.writeAndVerify("broker", expected, func() error {
+		ctx, cancel := tm.kafkaRequestContext()
+		defer cancel()
+		return tm.ka.RemoveThrottle(ctx, cfg)
+	})
+
+	if err != nil {
 		return fmt.Errorf("Error removing broker throttles: %s", err)
 	}
 
 	listStr := strings.Trim(strings.Join(strings.Fields(fmt.Sprint(brokers)), ", "), "[]")
 	log.Printf("Throttles removed on brokers: %s\n", listStr)
 
+	tm.writeRemovalDecisions(brokers, tm.previouslySetThrottles, reason)
+
 	return nil
 }
 
@@ -547,5 +803,5 @@ func (tm *ThrottleManager) removeBrokerThrottles() error {
 		ids[id] = struct{}{}
 	}
 
-	return tm.removeBrokerThrottlesByID(ids)
+	return tm.removeBrokerThrottlesByID(ids, ReasonRemoved)
 }
@@ -0,0 +1,563 @@
+package replication
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/api"
+	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// newTestThrottleManager returns a ZooKeeper mode *ThrottleManager for the
+// kafkazk.Stub default reassignments, with metrics served by the
+// kafkametrics.Stub.
+func newTestThrottleManager(t *testing.T, zk *kafkazk.Stub, km *kafkametrics.Stub) *ThrottleManager {
+	lim, err := NewLimits(NewLimitsConfig{
+		Minimum:            20,
+		SourceMaximum:      90,
+		DestinationMaximum: 80,
+		CapacityMap:        CapacityMap{"stub": {TX: 200, RX: 200}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm, err := NewThrottleManager(ThrottleManagerConfig{
+		Limits:           lim,
+		FailureThreshold: 1,
+		ChangeThreshold:  10,
+		KafkaZK:          zk,
+		KafkaMetrics:     km,
+		Events:           &eventsStub{},
+		ZnodePaths:       api.NewZnodePaths("autothrottle"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reassignments := zk.GetReassignments()
+	rb, err := GetReassigningBrokers(reassignments, zk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm.SetReassignments(reassignments)
+	tm.SetReassigningBrokers(rb)
+
+	return tm
+}
+
+// brokerConfigUpdates returns the broker configs written to the stub as a map
+// of broker ID to config name to value.
+func brokerConfigUpdates(zk *kafkazk.Stub) map[string]map[string]string {
+	updates := map[string]map[string]string{}
+	for _, c := range zk.KafkaConfigUpdates() {
+		if c.Type != "broker" {
+			continue
+		}
+
+		if _, exists := updates[c.Name]; !exists {
+			updates[c.Name] = map[string]string{}
+		}
+
+		for _, kv := range c.Configs {
+			updates[c.Name][kv[0]] = kv[1]
+		}
+	}
+
+	return updates
+}
+
+// throttleUpdates is a helper for building expected brokerConfigUpdates. Each
+// broker ID maps to leader and follower rates in bytes; an empty string means
+// no update is expected for the role.
+func throttleUpdates(rates map[string][2]string) map[string]map[string]string {
+	updates := map[string]map[string]string{}
+	for id, r := range rates {
+		updates[id] = map[string]string{}
+		if r[0] != "" {
+			updates[id]["leader.replication.throttled.rate"] = r[0]
+		}
+		if r[1] != "" {
+			updates[id]["follower.replication.throttled.rate"] = r[1]
+		}
+	}
+
+	return updates
+}
+
+func TestUpdateReplicationThrottle(t *testing.T) {
+	zkWriteInterval = 0
+
+	metricsErr := metricsErrResponse("timeout")
+
+	// Rates applied to all reassigning brokers in both roles.
+	allBrokers := func(rate string) map[string]map[string]string {
+		return throttleUpdates(map[string][2]string{
+			"1000": {rate, rate},
+			"1002": {rate, rate},
+			"1003": {rate, rate},
+			"1005": {rate, rate},
+			"1010": {rate, rate},
+		})
+	}
+
+	tests := []struct {
+		name string
+		// Modifies the ThrottleManager and stubs prior to the updates.
+		setup func(*ThrottleManager, *kafkametrics.Stub)
+		// The number of UpdateReplicationThrottle calls. The broker config
+		// updates from the final call are compared.
+		calls    int
+		expected map[string]map[string]string
+	}{
+		{
+			name:  "metrics",
+			calls: 1,
+			expected: throttleUpdates(map[string][2]string{
+				"1000": {"108000000", ""},
+				"1002": {"108000000", ""},
+				"1003": {"", "96000000"},
+				"1005": {"", "20000000"},
+				"1010": {"", "64000000"},
+			}),
+		},
+		{
+			name: "global override",
+			setup: func(tm *ThrottleManager, _ *kafkametrics.Stub) {
+				tm.SetOverrideRate(50)
+			},
+			calls:    1,
+			expected: allBrokers("50000000"),
+		},
+		{
+			name: "broker override",
+			setup: func(tm *ThrottleManager, _ *kafkametrics.Stub) {
+				tm.SetBrokerOverrides(throttlestore.BrokerOverrides{
+					1003: {ID: 1003, Config: throttlestore.ThrottleOverrideConfig{Rate: 30}},
+				})
+			},
+			calls: 1,
+			expected: throttleUpdates(map[string][2]string{
+				"1000": {"108000000", ""},
+				"1002": {"108000000", ""},
+				"1003": {"30000000", "30000000"},
+				"1005": {"", "20000000"},
+				"1010": {"", "64000000"},
+			}),
+		},
+		{
+			// The override rate is used for roles without a determined rate.
+			name: "broker override min precedence",
+			setup: func(tm *ThrottleManager, _ *kafkametrics.Stub) {
+				tm.SetBrokerOverrides(throttlestore.BrokerOverrides{
+					1003: {ID: 1003, Config: throttlestore.ThrottleOverrideConfig{Rate: 120, Precedence: throttlestore.PrecedenceMin}},
+				})
+			},
+			calls: 1,
+			expected: throttleUpdates(map[string][2]string{
+				"1000": {"108000000", ""},
+				"1002": {"108000000", ""},
+				"1003": {"120000000", "96000000"},
+				"1005": {"", "20000000"},
+				"1010": {"", "64000000"},
+			}),
+		},
+		{
+			name: "broker override max precedence",
+			setup: func(tm *ThrottleManager, _ *kafkametrics.Stub) {
+				tm.SetBrokerOverrides(throttlestore.BrokerOverrides{
+					1003: {ID: 1003, Config: throttlestore.ThrottleOverrideConfig{Rate: 50, Precedence: throttlestore.PrecedenceMax}},
+				})
+			},
+			calls: 1,
+			expected: throttleUpdates(map[string][2]string{
+				"1000": {"108000000", ""},
+				"1002": {"108000000", ""},
+				"1003": {"50000000", "96000000"},
+				"1005": {"", "20000000"},
+				"1010": {"", "64000000"},
+			}),
+		},
+		{
+			name: "guardrails tripped",
+			setup: func(tm *ThrottleManager, _ *kafkametrics.Stub) {
+				tm.guardrailsTripped = true
+				tm.SetOverrideRate(50)
+			},
+			calls:    1,
+			expected: allBrokers("20000000"),
+		},
+		{
+			// Failures up to the threshold retain the previous throttles.
+			name: "metrics failure within threshold",
+			setup: func(_ *ThrottleManager, km *kafkametrics.Stub) {
+				km.Script(metricsErr)
+			},
+			calls:    1,
+			expected: map[string]map[string]string{},
+		},
+		{
+			name: "metrics failure over threshold",
+			setup: func(_ *ThrottleManager, km *kafkametrics.Stub) {
+				km.Script(metricsErr)
+			},
+			calls:    2,
+			expected: allBrokers("20000000"),
+		},
+		{
+			// The failback rates are the role and instance-type minimums. The
+			// instance type is known from the first interval's metrics.
+			name: "metrics failure over threshold with minimums",
+			setup: func(tm *ThrottleManager, km *kafkametrics.Stub) {
+				tm.limits["dstMin"] = 30
+				tm.limits["stub-large"] = 200
+				tm.limits[minimumKeyPrefix+"stub-large"] = 40
+
+				bm := stubBrokerMetrics()
+				bm[1000].InstanceType = "stub-large"
+				km.Script(kafkametrics.StubResponse{Metrics: bm}, metricsErr)
+			},
+			calls: 3,
+			expected: throttleUpdates(map[string][2]string{
+				"1000": {"40000000", "40000000"},
+				"1002": {"20000000", "30000000"},
+				"1003": {"20000000", "30000000"},
+				// The follower rate was already floored at the minimum in the
+				// first interval.
+				"1005": {"20000000", ""},
+				"1010": {"20000000", "30000000"},
+			}),
+		},
+		{
+			// Errors are ignored if metrics are complete.
+			name: "metrics errors with complete metrics",
+			setup: func(_ *ThrottleManager, km *kafkametrics.Stub) {
+				km.Script(kafkametrics.StubResponse{
+					Metrics: stubBrokerMetrics(),
+					Errors:  metricsErr.Errors,
+				})
+			},
+			calls: 1,
+			expected: throttleUpdates(map[string][2]string{
+				"1000": {"108000000", ""},
+				"1002": {"108000000", ""},
+				"1003": {"", "96000000"},
+				"1005": {"", "20000000"},
+				"1010": {"", "64000000"},
+			}),
+		},
+		{
+			name: "paused",
+			setup: func(tm *ThrottleManager, _ *kafkametrics.Stub) {
+				tm.SetPaused(true)
+			},
+			calls:    1,
+			expected: map[string]map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		zk := kafkazk.NewZooKeeperStub()
+		km := kafkametrics.NewStub()
+		km.Script(kafkametrics.StubResponse{Metrics: stubBrokerMetrics()})
+
+		tm := newTestThrottleManager(t, zk, km)
+		if test.setup != nil {
+			test.setup(tm, km)
+		}
+
+		for i := 0; i < test.calls; i++ {
+			zk.ResetKafkaConfigUpdates()
+			if err := tm.UpdateReplicationThrottle(); err != nil {
+				t.Fatalf("[%s] Unexpected error: %s", test.name, err)
+			}
+		}
+
+		got := brokerConfigUpdates(zk)
+
+		if len(got) != len(test.expected) {
+			t.Errorf("[%s] Expected updates for %d brokers, got %v", test.name, len(test.expected), got)
+		}
+
+		for id, configs := range test.expected {
+			if len(got[id]) != len(configs) {
+				t.Errorf("[%s] Expected broker %s configs %v, got %v", test.name, id, configs, got[id])
+				continue
+			}
+
+			for k, v := range configs {
+				if got[id][k] != v {
+					t.Errorf("[%s] Expected broker %s %s %s, got %s", test.name, id, k, v, got[id][k])
+				}
+			}
+		}
+	}
+}
+
+func TestUpdateReplicationThrottleFailureEvent(t *testing.T) {
+	zkWriteInterval = 0
+
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(metricsErrResponse("timeout"))
+
+	tm := newTestThrottleManager(t, zk, km)
+	events := tm.events.(*eventsStub)
+
+	// A critical event is written once as the threshold is first exceeded.
+	for i, expected := range []int{0, 1, 1} {
+		if err := tm.UpdateReplicationThrottle(); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(events.criticalTitles) != expected {
+			t.Errorf("[call %d] Expected %d critical events, got %v", i, expected, events.criticalTitles)
+		}
+	}
+
+	if events.criticalTitles[0] != "Metrics failure threshold exceeded" {
+		t.Errorf("Unexpected critical event %s", events.criticalTitles[0])
+	}
+}
+
+func TestUpdateReplicationThrottleChangeThreshold(t *testing.T) {
+	zkWriteInterval = 0
+
+	// In the second interval, replication consumes the applied throttles in
+	// addition to the unchanged non-replication utilization.
+	replicating := stubBrokerMetrics()
+	replicating[1000].NetTX += 108
+	replicating[1002].NetTX += 108
+	replicating[1003].NetRX += 96
+	replicating[1005].NetRX += 20
+	replicating[1010].NetRX += 64
+
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(
+		kafkametrics.StubResponse{Metrics: stubBrokerMetrics()},
+		kafkametrics.StubResponse{Metrics: replicating},
+	)
+
+	tm := newTestThrottleManager(t, zk, km)
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Applied rates are stored.
+	if r := tm.GetPreviousThrottles()[1000][0]; r == nil || *r != 108.00 {
+		t.Errorf("Expected previous leader throttle 108.00 for 1000, got %v", r)
+	}
+
+	// The determined rates are unchanged and within the change threshold; no
+	// updates are written.
+	zk.ResetKafkaConfigUpdates()
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := brokerConfigUpdates(zk); len(got) != 5 {
+		t.Fatalf("Expected empty updates for 5 brokers, got %v", got)
+	} else {
+		for id, configs := range got {
+			if len(configs) != 0 {
+				t.Errorf("Expected no config updates for broker %s, got %v", id, configs)
+			}
+		}
+	}
+}
+
+func TestApplyTopicThrottlesChanged(t *testing.T) {
+	zkWriteInterval = 0
+
+	zk := kafkazk.NewZooKeeperStub()
+	tm := newTestThrottleManager(t, zk, kafkametrics.NewStub())
+
+	topicUpdates := func() []string {
+		var topics []string
+		for _, c := range zk.KafkaConfigUpdates() {
+			if c.Type == "topic" {
+				topics = append(topics, c.Name)
+			}
+		}
+		zk.ResetKafkaConfigUpdates()
+		return topics
+	}
+
+	ttr := TopicThrottledReplicas{}
+	ttr.addReplica("test_topic", "0", "leaders", "1001")
+	ttr.addReplica("test_topic", "0", "followers", "1002")
+	ttr.addReplica("test_topic2", "0", "leaders", "1003")
+	ttr.addReplica("test_topic2", "0", "followers", "1004")
+
+	if errs := tm.applyTopicThrottles(ttr); errs != nil {
+		t.Fatal(errs)
+	}
+
+	if got := topicUpdates(); len(got) != 2 {
+		t.Errorf("Expected updates for 2 topics, got %v", got)
+	}
+
+	// Unchanged lists aren't written again.
+	if errs := tm.applyTopicThrottles(ttr); errs != nil {
+		t.Fatal(errs)
+	}
+
+	if got := topicUpdates(); len(got) != 0 {
+		t.Errorf("Expected no topic updates, got %v", got)
+	}
+
+	// A partition added to an existing reassignment only updates its topic.
+	ttr.addReplica("test_topic", "1", "followers", "1005")
+
+	if errs := tm.applyTopicThrottles(ttr); errs != nil {
+		t.Fatal(errs)
+	}
+
+	if got := topicUpdates(); len(got) != 1 || got[0] != "test_topic" {
+		t.Errorf("Expected updates for [test_topic], got %v", got)
+	}
+
+	// Removed throttles are written again.
+	if err := tm.removeTopicThrottlesByName([]string{"test_topic2"}); err != nil {
+		t.Fatal(err)
+	}
+	zk.ResetKafkaConfigUpdates()
+
+	if errs := tm.applyTopicThrottles(ttr); errs != nil {
+		t.Fatal(errs)
+	}
+
+	if got := topicUpdates(); len(got) != 1 || got[0] != "test_topic2" {
+		t.Errorf("Expected updates for [test_topic2], got %v", got)
+	}
+}
+
+func TestUpdateReplicationThrottleBrokerReplaced(t *testing.T) {
+	zkWriteInterval = 0
+
+	// In the second interval, broker 1003 has been replaced by a new host that
+	// isn't yet replicating; the remaining brokers consume the applied throttles.
+	replaced := stubBrokerMetrics()
+	replaced[1000].NetTX += 108
+	replaced[1002].NetTX += 108
+	replaced[1005].NetRX += 20
+	replaced[1010].NetRX += 64
+	replaced[1003].Host = "host3-replacement"
+
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(
+		kafkametrics.StubResponse{Metrics: stubBrokerMetrics()},
+		kafkametrics.StubResponse{Metrics: replaced},
+	)
+
+	tm := newTestThrottleManager(t, zk, km)
+	events := &eventsStub{}
+	tm.events = events
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := km.Invalidated(); len(ids) != 0 {
+		t.Errorf("Expected no invalidated brokers, got %v", ids)
+	}
+
+	zk.ResetKafkaConfigUpdates()
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := km.Invalidated(); len(ids) != 1 || ids[0] != 1003 {
+		t.Errorf("Expected invalidated broker 1003, got %v", ids)
+	}
+
+	var detected bool
+	for _, title := range events.titles {
+		if title == "Broker replacement detected" {
+			detected = true
+		}
+	}
+
+	if !detected {
+		t.Errorf("Expected a broker replacement event, got %v", events.titles)
+	}
+
+	// The replacement's throttle is applied again since the previous throttle
+	// was cleared; the remaining brokers are within the change threshold.
+	got := brokerConfigUpdates(zk)
+	expected := throttleUpdates(map[string][2]string{
+		"1003": {"", "96000000"},
+	})
+
+	if _, exists := got["1003"]; !exists {
+		t.Errorf("Expected config updates for broker 1003, got %v", got)
+	}
+
+	for id, configs := range got {
+		if len(configs) != len(expected[id]) {
+			t.Errorf("Expected broker %s configs %v, got %v", id, expected[id], configs)
+			continue
+		}
+
+		for k, v := range expected[id] {
+			if configs[k] != v {
+				t.Errorf("Expected broker %s %s %s, got %s", id, k, v, configs[k])
+			}
+		}
+	}
+
+	// A further interval with the same hosts isn't a replacement.
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := km.Invalidated(); len(ids) != 1 {
+		t.Errorf("Expected no further invalidated brokers, got %v", ids)
+	}
+}
+
+// metricsErrResponse returns a kafkametrics.StubResponse with no metrics and the
+// error.
+func metricsErrResponse(err string) kafkametrics.StubResponse {
+	return kafkametrics.StubResponse{Errors: []error{errors.New(err)}}
+}
+
+func TestOverrideRates(t *testing.T) {
+	zkWriteInterval = 0
+
+	zk := kafkazk.NewZooKeeperStub()
+	km := kafkametrics.NewStub()
+	km.Script(kafkametrics.StubResponse{Metrics: stubBrokerMetrics()})
+	tm := newTestThrottleManager(t, zk, km)
+	tm.SetBrokerOverrides(throttlestore.BrokerOverrides{
+		1003: {ID: 1003, Config: throttlestore.ThrottleOverrideConfig{Rate: 120, Precedence: throttlestore.PrecedenceMin}},
+		1005: {ID: 1005, Pinned: true, Config: throttlestore.ThrottleOverrideConfig{Rate: 10}},
+	})
+
+	if err := tm.UpdateReplicationThrottle(); err != nil {
+		t.Fatal(err)
+	}
+
+	rates := tm.OverrideRates()
+	if len(rates) != 2 {
+		t.Fatalf("Expected 2 override rates, got %v", rates)
+	}
+
+	r := rates[1003]
+	if r.Rate != 120 || r.Precedence != throttlestore.PrecedenceMin || *r.Effective[0] != 120 || *r.Effective[1] != 96 {
+		t.Errorf("Unexpected override rate for 1003: %+v", r)
+	}
+
+	r = rates[1005]
+	if !r.Pinned || *r.Effective[0] != 10 || *r.Effective[1] != 10 {
+		t.Errorf("Unexpected override rate for 1005: %+v", r)
+	}
+}
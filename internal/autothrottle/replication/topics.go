@@ -3,6 +3,7 @@ package replication
 import (
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"strconv"
 )
 
@@ -43,6 +44,23 @@ func (ttr TopicThrottledReplicas) topics() []string {
 	return names
 }
 
+// listsHash returns a hash of the leader and follower throttled replicas lists
+// written for the topic. Lists are "*" in wildcard mode.
+func (ttr TopicThrottledReplicas) listsHash(topic Topic, wildcard bool) uint64 {
+	leaders, followers := "*", "*"
+	if !wildcard {
+		leaders = throttledReplicasList(string(topic), ttr[topic]["leaders"])
+		followers = throttledReplicasList(string(topic), ttr[topic]["followers"])
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(leaders))
+	h.Write([]byte{0})
+	h.Write([]byte(followers))
+
+	return h.Sum64()
+}
+
 // addReplica takes a topic, partition number, role (leader, follower), and
 // broker ID and adds the configuration to the TopicThrottledReplicas.
 func (ttr TopicThrottledReplicas) addReplica(topic Topic, partn string, role ReplicaType, id string) error {
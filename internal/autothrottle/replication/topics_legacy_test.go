@@ -97,3 +97,108 @@ func TestFilter(t *testing.T) {
 	}
 }
 */
+
+func TestLegacyApplyTopicThrottlesWildcard(t *testing.T) {
+	throttled := TopicThrottledReplicas{
+		"test_topic": Throttled{
+			"leaders":   []string{"1:1002", "0:1001"},
+			"followers": []string{"0:1003"},
+		},
+	}
+
+	for _, wildcard := range []bool{false, true} {
+		zk := kafkazk.NewZooKeeperStub()
+		tm := &ThrottleManager{zk: zk, wildcardReplicas: wildcard}
+
+		tm.legacyApplyTopicThrottles(throttled)
+
+		updates := zk.KafkaConfigUpdates()
+		if len(updates) != 1 {
+			t.Fatalf("Expected 1 config update, got %d", len(updates))
+		}
+
+		expected := []kafkazk.KafkaConfigKV{
+			{"leader.replication.throttled.replicas", "0:1001,1:1002"},
+			{"follower.replication.throttled.replicas", "0:1003"},
+		}
+
+		if wildcard {
+			expected = []kafkazk.KafkaConfigKV{
+				{"leader.replication.throttled.replicas", "*"},
+				{"follower.replication.throttled.replicas", "*"},
+			}
+		}
+
+		got := updates[0].Configs
+		if len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+			t.Errorf("[wildcard=%v] Expected configs %v, got %v", wildcard, expected, got)
+		}
+	}
+}
+
+func TestLegacyApplyTopicThrottlesUnchanged(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	tm := &ThrottleManager{zk: zk}
+
+	// The stub topic config has the same replica sets in a different order.
+	throttled := TopicThrottledReplicas{
+		"test_topic": Throttled{
+			"leaders":   []string{"0:1002", "0:1001", "0:1001"},
+			"followers": []string{"0:1004", "0:1003"},
+		},
+	}
+
+	tm.legacyApplyTopicThrottles(throttled)
+
+	if updates := zk.KafkaConfigUpdates(); len(updates) != 0 {
+		t.Errorf("Expected no config updates, got %v", updates)
+	}
+
+	// Only the changed list is written.
+	throttled["test_topic"]["followers"] = []string{"0:1003"}
+
+	tm.legacyApplyTopicThrottles(throttled)
+
+	updates := zk.KafkaConfigUpdates()
+	if len(updates) != 1 || len(updates[0].Configs) != 1 {
+		t.Fatalf("Expected 1 config update with 1 config, got %v", updates)
+	}
+
+	expected := kafkazk.KafkaConfigKV{"follower.replication.throttled.replicas", "0:1003"}
+	if updates[0].Configs[0] != expected {
+		t.Errorf("Expected config %v, got %v", expected, updates[0].Configs[0])
+	}
+}
+
+func TestThrottledReplicasList(t *testing.T) {
+	list := throttledReplicasList("test", []string{"1:1002", "0:1001", "1:1002"})
+	if list != "0:1001,1:1002" {
+		t.Errorf("Expected 0:1001,1:1002, got %s", list)
+	}
+
+	// Lists exceeding the max size fall back to all replicas.
+	defer func(n int) { maxThrottledReplicasListSize = n }(maxThrottledReplicasListSize)
+	maxThrottledReplicasListSize = 10
+
+	list = throttledReplicasList("test", []string{"1:1002", "0:1001"})
+	if list != "*" {
+		t.Errorf("Expected *, got %s", list)
+	}
+}
+
+func TestReplicasListDelta(t *testing.T) {
+	added, removed := replicasListDelta([]string{"0:1001", "1:1002"}, []string{"1:1002", "2:1003"})
+
+	if len(added) != 1 || added[0] != "2:1003" {
+		t.Errorf("Expected added [2:1003], got %v", added)
+	}
+
+	if len(removed) != 1 || removed[0] != "0:1001" {
+		t.Errorf("Expected removed [0:1001], got %v", removed)
+	}
+
+	added, removed = replicasListDelta(nil, nil)
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("Expected no delta, got %v, %v", added, removed)
+	}
+}
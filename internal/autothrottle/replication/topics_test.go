@@ -1,9 +1,11 @@
 package replication
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/DataDog/kafka-kit/v4/internal/autothrottle/throttlestore"
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 	"github.com/DataDog/kafka-kit/v4/kafkaadmin/stub"
 )
 
@@ -92,3 +94,60 @@ func TestGetTopicsWithThrottledBrokers(t *testing.T) {
 		}
 	}
 }
+
+func TestTopicThrottleConfig(t *testing.T) {
+	throttled := TopicThrottledReplicas{
+		"test1": Throttled{
+			"leaders":   []string{"1:1002", "0:1001", "0:1001"},
+			"followers": []string{"0:1003"},
+		},
+		// An offline leader isn't listed.
+		"test2": Throttled{
+			"leaders":   []string{},
+			"followers": []string{"2:1004"},
+		},
+	}
+
+	tm := &ThrottleManager{kafkaNativeMode: true, ka: stub.NewClient()}
+
+	cfg, expected := tm.topicThrottleConfig(throttled)
+
+	expectedReplicas := map[string]kafkaadmin.ThrottledReplicas{
+		"test1": {Leaders: "0:1001,1:1002", Followers: "0:1003"},
+		"test2": {Followers: "2:1004"},
+	}
+
+	if !reflect.DeepEqual(cfg.TopicReplicas, expectedReplicas) {
+		t.Errorf("Expected replicas %v, got %v", expectedReplicas, cfg.TopicReplicas)
+	}
+
+	expectedConfigs := configExpectations{
+		"test1": {
+			"leader.replication.throttled.replicas":   "0:1001,1:1002",
+			"follower.replication.throttled.replicas": "0:1003",
+		},
+		"test2": {
+			"follower.replication.throttled.replicas": "2:1004",
+		},
+	}
+
+	if !reflect.DeepEqual(expected, expectedConfigs) {
+		t.Errorf("Expected configs %v, got %v", expectedConfigs, expected)
+	}
+
+	// In wildcard mode, all replicas are throttled.
+	tm.wildcardReplicas = true
+	cfg, expected = tm.topicThrottleConfig(throttled)
+
+	if cfg.TopicReplicas != nil {
+		t.Errorf("Unexpected replicas %v", cfg.TopicReplicas)
+	}
+
+	for _, topic := range []string{"test1", "test2"} {
+		for _, name := range topicThrottleCfgNames {
+			if expected[topic][name] != "*" {
+				t.Errorf("Expected %s %s to be *, got %s", topic, name, expected[topic][name])
+			}
+		}
+	}
+}
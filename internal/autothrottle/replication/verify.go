@@ -0,0 +1,259 @@
+package replication
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+var (
+	// verifyBackoff is the time waited between a config write and read back
+	// verification retry.
+	verifyBackoff = 2 * time.Second
+)
+
+// ErrConfigDivergence is returned when throttle configs read back after a
+// write persistently don't match the intended state.
+type ErrConfigDivergence struct {
+	Mismatches []configMismatch
+}
+
+func (e ErrConfigDivergence) Error() string {
+	var s []string
+	for _, m := range e.Mismatches {
+		s = append(s, m.String())
+	}
+
+	return fmt.Sprintf("throttle configs diverged from the intended state: %s", strings.Join(s, "; "))
+}
+
+// configExpectations maps resource names (broker IDs or topic names) to config
+// names and their intended values. An empty value means the config is
+// expected to be unset.
+type configExpectations map[string]map[string]string
+
+// configMismatch describes a config whose read back value doesn't match the
+// intended value.
+type configMismatch struct {
+	kind, name, config string
+	want, got          string
+}
+
+func (m configMismatch) String() string {
+	return fmt.Sprintf("%s %s %s: want %s, got %s", m.kind, m.name, m.config, quoteOrUnset(m.want), quoteOrUnset(m.got))
+}
+
+func quoteOrUnset(s string) string {
+	if s == "" {
+		return "<unset>"
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// writeAndVerify calls the write func, then reads back the configs of the
+// specified kind ("broker" or "topic") and compares them to the expected
+// values. On a mismatch, the write is retried up to verifyAttempts times. A
+// persistent divergence is written as a critical event and returned as an
+// ErrConfigDivergence. If verification is disabled, write is called once.
+func (tm *ThrottleManager) writeAndVerify(kind string, expected configExpectations, write func() error) error {
+	if err := tm.snapshotConfigs(kind, expected); err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := write(); err != nil {
+			return err
+		}
+
+		if tm.verifyAttempts < 1 {
+			return nil
+		}
+
+		mismatches, err := tm.verifyConfigs(kind, expected)
+		if err != nil {
+			// A failed read doesn't imply a failed write; log and move on.
+			log.Printf("Error verifying %s throttle configs: %s\n", kind, err)
+			return nil
+		}
+
+		if len(mismatches) == 0 {
+			return nil
+		}
+
+		divergence := ErrConfigDivergence{Mismatches: mismatches}
+
+		if attempt >= tm.verifyAttempts {
+			tm.events.WriteCritical("Replication throttle config divergence",
+				fmt.Sprintf("Throttle configs don't match the intended state after %d attempts: %s", attempt, divergence.Error()))
+			return divergence
+		}
+
+		log.Printf("%s, retrying (attempt %d of %d)\n", divergence.Error(), attempt+1, tm.verifyAttempts)
+		time.Sleep(verifyBackoff)
+	}
+}
+
+// verifyConfigs reads back the configs of the specified kind ("broker" or
+// "topic") for all resources in the configExpectations and returns any
+// mismatches, ordered by resource and config name.
+func (tm *ThrottleManager) verifyConfigs(kind string, expected configExpectations) ([]configMismatch, error) {
+	var names []string
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	current, err := tm.currentConfigs(kind, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []configMismatch
+
+	for _, name := range names {
+		var configNames []string
+		for config := range expected[name] {
+			configNames = append(configNames, config)
+		}
+		sort.Strings(configNames)
+
+		for _, config := range configNames {
+			want, got := expected[name][config], current[name][config]
+			if want != got {
+				mismatches = append(mismatches, configMismatch{
+					kind:   kind,
+					name:   name,
+					config: config,
+					want:   want,
+					got:    got,
+				})
+			}
+		}
+	}
+
+	return mismatches, nil
+}
+
+// currentConfigs returns the dynamic configs for the named brokers or topics,
+// read from Kafka or ZooKeeper according to the mode.
+func (tm *ThrottleManager) currentConfigs(kind string, names []string) (map[string]map[string]string, error) {
+	if tm.kafkaNativeMode {
+		return tm.readConfigs(kind, names)
+	}
+	return tm.legacyReadConfigs(kind, names)
+}
+
+// readConfigs returns the dynamic configs for the named brokers or topics.
+func (tm *ThrottleManager) readConfigs(kind string, names []string) (map[string]map[string]string, error) {
+	ctx, cancel := tm.kafkaRequestContext()
+	defer cancel()
+
+	switch kind {
+	case "broker":
+		var ids []int
+		for _, name := range names {
+			id, err := strconv.Atoi(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid broker ID %s", name)
+			}
+			ids = append(ids, id)
+		}
+
+		configs, errs := tm.ka.BulkGetDynamicConfigs(ctx, ids)
+		if errs != nil {
+			return nil, errs
+		}
+
+		return configs, nil
+	default:
+		return tm.ka.GetDynamicConfigs(ctx, kind, names)
+	}
+}
+
+// legacyReadConfigs returns the dynamic configs for the named brokers or topics
+// from ZooKeeper.
+func (tm *ThrottleManager) legacyReadConfigs(kind string, names []string) (map[string]map[string]string, error) {
+	var configs = make(map[string]map[string]string)
+
+	for _, name := range names {
+		var config map[string]string
+		var err error
+
+		switch kind {
+		case "broker":
+			id, _ := strconv.Atoi(name)
+			var c *kafkazk.BrokerConfig
+			if c, err = tm.zk.GetBrokerConfig(id); err == nil {
+				config = c.Config
+			}
+		default:
+			var c *kafkazk.TopicConfig
+			if c, err = tm.zk.GetTopicConfig(name); err == nil {
+				config = c.Config
+			}
+		}
+
+		switch err.(type) {
+		case nil:
+			configs[name] = config
+		case kafkazk.ErrNoNode:
+			// No config znode means no configs are set.
+		default:
+			return nil, err
+		}
+	}
+
+	return configs, nil
+}
+
+// throttleExpectations takes a list of resource names, throttle config names
+// and a value and returns a configExpectations where each config is expected
+// to hold the value for each resource.
+func throttleExpectations(names []string, configs [2]string, value string) configExpectations {
+	expected := configExpectations{}
+	for _, name := range names {
+		expected[name] = map[string]string{
+			configs[0]: value,
+			configs[1]: value,
+		}
+	}
+
+	return expected
+}
+
+// kafkaConfigExpectations takes a kafkazk.KafkaConfig and returns a
+// configExpectations for the configs it sets.
+func kafkaConfigExpectations(c kafkazk.KafkaConfig) configExpectations {
+	expected := configExpectations{}
+	for _, kv := range c.Configs {
+		if _, exists := expected[c.Name]; !exists {
+			expected[c.Name] = map[string]string{}
+		}
+		expected[c.Name][kv[0]] = kv[1]
+	}
+
+	return expected
+}
+
+// mergeChanged takes two kafkazk.UpdateKafkaConfig changed results and merges b
+// into a; a config is changed if it was changed in either.
+func mergeChanged(a, b []bool) []bool {
+	for i, changed := range b {
+		if i < len(a) {
+			a[i] = a[i] || changed
+		} else {
+			a = append(a, changed)
+		}
+	}
+
+	return a
+}
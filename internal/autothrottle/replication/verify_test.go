@@ -0,0 +1,123 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin/stub"
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestWriteAndVerify(t *testing.T) {
+	verifyBackoff = 0
+	events := &eventsStub{}
+	tm := &ThrottleManager{
+		zk:             kafkazk.NewZooKeeperStub(),
+		events:         events,
+		verifyAttempts: 3,
+	}
+
+	var writes int
+	write := func() error {
+		writes++
+		return nil
+	}
+
+	// The stub has throttles set on all brokers.
+	expected := throttleExpectations([]string{"1001"}, brokerThrottleCfgNames, "100000000")
+
+	if err := tm.writeAndVerify("broker", expected, write); err != nil {
+		t.Fatal(err)
+	}
+
+	if writes != 1 {
+		t.Errorf("Expected 1 write, got %d", writes)
+	}
+
+	// The stub throttles can't be removed; the divergence persists.
+	writes = 0
+	expected = throttleExpectations([]string{"1001"}, brokerThrottleCfgNames, "")
+
+	err := tm.writeAndVerify("broker", expected, write)
+	divergence, ok := err.(ErrConfigDivergence)
+	if !ok {
+		t.Fatalf("Expected ErrConfigDivergence, got %v", err)
+	}
+
+	if writes != 3 {
+		t.Errorf("Expected 3 writes, got %d", writes)
+	}
+
+	if len(divergence.Mismatches) != 2 {
+		t.Errorf("Expected 2 mismatches, got %d", len(divergence.Mismatches))
+	}
+
+	expectedErr := "throttle configs diverged from the intended state: " +
+		`broker 1001 follower.replication.throttled.rate: want <unset>, got "100000000"; ` +
+		`broker 1001 leader.replication.throttled.rate: want <unset>, got "100000000"`
+	if err.Error() != expectedErr {
+		t.Errorf("Expected error:\n%s\ngot:\n%s", expectedErr, err)
+	}
+
+	if len(events.criticalTitles) != 1 {
+		t.Errorf("Expected a critical event, got %v", events.criticalTitles)
+	}
+
+	// Verification disabled.
+	writes = 0
+	tm.verifyAttempts = 0
+
+	if err := tm.writeAndVerify("broker", expected, write); err != nil {
+		t.Fatal(err)
+	}
+
+	if writes != 1 {
+		t.Errorf("Expected 1 write, got %d", writes)
+	}
+}
+
+func TestVerifyConfigsNative(t *testing.T) {
+	tm := &ThrottleManager{
+		kafkaNativeMode: true,
+		ka:              stub.NewClient(),
+	}
+
+	// The stub topics only have retention.ms set.
+	expected := throttleExpectations([]string{"test1"}, topicThrottleCfgNames, "*")
+	expected["test1"]["retention.ms"] = "172800000"
+
+	mismatches, err := tm.verifyConfigs("topic", expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mismatches) != 2 {
+		t.Fatalf("Expected 2 mismatches, got %v", mismatches)
+	}
+
+	for _, m := range mismatches {
+		if m.want != "*" || m.got != "" {
+			t.Errorf("Unexpected mismatch %s", m)
+		}
+	}
+
+	expected = throttleExpectations([]string{"test1"}, topicThrottleCfgNames, "")
+
+	mismatches, err = tm.verifyConfigs("topic", expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mismatches) != 0 {
+		t.Errorf("Expected no mismatches, got %v", mismatches)
+	}
+}
+
+func TestMergeChanged(t *testing.T) {
+	var changed []bool
+	changed = mergeChanged(changed, []bool{true, false})
+	changed = mergeChanged(changed, []bool{false, true})
+
+	if len(changed) != 2 || !changed[0] || !changed[1] {
+		t.Errorf("Expected [true true], got %v", changed)
+	}
+}
@@ -9,6 +9,13 @@ type BrokerThrottleOverride struct {
 	ID int
 	// Whether this override is for a broker that's part of a reassignment.
 	ReassignmentParticipant bool
+	// Whether this is a pinned throttle rather than an override. Pinned
+	// throttles are never recomputed or automatically removed.
+	Pinned bool
+	// Whether the broker only has overrides pending a scheduled start. These are
+	// retained with a rate of 0 so that any throttles are removed, but unlike
+	// other overrides with a rate of 0, the stored override isn't removed.
+	Scheduled bool
 	// The ThrottleOverrideConfig.
 	Config ThrottleOverrideConfig
 }
@@ -18,12 +25,23 @@ type BrokerOverridesFilterFn func(BrokerThrottleOverride) bool
 
 // Copy returns a copy of a BrokerThrottleOverride.
 func (b BrokerThrottleOverride) Copy() BrokerThrottleOverride {
+	var requesters map[string]ThrottleOverrideConfig
+	if b.Config.Requesters != nil {
+		requesters = b.Config.RequesterOverrides()
+	}
+
 	return BrokerThrottleOverride{
 		ID:                      b.ID,
 		ReassignmentParticipant: b.ReassignmentParticipant,
+		Pinned:                  b.Pinned,
+		Scheduled:               b.Scheduled,
 		Config: ThrottleOverrideConfig{
 			Rate:       b.Config.Rate,
 			AutoRemove: b.Config.AutoRemove,
+			Expires:    b.Config.Expires,
+			StartAt:    b.Config.StartAt,
+			Precedence: b.Config.Precedence,
+			Requesters: requesters,
 		},
 	}
 }
@@ -50,3 +68,52 @@ func (b BrokerOverrides) Filter(fn BrokerOverridesFilterFn) BrokerOverrides {
 
 	return bo
 }
+
+// RemovePending sets the config of each override to its active config,
+// without any requester overrides pending a scheduled start. Brokers left
+// without an override are removed, unless their IDs are in the []int keep,
+// such as brokers with overrides that just expired; those are left with a
+// rate of 0 and marked Scheduled so that their throttles are removed.
+func (b BrokerOverrides) RemovePending(keep []int) {
+	var keepSet = make(map[int]struct{}, len(keep))
+	for _, id := range keep {
+		keepSet[id] = struct{}{}
+	}
+
+	for id, override := range b {
+		active := override.Config.Active()
+		if active.Rate != 0 || override.Config.Rate == 0 {
+			override.Config = active
+			b[id] = override
+			continue
+		}
+
+		// Only pending overrides.
+		if _, exists := keepSet[id]; !exists {
+			delete(b, id)
+			continue
+		}
+
+		override.Config = active
+		override.Scheduled = true
+		b[id] = override
+	}
+}
+
+// ApplyPins takes a BrokerOverrides of pinned broker throttles and sets them in
+// the BrokerOverrides, taking precedence over any override for the same
+// broker. Pins with a rate of 0 are marked for removal and only set if the
+// broker has no override.
+func (b BrokerOverrides) ApplyPins(pins BrokerOverrides) {
+	for id, pin := range pins {
+		if _, exists := b[id]; exists && pin.Config.Rate == 0 {
+			continue
+		}
+
+		b[id] = BrokerThrottleOverride{
+			ID:     id,
+			Pinned: true,
+			Config: ThrottleOverrideConfig{Rate: pin.Config.Rate},
+		}
+	}
+}
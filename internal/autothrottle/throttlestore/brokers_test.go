@@ -0,0 +1,74 @@
+package throttlestore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyPins(t *testing.T) {
+	bo := BrokerOverrides{
+		1001: {ID: 1001, Config: ThrottleOverrideConfig{Rate: 10, AutoRemove: true}},
+		1002: {ID: 1002, Config: ThrottleOverrideConfig{Rate: 20}},
+	}
+
+	pins := BrokerOverrides{
+		1001: {ID: 1001, Config: ThrottleOverrideConfig{Rate: 50}},
+		// Pins marked for removal shouldn't replace overrides.
+		1002: {ID: 1002, Config: ThrottleOverrideConfig{Rate: 0}},
+		1003: {ID: 1003, Config: ThrottleOverrideConfig{Rate: 0}},
+	}
+
+	bo.ApplyPins(pins)
+
+	expected := map[int]struct {
+		rate   int
+		pinned bool
+	}{
+		1001: {50, true},
+		1002: {20, false},
+		1003: {0, true},
+	}
+
+	for id, e := range expected {
+		got := bo[id]
+		if got.Config.Rate != e.rate || got.Pinned != e.pinned {
+			t.Errorf("[%d] Expected rate %d pinned %v, got rate %d pinned %v",
+				id, e.rate, e.pinned, got.Config.Rate, got.Pinned)
+		}
+	}
+
+	if bo[1001].Config.AutoRemove {
+		t.Error("Expected pinned throttle to not be autoremoved")
+	}
+}
+
+func TestRemovePending(t *testing.T) {
+	later := time.Now().Add(time.Hour).Unix()
+
+	bo := BrokerOverrides{
+		1001: {ID: 1001, Config: ThrottleOverrideConfig{Rate: 10}},
+		1002: {ID: 1002, Config: ThrottleOverrideConfig{Rate: 20, StartAt: later}},
+		// Pending, with a just expired override.
+		1003: {ID: 1003, Config: ThrottleOverrideConfig{Rate: 30, StartAt: later}},
+		// Marked for removal.
+		1004: {ID: 1004, Config: ThrottleOverrideConfig{Rate: 0}},
+	}
+
+	bo.RemovePending([]int{1003})
+
+	if bo[1001].Config.Rate != 10 || bo[1001].Scheduled {
+		t.Errorf("Unexpected override for broker 1001: %+v", bo[1001])
+	}
+
+	if _, exists := bo[1002]; exists {
+		t.Error("Expected pending override for broker 1002 to be removed")
+	}
+
+	if bo[1003].Config.Rate != 0 || !bo[1003].Scheduled {
+		t.Errorf("Expected broker 1003 to be scheduled with a rate of 0, got %+v", bo[1003])
+	}
+
+	if bo[1004].Config.Rate != 0 || bo[1004].Scheduled {
+		t.Errorf("Unexpected override for broker 1004: %+v", bo[1004])
+	}
+}
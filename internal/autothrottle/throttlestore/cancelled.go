@@ -0,0 +1,95 @@
+package throttlestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// CancelledReassignments holds the topics of cancelled reassignments whose
+// throttles are yet to be cleared.
+type CancelledReassignments struct {
+	Topics []string `json:"topics"`
+}
+
+// FetchCancelledReassignments gets the cancelled reassignments from path p. If
+// none are recorded, an empty CancelledReassignments is returned.
+func FetchCancelledReassignments(zk kafkazk.Handler, p string) (CancelledReassignments, error) {
+	c := CancelledReassignments{}
+
+	if exists, err := zk.Exists(p); err != nil {
+		return c, fmt.Errorf("error getting cancelled reassignments: %s", err)
+	} else if !exists {
+		return c, nil
+	}
+
+	data, err := zk.Get(p)
+	if err != nil {
+		return c, fmt.Errorf("error getting cancelled reassignments: %s", err)
+	}
+
+	if len(data) == 0 {
+		return c, nil
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("error unmarshalling cancelled reassignments: %s", err)
+	}
+
+	return c, nil
+}
+
+// AddCancelledReassignments records the topics as cancelled at path p, in
+// addition to any already recorded.
+func AddCancelledReassignments(zk kafkazk.Handler, p string, topics []string) error {
+	c, err := FetchCancelledReassignments(zk, p)
+	if err != nil {
+		return err
+	}
+
+	set := map[string]struct{}{}
+	for _, t := range append(c.Topics, topics...) {
+		set[t] = struct{}{}
+	}
+
+	c.Topics = c.Topics[:0]
+	for t := range set {
+		c.Topics = append(c.Topics, t)
+	}
+	sort.Strings(c.Topics)
+
+	d, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling cancelled reassignments: %s", err)
+	}
+
+	exists, _ := zk.Exists(p)
+
+	if exists {
+		err = zk.Set(p, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting cancelled reassignments: %s", err)
+	}
+
+	return nil
+}
+
+// RemoveCancelledReassignments deletes the cancelled reassignments at path p.
+func RemoveCancelledReassignments(zk kafkazk.Handler, p string) error {
+	exists, err := zk.Exists(p)
+	if !exists && err == nil {
+		return nil
+	}
+
+	if err := zk.Delete(p); err != nil {
+		return fmt.Errorf("error removing cancelled reassignments: %s", err)
+	}
+
+	return nil
+}
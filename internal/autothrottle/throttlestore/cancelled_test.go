@@ -0,0 +1,48 @@
+package throttlestore
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestCancelledReassignments(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	path := "/autothrottle/cancelled_reassignments"
+
+	c, err := FetchCancelledReassignments(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Topics) != 0 {
+		t.Errorf("Expected no cancelled topics, got %v", c.Topics)
+	}
+
+	if err := AddCancelledReassignments(zk, path, []string{"b", "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddCancelledReassignments(zk, path, []string{"a", "c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, _ = FetchCancelledReassignments(zk, path); !reflect.DeepEqual(c.Topics, []string{"a", "b", "c"}) {
+		t.Errorf("Expected cancelled topics [a b c], got %v", c.Topics)
+	}
+
+	if err := RemoveCancelledReassignments(zk, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing when nothing is recorded is a no-op.
+	if err := RemoveCancelledReassignments(zk, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, _ = FetchCancelledReassignments(zk, path); len(c.Topics) != 0 {
+		t.Errorf("Expected no cancelled topics, got %v", c.Topics)
+	}
+}
@@ -0,0 +1,64 @@
+package throttlestore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// CleanupState holds the time of the most recent global throttle unset so
+// that cleanup timing survives restarts.
+type CleanupState struct {
+	// Unix timestamp (seconds) of the most recent global throttle unset.
+	Last int64 `json:"last"`
+}
+
+// FetchCleanupState gets the cleanup state from path p. If no state is set, a
+// zero CleanupState is returned.
+func FetchCleanupState(zk kafkazk.Handler, p string) (CleanupState, error) {
+	c := CleanupState{}
+
+	if exists, err := zk.Exists(p); err != nil {
+		return c, fmt.Errorf("error getting cleanup state: %s", err)
+	} else if !exists {
+		return c, nil
+	}
+
+	data, err := zk.Get(p)
+	if err != nil {
+		return c, fmt.Errorf("error getting cleanup state: %s", err)
+	}
+
+	if len(data) == 0 {
+		return c, nil
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("error unmarshalling cleanup state: %s", err)
+	}
+
+	return c, nil
+}
+
+// StoreCleanupState sets the cleanup state to path p.
+func StoreCleanupState(zk kafkazk.Handler, p string, c CleanupState) error {
+	d, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling cleanup state: %s", err)
+	}
+
+	exists, _ := zk.Exists(p)
+
+	if exists {
+		err = zk.Set(p, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting cleanup state: %s", err)
+	}
+
+	return nil
+}
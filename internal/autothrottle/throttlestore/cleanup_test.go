@@ -0,0 +1,38 @@
+package throttlestore
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestCleanupState(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	path := "/autothrottle/last_cleanup"
+
+	// No state is set.
+	c, err := FetchCleanupState(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Last != 0 {
+		t.Errorf("Expected an unset cleanup state, got %+v", c)
+	}
+
+	for _, last := range []int64{1700000000, 1700003600} {
+		if err := StoreCleanupState(zk, path, CleanupState{Last: last}); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := FetchCleanupState(zk, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.Last != last {
+			t.Errorf("Expected last cleanup %d, got %d", last, c.Last)
+		}
+	}
+}
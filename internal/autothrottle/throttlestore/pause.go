@@ -0,0 +1,65 @@
+package throttlestore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// PauseConfig holds the autothrottle pause state. While paused, autothrottle
+// continues observing reassignments but doesn't write any throttle changes.
+type PauseConfig struct {
+	Paused bool `json:"paused"`
+	// Unix timestamp (seconds) of when autothrottle was paused.
+	Since int64 `json:"since,omitempty"`
+}
+
+// FetchPauseConfig gets the pause config from path p. If no config is set, an
+// unpaused PauseConfig is returned.
+func FetchPauseConfig(zk kafkazk.Handler, p string) (PauseConfig, error) {
+	c := PauseConfig{}
+
+	if exists, err := zk.Exists(p); err != nil {
+		return c, fmt.Errorf("error getting pause config: %s", err)
+	} else if !exists {
+		return c, nil
+	}
+
+	data, err := zk.Get(p)
+	if err != nil {
+		return c, fmt.Errorf("error getting pause config: %s", err)
+	}
+
+	if len(data) == 0 {
+		return c, nil
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("error unmarshalling pause config: %s", err)
+	}
+
+	return c, nil
+}
+
+// StorePauseConfig sets the pause config to path p.
+func StorePauseConfig(zk kafkazk.Handler, p string, c PauseConfig) error {
+	d, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling pause config: %s", err)
+	}
+
+	exists, _ := zk.Exists(p)
+
+	if exists {
+		err = zk.Set(p, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting pause config: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package throttlestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// BrokerPeaks is a map of broker ID to BrokerPeak.
+type BrokerPeaks map[int]BrokerPeak
+
+// BrokerPeak holds the network throughput samples observed for a broker,
+// used to calibrate its capacity.
+type BrokerPeak struct {
+	// The instance type the samples were observed on. Samples are discarded if
+	// the broker's instance type changes.
+	InstanceType string `json:"instance_type"`
+	// Samples in ascending order of time.
+	Samples []PeakSample `json:"samples"`
+}
+
+// PeakSample is the peak outbound and inbound network throughput in MB/s
+// observed within a period.
+type PeakSample struct {
+	// Unix timestamp (seconds) of the start of the period.
+	Start int64   `json:"start"`
+	TX    float64 `json:"tx"`
+	RX    float64 `json:"rx"`
+}
+
+// Max returns the peak outbound and inbound throughput across all samples.
+func (b BrokerPeak) Max() (tx, rx float64) {
+	for _, s := range b.Samples {
+		if s.TX > tx {
+			tx = s.TX
+		}
+		if s.RX > rx {
+			rx = s.RX
+		}
+	}
+
+	return tx, rx
+}
+
+// FetchBrokerPeaks returns the BrokerPeaks for all brokers with samples stored
+// beneath path p.
+func FetchBrokerPeaks(zk kafkazk.Handler, p string) (BrokerPeaks, error) {
+	peaks := BrokerPeaks{}
+
+	ids, err := zk.Children(p)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range ids {
+		id, err := strconv.Atoi(child)
+		if err != nil {
+			continue
+		}
+
+		data, err := zk.Get(fmt.Sprintf("%s/%s", p, child))
+		if err != nil {
+			return peaks, fmt.Errorf("error getting broker peaks: %s", err)
+		}
+
+		var b BrokerPeak
+		if err := json.Unmarshal(data, &b); err != nil {
+			return peaks, fmt.Errorf("error unmarshalling broker peaks: %s", err)
+		}
+
+		peaks[id] = b
+	}
+
+	return peaks, nil
+}
+
+// StoreBrokerPeak sets the BrokerPeak for broker id beneath path p.
+func StoreBrokerPeak(zk kafkazk.Handler, p string, id int, b BrokerPeak) error {
+	d, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("error marshalling broker peaks: %s", err)
+	}
+
+	path := fmt.Sprintf("%s/%d", p, id)
+	exists, _ := zk.Exists(path)
+
+	if exists {
+		err = zk.Set(path, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, path, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting broker peaks: %s", err)
+	}
+
+	return nil
+}
+
+// RemoveBrokerPeak deletes the BrokerPeak for broker id beneath path p.
+func RemoveBrokerPeak(zk kafkazk.Handler, p string, id int) error {
+	path := fmt.Sprintf("%s/%d", p, id)
+
+	exists, err := zk.Exists(path)
+	if !exists && err == nil {
+		return nil
+	}
+
+	if err := zk.Delete(path); err != nil {
+		return fmt.Errorf("error removing broker peaks: %s", err)
+	}
+
+	return nil
+}
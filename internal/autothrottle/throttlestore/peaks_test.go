@@ -0,0 +1,59 @@
+package throttlestore
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestBrokerPeaks(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	zk.Create("/autothrottle/capacity_peaks", "")
+	path := "/autothrottle/capacity_peaks"
+
+	peak := BrokerPeak{
+		InstanceType: "i3.2xlarge",
+		Samples: []PeakSample{
+			{Start: 3600, TX: 180, RX: 90},
+			{Start: 7200, TX: 150, RX: 140},
+		},
+	}
+
+	if err := StoreBrokerPeak(zk, path, 1001, peak); err != nil {
+		t.Fatal(err)
+	}
+
+	// Update the peaks.
+	peak.Samples = append(peak.Samples, PeakSample{Start: 10800, TX: 10, RX: 10})
+	if err := StoreBrokerPeak(zk, path, 1001, peak); err != nil {
+		t.Fatal(err)
+	}
+
+	peaks, err := FetchBrokerPeaks(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(peaks, BrokerPeaks{1001: peak}) {
+		t.Errorf("Unexpected peaks %v", peaks)
+	}
+
+	if tx, rx := peaks[1001].Max(); tx != 180 || rx != 140 {
+		t.Errorf("Expected max tx 180, rx 140, got %f, %f", tx, rx)
+	}
+
+	if err := RemoveBrokerPeak(zk, path, 1001); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing non-existent peaks is a no-op.
+	if err := RemoveBrokerPeak(zk, path, 1001); err != nil {
+		t.Fatal(err)
+	}
+
+	if peaks, _ = FetchBrokerPeaks(zk, path); len(peaks) != 0 {
+		t.Errorf("Expected no peaks, got %v", peaks)
+	}
+}
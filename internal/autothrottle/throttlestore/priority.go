@@ -0,0 +1,93 @@
+package throttlestore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// DefaultPriority is the weight of a reassigning topic without a priority set.
+const DefaultPriority = 1
+
+// TopicPriorities is a map of topic name to priority weight.
+type TopicPriorities map[string]int
+
+// Weight returns the priority weight for topic t, or the DefaultPriority if
+// none is set.
+func (tp TopicPriorities) Weight(t string) int {
+	if w, exists := tp[t]; exists && w > 0 {
+		return w
+	}
+
+	return DefaultPriority
+}
+
+// PriorityConfig holds a topic priority.
+type PriorityConfig struct {
+	Weight int `json:"weight"`
+}
+
+// FetchTopicPriorities returns the TopicPriorities for all topics with a
+// priority set beneath path p.
+func FetchTopicPriorities(zk kafkazk.Handler, p string) (TopicPriorities, error) {
+	priorities := TopicPriorities{}
+
+	topics, err := zk.Children(p)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range topics {
+		c := &PriorityConfig{}
+
+		data, err := zk.Get(fmt.Sprintf("%s/%s", p, t))
+		if err != nil {
+			return priorities, fmt.Errorf("error getting topic priority: %s", err)
+		}
+
+		if err := json.Unmarshal(data, c); err != nil {
+			return priorities, fmt.Errorf("error unmarshalling topic priority: %s", err)
+		}
+
+		priorities[t] = c.Weight
+	}
+
+	return priorities, nil
+}
+
+// StoreTopicPriority sets the priority config to path p.
+func StoreTopicPriority(zk kafkazk.Handler, p string, c PriorityConfig) error {
+	d, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling topic priority: %s", err)
+	}
+
+	exists, _ := zk.Exists(p)
+
+	if exists {
+		err = zk.Set(p, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting topic priority: %s", err)
+	}
+
+	return nil
+}
+
+// RemoveTopicPriority deletes the priority at path p.
+func RemoveTopicPriority(zk kafkazk.Handler, p string) error {
+	exists, err := zk.Exists(p)
+	if !exists && err == nil {
+		return nil
+	}
+
+	if err := zk.Delete(p); err != nil {
+		return fmt.Errorf("error removing topic priority: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package throttlestore
+
+import (
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestTopicPriorities(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	zk.Create("/autothrottle/priorities", "")
+	path := "/autothrottle/priorities"
+
+	for topic, weight := range map[string]int{"a": 3, "b": 2} {
+		if err := StoreTopicPriority(zk, path+"/"+topic, PriorityConfig{Weight: weight}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Update a priority.
+	if err := StoreTopicPriority(zk, path+"/b", PriorityConfig{Weight: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	tp, err := FetchTopicPriorities(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tp) != 2 || tp.Weight("a") != 3 || tp.Weight("b") != 5 {
+		t.Errorf("Expected priorities a:3 b:5, got %v", tp)
+	}
+
+	if w := tp.Weight("c"); w != DefaultPriority {
+		t.Errorf("Expected default weight %d, got %d", DefaultPriority, w)
+	}
+
+	if err := RemoveTopicPriority(zk, path+"/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing a non-existent priority is a no-op.
+	if err := RemoveTopicPriority(zk, path+"/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if tp, _ = FetchTopicPriorities(zk, path); len(tp) != 1 {
+		t.Errorf("Expected 1 priority, got %v", tp)
+	}
+}
@@ -0,0 +1,115 @@
+package throttlestore
+
+import (
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// Throttle overrides are namespaced by requester so that multiple automation
+// systems can set overrides without clobbering each other. Each requester's
+// override is held in the Requesters map of the stored ThrottleOverrideConfig,
+// while the top-level fields hold the effective override: that of the
+// requester with the lowest rate. Overrides set without a requester are held
+// by the empty requester and, when no other requesters are set, stored
+// without a Requesters map as they always have been.
+
+// SetRequesterOverride sets the override of requester at path p to c, or
+// removes it if c.Rate is 0, leaving the overrides of any other requesters in
+// place. The resulting effective override is stored and returned.
+func SetRequesterOverride(zk kafkazk.Handler, p string, requester string, c ThrottleOverrideConfig) (ThrottleOverrideConfig, error) {
+	current := &ThrottleOverrideConfig{}
+
+	if exists, _ := zk.Exists(p); exists {
+		var err error
+		if current, err = FetchThrottleOverride(zk, p); err != nil {
+			return ThrottleOverrideConfig{}, err
+		}
+	}
+
+	updated := current.WithRequester(requester, c)
+
+	return updated, StoreThrottleOverride(zk, p, updated)
+}
+
+// RequesterOverrides returns the override of each requester, keyed by
+// requester name.
+func (c ThrottleOverrideConfig) RequesterOverrides() map[string]ThrottleOverrideConfig {
+	overrides := map[string]ThrottleOverrideConfig{}
+
+	if c.Requesters == nil {
+		if c.Rate != 0 {
+			overrides[""] = c
+		}
+		return overrides
+	}
+
+	for r, o := range c.Requesters {
+		overrides[r] = o
+	}
+
+	return overrides
+}
+
+// WithRequester returns a copy of the ThrottleOverrideConfig with the override
+// of requester set to o, or removed if o.Rate is 0.
+func (c ThrottleOverrideConfig) WithRequester(requester string, o ThrottleOverrideConfig) ThrottleOverrideConfig {
+	overrides := c.RequesterOverrides()
+
+	if o.Rate == 0 {
+		delete(overrides, requester)
+	} else {
+		o.Requesters = nil
+		overrides[requester] = o
+	}
+
+	return effectiveOverride(overrides)
+}
+
+// Remove returns a copy of the ThrottleOverrideConfig without the requester
+// overrides for which fn returns true, along with the number removed.
+func (c ThrottleOverrideConfig) Remove(fn func(ThrottleOverrideConfig) bool) (ThrottleOverrideConfig, int) {
+	overrides := c.RequesterOverrides()
+
+	var removed int
+	for r, o := range overrides {
+		if fn(o) {
+			delete(overrides, r)
+			removed++
+		}
+	}
+
+	return effectiveOverride(overrides), removed
+}
+
+// effectiveOverride takes a map of requester overrides and returns a
+// ThrottleOverrideConfig holding them, with the lowest rate override as the
+// effective override. Ties are broken by requester name.
+func effectiveOverride(overrides map[string]ThrottleOverrideConfig) ThrottleOverrideConfig {
+	if len(overrides) == 0 {
+		return ThrottleOverrideConfig{}
+	}
+
+	var requesters []string
+	for r := range overrides {
+		requesters = append(requesters, r)
+	}
+	sort.Strings(requesters)
+
+	min := requesters[0]
+	for _, r := range requesters[1:] {
+		if overrides[r].Rate < overrides[min].Rate {
+			min = r
+		}
+	}
+
+	c := overrides[min]
+
+	// Overrides set only without a requester are stored as they were before
+	// requesters were introduced.
+	if _, exists := overrides[""]; !exists || len(overrides) > 1 {
+		c.Requesters = overrides
+	}
+
+	return c
+}
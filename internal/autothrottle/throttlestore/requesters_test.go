@@ -0,0 +1,71 @@
+package throttlestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestSetRequesterOverride(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	path := "/autothrottle/override_rate"
+
+	// An override set without a requester is stored as it always has been.
+	c, err := SetRequesterOverride(zk, path, "", ThrottleOverrideConfig{Rate: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Rate != 200 || c.Requesters != nil {
+		t.Errorf("Unexpected override %+v", c)
+	}
+
+	// The lowest rate override is in effect.
+	SetRequesterOverride(zk, path, "ci", ThrottleOverrideConfig{Rate: 100, AutoRemove: true})
+	SetRequesterOverride(zk, path, "operator", ThrottleOverrideConfig{Rate: 150})
+
+	stored, err := FetchThrottleOverride(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.Rate != 100 || !stored.AutoRemove || len(stored.Requesters) != 3 {
+		t.Errorf("Unexpected override %+v", stored)
+	}
+
+	// Removing an override leaves those of other requesters in place.
+	c, _ = SetRequesterOverride(zk, path, "ci", ThrottleOverrideConfig{})
+	if c.Rate != 150 || c.AutoRemove || len(c.Requesters) != 2 {
+		t.Errorf("Unexpected override %+v", c)
+	}
+
+	c, _ = SetRequesterOverride(zk, path, "operator", ThrottleOverrideConfig{})
+	if c.Rate != 200 || c.Requesters != nil {
+		t.Errorf("Unexpected override %+v", c)
+	}
+
+	c, _ = SetRequesterOverride(zk, path, "", ThrottleOverrideConfig{})
+	if c.Rate != 0 || c.Requesters != nil {
+		t.Errorf("Unexpected override %+v", c)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	c := ThrottleOverrideConfig{Rate: 200, AutoRemove: true}
+	c = c.WithRequester("ci", ThrottleOverrideConfig{Rate: 100, Expires: time.Now().Add(-time.Minute).Unix()})
+
+	if !c.Expired() {
+		t.Error("Expected an expired requester override")
+	}
+
+	c, removed := c.Remove(ThrottleOverrideConfig.Expired)
+	if removed != 1 || c.Rate != 200 || c.Expired() {
+		t.Errorf("Unexpected override %+v", c)
+	}
+
+	c, removed = c.Remove(func(c ThrottleOverrideConfig) bool { return c.AutoRemove })
+	if removed != 1 || c.Rate != 0 {
+		t.Errorf("Unexpected override %+v", c)
+	}
+}
@@ -0,0 +1,174 @@
+package throttlestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+// ConfigSnapshot holds broker and topic dynamic config values as they were
+// before autothrottle first wrote to them, keyed by resource name (broker ID
+// or topic name) and config name. An empty value means the config was unset.
+type ConfigSnapshot struct {
+	Brokers map[string]map[string]string `json:"brokers,omitempty"`
+	Topics  map[string]map[string]string `json:"topics,omitempty"`
+	// Unix timestamp (seconds) of the first recorded value.
+	Created int64 `json:"created,omitempty"`
+}
+
+// Resources returns the snapshot values for the specified kind ("broker" or
+// "topic"), initializing the map if needed.
+func (s *ConfigSnapshot) Resources(kind string) map[string]map[string]string {
+	switch kind {
+	case "broker":
+		if s.Brokers == nil {
+			s.Brokers = map[string]map[string]string{}
+		}
+		return s.Brokers
+	default:
+		if s.Topics == nil {
+			s.Topics = map[string]map[string]string{}
+		}
+		return s.Topics
+	}
+}
+
+// Empty returns whether the snapshot holds no values.
+func (s ConfigSnapshot) Empty() bool {
+	return len(s.Brokers) == 0 && len(s.Topics) == 0
+}
+
+// FetchConfigSnapshot gets the config snapshot from path p. If no snapshot is
+// stored, an empty ConfigSnapshot is returned.
+func FetchConfigSnapshot(zk kafkazk.Handler, p string) (ConfigSnapshot, error) {
+	s := ConfigSnapshot{}
+
+	if exists, err := zk.Exists(p); err != nil {
+		return s, fmt.Errorf("error getting config snapshot: %s", err)
+	} else if !exists {
+		return s, nil
+	}
+
+	data, err := zk.Get(p)
+	if err != nil {
+		return s, fmt.Errorf("error getting config snapshot: %s", err)
+	}
+
+	if len(data) == 0 {
+		return s, nil
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("error unmarshalling config snapshot: %s", err)
+	}
+
+	return s, nil
+}
+
+// StoreConfigSnapshot sets the config snapshot to path p.
+func StoreConfigSnapshot(zk kafkazk.Handler, p string, s ConfigSnapshot) error {
+	d, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("error marshalling config snapshot: %s", err)
+	}
+
+	exists, _ := zk.Exists(p)
+
+	if exists {
+		err = zk.Set(p, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting config snapshot: %s", err)
+	}
+
+	return nil
+}
+
+// RemoveConfigSnapshot deletes the config snapshot at path p.
+func RemoveConfigSnapshot(zk kafkazk.Handler, p string) error {
+	exists, err := zk.Exists(p)
+	if !exists && err == nil {
+		return nil
+	}
+
+	if err := zk.Delete(p); err != nil {
+		return fmt.Errorf("error removing config snapshot: %s", err)
+	}
+
+	return nil
+}
+
+// ConfigRestoreRequest records a request to restore the config snapshot.
+type ConfigRestoreRequest struct {
+	Requested bool `json:"requested"`
+	// Unix timestamp (seconds) of when the restore was requested.
+	Since int64 `json:"since,omitempty"`
+}
+
+// FetchConfigRestoreRequest gets the config restore request from path p. If
+// none is stored, an unrequested ConfigRestoreRequest is returned.
+func FetchConfigRestoreRequest(zk kafkazk.Handler, p string) (ConfigRestoreRequest, error) {
+	r := ConfigRestoreRequest{}
+
+	if exists, err := zk.Exists(p); err != nil {
+		return r, fmt.Errorf("error getting config restore request: %s", err)
+	} else if !exists {
+		return r, nil
+	}
+
+	data, err := zk.Get(p)
+	if err != nil {
+		return r, fmt.Errorf("error getting config restore request: %s", err)
+	}
+
+	if len(data) == 0 {
+		return r, nil
+	}
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("error unmarshalling config restore request: %s", err)
+	}
+
+	return r, nil
+}
+
+// RequestConfigRestore records a config restore request at path p.
+func RequestConfigRestore(zk kafkazk.Handler, p string) error {
+	d, err := json.Marshal(ConfigRestoreRequest{Requested: true, Since: time.Now().Unix()})
+	if err != nil {
+		return fmt.Errorf("error marshalling config restore request: %s", err)
+	}
+
+	exists, _ := zk.Exists(p)
+
+	if exists {
+		err = zk.Set(p, string(d))
+	} else {
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting config restore request: %s", err)
+	}
+
+	return nil
+}
+
+// RemoveConfigRestoreRequest deletes the config restore request at path p.
+func RemoveConfigRestoreRequest(zk kafkazk.Handler, p string) error {
+	exists, err := zk.Exists(p)
+	if !exists && err == nil {
+		return nil
+	}
+
+	if err := zk.Delete(p); err != nil {
+		return fmt.Errorf("error removing config restore request: %s", err)
+	}
+
+	return nil
+}
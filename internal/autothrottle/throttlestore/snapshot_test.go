@@ -0,0 +1,79 @@
+package throttlestore
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestConfigSnapshot(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	path := "/autothrottle/config_snapshot"
+
+	s, err := FetchConfigSnapshot(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Empty() {
+		t.Errorf("Expected an empty snapshot, got %+v", s)
+	}
+
+	s.Resources("broker")["1001"] = map[string]string{"leader.replication.throttled.rate": ""}
+	s.Resources("topic")["test"] = map[string]string{"leader.replication.throttled.replicas": "0:1001"}
+	s.Created = 1700000000
+
+	if err := StoreConfigSnapshot(zk, path, s); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FetchConfigSnapshot(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("Expected snapshot %+v, got %+v", s, got)
+	}
+
+	if err := RemoveConfigSnapshot(zk, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing when nothing is stored is a no-op.
+	if err := RemoveConfigSnapshot(zk, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, _ = FetchConfigSnapshot(zk, path); !s.Empty() {
+		t.Errorf("Expected an empty snapshot, got %+v", s)
+	}
+}
+
+func TestConfigRestoreRequest(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	zk.Create("/autothrottle", "")
+	path := "/autothrottle/config_restore"
+
+	if r, err := FetchConfigRestoreRequest(zk, path); err != nil || r.Requested {
+		t.Fatalf("Expected no restore request, got %+v (%v)", r, err)
+	}
+
+	if err := RequestConfigRestore(zk, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if r, err := FetchConfigRestoreRequest(zk, path); err != nil || !r.Requested || r.Since == 0 {
+		t.Errorf("Expected a restore request, got %+v (%v)", r, err)
+	}
+
+	if err := RemoveConfigRestoreRequest(zk, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if r, _ := FetchConfigRestoreRequest(zk, path); r.Requested {
+		t.Errorf("Expected no restore request, got %+v", r)
+	}
+}
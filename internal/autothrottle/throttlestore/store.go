@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/DataDog/kafka-kit/v4/kafkazk"
 )
@@ -13,6 +14,29 @@ var (
 	ErrNoOverrideSet = errors.New("no override set at path")
 )
 
+// Broker override precedences, describing how a broker override rate is
+// combined with the rate otherwise determined for a broker participating in a
+// reassignment.
+const (
+	// The override rate is used.
+	PrecedenceOverride = "override"
+	// The lower of the override and determined rates is used.
+	PrecedenceMin = "min"
+	// The higher of the override and determined rates is used.
+	PrecedenceMax = "max"
+)
+
+// ValidPrecedence returns whether p is a valid precedence. An empty precedence
+// is valid and equivalent to PrecedenceOverride.
+func ValidPrecedence(p string) bool {
+	switch p {
+	case "", PrecedenceOverride, PrecedenceMin, PrecedenceMax:
+		return true
+	}
+
+	return false
+}
+
 // ThrottleOverrideConfig holds throttle override configurations.
 type ThrottleOverrideConfig struct {
 	// Rate in MB.
@@ -20,6 +44,50 @@ type ThrottleOverrideConfig struct {
 	// Whether the override rate should be
 	// removed when the current reassignments finish.
 	AutoRemove bool `json:"autoremove"`
+	// Optional Unix timestamp (seconds) after which the override should be
+	// removed. A value of 0 means the override doesn't expire.
+	Expires int64 `json:"expires,omitempty"`
+	// Optional Unix timestamp (seconds) before which the override isn't
+	// applied, for overrides scheduled ahead of time. A value of 0 means the
+	// override applies immediately.
+	StartAt int64 `json:"start_at,omitempty"`
+	// How a broker override rate is combined with the determined rate for
+	// brokers participating in a reassignment. Unused for global overrides.
+	Precedence string `json:"precedence,omitempty"`
+	// The override of each requester, if set by requester. The above fields
+	// hold the effective (lowest rate) requester override.
+	Requesters map[string]ThrottleOverrideConfig `json:"requesters,omitempty"`
+}
+
+// Expired returns whether the ThrottleOverrideConfig, or the override of any
+// requester, has an expiry set that has passed.
+func (c ThrottleOverrideConfig) Expired() bool {
+	if c.Expires != 0 && time.Now().Unix() >= c.Expires {
+		return true
+	}
+
+	for _, o := range c.Requesters {
+		if o.Expired() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pending returns whether the ThrottleOverrideConfig has a start time set that
+// hasn't passed. Unlike Expired, the overrides of requesters aren't checked;
+// see Active.
+func (c ThrottleOverrideConfig) Pending() bool {
+	return c.StartAt != 0 && time.Now().Unix() < c.StartAt
+}
+
+// Active returns a copy of the ThrottleOverrideConfig without any requester
+// overrides that are pending; the effective override is that of the started
+// overrides.
+func (c ThrottleOverrideConfig) Active() ThrottleOverrideConfig {
+	active, _ := c.Remove(ThrottleOverrideConfig.Pending)
+	return active
 }
 
 // fetchThrottleOverride gets a throttle override from path p.
@@ -63,7 +131,7 @@ func StoreThrottleOverride(zk kafkazk.Handler, p string, c ThrottleOverrideConfi
 		err = zk.Set(p, string(d))
 	} else {
 		// Create.
-		err = zk.Create(p, string(d))
+		err = kafkazk.CreateWithParentACL(zk, p, string(d))
 	}
 
 	if err != nil {
@@ -126,3 +194,29 @@ func FetchBrokerOverrides(zk kafkazk.Handler, p string) (BrokerOverrides, error)
 
 	return overrides, nil
 }
+
+// ExpireBrokerOverrides takes a BrokerOverrides and removes any expired
+// requester overrides, both in the BrokerOverrides and at path p. Brokers left
+// without an override have a rate of 0, marking them for removal. A []int of
+// the IDs of brokers with expired overrides is returned.
+func ExpireBrokerOverrides(zk kafkazk.Handler, p string, bo BrokerOverrides) ([]int, error) {
+	var expired []int
+
+	for id, override := range bo {
+		if !override.Config.Expired() {
+			continue
+		}
+
+		override.Config, _ = override.Config.Remove(ThrottleOverrideConfig.Expired)
+		brokerZnode := fmt.Sprintf("%s/%d", p, id)
+
+		if err := StoreThrottleOverride(zk, brokerZnode, override.Config); err != nil {
+			return expired, err
+		}
+
+		bo[id] = override
+		expired = append(expired, id)
+	}
+
+	return expired, nil
+}
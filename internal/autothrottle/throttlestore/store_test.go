@@ -0,0 +1,122 @@
+package throttlestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkazk"
+)
+
+func TestExpired(t *testing.T) {
+	c := ThrottleOverrideConfig{Rate: 10}
+	if c.Expired() {
+		t.Error("Expected an override without an expiry to not be expired")
+	}
+
+	c.Expires = time.Now().Add(time.Hour).Unix()
+	if c.Expired() {
+		t.Error("Expected a future expiry to not be expired")
+	}
+
+	c.Expires = time.Now().Add(-time.Hour).Unix()
+	if !c.Expired() {
+		t.Error("Expected a past expiry to be expired")
+	}
+}
+
+func TestPendingActive(t *testing.T) {
+	c := ThrottleOverrideConfig{Rate: 10}
+	if c.Pending() {
+		t.Error("Expected an override without a start to not be pending")
+	}
+
+	c.StartAt = time.Now().Add(-time.Hour).Unix()
+	if c.Pending() {
+		t.Error("Expected a past start to not be pending")
+	}
+
+	c.StartAt = time.Now().Add(time.Hour).Unix()
+	if !c.Pending() {
+		t.Error("Expected a future start to be pending")
+	}
+
+	if a := c.Active(); a.Rate != 0 {
+		t.Errorf("Expected no active override, got %+v", a)
+	}
+
+	// The effective override is that of the started requesters.
+	c = c.WithRequester("maintenance", ThrottleOverrideConfig{Rate: 5, StartAt: time.Now().Add(time.Hour).Unix()})
+	c = c.WithRequester("operator", ThrottleOverrideConfig{Rate: 20})
+
+	if c.Rate != 5 {
+		t.Errorf("Expected stored rate 5, got %d", c.Rate)
+	}
+
+	if a := c.Active(); a.Rate != 20 || len(a.Requesters) != 1 {
+		t.Errorf("Expected active rate 20 from a single requester, got %+v", a)
+	}
+}
+
+func TestExpireBrokerOverrides(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	path := "/autothrottle/override_rate"
+
+	bo := BrokerOverrides{
+		1001: {ID: 1001, Config: ThrottleOverrideConfig{Rate: 10, Expires: time.Now().Add(-time.Minute).Unix()}},
+		1002: {ID: 1002, Config: ThrottleOverrideConfig{Rate: 20, Expires: time.Now().Add(time.Hour).Unix()}},
+		1003: {ID: 1003, Config: ThrottleOverrideConfig{Rate: 30}},
+	}
+
+	expired, err := ExpireBrokerOverrides(zk, path, bo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expired) != 1 || expired[0] != 1001 {
+		t.Errorf("Expected [1001] expired, got %v", expired)
+	}
+
+	if bo[1001].Config.Rate != 0 {
+		t.Errorf("Expected rate 0 for broker 1001, got %d", bo[1001].Config.Rate)
+	}
+
+	stored, err := FetchThrottleOverride(zk, path+"/1001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.Rate != 0 {
+		t.Errorf("Expected stored rate 0 for broker 1001, got %d", stored.Rate)
+	}
+
+	if bo[1002].Config.Rate != 20 || bo[1003].Config.Rate != 30 {
+		t.Error("Unexpected changes to unexpired overrides")
+	}
+}
+
+func TestPauseConfig(t *testing.T) {
+	zk := kafkazk.NewZooKeeperStub()
+	path := "/autothrottle/paused"
+
+	c, err := FetchPauseConfig(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Paused {
+		t.Error("Expected an unset pause config to be unpaused")
+	}
+
+	if err := StorePauseConfig(zk, path, PauseConfig{Paused: true, Since: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = FetchPauseConfig(zk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.Paused || c.Since != 1 {
+		t.Errorf("Unexpected pause config %+v", c)
+	}
+}
@@ -0,0 +1,326 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// OTLP span kind and status codes.
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+// ExporterConfig holds Exporter configuration parameters.
+type ExporterConfig struct {
+	// The OTLP/HTTP endpoint, e.g. http://localhost:4318. Spans are posted to
+	// the /v1/traces path.
+	Endpoint string
+	// Optional headers sent with each request, e.g. backend API keys.
+	Headers map[string]string
+	// The service.name resource attribute.
+	ServiceName string
+	// The interval at which queued spans are exported. Defaults to 5s.
+	FlushInterval time.Duration
+	// The maximum number of spans queued for export; further spans are dropped
+	// until the queue is flushed. Defaults to 2048.
+	QueueSize int
+	// The HTTP client used for exports. Defaults to a client with a 10s
+	// timeout.
+	Client *http.Client
+}
+
+// Exporter exports spans in batches.
+type Exporter struct {
+	url         string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+
+	spans   chan *Span
+	flush   chan chan error
+	dropped atomic.Int64
+}
+
+// NewExporter takes an ExporterConfig and returns an *Exporter. Spans are
+// exported in the background until the process exits.
+func NewExporter(cfg ExporterConfig) (*Exporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", cfg.Endpoint)
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 2048
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	e := &Exporter{
+		url:         strings.TrimSuffix(cfg.Endpoint, "/") + "/v1/traces",
+		headers:     cfg.Headers,
+		serviceName: cfg.ServiceName,
+		client:      cfg.Client,
+		spans:       make(chan *Span, cfg.QueueSize),
+		flush:       make(chan chan error),
+	}
+
+	go e.run(cfg.FlushInterval)
+
+	return e, nil
+}
+
+// Dropped returns the number of spans dropped because the queue was full.
+func (e *Exporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Flush exports all queued spans, returning any export error.
+func (e *Exporter) Flush(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	select {
+	case e.flush <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue queues a span for export, dropping it if the queue is full.
+func (e *Exporter) enqueue(s *Span) {
+	select {
+	case e.spans <- s:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// run exports queued spans every interval and on Flush requests.
+func (e *Exporter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.export(e.drain()); err != nil {
+				log.Printf("Error exporting spans: %s\n", err)
+			}
+		case done := <-e.flush:
+			done <- e.export(e.drain())
+		}
+	}
+}
+
+// drain returns all queued spans.
+func (e *Exporter) drain() []*Span {
+	var spans []*Span
+	for {
+		select {
+		case s := <-e.spans:
+			spans = append(spans, s)
+		default:
+			return spans
+		}
+	}
+}
+
+// export posts the spans to the OTLP endpoint.
+func (e *Exporter) export(spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("OTLP endpoint returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// The OTLP JSON encoding of an ExportTraceServiceRequest. IDs are hex encoded
+// and 64 bit integers are encoded as strings.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+// request returns the spans as an otlpRequest.
+func (e *Exporter) request(spans []*Span) otlpRequest {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: "github.com/DataDog/kafka-kit/v4/autothrottle"}}
+
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attrs),
+		}
+
+		if s.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+
+		if s.err != nil {
+			span.Status = otlpStatus{Code: statusCodeError, Message: s.err.Error()}
+		}
+		s.mu.Unlock()
+
+		scope.Spans = append(scope.Spans, span)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: otlpAttributes([]Attribute{String("service.name", e.serviceName)})},
+			ScopeSpans: []otlpScopeSpans{scope},
+		}},
+	}
+}
+
+// otlpAttributes converts attrs to their OTLP encoding. Values of unsupported
+// types are encoded as strings.
+func otlpAttributes(attrs []Attribute) []otlpAttribute {
+	var out []otlpAttribute
+
+	for _, a := range attrs {
+		var v otlpValue
+
+		switch t := a.Value.(type) {
+		case string:
+			v.StringValue = &t
+		case int:
+			s := strconv.Itoa(t)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &t
+		case bool:
+			v.BoolValue = &t
+		default:
+			s := fmt.Sprint(t)
+			v.StringValue = &s
+		}
+
+		out = append(out, otlpAttribute{Key: a.Key, Value: v})
+	}
+
+	return out
+}
+
+// ParseHeaders parses a comma-delimited list of key=value pairs, as used by
+// the OTEL_EXPORTER_OTLP_HEADERS env var, into a map.
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+
+	for _, kv := range strings.Split(s, ",") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+
+		k, v, found := strings.Cut(kv, "=")
+		if k = strings.TrimSpace(k); !found || k == "" {
+			return nil, errors.New("headers must be key=value pairs")
+		}
+
+		headers[k] = strings.TrimSpace(v)
+	}
+
+	return headers, nil
+}
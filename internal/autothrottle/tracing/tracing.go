@@ -0,0 +1,136 @@
+// Package tracing records spans and exports them to an OpenTelemetry collector
+// or tracing backend with the OpenTelemetry protocol (OTLP) over HTTP, using
+// the JSON encoding. Spans are only recorded once an Exporter is registered
+// with SetExporter; until then, Start returns a nil *Span, on which all methods
+// are no-ops, so that instrumented code needn't check whether tracing is
+// enabled.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// exporter is the registered Exporter, if any.
+var exporter atomic.Pointer[Exporter]
+
+// SetExporter registers the Exporter that spans are recorded to. A nil
+// Exporter disables tracing.
+func SetExporter(e *Exporter) {
+	exporter.Store(e)
+}
+
+// Attribute is a span attribute. Values are strings, ints, float64s or bools.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string Attribute.
+func String(k, v string) Attribute { return Attribute{Key: k, Value: v} }
+
+// Int returns an int Attribute.
+func Int(k string, v int) Attribute { return Attribute{Key: k, Value: v} }
+
+// Float64 returns a float64 Attribute.
+func Float64(k string, v float64) Attribute { return Attribute{Key: k, Value: v} }
+
+// Bool returns a bool Attribute.
+func Bool(k string, v bool) Attribute { return Attribute{Key: k, Value: v} }
+
+// Span is a timed operation within a trace.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+	exporter *Exporter
+
+	mu    sync.Mutex
+	end   time.Time
+	attrs []Attribute
+	err   error
+	ended bool
+}
+
+type spanKey struct{}
+
+// Start starts a span named name. If ctx holds a span, the new span is its
+// child, otherwise it starts a new trace. The returned context holds the new
+// span. Spans must be ended with End.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	e := exporter.Load()
+	if e == nil {
+		return ctx, nil
+	}
+
+	s := &Span{
+		name:     name,
+		start:    time.Now(),
+		exporter: e,
+		attrs:    attrs,
+	}
+
+	if parent := FromContext(ctx); parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+
+	rand.Read(s.spanID[:])
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext returns the span held by ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SetAttributes sets attributes on the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// RecordError marks the span as failed with err. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+// End ends the span and queues it for export. Subsequent calls are no-ops.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	s.exporter.enqueue(s)
+}
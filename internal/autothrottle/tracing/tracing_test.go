@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStartDisabled(t *testing.T) {
+	SetExporter(nil)
+
+	ctx, span := Start(context.Background(), "interval")
+	if span != nil || FromContext(ctx) != nil {
+		t.Fatal("Expected no span")
+	}
+
+	// Methods on a nil span are no-ops.
+	span.SetAttributes(Int("brokers", 3))
+	span.RecordError(errors.New("error"))
+	span.End()
+}
+
+func TestExport(t *testing.T) {
+	requests := make(chan otlpRequest, 1)
+	var apiKey string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+
+		apiKey = r.Header.Get("Dd-Api-Key")
+
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		requests <- req
+	}))
+	defer ts.Close()
+
+	e, err := NewExporter(ExporterConfig{
+		Endpoint:      ts.URL + "/",
+		Headers:       map[string]string{"DD-API-KEY": "key"},
+		ServiceName:   "autothrottle",
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetExporter(e)
+	t.Cleanup(func() { SetExporter(nil) })
+
+	ctx, root := Start(context.Background(), "interval", Bool("paused", false))
+	_, child := Start(ctx, "metrics fetch")
+	child.SetAttributes(Int("brokers", 3), Float64("rate", 1.5), String("role", "leader"))
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	root.End()
+	// Ending a span again is a no-op.
+	root.End()
+
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-requests
+
+	if apiKey != "key" {
+		t.Errorf("Expected header DD-API-KEY: key, got %q", apiKey)
+	}
+
+	rs := req.ResourceSpans[0]
+	if a := rs.Resource.Attributes[0]; a.Key != "service.name" || *a.Value.StringValue != "autothrottle" {
+		t.Errorf("Unexpected resource attribute %+v", a)
+	}
+
+	spans := rs.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	c, r := spans[0], spans[1]
+
+	if r.Name != "interval" || r.ParentSpanID != "" || len(r.TraceID) != 32 || len(r.SpanID) != 16 {
+		t.Errorf("Unexpected root span %+v", r)
+	}
+
+	if c.TraceID != r.TraceID || c.ParentSpanID != r.SpanID {
+		t.Errorf("Expected child of %s/%s, got %s/%s", r.TraceID, r.SpanID, c.TraceID, c.ParentSpanID)
+	}
+
+	if c.Status.Code != statusCodeError || c.Status.Message != "timeout" {
+		t.Errorf("Unexpected status %+v", c.Status)
+	}
+
+	if len(c.Attributes) != 3 || *c.Attributes[0].Value.IntValue != "3" || *c.Attributes[1].Value.DoubleValue != 1.5 {
+		t.Errorf("Unexpected attributes %+v", c.Attributes)
+	}
+
+	if r.StartTimeUnixNano == "" || r.EndTimeUnixNano < r.StartTimeUnixNano {
+		t.Errorf("Unexpected times %s-%s", r.StartTimeUnixNano, r.EndTimeUnixNano)
+	}
+
+	// Nothing is exported without spans.
+	if err := e.Flush(context.Background()); err != nil || len(requests) != 0 {
+		t.Errorf("Expected no export, got %v", err)
+	}
+}
+
+func TestExportError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid api key", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	e, _ := NewExporter(ExporterConfig{Endpoint: ts.URL, FlushInterval: time.Hour, QueueSize: 1})
+
+	SetExporter(e)
+	t.Cleanup(func() { SetExporter(nil) })
+
+	for i := 0; i < 2; i++ {
+		_, s := Start(context.Background(), "interval")
+		s.End()
+	}
+
+	if n := e.Dropped(); n != 1 {
+		t.Errorf("Expected 1 dropped span, got %d", n)
+	}
+
+	if err := e.Flush(context.Background()); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestNewExporterInvalid(t *testing.T) {
+	for _, endpoint := range []string{"", "localhost:4318", "ftp://localhost"} {
+		if _, err := NewExporter(ExporterConfig{Endpoint: endpoint}); err == nil {
+			t.Errorf("Expected an error for %q", endpoint)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	h, err := ParseHeaders("DD-API-KEY=abc, x-tenant = kafka,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(h) != 2 || h["DD-API-KEY"] != "abc" || h["x-tenant"] != "kafka" {
+		t.Errorf("Unexpected headers %v", h)
+	}
+
+	if _, err := ParseHeaders("DD-API-KEY"); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,257 @@
+// Package awsauth provides AWS credentials from the environment or the EC2
+// instance metadata service, and Signature Version 4 request signing.
+package awsauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetadataEndpoint is the EC2 instance metadata service endpoint.
+const DefaultMetadataEndpoint = "http://169.254.169.254"
+
+// ErrNoCredentials is returned when no AWS credentials are found in the
+// environment or instance metadata.
+var ErrNoCredentials = errors.New("no AWS credentials found")
+
+// Credentials are AWS request signing credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// The zero value for credentials that don't expire.
+	Expiration time.Time
+}
+
+// Provider provides credentials from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN env vars if set, otherwise the
+// instance's IAM role credentials from the instance metadata service (IMDSv2).
+type Provider struct {
+	metadataEndpoint string
+	client           *http.Client
+	now              func() time.Time
+
+	mu    sync.Mutex
+	creds Credentials
+}
+
+// NewProvider takes an instance metadata service endpoint and HTTP client and
+// returns a *Provider. The DefaultMetadataEndpoint is used if the endpoint is
+// empty.
+func NewProvider(metadataEndpoint string, client *http.Client) *Provider {
+	if metadataEndpoint == "" {
+		metadataEndpoint = DefaultMetadataEndpoint
+	}
+
+	return &Provider{
+		metadataEndpoint: metadataEndpoint,
+		client:           client,
+		now:              time.Now,
+	}
+}
+
+// Credentials returns AWS credentials from the environment or, if not set, the
+// instance's IAM role. Role credentials are cached until shortly before they
+// expire.
+func (p *Provider) Credentials(ctx context.Context) (Credentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return Credentials{
+			AccessKeyID:     id,
+			SecretAccessKey: secret,
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.creds.AccessKeyID != "" && p.now().Add(5*time.Minute).Before(p.creds.Expiration) {
+		return p.creds, nil
+	}
+
+	role, err := p.Metadata(ctx, "iam/security-credentials/")
+	if err != nil {
+		return Credentials{}, fmt.Errorf("%s: %s", ErrNoCredentials, err)
+	}
+
+	role = strings.TrimSpace(strings.Split(role, "\n")[0])
+	if role == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+
+	data, err := p.Metadata(ctx, "iam/security-credentials/"+role)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("error fetching IAM role credentials: %s", err)
+	}
+
+	var creds struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		Token           string    `json:"Token"`
+		Expiration      time.Time `json:"Expiration"`
+	}
+
+	if err := json.Unmarshal([]byte(data), &creds); err != nil {
+		return Credentials{}, fmt.Errorf("error parsing IAM role credentials: %s", err)
+	}
+
+	p.creds = Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.Token,
+		Expiration:      creds.Expiration,
+	}
+
+	return p.creds, nil
+}
+
+// Region returns the region from the AWS_REGION env var if set, otherwise
+// from the instance metadata.
+func (p *Provider) Region(ctx context.Context) (string, error) {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region, nil
+	}
+
+	region, err := p.Metadata(ctx, "placement/region")
+	if err != nil {
+		return "", fmt.Errorf("error fetching AWS region: %s", err)
+	}
+
+	return region, nil
+}
+
+// Metadata takes an instance metadata path relative to /latest/meta-data/ and
+// returns the value, using an IMDSv2 session token.
+func (p *Provider) Metadata(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.metadataEndpoint+"/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+
+	token, err := p.metadataRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, p.metadataEndpoint+"/latest/meta-data/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+
+	return p.metadataRequest(req)
+}
+
+func (p *Provider) metadataRequest(req *http.Request) (string, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("instance metadata request %s: %s", req.URL.Path, resp.Status)
+	}
+
+	return string(body), nil
+}
+
+// SignV4 signs an AWS API request with Signature Version 4. The host,
+// content-type and any x-amz-* headers are signed.
+func SignV4(req *http.Request, body []byte, creds Credentials, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if k == "content-type" || strings.HasPrefix(k, "x-amz-") {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	var names []string
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", k, headers[k])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		QueryEscape(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// QueryEscape returns the values encoded as a canonical AWS query string;
+// sorted by key with spaces encoded as %20.
+func QueryEscape(v url.Values) string {
+	return strings.ReplaceAll(v.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
@@ -407,7 +407,7 @@ func (s idList) Less(i, j int) bool { return s[i] < s[j] }
 func (s idList) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
 func pbBrokerFromMeta(id uint32, b *mapper.BrokerMeta) *pb.Broker {
-	return &pb.Broker{
+	broker := &pb.Broker{
 		Id:                         id,
 		Host:                       b.Host,
 		Rack:                       b.Rack,
@@ -415,4 +415,15 @@ func pbBrokerFromMeta(id uint32, b *mapper.BrokerMeta) *pb.Broker {
 		Logmessageformat:           b.LogMessageFormat,
 		Interbrokerprotocolversion: b.InterBrokerProtocolVersion,
 	}
+
+	for _, e := range b.Endpoints {
+		broker.Endpoints = append(broker.Endpoints, &pb.BrokerEndpoint{
+			Listener:         e.Listener,
+			SecurityProtocol: e.SecurityProtocol,
+			Host:             e.Host,
+			Port:             uint32(e.Port),
+		})
+	}
+
+	return broker
 }
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+	pb "github.com/DataDog/kafka-kit/v4/proto/registrypb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrDuplicateManifestTopic error.
+	ErrDuplicateManifestTopic = status.Error(codes.InvalidArgument, "topic specified more than once in manifest")
+	// ErrInvalidManifestTopic error.
+	ErrInvalidManifestTopic = status.Error(codes.InvalidArgument, "manifest topic partitions and replication must be > 0")
+)
+
+// ReconcileTopics takes a manifest of desired topics and reconciles the cluster
+// toward it. Missing topics are created with any specified tags and drifted
+// topic configs are altered. Partition count and replication factor drift, as
+// well as topics not in the manifest, are only reported. If the DryRun field is
+// set, no changes are made.
+func (s *Server) ReconcileTopics(ctx context.Context, req *pb.ReconcileTopicsRequest) (*pb.ReconcileTopicsResponse, error) {
+	kind := writeRequest
+	if req.DryRun {
+		kind = readRequest
+	}
+
+	ctx, cancel, err := s.ValidateRequest(ctx, req, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if cancel != nil {
+		defer cancel()
+	}
+
+	if err := validateManifest(req.Topics); err != nil {
+		return nil, err
+	}
+
+	if !req.DryRun {
+		if err := s.Locking.Lock(ctx); err != nil {
+			return nil, err
+		}
+		defer s.Locking.UnlockLogError(ctx)
+	}
+
+	// Get the current state of all topics.
+	current, err := s.fetchTopicSet(ctx, fetchTopicSetParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := reconcileTopicsDiff(req.Topics, current)
+	resp.DryRun = req.DryRun
+
+	if req.DryRun {
+		return resp, nil
+	}
+
+	desired := map[string]*pb.Topic{}
+	for _, t := range req.Topics {
+		desired[t.Name] = t
+	}
+
+	// Create missing topics.
+	for _, name := range resp.Missing {
+		t := desired[name]
+		cfg := kafkaadmin.CreateTopicConfig{
+			Name:              t.Name,
+			Partitions:        int(t.Partitions),
+			ReplicationFactor: int(t.Replication),
+			Config:            t.Configs,
+		}
+
+		if err := s.kafkaadmin.CreateTopic(ctx, cfg); err != nil {
+			return nil, fmt.Errorf("error creating topic %s: %s", name, err)
+		}
+
+		if tags := TagSet(t.Tags).Tags(); len(tags) > 0 {
+			if err := s.tagTopicWithRetries(ctx, &pb.TopicRequest{Name: name, Tag: tags}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Alter drifted configs.
+	configs := kafkaadmin.ResourceConfigs{}
+	for _, d := range resp.Drift {
+		if !d.Reconcilable {
+			continue
+		}
+		configs.AddConfig(d.Name, strings.TrimPrefix(d.Field, "config."), d.Desired)
+	}
+
+	if err := s.kafkaadmin.SetTopicConfigs(ctx, configs); err != nil {
+		return nil, fmt.Errorf("error setting topic configs: %s", err)
+	}
+
+	return resp, nil
+}
+
+// validateManifest checks that all manifest topics are named, unique, and have
+// valid partition and replication values.
+func validateManifest(topics []*pb.Topic) error {
+	seen := map[string]struct{}{}
+
+	for _, t := range topics {
+		if t.Name == "" {
+			return ErrTopicNameEmpty
+		}
+
+		if _, exists := seen[t.Name]; exists {
+			return ErrDuplicateManifestTopic
+		}
+		seen[t.Name] = struct{}{}
+
+		if t.Partitions == 0 || t.Replication == 0 {
+			return ErrInvalidManifestTopic
+		}
+	}
+
+	return nil
+}
+
+// reconcileTopicsDiff takes a manifest of desired topics and the current
+// TopicSet and returns a *pb.ReconcileTopicsResponse describing the
+// differences. Only configs specified in the manifest are compared.
+func reconcileTopicsDiff(desired []*pb.Topic, current TopicSet) *pb.ReconcileTopicsResponse {
+	resp := &pb.ReconcileTopicsResponse{}
+	inManifest := map[string]struct{}{}
+
+	for _, t := range desired {
+		inManifest[t.Name] = struct{}{}
+
+		c, exists := current[t.Name]
+		if !exists {
+			resp.Missing = append(resp.Missing, t.Name)
+			continue
+		}
+
+		if c.Partitions != t.Partitions {
+			resp.Drift = append(resp.Drift, &pb.TopicDrift{
+				Name:    t.Name,
+				Field:   "partitions",
+				Current: fmt.Sprint(c.Partitions),
+				Desired: fmt.Sprint(t.Partitions),
+			})
+		}
+
+		if c.Replication != t.Replication {
+			resp.Drift = append(resp.Drift, &pb.TopicDrift{
+				Name:    t.Name,
+				Field:   "replication",
+				Current: fmt.Sprint(c.Replication),
+				Desired: fmt.Sprint(t.Replication),
+			})
+		}
+
+		var keys []string
+		for k := range t.Configs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if c.Configs[k] != t.Configs[k] {
+				resp.Drift = append(resp.Drift, &pb.TopicDrift{
+					Name:         t.Name,
+					Field:        "config." + k,
+					Current:      c.Configs[k],
+					Desired:      t.Configs[k],
+					Reconcilable: true,
+				})
+			}
+		}
+	}
+
+	for name := range current {
+		if _, exists := inManifest[name]; exists || strings.HasPrefix(name, "__") {
+			continue
+		}
+		resp.Extra = append(resp.Extra, name)
+	}
+
+	sort.Strings(resp.Missing)
+	sort.Strings(resp.Extra)
+	sort.SliceStable(resp.Drift, func(i, j int) bool {
+		return resp.Drift[i].Name < resp.Drift[j].Name
+	})
+
+	return resp
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/kafka-kit/v4/proto/registrypb"
+)
+
+func TestReconcileTopics(t *testing.T) {
+	manifest := []*pb.Topic{
+		{
+			Name:        "test1",
+			Partitions:  4,
+			Replication: 2,
+			Configs:     map[string]string{"retention.ms": "86400000", "cleanup.policy": "delete"},
+		},
+		{
+			Name:        "test3",
+			Partitions:  8,
+			Replication: 3,
+		},
+	}
+
+	expected := &pb.ReconcileTopicsResponse{
+		Missing: []string{"test3"},
+		Drift: []*pb.TopicDrift{
+			{Name: "test1", Field: "partitions", Current: "2", Desired: "4"},
+			{Name: "test1", Field: "config.cleanup.policy", Current: "", Desired: "delete", Reconcilable: true},
+			{Name: "test1", Field: "config.retention.ms", Current: "172800000", Desired: "86400000", Reconcilable: true},
+		},
+		Extra: []string{"test2"},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		s := testServer()
+
+		resp, err := s.ReconcileTopics(context.Background(), &pb.ReconcileTopicsRequest{Topics: manifest, DryRun: dryRun})
+		assert.Nil(t, err)
+
+		expected.DryRun = dryRun
+		assert.Equal(t, expected.String(), resp.String(), "dry run: %v", dryRun)
+	}
+}
+
+func TestReconcileTopicsInvalidManifest(t *testing.T) {
+	tests := []struct {
+		topics []*pb.Topic
+		err    error
+	}{
+		{
+			topics: []*pb.Topic{{Partitions: 1, Replication: 1}},
+			err:    ErrTopicNameEmpty,
+		},
+		{
+			topics: []*pb.Topic{{Name: "test1", Partitions: 1}},
+			err:    ErrInvalidManifestTopic,
+		},
+		{
+			topics: []*pb.Topic{
+				{Name: "test1", Partitions: 1, Replication: 1},
+				{Name: "test1", Partitions: 2, Replication: 1},
+			},
+			err: ErrDuplicateManifestTopic,
+		},
+	}
+
+	for i, test := range tests {
+		s := testServer()
+
+		_, err := s.ReconcileTopics(context.Background(), &pb.ReconcileTopicsRequest{Topics: test.topics})
+		assert.Equal(t, test.err, err, "case %d", i)
+	}
+}
@@ -11,8 +11,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/confluentinc/confluent-kafka-go/kafka"
-
 	"github.com/DataDog/kafka-kit/v4/cluster"
 	zklocking "github.com/DataDog/kafka-kit/v4/cluster/zookeeper"
 	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
@@ -43,7 +41,7 @@ type Server struct {
 	readReqThrottle       RequestThrottle
 	writeReqThrottle      RequestThrottle
 	reqID                 uint64
-	kafkaconsumer         *kafka.Consumer
+	kafkaconsumer         *kafkaadmin.Consumer
 	// For tests.
 	test bool
 }
@@ -213,15 +211,7 @@ func (s *Server) InitKafkaAdmin(ctx context.Context, wg *sync.WaitGroup, cfg kaf
 
 	wg.Add(1)
 
-	k, err := kafkaadmin.NewClient(
-		kafkaadmin.Config{
-			BootstrapServers: cfg.BootstrapServers,
-			SSLCALocation:    cfg.SSLCALocation,
-			SecurityProtocol: cfg.SecurityProtocol,
-			SASLMechanism:    cfg.SASLMechanism,
-			SASLUsername:     cfg.SASLUsername,
-			SASLPassword:     cfg.SASLPassword,
-		})
+	k, err := kafkaadmin.NewClient(cfg)
 	if err != nil {
 		return err
 	}
@@ -240,7 +230,7 @@ func (s *Server) InitKafkaAdmin(ctx context.Context, wg *sync.WaitGroup, cfg kaf
 }
 
 // InitKafkaConsumer takes a Context, WaitGroup and an admin.Config and initializes
-// a kafka.Consumer. A background shutdown procedure is called when the context is cancelled.
+// a kafkaadmin.Consumer. A background shutdown procedure is called when the context is cancelled.
 func (s *Server) InitKafkaConsumer(ctx context.Context, wg *sync.WaitGroup, cfg kafkaadmin.Config) error {
 	if s.test {
 		return nil
@@ -248,16 +238,8 @@ func (s *Server) InitKafkaConsumer(ctx context.Context, wg *sync.WaitGroup, cfg
 
 	wg.Add(1)
 
-	k, err := kafkaadmin.NewConsumer(
-		kafkaadmin.Config{
-			BootstrapServers: cfg.BootstrapServers,
-			GroupId:          "registry",
-			SSLCALocation:    cfg.SSLCALocation,
-			SecurityProtocol: cfg.SecurityProtocol,
-			SASLMechanism:    cfg.SASLMechanism,
-			SASLUsername:     cfg.SASLUsername,
-			SASLPassword:     cfg.SASLPassword,
-		})
+	cfg.GroupId = "registry"
+	k, err := kafkaadmin.NewConsumer(cfg)
 	if err != nil {
 		return err
 	}
@@ -329,9 +311,14 @@ func (s *Server) DialZK(ctx context.Context, wg *sync.WaitGroup, c *kafkazk.Conf
 		return fmt.Errorf("failed to initialize ZooKeeper TagStorage backend")
 	}
 
-	// Shutdown procedure.
+	// Shutdown procedure. Exhausting the ZooKeeper reconnect retry budget is
+	// fatal.
 	go func() {
-		<-ctx.Done()
+		select {
+		case <-ctx.Done():
+		case err := <-zk.Fatal():
+			log.Fatal(err)
+		}
 		zk.Close()
 		wg.Done()
 	}()
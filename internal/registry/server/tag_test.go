@@ -350,6 +350,7 @@ func TestReservedFields(t *testing.T) {
 		"rack":                       {},
 		"logmessageformat":           {},
 		"interbrokerprotocolversion": {},
+		"endpoints":                  {},
 		"tags":                       {},
 	}
 
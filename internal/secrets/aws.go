@@ -0,0 +1,151 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/awsauth"
+)
+
+// AWSConfig holds AWS Secrets Manager backend configuration parameters.
+// Requests are authenticated with credentials from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN env vars if set, otherwise with
+// the instance's IAM role credentials from the instance metadata service.
+type AWSConfig struct {
+	// The AWS region. If empty, the region is taken from the secret ARN, the
+	// AWS_REGION env var or the instance metadata.
+	Region string
+	// Optional Secrets Manager endpoint. Defaults to the regional endpoint.
+	Endpoint string
+	// Optional instance metadata service endpoint.
+	MetadataEndpoint string
+	// Optional HTTP client.
+	HTTPClient *http.Client
+}
+
+// awsSecretsManager fetches secrets with the Secrets Manager GetSecretValue
+// API.
+type awsSecretsManager struct {
+	region   string
+	endpoint string
+	auth     *awsauth.Provider
+	client   *http.Client
+	now      func() time.Time
+}
+
+func newAWSSecretsManager(c AWSConfig) *awsSecretsManager {
+	a := &awsSecretsManager{
+		region:   c.Region,
+		endpoint: c.Endpoint,
+		client:   c.HTTPClient,
+		now:      time.Now,
+	}
+
+	if a.client == nil {
+		a.client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	a.auth = awsauth.NewProvider(c.MetadataEndpoint, a.client)
+
+	return a
+}
+
+// awsErrorResponse is an AWS JSON protocol error response.
+type awsErrorResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+	// Some errors capitalize the message field.
+	MessageAlt string `json:"Message"`
+}
+
+// get implements the backend interface. The secret ID may be a name or ARN.
+func (a *awsSecretsManager) get(ctx context.Context, id string) (string, map[string]interface{}, error) {
+	region, err := a.regionFor(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	creds, err := a.auth.Credentials(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	endpoint := a.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", strings.NewReader(string(body)))
+	if err != nil {
+		return "", nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	awsauth.SignV4(req, body, creds, region, "secretsmanager", a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e awsErrorResponse
+		if json.Unmarshal(respBody, &e) == nil && e.Type != "" {
+			msg := e.Message
+			if msg == "" {
+				msg = e.MessageAlt
+			}
+			// The type may be prefixed with a namespace.
+			t := e.Type[strings.LastIndex(e.Type, "#")+1:]
+			return "", nil, fmt.Errorf("Secrets Manager API error: %s: %s", t, msg)
+		}
+		return "", nil, fmt.Errorf("Secrets Manager API error: %s", resp.Status)
+	}
+
+	var r struct {
+		SecretString *string `json:"SecretString"`
+	}
+
+	if err := json.Unmarshal(respBody, &r); err != nil {
+		return "", nil, fmt.Errorf("error parsing GetSecretValue response: %s", err)
+	}
+
+	if r.SecretString == nil {
+		return "", nil, errors.New("binary secrets aren't supported")
+	}
+
+	return *r.SecretString, nil, nil
+}
+
+// regionFor returns the region for the secret ID; the region of an ARN,
+// otherwise the configured or default region.
+func (a *awsSecretsManager) regionFor(ctx context.Context, id string) (string, error) {
+	// arn:aws:secretsmanager:<region>:<account>:secret:<name>
+	if parts := strings.Split(id, ":"); len(parts) >= 7 && parts[0] == "arn" {
+		return parts[3], nil
+	}
+
+	if a.region != "" {
+		return a.region, nil
+	}
+
+	return a.auth.Region(ctx)
+}
@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// secretsManagerStub serves GetSecretValue requests signed with the AKID
+// access key for the secrets, recording the region of each request.
+func secretsManagerStub(t *testing.T, secrets map[string]string, regions *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/secretsmanager/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"__type": "UnrecognizedClientException", "message": "invalid signature"}`))
+			return
+		}
+
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Credential=AKID/<date>/<region>/secretsmanager/aws4_request
+		*regions = append(*regions, strings.Split(auth, "/")[2])
+
+		var req struct {
+			SecretID string `json:"SecretId"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+
+		s, exists := secrets[req.SecretID]
+		if !exists {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type": "com.amazonaws.secretsmanager#ResourceNotFoundException", "Message": "Secrets Manager can't find the specified secret."}`))
+			return
+		}
+
+		resp, _ := json.Marshal(map[string]string{"SecretString": s})
+		w.Write(resp)
+	}))
+}
+
+func TestAWSSecretsManagerGet(t *testing.T) {
+	arn := "arn:aws:secretsmanager:eu-west-1:123456789012:secret:datadog-AbCdEf"
+
+	var regions []string
+	srv := secretsManagerStub(t, map[string]string{
+		"prod/datadog": `{"api_key": "api", "app_key": "app"}`,
+		"prod/plain":   "plain",
+		arn:            `{"api_key": "arn-api"}`,
+	}, &regions)
+	defer srv.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	c := NewClient(Config{AWS: AWSConfig{Region: "us-east-1", Endpoint: srv.URL}})
+
+	tests := []struct {
+		ref      string
+		expected string
+		err      string
+	}{
+		{"aws-sm://prod/datadog#api_key", "api", ""},
+		{"aws-sm://prod/datadog#app_key", "app", ""},
+		{"aws-sm://prod/plain", "plain", ""},
+		{"aws-sm://prod/plain#api_key", "", "isn't a JSON object"},
+		{"aws-sm://" + arn + "#api_key", "arn-api", ""},
+		{"aws-sm://prod/missing", "", "ResourceNotFoundException: Secrets Manager can't find the specified secret."},
+	}
+
+	for _, test := range tests {
+		ref, err := ParseRef(test.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := c.Get(context.Background(), ref)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("[%s] Unexpected error: %s", test.ref, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("[%s] Expected error containing '%s', got %v", test.ref, test.err, err)
+		case v != test.expected:
+			t.Errorf("[%s] Expected %s, got %s", test.ref, test.expected, v)
+		}
+	}
+
+	// Requests for ARNs are signed for the ARN region.
+	if regions[4] != "eu-west-1" || regions[0] != "us-east-1" {
+		t.Errorf("Unexpected request regions %v", regions)
+	}
+}
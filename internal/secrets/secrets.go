@@ -0,0 +1,243 @@
+// Package secrets retrieves secrets such as API keys from a secrets backend,
+// currently HashiCorp Vault or AWS Secrets Manager, so that they needn't be
+// provided through process arguments or the environment.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend names used as Ref schemes.
+const (
+	BackendVault = "vault"
+	BackendAWS   = "aws-sm"
+)
+
+// Ref references a secret value in a backend. Refs are written in the form
+// <backend>://<path>[#<key>]; e.g. vault://secret/data/kafka/datadog#api_key
+// or aws-sm://prod/datadog#api_key. The key selects a field of a secret
+// holding multiple values.
+type Ref struct {
+	Backend string
+	Path    string
+	Key     string
+}
+
+// ParseRef parses a Ref from s.
+func ParseRef(s string) (Ref, error) {
+	backend, rest, found := strings.Cut(s, "://")
+	if !found {
+		return Ref{}, fmt.Errorf("invalid secret reference %q: expected <backend>://<path>[#<key>]", s)
+	}
+
+	r := Ref{Backend: backend, Path: rest}
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		r.Path, r.Key = rest[:i], rest[i+1:]
+	}
+
+	switch {
+	case r.Backend != BackendVault && r.Backend != BackendAWS:
+		return Ref{}, fmt.Errorf("invalid secret reference %q: unsupported backend %q (supported: %s, %s)", s, r.Backend, BackendVault, BackendAWS)
+	case r.Path == "":
+		return Ref{}, fmt.Errorf("invalid secret reference %q: no path", s)
+	case r.Backend == BackendVault && r.Key == "":
+		return Ref{}, fmt.Errorf("invalid secret reference %q: vault references require a #key", s)
+	}
+
+	return r, nil
+}
+
+// String returns the Ref in the form it's parsed from.
+func (r Ref) String() string {
+	if r.Key == "" {
+		return fmt.Sprintf("%s://%s", r.Backend, r.Path)
+	}
+
+	return fmt.Sprintf("%s://%s#%s", r.Backend, r.Path, r.Key)
+}
+
+// backend fetches the secret at a path, returning either a single value or
+// the fields of a secret holding multiple values.
+type backend interface {
+	get(ctx context.Context, path string) (value string, fields map[string]interface{}, err error)
+}
+
+// Config holds Client configuration parameters.
+type Config struct {
+	Vault VaultConfig
+	AWS   AWSConfig
+}
+
+// Client fetches secrets from the backend referenced by each Ref. Backends are
+// initialized when first used.
+type Client struct {
+	cfg Config
+
+	mu       sync.Mutex
+	backends map[string]backend
+}
+
+// NewClient takes a Config and returns a *Client.
+func NewClient(c Config) *Client {
+	return &Client{
+		cfg:      c,
+		backends: map[string]backend{},
+	}
+}
+
+// Get returns the value referenced by r.
+func (c *Client) Get(ctx context.Context, r Ref) (string, error) {
+	b, err := c.backend(r.Backend)
+	if err != nil {
+		return "", err
+	}
+
+	value, fields, err := b.get(ctx, r.Path)
+	if err != nil {
+		return "", fmt.Errorf("error fetching secret %s: %s", r, err)
+	}
+
+	// A key selects a field from a secret holding multiple values. Single
+	// valued secrets holding a JSON object are parsed for the field.
+	if r.Key != "" && fields == nil {
+		if err := json.Unmarshal([]byte(value), &fields); err != nil {
+			return "", fmt.Errorf("error fetching secret %s: secret isn't a JSON object", r)
+		}
+	}
+
+	if r.Key != "" {
+		v, exists := fields[r.Key]
+		if !exists {
+			return "", fmt.Errorf("error fetching secret %s: key not found", r)
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("error fetching secret %s: value isn't a string", r)
+		}
+
+		value = s
+	}
+
+	if value == "" {
+		return "", fmt.Errorf("error fetching secret %s: value is empty", r)
+	}
+
+	return value, nil
+}
+
+// GetAll returns the values referenced by refs, in order.
+func (c *Client) GetAll(ctx context.Context, refs []Ref) ([]string, error) {
+	values := make([]string, len(refs))
+	for i, r := range refs {
+		v, err := c.Get(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+func (c *Client) backend(name string) (backend, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b, exists := c.backends[name]; exists {
+		return b, nil
+	}
+
+	var b backend
+	var err error
+
+	switch name {
+	case BackendVault:
+		b, err = newVault(c.cfg.Vault)
+	case BackendAWS:
+		b = newAWSSecretsManager(c.cfg.AWS)
+	default:
+		err = fmt.Errorf("unsupported secrets backend %q", name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.backends[name] = b
+
+	return b, nil
+}
+
+// WatchConfig holds Watch configuration parameters.
+type WatchConfig struct {
+	// The secrets to watch.
+	Refs []Ref
+	// The current values of Refs.
+	Values []string
+	// How often the secrets are fetched.
+	Interval time.Duration
+	// Called with the values of Refs, in order, when any have changed. If an
+	// error is returned, the change is retried in the next interval.
+	OnChange func(values []string) error
+	// The timeout for fetching the secrets each interval; defaults to the
+	// Interval.
+	Timeout time.Duration
+}
+
+// Watch fetches the watched secrets every interval until ctx is cancelled,
+// calling OnChange when a secret has been rotated. Errors are logged and the
+// current values are retained. Secret values are never logged.
+func (c *Client) Watch(ctx context.Context, cfg WatchConfig) {
+	current := append([]string(nil), cfg.Values...)
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = cfg.Interval
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+		values, err := c.GetAll(fetchCtx, cfg.Refs)
+		cancel()
+
+		if err != nil {
+			log.Printf("Error refreshing secrets: %s\n", err)
+			continue
+		}
+
+		var rotated []string
+		for i := range values {
+			if values[i] != current[i] {
+				rotated = append(rotated, cfg.Refs[i].String())
+			}
+		}
+
+		if len(rotated) == 0 {
+			continue
+		}
+
+		if err := cfg.OnChange(values); err != nil {
+			log.Printf("Error applying rotated secrets %s: %s\n", strings.Join(rotated, ", "), err)
+			continue
+		}
+
+		log.Printf("Applied rotated secrets: %s\n", strings.Join(rotated, ", "))
+		current = values
+	}
+}
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected Ref
+		err      bool
+	}{
+		{"vault://secret/data/kafka/datadog#api_key", Ref{"vault", "secret/data/kafka/datadog", "api_key"}, false},
+		{"aws-sm://prod/datadog", Ref{"aws-sm", "prod/datadog", ""}, false},
+		{"aws-sm://arn:aws:secretsmanager:us-east-1:123456789012:secret:datadog-AbCdEf#app_key", Ref{"aws-sm", "arn:aws:secretsmanager:us-east-1:123456789012:secret:datadog-AbCdEf", "app_key"}, false},
+		// Vault secrets always hold fields.
+		{"vault://secret/data/kafka/datadog", Ref{}, true},
+		{"gcp://secret", Ref{}, true},
+		{"aws-sm://", Ref{}, true},
+		{"secret/data/kafka", Ref{}, true},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRef(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("[%s] Expected error %v, got %v", test.in, test.err, err)
+			continue
+		}
+
+		if r != test.expected {
+			t.Errorf("[%s] Expected %+v, got %+v", test.in, test.expected, r)
+		}
+
+		if err == nil && r.String() != test.in {
+			t.Errorf("Expected %s, got %s", test.in, r.String())
+		}
+	}
+}
+
+// rotatingVault serves a KV version 2 secret whose value can be changed.
+type rotatingVault struct {
+	mu    sync.Mutex
+	value string
+}
+
+func (v *rotatingVault) set(s string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.value = s
+}
+
+func (v *rotatingVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	w.Write([]byte(`{"data": {"data": {"api_key": "` + v.value + `"}, "metadata": {"version": 1}}}`))
+}
+
+func TestWatch(t *testing.T) {
+	v := &rotatingVault{value: "key1"}
+	srv := httptest.NewServer(v)
+	defer srv.Close()
+
+	t.Setenv("VAULT_TOKEN", "token")
+	c := NewClient(Config{Vault: VaultConfig{Address: srv.URL}})
+
+	ref, _ := ParseRef("vault://secret/data/datadog#api_key")
+
+	changes := make(chan []string, 10)
+	fail := true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go c.Watch(ctx, WatchConfig{
+		Refs:     []Ref{ref},
+		Values:   []string{"key1"},
+		Interval: 10 * time.Millisecond,
+		OnChange: func(values []string) error {
+			changes <- values
+			// The first attempt to apply a change fails and is retried.
+			if fail {
+				fail = false
+				return errors.New("invalid key")
+			}
+			return nil
+		},
+	})
+
+	v.set("key2")
+
+	for i := 0; i < 2; i++ {
+		select {
+		case values := <-changes:
+			if values[0] != "key2" {
+				t.Errorf("Expected key2, got %s", values[0])
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the rotated secret")
+		}
+	}
+
+	// Once applied, the change isn't applied again.
+	select {
+	case values := <-changes:
+		t.Errorf("Unexpected change %v", values)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
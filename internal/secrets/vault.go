@@ -0,0 +1,139 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultConfig holds Vault backend configuration parameters.
+type VaultConfig struct {
+	// The Vault address. Defaults to the VAULT_ADDR env var.
+	Address string
+	// A file holding the Vault token, e.g. as written by a Vault Agent sink.
+	// The file is read for each request so that renewed tokens are used. If
+	// unset, the VAULT_TOKEN env var is used.
+	TokenFile string
+	// Optional Vault Enterprise namespace. Defaults to the VAULT_NAMESPACE env
+	// var.
+	Namespace string
+	// Optional HTTP client.
+	HTTPClient *http.Client
+}
+
+// vault fetches secrets from a Vault KV secrets engine (version 1 or 2).
+type vault struct {
+	address   string
+	tokenFile string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newVault(c VaultConfig) (*vault, error) {
+	v := &vault{
+		address:   c.Address,
+		tokenFile: c.TokenFile,
+		namespace: c.Namespace,
+		client:    c.HTTPClient,
+	}
+
+	if v.address == "" {
+		v.address = os.Getenv("VAULT_ADDR")
+	}
+
+	if v.address == "" {
+		return nil, errors.New("no Vault address configured")
+	}
+
+	v.address = strings.TrimSuffix(v.address, "/")
+
+	if v.tokenFile == "" {
+		v.token = os.Getenv("VAULT_TOKEN")
+		if v.token == "" {
+			return nil, errors.New("no Vault token configured")
+		}
+	}
+
+	if v.namespace == "" {
+		v.namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+
+	if v.client == nil {
+		v.client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return v, nil
+}
+
+// vaultResponse is a Vault secret read response. For KV version 2 secrets,
+// Data holds the secret data and metadata.
+type vaultResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// vaultErrorResponse is a Vault API error response.
+type vaultErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// get implements the backend interface. Vault secrets always hold fields.
+func (v *vault) get(ctx context.Context, path string) (string, map[string]interface{}, error) {
+	token := v.token
+	if v.tokenFile != "" {
+		data, err := os.ReadFile(v.tokenFile)
+		if err != nil {
+			return "", nil, fmt.Errorf("error reading Vault token: %s", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s", v.address, strings.TrimPrefix(path, "/")), nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e vaultErrorResponse
+		if json.Unmarshal(body, &e) == nil && len(e.Errors) > 0 {
+			return "", nil, fmt.Errorf("Vault API error: %s: %s", resp.Status, strings.Join(e.Errors, ", "))
+		}
+		return "", nil, fmt.Errorf("Vault API error: %s", resp.Status)
+	}
+
+	var r vaultResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, fmt.Errorf("error parsing Vault response: %s", err)
+	}
+
+	// KV version 2 nests the secret data.
+	if data, ok := r.Data["data"].(map[string]interface{}); ok {
+		if _, versioned := r.Data["metadata"]; versioned {
+			return "", data, nil
+		}
+	}
+
+	return "", r.Data, nil
+}
@@ -0,0 +1,117 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// vaultStub serves a KV version 1 secret at secret/kafka and a KV version 2
+// secret at secret/data/kafka for requests with the token.
+func vaultStub(t *testing.T, token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/kafka":
+			w.Write([]byte(`{"data": {"api_key": "v1-api", "count": 1}}`))
+		case "/v1/secret/data/kafka":
+			if r.Header.Get("X-Vault-Namespace") != "team" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors": []}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"data": {"api_key": "v2-api", "app_key": "v2-app"}, "metadata": {"version": 3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+}
+
+func TestVaultGet(t *testing.T) {
+	srv := vaultStub(t, "token")
+	defer srv.Close()
+
+	// The token is read from a file.
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(Config{Vault: VaultConfig{Address: srv.URL, TokenFile: tokenFile, Namespace: "team"}})
+
+	tests := []struct {
+		ref      string
+		expected string
+		err      string
+	}{
+		{"vault://secret/kafka#api_key", "v1-api", ""},
+		{"vault://secret/data/kafka#api_key", "v2-api", ""},
+		{"vault://secret/data/kafka#app_key", "v2-app", ""},
+		{"vault://secret/data/kafka#other", "", "key not found"},
+		{"vault://secret/kafka#count", "", "value isn't a string"},
+		{"vault://secret/missing#api_key", "", "404 Not Found"},
+	}
+
+	for _, test := range tests {
+		ref, err := ParseRef(test.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := c.Get(context.Background(), ref)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("[%s] Unexpected error: %s", test.ref, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("[%s] Expected error containing '%s', got %v", test.ref, test.err, err)
+		case v != test.expected:
+			t.Errorf("[%s] Expected %s, got %s", test.ref, test.expected, v)
+		}
+	}
+
+	// A rejected token.
+	if err := os.WriteFile(tokenFile, []byte("expired"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, _ := ParseRef("vault://secret/kafka#api_key")
+	if _, err := c.Get(context.Background(), ref); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected a permission denied error, got %v", err)
+	}
+}
+
+func TestVaultConfig(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+
+	if _, err := newVault(VaultConfig{}); err == nil {
+		t.Error("Expected an error without a Vault address")
+	}
+
+	t.Setenv("VAULT_ADDR", "https://vault:8200/")
+
+	if _, err := newVault(VaultConfig{}); err == nil {
+		t.Error("Expected an error without a Vault token")
+	}
+
+	t.Setenv("VAULT_TOKEN", "token")
+
+	v, err := newVault(VaultConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v.address != "https://vault:8200" || v.token != "token" {
+		t.Errorf("Unexpected Vault config: %s, %s", v.address, v.token)
+	}
+}
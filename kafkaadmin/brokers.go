@@ -2,6 +2,7 @@ package kafkaadmin
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strconv"
 	"time"
@@ -17,6 +18,9 @@ type BrokerState struct {
 	Rack                       string // broker.rack
 	LogMessageFormat           string // log.message.format.version
 	InterBrokerProtocolVersion string // inter.broker.protocol.version
+	// Advertised listener endpoints (advertised.listeners, or listeners if
+	// unset) along with the security protocol of each.
+	Endpoints BrokerEndpoints
 	// All metadata.
 	FullData map[string]string
 }
@@ -77,6 +81,16 @@ func (c Client) DescribeBrokers(ctx context.Context, fullData bool) (BrokerState
 		b.LogMessageFormat = md[strID]["log.message.format.version"]
 		b.InterBrokerProtocolVersion = md[strID]["inter.broker.protocol.version"]
 
+		listeners := md[strID]["advertised.listeners"]
+		if listeners == "" {
+			listeners = md[strID]["listeners"]
+		}
+
+		b.Endpoints, err = parseListenerConfigs(listeners, md[strID]["listener.security.protocol.map"])
+		if err != nil {
+			return nil, fmt.Errorf("broker %d: %s", id, err)
+		}
+
 		// Populate full data if configured.
 		if fullData {
 			b.FullData = data
@@ -13,7 +13,7 @@ var (
 	// SecurityProtocolSet is the set of protocols supported to communicate with brokers
 	SecurityProtocolSet = map[string]struct{}{"PLAINTEXT": empty, "SSL": empty, "SASL_PLAINTEXT": empty, "SASL_SSL": empty}
 	// SASLMechanismSet is the set of mechanisms supported for client to broker authentication
-	SASLMechanismSet = map[string]struct{}{"PLAIN": empty, "SCRAM-SHA-256": empty, "SCRAM-SHA-512": empty}
+	SASLMechanismSet = map[string]struct{}{"PLAIN": empty, "SCRAM-SHA-256": empty, "SCRAM-SHA-512": empty, "OAUTHBEARER": empty}
 	// Default timeout for requests to Kafka if a context is passed in with no
 	// deadline set.
 	defaultTimeout = 5 * time.Second
@@ -25,6 +25,8 @@ type FactoryFunc func(conf *kafka.ConfigMap) (*kafka.AdminClient, error)
 type Client struct {
 	c                *kafka.AdminClient
 	DefaultTimeoutMs int
+	// Stops the OAUTHBEARER token refresh, if enabled.
+	stopTokenRefresh func()
 }
 
 // Config holds Client configuration parameters.
@@ -39,15 +41,70 @@ type Config struct {
 	SASLMechanism    string
 	SASLUsername     string
 	SASLPassword     string
+	// Client certificate and key paths for SSL client authentication.
+	SSLCertificateLocation string
+	SSLKeyLocation         string
+	// OAUTHBEARER mechanism settings. If OAuthBearerTokenRefresh is set, it's
+	// used to fetch tokens. Otherwise, tokens are read from the
+	// SASLOAuthBearerTokenFile if set. If neither are set, librdkafka's
+	// unsecured JWT handling is used with the SASLOAuthBearerConfig.
+	SASLOAuthBearerConfig    string
+	SASLOAuthBearerTokenFile string
+	OAuthBearerTokenRefresh  OAuthBearerTokenRefreshFunc
+	// Interceptors called around every KafkaAdmin call made by clients returned
+	// from NewClient.
+	Interceptors []Interceptor
 }
 
-// NewClient returns a KafkaAdmin.
+// Consumer is a kafka.Consumer configured from a Config.
+type Consumer struct {
+	*kafka.Consumer
+	// Stops the OAUTHBEARER token refresh, if enabled.
+	stopTokenRefresh func()
+}
+
+// Close closes the Consumer.
+func (c *Consumer) Close() error {
+	if c.stopTokenRefresh != nil {
+		c.stopTokenRefresh()
+	}
+
+	return c.Consumer.Close()
+}
+
+// Producer is a kafka.Producer configured from a Config.
+type Producer struct {
+	*kafka.Producer
+	// Stops the OAUTHBEARER token refresh, if enabled.
+	stopTokenRefresh func()
+}
+
+// Close closes the Producer.
+func (p *Producer) Close() {
+	if p.stopTokenRefresh != nil {
+		p.stopTokenRefresh()
+	}
+
+	p.Producer.Close()
+}
+
+// NewClient returns a KafkaAdmin. If any Interceptors are configured, they're
+// called around every call; see WithInterceptors.
 func NewClient(cfg Config) (KafkaAdmin, error) {
-	return newClient(cfg, kafka.NewAdminClient)
+	c, err := newClient(cfg, kafka.NewAdminClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return WithInterceptors(c, cfg.Interceptors...), nil
 }
 
 // Close closes the Client.
 func (c Client) Close() {
+	if c.stopTokenRefresh != nil {
+		c.stopTokenRefresh()
+	}
+
 	c.c.Close()
 }
 
@@ -56,17 +113,67 @@ func NewClientWithFactory(cfg Config, factory FactoryFunc) (*Client, error) {
 	return newClient(cfg, factory)
 }
 
-func NewConsumer(cfg Config) (*kafka.Consumer, error) {
+// NewConsumer returns a Consumer.
+func NewConsumer(cfg Config) (*Consumer, error) {
 	kafkaCfg, err := cfgToConfigMap(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("[config] %s", err)
 	}
-	c, err := kafka.NewConsumer(kafkaCfg)
+	k, err := kafka.NewConsumer(kafkaCfg)
+	if err != nil {
+		return nil, fmt.Errorf("[librdkafka] %s", err)
+	}
+
+	c := &Consumer{Consumer: k}
+
+	if fn := cfg.tokenRefreshFunc(); fn != nil {
+		if c.stopTokenRefresh, err = startTokenRefresh(fn, k); err != nil {
+			k.Close()
+			return nil, fmt.Errorf("[oauthbearer] %s", err)
+		}
+	}
+
+	return c, nil
+}
 
+// NewProducer returns a Producer.
+func NewProducer(cfg Config) (*Producer, error) {
+	kafkaCfg, err := cfgToConfigMap(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("[config] %s", err)
+	}
+	k, err := kafka.NewProducer(kafkaCfg)
 	if err != nil {
-		err = fmt.Errorf("[librdkafka] %s", err)
+		return nil, fmt.Errorf("[librdkafka] %s", err)
+	}
+
+	p := &Producer{Producer: k}
+
+	if fn := cfg.tokenRefreshFunc(); fn != nil {
+		if p.stopTokenRefresh, err = startTokenRefresh(fn, k); err != nil {
+			k.Close()
+			return nil, fmt.Errorf("[oauthbearer] %s", err)
+		}
+	}
+
+	return p, nil
+}
+
+// tokenRefreshFunc returns the OAuthBearerTokenRefreshFunc to use for the
+// Config, or nil if OAUTHBEARER token refreshes aren't handled by the client.
+func (cfg Config) tokenRefreshFunc() OAuthBearerTokenRefreshFunc {
+	if !strings.HasPrefix(cfg.SecurityProtocol, "SASL_") || cfg.SASLMechanism != "OAUTHBEARER" {
+		return nil
 	}
-	return c, err
+
+	switch {
+	case cfg.OAuthBearerTokenRefresh != nil:
+		return cfg.OAuthBearerTokenRefresh
+	case cfg.SASLOAuthBearerTokenFile != "":
+		return OAuthBearerTokenFromFile(cfg.SASLOAuthBearerTokenFile)
+	}
+
+	return nil
 }
 
 func cfgToConfigMap(cfg Config) (*kafka.ConfigMap, error) {
@@ -87,12 +194,35 @@ func cfgToConfigMap(cfg Config) (*kafka.ConfigMap, error) {
 			return nil, fmt.Errorf("kafka %s is enabled but SSLCALocation was not provided", cfg.SecurityProtocol)
 		}
 		kafkaCfg.SetKey("ssl.ca.location", cfg.SSLCALocation)
+
+		if (cfg.SSLCertificateLocation == "") != (cfg.SSLKeyLocation == "") {
+			return nil, fmt.Errorf("SSLCertificateLocation and SSLKeyLocation must be provided together")
+		}
+		if cfg.SSLCertificateLocation != "" {
+			kafkaCfg.SetKey("ssl.certificate.location", cfg.SSLCertificateLocation)
+			kafkaCfg.SetKey("ssl.key.location", cfg.SSLKeyLocation)
+		}
 	}
 
 	if strings.HasPrefix(cfg.SecurityProtocol, "SASL_") {
 		kafkaCfg.SetKey("sasl.mechanism", cfg.SASLMechanism)
-		kafkaCfg.SetKey("sasl.username", cfg.SASLUsername)
-		kafkaCfg.SetKey("sasl.password", cfg.SASLPassword)
+
+		switch cfg.SASLMechanism {
+		case "OAUTHBEARER":
+			if cfg.SASLOAuthBearerConfig != "" {
+				kafkaCfg.SetKey("sasl.oauthbearer.config", cfg.SASLOAuthBearerConfig)
+			}
+			// Without a token source, fall back to librdkafka's unsecured JWTs.
+			if cfg.tokenRefreshFunc() == nil {
+				if cfg.SASLOAuthBearerConfig == "" {
+					return nil, fmt.Errorf("OAUTHBEARER is enabled but no token source or SASLOAuthBearerConfig was provided")
+				}
+				kafkaCfg.SetKey("enable.sasl.oauthbearer.unsecure.jwt", true)
+			}
+		default:
+			kafkaCfg.SetKey("sasl.username", cfg.SASLUsername)
+			kafkaCfg.SetKey("sasl.password", cfg.SASLPassword)
+		}
 	}
 	return kafkaCfg, nil
 }
@@ -115,7 +245,15 @@ func newClient(cfg Config, factory FactoryFunc) (*Client, error) {
 	c.c = k
 
 	if err != nil {
-		err = fmt.Errorf("[librdkafka] %s", err)
+		return c, fmt.Errorf("[librdkafka] %s", err)
+	}
+
+	if fn := cfg.tokenRefreshFunc(); fn != nil {
+		if c.stopTokenRefresh, err = startTokenRefresh(fn, k); err != nil {
+			k.Close()
+			return nil, fmt.Errorf("[oauthbearer] %s", err)
+		}
 	}
-	return c, err
+
+	return c, nil
 }
@@ -58,3 +58,78 @@ func TestNewClientWithSASLEnabled(t *testing.T) {
 	assert.Nil(t, err)
 	mkac.AssertExpectations(t)
 }
+
+func TestNewClientWithSSLClientAuth(t *testing.T) {
+	mkac := &MockedKafkaAdminClient{}
+	mkac.On("NewAdminClient",
+		&kafka.ConfigMap{
+			"bootstrap.servers":        "kafka:9092",
+			"ssl.ca.location":          "/etc/kafka/config/ca.crt",
+			"ssl.certificate.location": "/etc/kafka/config/client.crt",
+			"ssl.key.location":         "/etc/kafka/config/client.key",
+			"security.protocol":        "SSL",
+		},
+	).Return(&kafka.AdminClient{}, nil)
+	_, err := NewClientWithFactory(
+		Config{
+			BootstrapServers:       "kafka:9092",
+			SSLCALocation:          "/etc/kafka/config/ca.crt",
+			SSLCertificateLocation: "/etc/kafka/config/client.crt",
+			SSLKeyLocation:         "/etc/kafka/config/client.key",
+			SecurityProtocol:       "SSL",
+		},
+		mkac.NewAdminClient,
+	)
+	assert.Nil(t, err)
+	mkac.AssertExpectations(t)
+
+	// A certificate without a key is invalid.
+	_, err = NewClientWithFactory(
+		Config{
+			BootstrapServers:       "kafka:9092",
+			SSLCALocation:          "/etc/kafka/config/ca.crt",
+			SSLCertificateLocation: "/etc/kafka/config/client.crt",
+			SecurityProtocol:       "SSL",
+		},
+		mkac.NewAdminClient,
+	)
+	assert.NotNil(t, err)
+}
+
+func TestNewClientWithOAuthBearerEnabled(t *testing.T) {
+	mkac := &MockedKafkaAdminClient{}
+	mkac.On("NewAdminClient",
+		&kafka.ConfigMap{
+			"bootstrap.servers":                    "kafka:9092",
+			"ssl.ca.location":                      "/etc/kafka/config/ca.crt",
+			"security.protocol":                    "SASL_SSL",
+			"sasl.mechanism":                       "OAUTHBEARER",
+			"sasl.oauthbearer.config":              "principal=registry",
+			"enable.sasl.oauthbearer.unsecure.jwt": true,
+		},
+	).Return(&kafka.AdminClient{}, nil)
+	_, err := NewClientWithFactory(
+		Config{
+			BootstrapServers:      "kafka:9092",
+			SSLCALocation:         "/etc/kafka/config/ca.crt",
+			SecurityProtocol:      "SASL_SSL",
+			SASLMechanism:         "OAUTHBEARER",
+			SASLOAuthBearerConfig: "principal=registry",
+		},
+		mkac.NewAdminClient,
+	)
+	assert.Nil(t, err)
+	mkac.AssertExpectations(t)
+
+	// No token source or config is invalid.
+	_, err = NewClientWithFactory(
+		Config{
+			BootstrapServers: "kafka:9092",
+			SSLCALocation:    "/etc/kafka/config/ca.crt",
+			SecurityProtocol: "SASL_SSL",
+			SASLMechanism:    "OAUTHBEARER",
+		},
+		mkac.NewAdminClient,
+	)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,108 @@
+package kafkaadmin
+
+import (
+	"context"
+	"sort"
+)
+
+// Note: tests are located in the stub subdir so that mock data can be used
+// without resulting in import cycle.
+
+// ClusterHealth is a report of the partition health of a cluster.
+type ClusterHealth struct {
+	Topics     int
+	Partitions int
+	// Partitions where the ISR is smaller than the replica set. As with
+	// UnderReplicated, this includes partitions that are being reassigned.
+	UnderReplicated []PartitionHealth
+	// Partitions without a leader.
+	Offline []PartitionHealth
+	// Map of broker ID to the number of partitions where the broker is an
+	// assigned replica but not in the ISR.
+	OutOfSyncReplicas map[int32]int
+}
+
+// PartitionHealth describes the state of an unhealthy partition. Partition
+// lists in a ClusterHealth are ordered by topic name then partition ID.
+type PartitionHealth struct {
+	Topic     string
+	Partition int32
+	Leader    int32
+	Replicas  []int32
+	ISR       []int32
+	// Assigned replicas that aren't in the ISR.
+	OutOfSync []int32
+}
+
+// ClusterHealth returns a ClusterHealth for all topics.
+func (c Client) ClusterHealth(ctx context.Context) (ClusterHealth, error) {
+	states, err := c.DescribeTopics(ctx, []string{".*"})
+	switch err {
+	case nil:
+	case ErrNoData:
+		return NewTopicStates().Health(), nil
+	default:
+		return ClusterHealth{}, err
+	}
+
+	return states.Health(), nil
+}
+
+// Health returns a ClusterHealth for the TopicStates.
+func (ts TopicStates) Health() ClusterHealth {
+	health := ClusterHealth{
+		Topics:            len(ts),
+		OutOfSyncReplicas: map[int32]int{},
+	}
+
+	var names []string
+	for name := range ts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		state := ts[name]
+
+		var ids []int
+		for id := range state.PartitionStates {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+
+		health.Partitions += len(ids)
+
+		for _, id := range ids {
+			partn := state.PartitionStates[id]
+
+			ph := PartitionHealth{
+				Topic:     name,
+				Partition: partn.ID,
+				Leader:    partn.Leader,
+				Replicas:  partn.Replicas,
+				ISR:       partn.ISR,
+				OutOfSync: subtractInt32s(partn.Replicas, partn.ISR),
+			}
+
+			for _, broker := range ph.OutOfSync {
+				health.OutOfSyncReplicas[broker]++
+			}
+
+			// The leader is -1 where there's no leader.
+			if partn.Leader < 0 {
+				health.Offline = append(health.Offline, ph)
+			}
+
+			if len(partn.ISR) < len(partn.Replicas) {
+				health.UnderReplicated = append(health.UnderReplicated, ph)
+			}
+		}
+	}
+
+	return health
+}
+
+// Healthy returns whether there are no under-replicated or offline partitions.
+func (h ClusterHealth) Healthy() bool {
+	return len(h.UnderReplicated) == 0 && len(h.Offline) == 0
+}
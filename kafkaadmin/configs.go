@@ -3,6 +3,10 @@ package kafkaadmin
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 )
@@ -84,6 +88,56 @@ func (c Client) getConfigs(ctx context.Context, kind string, names []string, onl
 	return results, nil
 }
 
+// SetTopicConfigs takes a ResourceConfigs of topic name to configurations and
+// applies them to each topic. The configurations provided are merged into any
+// existing dynamic configurations for each topic; configs not specified are
+// left unmodified.
+func (c Client) SetTopicConfigs(ctx context.Context, configs ResourceConfigs) error {
+	if len(configs) == 0 {
+		return nil
+	}
+
+	var names []string
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Fetch the current dynamic configs; AlterConfigs replaces all dynamic
+	// configs for a resource.
+	current, err := c.GetDynamicConfigs(ctx, "topic", names)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		for k, v := range configs[name] {
+			if err := current.AddConfig(name, k, v); err != nil {
+				return fmt.Errorf("topic %s: %s", name, err)
+			}
+		}
+
+		cr := kafka.ConfigResource{
+			Type:   topicResourceType,
+			Name:   name,
+			Config: kafka.StringMapToConfigEntries(current[name], kafka.AlterOperationSet),
+		}
+
+		results, err := c.c.AlterConfigs(ctx, []kafka.ConfigResource{cr})
+		if err != nil {
+			return fmt.Errorf("topic %s: %s", name, err)
+		}
+
+		for _, r := range results {
+			if r.Error.Code() != kafka.ErrNoError {
+				return fmt.Errorf("topic %s: %s", name, r.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
 // AddConfig takes a resource name and populates the config key to the specified
 // value.
 func (rc ResourceConfigs) AddConfig(name, key, value string) error {
@@ -120,3 +174,127 @@ func (rc ResourceConfigs) AddConfigEntry(name string, config kafka.ConfigEntryRe
 
 	return nil
 }
+
+// BrokerErrors is a map of broker ID, as named in a ResourceConfigs, to the
+// error encountered for the broker.
+type BrokerErrors map[string]error
+
+// Error returns the errors for all brokers, ordered by broker ID.
+func (be BrokerErrors) Error() string {
+	var ids []string
+	for id := range be {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var errs []string
+	for _, id := range ids {
+		errs = append(errs, fmt.Sprintf("broker %s: %s", id, be[id]))
+	}
+
+	return strings.Join(errs, ", ")
+}
+
+// BulkGetDynamicConfigs takes a list of broker IDs and returns a ResourceConfigs
+// of all dynamic configurations for each broker. Kafka only allows one broker
+// resource per describe request, so requests for all brokers are issued
+// concurrently. Any errors are returned in a BrokerErrors, which is nil if all
+// requests succeeded.
+func (c Client) BulkGetDynamicConfigs(ctx context.Context, ids []int) (ResourceConfigs, BrokerErrors) {
+	var results = make(ResourceConfigs)
+	var errs = make(BrokerErrors)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, id := range ids {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			cr := kafka.ConfigResource{
+				Type: brokerResourceType,
+				Name: name,
+			}
+
+			resourceConfigs, err := c.c.DescribeConfigs(ctx, []kafka.ConfigResource{cr})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs[name] = ErrorFetchingMetadata{err.Error()}
+				return
+			}
+
+			for _, config := range resourceConfigs {
+				if config.Error.Code() != kafka.ErrNoError {
+					errs[name] = ErrorFetchingMetadata{config.Error.Error()}
+					return
+				}
+
+				for _, v := range config.Config {
+					if v.Source == kafka.ConfigSourceDynamicBroker {
+						results.AddConfigEntry(config.Name, v)
+					}
+				}
+			}
+		}(strconv.Itoa(id))
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return results, errs
+	}
+
+	return results, nil
+}
+
+// BulkSetDynamicConfigs takes a ResourceConfigs of broker ID to dynamic
+// configurations and applies them to each broker. The configurations provided
+// replace all existing dynamic configurations for each broker. Kafka only
+// allows one broker resource per alter request, so requests for all brokers are
+// issued concurrently. Any errors are returned in a BrokerErrors, which is nil
+// if all requests succeeded.
+func (c Client) BulkSetDynamicConfigs(ctx context.Context, configs ResourceConfigs) BrokerErrors {
+	var errs = make(BrokerErrors)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, config := range configs {
+		wg.Add(1)
+		go func(name string, config map[string]string) {
+			defer wg.Done()
+
+			cr := kafka.ConfigResource{
+				Type:   brokerResourceType,
+				Name:   name,
+				Config: kafka.StringMapToConfigEntries(config, kafka.AlterOperationSet),
+			}
+
+			results, err := c.c.AlterConfigs(ctx, []kafka.ConfigResource{cr})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs[name] = err
+				return
+			}
+
+			for _, r := range results {
+				if r.Error.Code() != kafka.ErrNoError {
+					errs[name] = r.Error
+				}
+			}
+		}(name, config)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build integration
+
+package kafkaadmin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkSetGetDynamicConfigs(t *testing.T) {
+	ctx, ka := testKafkaAdminClient(t)
+
+	configs := ResourceConfigs{
+		"1001": {"follower.replication.throttled.rate": "1000"},
+		"1002": {"leader.replication.throttled.rate": "2000"},
+	}
+
+	errs := ka.BulkSetDynamicConfigs(ctx, configs)
+	assert.Nil(t, errs)
+
+	time.Sleep(1 * time.Second)
+
+	got, errs := ka.BulkGetDynamicConfigs(ctx, []int{1001, 1002})
+	assert.Nil(t, errs)
+	assert.Equal(t, configs, got)
+
+	// Clear the configs.
+	errs = ka.BulkSetDynamicConfigs(ctx, ResourceConfigs{"1001": {}, "1002": {}})
+	assert.Nil(t, errs)
+
+	time.Sleep(1 * time.Second)
+
+	got, errs = ka.BulkGetDynamicConfigs(ctx, []int{1001, 1002})
+	assert.Nil(t, errs)
+	assert.Equal(t, ResourceConfigs{}, got)
+}
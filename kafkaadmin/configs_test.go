@@ -1,6 +1,7 @@
 package kafkaadmin
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
@@ -42,3 +43,13 @@ func TestAddConfigEntry(t *testing.T) {
 
 	assert.Equal(t, "config-value", rc["test-entry"]["config-key"], "unexpected value")
 }
+
+func TestBrokerErrors(t *testing.T) {
+	errs := BrokerErrors{
+		"1002": fmt.Errorf("timed out"),
+		"1001": ErrNoData,
+	}
+
+	expected := "broker 1001: no data returned, broker 1002: timed out"
+	assert.Equal(t, expected, errs.Error())
+}
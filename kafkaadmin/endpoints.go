@@ -0,0 +1,103 @@
+package kafkaadmin
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// BrokerEndpoint describes a broker listener endpoint.
+type BrokerEndpoint struct {
+	// The listener name, e.g. PLAINTEXT or INTERNAL.
+	Listener         string
+	SecurityProtocol string
+	Host             string
+	Port             int
+}
+
+// BrokerEndpoints is a list of BrokerEndpoint.
+type BrokerEndpoints []BrokerEndpoint
+
+// String returns the endpoint in the LISTENER://host:port form.
+func (e BrokerEndpoint) String() string {
+	return fmt.Sprintf("%s://%s", e.Listener, net.JoinHostPort(e.Host, strconv.Itoa(e.Port)))
+}
+
+// ForSecurityProtocol returns the first BrokerEndpoint using the specified
+// security protocol and whether one was found.
+func (be BrokerEndpoints) ForSecurityProtocol(p string) (BrokerEndpoint, bool) {
+	for _, e := range be {
+		if e.SecurityProtocol == p {
+			return e, true
+		}
+	}
+
+	return BrokerEndpoint{}, false
+}
+
+// ParseBrokerEndpoints takes a list of listener endpoints in the
+// LISTENER://host:port form, as found in the broker listeners configs and
+// registration, along with a map of listener name to security protocol and
+// returns a BrokerEndpoints. Listeners missing from the protocol map are
+// assumed to be named after their security protocol, matching the Kafka default.
+func ParseBrokerEndpoints(endpoints []string, protocolMap map[string]string) (BrokerEndpoints, error) {
+	var be BrokerEndpoints
+
+	for _, endpoint := range endpoints {
+		listener, address, found := strings.Cut(strings.TrimSpace(endpoint), "://")
+		if !found || listener == "" {
+			return nil, fmt.Errorf("invalid endpoint %s", endpoint)
+		}
+
+		host, portStr, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %s: %s", endpoint, err)
+		}
+
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %s: invalid port", endpoint)
+		}
+
+		protocol, exists := protocolMap[listener]
+		if !exists {
+			if _, valid := SecurityProtocolSet[listener]; !valid {
+				return nil, fmt.Errorf("no security protocol mapped for listener %s", listener)
+			}
+			protocol = listener
+		}
+
+		be = append(be, BrokerEndpoint{
+			Listener:         listener,
+			SecurityProtocol: protocol,
+			Host:             host,
+			Port:             port,
+		})
+	}
+
+	return be, nil
+}
+
+// parseListenerConfigs takes the comma delimited listeners and
+// listener.security.protocol.map config values, e.g.
+// "INTERNAL://host:9092,EXTERNAL://host:9093" and "INTERNAL:PLAINTEXT,EXTERNAL:SSL",
+// and returns a BrokerEndpoints.
+func parseListenerConfigs(listeners, protocolMap string) (BrokerEndpoints, error) {
+	if listeners == "" {
+		return nil, nil
+	}
+
+	var pm = make(map[string]string)
+	if protocolMap != "" {
+		for _, entry := range strings.Split(protocolMap, ",") {
+			listener, protocol, found := strings.Cut(strings.TrimSpace(entry), ":")
+			if !found {
+				return nil, fmt.Errorf("invalid listener security protocol map entry %s", entry)
+			}
+			pm[listener] = protocol
+		}
+	}
+
+	return ParseBrokerEndpoints(strings.Split(listeners, ","), pm)
+}
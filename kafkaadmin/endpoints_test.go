@@ -0,0 +1,68 @@
+package kafkaadmin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBrokerEndpoints(t *testing.T) {
+	endpoints := []string{"INTERNAL://10.0.1.100:9092", "SSL://kafka-1.example.com:9093", "PLAINTEXT://[::1]:9094"}
+	protocolMap := map[string]string{"INTERNAL": "SASL_PLAINTEXT"}
+
+	be, err := ParseBrokerEndpoints(endpoints, protocolMap)
+	assert.Nil(t, err)
+
+	expected := BrokerEndpoints{
+		{Listener: "INTERNAL", SecurityProtocol: "SASL_PLAINTEXT", Host: "10.0.1.100", Port: 9092},
+		{Listener: "SSL", SecurityProtocol: "SSL", Host: "kafka-1.example.com", Port: 9093},
+		{Listener: "PLAINTEXT", SecurityProtocol: "PLAINTEXT", Host: "::1", Port: 9094},
+	}
+
+	assert.Equal(t, expected, be)
+	assert.Equal(t, "PLAINTEXT://[::1]:9094", be[2].String())
+
+	// Error conditions.
+
+	for _, endpoint := range []string{"10.0.1.100:9092", "INTERNAL://10.0.1.100", "INTERNAL://10.0.1.100:port"} {
+		_, err = ParseBrokerEndpoints([]string{endpoint}, protocolMap)
+		assert.NotNil(t, err, endpoint)
+	}
+
+	// Unmapped listeners not named after a security protocol.
+	_, err = ParseBrokerEndpoints([]string{"EXTERNAL://10.0.1.100:9092"}, protocolMap)
+	assert.NotNil(t, err)
+}
+
+func TestParseListenerConfigs(t *testing.T) {
+	be, err := parseListenerConfigs("INTERNAL://:9092,EXTERNAL://kafka-1:9093", "INTERNAL:PLAINTEXT,EXTERNAL:SSL")
+	assert.Nil(t, err)
+
+	expected := BrokerEndpoints{
+		{Listener: "INTERNAL", SecurityProtocol: "PLAINTEXT", Host: "", Port: 9092},
+		{Listener: "EXTERNAL", SecurityProtocol: "SSL", Host: "kafka-1", Port: 9093},
+	}
+
+	assert.Equal(t, expected, be)
+
+	be, err = parseListenerConfigs("", "")
+	assert.Nil(t, err)
+	assert.Nil(t, be)
+
+	_, err = parseListenerConfigs("INTERNAL://:9092", "INTERNAL")
+	assert.NotNil(t, err)
+}
+
+func TestForSecurityProtocol(t *testing.T) {
+	be := BrokerEndpoints{
+		{Listener: "INTERNAL", SecurityProtocol: "PLAINTEXT", Host: "10.0.1.100", Port: 9092},
+		{Listener: "EXTERNAL", SecurityProtocol: "SSL", Host: "kafka-1", Port: 9093},
+	}
+
+	e, found := be.ForSecurityProtocol("SSL")
+	assert.True(t, found)
+	assert.Equal(t, "EXTERNAL", e.Listener)
+
+	_, found = be.ForSecurityProtocol("SASL_SSL")
+	assert.False(t, found)
+}
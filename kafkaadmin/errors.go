@@ -2,6 +2,7 @@ package kafkaadmin
 
 import (
 	"fmt"
+	"strings"
 )
 
 var (
@@ -29,3 +30,34 @@ type ErrorFetchingMetadata struct{ Message string }
 func (e ErrorFetchingMetadata) Error() string {
 	return fmt.Sprintf("error fetching metadata: %s", e.Message)
 }
+
+// ErrTopicSpecMismatch is returned by CreateTopicIfNotExists when an existing
+// topic differs from the requested configuration. Each field describes the
+// difference as "existing != requested", empty if not different.
+type ErrTopicSpecMismatch struct {
+	Topic             string
+	Partitions        string
+	ReplicationFactor string
+	Configs           []string
+}
+
+func (e ErrTopicSpecMismatch) Error() string {
+	var diffs []string
+	if e.Partitions != "" {
+		diffs = append(diffs, "partitions: "+e.Partitions)
+	}
+	if e.ReplicationFactor != "" {
+		diffs = append(diffs, "replication factor: "+e.ReplicationFactor)
+	}
+	diffs = append(diffs, e.Configs...)
+
+	return fmt.Sprintf("topic %s exists with a different configuration: %s", e.Topic, strings.Join(diffs, ", "))
+}
+
+func (e ErrTopicSpecMismatch) empty() bool {
+	return e.Partitions == "" && e.ReplicationFactor == "" && len(e.Configs) == 0
+}
+
+func (e ErrTopicSpecMismatch) onlyConfigs() bool {
+	return e.Partitions == "" && e.ReplicationFactor == ""
+}
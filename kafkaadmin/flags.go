@@ -0,0 +1,56 @@
+package kafkaadmin
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RegisterFlags registers flags for the Config security settings on the
+// provided FlagSet. The BootstrapServers flag is left to the caller since
+// naming varies between tools.
+func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&cfg.SecurityProtocol, "kafka-security-protocol", "", fmt.Sprintf("Protocol used to communicate with brokers. Supported: %s", strings.Join(setKeys(SecurityProtocolSet), ", ")))
+	fs.StringVar(&cfg.SSLCALocation, "kafka-ssl-ca-location", "", "CA certificate path (.pem/.crt) for verifying broker's identity. Needed for SSL and SASL_SSL protocols.")
+	fs.StringVar(&cfg.SSLCertificateLocation, "kafka-ssl-certificate-location", "", "Client certificate path (.pem/.crt) for SSL client authentication")
+	fs.StringVar(&cfg.SSLKeyLocation, "kafka-ssl-key-location", "", "Client private key path (.pem/.key) for SSL client authentication")
+	fs.StringVar(&cfg.SASLMechanism, "kafka-sasl-mechanism", "", fmt.Sprintf("SASL mechanism to use for authentication. Supported: %s", strings.Join(setKeys(SASLMechanismSet), ", ")))
+	fs.StringVar(&cfg.SASLUsername, "kafka-sasl-username", "", "SASL username for use with the PLAIN and SASL-SCRAM-* mechanisms")
+	fs.StringVar(&cfg.SASLPassword, "kafka-sasl-password", "", "SASL password for use with the PLAIN and SASL-SCRAM-* mechanisms")
+	fs.StringVar(&cfg.SASLOAuthBearerConfig, "kafka-sasl-oauthbearer-config", "", "SASL OAUTHBEARER configuration (e.g. 'principal=admin'); used for unsecured JWTs if no token file is provided")
+	fs.StringVar(&cfg.SASLOAuthBearerTokenFile, "kafka-sasl-oauthbearer-token-file", "", "Path to a JWT for use with the OAUTHBEARER mechanism; re-read as each token nears expiration")
+}
+
+// Validate normalizes and validates the Config security settings.
+func (cfg *Config) Validate() error {
+	if cfg.SecurityProtocol != "" {
+		cfg.SecurityProtocol = strings.ToUpper(cfg.SecurityProtocol)
+		if _, validChoice := SecurityProtocolSet[cfg.SecurityProtocol]; !validChoice {
+			return fmt.Errorf("invalid kafka security protocol. Supported protocols: %s", strings.Join(setKeys(SecurityProtocolSet), ", "))
+		}
+	}
+
+	if cfg.SASLMechanism != "" {
+		cfg.SASLMechanism = strings.ToUpper(cfg.SASLMechanism)
+		if _, validChoice := SASLMechanismSet[cfg.SASLMechanism]; !validChoice {
+			return fmt.Errorf("invalid kafka SASL mechanism. Supported mechanisms: %s", strings.Join(setKeys(SASLMechanismSet), ", "))
+		}
+	}
+
+	_, err := cfgToConfigMap(*cfg)
+
+	return err
+}
+
+// setKeys returns the sorted keys of a set.
+func setKeys(s map[string]struct{}) []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,183 @@
+package kafkaadmin
+
+import (
+	"context"
+	"time"
+)
+
+// Interceptor is called around every KafkaAdmin call, other than Close, with
+// the name of the KafkaAdmin method and a call func that performs it. An
+// Interceptor must call call, passing ctx or a context derived from it, and
+// should return its error; this allows logging, metrics and tracing to be
+// attached to all calls uniformly. BrokerErrors returned by bulk calls are
+// passed to Interceptors as an error if non-empty.
+type Interceptor func(ctx context.Context, method string, call func(context.Context) error) error
+
+// WithInterceptors returns a KafkaAdmin that calls the Interceptors around
+// every call to ka. Interceptors are nested in order; the first Interceptor is
+// outermost.
+func WithInterceptors(ka KafkaAdmin, interceptors ...Interceptor) KafkaAdmin {
+	if len(interceptors) == 0 {
+		return ka
+	}
+
+	return interceptedClient{ka: ka, interceptors: interceptors}
+}
+
+// interceptedClient is a KafkaAdmin that calls interceptors around every call
+// to ka.
+type interceptedClient struct {
+	ka           KafkaAdmin
+	interceptors []Interceptor
+}
+
+// intercept calls call through the interceptors.
+func (c interceptedClient) intercept(ctx context.Context, method string, call func(context.Context) error) error {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], call
+		call = func(ctx context.Context) error {
+			return interceptor(ctx, method, next)
+		}
+	}
+
+	return call(ctx)
+}
+
+// brokerErrors returns be as an error if non-empty.
+func brokerErrors(be BrokerErrors) error {
+	if len(be) == 0 {
+		return nil
+	}
+	return be
+}
+
+func (c interceptedClient) Close() {
+	c.ka.Close()
+}
+
+func (c interceptedClient) CreateTopic(ctx context.Context, cfg CreateTopicConfig) error {
+	return c.intercept(ctx, "CreateTopic", func(ctx context.Context) error {
+		return c.ka.CreateTopic(ctx, cfg)
+	})
+}
+
+func (c interceptedClient) CreateTopicIfNotExists(ctx context.Context, cfg CreateTopicConfig, onExists TopicExistsBehavior) (bool, error) {
+	var created bool
+	err := c.intercept(ctx, "CreateTopicIfNotExists", func(ctx context.Context) (err error) {
+		created, err = c.ka.CreateTopicIfNotExists(ctx, cfg, onExists)
+		return err
+	})
+	return created, err
+}
+
+func (c interceptedClient) DeleteTopic(ctx context.Context, name string) error {
+	return c.intercept(ctx, "DeleteTopic", func(ctx context.Context) error {
+		return c.ka.DeleteTopic(ctx, name)
+	})
+}
+
+func (c interceptedClient) DescribeTopics(ctx context.Context, topics []string) (TopicStates, error) {
+	var states TopicStates
+	err := c.intercept(ctx, "DescribeTopics", func(ctx context.Context) (err error) {
+		states, err = c.ka.DescribeTopics(ctx, topics)
+		return err
+	})
+	return states, err
+}
+
+func (c interceptedClient) UnderReplicatedTopics(ctx context.Context) (TopicStates, error) {
+	var states TopicStates
+	err := c.intercept(ctx, "UnderReplicatedTopics", func(ctx context.Context) (err error) {
+		states, err = c.ka.UnderReplicatedTopics(ctx)
+		return err
+	})
+	return states, err
+}
+
+// SubscribeTopicStates polls with the interceptedClient so that each poll is
+// intercepted as a DescribeTopics call.
+func (c interceptedClient) SubscribeTopicStates(ctx context.Context, interval time.Duration, filter []string) <-chan TopicEvent {
+	return PollTopicStates(ctx, c, interval, filter)
+}
+
+func (c interceptedClient) ListBrokers(ctx context.Context) ([]int, error) {
+	var ids []int
+	err := c.intercept(ctx, "ListBrokers", func(ctx context.Context) (err error) {
+		ids, err = c.ka.ListBrokers(ctx)
+		return err
+	})
+	return ids, err
+}
+
+func (c interceptedClient) DescribeBrokers(ctx context.Context, withConfigs bool) (BrokerStates, error) {
+	var states BrokerStates
+	err := c.intercept(ctx, "DescribeBrokers", func(ctx context.Context) (err error) {
+		states, err = c.ka.DescribeBrokers(ctx, withConfigs)
+		return err
+	})
+	return states, err
+}
+
+func (c interceptedClient) ClusterHealth(ctx context.Context) (ClusterHealth, error) {
+	var health ClusterHealth
+	err := c.intercept(ctx, "ClusterHealth", func(ctx context.Context) (err error) {
+		health, err = c.ka.ClusterHealth(ctx)
+		return err
+	})
+	return health, err
+}
+
+func (c interceptedClient) SetThrottle(ctx context.Context, cfg SetThrottleConfig) error {
+	return c.intercept(ctx, "SetThrottle", func(ctx context.Context) error {
+		return c.ka.SetThrottle(ctx, cfg)
+	})
+}
+
+func (c interceptedClient) RemoveThrottle(ctx context.Context, cfg RemoveThrottleConfig) error {
+	return c.intercept(ctx, "RemoveThrottle", func(ctx context.Context) error {
+		return c.ka.RemoveThrottle(ctx, cfg)
+	})
+}
+
+func (c interceptedClient) GetConfigs(ctx context.Context, kind string, names []string) (ResourceConfigs, error) {
+	var configs ResourceConfigs
+	err := c.intercept(ctx, "GetConfigs", func(ctx context.Context) (err error) {
+		configs, err = c.ka.GetConfigs(ctx, kind, names)
+		return err
+	})
+	return configs, err
+}
+
+func (c interceptedClient) GetDynamicConfigs(ctx context.Context, kind string, names []string) (ResourceConfigs, error) {
+	var configs ResourceConfigs
+	err := c.intercept(ctx, "GetDynamicConfigs", func(ctx context.Context) (err error) {
+		configs, err = c.ka.GetDynamicConfigs(ctx, kind, names)
+		return err
+	})
+	return configs, err
+}
+
+func (c interceptedClient) SetTopicConfigs(ctx context.Context, configs ResourceConfigs) error {
+	return c.intercept(ctx, "SetTopicConfigs", func(ctx context.Context) error {
+		return c.ka.SetTopicConfigs(ctx, configs)
+	})
+}
+
+func (c interceptedClient) BulkGetDynamicConfigs(ctx context.Context, ids []int) (ResourceConfigs, BrokerErrors) {
+	var configs ResourceConfigs
+	var errs BrokerErrors
+	c.intercept(ctx, "BulkGetDynamicConfigs", func(ctx context.Context) error {
+		configs, errs = c.ka.BulkGetDynamicConfigs(ctx, ids)
+		return brokerErrors(errs)
+	})
+	return configs, errs
+}
+
+func (c interceptedClient) BulkSetDynamicConfigs(ctx context.Context, configs ResourceConfigs) BrokerErrors {
+	var errs BrokerErrors
+	c.intercept(ctx, "BulkSetDynamicConfigs", func(ctx context.Context) error {
+		errs = c.ka.BulkSetDynamicConfigs(ctx, configs)
+		return brokerErrors(errs)
+	})
+	return errs
+}
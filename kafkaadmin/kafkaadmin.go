@@ -3,6 +3,7 @@ package kafkaadmin
 
 import (
 	"context"
+	"time"
 )
 
 // KafkaAdmin interface.
@@ -10,15 +11,21 @@ type KafkaAdmin interface {
 	Close()
 	// Topics.
 	CreateTopic(context.Context, CreateTopicConfig) error
+	CreateTopicIfNotExists(context.Context, CreateTopicConfig, TopicExistsBehavior) (bool, error)
 	DeleteTopic(context.Context, string) error
 	DescribeTopics(context.Context, []string) (TopicStates, error)
 	UnderReplicatedTopics(context.Context) (TopicStates, error)
+	SubscribeTopicStates(context.Context, time.Duration, []string) <-chan TopicEvent
 	// Brokers.
 	ListBrokers(context.Context) ([]int, error)
 	DescribeBrokers(context.Context, bool) (BrokerStates, error)
 	// Cluster.
+	ClusterHealth(context.Context) (ClusterHealth, error)
 	SetThrottle(context.Context, SetThrottleConfig) error
 	RemoveThrottle(context.Context, RemoveThrottleConfig) error
 	GetConfigs(context.Context, string, []string) (ResourceConfigs, error)
 	GetDynamicConfigs(context.Context, string, []string) (ResourceConfigs, error)
+	SetTopicConfigs(context.Context, ResourceConfigs) error
+	BulkGetDynamicConfigs(context.Context, []int) (ResourceConfigs, BrokerErrors)
+	BulkSetDynamicConfigs(context.Context, ResourceConfigs) BrokerErrors
 }
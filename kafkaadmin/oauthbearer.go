@@ -0,0 +1,145 @@
+package kafkaadmin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+var (
+	// The minimum interval between token refreshes, also used as the retry
+	// interval after a failed refresh.
+	tokenMinRefreshInterval = 10 * time.Second
+)
+
+// OAuthBearerTokenRefreshFunc returns a token for SASL/OAUTHBEARER
+// authentication. It's called when a client is initialized and again as each
+// token approaches its expiration.
+type OAuthBearerTokenRefreshFunc func() (kafka.OAuthBearerToken, error)
+
+// oauthBearerTokenSetter is implemented by clients that accept SASL/OAUTHBEARER
+// tokens, e.g. a *kafka.AdminClient or *kafka.Consumer.
+type oauthBearerTokenSetter interface {
+	SetOAuthBearerToken(kafka.OAuthBearerToken) error
+	SetOAuthBearerTokenFailure(string) error
+}
+
+// startTokenRefresh takes an OAuthBearerTokenRefreshFunc and an
+// oauthBearerTokenSetter. The initial token is fetched and set before
+// returning. Subsequent tokens are fetched and set in the background once 80%
+// of the current token lifetime has elapsed. The returned func stops the
+// background refresh and must be called before the client is closed.
+func startTokenRefresh(fn OAuthBearerTokenRefreshFunc, setter oauthBearerTokenSetter) (func(), error) {
+	token, err := fn()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching oauthbearer token: %s", err)
+	}
+
+	if err := setter.SetOAuthBearerToken(token); err != nil {
+		return nil, fmt.Errorf("error setting oauthbearer token: %s", err)
+	}
+
+	var stop = make(chan struct{})
+	var done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		next := refreshInterval(token.Expiration)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(next):
+			}
+
+			token, err := fn()
+			if err == nil {
+				err = setter.SetOAuthBearerToken(token)
+			}
+
+			if err != nil {
+				setter.SetOAuthBearerTokenFailure(err.Error())
+				next = tokenMinRefreshInterval
+				continue
+			}
+
+			next = refreshInterval(token.Expiration)
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}, nil
+}
+
+// refreshInterval returns the duration after which a token expiring at the
+// specified time should be refreshed.
+func refreshInterval(expiration time.Time) time.Duration {
+	d := time.Until(expiration) * 8 / 10
+	if d < tokenMinRefreshInterval {
+		return tokenMinRefreshInterval
+	}
+
+	return d
+}
+
+// OAuthBearerTokenFromFile returns an OAuthBearerTokenRefreshFunc that reads a
+// JWT from the specified file, e.g. one maintained by a credentials sidecar.
+// The token expiration and principal are taken from the exp and sub claims.
+func OAuthBearerTokenFromFile(path string) OAuthBearerTokenRefreshFunc {
+	return func() (kafka.OAuthBearerToken, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return kafka.OAuthBearerToken{}, err
+		}
+
+		return parseJWT(strings.TrimSpace(string(data)))
+	}
+}
+
+// jwtClaims holds the JWT claims used to populate a kafka.OAuthBearerToken.
+type jwtClaims struct {
+	Exp int64  `json:"exp"`
+	Sub string `json:"sub"`
+}
+
+// parseJWT takes a JWT in the compact serialization form and returns a
+// kafka.OAuthBearerToken. The token signature is not verified.
+func parseJWT(token string) (kafka.OAuthBearerToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return kafka.OAuthBearerToken{}, fmt.Errorf("invalid JWT: expected 3 parts, got %d", len(parts))
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return kafka.OAuthBearerToken{}, fmt.Errorf("invalid JWT payload: %s", err)
+	}
+
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return kafka.OAuthBearerToken{}, fmt.Errorf("invalid JWT claims: %s", err)
+	}
+
+	if claims.Exp == 0 {
+		return kafka.OAuthBearerToken{}, fmt.Errorf("invalid JWT: missing exp claim")
+	}
+
+	return kafka.OAuthBearerToken{
+		TokenValue: token,
+		Expiration: time.Unix(claims.Exp, 0),
+		Principal:  claims.Sub,
+	}, nil
+}
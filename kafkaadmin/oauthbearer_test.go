@@ -0,0 +1,120 @@
+package kafkaadmin
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTokenSetter struct {
+	sync.Mutex
+	tokens   []kafka.OAuthBearerToken
+	failures []string
+}
+
+func (m *mockTokenSetter) SetOAuthBearerToken(t kafka.OAuthBearerToken) error {
+	m.Lock()
+	defer m.Unlock()
+	m.tokens = append(m.tokens, t)
+	return nil
+}
+
+func (m *mockTokenSetter) SetOAuthBearerTokenFailure(s string) error {
+	m.Lock()
+	defer m.Unlock()
+	m.failures = append(m.failures, s)
+	return nil
+}
+
+func testJWT(claims string) string {
+	enc := base64.RawURLEncoding
+	return fmt.Sprintf("%s.%s.%s",
+		enc.EncodeToString([]byte(`{"alg":"none"}`)),
+		enc.EncodeToString([]byte(claims)),
+		enc.EncodeToString([]byte("sig")),
+	)
+}
+
+func TestParseJWT(t *testing.T) {
+	jwt := testJWT(`{"sub":"registry","exp":1700000000}`)
+
+	token, err := parseJWT(jwt)
+	assert.Nil(t, err)
+	assert.Equal(t, jwt, token.TokenValue)
+	assert.Equal(t, "registry", token.Principal)
+	assert.Equal(t, time.Unix(1700000000, 0), token.Expiration)
+
+	// Invalid tokens.
+	for _, s := range []string{
+		"",
+		"a.b",
+		"a.!!!.c",
+		testJWT(`not json`),
+		testJWT(`{"sub":"registry"}`),
+	} {
+		_, err := parseJWT(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestOAuthBearerTokenFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	jwt := testJWT(`{"sub":"registry","exp":1700000000}`)
+	os.WriteFile(path, []byte(jwt+"\n"), 0600)
+
+	token, err := OAuthBearerTokenFromFile(path)()
+	assert.Nil(t, err)
+	assert.Equal(t, jwt, token.TokenValue)
+
+	_, err = OAuthBearerTokenFromFile(path + ".missing")()
+	assert.NotNil(t, err)
+}
+
+func TestStartTokenRefresh(t *testing.T) {
+	defer func(d time.Duration) { tokenMinRefreshInterval = d }(tokenMinRefreshInterval)
+	tokenMinRefreshInterval = time.Millisecond
+
+	// The first token is set before returning; subsequent fetches alternate
+	// between failures and expired tokens to force frequent refreshes.
+	var calls int
+	fn := func() (kafka.OAuthBearerToken, error) {
+		calls++
+		if calls > 1 && calls%2 == 0 {
+			return kafka.OAuthBearerToken{}, errors.New("unavailable")
+		}
+		return kafka.OAuthBearerToken{TokenValue: "token", Expiration: time.Now()}, nil
+	}
+
+	setter := &mockTokenSetter{}
+	stop, err := startTokenRefresh(fn, setter)
+	assert.Nil(t, err)
+
+	setter.Lock()
+	assert.Len(t, setter.tokens, 1)
+	setter.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+	stop()
+	// Stopping is idempotent.
+	stop()
+
+	setter.Lock()
+	defer setter.Unlock()
+	assert.Greater(t, len(setter.tokens), 1)
+	assert.Greater(t, len(setter.failures), 0)
+	assert.Equal(t, "unavailable", setter.failures[0])
+
+	// An initial fetch failure is returned.
+	_, err = startTokenRefresh(func() (kafka.OAuthBearerToken, error) {
+		return kafka.OAuthBearerToken{}, errors.New("unavailable")
+	}, setter)
+	assert.NotNil(t, err)
+}
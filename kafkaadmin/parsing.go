@@ -3,6 +3,7 @@ package kafkaadmin
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 /*
@@ -16,21 +17,34 @@ var (
 )
 
 // stringsToRegex takes a []string of topic names and returns a []*regexp.Regexp.
-// The values are either a string literal and become ^value$ or are regex and
-// compiled then added.
+// The values are either a string literal and become ^value$, a wildcard
+// pattern where * matches any sequence of characters and all other characters
+// are matched literally (e.g. logs_*), or are regex and compiled then added.
 func stringsToRegex(names []string) ([]*regexp.Regexp, error) {
 	var out []*regexp.Regexp
 
-	// Update string literals to ^value$ regex.
-	for n, t := range names {
-		if !containsRegex(t) {
-			names[n] = fmt.Sprintf(`^%s$`, t)
+	for _, t := range names {
+		var pattern string
+
+		switch {
+		// Update wildcards to ^value$ regex where * becomes .* and the literal
+		// segments are quoted.
+		case containsWildcard(t):
+			segments := strings.Split(t, "*")
+			for i, s := range segments {
+				segments[i] = regexp.QuoteMeta(s)
+			}
+			pattern = fmt.Sprintf(`^%s$`, strings.Join(segments, ".*"))
+		// Regex is used as is.
+		case containsRegex(t):
+			pattern = t
+		// Update string literals to ^value$ regex.
+		default:
+			pattern = fmt.Sprintf(`^%s$`, t)
 		}
-	}
 
-	// Compile regex patterns.
-	for _, t := range names {
-		r, err := regexp.Compile(t)
+		// Compile regex patterns.
+		r, err := regexp.Compile(pattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid regex pattern: %s\n", t)
 		}
@@ -41,6 +55,24 @@ func stringsToRegex(names []string) ([]*regexp.Regexp, error) {
 	return out, nil
 }
 
+// containsWildcard takes a topic name string and returns whether or not it
+// should be interpreted as a wildcard pattern; it contains at least one * and
+// otherwise only legal Kafka topic name characters. A * following a . is the
+// regex .* (e.g. test.*), in which case it's not a wildcard pattern.
+func containsWildcard(t string) bool {
+	if !strings.Contains(t, "*") || strings.Contains(t, ".*") {
+		return false
+	}
+
+	for _, c := range strings.ReplaceAll(t, "*", "") {
+		if c != '.' && !topicNormalChar.MatchString(string(c)) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // containsRegex takes a topic name string and returns whether or not
 // it should be interpreted as regex.
 func containsRegex(t string) bool {
@@ -0,0 +1,45 @@
+package kafkaadmin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringsToRegex(t *testing.T) {
+	tests := []struct {
+		name      string
+		matches   []string
+		unmatched []string
+	}{
+		// Literals.
+		{name: "test", matches: []string{"test"}, unmatched: []string{"test1"}},
+		// Wildcards; literal segments are matched literally.
+		{name: "test*", matches: []string{"test", "test1"}, unmatched: []string{"atest"}},
+		{name: "a.b*", matches: []string{"a.b", "a.b1"}, unmatched: []string{"aXb"}},
+		{name: "*.logs", matches: []string{"app.logs"}, unmatched: []string{"appXlogs"}},
+		// Regex.
+		{name: "test.*", matches: []string{"test", "test1"}},
+		{name: ".*", matches: []string{"test", "a.b"}},
+		{name: "test[2-9]", matches: []string{"test2"}, unmatched: []string{"test1"}},
+	}
+
+	for _, tt := range tests {
+		re, err := stringsToRegex([]string{tt.name})
+		assert.Nil(t, err)
+		assert.Len(t, re, 1)
+
+		for _, m := range tt.matches {
+			assert.True(t, re[0].MatchString(m), fmt.Sprintf("%s should match %s", tt.name, m))
+		}
+
+		for _, m := range tt.unmatched {
+			assert.False(t, re[0].MatchString(m), fmt.Sprintf("%s should not match %s", tt.name, m))
+		}
+	}
+
+	// Invalid regex.
+	_, err := stringsToRegex([]string{"test["})
+	assert.NotNil(t, err)
+}
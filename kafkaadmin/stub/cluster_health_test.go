@@ -0,0 +1,65 @@
+package stub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	md := fakeKafkaMetadata()
+	// Under-replicated.
+	md.Topics["test2"].Partitions[1].Isrs = []int32{1003}
+	// Offline.
+	md.Topics["test1"].Partitions[1].Leader = -1
+	md.Topics["test1"].Partitions[1].Isrs = []int32{}
+
+	ts, err := kafkaadmin.TopicStatesFromMetadata(&md)
+	assert.Nil(t, err)
+
+	health := ts.Health()
+
+	offline := kafkaadmin.PartitionHealth{
+		Topic:     "test1",
+		Partition: 1,
+		Leader:    -1,
+		Replicas:  []int32{1002},
+		ISR:       []int32{},
+		OutOfSync: []int32{1002},
+	}
+
+	urp := kafkaadmin.PartitionHealth{
+		Topic:     "test2",
+		Partition: 1,
+		Leader:    1003,
+		Replicas:  []int32{1002, 1003},
+		ISR:       []int32{1003},
+		OutOfSync: []int32{1002},
+	}
+
+	expected := kafkaadmin.ClusterHealth{
+		Topics:            2,
+		Partitions:        4,
+		UnderReplicated:   []kafkaadmin.PartitionHealth{offline, urp},
+		Offline:           []kafkaadmin.PartitionHealth{offline},
+		OutOfSyncReplicas: map[int32]int{1002: 2},
+	}
+
+	assert.Equal(t, expected, health)
+	assert.False(t, health.Healthy())
+}
+
+func TestClusterHealth(t *testing.T) {
+	ka := NewClient()
+
+	health, err := ka.ClusterHealth(context.Background())
+	assert.Nil(t, err)
+
+	assert.True(t, health.Healthy())
+	assert.Equal(t, 2, health.Topics)
+	assert.Equal(t, 4, health.Partitions)
+	assert.Empty(t, health.OutOfSyncReplicas)
+}
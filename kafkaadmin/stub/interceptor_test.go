@@ -0,0 +1,62 @@
+package stub
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+func TestWithInterceptors(t *testing.T) {
+	var calls []string
+
+	// The outer interceptor records calls and passes a value to the inner
+	// interceptor via the context.
+	outer := func(ctx context.Context, method string, call func(context.Context) error) error {
+		calls = append(calls, "outer "+method)
+		return call(context.WithValue(ctx, ctxKey{}, "value"))
+	}
+
+	var errs []error
+	inner := func(ctx context.Context, method string, call func(context.Context) error) error {
+		calls = append(calls, "inner "+method+" "+ctx.Value(ctxKey{}).(string))
+		err := call(ctx)
+		errs = append(errs, err)
+		return err
+	}
+
+	ka := kafkaadmin.WithInterceptors(NewClient(), outer, inner)
+
+	// Results are returned through the interceptors.
+	states, err := ka.DescribeTopics(context.Background(), []string{"test1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test1"}, states.List())
+
+	// As are errors.
+	_, err = ka.DescribeTopics(context.Background(), []string{"test["})
+	assert.NotNil(t, err)
+
+	expected := []string{
+		"outer DescribeTopics",
+		"inner DescribeTopics value",
+		"outer DescribeTopics",
+		"inner DescribeTopics value",
+	}
+	assert.Equal(t, expected, calls)
+	assert.Equal(t, []error{nil, err}, errs)
+
+	// Interceptor errors are returned.
+	errIntercepted := errors.New("intercepted")
+	ka = kafkaadmin.WithInterceptors(NewClient(), func(ctx context.Context, _ string, call func(context.Context) error) error {
+		call(ctx)
+		return errIntercepted
+	})
+
+	_, err = ka.ListBrokers(context.Background())
+	assert.Equal(t, errIntercepted, err)
+}
@@ -2,7 +2,8 @@ package stub
 
 import (
 	"context"
-	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 
@@ -17,6 +18,11 @@ func (s Client) CreateTopic(context.Context, kafkaadmin.CreateTopicConfig) error
 	return nil
 }
 
+func (s Client) CreateTopicIfNotExists(_ context.Context, cfg kafkaadmin.CreateTopicConfig, _ kafkaadmin.TopicExistsBehavior) (bool, error) {
+	_, exists := s.DumpMetadata().Topics[cfg.Name]
+	return !exists, nil
+}
+
 func (s Client) DeleteTopic(context.Context, string) error {
 	return nil
 }
@@ -24,21 +30,17 @@ func (s Client) DeleteTopic(context.Context, string) error {
 func (s Client) DescribeTopics(_ context.Context, names []string) (kafkaadmin.TopicStates, error) {
 	md := s.DumpMetadata()
 
-	re := []*regexp.Regexp{}
-	for _, name := range names {
-		re = append(re, regexp.MustCompile(name))
-	}
-
+	// Filter a copy so that the stored metadata is left intact.
 	topics := map[string]kafka.TopicMetadata{}
 	for name, data := range md.Topics {
-		for _, r := range re {
-			if r.MatchString(name) {
-				topics[name] = data
-			}
-		}
+		topics[name] = data
 	}
-
 	md.Topics = topics
+
+	if err := kafkaadmin.FilterMetadataTopics(&md, names); err != nil {
+		return nil, err
+	}
+
 	return kafkaadmin.TopicStatesFromMetadata(&md)
 }
 
@@ -46,6 +48,19 @@ func (s Client) UnderReplicatedTopics(ctx context.Context) (kafkaadmin.TopicStat
 	return nil, nil
 }
 
+func (s Client) SubscribeTopicStates(ctx context.Context, interval time.Duration, filter []string) <-chan kafkaadmin.TopicEvent {
+	return kafkaadmin.PollTopicStates(ctx, s, interval, filter)
+}
+
+func (s Client) ClusterHealth(ctx context.Context) (kafkaadmin.ClusterHealth, error) {
+	states, err := s.DescribeTopics(ctx, []string{".*"})
+	if err != nil {
+		return kafkaadmin.ClusterHealth{}, err
+	}
+
+	return states.Health(), nil
+}
+
 func (s Client) SetThrottle(context.Context, kafkaadmin.SetThrottleConfig) error {
 	return nil
 }
@@ -80,3 +95,41 @@ func (s Client) GetDynamicConfigs(_ context.Context, _ string, names []string) (
 	}
 	return matched, nil
 }
+
+func (s Client) SetTopicConfigs(context.Context, kafkaadmin.ResourceConfigs) error {
+	return nil
+}
+
+func (s Client) BulkGetDynamicConfigs(_ context.Context, ids []int) (kafkaadmin.ResourceConfigs, kafkaadmin.BrokerErrors) {
+	configs := kafkaadmin.ResourceConfigs{}
+	errs := kafkaadmin.BrokerErrors{}
+
+	for _, id := range ids {
+		if _, exists := s.brokerStates[id]; !exists {
+			errs[strconv.Itoa(id)] = kafkaadmin.ErrNoData
+		}
+	}
+
+	if len(errs) > 0 {
+		return configs, errs
+	}
+
+	return configs, nil
+}
+
+func (s Client) BulkSetDynamicConfigs(_ context.Context, configs kafkaadmin.ResourceConfigs) kafkaadmin.BrokerErrors {
+	errs := kafkaadmin.BrokerErrors{}
+
+	for name := range configs {
+		id, _ := strconv.Atoi(name)
+		if _, exists := s.brokerStates[id]; !exists {
+			errs[name] = kafkaadmin.ErrNoData
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
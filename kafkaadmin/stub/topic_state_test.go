@@ -5,6 +5,7 @@ import (
 
 	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 
+	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +41,66 @@ func TestUnderReplicated(t *testing.T) {
 
 	assert.Equal(t, expected, urp)
 }
+
+func TestTopicStatesDiff(t *testing.T) {
+	md := fakeKafkaMetadata()
+	before, err := kafkaadmin.TopicStatesFromMetadata(&md)
+	assert.Nil(t, err)
+
+	// Build a newer state from a modified copy of the metadata.
+	md = fakeKafkaMetadata()
+	md.Topics = map[string]kafka.TopicMetadata{
+		"test1": md.Topics["test1"],
+		"test3": md.Topics["test2"],
+	}
+
+	test1 := md.Topics["test1"]
+	test1.Partitions = append([]kafka.PartitionMetadata{}, test1.Partitions...)
+	// Partition 0: the leader moved and 1002 dropped from the ISR.
+	test1.Partitions[0].Leader = 1002
+	test1.Partitions[0].Isrs = []int32{1001}
+	// Partition 1: the replica set changed and 1003 joined the ISR.
+	test1.Partitions[1].Replicas = []int32{1002, 1003}
+	test1.Partitions[1].Isrs = []int32{1002, 1003}
+	// Partition 2 was added.
+	test1.Partitions = append(test1.Partitions, kafka.PartitionMetadata{
+		ID:       2,
+		Leader:   1003,
+		Replicas: []int32{1003, 1001},
+		Isrs:     []int32{1003, 1001},
+	})
+	md.Topics["test1"] = test1
+
+	after, err := kafkaadmin.TopicStatesFromMetadata(&md)
+	assert.Nil(t, err)
+
+	diff := before.Diff(after)
+
+	expected := kafkaadmin.TopicStatesDiff{
+		TopicsAdded:   []string{"test3"},
+		TopicsRemoved: []string{"test2"},
+		Topics: map[string]kafkaadmin.TopicStateDiff{
+			"test1": {
+				PartitionsAdded: []int{2},
+				ReplicasChanged: []kafkaadmin.PartitionReplicasChange{
+					{Partition: 1, Before: []int32{1002}, After: []int32{1002, 1003}},
+				},
+				ISRShrunk: []kafkaadmin.PartitionReplicasChange{
+					{Partition: 0, Before: []int32{1001, 1002}, After: []int32{1001}},
+				},
+				ISRExpanded: []kafkaadmin.PartitionReplicasChange{
+					{Partition: 1, Before: []int32{1002}, After: []int32{1002, 1003}},
+				},
+				LeaderMoved: []kafkaadmin.PartitionLeaderChange{
+					{Partition: 0, Before: 1001, After: 1002},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, diff)
+	assert.False(t, diff.Empty())
+
+	// No changes.
+	assert.True(t, before.Diff(before).Empty())
+}
@@ -19,10 +19,21 @@ const (
 type SetThrottleConfig struct {
 	// Topics is a list of all topics that require throttled replica configs.
 	Topics []string
+	// TopicReplicas is an optional mapping of topic to the ThrottledReplicas
+	// to set. All replicas ("*") are throttled for topics that aren't mapped.
+	TopicReplicas map[string]ThrottledReplicas
 	// Brokers is a mapping of broker ID to BrokerThrottleConfig.
 	Brokers map[int]BrokerThrottleConfig
 }
 
+// ThrottledReplicas holds a topic's leader and follower throttled replicas
+// lists in the Kafka partition:broker,... format. An empty list leaves the
+// existing config in place.
+type ThrottledReplicas struct {
+	Leaders   string
+	Followers string
+}
+
 // RemoveThrottleConfig holds lists of all topics and brokers to remove throttles
 // from.
 type RemoveThrottleConfig struct {
@@ -66,7 +77,7 @@ func (c Client) SetThrottle(ctx context.Context, cfg SetThrottleConfig) error {
 	}
 
 	// Update the fetched configs to include the desired new configs.
-	if err := populateTopicThrottleConfigs(cfg.Topics, topicDynamicConfigs); err != nil {
+	if err := populateTopicThrottleConfigs(cfg.Topics, cfg.TopicReplicas, topicDynamicConfigs); err != nil {
 		return ErrSetThrottle{Message: err.Error()}
 	}
 
@@ -114,7 +125,9 @@ func (c Client) SetThrottle(ctx context.Context, cfg SetThrottleConfig) error {
 }
 
 // RemoveThrottle takes a RemoveThrottleConfig that includes an optionally specified
-// list of brokers and topics to remove all throttle configurations from.
+// list of brokers and topics to remove all throttle configurations from. Broker
+// configs are fetched and updated concurrently; if fetching the configs for any
+// broker fails, no throttle configs are removed.
 func (c Client) RemoveThrottle(ctx context.Context, cfg RemoveThrottleConfig) error {
 	var topicDynamicConfigs, brokerDynamicConfigs ResourceConfigs
 	var err error
@@ -129,14 +142,10 @@ func (c Client) RemoveThrottle(ctx context.Context, cfg RemoveThrottleConfig) er
 
 	// Get the named broker ID dynamic configs.
 	if len(cfg.Brokers) > 0 {
-		var brokerIDss []string
-		for _, id := range cfg.Brokers {
-			brokerIDss = append(brokerIDss, fmt.Sprintf("%d", id))
-		}
-
-		brokerDynamicConfigs, err = c.GetDynamicConfigs(ctx, "broker", brokerIDss)
-		if err != nil {
-			return ErrRemoveThrottle{Message: err.Error()}
+		var errs BrokerErrors
+		brokerDynamicConfigs, errs = c.BulkGetDynamicConfigs(ctx, cfg.Brokers)
+		if errs != nil {
+			return ErrRemoveThrottle{Message: errs.Error()}
 		}
 	}
 
@@ -150,40 +159,26 @@ func (c Client) RemoveThrottle(ctx context.Context, cfg RemoveThrottleConfig) er
 		return ErrRemoveThrottle{Message: err.Error()}
 	}
 
-	// Build a new configuration set.
-	var throttleConfigs []kafka.ConfigResource
-
-	// Merge all configs into the global configuration set.
-	for i, resourceConfig := range []ResourceConfigs{topicDynamicConfigs, brokerDynamicConfigs} {
-		for name, configs := range resourceConfig {
-			c := kafka.ConfigResource{
-				Name:   name,
-				Config: kafka.StringMapToConfigEntries(configs, kafka.AlterOperationSet),
-			}
-
-			// Assign the type to the respective config class according to the index.
-			switch i {
-			case 0:
-				c.Type = topicResourceType
-			case 1:
-				c.Type = brokerResourceType
-			}
-
-			throttleConfigs = append(throttleConfigs, c)
+	// Apply the topic configs in sequence.
+	for name, configs := range topicDynamicConfigs {
+		config := kafka.ConfigResource{
+			Type:   topicResourceType,
+			Name:   name,
+			Config: kafka.StringMapToConfigEntries(configs, kafka.AlterOperationSet),
 		}
-	}
 
-	// Apply the configs in sequence.
-	for _, config := range throttleConfigs {
-		// TODO(jamie) perform these in batch once the 'Only one ConfigResource of
-		// type BROKER is allowed per call' error is no longer encountered.
-		// TODO(jamie) review whether the kafka.SetAdminIncremental AlterConfigsAdminOption
-		// actually works here.
 		if _, err = c.c.AlterConfigs(ctx, []kafka.ConfigResource{config}); err != nil {
 			return ErrRemoveThrottle{Message: err.Error()}
 		}
 	}
 
+	// Apply the broker configs.
+	if len(brokerDynamicConfigs) > 0 {
+		if errs := c.BulkSetDynamicConfigs(ctx, brokerDynamicConfigs); errs != nil {
+			return ErrRemoveThrottle{Message: errs.Error()}
+		}
+	}
+
 	return nil
 }
 
@@ -191,8 +186,10 @@ func (c Client) RemoveThrottle(ctx context.Context, cfg RemoveThrottleConfig) er
 // set along with a ResourceConfigs. We need both; the provided ResourceConfigs
 // will only include topics that have at least one preexisting dynamic config.
 // If the topic from the topics list exists in the ResourceConfigs, we append the
-// throttle config. If it doesn't exist, we create the entry.
-func populateTopicThrottleConfigs(topics []string, configs ResourceConfigs) error {
+// throttle config. If it doesn't exist, we create the entry. Topics found in the
+// replicas map are set to their ThrottledReplicas, otherwise all replicas are
+// throttled.
+func populateTopicThrottleConfigs(topics []string, replicas map[string]ThrottledReplicas, configs ResourceConfigs) error {
 	// Remove any topics in the ResourceConfigs that aren't in the topics list.
 	nameSet := map[string]struct{}{}
 	for _, t := range topics {
@@ -207,9 +204,17 @@ func populateTopicThrottleConfigs(topics []string, configs ResourceConfigs) erro
 
 	// Update the configs.
 	for _, topic := range topics {
+		lists := [2]string{"*", "*"}
+		if r, exists := replicas[topic]; exists {
+			lists = [2]string{r.Leaders, r.Followers}
+		}
+
 		// We need to update the leader and follower throttle replicas list.
-		for _, cfgName := range []string{topicThrottledLeadersCfgName, topicThrottledFollowersCfgName} {
-			err := configs.AddConfig(topic, cfgName, "*")
+		for i, cfgName := range []string{topicThrottledLeadersCfgName, topicThrottledFollowersCfgName} {
+			if lists[i] == "" {
+				continue
+			}
+			err := configs.AddConfig(topic, cfgName, lists[i])
 			if err != nil {
 				return err
 			}
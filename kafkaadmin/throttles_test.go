@@ -80,7 +80,7 @@ func TestPopulateTopicThrottleConfigs(t *testing.T) {
 	}
 
 	for i, testCase := range tests {
-		err := populateTopicThrottleConfigs(inputTopics, testCase.input)
+		err := populateTopicThrottleConfigs(inputTopics, nil, testCase.input)
 		// Check the error.
 		assert.Equalf(t, testCase.expectedErr, err, fmt.Sprintf("case %d", i))
 		// Check the output.
@@ -88,6 +88,41 @@ func TestPopulateTopicThrottleConfigs(t *testing.T) {
 	}
 }
 
+func TestPopulateTopicThrottleConfigsReplicas(t *testing.T) {
+	configs := ResourceConfigs{
+		"topic2": map[string]string{
+			"leader.replication.throttled.replicas": "0:1001",
+		},
+	}
+
+	replicas := map[string]ThrottledReplicas{
+		"topic1": {Leaders: "0:1001,1:1002", Followers: "0:1003"},
+		// An empty list leaves the existing config in place.
+		"topic2": {Followers: "2:1004"},
+	}
+
+	err := populateTopicThrottleConfigs([]string{"topic1", "topic2", "topic3"}, replicas, configs)
+	assert.Nil(t, err)
+
+	expected := ResourceConfigs{
+		"topic1": map[string]string{
+			"leader.replication.throttled.replicas":   "0:1001,1:1002",
+			"follower.replication.throttled.replicas": "0:1003",
+		},
+		"topic2": map[string]string{
+			"leader.replication.throttled.replicas":   "0:1001",
+			"follower.replication.throttled.replicas": "2:1004",
+		},
+		// Topics without lists have all replicas throttled.
+		"topic3": map[string]string{
+			"leader.replication.throttled.replicas":   "*",
+			"follower.replication.throttled.replicas": "*",
+		},
+	}
+
+	assert.Equal(t, expected, configs)
+}
+
 func TestClearTopicThrottleConfigs(t *testing.T) {
 	tests := []struct {
 		input       ResourceConfigs
@@ -0,0 +1,179 @@
+package kafkaadmin
+
+import (
+	"sort"
+)
+
+// TopicStatesDiff describes the changes from one TopicStates to another.
+type TopicStatesDiff struct {
+	// Topics that are only present in the newer TopicStates.
+	TopicsAdded []string
+	// Topics that are only present in the older TopicStates.
+	TopicsRemoved []string
+	// Map of topic name to TopicStateDiff for topics present in both TopicStates
+	// that have changes.
+	Topics map[string]TopicStateDiff
+}
+
+// TopicStateDiff describes the changes from one TopicState to another. All
+// changes are ordered by partition ID.
+type TopicStateDiff struct {
+	PartitionsAdded   []int
+	PartitionsRemoved []int
+	// Partitions where the replica set or replica order changed.
+	ReplicasChanged []PartitionReplicasChange
+	// Partitions where any broker left the ISR.
+	ISRShrunk []PartitionReplicasChange
+	// Partitions where any broker joined the ISR.
+	ISRExpanded []PartitionReplicasChange
+	// Partitions where the leader changed.
+	LeaderMoved []PartitionLeaderChange
+}
+
+// PartitionReplicasChange describes a change in a partition replica set or ISR.
+type PartitionReplicasChange struct {
+	Partition int
+	Before    []int32
+	After     []int32
+}
+
+// PartitionLeaderChange describes a change in a partition leader.
+type PartitionLeaderChange struct {
+	Partition int
+	Before    int32
+	After     int32
+}
+
+// Diff takes a newer TopicStates and returns a TopicStatesDiff describing the
+// changes from the TopicStates to the newer TopicStates.
+func (ts TopicStates) Diff(other TopicStates) TopicStatesDiff {
+	diff := TopicStatesDiff{Topics: map[string]TopicStateDiff{}}
+
+	for name, state := range ts {
+		otherState, exists := other[name]
+		if !exists {
+			diff.TopicsRemoved = append(diff.TopicsRemoved, name)
+			continue
+		}
+
+		if d := state.Diff(otherState); !d.Empty() {
+			diff.Topics[name] = d
+		}
+	}
+
+	for name := range other {
+		if _, exists := ts[name]; !exists {
+			diff.TopicsAdded = append(diff.TopicsAdded, name)
+		}
+	}
+
+	sort.Strings(diff.TopicsAdded)
+	sort.Strings(diff.TopicsRemoved)
+
+	return diff
+}
+
+// Empty returns whether the TopicStatesDiff has no changes.
+func (d TopicStatesDiff) Empty() bool {
+	return len(d.TopicsAdded) == 0 && len(d.TopicsRemoved) == 0 && len(d.Topics) == 0
+}
+
+// Diff takes a newer TopicState and returns a TopicStateDiff describing the
+// changes from the TopicState to the newer TopicState.
+func (t TopicState) Diff(other TopicState) TopicStateDiff {
+	var diff TopicStateDiff
+
+	// Traverse partitions in order so that changes are ordered by partition ID.
+	var ids []int
+	for id := range t.PartitionStates {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		before := t.PartitionStates[id]
+		after, exists := other.PartitionStates[id]
+		if !exists {
+			diff.PartitionsRemoved = append(diff.PartitionsRemoved, id)
+			continue
+		}
+
+		if !equalInt32s(before.Replicas, after.Replicas) {
+			diff.ReplicasChanged = append(diff.ReplicasChanged, PartitionReplicasChange{
+				Partition: id,
+				Before:    before.Replicas,
+				After:     after.Replicas,
+			})
+		}
+
+		isrChange := PartitionReplicasChange{Partition: id, Before: before.ISR, After: after.ISR}
+
+		if len(subtractInt32s(before.ISR, after.ISR)) > 0 {
+			diff.ISRShrunk = append(diff.ISRShrunk, isrChange)
+		}
+
+		if len(subtractInt32s(after.ISR, before.ISR)) > 0 {
+			diff.ISRExpanded = append(diff.ISRExpanded, isrChange)
+		}
+
+		if before.Leader != after.Leader {
+			diff.LeaderMoved = append(diff.LeaderMoved, PartitionLeaderChange{
+				Partition: id,
+				Before:    before.Leader,
+				After:     after.Leader,
+			})
+		}
+	}
+
+	for id := range other.PartitionStates {
+		if _, exists := t.PartitionStates[id]; !exists {
+			diff.PartitionsAdded = append(diff.PartitionsAdded, id)
+		}
+	}
+
+	sort.Ints(diff.PartitionsAdded)
+
+	return diff
+}
+
+// Empty returns whether the TopicStateDiff has no changes.
+func (d TopicStateDiff) Empty() bool {
+	return len(d.PartitionsAdded) == 0 &&
+		len(d.PartitionsRemoved) == 0 &&
+		len(d.ReplicasChanged) == 0 &&
+		len(d.ISRShrunk) == 0 &&
+		len(d.ISRExpanded) == 0 &&
+		len(d.LeaderMoved) == 0
+}
+
+// equalInt32s returns whether two []int32 are equal in elements and order.
+func equalInt32s(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// subtractInt32s returns the elements of a that aren't in b.
+func subtractInt32s(a, b []int32) []int32 {
+	var set = make(map[int32]struct{}, len(b))
+	for _, i := range b {
+		set[i] = struct{}{}
+	}
+
+	var diff []int32
+	for _, i := range a {
+		if _, exists := set[i]; !exists {
+			diff = append(diff, i)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,154 @@
+package kafkaadmin
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// TopicEventType is the type of a TopicEvent.
+type TopicEventType string
+
+const (
+	// TopicCreated is sent for topics that weren't present in the previous poll.
+	TopicCreated TopicEventType = "created"
+	// TopicDeleted is sent for topics that are no longer present.
+	TopicDeleted TopicEventType = "deleted"
+	// TopicPartitionsChanged is sent when partitions are added or removed.
+	TopicPartitionsChanged TopicEventType = "partitions_changed"
+	// TopicReplicasChanged is sent when any partition replica set changes, such
+	// as during a reassignment.
+	TopicReplicasChanged TopicEventType = "replicas_changed"
+	// TopicISRChanged is sent when brokers leave or join any partition ISR.
+	TopicISRChanged TopicEventType = "isr_changed"
+)
+
+// TopicEvent is a topic state change sent by SubscribeTopicStates. If Err is
+// non-nil, the poll failed and all other fields are empty.
+type TopicEvent struct {
+	Type  TopicEventType
+	Topic string
+	// The topic state as of the poll, empty for TopicDeleted events.
+	State TopicState
+	// The changes from the previous poll, set for all events other than
+	// TopicCreated and TopicDeleted.
+	Diff TopicStateDiff
+	Err  error
+}
+
+// TopicDescriber describes topics; see DescribeTopics.
+type TopicDescriber interface {
+	DescribeTopics(context.Context, []string) (TopicStates, error)
+}
+
+// SubscribeTopicStates polls the states of topics matching filter every
+// interval and sends a TopicEvent for each change. See PollTopicStates.
+func (c Client) SubscribeTopicStates(ctx context.Context, interval time.Duration, filter []string) <-chan TopicEvent {
+	return PollTopicStates(ctx, c, interval, filter)
+}
+
+// PollTopicStates polls the states of topics matching filter from the
+// TopicDescriber every interval and sends a TopicEvent for each change. The
+// first poll is the baseline that changes are compared to and sends no
+// events. Failed polls send a TopicEvent with Err set and polling continues.
+// The channel is unbuffered and closed once the context is cancelled.
+func PollTopicStates(ctx context.Context, d TopicDescriber, interval time.Duration, filter []string) <-chan TopicEvent {
+	events := make(chan TopicEvent)
+
+	go func() {
+		defer close(events)
+
+		send := func(e TopicEvent) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var previous TopicStates
+
+		for {
+			current, err := d.DescribeTopics(ctx, filter)
+			switch err {
+			case nil:
+			// No matching topics.
+			case ErrNoData:
+				current = NewTopicStates()
+			default:
+				if ctx.Err() != nil || !send(TopicEvent{Err: err}) {
+					return
+				}
+				current = nil
+			}
+
+			if current != nil {
+				if previous != nil {
+					for _, e := range topicEvents(previous, current) {
+						if !send(e) {
+							return
+						}
+					}
+				}
+				previous = current
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+// topicEvents returns the TopicEvents for the changes from one TopicStates to
+// another; created, deleted then changed topics, each ordered by topic name.
+// Leader changes alone don't result in an event.
+func topicEvents(before, after TopicStates) []TopicEvent {
+	var events []TopicEvent
+
+	diff := before.Diff(after)
+
+	for _, name := range diff.TopicsAdded {
+		events = append(events, TopicEvent{Type: TopicCreated, Topic: name, State: after[name]})
+	}
+
+	for _, name := range diff.TopicsRemoved {
+		events = append(events, TopicEvent{Type: TopicDeleted, Topic: name})
+	}
+
+	var changed []string
+	for name := range diff.Topics {
+		changed = append(changed, name)
+	}
+	sort.Strings(changed)
+
+	for _, name := range changed {
+		d := diff.Topics[name]
+		event := TopicEvent{Topic: name, State: after[name], Diff: d}
+
+		if len(d.PartitionsAdded) > 0 || len(d.PartitionsRemoved) > 0 {
+			event.Type = TopicPartitionsChanged
+			events = append(events, event)
+		}
+
+		if len(d.ReplicasChanged) > 0 {
+			event.Type = TopicReplicasChanged
+			events = append(events, event)
+		}
+
+		if len(d.ISRShrunk) > 0 || len(d.ISRExpanded) > 0 {
+			event.Type = TopicISRChanged
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
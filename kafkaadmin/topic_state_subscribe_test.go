@@ -0,0 +1,107 @@
+package kafkaadmin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTopicDescriber returns each of its results in order, then the last
+// result for all subsequent calls.
+type fakeTopicDescriber struct {
+	results []fakeDescribeResult
+}
+
+type fakeDescribeResult struct {
+	states TopicStates
+	err    error
+}
+
+func (f *fakeTopicDescriber) DescribeTopics(context.Context, []string) (TopicStates, error) {
+	r := f.results[0]
+	if len(f.results) > 1 {
+		f.results = f.results[1:]
+	}
+	return r.states, r.err
+}
+
+func TestPollTopicStates(t *testing.T) {
+	test1 := fakeTopicState("test1", 1)
+	test1.setPartitionState(0, []int32{1001, 1002}, []int32{1001, 1002})
+
+	// ISR shrink.
+	test1Shrunk := fakeTopicState("test1", 1)
+	test1Shrunk.setPartitionState(0, []int32{1001, 1002}, []int32{1001})
+
+	test2 := fakeTopicState("test2", 1)
+	test2.setPartitionState(0, []int32{1002, 1003}, []int32{1002, 1003})
+
+	errPoll := errors.New("poll failed")
+
+	d := &fakeTopicDescriber{results: []fakeDescribeResult{
+		// The baseline.
+		{states: TopicStates{"test1": test1}},
+		{err: errPoll},
+		{states: TopicStates{"test1": test1Shrunk, "test2": test2}},
+		{err: ErrNoData},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := PollTopicStates(ctx, d, time.Millisecond, []string{".*"})
+
+	expected := []TopicEvent{
+		{Err: errPoll},
+		{Type: TopicCreated, Topic: "test2", State: test2},
+		{Type: TopicISRChanged, Topic: "test1", State: test1Shrunk, Diff: test1.Diff(test1Shrunk)},
+		{Type: TopicDeleted, Topic: "test1"},
+		{Type: TopicDeleted, Topic: "test2"},
+	}
+
+	for _, e := range expected {
+		select {
+		case got := <-events:
+			assert.Equal(t, e, got)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for event %v", e)
+		}
+	}
+
+	// The channel is closed once the context is cancelled.
+	cancel()
+	for range events {
+	}
+}
+
+func TestTopicEvents(t *testing.T) {
+	before := fakeTopicState("test1", 2)
+	before.setPartitionState(0, []int32{1001, 1002}, []int32{1001, 1002})
+	before.setPartitionState(1, []int32{1002, 1001}, []int32{1002, 1001})
+
+	// A partition added, a reassignment and an ISR expansion.
+	after := fakeTopicState("test1", 3)
+	after.setPartitionState(0, []int32{1001, 1002, 1003}, []int32{1001, 1002, 1003})
+	after.setPartitionState(1, []int32{1002, 1001}, []int32{1002, 1001})
+	after.setPartitionState(2, []int32{1003, 1001}, []int32{1003, 1001})
+
+	events := topicEvents(TopicStates{"test1": before}, TopicStates{"test1": after})
+
+	var types []TopicEventType
+	for _, e := range events {
+		types = append(types, e.Type)
+		assert.Equal(t, before.Diff(after), e.Diff)
+	}
+
+	assert.Equal(t, []TopicEventType{TopicPartitionsChanged, TopicReplicasChanged, TopicISRChanged}, types)
+
+	// Leader changes alone aren't sent.
+	moved := fakeTopicState("test1", 2)
+	moved.setPartitionState(0, []int32{1001, 1002}, []int32{1002, 1001})
+	moved.setPartitionState(1, []int32{1002, 1001}, []int32{1002, 1001})
+
+	assert.Empty(t, topicEvents(TopicStates{"test1": before}, TopicStates{"test1": moved}))
+}
@@ -2,7 +2,9 @@ package kafkaadmin
 
 import (
 	"context"
+	"fmt"
 	"regexp"
+	"sort"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
@@ -22,8 +24,84 @@ type CreateTopicConfig struct {
 // for the reference topic), the inner slice is an []int32 of broker assignments.
 type ReplicaAssignment [][]int32
 
+// TopicExistsBehavior is the CreateTopicIfNotExists behavior when the topic
+// already exists but differs from the requested CreateTopicConfig.
+type TopicExistsBehavior int
+
+const (
+	// TopicExistsError returns an ErrTopicSpecMismatch.
+	TopicExistsError TopicExistsBehavior = iota
+	// TopicExistsIgnore leaves the topic as is.
+	TopicExistsIgnore
+	// TopicExistsReconcileConfigs sets any differing configs. An
+	// ErrTopicSpecMismatch is returned if the partitions or replication factor
+	// differ, since those can't be reconciled by updating configs.
+	TopicExistsReconcileConfigs
+)
+
 // CreateTopic creates a topic.
 func (c Client) CreateTopic(ctx context.Context, cfg CreateTopicConfig) error {
+	_, err := c.createTopic(ctx, cfg)
+	return err
+}
+
+// CreateTopicIfNotExists creates a topic if it doesn't exist. If it does, the
+// topic partitions, replication factor and configs are compared to the
+// CreateTopicConfig and differences are handled according to the
+// TopicExistsBehavior. Replica assignments aren't compared, and configs set on
+// the topic that aren't in the CreateTopicConfig are ignored. Whether or not
+// the topic was created is returned.
+func (c Client) CreateTopicIfNotExists(ctx context.Context, cfg CreateTopicConfig, onExists TopicExistsBehavior) (bool, error) {
+	exists, err := c.topicExists(ctx, cfg.Name)
+	if err != nil {
+		return false, err
+	}
+
+	if !exists {
+		results, err := c.createTopic(ctx, cfg)
+		if err != nil {
+			return false, err
+		}
+
+		for _, r := range results {
+			switch r.Error.Code() {
+			case kafka.ErrNoError:
+				return true, nil
+			// The topic was created concurrently; handle it as existing.
+			case kafka.ErrTopicAlreadyExists:
+			default:
+				return false, r.Error
+			}
+		}
+	}
+
+	if onExists == TopicExistsIgnore {
+		return false, nil
+	}
+
+	states, err := c.DescribeTopics(ctx, []string{literalTopicRegex(cfg.Name)})
+	if err != nil {
+		return false, err
+	}
+
+	configs, err := c.GetDynamicConfigs(ctx, "topic", []string{cfg.Name})
+	if err != nil {
+		return false, err
+	}
+
+	mismatch := topicSpecMismatch(cfg, states[cfg.Name], configs[cfg.Name])
+
+	switch {
+	case mismatch.empty():
+		return false, nil
+	case onExists == TopicExistsReconcileConfigs && mismatch.onlyConfigs():
+		return false, c.SetTopicConfigs(ctx, ResourceConfigs{cfg.Name: cfg.Config})
+	default:
+		return false, mismatch
+	}
+}
+
+func (c Client) createTopic(ctx context.Context, cfg CreateTopicConfig) ([]kafka.TopicResult, error) {
 	spec := kafka.TopicSpecification{
 		Topic:             cfg.Name,
 		NumPartitions:     cfg.Partitions,
@@ -40,9 +118,70 @@ func (c Client) CreateTopic(ctx context.Context, cfg CreateTopicConfig) error {
 
 	topic := []kafka.TopicSpecification{spec}
 
-	_, err := c.c.CreateTopics(ctx, topic)
+	return c.c.CreateTopics(ctx, topic)
+}
 
-	return err
+// topicExists returns whether the named topic exists.
+func (c Client) topicExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.DescribeTopics(ctx, []string{literalTopicRegex(name)})
+	switch err {
+	case nil:
+		return true, nil
+	case ErrNoData:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// literalTopicRegex returns a regex matching only the topic name; topic names
+// may contain '.', which would otherwise be interpreted as regex.
+func literalTopicRegex(name string) string {
+	return fmt.Sprintf("^%s$", regexp.QuoteMeta(name))
+}
+
+// topicSpecMismatch compares a CreateTopicConfig to the TopicState and dynamic
+// configs of an existing topic.
+func topicSpecMismatch(cfg CreateTopicConfig, state TopicState, configs map[string]string) ErrTopicSpecMismatch {
+	mismatch := ErrTopicSpecMismatch{Topic: cfg.Name}
+
+	partitions, replication := cfg.Partitions, cfg.ReplicationFactor
+	if cfg.ReplicaAssignment != nil {
+		partitions = len(cfg.ReplicaAssignment)
+		replication = 0
+		for _, replicas := range cfg.ReplicaAssignment {
+			if len(replicas) > replication {
+				replication = len(replicas)
+			}
+		}
+	}
+
+	// Unset (or broker default, -1) values aren't compared.
+	if partitions > 0 && int32(partitions) != state.Partitions {
+		mismatch.Partitions = fmt.Sprintf("%d != %d", state.Partitions, partitions)
+	}
+
+	if replication > 0 && int32(replication) != state.ReplicationFactor {
+		mismatch.ReplicationFactor = fmt.Sprintf("%d != %d", state.ReplicationFactor, replication)
+	}
+
+	var keys []string
+	for k := range cfg.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		current, set := configs[k]
+		if !set {
+			current = "<unset>"
+		}
+		if current != cfg.Config[k] {
+			mismatch.Configs = append(mismatch.Configs, fmt.Sprintf("%s: %s != %s", k, current, cfg.Config[k]))
+		}
+	}
+
+	return mismatch
 }
 
 // DeleteTopic deletes a topic.
@@ -51,8 +190,11 @@ func (c Client) DeleteTopic(ctx context.Context, name string) error {
 	return err
 }
 
-// DescribeTopics takes a []string of topic names. Topic names can be name literals
-// or optional regex. A TopicStates is returned for all matching topics.
+// DescribeTopics takes a []string of topic names. Topic names can be name
+// literals, wildcards (e.g. logs_*) or optional regex. A TopicStates is returned
+// for all matching topics; the cluster metadata is fetched once and filtered,
+// so callers should prefer passing patterns over fetching all topics and
+// filtering client-side.
 func (c Client) DescribeTopics(ctx context.Context, topics []string) (TopicStates, error) {
 	md, err := c.getMetadata(ctx)
 	if err != nil {
@@ -60,13 +202,10 @@ func (c Client) DescribeTopics(ctx context.Context, topics []string) (TopicState
 	}
 
 	// Strip topics that don't match any of the specified names.
-	topicNamesRegex, err := stringsToRegex(topics)
-	if err != nil {
+	if err := FilterMetadataTopics(md, topics); err != nil {
 		return nil, err
 	}
 
-	filterMatches(md, topicNamesRegex)
-
 	return TopicStatesFromMetadata(md)
 }
 
@@ -101,12 +240,27 @@ func (c Client) getMetadata(ctx context.Context) (*kafka.Metadata, error) {
 	return md, nil
 }
 
+// FilterMetadataTopics takes a *kafka.Metadata and a []string of topic names
+// and removes all topics from the metadata that don't match any of the names.
+// Names are interpreted as in DescribeTopics.
+func FilterMetadataTopics(md *kafka.Metadata, topics []string) error {
+	topicNamesRegex, err := stringsToRegex(topics)
+	if err != nil {
+		return err
+	}
+
+	filterMatches(md, topicNamesRegex)
+
+	return nil
+}
+
 func filterMatches(md *kafka.Metadata, re []*regexp.Regexp) {
 	for topic := range md.Topics {
 		var keep bool
 		for _, r := range re {
 			if r.MatchString(topic) {
 				keep = true
+				break
 			}
 		}
 		if !keep {
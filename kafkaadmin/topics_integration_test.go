@@ -110,3 +110,50 @@ func TestDeleteTopic(t *testing.T) {
 	assert.Equal(t, "no data returned", err.Error())
 	assert.Equal(t, 0, len(ts))
 }
+
+func TestCreateTopicIfNotExists(t *testing.T) {
+	ctx, ka := testKafkaAdminClient(t)
+
+	topic := fmt.Sprintf("%s-if-not-exists", testIntegrationTestTopicName)
+	defer ka.DeleteTopic(ctx, topic)
+
+	cfg := CreateTopicConfig{
+		Name:              topic,
+		Partitions:        1,
+		ReplicationFactor: 2,
+		Config:            map[string]string{"flush.ms": "1234"},
+	}
+
+	// The topic is created.
+	created, err := ka.CreateTopicIfNotExists(ctx, cfg, TopicExistsError)
+	assert.Nil(t, err)
+	assert.True(t, created)
+
+	time.Sleep(250 * time.Millisecond)
+
+	// Re-running with the same config is a no-op.
+	created, err = ka.CreateTopicIfNotExists(ctx, cfg, TopicExistsError)
+	assert.Nil(t, err)
+	assert.False(t, created)
+
+	// Differing configs.
+	cfg.Config = map[string]string{"flush.ms": "5678"}
+
+	_, err = ka.CreateTopicIfNotExists(ctx, cfg, TopicExistsError)
+	assert.IsType(t, ErrTopicSpecMismatch{}, err)
+
+	_, err = ka.CreateTopicIfNotExists(ctx, cfg, TopicExistsIgnore)
+	assert.Nil(t, err)
+
+	_, err = ka.CreateTopicIfNotExists(ctx, cfg, TopicExistsReconcileConfigs)
+	assert.Nil(t, err)
+
+	topicConfigs, err := ka.GetConfigs(ctx, "topic", []string{topic})
+	assert.Nil(t, err)
+	assert.Equal(t, "5678", topicConfigs[topic]["flush.ms"])
+
+	// Differing partitions can't be reconciled.
+	cfg.Partitions = 2
+	_, err = ka.CreateTopicIfNotExists(ctx, cfg, TopicExistsReconcileConfigs)
+	assert.IsType(t, ErrTopicSpecMismatch{}, err)
+}
@@ -1,6 +1,7 @@
 package kafkaadmin
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
@@ -32,6 +33,48 @@ func TestTopicStatesFromMetadata(t *testing.T) {
 	assert.Equal(t, expected, ts)
 }
 
+func TestFilterMetadataTopics(t *testing.T) {
+	tests := []struct {
+		topics   []string
+		expected []string
+	}{
+		// Literals are matched exactly.
+		{topics: []string{"test"}, expected: []string{}},
+		{topics: []string{"test1"}, expected: []string{"test1"}},
+		// Wildcards.
+		{topics: []string{"*"}, expected: []string{"test1", "test2"}},
+		{topics: []string{"test*"}, expected: []string{"test1", "test2"}},
+		{topics: []string{"*2"}, expected: []string{"test2"}},
+		{topics: []string{"t*t1"}, expected: []string{"test1"}},
+		// Regex.
+		{topics: []string{".*"}, expected: []string{"test1", "test2"}},
+		{topics: []string{"test[2-9]"}, expected: []string{"test2"}},
+		// Multiple patterns.
+		{topics: []string{"test1", "*2"}, expected: []string{"test1", "test2"}},
+	}
+
+	for _, tt := range tests {
+		md := fakeKafkaMetadata()
+		err := FilterMetadataTopics(md, tt.topics)
+		assert.Nil(t, err)
+
+		names := []string{}
+		for name := range md.Topics {
+			names = append(names, name)
+		}
+		assert.ElementsMatch(t, tt.expected, names, fmt.Sprintf("topics %v", tt.topics))
+	}
+
+	// Invalid regex.
+	err := FilterMetadataTopics(fakeKafkaMetadata(), []string{"test["})
+	assert.NotNil(t, err)
+
+	// Names aren't modified.
+	names := []string{"test1"}
+	FilterMetadataTopics(fakeKafkaMetadata(), names)
+	assert.Equal(t, []string{"test1"}, names)
+}
+
 // fakeTopicState takes a topic name and desired number of partitions and returns
 // a TopicState. Note that the PartitionStates are left empty; those are to be
 // filled as needed in each test.
@@ -129,3 +172,39 @@ func fakeKafkaMetadata() *kafka.Metadata {
 		},
 	}
 }
+
+func TestTopicSpecMismatch(t *testing.T) {
+	state := fakeTopicState("test1", 2)
+	configs := map[string]string{"retention.ms": "1000"}
+
+	// Matching.
+	cfg := CreateTopicConfig{
+		Name:              "test1",
+		Partitions:        2,
+		ReplicationFactor: 2,
+		Config:            map[string]string{"retention.ms": "1000"},
+	}
+	mismatch := topicSpecMismatch(cfg, state, configs)
+	assert.True(t, mismatch.empty())
+
+	// Unset values aren't compared.
+	mismatch = topicSpecMismatch(CreateTopicConfig{Name: "test1", ReplicationFactor: -1}, state, configs)
+	assert.True(t, mismatch.empty())
+
+	// Differing configs.
+	cfg.Config = map[string]string{"retention.ms": "2000", "flush.ms": "10"}
+	mismatch = topicSpecMismatch(cfg, state, configs)
+	assert.True(t, mismatch.onlyConfigs())
+	assert.Equal(t, []string{"flush.ms: <unset> != 10", "retention.ms: 1000 != 2000"}, mismatch.Configs)
+
+	// Differing partitions and replication factor, from a replica assignment.
+	cfg = CreateTopicConfig{
+		Name:              "test1",
+		ReplicaAssignment: ReplicaAssignment{{1001, 1002, 1003}, {1002, 1003, 1001}, {1003, 1001, 1002}},
+	}
+	mismatch = topicSpecMismatch(cfg, state, configs)
+	assert.False(t, mismatch.onlyConfigs())
+
+	expected := "topic test1 exists with a different configuration: partitions: 2 != 3, replication factor: 2 != 3"
+	assert.Equal(t, expected, mismatch.Error())
+}
@@ -5,6 +5,7 @@ package datadog
 import (
 	"fmt"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
@@ -29,66 +30,171 @@ type Config struct {
 	// BrokerIDTag is the host tag name for Kafka broker IDs.
 	BrokerIDTag string
 	// InstanceTypeTag is the tag name for the kafka broker's instance type.
+	// If empty, instance types aren't populated and are expected to be
+	// resolved by other means.
 	InstanceTypeTag string
+	// CapacityTag is the optional tag name for the kafka broker's network
+	// capacity in MB/s.
+	CapacityTag string
 	// MetricsWindow specifies the window size of timeseries data to evaluate
-	// in seconds. All values for the window are averaged.
+	// in seconds. All values for the window are reduced to a single value with
+	// the Aggregation function.
 	MetricsWindow int
+	// Aggregation is the function used to reduce the values for the window:
+	// avg (the default if unset), max or p95.
+	Aggregation string
+	// NetworkTXUnits and NetworkRXUnits are the units of the per-second values
+	// returned by the respective queries: bytes (the default if unset), bits
+	// or kb. Values are normalized to MB/s.
+	NetworkTXUnits string
+	NetworkRXUnits string
+	// TieredUploadQuery is an optional query string that should return the
+	// tiered storage segment upload bandwidth by host for the reference Kafka
+	// brokers. Brokers without a series are assumed to have no uploads.
+	TieredUploadQuery string
+	// TieredUploadUnits is the units of the values returned by the
+	// TieredUploadQuery; see NetworkTXUnits.
+	TieredUploadUnits string
+}
+
+// Aggregation functions.
+const (
+	AggregationAvg = "avg"
+	AggregationMax = "max"
+	AggregationP95 = "p95"
+)
+
+// ValidAggregation returns whether s is a supported Aggregation.
+func ValidAggregation(s string) bool {
+	switch s {
+	case AggregationAvg, AggregationMax, AggregationP95:
+		return true
+	}
+	return false
 }
 
 type ddHandler struct {
-	c               *dd.Client
-	netTXQuery      string
-	netRXQuery      string
-	brokerIDTag     string
-	instanceTypeTag string
-	metricsWindow   int
-	tagCache        map[string][]string
-	keysRegex       *regexp.Regexp
-	redactionSub    []byte
+	// The client and keysRegex are replaced when credentials are updated.
+	mu                sync.RWMutex
+	c                 *dd.Client
+	netTXQuery        string
+	netRXQuery        string
+	tieredUploadQuery string
+	brokerIDTag       string
+	instanceTypeTag   string
+	capacityTag       string
+	metricsWindow     int
+	aggregation       string
+	netTXUnits        string
+	netRXUnits        string
+	tieredUploadUnits string
+	tagCache          map[string][]string
+	keysRegex         *regexp.Regexp
+	redactionSub      []byte
 }
 
 // NewHandler takes a *Config and returns a Handler, along with any credential
 // validation errors. Further backends can be supported with a type switch
 // and some other changes.
 func NewHandler(c *Config) (kafkametrics.Handler, error) {
+	aggregation := c.Aggregation
+	if aggregation == "" {
+		aggregation = AggregationAvg
+	}
+
+	if !ValidAggregation(aggregation) {
+		return nil, fmt.Errorf("invalid metrics aggregation %q; must be one of avg, max, p95", aggregation)
+	}
+
+	units := [3]string{c.NetworkTXUnits, c.NetworkRXUnits, c.TieredUploadUnits}
+	for i, u := range units {
+		if u == "" {
+			units[i] = kafkametrics.UnitBytes
+		} else if !kafkametrics.ValidUnit(u) {
+			return nil, fmt.Errorf("invalid network units %q; must be one of bytes, bits, kb", u)
+		}
+	}
+
+	var tieredUploadQuery string
+	if c.TieredUploadQuery != "" {
+		tieredUploadQuery = windowQuery(c.TieredUploadQuery, aggregation, c.MetricsWindow)
+	}
+
+	h := &ddHandler{
+		netTXQuery:        windowQuery(c.NetworkTXQuery, aggregation, c.MetricsWindow),
+		netRXQuery:        windowQuery(c.NetworkRXQuery, aggregation, c.MetricsWindow),
+		tieredUploadQuery: tieredUploadQuery,
+		metricsWindow:     c.MetricsWindow,
+		aggregation:       aggregation,
+		netTXUnits:        units[0],
+		netRXUnits:        units[1],
+		tieredUploadUnits: units[2],
+		brokerIDTag:       c.BrokerIDTag,
+		instanceTypeTag:   c.InstanceTypeTag,
+		capacityTag:       c.CapacityTag,
+		tagCache:          make(map[string][]string),
+		redactionSub:      []byte("xxx"),
+	}
+
+	if err := h.UpdateCredentials(c.APIKey, c.AppKey); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// windowQuery takes a query, Aggregation and window size in seconds and returns
+// the query to request. The avg and max aggregations are rolled up over the
+// window by Datadog. Percentiles can't be rolled up, so the points for the
+// window are requested and reduced by brokersFromSeries.
+func windowQuery(query, aggregation string, window int) string {
+	if aggregation == AggregationP95 {
+		return query
+	}
+
+	return fmt.Sprintf("%s.rollup(%s, %d)", query, aggregation, window)
+}
+
+// UpdateCredentials implements the kafkametrics.CredentialsUpdater interface.
+// The keys are validated with the Datadog API before being used.
+func (h *ddHandler) UpdateCredentials(apiKey, appKey string) error {
 	// The underlying client sometimes returns API errors with full dd URL,
 	// including parameterized app/api keys. Until an upstream improvement
 	// is done, we'll just brute force a redaction via string match/sub in all
 	// wrapped errors from the client.
-	keysRegex := regexp.MustCompile(fmt.Sprintf("%s|%s", c.APIKey, c.AppKey))
+	keysRegex := regexp.MustCompile(fmt.Sprintf("%s|%s", apiKey, appKey))
 
-	h := &ddHandler{
-		netTXQuery:      fmt.Sprintf("%s.rollup(avg, %d)", c.NetworkTXQuery, c.MetricsWindow),
-		netRXQuery:      fmt.Sprintf("%s.rollup(avg, %d)", c.NetworkRXQuery, c.MetricsWindow),
-		metricsWindow:   c.MetricsWindow,
-		brokerIDTag:     c.BrokerIDTag,
-		instanceTypeTag: c.InstanceTypeTag,
-		tagCache:        make(map[string][]string),
-		keysRegex:       keysRegex,
-		redactionSub:    []byte("xxx"),
-	}
-
-	client := dd.NewClient(c.APIKey, c.AppKey)
+	client := dd.NewClient(apiKey, appKey)
 
 	// Validate.
 	ok, err := client.Validate()
 	if err != nil {
-		return nil, &kafkametrics.APIError{
+		return &kafkametrics.APIError{
 			Request: "validate credentials",
-			Message: h.scrubbedErrorText(err),
+			Message: string(keysRegex.ReplaceAll([]byte(err.Error()), h.redactionSub)),
 		}
 	}
 
 	if !ok {
-		return nil, &kafkametrics.APIError{
+		return &kafkametrics.APIError{
 			Request: "validate credentials",
 			Message: "invalid API or app key",
 		}
 	}
 
-	h.c = client
+	h.mu.Lock()
+	h.c, h.keysRegex = client, keysRegex
+	h.mu.Unlock()
 
-	return h, nil
+	return nil
+}
+
+// client returns the current Datadog client.
+func (h *ddHandler) client() *dd.Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.c
 }
 
 // PostEvent posts an event to the Datadog API.
@@ -99,10 +205,41 @@ func (h *ddHandler) PostEvent(e *kafkametrics.Event) error {
 		Tags:  e.Tags,
 	}
 
-	_, err := h.c.PostEvent(m)
+	if e.AlertType != "" {
+		m.AlertType = &e.AlertType
+	}
+
+	_, err := h.client().PostEvent(m)
 	return err
 }
 
+// PostMetrics submits the metrics to the Datadog API as gauge series.
+func (h *ddHandler) PostMetrics(m []*kafkametrics.Metric) error {
+	return h.client().PostMetrics(toSeries(m))
+}
+
+// toSeries takes a []*kafkametrics.Metric and returns a single-point gauge
+// dd.Metric for each.
+func toSeries(m []*kafkametrics.Metric) []dd.Metric {
+	gauge := "gauge"
+	series := make([]dd.Metric, 0, len(m))
+
+	for _, metric := range m {
+		name := metric.Name
+		ts := float64(metric.Time.Unix())
+		value := metric.Value
+
+		series = append(series, dd.Metric{
+			Metric: &name,
+			Points: []dd.DataPoint{{&ts, &value}},
+			Type:   &gauge,
+			Tags:   metric.Tags,
+		})
+	}
+
+	return series
+}
+
 // GetMetrics requests broker metrics and metadata from the Datadog API and
 // returns a BrokerMetrics. If any errors are encountered (i.e. complete
 // metadata for a given broker can't be retrieved), the broker will not
@@ -111,13 +248,17 @@ func (h *ddHandler) PostEvent(e *kafkametrics.Event) error {
 func (h *ddHandler) GetMetrics() (kafkametrics.BrokerMetrics, []error) {
 	var errors []error
 	var mergedBrokerList []*kafkametrics.Broker
+	// Broker tags found in the series scopes.
+	scopes := map[string][]string{}
 
 	start := time.Now().Add(-time.Duration(h.metricsWindow) * time.Second).Unix()
 
 	// Get network metrics for tx and rx.
 	var lastLen int
+	units := []string{h.netTXUnits, h.netRXUnits}
+
 	for i, query := range []string{h.netTXQuery, h.netRXQuery} {
-		series, err := h.c.QueryMetrics(start, time.Now().Unix(), query)
+		series, err := h.client().QueryMetrics(start, time.Now().Unix(), query)
 		if err != nil {
 			return nil, []error{&kafkametrics.APIError{
 				Request: "metrics query",
@@ -131,9 +272,13 @@ func (h *ddHandler) GetMetrics() (kafkametrics.BrokerMetrics, []error) {
 			}}
 		}
 
+		for host, tags := range scopeTags(series, h.brokerIDTag) {
+			scopes[host] = tags
+		}
+
 		// Get a []*kafkametrics.Broker from the series. Brokers with missing
 		// points are excluded from blist.
-		blist, errs := brokersFromSeries(series, i)
+		blist, errs := brokersFromSeries(series, i, h.aggregation, units[i])
 		if errs != nil {
 			errors = append(errors, errs...)
 		}
@@ -151,10 +296,30 @@ func (h *ddHandler) GetMetrics() (kafkametrics.BrokerMetrics, []error) {
 		mergedBrokerList = mergeBrokerLists(mergedBrokerList, blist)
 	}
 
+	// Get the optional tiered storage upload metrics. No series are returned
+	// where no brokers are uploading segments, so only a failed request is
+	// an error.
+	if h.tieredUploadQuery != "" {
+		series, err := h.client().QueryMetrics(start, time.Now().Unix(), h.tieredUploadQuery)
+		if err != nil {
+			return nil, []error{&kafkametrics.APIError{
+				Request: "tiered storage upload metrics query",
+				Message: h.scrubbedErrorText(err),
+			}}
+		}
+
+		blist, errs := brokersFromSeries(series, 2, h.aggregation, h.tieredUploadUnits)
+		if errs != nil {
+			errors = append(errors, errs...)
+		}
+
+		mergeTieredUploads(mergedBrokerList, blist)
+	}
+
 	// The []*kafkametrics.Broker only contains hostnames and the network tx
 	// metric. Fetch the rest of the required metadata and construct a
 	// kafkametrics.BrokerMetrics.
-	bm, errs := h.brokerMetricsFromList(mergedBrokerList)
+	bm, errs := h.brokerMetricsFromList(mergedBrokerList, scopes)
 	if errs != nil {
 		errors = append(errors, errs...)
 	}
@@ -165,5 +330,8 @@ func (h *ddHandler) GetMetrics() (kafkametrics.BrokerMetrics, []error) {
 // scrubbedErrorText takes an error and returns the message
 // string, scrubbed of API and app keys.
 func (h *ddHandler) scrubbedErrorText(e error) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
 	return string(h.keysRegex.ReplaceAll([]byte(e.Error()), h.redactionSub))
 }
@@ -5,6 +5,9 @@ package datadog
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
 
 	dd "github.com/zorkian/go-datadog-api"
 )
@@ -16,7 +19,7 @@ import (
 func TestBrokersFromSeries(t *testing.T) {
 	// Test with expected input.
 	series := stubSeries()
-	bs, err := brokersFromSeries(series, 0)
+	bs, err := brokersFromSeries(series, 0, AggregationAvg, kafkametrics.UnitBytes)
 
 	if err != nil {
 		t.Fatal(err)
@@ -26,9 +29,23 @@ func TestBrokersFromSeries(t *testing.T) {
 		t.Errorf("Expected broker slice len 5, got %d\n", len(bs))
 	}
 
+	// Values are normalized to MB/s.
+	expected := map[string]float64{
+		kafkametrics.UnitBytes: 1024,
+		kafkametrics.UnitBits:  128,
+		kafkametrics.UnitKB:    1048576,
+	}
+
+	for unit, e := range expected {
+		bs, _ := brokersFromSeries(series, 1, AggregationAvg, unit)
+		if bs[0].NetRX != e {
+			t.Errorf("[%s] Expected NetRX %.2f, got %.2f", unit, e, bs[0].NetRX)
+		}
+	}
+
 	// Test with unexpected input.
 	series = stubSeriesWithoutPoints()
-	bs, err = brokersFromSeries(series, 0)
+	bs, err = brokersFromSeries(series, 0, AggregationAvg, kafkametrics.UnitBytes)
 	if err == nil {
 		t.Error("Expected error")
 	}
@@ -38,6 +55,48 @@ func TestBrokersFromSeries(t *testing.T) {
 	}
 }
 
+func TestAggregatePoints(t *testing.T) {
+	var points []dd.DataPoint
+	for i := 1; i <= 20; i++ {
+		v := float64(i)
+		points = append(points, dd.DataPoint{nil, &v})
+	}
+	// Points without a value are ignored.
+	points = append(points, dd.DataPoint{nil, nil})
+
+	expected := map[string]float64{
+		AggregationAvg: 10.5,
+		AggregationMax: 20,
+		AggregationP95: 19,
+	}
+
+	for aggregation, e := range expected {
+		if v, ok := aggregatePoints(points, aggregation); !ok || v != e {
+			t.Errorf("[%s] Expected %.2f, got %.2f", aggregation, e, v)
+		}
+	}
+
+	if _, ok := aggregatePoints([]dd.DataPoint{{nil, nil}}, AggregationAvg); ok {
+		t.Error("Expected false bool for points without values")
+	}
+}
+
+func TestWindowQuery(t *testing.T) {
+	q := "avg:system.net.bytes_sent{service:kafka} by {host}"
+
+	expected := map[string]string{
+		AggregationAvg: q + ".rollup(avg, 120)",
+		AggregationMax: q + ".rollup(max, 120)",
+		AggregationP95: q,
+	}
+
+	for aggregation, e := range expected {
+		if got := windowQuery(q, aggregation, 120); got != e {
+			t.Errorf("[%s] Expected %s, got %s", aggregation, e, got)
+		}
+	}
+}
+
 func stubSeries() []dd.Series {
 	ss := []dd.Series{}
 	var f1 = 0.00
@@ -69,3 +128,35 @@ func stubSeriesWithoutPoints() []dd.Series {
 
 	return ss
 }
+
+func TestToSeries(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	series := toSeries([]*kafkametrics.Metric{
+		{Name: "autothrottle.throttle.rate", Value: 50, Time: ts, Tags: []string{"broker_id:1001", "role:leader"}},
+		{Name: "autothrottle.interval.duration", Value: 1.5, Time: ts},
+	})
+
+	if len(series) != 2 {
+		t.Fatalf("Expected 2 series, got %d", len(series))
+	}
+
+	expected := []struct {
+		name  string
+		value float64
+		tags  int
+	}{
+		{"autothrottle.throttle.rate", 50, 2},
+		{"autothrottle.interval.duration", 1.5, 0},
+	}
+
+	for i, e := range expected {
+		s := series[i]
+		if *s.Metric != e.name || *s.Type != "gauge" || len(s.Tags) != e.tags {
+			t.Errorf("Unexpected series %s type %s tags %v", *s.Metric, *s.Type, s.Tags)
+		}
+
+		if len(s.Points) != 1 || *s.Points[0][0] != 1700000000 || *s.Points[0][1] != e.value {
+			t.Errorf("Expected a single point [1700000000, %.2f] for %s", e.value, e.name)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package datadog
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -11,18 +13,21 @@ import (
 	dd "github.com/zorkian/go-datadog-api"
 )
 
-// brokersFromSeries takes a []dd.Series an int desciptor for the metric
-// type and returns a []*kafkametrics.Broker. If for some reason points were
-// not returned for a broker, it's excluded from the []*kafkametrics.Broker
-// and an error is populated in the return []error.
-func brokersFromSeries(s []dd.Series, metric int) ([]*kafkametrics.Broker, []error) {
+// brokersFromSeries takes a []dd.Series, an int desciptor for the metric
+// type, an Aggregation and the unit of the series values and returns a
+// []*kafkametrics.Broker, with the points of each series reduced to a single
+// value with the Aggregation and normalized to MB/s. If for some
+// reason points were not returned for a broker, it's excluded from the
+// []*kafkametrics.Broker and an error is populated in the return []error.
+func brokersFromSeries(s []dd.Series, metric int, aggregation, unit string) ([]*kafkametrics.Broker, []error) {
 	bs := []*kafkametrics.Broker{}
 	var errors []error
 
 	for _, ts := range s {
 		host := tagValFromScope(ts.GetScope(), "host")
 
-		if len(ts.Points) == 0 {
+		v, ok := aggregatePoints(ts.Points, aggregation)
+		if !ok {
 			errors = append(errors, &kafkametrics.PartialResults{
 				Message: fmt.Sprintf("No points for host %s", host),
 			})
@@ -35,9 +40,11 @@ func brokersFromSeries(s []dd.Series, metric int) ([]*kafkametrics.Broker, []err
 
 		switch metric {
 		case 0:
-			b.NetTX = *ts.Points[0][1] / 1024 / 1024
+			b.NetTX = kafkametrics.ToMB(v, unit)
 		case 1:
-			b.NetRX = *ts.Points[0][1] / 1024 / 1024
+			b.NetRX = kafkametrics.ToMB(v, unit)
+		case 2:
+			b.TieredUpload = kafkametrics.ToMB(v, unit)
 		}
 
 		bs = append(bs, b)
@@ -46,6 +53,42 @@ func brokersFromSeries(s []dd.Series, metric int) ([]*kafkametrics.Broker, []err
 	return bs, errors
 }
 
+// aggregatePoints takes a []dd.DataPoint and an Aggregation and returns the
+// point values reduced with the Aggregation. Points without a value are
+// ignored. A false bool is returned if no points have a value.
+func aggregatePoints(points []dd.DataPoint, aggregation string) (float64, bool) {
+	var values []float64
+	for _, p := range points {
+		if p[1] != nil {
+			values = append(values, *p[1])
+		}
+	}
+
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	switch aggregation {
+	case AggregationMax:
+		max := values[0]
+		for _, v := range values[1:] {
+			max = math.Max(max, v)
+		}
+		return max, true
+	case AggregationP95:
+		// Nearest-rank percentile.
+		sort.Float64s(values)
+		rank := int(math.Ceil(0.95 * float64(len(values))))
+		return values[rank-1], true
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), true
+	}
+}
+
 // mergeBrokerLists takes a destination and source []*kafkametrics.Broker
 // and adds/updates source brokers into the destination list, returning
 // a merged copy.
@@ -72,6 +115,21 @@ func mergeBrokerLists(dst, src []*kafkametrics.Broker) []*kafkametrics.Broker {
 	return dst
 }
 
+// mergeTieredUploads takes a destination and source []*kafkametrics.Broker
+// and sets the tiered storage upload bandwidth of each destination broker
+// from the source broker with the same host. Source brokers missing from the
+// destination list, i.e. without network metrics, are ignored.
+func mergeTieredUploads(dst, src []*kafkametrics.Broker) {
+	m := map[string]float64{}
+	for _, b := range src {
+		m[b.Host] = b.TieredUpload
+	}
+
+	for _, b := range dst {
+		b.TieredUpload = m[b.Host]
+	}
+}
+
 // updateBroker takes a destination and source broker and merges the
 // source broker metrics values to the destination if the destiation
 // are default values.
@@ -85,19 +143,20 @@ func updateBroker(dst, src *kafkametrics.Broker) {
 	}
 }
 
-// brokerMetricsFromList takes a *[]kafkametrics.Broker and fetches relevant
-// host tags for all brokers in the list, returning a BrokerMetrics.
-func (h *ddHandler) brokerMetricsFromList(l []*kafkametrics.Broker) (kafkametrics.BrokerMetrics, []error) {
+// brokerMetricsFromList takes a *[]kafkametrics.Broker and a map of hosts to
+// tags found in the metrics series scopes and fetches relevant host tags for
+// all brokers in the list, returning a BrokerMetrics.
+func (h *ddHandler) brokerMetricsFromList(l []*kafkametrics.Broker, scopes map[string][]string) (kafkametrics.BrokerMetrics, []error) {
 	var errors []error
 	// Get host tags for brokers
 	// in the list.
-	tags, errs := h.getHostTagMap(l)
+	tags, errs := h.getHostTagMap(l, scopes)
 	if errs != nil {
 		errors = append(errors, errs...)
 	}
 
 	brokers := kafkametrics.BrokerMetrics{}
-	errs = populateFromTagMap(brokers, h.tagCache, tags, h.brokerIDTag, h.instanceTypeTag)
+	errs = populateFromTagMap(brokers, h.tagCache, tags, h.brokerIDTag, h.instanceTypeTag, h.capacityTag)
 	if errs != nil {
 		errors = append(errors, errs...)
 	}
@@ -105,15 +164,23 @@ func (h *ddHandler) brokerMetricsFromList(l []*kafkametrics.Broker) (kafkametric
 	return brokers, errors
 }
 
-// getHostTagMap takes a []*kafkametrics.Broker and fetches  host tags for
-// each. If no errors are encountered, a map[*kafkametrics.Broker][]string
-// holding the received tags is returned.
-func (h *ddHandler) getHostTagMap(l []*kafkametrics.Broker) (map[*kafkametrics.Broker][]string, []error) {
+// getHostTagMap takes a []*kafkametrics.Broker and a map of hosts to tags
+// found in the metrics series scopes and fetches host tags for each broker
+// without scope tags. If no errors are encountered, a
+// map[*kafkametrics.Broker][]string holding the received tags is returned.
+func (h *ddHandler) getHostTagMap(l []*kafkametrics.Broker, scopes map[string][]string) (map[*kafkametrics.Broker][]string, []error) {
 	var errors []error
 
 	brokers := map[*kafkametrics.Broker][]string{}
 	// Get broker IDs for each host, populate into a BrokerMetrics.
 	for _, b := range l {
+		// Tags returned with the metrics series, e.g. where the queries are
+		// grouped by the broker ID tag, don't require a host tags request.
+		if st, exists := scopes[b.Host]; exists {
+			brokers[b] = st
+			continue
+		}
+
 		// Check if we already have this broker's metadata.
 		ht, cached := h.tagCache[b.Host]
 
@@ -121,7 +188,7 @@ func (h *ddHandler) getHostTagMap(l []*kafkametrics.Broker) (map[*kafkametrics.B
 			brokers[b] = ht
 		} else {
 			// Else fetch it.
-			ht, err := h.c.GetHostTags(b.Host, "")
+			ht, err := h.client().GetHostTags(b.Host, "")
 			if err != nil {
 				errors = append(errors, &kafkametrics.APIError{
 					Request: "host tags",
@@ -139,17 +206,19 @@ func (h *ddHandler) getHostTagMap(l []*kafkametrics.Broker) (map[*kafkametrics.B
 
 // populateFromTagMap takes a kafkametrics.BrokerMetrics, map of broker
 // IDs to []string host tags that functions as a cache, a map of brokers
-// to []string unparsed host tag key:value pairs, and a broker ID tag key
-// populates the kafkametrics.BrokerMetrics with tags of interest.
-// An error describing any missing tags is returned.
+// to []string unparsed host tag key:value pairs, a broker ID tag key and the
+// optional instance type and capacity tag keys and populates the
+// kafkametrics.BrokerMetrics with tags of interest. An error describing any
+// missing tags is returned. The capacity tag is optional for each broker.
 func populateFromTagMap(
 	bm kafkametrics.BrokerMetrics,
 	c map[string][]string,
 	t map[*kafkametrics.Broker][]string,
 	btag string,
 	instanceTypeTag string,
+	capacityTag string,
 ) []error {
-	var missingTags bytes.Buffer
+	var missingTags, invalidTags bytes.Buffer
 
 	for b, ht := range t {
 		// We need to get both the ID and instance type tag values. Both must
@@ -167,34 +236,99 @@ func populateFromTagMap(
 			continue
 		}
 
-		// Get instance type.
-		it = valFromTags(ht, instanceTypeTag)
-		if it != "" {
-			// Cache this broker's tags. In case additional tags are populated
-			// in the future, we should only cache brokers that have successfully
-			// had all of their tags populated. Leaving it uncached gives it another
-			// chance for complete metadata in the preceding API lookups.
-			c[b.Host] = t[b]
-		} else {
-			s := fmt.Sprintf(" instance_type:%s", b.Host)
-			missingTags.WriteString(s)
-			continue
+		// Get instance type. The instance type tag is optional where instance
+		// types are resolved elsewhere, e.g. from cloud provider APIs.
+		if instanceTypeTag != "" {
+			it = valFromTags(ht, instanceTypeTag)
+			if it == "" {
+				s := fmt.Sprintf(" instance_type:%s", b.Host)
+				missingTags.WriteString(s)
+				continue
+			}
 		}
 
-		// If we are here, we have both the ID and Instance
-		// type tag values. Populate.
+		// Get the capacity, if tagged.
+		var capacity float64
+		if capacityTag != "" {
+			if v := valFromTags(ht, capacityTag); v != "" {
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil || f <= 0 {
+					s := fmt.Sprintf(" %s:%s", capacityTag, b.Host)
+					invalidTags.WriteString(s)
+				} else {
+					capacity = f
+				}
+			}
+		}
+
+		// Cache this broker's tags. In case additional tags are populated
+		// in the future, we should only cache brokers that have successfully
+		// had all of their tags populated. Leaving it uncached gives it another
+		// chance for complete metadata in the preceding API lookups.
+		c[b.Host] = t[b]
+
+		// If we are here, we have both the ID and any required
+		// instance type tag values. Populate.
 		b.ID = id
 		b.InstanceType = it
+		b.Capacity = capacity
 		bm[id] = b
 	}
 
+	var errors []error
+
 	if missingTags.String() != "" {
-		return []error{&kafkametrics.PartialResults{
+		errors = append(errors, &kafkametrics.PartialResults{
 			Message: fmt.Sprintf("Missing host tags:%s", missingTags.String()),
-		}}
+		})
+	}
+
+	if invalidTags.String() != "" {
+		errors = append(errors, &kafkametrics.PartialResults{
+			Message: fmt.Sprintf("Invalid capacity tags:%s", invalidTags.String()),
+		})
+	}
+
+	return errors
+}
+
+// scopeTags takes a []dd.Series and a broker ID tag key and returns a map of
+// hosts to the tags in the scope of each series that includes the broker ID
+// tag.
+func scopeTags(s []dd.Series, btag string) map[string][]string {
+	tags := map[string][]string{}
+
+	for _, ts := range s {
+		st := strings.Split(ts.GetScope(), ",")
+		if valFromTags(st, btag) == "" {
+			continue
+		}
+
+		if host := valFromTags(st, "host"); host != "" {
+			tags[host] = st
+		}
 	}
 
-	return nil
+	return tags
+}
+
+// InvalidateBroker implements the kafkametrics.BrokerCacheInvalidator
+// interface. Cached host tags for any hosts tagged with the broker ID are
+// dropped; the host to broker ID mapping and instance type are fetched again
+// on the next GetMetrics call.
+func (h *ddHandler) InvalidateBroker(id int) {
+	invalidateCachedBroker(h.tagCache, h.brokerIDTag, id)
+}
+
+// invalidateCachedBroker takes a host tags cache, a broker ID tag key and a
+// broker ID and deletes all cache entries for hosts tagged with the ID.
+func invalidateCachedBroker(c map[string][]string, btag string, id int) {
+	ids := strconv.Itoa(id)
+	for host, ht := range c {
+		if valFromTags(ht, btag) == ids {
+			delete(c, host)
+		}
+	}
 }
 
 // tagValFromScope takes a metric scope string and a tag and returns
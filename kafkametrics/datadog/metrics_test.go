@@ -2,9 +2,12 @@ package datadog
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+
+	dd "github.com/zorkian/go-datadog-api"
 )
 
 func TestMergeBrokerLists(t *testing.T) {
@@ -83,6 +86,36 @@ func TestMergeBrokerLists(t *testing.T) {
 	}
 }
 
+func TestMergeTieredUploads(t *testing.T) {
+	dst := []*kafkametrics.Broker{
+		{Host: "i-abc0", NetTX: 40.00},
+		// This broker has no upload series and should be assumed idle.
+		{Host: "i-abc1", NetTX: 60.00},
+	}
+
+	src := []*kafkametrics.Broker{
+		{Host: "i-abc0", TieredUpload: 15.00},
+		// This broker doesn't exist in dst and should be ignored.
+		{Host: "i-abc2", TieredUpload: 20.00},
+	}
+
+	mergeTieredUploads(dst, src)
+
+	if len(dst) != 2 {
+		t.Fatalf("Expected 2 brokers, got %d", len(dst))
+	}
+
+	for i, expected := range []float64{15.00, 0.00} {
+		if dst[i].TieredUpload != expected {
+			t.Errorf("[%s] Expected TieredUpload %.2f, got %.2f", dst[i].Host, expected, dst[i].TieredUpload)
+		}
+	}
+
+	if dst[0].NetTX != 40.00 {
+		t.Errorf("Expected NetTX 40.00, got %.2f", dst[0].NetTX)
+	}
+}
+
 func brokerEqual(b0, b1 *kafkametrics.Broker) bool {
 	switch {
 	case b0.ID != b1.ID,
@@ -107,7 +140,7 @@ func TestPopulateFromTagMap(t *testing.T) {
 
 	// Test with complete input.
 	tagMap := stubTagMap()
-	err := populateFromTagMap(b, map[string][]string{}, tagMap, "broker_id", "instance-type")
+	err := populateFromTagMap(b, map[string][]string{}, tagMap, "broker_id", "instance-type", "")
 	if err != nil {
 		t.Errorf("Unexpected error: %s\n", err)
 	}
@@ -128,10 +161,90 @@ func TestPopulateFromTagMap(t *testing.T) {
 
 	// Test with incomplete input.
 	tagMap[rndBroker] = tagMap[rndBroker][1:]
-	err = populateFromTagMap(b, map[string][]string{}, tagMap, "broker_id", "instance-type")
+	err = populateFromTagMap(b, map[string][]string{}, tagMap, "broker_id", "instance-type", "")
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
+
+	// Test without an instance type tag.
+	b = kafkametrics.BrokerMetrics{}
+	tagMap = stubTagMap()
+	for broker := range tagMap {
+		tagMap[broker] = tagMap[broker][:1]
+		broker.InstanceType = ""
+	}
+
+	err = populateFromTagMap(b, map[string][]string{}, tagMap, "broker_id", "", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %s\n", err)
+	}
+
+	if len(b) != 5 {
+		t.Errorf("Expected 5 brokers, got %d\n", len(b))
+	}
+
+	// Test with a capacity tag. The tag is optional for each broker.
+	b = kafkametrics.BrokerMetrics{}
+	tagMap = stubTagMap()
+	for broker := range tagMap {
+		switch broker.ID {
+		case 1000:
+			tagMap[broker] = append(tagMap[broker], "network_capacity:1250")
+		case 1001:
+			tagMap[broker] = append(tagMap[broker], "network_capacity:10g")
+		}
+	}
+
+	errs := populateFromTagMap(b, map[string][]string{}, tagMap, "broker_id", "instance-type", "network_capacity")
+	if len(errs) != 1 || errs[0].Error() != "Invalid capacity tags: network_capacity:host1" {
+		t.Errorf("Expected an invalid capacity tag error, got %v", errs)
+	}
+
+	for id, expected := range map[int]float64{1000: 1250, 1001: 0, 1002: 0} {
+		if b[id].Capacity != expected {
+			t.Errorf("Expected broker %d capacity %.2f, got %.2f", id, expected, b[id].Capacity)
+		}
+	}
+}
+
+func TestScopeTags(t *testing.T) {
+	scopes := []string{
+		"host:host0,broker_id:1000,instance-type:stub",
+		"host:host1",
+	}
+
+	var series []dd.Series
+	for i := range scopes {
+		series = append(series, dd.Series{Scope: &scopes[i]})
+	}
+
+	tags := scopeTags(series, "broker_id")
+
+	expected := map[string][]string{
+		"host0": {"host:host0", "broker_id:1000", "instance-type:stub"},
+	}
+
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+}
+
+func TestInvalidateCachedBroker(t *testing.T) {
+	c := map[string][]string{
+		"host0":  {"broker_id:1000", "instance-type:stub"},
+		"host1":  {"broker_id:1001", "instance-type:stub"},
+		"host1b": {"broker_id:1001", "instance-type:stub2"},
+	}
+
+	invalidateCachedBroker(c, "broker_id", 1001)
+
+	if len(c) != 1 {
+		t.Errorf("Expected 1 cached host, got %v", c)
+	}
+
+	if _, exists := c["host0"]; !exists {
+		t.Error("Expected host0 to remain cached")
+	}
 }
 
 func stubTagMap() map[*kafkametrics.Broker][]string {
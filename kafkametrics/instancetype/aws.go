@@ -0,0 +1,285 @@
+package instancetype
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/internal/awsauth"
+)
+
+const (
+	// The EC2 API version used for DescribeInstances requests.
+	ec2APIVersion = "2016-11-15"
+	// The maximum number of filter values per DescribeInstances request.
+	ec2MaxFilterValues = 100
+)
+
+var (
+	// ErrNoAWSCredentials is returned when no AWS credentials are found in the
+	// environment or instance metadata.
+	ErrNoAWSCredentials = awsauth.ErrNoCredentials
+
+	instanceIDRegex = regexp.MustCompile(`^i-[0-9a-f]{8,17}$`)
+)
+
+// EC2Config holds EC2Resolver configuration parameters.
+type EC2Config struct {
+	// The AWS region. If empty, the region is read from the AWS_REGION env var
+	// or the instance metadata.
+	Region string
+	// If set, hosts are matched against the value of this instance tag (e.g.
+	// "Name"). Otherwise, hosts that are instance IDs are matched by ID and
+	// all others by private DNS name.
+	LookupTag string
+	// Optional EC2 API endpoint. Defaults to the regional endpoint.
+	Endpoint string
+	// Optional instance metadata service endpoint.
+	MetadataEndpoint string
+	// Optional HTTP client.
+	HTTPClient *http.Client
+}
+
+// EC2Resolver resolves instance types with the EC2 DescribeInstances API.
+// Requests are authenticated with credentials from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN env vars if set, otherwise with
+// the instance's IAM role credentials from the instance metadata service.
+type EC2Resolver struct {
+	region    string
+	lookupTag string
+	endpoint  string
+	auth      *awsauth.Provider
+	client    *http.Client
+	now       func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewEC2Resolver takes an EC2Config and returns an *EC2Resolver.
+func NewEC2Resolver(c EC2Config) *EC2Resolver {
+	r := &EC2Resolver{
+		region:    c.Region,
+		lookupTag: c.LookupTag,
+		endpoint:  c.Endpoint,
+		client:    c.HTTPClient,
+		now:       time.Now,
+	}
+
+	if r.client == nil {
+		r.client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	r.auth = awsauth.NewProvider(c.MetadataEndpoint, r.client)
+
+	return r
+}
+
+// ec2DescribeInstancesResponse is a DescribeInstances API response.
+type ec2DescribeInstancesResponse struct {
+	Reservations []struct {
+		Instances []ec2Instance `xml:"instancesSet>item"`
+	} `xml:"reservationSet>item"`
+	NextToken string `xml:"nextToken"`
+}
+
+type ec2Instance struct {
+	InstanceID     string `xml:"instanceId"`
+	InstanceType   string `xml:"instanceType"`
+	PrivateDNSName string `xml:"privateDnsName"`
+	Tags           []struct {
+		Key   string `xml:"key"`
+		Value string `xml:"value"`
+	} `xml:"tagSet>item"`
+}
+
+// ec2ErrorResponse is an EC2 API error response.
+type ec2ErrorResponse struct {
+	Errors []struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Errors>Error"`
+}
+
+// InstanceTypes implements the Resolver interface.
+func (r *EC2Resolver) InstanceTypes(ctx context.Context, hosts []string) (map[string]string, error) {
+	if err := r.resolveRegion(ctx); err != nil {
+		return nil, err
+	}
+
+	// Group hosts by the filter they're matched with.
+	filters := map[string][]string{}
+	for _, host := range hosts {
+		switch {
+		case r.lookupTag != "":
+			filters["tag:"+r.lookupTag] = append(filters["tag:"+r.lookupTag], host)
+		case instanceIDRegex.MatchString(host):
+			filters["instance-id"] = append(filters["instance-id"], host)
+		default:
+			filters["private-dns-name"] = append(filters["private-dns-name"], host)
+		}
+	}
+
+	var names []string
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	types := map[string]string{}
+	for _, name := range names {
+		values := filters[name]
+		for len(values) > 0 {
+			n := len(values)
+			if n > ec2MaxFilterValues {
+				n = ec2MaxFilterValues
+			}
+
+			instances, err := r.describeInstances(ctx, name, values[:n])
+			if err != nil {
+				return nil, err
+			}
+
+			for _, i := range instances {
+				types[r.instanceHost(name, i)] = i.InstanceType
+			}
+
+			values = values[n:]
+		}
+	}
+
+	delete(types, "")
+
+	return types, nil
+}
+
+// instanceHost takes a filter name and an ec2Instance and returns the host the
+// instance was matched by.
+func (r *EC2Resolver) instanceHost(filter string, i ec2Instance) string {
+	switch filter {
+	case "instance-id":
+		return i.InstanceID
+	case "private-dns-name":
+		return i.PrivateDNSName
+	default:
+		for _, t := range i.Tags {
+			if t.Key == r.lookupTag {
+				return t.Value
+			}
+		}
+	}
+
+	return ""
+}
+
+// describeInstances takes a filter name and values and returns all matching
+// instances.
+func (r *EC2Resolver) describeInstances(ctx context.Context, filter string, values []string) ([]ec2Instance, error) {
+	var instances []ec2Instance
+	var nextToken string
+
+	for {
+		params := url.Values{}
+		params.Set("Action", "DescribeInstances")
+		params.Set("Version", ec2APIVersion)
+		params.Set("Filter.1.Name", filter)
+		for i, v := range values {
+			params.Set(fmt.Sprintf("Filter.1.Value.%d", i+1), v)
+		}
+		if nextToken != "" {
+			params.Set("NextToken", nextToken)
+		}
+
+		body, err := r.do(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp ec2DescribeInstancesResponse
+		if err := xml.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("error parsing DescribeInstances response: %s", err)
+		}
+
+		for _, res := range resp.Reservations {
+			instances = append(instances, res.Instances...)
+		}
+
+		if resp.NextToken == "" {
+			return instances, nil
+		}
+
+		nextToken = resp.NextToken
+	}
+}
+
+// do takes EC2 API request params, makes a signed request and returns the
+// response body.
+func (r *EC2Resolver) do(ctx context.Context, params url.Values) ([]byte, error) {
+	creds, err := r.auth.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := r.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://ec2.%s.amazonaws.com", r.region)
+	}
+
+	body := []byte(awsauth.QueryEscape(params))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	awsauth.SignV4(req, body, creds, r.region, "ec2", r.now())
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e ec2ErrorResponse
+		if xml.Unmarshal(respBody, &e) == nil && len(e.Errors) > 0 {
+			return nil, fmt.Errorf("EC2 API error: %s: %s", e.Errors[0].Code, e.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("EC2 API error: %s", resp.Status)
+	}
+
+	return respBody, nil
+}
+
+// resolveRegion populates the region from the AWS_REGION env var or the
+// instance metadata if unset.
+func (r *EC2Resolver) resolveRegion(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.region != "" {
+		return nil
+	}
+
+	region, err := r.auth.Region(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.region = region
+
+	return nil
+}
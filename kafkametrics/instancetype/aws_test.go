@@ -0,0 +1,171 @@
+package instancetype
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ec2Stub serves IMDSv2 metadata and EC2 DescribeInstances requests for the
+// instances.
+func ec2Stub(t *testing.T, instances []ec2Instance) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			w.Write([]byte("token"))
+			return
+		case strings.HasPrefix(r.URL.Path, "/latest/meta-data/"):
+			if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			switch strings.TrimPrefix(r.URL.Path, "/latest/meta-data/") {
+			case "placement/region":
+				w.Write([]byte("us-east-1"))
+			case "iam/security-credentials/":
+				w.Write([]byte("kafka-autothrottle\n"))
+			case "iam/security-credentials/kafka-autothrottle":
+				w.Write([]byte(`{"AccessKeyId": "AKID", "SecretAccessKey": "secret", "Token": "session",
+					"Expiration": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		}
+
+		// DescribeInstances.
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get("X-Amz-Security-Token") != "session" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Response><Errors><Error><Code>AuthFailure</Code><Message>denied</Message></Error></Errors></Response>`))
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		params, _ := url.ParseQuery(string(body))
+
+		filter := params.Get("Filter.1.Name")
+		values := map[string]bool{}
+		for k, v := range params {
+			if strings.HasPrefix(k, "Filter.1.Value.") {
+				values[v[0]] = true
+			}
+		}
+
+		var items strings.Builder
+		for _, i := range instances {
+			match := i.InstanceID
+			switch filter {
+			case "private-dns-name":
+				match = i.PrivateDNSName
+			case "tag:Name":
+				match = i.Tags[0].Value
+			}
+
+			if !values[match] {
+				continue
+			}
+
+			fmt.Fprintf(&items, `<item><instanceId>%s</instanceId><instanceType>%s</instanceType>
+				<privateDnsName>%s</privateDnsName><tagSet><item><key>Name</key><value>%s</value></item></tagSet></item>`,
+				i.InstanceID, i.InstanceType, i.PrivateDNSName, i.Tags[0].Value)
+		}
+
+		fmt.Fprintf(w, `<DescribeInstancesResponse><reservationSet><item><instancesSet>%s</instancesSet></item></reservationSet></DescribeInstancesResponse>`,
+			items.String())
+	}))
+}
+
+func stubInstance(id, instanceType, dnsName, name string) ec2Instance {
+	i := ec2Instance{InstanceID: id, InstanceType: instanceType, PrivateDNSName: dnsName}
+	i.Tags = append(i.Tags, struct {
+		Key   string `xml:"key"`
+		Value string `xml:"value"`
+	}{"Name", name})
+
+	return i
+}
+
+func TestEC2ResolverInstanceTypes(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_REGION", "")
+
+	srv := ec2Stub(t, []ec2Instance{
+		stubInstance("i-0123456789abcdef0", "i3en.xlarge", "ip-10-0-0-1.ec2.internal", "kafka-1"),
+		stubInstance("i-0123456789abcdef1", "d2.2xlarge", "ip-10-0-0-2.ec2.internal", "kafka-2"),
+	})
+	defer srv.Close()
+
+	tests := []struct {
+		lookupTag string
+		hosts     []string
+		expected  map[string]string
+	}{
+		{
+			hosts: []string{"i-0123456789abcdef0", "ip-10-0-0-2.ec2.internal", "ip-10-0-0-3.ec2.internal"},
+			expected: map[string]string{
+				"i-0123456789abcdef0":      "i3en.xlarge",
+				"ip-10-0-0-2.ec2.internal": "d2.2xlarge",
+			},
+		},
+		{
+			lookupTag: "Name",
+			hosts:     []string{"kafka-1", "kafka-2"},
+			expected: map[string]string{
+				"kafka-1": "i3en.xlarge",
+				"kafka-2": "d2.2xlarge",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		r := NewEC2Resolver(EC2Config{
+			LookupTag:        test.lookupTag,
+			Endpoint:         srv.URL,
+			MetadataEndpoint: srv.URL,
+		})
+
+		types, err := r.InstanceTypes(context.Background(), test.hosts)
+		if err != nil {
+			t.Fatalf("[test %d] Unexpected error: %s", i, err)
+		}
+
+		if len(types) != len(test.expected) {
+			t.Errorf("[test %d] Expected %v, got %v", i, test.expected, types)
+		}
+
+		for host, it := range test.expected {
+			if types[host] != it {
+				t.Errorf("[test %d] Expected %s for %s, got %s", i, it, host, types[host])
+			}
+		}
+
+		if r.region != "us-east-1" {
+			t.Errorf("[test %d] Expected region us-east-1, got %s", i, r.region)
+		}
+	}
+}
+
+func TestEC2ResolverAPIError(t *testing.T) {
+	srv := ec2Stub(t, nil)
+	defer srv.Close()
+
+	// Env credentials take precedence over the IAM role and are rejected.
+	t.Setenv("AWS_ACCESS_KEY_ID", "other")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	r := NewEC2Resolver(EC2Config{Region: "us-east-1", Endpoint: srv.URL, MetadataEndpoint: srv.URL})
+
+	_, err := r.InstanceTypes(context.Background(), []string{"i-0123456789abcdef0"})
+	if err == nil || !strings.Contains(err.Error(), "AuthFailure") {
+		t.Errorf("Expected an AuthFailure error, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package instancetype
+
+import (
+	"sync"
+	"time"
+)
+
+// cache is a host to instance type cache. Hosts that couldn't be resolved are
+// cached with a separate TTL so that new hosts are found promptly without
+// requesting them every interval.
+type cache struct {
+	ttl         time.Duration
+	notFoundTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	// An empty instanceType means the host wasn't found.
+	instanceType string
+	expires      time.Time
+}
+
+func newCache(ttl, notFoundTTL time.Duration) *cache {
+	return &cache{
+		ttl:         ttl,
+		notFoundTTL: notFoundTTL,
+		entries:     map[string]cacheEntry{},
+	}
+}
+
+// get takes a list of hosts and the current time and returns a map of cached
+// host to instance types, along with a list of hosts not cached.
+func (c *cache) get(hosts []string, now time.Time) (map[string]string, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	types := map[string]string{}
+	var uncached []string
+
+	for _, host := range hosts {
+		e, exists := c.entries[host]
+		switch {
+		case !exists || now.After(e.expires):
+			uncached = append(uncached, host)
+		case e.instanceType != "":
+			types[host] = e.instanceType
+		}
+	}
+
+	return types, uncached
+}
+
+// set takes a list of requested hosts, a map of resolved host to instance types
+// and the current time and caches the results.
+func (c *cache) set(hosts []string, resolved map[string]string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, host := range hosts {
+		if it, exists := resolved[host]; exists {
+			c.entries[host] = cacheEntry{instanceType: it, expires: now.Add(c.ttl)}
+		} else {
+			c.entries[host] = cacheEntry{expires: now.Add(c.notFoundTTL)}
+		}
+	}
+}
+
+// delete removes a host from the cache.
+func (c *cache) delete(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, host)
+}
@@ -0,0 +1,290 @@
+package instancetype
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The default GCE metadata server endpoint.
+	gceMetadataEndpoint = "http://metadata.google.internal"
+	// The default Compute Engine API endpoint.
+	gceComputeEndpoint = "https://compute.googleapis.com"
+	// The maximum number of instance names per instances list filter.
+	gceMaxFilterNames = 50
+)
+
+// GCEConfig holds GCEResolver configuration parameters.
+type GCEConfig struct {
+	// The GCP project. If empty, the project is read from the metadata server.
+	Project string
+	// Optional Compute Engine API endpoint.
+	Endpoint string
+	// Optional metadata server endpoint.
+	MetadataEndpoint string
+	// Optional HTTP client.
+	HTTPClient *http.Client
+}
+
+// GCEResolver resolves machine types with the Compute Engine instances API.
+// Hosts are matched by instance name; any domain suffix is ignored. Requests
+// are authenticated with the instance's service account through the metadata
+// server.
+type GCEResolver struct {
+	project          string
+	endpoint         string
+	metadataEndpoint string
+	client           *http.Client
+	now              func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCEResolver takes a GCEConfig and returns a *GCEResolver.
+func NewGCEResolver(c GCEConfig) *GCEResolver {
+	r := &GCEResolver{
+		project:          c.Project,
+		endpoint:         c.Endpoint,
+		metadataEndpoint: c.MetadataEndpoint,
+		client:           c.HTTPClient,
+		now:              time.Now,
+	}
+
+	if r.endpoint == "" {
+		r.endpoint = gceComputeEndpoint
+	}
+
+	if r.metadataEndpoint == "" {
+		r.metadataEndpoint = gceMetadataEndpoint
+	}
+
+	if r.client == nil {
+		r.client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return r
+}
+
+// gceAggregatedInstances is an instances aggregatedList API response.
+type gceAggregatedInstances struct {
+	Items map[string]struct {
+		Instances []struct {
+			Name        string `json:"name"`
+			MachineType string `json:"machineType"`
+		} `json:"instances"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// InstanceTypes implements the Resolver interface.
+func (r *GCEResolver) InstanceTypes(ctx context.Context, hosts []string) (map[string]string, error) {
+	if err := r.resolveProject(ctx); err != nil {
+		return nil, err
+	}
+
+	// Instance names may map to several hosts, e.g. "kafka-1" and
+	// "kafka-1.c.project.internal".
+	byName := map[string][]string{}
+	for _, host := range hosts {
+		name := strings.SplitN(host, ".", 2)[0]
+		byName[name] = append(byName[name], host)
+	}
+
+	var names []string
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	types := map[string]string{}
+	for len(names) > 0 {
+		n := len(names)
+		if n > gceMaxFilterNames {
+			n = gceMaxFilterNames
+		}
+
+		machineTypes, err := r.machineTypes(ctx, names[:n])
+		if err != nil {
+			return nil, err
+		}
+
+		for name, mt := range machineTypes {
+			for _, host := range byName[name] {
+				types[host] = mt
+			}
+		}
+
+		names = names[n:]
+	}
+
+	return types, nil
+}
+
+// machineTypes takes a list of instance names and returns a map of instance
+// name to machine type.
+func (r *GCEResolver) machineTypes(ctx context.Context, names []string) (map[string]string, error) {
+	var clauses []string
+	for _, name := range names {
+		clauses = append(clauses, fmt.Sprintf(`(name = "%s")`, name))
+	}
+
+	types := map[string]string{}
+	var pageToken string
+
+	for {
+		params := url.Values{}
+		params.Set("filter", strings.Join(clauses, " OR "))
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+
+		u := fmt.Sprintf("%s/compute/v1/projects/%s/aggregated/instances?%s",
+			r.endpoint, url.PathEscape(r.project), params.Encode())
+
+		var resp gceAggregatedInstances
+		if err := r.get(ctx, u, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, scope := range resp.Items {
+			for _, i := range scope.Instances {
+				// Machine types are URLs, e.g.
+				// ".../zones/us-central1-a/machineTypes/n2-standard-8".
+				types[i.Name] = path.Base(i.MachineType)
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			return types, nil
+		}
+
+		pageToken = resp.NextPageToken
+	}
+}
+
+// get makes an authenticated Compute Engine API request and decodes the
+// response into v.
+func (r *GCEResolver) get(ctx context.Context, u string, v interface{}) error {
+	token, err := r.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Error.Message != "" {
+			return fmt.Errorf("compute API error: %s", e.Error.Message)
+		}
+		return fmt.Errorf("compute API error: %s", resp.Status)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// resolveProject populates the project from the metadata server if unset.
+func (r *GCEResolver) resolveProject(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.project != "" {
+		return nil
+	}
+
+	project, err := r.metadata(ctx, "project/project-id")
+	if err != nil {
+		return fmt.Errorf("error fetching GCP project: %s", err)
+	}
+
+	r.project = project
+
+	return nil
+}
+
+// accessToken returns an access token for the instance's default service
+// account. Tokens are cached until shortly before they expire.
+func (r *GCEResolver) accessToken(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.token != "" && r.now().Add(time.Minute).Before(r.tokenExpiry) {
+		return r.token, nil
+	}
+
+	data, err := r.metadata(ctx, "instance/service-accounts/default/token")
+	if err != nil {
+		return "", fmt.Errorf("error fetching service account token: %s", err)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		return "", fmt.Errorf("error parsing service account token: %s", err)
+	}
+
+	r.token = token.AccessToken
+	r.tokenExpiry = r.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return r.token, nil
+}
+
+// metadata takes a metadata server path relative to /computeMetadata/v1/ and
+// returns the value.
+func (r *GCEResolver) metadata(ctx context.Context, p string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.metadataEndpoint+"/computeMetadata/v1/"+p, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata request %s: %s", p, resp.Status)
+	}
+
+	return string(body), nil
+}
@@ -0,0 +1,101 @@
+package instancetype
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGCEResolverInstanceTypes(t *testing.T) {
+	machineTypes := map[string]string{
+		"kafka-1": "zones/us-central1-a/machineTypes/n2-standard-8",
+		"kafka-2": "zones/us-central1-b/machineTypes/n2-highmem-16",
+	}
+
+	var tokenRequests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/computeMetadata/v1/") {
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			switch strings.TrimPrefix(r.URL.Path, "/computeMetadata/v1/") {
+			case "project/project-id":
+				w.Write([]byte("kafka-project"))
+			case "instance/service-accounts/default/token":
+				tokenRequests++
+				w.Write([]byte(`{"access_token": "token", "expires_in": 3600, "token_type": "Bearer"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "unauthorized"}}`))
+			return
+		}
+
+		if r.URL.Path != "/compute/v1/projects/kafka-project/aggregated/instances" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		type instance struct {
+			Name        string `json:"name"`
+			MachineType string `json:"machineType"`
+		}
+
+		var instances []instance
+		filter := r.URL.Query().Get("filter")
+		for name, mt := range machineTypes {
+			if strings.Contains(filter, `(name = "`+name+`")`) {
+				instances = append(instances, instance{name, "https://www.googleapis.com/compute/v1/projects/kafka-project/" + mt})
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": map[string]interface{}{
+				"zones/us-central1-a": map[string]interface{}{"instances": instances},
+				"zones/us-central1-c": map[string]interface{}{"warning": map[string]string{"code": "NO_RESULTS_ON_PAGE"}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	r := NewGCEResolver(GCEConfig{Endpoint: srv.URL, MetadataEndpoint: srv.URL})
+
+	hosts := []string{"kafka-1", "kafka-2.c.kafka-project.internal", "kafka-3"}
+	for i := 0; i < 2; i++ {
+		types, err := r.InstanceTypes(context.Background(), hosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]string{
+			"kafka-1":                          "n2-standard-8",
+			"kafka-2.c.kafka-project.internal": "n2-highmem-16",
+		}
+
+		if len(types) != len(expected) {
+			t.Errorf("Expected %v, got %v", expected, types)
+		}
+
+		for host, it := range expected {
+			if types[host] != it {
+				t.Errorf("Expected %s for %s, got %s", it, host, types[host])
+			}
+		}
+	}
+
+	// The access token is reused.
+	if tokenRequests != 1 {
+		t.Errorf("Expected 1 token request, got %d", tokenRequests)
+	}
+}
@@ -0,0 +1,179 @@
+// Package instancetype resolves Kafka broker instance types from cloud
+// provider APIs. A Handler wraps a kafkametrics.Handler, populating each
+// broker's instance type by its host rather than relying on the metrics
+// backend's host tags.
+package instancetype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// Resolver resolves instance types for hosts.
+type Resolver interface {
+	// InstanceTypes takes a list of hosts and returns a map of host to
+	// instance type. Hosts that couldn't be found are omitted.
+	InstanceTypes(ctx context.Context, hosts []string) (map[string]string, error)
+}
+
+// Config holds Handler configuration parameters.
+type Config struct {
+	// The wrapped metrics handler.
+	Handler kafkametrics.Handler
+	// The instance type resolver.
+	Resolver Resolver
+	// How long resolved instance types are cached. Defaults to 1 hour.
+	TTL time.Duration
+	// How long hosts that couldn't be resolved are cached. Defaults to 1
+	// minute.
+	NotFoundTTL time.Duration
+	// The resolver request timeout. Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// Handler is a kafkametrics.Handler that populates broker instance types from
+// a Resolver.
+type Handler struct {
+	kafkametrics.Handler
+	resolver Resolver
+	cache    *cache
+	timeout  time.Duration
+
+	mu sync.Mutex
+	// The most recently seen host for each broker ID.
+	hosts map[int]string
+}
+
+// NewHandler takes a Config and returns a *Handler.
+func NewHandler(c Config) *Handler {
+	if c.TTL == 0 {
+		c.TTL = time.Hour
+	}
+
+	if c.NotFoundTTL == 0 {
+		c.NotFoundTTL = time.Minute
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = 30 * time.Second
+	}
+
+	return &Handler{
+		Handler:  c.Handler,
+		resolver: c.Resolver,
+		cache:    newCache(c.TTL, c.NotFoundTTL),
+		timeout:  c.Timeout,
+		hosts:    map[int]string{},
+	}
+}
+
+// GetMetrics fetches metrics from the wrapped handler and sets each broker's
+// instance type to that resolved for its host. Brokers whose instance type
+// couldn't be resolved keep any instance type populated by the wrapped
+// handler; if there's none, the broker is excluded and a
+// kafkametrics.PartialResults error is returned.
+func (h *Handler) GetMetrics() (kafkametrics.BrokerMetrics, []error) {
+	bm, errs := h.Handler.GetMetrics()
+	if bm == nil {
+		return bm, errs
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var hosts []string
+	for id, b := range bm {
+		h.hosts[id] = b.Host
+		hosts = append(hosts, b.Host)
+	}
+
+	types, err := h.instanceTypes(hosts)
+	if err != nil {
+		errs = append(errs, &kafkametrics.APIError{
+			Request: "instance types",
+			Message: err.Error(),
+		})
+	}
+
+	var missing []string
+	for id, b := range bm {
+		if it, exists := types[b.Host]; exists {
+			b.InstanceType = it
+			continue
+		}
+
+		if b.InstanceType == "" {
+			missing = append(missing, b.Host)
+			delete(bm, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		errs = append(errs, &kafkametrics.PartialResults{
+			Message: fmt.Sprintf("Unknown instance types for hosts: %s", strings.Join(missing, " ")),
+		})
+	}
+
+	return bm, errs
+}
+
+// instanceTypes takes a list of hosts and returns a map of host to instance
+// type. Cached values are used where available; any remaining hosts are
+// resolved and cached.
+func (h *Handler) instanceTypes(hosts []string) (map[string]string, error) {
+	types, uncached := h.cache.get(hosts, time.Now())
+	if len(uncached) == 0 {
+		return types, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	resolved, err := h.resolver.InstanceTypes(ctx, uncached)
+	if err != nil {
+		// Nothing is cached for failed requests.
+		return types, err
+	}
+
+	h.cache.set(uncached, resolved, time.Now())
+
+	for host, it := range resolved {
+		types[host] = it
+	}
+
+	return types, nil
+}
+
+// UpdateCredentials implements the kafkametrics.CredentialsUpdater interface
+// by passing the call to the wrapped handler, if supported.
+func (h *Handler) UpdateCredentials(apiKey, appKey string) error {
+	u, ok := h.Handler.(kafkametrics.CredentialsUpdater)
+	if !ok {
+		return errors.New("credential updates aren't supported by the metrics handler")
+	}
+
+	return u.UpdateCredentials(apiKey, appKey)
+}
+
+// InvalidateBroker implements the kafkametrics.BrokerCacheInvalidator
+// interface. The cached instance type for the broker's most recently seen host
+// is dropped and the call is passed to the wrapped handler, if supported.
+func (h *Handler) InvalidateBroker(id int) {
+	h.mu.Lock()
+	if host, exists := h.hosts[id]; exists {
+		h.cache.delete(host)
+	}
+	h.mu.Unlock()
+
+	if i, ok := h.Handler.(kafkametrics.BrokerCacheInvalidator); ok {
+		i.InvalidateBroker(id)
+	}
+}
@@ -0,0 +1,160 @@
+package instancetype
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DataDog/kafka-kit/v4/kafkametrics"
+)
+
+// resolverStub resolves instance types from a map, recording requested hosts.
+type resolverStub struct {
+	types     map[string]string
+	err       error
+	requested [][]string
+}
+
+func (r *resolverStub) InstanceTypes(_ context.Context, hosts []string) (map[string]string, error) {
+	sorted := append([]string{}, hosts...)
+	sort.Strings(sorted)
+	r.requested = append(r.requested, sorted)
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	types := map[string]string{}
+	for _, host := range hosts {
+		if it, exists := r.types[host]; exists {
+			types[host] = it
+		}
+	}
+
+	return types, nil
+}
+
+func TestHandlerGetMetrics(t *testing.T) {
+	km := kafkametrics.NewStub()
+	resolver := &resolverStub{types: map[string]string{}}
+
+	// host0-host8 are resolved; host9 isn't.
+	for i := 0; i < 9; i++ {
+		resolver.types[kafkametrics.StubBrokerMetrics()[1000+i].Host] = "i3en.xlarge"
+	}
+
+	h := NewHandler(Config{Handler: km, Resolver: resolver})
+
+	bm, errs := h.GetMetrics()
+
+	// host9 keeps the stub's instance type.
+	if errs != nil {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	if bm[1000].InstanceType != "i3en.xlarge" || bm[1009].InstanceType != "stub" {
+		t.Errorf("Unexpected instance types %s, %s", bm[1000].InstanceType, bm[1009].InstanceType)
+	}
+
+	// Brokers without an instance type are excluded.
+	noTypes := kafkametrics.StubBrokerMetrics()
+	for _, b := range noTypes {
+		b.InstanceType = ""
+	}
+	km.Script(kafkametrics.StubResponse{Metrics: noTypes})
+
+	bm, errs = h.GetMetrics()
+
+	if _, exists := bm[1009]; exists || len(bm) != 9 {
+		t.Errorf("Expected 9 brokers excluding 1009, got %d", len(bm))
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+
+	if _, ok := errs[0].(*kafkametrics.PartialResults); !ok {
+		t.Errorf("Expected a PartialResults error, got %T", errs[0])
+	}
+
+	// The second call was served from the cache.
+	if len(resolver.requested) != 1 {
+		t.Errorf("Expected 1 resolver request, got %v", resolver.requested)
+	}
+
+	// Invalidating a broker requests its host again.
+	h.InvalidateBroker(1001)
+	h.GetMetrics()
+
+	if len(resolver.requested) != 2 || len(resolver.requested[1]) != 1 || resolver.requested[1][0] != "host1" {
+		t.Errorf("Expected a request for host1, got %v", resolver.requested)
+	}
+
+	if ids := km.Invalidated(); len(ids) != 1 || ids[0] != 1001 {
+		t.Errorf("Expected the invalidation to be passed to the wrapped handler, got %v", ids)
+	}
+}
+
+func TestHandlerResolverError(t *testing.T) {
+	km := kafkametrics.NewStub()
+	resolver := &resolverStub{err: errors.New("unavailable")}
+
+	h := NewHandler(Config{Handler: km, Resolver: resolver})
+
+	bm, errs := h.GetMetrics()
+
+	// Brokers keep the stub's instance types.
+	if len(bm) != 10 {
+		t.Errorf("Expected 10 brokers, got %d", len(bm))
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+
+	if _, ok := errs[0].(*kafkametrics.APIError); !ok {
+		t.Errorf("Expected an APIError, got %T", errs[0])
+	}
+
+	// Failed requests aren't cached.
+	h.GetMetrics()
+
+	if len(resolver.requested) != 2 {
+		t.Errorf("Expected 2 resolver requests, got %d", len(resolver.requested))
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := newCache(time.Hour, time.Minute)
+	now := time.Now()
+
+	c.set([]string{"a", "b"}, map[string]string{"a": "i3en.xlarge"}, now)
+
+	types, uncached := c.get([]string{"a", "b", "c"}, now)
+	if len(types) != 1 || types["a"] != "i3en.xlarge" {
+		t.Errorf("Unexpected cached types %v", types)
+	}
+
+	// b is cached as not found.
+	if len(uncached) != 1 || uncached[0] != "c" {
+		t.Errorf("Expected uncached [c], got %v", uncached)
+	}
+
+	// Not found entries expire first.
+	_, uncached = c.get([]string{"a", "b"}, now.Add(2*time.Minute))
+	if len(uncached) != 1 || uncached[0] != "b" {
+		t.Errorf("Expected uncached [b], got %v", uncached)
+	}
+
+	_, uncached = c.get([]string{"a", "b"}, now.Add(2*time.Hour))
+	if len(uncached) != 2 {
+		t.Errorf("Expected uncached [a b], got %v", uncached)
+	}
+
+	c.delete("a")
+	if _, uncached = c.get([]string{"a"}, now); len(uncached) != 1 {
+		t.Errorf("Expected uncached [a], got %v", uncached)
+	}
+}
@@ -2,12 +2,36 @@
 // supported metrics backends.
 package kafkametrics
 
+import "time"
+
 // Handler requests broker metrics and posts events.
 type Handler interface {
 	GetMetrics() (BrokerMetrics, []error)
 	PostEvent(*Event) error
 }
 
+// BrokerCacheInvalidator is implemented by Handlers that cache broker metadata,
+// such as host to broker ID mappings and instance types.
+type BrokerCacheInvalidator interface {
+	// InvalidateBroker drops any cached metadata for the broker ID so that it's
+	// resolved again on the next GetMetrics call.
+	InvalidateBroker(id int)
+}
+
+// CredentialsUpdater is implemented by Handlers whose API credentials can be
+// replaced at runtime, e.g. when rotated in a secrets backend.
+type CredentialsUpdater interface {
+	// UpdateCredentials validates and switches to the API and app keys. The
+	// current keys are retained if validation fails.
+	UpdateCredentials(apiKey, appKey string) error
+}
+
+// MetricsPoster is implemented by Handlers that can submit metrics series to
+// the backend metrics system.
+type MetricsPoster interface {
+	PostMetrics([]*Metric) error
+}
+
 // BrokerMetrics is a map of broker IDs to *Broker structs.
 type BrokerMetrics map[int]*Broker
 
@@ -23,6 +47,10 @@ type Broker struct {
 	NetTX float64
 	// Network rx, window avg.
 	NetRX float64
+	// Tiered storage segment upload bandwidth, window avg; 0 if not queried.
+	TieredUpload float64
+	// Network capacity in MB/s read from a capacity tag; 0 if unknown.
+	Capacity float64
 }
 
 // Event is used to post autothrottle events to the backend metrics system.
@@ -30,4 +58,16 @@ type Event struct {
 	Title string
 	Text  string
 	Tags  []string
+	// AlertType is an optional event severity (ie info, warning, error). The
+	// backend default is used if unset.
+	AlertType string
+}
+
+// Metric is a gauge point used to post autothrottle's own metrics to the
+// backend metrics system.
+type Metric struct {
+	Name  string
+	Value float64
+	Time  time.Time
+	Tags  []string
 }
@@ -0,0 +1,126 @@
+// This file is entirely for tests, but isn't defined as a _test file due to
+// use of the stubs in other packages.
+
+package kafkametrics
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Stub stubs the Handler interface. By default, GetMetrics returns
+// deterministic metrics for brokers 1000-1009 of instance type "stub".
+// Responses can be scripted with Script and posted events and metrics are
+// recorded.
+type Stub struct {
+	mu        sync.Mutex
+	responses []StubResponse
+	events    []*Event
+	metrics   []*Metric
+	// Broker IDs passed to InvalidateBroker.
+	invalidated []int
+}
+
+// StubResponse is a scripted GetMetrics response.
+type StubResponse struct {
+	Metrics BrokerMetrics
+	Errors  []error
+}
+
+// NewStub returns a *Stub.
+func NewStub() *Stub {
+	return &Stub{}
+}
+
+// Script sets the responses returned by subsequent GetMetrics calls, in
+// order. Once all but the last response are consumed, the last response is
+// returned for all further calls.
+func (k *Stub) Script(r ...StubResponse) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.responses = r
+}
+
+// GetMetrics stubs the GetMetrics function.
+func (k *Stub) GetMetrics() (BrokerMetrics, []error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if len(k.responses) == 0 {
+		return StubBrokerMetrics(), nil
+	}
+
+	r := k.responses[0]
+	if len(k.responses) > 1 {
+		k.responses = k.responses[1:]
+	}
+
+	return r.Metrics, r.Errors
+}
+
+// PostEvent stubs the PostEvent function.
+func (k *Stub) PostEvent(e *Event) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.events = append(k.events, e)
+	return nil
+}
+
+// Events returns all posted events, in order.
+func (k *Stub) Events() []*Event {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.events
+}
+
+// PostMetrics stubs the MetricsPoster interface, recording the metrics.
+func (k *Stub) PostMetrics(m []*Metric) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.metrics = append(k.metrics, m...)
+	return nil
+}
+
+// Metrics returns all posted metrics, in order.
+func (k *Stub) Metrics() []*Metric {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.metrics
+}
+
+// InvalidateBroker stubs the BrokerCacheInvalidator interface, recording the
+// broker ID.
+func (k *Stub) InvalidateBroker(id int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.invalidated = append(k.invalidated, id)
+}
+
+// Invalidated returns all broker IDs passed to InvalidateBroker, in order.
+func (k *Stub) Invalidated() []int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.invalidated
+}
+
+// StubBrokerMetrics returns the default Stub BrokerMetrics.
+func StubBrokerMetrics() BrokerMetrics {
+	bm := BrokerMetrics{}
+	for i := 0; i < 10; i++ {
+		bm[1000+i] = &Broker{
+			ID:           1000 + i,
+			Host:         fmt.Sprintf("host%d", i),
+			InstanceType: "stub",
+			NetTX:        100.00 + float64(i),
+		}
+	}
+
+	return bm
+}
@@ -1,30 +1,56 @@
 package kafkametrics
 
 import (
-	"fmt"
+	"errors"
+	"testing"
 )
 
-// Stub stubs tshe
-// Handler interface.
-type Stub struct{}
-
-// GetMetrics stubs the GetMetrics function.
-func (k *Stub) GetMetrics() (BrokerMetrics, []error) {
-	bm := BrokerMetrics{}
-	for i := 0; i < 10; i++ {
-		bm[1000+i] = &Broker{
-			ID:           1000 + i,
-			Host:         fmt.Sprintf("host%d", i),
-			InstanceType: "stub",
-			NetTX:        100.00 + float64(i),
+func TestStubGetMetrics(t *testing.T) {
+	k := NewStub()
+
+	bm, errs := k.GetMetrics()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	if len(bm) != 10 || bm[1009].NetTX != 109.00 {
+		t.Errorf("Unexpected default metrics %v", bm)
+	}
+
+	// Scripted responses are returned in order, repeating the last.
+	failed := StubResponse{Errors: []error{errors.New("timeout")}}
+	partial := StubResponse{Metrics: BrokerMetrics{1000: &Broker{ID: 1000}}}
+	k.Script(failed, partial)
+
+	expected := []int{0, 1, 1}
+	for i, n := range expected {
+		bm, errs := k.GetMetrics()
+		if len(bm) != n {
+			t.Errorf("[call %d] Expected %d brokers, got %d", i, n, len(bm))
+		}
+		if (i == 0) != (errs != nil) {
+			t.Errorf("[call %d] Unexpected errors: %v", i, errs)
 		}
 	}
+}
+
+func TestStubPostEvent(t *testing.T) {
+	k := NewStub()
+	k.PostEvent(&Event{Title: "a"})
+	k.PostEvent(&Event{Title: "b"})
 
-	return bm, nil
+	events := k.Events()
+	if len(events) != 2 || events[0].Title != "a" || events[1].Title != "b" {
+		t.Errorf("Unexpected events %v", events)
+	}
 }
 
-// PostEvent stubs the PostEvent function.
-func (k *Stub) PostEvent(e *Event) error {
-	_ = e
-	return nil
+func TestStubInvalidateBroker(t *testing.T) {
+	k := NewStub()
+	k.InvalidateBroker(1001)
+	k.InvalidateBroker(1002)
+
+	if ids := k.Invalidated(); len(ids) != 2 || ids[0] != 1001 || ids[1] != 1002 {
+		t.Errorf("Unexpected invalidated IDs %v", ids)
+	}
 }
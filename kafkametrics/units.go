@@ -0,0 +1,31 @@
+package kafkametrics
+
+// Units of the per-second network values returned by metrics queries. Broker
+// NetTX and NetRX values are normalized to MB/s.
+const (
+	UnitBytes = "bytes"
+	UnitBits  = "bits"
+	UnitKB    = "kb"
+)
+
+// ValidUnit returns whether s is a supported unit.
+func ValidUnit(s string) bool {
+	switch s {
+	case UnitBytes, UnitBits, UnitKB:
+		return true
+	}
+	return false
+}
+
+// ToMB takes a per-second value in the unit and returns it in MB/s. Values in
+// an unknown unit are treated as bytes.
+func ToMB(v float64, unit string) float64 {
+	switch unit {
+	case UnitBits:
+		return v / 8 / 1024 / 1024
+	case UnitKB:
+		return v / 1024
+	default:
+		return v / 1024 / 1024
+	}
+}
@@ -0,0 +1,35 @@
+package kafkametrics
+
+import (
+	"testing"
+)
+
+func TestToMB(t *testing.T) {
+	tests := []struct {
+		v        float64
+		unit     string
+		expected float64
+	}{
+		{1048576, UnitBytes, 1},
+		{8388608, UnitBits, 1},
+		{1024, UnitKB, 1},
+		// Unknown units are treated as bytes.
+		{1048576, "", 1},
+	}
+
+	for i, test := range tests {
+		if v := ToMB(test.v, test.unit); v != test.expected {
+			t.Errorf("[test %d] Expected %.2f, got %.2f", i, test.expected, v)
+		}
+	}
+
+	for _, u := range []string{UnitBytes, UnitBits, UnitKB} {
+		if !ValidUnit(u) {
+			t.Errorf("Expected %s to be valid", u)
+		}
+	}
+
+	if ValidUnit("mb") {
+		t.Error("Expected mb to be invalid")
+	}
+}
@@ -3,17 +3,20 @@ package kafkazk
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 	"github.com/DataDog/kafka-kit/v4/mapper"
 
 	zkclient "github.com/go-zookeeper/zk"
@@ -29,15 +32,22 @@ type Handler interface {
 	UpdateKafkaConfig(KafkaConfig) ([]bool, error)
 	GetReassignments() Reassignments
 	ListReassignments() (Reassignments, error)
+	SubmitReassignment(*mapper.PartitionMap) error
+	CancelReassignments([]string) (Reassignments, error)
 	GetUnderReplicated() ([]string, error)
 	GetPendingDeletion() ([]string, error)
 	GetTopics([]*regexp.Regexp) ([]string, error)
+	ListTopics(context.Context, []*regexp.Regexp, int) <-chan TopicChunk
 	GetTopicConfig(string) (*TopicConfig, error)
+	GetBrokerConfig(int) (*BrokerConfig, error)
 	GetTopicMetadata(string) (TopicMetadata, error)
 	GetAllBrokerMeta(bool) (mapper.BrokerMetaMap, []error)
 	GetAllPartitionMeta() (mapper.PartitionMetaMap, error)
 	MaxMetaAge() (time.Duration, error)
 	GetPartitionMap(string) (*mapper.PartitionMap, error)
+	Fatal() <-chan error
+	RecursiveDelete(string) error
+	ExportTree(string) (*Znode, error)
 }
 
 // SimpleZooKeeperClient is an interface that wraps a real ZooKeeper client,
@@ -46,10 +56,16 @@ type Handler interface {
 type SimpleZooKeeperClient interface {
 	Exists(string) (bool, error)
 	Create(string, string) error
+	CreateWithACL(string, string, []ACL) error
 	CreateSequential(string, string) error
 	Set(string, string) error
+	SetWithVersion(string, string, int32) error
 	Get(string) ([]byte, error)
+	GetWithVersion(string) ([]byte, int32, error)
+	GetACL(string) ([]ACL, error)
+	SetACL(string, []ACL) error
 	Delete(string) error
+	DeleteWithVersion(string, int32) error
 	Children(string) ([]string, error)
 	NextInt(string) (int32, error)
 	Close()
@@ -58,39 +74,156 @@ type SimpleZooKeeperClient interface {
 
 // ZKHandler implements the Handler interface for real ZooKeeper clusters.
 type ZKHandler struct {
-	client        *zkclient.Conn
+	client        zkConn
+	reconnector   *reconnector
 	Connect       string
 	Prefix        string
 	MetricsPrefix string
 }
 
 // Config holds initialization paramaters for a Handler. Connect is a ZooKeeper
-// connect string. Prefix should reflect any prefix used for Kafka on the
-// reference ZooKeeper cluster (excluding slashes). MetricsPrefix is the prefix
-// used for broker metrics metadata persisted in ZooKeeper.
+// connect string in the standard host:port[,host:port...][/chroot] form.
+// Prefix should reflect any prefix used for Kafka on the reference ZooKeeper
+// cluster (excluding slashes); it's derived from the connect string chroot if
+// one is present. A chroot must exist unless CreateChroot is set, in which
+// case it's created. MetricsPrefix is the prefix used for broker metrics
+// metadata persisted in ZooKeeper. Auth optionally specifies credentials in
+// the scheme:credentials form (e.g. digest:user:pass) to authenticate the
+// session with, for use with ACL protected znodes. Reconnect configures the
+// backoff between connection attempts; DefaultReconnectConfig is used if
+// unset. Timeouts configures the session, connect and request timeouts;
+// DefaultTimeoutConfig values are used for those unset.
 type Config struct {
 	Connect       string
 	Prefix        string
+	CreateChroot  bool
 	MetricsPrefix string
+	Auth          string
+	Reconnect     ReconnectConfig
+	Timeouts      TimeoutConfig
 }
 
 // NewHandler takes a *Config, performs any initialization and returns a Handler.
 func NewHandler(c *Config) (Handler, error) {
 	z := &ZKHandler{
+		reconnector:   newReconnector(c.Reconnect),
 		Connect:       c.Connect,
 		Prefix:        c.Prefix,
 		MetricsPrefix: c.MetricsPrefix,
 	}
 
-	var err error
-	z.client, _, err = zkclient.Connect([]string{z.Connect}, 10*time.Second, zkclient.WithLogInfo(false))
+	servers, chroot, err := ParseConnect(c.Connect)
 	if err != nil {
 		return nil, err
 	}
 
+	if chroot != "" {
+		if p := strings.Trim(c.Prefix, "/"); p != "" && p != chroot {
+			return nil, fmt.Errorf("prefix %s conflicts with connect string chroot /%s", c.Prefix, chroot)
+		}
+		z.Prefix = chroot
+	}
+
+	timeouts := c.Timeouts
+	if timeouts.Session <= 0 {
+		timeouts.Session = DefaultTimeoutConfig().Session
+	}
+	if timeouts.Connect <= 0 {
+		timeouts.Connect = DefaultTimeoutConfig().Connect
+	}
+
+	// The client dials with a fixed timeout; override it with the configured
+	// connect timeout.
+	dialer := func(network, address string, _ time.Duration) (net.Conn, error) {
+		return z.reconnector.dialer(network, address, timeouts.Connect)
+	}
+
+	conn, _, err := zkclient.Connect(servers, timeouts.Session,
+		zkclient.WithLogInfo(false),
+		zkclient.WithDialer(dialer),
+		zkclient.WithEventCallback(z.reconnector.event),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	z.client = &timeoutConn{zkConn: conn, read: timeouts.Read, write: timeouts.Write}
+
+	if c.Auth != "" {
+		scheme, creds, ok := strings.Cut(c.Auth, ":")
+		if !ok || scheme == "" || creds == "" {
+			z.Close()
+			return nil, errors.New("invalid auth: expected scheme:credentials")
+		}
+
+		if err := z.client.AddAuth(scheme, []byte(creds)); err != nil {
+			z.Close()
+			return nil, fmt.Errorf("error adding %s auth: %s", scheme, err)
+		}
+	}
+
+	if chroot != "" {
+		if err := z.ensureChroot(c.CreateChroot); err != nil {
+			z.Close()
+			return nil, err
+		}
+	}
+
 	return z, nil
 }
 
+// ParseConnect parses a ZooKeeper connect string in the
+// host:port[,host:port...][/chroot] form, returning the list of servers and the
+// chroot, if any, without leading or trailing slashes.
+func ParseConnect(s string) ([]string, string, error) {
+	hosts, chroot, _ := strings.Cut(s, "/")
+
+	var servers []string
+	for _, h := range strings.Split(hosts, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			servers = append(servers, h)
+		}
+	}
+
+	if len(servers) == 0 {
+		return nil, "", fmt.Errorf("invalid connect string '%s': no servers specified", s)
+	}
+
+	chroot = strings.Trim(chroot, "/")
+	if strings.Contains(chroot, "//") {
+		return nil, "", fmt.Errorf("invalid connect string '%s': invalid chroot", s)
+	}
+
+	return servers, chroot, nil
+}
+
+// ensureChroot checks that the chroot znode exists, creating it and any
+// missing parents if create is true.
+func (z *ZKHandler) ensureChroot(create bool) error {
+	var p string
+	for _, part := range strings.Split(z.Prefix, "/") {
+		p = p + "/" + part
+
+		exists, err := z.Exists(p)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case exists:
+			continue
+		case !create:
+			return fmt.Errorf("chroot /%s doesn't exist", z.Prefix)
+		}
+
+		if err := z.Create(p, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Ready returns true if the client is in either state StateConnected or
 // StateHasSession. See https://godoc.org/github.com/go-zookeeper/zk#State.
 func (z *ZKHandler) Ready() bool {
@@ -105,9 +238,16 @@ func (z *ZKHandler) Ready() bool {
 // Close calls close on the *ZKHandler. Any additional shutdown cleanup or other
 // tasks should be performed here.
 func (z *ZKHandler) Close() {
+	z.reconnector.close()
 	z.client.Close()
 }
 
+// Fatal returns a channel that receives an error if the ZooKeeper connection
+// can't be re-established within the configured retry budget.
+func (z *ZKHandler) Fatal() <-chan error {
+	return z.reconnector.fatal
+}
+
 // Get returns the data from path p.
 func (z *ZKHandler) Get(p string) ([]byte, error) {
 	r, _, e := z.client.Get(p)
@@ -150,6 +290,61 @@ func (z *ZKHandler) Delete(p string) error {
 	return nil
 }
 
+// GetWithVersion returns the data at path p along with the znode version, for
+// use with SetWithVersion and DeleteWithVersion.
+func (z *ZKHandler) GetWithVersion(p string) ([]byte, int32, error) {
+	r, s, e := z.client.Get(p)
+	if e != nil {
+		return nil, 0, versionedOpErr(p, e)
+	}
+
+	return r, s.Version, nil
+}
+
+// SetWithVersion sets the data at path p if the znode is at version v. An
+// ErrBadVersion is returned if the znode was modified since it was read.
+func (z *ZKHandler) SetWithVersion(p string, d string, v int32) error {
+	if _, e := z.client.Set(p, []byte(d), v); e != nil {
+		return versionedOpErr(p, e)
+	}
+
+	return nil
+}
+
+// DeleteWithVersion deletes the znode at path p if it's at version v. An
+// ErrBadVersion is returned if the znode was modified since it was read.
+func (z *ZKHandler) DeleteWithVersion(p string, v int32) error {
+	if e := z.client.Delete(p, v); e != nil {
+		return versionedOpErr(p, e)
+	}
+
+	return nil
+}
+
+// versionedOpErr wraps errors returned by versioned operations on path p.
+func versionedOpErr(p string, e error) error {
+	switch e {
+	case zkclient.ErrNoNode:
+		return ErrNoNode{s: fmt.Sprintf("[%s] %s", p, e.Error())}
+	case zkclient.ErrBadVersion:
+		return ErrBadVersion{s: fmt.Sprintf("[%s] %s", p, e.Error())}
+	default:
+		return fmt.Errorf("[%s] %s", p, e.Error())
+	}
+}
+
+// RecursiveDelete deletes the znode at path p along with all of its
+// descendants.
+func (z *ZKHandler) RecursiveDelete(p string) error {
+	return recursiveDelete(z, p)
+}
+
+// ExportTree returns the znode at path p along with all of its descendants as
+// a *Znode, suitable for serializing to JSON.
+func (z *ZKHandler) ExportTree(p string) (*Znode, error) {
+	return exportTree(z, p)
+}
+
 // CreateSequential takes a path p and data d and creates a sequential znode at
 // p with data d. An error is returned if encountered.
 func (z *ZKHandler) CreateSequential(p string, d string) error {
@@ -178,6 +373,52 @@ func (z *ZKHandler) Create(p string, d string) error {
 	return nil
 }
 
+// CreateWithACL creates the provided path p with the data from the provided
+// string d and the ACL acl, returning an error if encountered.
+func (z *ZKHandler) CreateWithACL(p string, d string, acl []ACL) error {
+	_, e := z.client.Create(p, []byte(d), 0, toZKACL(acl))
+	if e != nil {
+		switch e {
+		case zkclient.ErrNoNode:
+			return ErrNoNode{s: fmt.Sprintf("[%s] %s", p, e.Error())}
+		default:
+			return fmt.Errorf("[%s] %s", p, e.Error())
+		}
+	}
+
+	return nil
+}
+
+// GetACL returns the ACL of the znode at path p.
+func (z *ZKHandler) GetACL(p string) ([]ACL, error) {
+	acl, _, e := z.client.GetACL(p)
+	if e != nil {
+		switch e {
+		case zkclient.ErrNoNode:
+			return nil, ErrNoNode{s: fmt.Sprintf("[%s] %s", p, e.Error())}
+		default:
+			return nil, fmt.Errorf("[%s] %s", p, e.Error())
+		}
+	}
+
+	return fromZKACL(acl), nil
+}
+
+// SetACL sets the ACL of the znode at path p.
+func (z *ZKHandler) SetACL(p string, acl []ACL) error {
+	_, e := z.client.SetACL(p, toZKACL(acl), -1)
+	if e != nil {
+		switch e {
+		case zkclient.ErrNoNode:
+			return ErrNoNode{s: fmt.Sprintf("[%s] %s", p, e.Error())}
+		default:
+			return fmt.Errorf("[%s] %s", p, e.Error())
+		}
+	}
+
+	return nil
+}
+
 // Exists takes a path p and returns a bool as to whether the path exists and
 // an error if encountered.
 func (z *ZKHandler) Exists(p string) (bool, error) {
@@ -244,6 +485,28 @@ func (z *ZKHandler) GetReassignments() Reassignments {
 	return reassigns
 }
 
+// SubmitReassignment takes a *mapper.PartitionMap and submits it as a partition
+// reassignment. ErrReassignmentInProgress is returned if a reassignment is
+// already in progress.
+func (z *ZKHandler) SubmitReassignment(pm *mapper.PartitionMap) error {
+	data, err := json.Marshal(pm)
+	if err != nil {
+		return fmt.Errorf("error marshalling reassignment: %s", err)
+	}
+
+	path := z.getPath("/admin/reassign_partitions")
+
+	_, err = z.client.Create(path, data, 0, zkclient.WorldACL(31))
+	switch err {
+	case nil:
+		return nil
+	case zkclient.ErrNodeExists:
+		return ErrReassignmentInProgress
+	default:
+		return fmt.Errorf("[%s] %s", path, err)
+	}
+}
+
 // ListReassignments looks up any ongoing topic reassignments and returns the data
 // as a Reassignments. ListReassignments is a KIP-455 compatible call for Kafka
 // 2.4 and Kafka cli tools 2.6.
@@ -322,6 +585,16 @@ func (z *ZKHandler) GetTopics(ts []*regexp.Regexp) ([]string, error) {
 	return matchingTopics, nil
 }
 
+// ListTopics takes a []*regexp.Regexp and a chunk size and streams the states
+// of all matching topics in chunks over the returned channel, rather than
+// loading every topic state at once. Chunks are fetched as they're received;
+// the channel is closed once all topics have been sent, after a TopicChunk
+// with a non-nil Err, or when the context is cancelled. A non-positive chunk
+// size uses DefaultTopicChunkSize.
+func (z *ZKHandler) ListTopics(ctx context.Context, ts []*regexp.Regexp, size int) <-chan TopicChunk {
+	return listTopics(ctx, z, ts, size)
+}
+
 // GetTopicMetadata takes a topic name. If the topic exists, the topic metadata
 // is returned as a TopicMetadata.
 func (z *ZKHandler) GetTopicMetadata(t string) (TopicMetadata, error) {
@@ -367,6 +640,23 @@ func (z *ZKHandler) GetTopicConfig(t string) (*TopicConfig, error) {
 	return config, nil
 }
 
+// GetBrokerConfig takes a broker ID and returns a *BrokerConfig holding the
+// broker's dynamic configs.
+func (z *ZKHandler) GetBrokerConfig(id int) (*BrokerConfig, error) {
+	config := &BrokerConfig{}
+	path := z.getPath(fmt.Sprintf("/config/brokers/%d", id))
+
+	// Get broker config.
+	data, err := z.Get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(data, config)
+
+	return config, nil
+}
+
 // GetAllBrokerMeta looks up all registered Kafka brokers and returns their
 // metadata as a mapper.BrokerMetaMap. A withMetrics bool param determines whether
 // we additionally want to fetch stored broker metrics.
@@ -405,6 +695,13 @@ func (z *ZKHandler) GetAllBrokerMeta(withMetrics bool) (mapper.BrokerMetaMap, []
 			continue
 		}
 
+		// Parse the listener endpoints. Endpoints are left unset if the
+		// registration data can't be parsed rather than failing the lookup.
+		reg := brokerRegistration{}
+		if err := json.Unmarshal(data, &reg); err == nil {
+			bm.Endpoints, _ = kafkaadmin.ParseBrokerEndpoints(reg.Endpoints, reg.ListenerSecurityProtocolMap)
+		}
+
 		bmm[bid] = bm
 	}
 
@@ -0,0 +1,145 @@
+package kafkazk
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+
+	zkclient "github.com/go-zookeeper/zk"
+)
+
+// Znode permissions.
+const (
+	PermRead   int32 = zkclient.PermRead
+	PermWrite  int32 = zkclient.PermWrite
+	PermCreate int32 = zkclient.PermCreate
+	PermDelete int32 = zkclient.PermDelete
+	PermAdmin  int32 = zkclient.PermAdmin
+	PermAll    int32 = zkclient.PermAll
+)
+
+// permChars maps the characters used in ACL strings to permissions, in the
+// order used by the ZooKeeper cli.
+var permChars = []struct {
+	c    byte
+	perm int32
+}{
+	{'c', PermCreate},
+	{'d', PermDelete},
+	{'r', PermRead},
+	{'w', PermWrite},
+	{'a', PermAdmin},
+}
+
+// ACL is a znode access control list entry, granting Perms to the identity ID
+// under the authentication scheme Scheme.
+type ACL struct {
+	Scheme string
+	ID     string
+	Perms  int32
+}
+
+// String returns the ACL in the scheme:id:perms form used by the ZooKeeper
+// cli, e.g. "world:anyone:cdrwa".
+func (a ACL) String() string {
+	var perms []byte
+	for _, p := range permChars {
+		if a.Perms&p.perm != 0 {
+			perms = append(perms, p.c)
+		}
+	}
+
+	return fmt.Sprintf("%s:%s:%s", a.Scheme, a.ID, perms)
+}
+
+// WorldACL returns an ACL granting perms to everyone.
+func WorldACL(perms int32) []ACL {
+	return []ACL{{Scheme: "world", ID: "anyone", Perms: perms}}
+}
+
+// DigestACL returns an ACL granting perms to clients authenticated with the
+// digest scheme as user with password.
+func DigestACL(perms int32, user, password string) []ACL {
+	h := sha1.Sum([]byte(user + ":" + password))
+	id := user + ":" + base64.StdEncoding.EncodeToString(h[:])
+	return []ACL{{Scheme: "digest", ID: id, Perms: perms}}
+}
+
+// ParseACL parses a comma delimited list of ACLs in the scheme:id:perms form
+// used by the ZooKeeper cli, e.g. "digest:user:<hash>:cdrwa,world:anyone:r".
+func ParseACL(s string) ([]ACL, error) {
+	var acl []ACL
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		// The ID may itself contain colons (e.g. digest user:hash IDs).
+		first, last := strings.Index(entry, ":"), strings.LastIndex(entry, ":")
+		if first <= 0 || last == first {
+			return nil, fmt.Errorf("invalid ACL '%s': expected scheme:id:perms", entry)
+		}
+
+		a := ACL{Scheme: entry[:first], ID: entry[first+1 : last]}
+
+	PERMS:
+		for _, c := range []byte(entry[last+1:]) {
+			for _, p := range permChars {
+				if c == p.c {
+					a.Perms |= p.perm
+					continue PERMS
+				}
+			}
+			return nil, fmt.Errorf("invalid ACL '%s': unknown permission '%c'", entry, c)
+		}
+
+		if a.Perms == 0 {
+			return nil, fmt.Errorf("invalid ACL '%s': no permissions", entry)
+		}
+
+		acl = append(acl, a)
+	}
+
+	if len(acl) == 0 {
+		return nil, fmt.Errorf("no ACLs specified")
+	}
+
+	return acl, nil
+}
+
+// CreateWithParentACL creates the path p with the data d, using the ACL of the
+// parent znode. This allows znodes beneath a protected znode to be created
+// with the same protection. If the parent ACL can't be read, the znode is
+// created with the default ACL.
+func CreateWithParentACL(zk SimpleZooKeeperClient, p string, d string) error {
+	acl, err := zk.GetACL(path.Dir(p))
+	if err != nil || len(acl) == 0 {
+		return zk.Create(p, d)
+	}
+
+	return zk.CreateWithACL(p, d, acl)
+}
+
+// toZKACL converts an []ACL to the ZooKeeper client type.
+func toZKACL(acl []ACL) []zkclient.ACL {
+	zacl := make([]zkclient.ACL, len(acl))
+	for i, a := range acl {
+		zacl[i] = zkclient.ACL{Scheme: a.Scheme, ID: a.ID, Perms: a.Perms}
+	}
+
+	return zacl
+}
+
+// fromZKACL converts a ZooKeeper client []ACL to an []ACL.
+func fromZKACL(zacl []zkclient.ACL) []ACL {
+	acl := make([]ACL, len(zacl))
+	for i, a := range zacl {
+		acl[i] = ACL{Scheme: a.Scheme, ID: a.ID, Perms: a.Perms}
+	}
+
+	return acl
+}
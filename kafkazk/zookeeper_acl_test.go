@@ -0,0 +1,77 @@
+package kafkazk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseACL(t *testing.T) {
+	acl, err := ParseACL("digest:user:hash=:cdrwa, world:anyone:r")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ACL{
+		{Scheme: "digest", ID: "user:hash=", Perms: PermAll},
+		{Scheme: "world", ID: "anyone", Perms: PermRead},
+	}
+
+	if !reflect.DeepEqual(acl, expected) {
+		t.Errorf("Expected %v, got %v", expected, acl)
+	}
+
+	for _, s := range []string{"", "world:anyone", "world:anyone:", "world:anyone:x", ":anyone:r"} {
+		if _, err := ParseACL(s); err == nil {
+			t.Errorf("Expected error parsing '%s'", s)
+		}
+	}
+}
+
+func TestACLString(t *testing.T) {
+	tests := map[string]ACL{
+		"world:anyone:cdrwa": WorldACL(PermAll)[0],
+		"ip:10.0.0.1:rw":     {Scheme: "ip", ID: "10.0.0.1", Perms: PermRead | PermWrite},
+	}
+
+	for expected, acl := range tests {
+		if s := acl.String(); s != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, s)
+		}
+	}
+}
+
+func TestDigestACL(t *testing.T) {
+	acl := DigestACL(PermRead, "user", "password")
+	// Matches the ZooKeeper DigestAuthenticationProvider.
+	expected := ACL{Scheme: "digest", ID: "user:tpUq/4Pn5A64fVZyQ0gOJ8ZWqkY=", Perms: PermRead}
+
+	if len(acl) != 1 || acl[0] != expected {
+		t.Errorf("Expected %v, got %v", expected, acl)
+	}
+}
+
+func TestCreateWithParentACL(t *testing.T) {
+	zk := NewZooKeeperStub()
+	acl := WorldACL(PermRead)
+
+	if err := zk.CreateWithACL("/parent", "", acl); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CreateWithParentACL(zk, "/parent/child", "data"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := zk.GetACL("/parent/child")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, acl) {
+		t.Errorf("Expected %v, got %v", acl, got)
+	}
+
+	if d, _ := zk.Get("/parent/child"); string(d) != "data" {
+		t.Errorf("Expected data 'data', got '%s'", d)
+	}
+}
@@ -0,0 +1,222 @@
+package kafkazk
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// CancelReassignments cancels the in-flight reassignments for the provided
+// topics, or all reassignments if none are provided, and returns the
+// reassignments that were cancelled. The Kafka client library doesn't support
+// AlterPartitionReassignments, so reassignments are rolled back through
+// ZooKeeper: cancelled partitions are removed from /admin/reassign_partitions,
+// each partition's replicas are restored to the original assignment in the
+// topic znode, and the /controller znode is deleted to force a controller
+// failover so that the rolled back state is loaded.
+//
+// The original assignment is derived from the adding_replicas topic metadata
+// (Kafka 2.4+). On older clusters, replicas being added that haven't yet
+// joined the ISR are removed; new replicas that are already in sync are kept.
+func (z *ZKHandler) CancelReassignments(topics []string) (Reassignments, error) {
+	cancelled := Reassignments{}
+
+	// Reassignments may have been submitted through the reassign_partitions
+	// znode or the Kafka API (found in the topic metadata).
+	running, err := z.ListReassignments()
+	if err != nil {
+		return nil, err
+	}
+
+	for t, partitions := range z.GetReassignments() {
+		if running[t] == nil {
+			running[t] = map[int][]int{}
+		}
+		for p, replicas := range partitions {
+			running[t][p] = replicas
+		}
+	}
+
+	for t, partitions := range running {
+		if len(topics) == 0 || inStrings(t, topics) {
+			cancelled[t] = partitions
+		}
+	}
+
+	if len(cancelled) == 0 {
+		return cancelled, nil
+	}
+
+	// Remove the cancelled partitions from the reassign_partitions znode first
+	// so that a new controller doesn't resume them.
+	if err := z.removeReassignPartitions(cancelled); err != nil {
+		return nil, err
+	}
+
+	// Restore the original assignments.
+	for t, partitions := range cancelled {
+		path := z.getPath("/brokers/topics/" + t)
+
+		data, err := z.Get(path)
+		if err != nil {
+			return nil, err
+		}
+
+		isr, err := z.GetTopicStateISR(t)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := rollbackTopic(data, partitions, isr)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] %s", t, err)
+		}
+
+		if err := z.Set(path, string(updated)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Force a controller failover.
+	controller := z.getPath("/controller")
+	if exists, err := z.Exists(controller); err != nil {
+		return nil, err
+	} else if exists {
+		if err := z.Delete(controller); err != nil {
+			return nil, err
+		}
+	}
+
+	return cancelled, nil
+}
+
+// removeReassignPartitions removes the partitions in r from the
+// reassign_partitions znode, deleting the znode if no partitions remain.
+func (z *ZKHandler) removeReassignPartitions(r Reassignments) error {
+	path := z.getPath("/admin/reassign_partitions")
+
+	data, err := z.Get(path)
+	switch err.(type) {
+	case nil:
+	case ErrNoNode:
+		return nil
+	default:
+		return err
+	}
+
+	rec := &reassignPartitions{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return fmt.Errorf("error unmarshalling reassignment: %s", err)
+	}
+
+	var remaining []reassignConfig
+	for _, cfg := range rec.Partitions {
+		if _, cancelled := r[cfg.Topic][cfg.Partition]; !cancelled {
+			remaining = append(remaining, cfg)
+		}
+	}
+
+	if len(remaining) == 0 {
+		return z.Delete(path)
+	}
+
+	updated, err := json.Marshal(struct {
+		Version    int              `json:"version"`
+		Partitions []reassignConfig `json:"partitions"`
+	}{1, remaining})
+	if err != nil {
+		return fmt.Errorf("error marshalling reassignment: %s", err)
+	}
+
+	return z.Set(path, string(updated))
+}
+
+// rollbackTopic takes the data from a /brokers/topics/<topic> znode, the
+// cancelled partition reassignments for the topic and the topic ISR state,
+// and returns the znode data with each cancelled partition restored to its
+// original replicas. Any fields in the data other than those describing the
+// assignment are preserved.
+func rollbackTopic(data []byte, cancelled map[int][]int, isr TopicStateISR) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error unmarshalling topic metadata: %s", err)
+	}
+
+	assignment := map[string]map[string][]int{}
+	for _, field := range []string{"partitions", "adding_replicas", "removing_replicas"} {
+		m := map[string][]int{}
+		if v, exists := raw[field]; exists {
+			if err := json.Unmarshal(v, &m); err != nil {
+				return nil, fmt.Errorf("error unmarshalling topic metadata %s: %s", field, err)
+			}
+		}
+		assignment[field] = m
+	}
+
+	// Roll back partitions in a stable order.
+	var ids []int
+	for p := range cancelled {
+		ids = append(ids, p)
+	}
+	sort.Ints(ids)
+
+	for _, p := range ids {
+		pn := strconv.Itoa(p)
+		replicas, exists := assignment["partitions"][pn]
+		if !exists {
+			return nil, fmt.Errorf("partition %d not found", p)
+		}
+
+		// Replicas to remove; those being added where known, otherwise target
+		// replicas that aren't in sync.
+		adding, known := assignment["adding_replicas"][pn]
+		if !known {
+			for _, id := range cancelled[p] {
+				if !inIntSlice(id, isr[pn].ISR) {
+					adding = append(adding, id)
+				}
+			}
+		}
+
+		var original []int
+		for _, id := range replicas {
+			if !inIntSlice(id, adding) {
+				original = append(original, id)
+			}
+		}
+
+		if len(original) == 0 {
+			return nil, fmt.Errorf("partition %d: no original replicas remain", p)
+		}
+
+		assignment["partitions"][pn] = original
+		delete(assignment["adding_replicas"], pn)
+		delete(assignment["removing_replicas"], pn)
+	}
+
+	for field, m := range assignment {
+		// Don't add fields absent from the original metadata.
+		if _, exists := raw[field]; !exists && len(m) == 0 {
+			continue
+		}
+
+		v, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		raw[field] = v
+	}
+
+	return json.Marshal(raw)
+}
+
+func inStrings(s string, l []string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package kafkazk
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRollbackTopic(t *testing.T) {
+	// Partition 0 is moving from 1001,1002 to 1003,1002 with adding_replicas
+	// metadata; partition 1 is moving from 1002,1003 to 1004,1005 without.
+	data := []byte(`{"version":2,"topic_id":"abc","partitions":{"0":[1003,1002,1001],"1":[1004,1005,1002,1003],"2":[1001,1002]},` +
+		`"adding_replicas":{"0":[1003]},"removing_replicas":{"0":[1001]}}`)
+
+	cancelled := map[int][]int{
+		0: {1003, 1002},
+		1: {1004, 1005},
+	}
+
+	// 1005 has caught up and is kept.
+	isr := TopicStateISR{
+		"1": PartitionState{ISR: []int{1002, 1003, 1005}},
+	}
+
+	updated, err := rollbackTopic(data, cancelled, isr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		TopicID          string           `json:"topic_id"`
+		Partitions       map[string][]int `json:"partitions"`
+		AddingReplicas   map[string][]int `json:"adding_replicas"`
+		RemovingReplicas map[string][]int `json:"removing_replicas"`
+	}
+
+	if err := json.Unmarshal(updated, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]int{
+		"0": {1002, 1001},
+		"1": {1005, 1002, 1003},
+		"2": {1001, 1002},
+	}
+
+	if !reflect.DeepEqual(got.Partitions, expected) {
+		t.Errorf("Expected partitions %v, got %v", expected, got.Partitions)
+	}
+
+	if len(got.AddingReplicas) != 0 || len(got.RemovingReplicas) != 0 {
+		t.Errorf("Expected empty adding and removing replicas, got %v and %v", got.AddingReplicas, got.RemovingReplicas)
+	}
+
+	// Other fields are preserved.
+	if got.TopicID != "abc" {
+		t.Errorf("Expected topic_id abc, got '%s'", got.TopicID)
+	}
+
+	// Unknown partitions.
+	if _, err := rollbackTopic(data, map[int][]int{5: {1001}}, isr); err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
+
+func TestStubCancelReassignments(t *testing.T) {
+	zk := NewZooKeeperStub()
+	zk.SetReassignments(Reassignments{
+		"a": {0: {1001, 1002}},
+		"b": {0: {1003, 1004}},
+	})
+
+	cancelled, err := zk.CancelReassignments([]string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, exists := cancelled["a"]; len(cancelled) != 1 || !exists {
+		t.Errorf("Expected topic a cancelled, got %v", cancelled)
+	}
+
+	if r := zk.GetReassignments(); len(r) != 1 || r["b"] == nil {
+		t.Errorf("Expected topic b reassigning, got %v", r)
+	}
+
+	// Cancel all.
+	if cancelled, _ = zk.CancelReassignments(nil); len(cancelled) != 1 {
+		t.Errorf("Expected 1 topic cancelled, got %v", cancelled)
+	}
+
+	if r := zk.GetReassignments(); len(r) != 0 {
+		t.Errorf("Expected no reassignments, got %v", r)
+	}
+}
@@ -0,0 +1,71 @@
+package kafkazk
+
+import (
+	"strings"
+)
+
+// Topic throttled replicas config names.
+const (
+	TopicThrottledLeadersConfig   = "leader.replication.throttled.replicas"
+	TopicThrottledFollowersConfig = "follower.replication.throttled.replicas"
+)
+
+// TopicThrottleConfig holds a topic's dynamic configs along with its parsed
+// throttled replicas configs.
+type TopicThrottleConfig struct {
+	// All dynamic configs of the topic, as stored.
+	Config map[string]string
+	// The leader and follower throttled replicas in partition:broker form. A
+	// single "*" entry throttles all replicas. Nil if unset.
+	Leaders   []string
+	Followers []string
+}
+
+// NewTopicThrottleConfig takes a topic's dynamic configs and returns a
+// TopicThrottleConfig.
+func NewTopicThrottleConfig(config map[string]string) TopicThrottleConfig {
+	return TopicThrottleConfig{
+		Config:    config,
+		Leaders:   ParseThrottledReplicas(config[TopicThrottledLeadersConfig]),
+		Followers: ParseThrottledReplicas(config[TopicThrottledFollowersConfig]),
+	}
+}
+
+// Throttled returns whether either throttled replicas config is set.
+func (c TopicThrottleConfig) Throttled() bool {
+	return len(c.Leaders) > 0 || len(c.Followers) > 0
+}
+
+// ParseThrottledReplicas takes a comma-delimited throttled replicas config
+// value and returns the replicas listed, in order. Nil is returned if the
+// value is empty.
+func ParseThrottledReplicas(s string) []string {
+	var replicas []string
+	for _, r := range strings.Split(s, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			replicas = append(replicas, r)
+		}
+	}
+
+	return replicas
+}
+
+// GetTopicThrottleConfigs takes a Handler and a list of topic names and
+// returns a TopicThrottleConfig for each topic. Topics without a config
+// znode, such as those that never had dynamic configs set, are omitted.
+func GetTopicThrottleConfigs(zk Handler, topics []string) (map[string]TopicThrottleConfig, error) {
+	configs := make(map[string]TopicThrottleConfig, len(topics))
+
+	for _, topic := range topics {
+		config, err := zk.GetTopicConfig(topic)
+		switch err.(type) {
+		case nil:
+			configs[topic] = NewTopicThrottleConfig(config.Config)
+		case ErrNoNode:
+		default:
+			return nil, err
+		}
+	}
+
+	return configs, nil
+}
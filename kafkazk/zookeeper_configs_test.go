@@ -0,0 +1,72 @@
+package kafkazk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseThrottledReplicas(t *testing.T) {
+	tests := map[string][]string{
+		"":                 nil,
+		"*":                {"*"},
+		"0:1001,1:1002":    {"0:1001", "1:1002"},
+		"0:1001, 1:1002,,": {"0:1001", "1:1002"},
+		"1:1002,0:1001":    {"1:1002", "0:1001"},
+	}
+
+	for input, expected := range tests {
+		if got := ParseThrottledReplicas(input); !reflect.DeepEqual(got, expected) {
+			t.Errorf("[%q] Expected %v, got %v", input, expected, got)
+		}
+	}
+}
+
+func TestNewTopicThrottleConfig(t *testing.T) {
+	c := NewTopicThrottleConfig(map[string]string{
+		"retention.ms":              "172800000",
+		TopicThrottledLeadersConfig: "*",
+	})
+
+	if !reflect.DeepEqual(c.Leaders, []string{"*"}) {
+		t.Errorf("Expected leaders [*], got %v", c.Leaders)
+	}
+
+	if c.Followers != nil {
+		t.Errorf("Expected nil followers, got %v", c.Followers)
+	}
+
+	if !c.Throttled() {
+		t.Error("Expected throttled config")
+	}
+
+	if NewTopicThrottleConfig(map[string]string{"retention.ms": "172800000"}).Throttled() {
+		t.Error("Expected unthrottled config")
+	}
+}
+
+func TestGetTopicThrottleConfigs(t *testing.T) {
+	zk := NewZooKeeperStub()
+
+	configs, err := GetTopicThrottleConfigs(zk, []string{"test_topic", "test_topic2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(configs) != 2 {
+		t.Fatalf("Expected 2 configs, got %d", len(configs))
+	}
+
+	c := configs["test_topic"]
+
+	if c.Config["retention.ms"] != "172800000" {
+		t.Errorf("Expected retention.ms 172800000, got %s", c.Config["retention.ms"])
+	}
+
+	if expected := []string{"0:1001", "0:1002"}; !reflect.DeepEqual(c.Leaders, expected) {
+		t.Errorf("Expected leaders %v, got %v", expected, c.Leaders)
+	}
+
+	if expected := []string{"0:1003", "0:1004"}; !reflect.DeepEqual(c.Followers, expected) {
+		t.Errorf("Expected followers %v, got %v", expected, c.Followers)
+	}
+}
@@ -2,12 +2,16 @@ package kafkazk
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 )
 
 var (
 	// ErrInvalidKafkaConfigType error.
 	ErrInvalidKafkaConfigType = errors.New("Invalid Kafka config type")
+	// ErrReassignmentInProgress is returned when submitting a reassignment
+	// while another is in progress.
+	ErrReassignmentInProgress = errors.New("a reassignment is already in progress")
 	// validKafkaConfigTypes is used as a set to define valid configuration
 	// type names.
 	validKafkaConfigTypes = map[string]struct{}{
@@ -27,3 +31,26 @@ type ErrNoNode struct {
 func (e ErrNoNode) Error() string {
 	return e.s
 }
+
+// NewErrNoNode returns an ErrNoNode for the path p. It's intended for Handler
+// implementations that aren't backed by ZooKeeper.
+func NewErrNoNode(p string) ErrNoNode {
+	return ErrNoNode{s: fmt.Sprintf("[%s] node does not exist", p)}
+}
+
+// ErrBadVersion error type is for versioned writes where the znode version
+// doesn't match the expected version, i.e. the znode was modified since it
+// was read.
+type ErrBadVersion struct {
+	s string
+}
+
+func (e ErrBadVersion) Error() string {
+	return e.s
+}
+
+// NewErrBadVersion returns an ErrBadVersion for the path p. It's intended for
+// Handler implementations that aren't backed by ZooKeeper.
+func NewErrBadVersion(p string) ErrBadVersion {
+	return ErrBadVersion{s: fmt.Sprintf("[%s] version conflict", p)}
+}
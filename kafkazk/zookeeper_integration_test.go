@@ -5,9 +5,11 @@ package kafkazk
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"regexp"
 	"sort"
 	"testing"
@@ -301,6 +303,98 @@ func TestCreateSetGetDelete(t *testing.T) {
 	}
 }
 
+func TestCreateWithACL(t *testing.T) {
+	acl := WorldACL(PermRead | PermWrite | PermDelete | PermAdmin)
+
+	err := zki.CreateWithACL("/test_acl", "", acl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := zki.GetACL("/test_acl")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(got) != 1 || got[0] != acl[0] {
+		t.Errorf("Expected ACL %v, got %v", acl, got)
+	}
+
+	// Without the create permission, child znodes can't be created.
+	if err := zki.Create("/test_acl/child", ""); err == nil {
+		t.Error("Expected error creating child znode")
+	}
+
+	acl = WorldACL(PermAll)
+	if err := zki.SetACL("/test_acl", acl); err != nil {
+		t.Error(err)
+	}
+
+	got, err = zki.GetACL("/test_acl")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(got) != 1 || got[0] != acl[0] {
+		t.Errorf("Expected ACL %v, got %v", acl, got)
+	}
+
+	if err := zki.Delete("/test_acl"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExportTreeRecursiveDelete(t *testing.T) {
+	for _, p := range []string{"/test_tree", "/test_tree/a", "/test_tree/a/b"} {
+		if err := zki.Create(p, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := zki.ExportTree("/test_tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n.Data != "/test_tree" || len(n.Children) != 1 || n.Children[0].Children[0].Path != "/test_tree/a/b" {
+		t.Errorf("Unexpected tree %+v", n)
+	}
+
+	if err := zki.RecursiveDelete("/test_tree"); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, _ := zki.Exists("/test_tree"); exists {
+		t.Error("Expected /test_tree to be deleted")
+	}
+}
+
+func TestChroot(t *testing.T) {
+	connect := zkaddr + "/test_chroot/kafka"
+
+	if _, err := NewHandler(&Config{Connect: connect}); err == nil {
+		t.Error("Expected error for non-existent chroot")
+	}
+
+	if _, err := NewHandler(&Config{Connect: connect, Prefix: "other"}); err == nil {
+		t.Error("Expected error for conflicting prefix")
+	}
+
+	z, err := NewHandler(&Config{Connect: connect, CreateChroot: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+
+	if z.(*ZKHandler).Prefix != "test_chroot/kafka" {
+		t.Errorf("Expected prefix test_chroot/kafka, got %s", z.(*ZKHandler).Prefix)
+	}
+
+	if err := zki.RecursiveDelete("/test_chroot"); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestCreateSequential(t *testing.T) {
 	err := zki.Create(zkprefix+"/test", "")
 	if err != nil {
@@ -411,6 +505,16 @@ func TestGetReassignments(t *testing.T) {
 	}
 }
 
+func TestSubmitReassignmentInProgress(t *testing.T) {
+	// The topic0 reassignment from setup is in progress.
+	pm := mapper.NewPartitionMap()
+	pm.Partitions = mapper.PartitionList{{Topic: "topic1", Partition: 0, Replicas: []int{1001, 1002}}}
+
+	if err := zki.SubmitReassignment(pm); err != ErrReassignmentInProgress {
+		t.Errorf("Expected error '%v', got '%v'", ErrReassignmentInProgress, err)
+	}
+}
+
 func TestGetPendingDeletion(t *testing.T) {
 	pd, err := zki.GetPendingDeletion()
 	if err != nil {
@@ -451,6 +555,29 @@ func TestGetTopics(t *testing.T) {
 	}
 }
 
+func TestListTopics(t *testing.T) {
+	rs := []*regexp.Regexp{
+		regexp.MustCompile("topic[0-2]"),
+	}
+
+	var ts []string
+	for c := range zki.ListTopics(context.Background(), rs, 2) {
+		if c.Err != nil {
+			t.Fatal(c.Err)
+		}
+		for topic := range c.States {
+			ts = append(ts, topic)
+		}
+	}
+
+	sort.Strings(ts)
+
+	expected := []string{"topic0", "topic1", "topic2"}
+	if !reflect.DeepEqual(ts, expected) {
+		t.Errorf("Expected topics %v, got %v", expected, ts)
+	}
+}
+
 func TestGetTopicConfig(t *testing.T) {
 	c, err := zki.GetTopicConfig("topic0")
 	if err != nil {
@@ -493,6 +620,15 @@ func TestGetAllBrokerMeta(t *testing.T) {
 		if r.Rack != expected[b] {
 			t.Errorf("Expected rack '%s' for %d, got '%s'", expected[b], b, r.Rack)
 		}
+
+		if r.JMXPort != 9999 {
+			t.Errorf("Expected JMX port 9999 for %d, got %d", b, r.JMXPort)
+		}
+
+		e, found := r.Endpoints.ForSecurityProtocol("PLAINTEXT")
+		if !found || e.Port != 9092 || e.Host != r.Host {
+			t.Errorf("Unexpected endpoints for %d: %v", b, r.Endpoints)
+		}
 	}
 }
 
@@ -858,6 +994,16 @@ func TestUpdateKafkaConfigBroker(t *testing.T) {
 	if string(d) != expected {
 		t.Errorf("Expected config '%s', got '%s'", expected, string(d))
 	}
+
+	// Validate the config lookup.
+	bc, err := zki.GetBrokerConfig(1001)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if v := bc.Config["leader.replication.throttled.rate"]; v != "100000" {
+		t.Errorf("Expected config value '100000', got '%s'", v)
+	}
 }
 
 func TestUpdateKafkaConfigTopic(t *testing.T) {
@@ -33,6 +33,19 @@ type TopicConfig struct {
 	Config  map[string]string `json:"config"`
 }
 
+// BrokerConfig is used for unmarshalling /config/brokers/<id> from ZooKeeper.
+type BrokerConfig struct {
+	Version int               `json:"version"`
+	Config  map[string]string `json:"config"`
+}
+
+// brokerRegistration is used for unmarshalling the listener endpoints from
+// /brokers/ids/<id> data.
+type brokerRegistration struct {
+	Endpoints                   []string          `json:"endpoints"`
+	ListenerSecurityProtocolMap map[string]string `json:"listener_security_protocol_map"`
+}
+
 // TopicMetadata holds the topic data found in the /brokers/topics/<topic> znode.
 // This is designed for the version 3 fields present in Kafka version ~2.4+.
 type TopicMetadata struct {
@@ -0,0 +1,160 @@
+package kafkazk
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	zkclient "github.com/go-zookeeper/zk"
+)
+
+// ReconnectConfig configures the backoff between ZooKeeper connection
+// attempts. Consecutive failed attempts back off exponentially from
+// InitialBackoff up to MaxBackoff, each reduced by a random fraction of up to
+// Jitter (0-1) so that many clients don't retry in lockstep. If MaxRetries is
+// set, exceeding that many consecutive failed attempts is reported as a fatal
+// error on the Handler Fatal channel; attempts continue at MaxBackoff.
+type ReconnectConfig struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Jitter         float64
+	MaxRetries     int
+}
+
+// DefaultReconnectConfig returns the ReconnectConfig used if none is
+// specified.
+func DefaultReconnectConfig() ReconnectConfig {
+	return ReconnectConfig{
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+// reconnector dials ZooKeeper servers, backing off between failed attempts.
+type reconnector struct {
+	cfg   ReconnectConfig
+	dial  zkclient.Dialer
+	sleep func(time.Duration, <-chan struct{})
+	rand  func() float64
+	// Closed to interrupt any backoff in progress.
+	quit  chan struct{}
+	fatal chan error
+
+	mu       sync.Mutex
+	failures int
+	reported bool
+}
+
+func newReconnector(cfg ReconnectConfig) *reconnector {
+	if cfg == (ReconnectConfig{}) {
+		cfg = DefaultReconnectConfig()
+	}
+
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = DefaultReconnectConfig().InitialBackoff
+	}
+
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = cfg.InitialBackoff
+	}
+
+	return &reconnector{
+		cfg:   cfg,
+		dial:  net.DialTimeout,
+		sleep: sleep,
+		rand:  rand.Float64,
+		quit:  make(chan struct{}),
+		fatal: make(chan error, 1),
+	}
+}
+
+// dialer is a zkclient.Dialer that backs off according to the number of
+// consecutive failed attempts.
+func (r *reconnector) dialer(network, address string, timeout time.Duration) (net.Conn, error) {
+	r.mu.Lock()
+	failures := r.failures
+	r.mu.Unlock()
+
+	if failures > 0 {
+		r.sleep(r.backoff(failures), r.quit)
+	}
+
+	select {
+	case <-r.quit:
+		return nil, zkclient.ErrClosing
+	default:
+	}
+
+	conn, err := r.dial(network, address, timeout)
+	if err != nil {
+		r.failed(err)
+	}
+
+	return conn, err
+}
+
+// backoff returns the delay before the next attempt after n consecutive
+// failures.
+func (r *reconnector) backoff(n int) time.Duration {
+	d := r.cfg.InitialBackoff
+	for i := 1; i < n && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+
+	return d - time.Duration(r.cfg.Jitter*r.rand()*float64(d))
+}
+
+// failed records a failed attempt, reporting a fatal error the first time the
+// retry budget is exceeded.
+func (r *reconnector) failed(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures++
+
+	if r.cfg.MaxRetries > 0 && r.failures > r.cfg.MaxRetries && !r.reported {
+		r.reported = true
+		r.fatal <- fmt.Errorf("failed to connect to ZooKeeper after %d retries: %s", r.cfg.MaxRetries, err)
+	}
+}
+
+// event is a zkclient.EventCallback that resets the failure count once a
+// session is established. A dial succeeding isn't sufficient since a
+// struggling server may accept connections without establishing sessions.
+func (r *reconnector) event(e zkclient.Event) {
+	if e.Type != zkclient.EventSession || e.State != zkclient.StateHasSession {
+		return
+	}
+
+	r.mu.Lock()
+	r.failures = 0
+	r.reported = false
+	r.mu.Unlock()
+}
+
+// close interrupts any backoff in progress.
+func (r *reconnector) close() {
+	select {
+	case <-r.quit:
+	default:
+		close(r.quit)
+	}
+}
+
+// sleep sleeps for d or until quit is closed.
+func sleep(d time.Duration, quit <-chan struct{}) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-quit:
+	}
+}
@@ -0,0 +1,95 @@
+package kafkazk
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	zkclient "github.com/go-zookeeper/zk"
+)
+
+func newTestReconnector(cfg ReconnectConfig) (*reconnector, *[]time.Duration) {
+	r := newReconnector(cfg)
+
+	var slept []time.Duration
+	r.sleep = func(d time.Duration, _ <-chan struct{}) { slept = append(slept, d) }
+	r.rand = func() float64 { return 1 }
+	r.dial = func(string, string, time.Duration) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	return r, &slept
+}
+
+func TestReconnectorBackoff(t *testing.T) {
+	r, slept := newTestReconnector(ReconnectConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         0.5,
+	})
+
+	for i := 0; i < 5; i++ {
+		r.dialer("tcp", "zk:2181", time.Second)
+	}
+
+	// No backoff before the first attempt, with the maximum jitter applied
+	// to each subsequent backoff.
+	expected := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 2500 * time.Millisecond}
+	if len(*slept) != len(expected) {
+		t.Fatalf("Expected backoffs %v, got %v", expected, *slept)
+	}
+
+	for i := range expected {
+		if (*slept)[i] != expected[i] {
+			t.Errorf("Expected backoffs %v, got %v", expected, *slept)
+			break
+		}
+	}
+
+	// Establishing a session resets the backoff.
+	r.event(zkclient.Event{Type: zkclient.EventSession, State: zkclient.StateHasSession})
+	*slept = nil
+	r.dialer("tcp", "zk:2181", time.Second)
+
+	if len(*slept) != 0 {
+		t.Errorf("Expected no backoff, got %v", *slept)
+	}
+}
+
+func TestReconnectorMaxRetries(t *testing.T) {
+	r, _ := newTestReconnector(ReconnectConfig{InitialBackoff: time.Second, MaxRetries: 2})
+
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-r.fatal:
+			t.Fatalf("Unexpected fatal error after %d attempts: %s", i, err)
+		default:
+		}
+		r.dialer("tcp", "zk:2181", time.Second)
+	}
+
+	select {
+	case <-r.fatal:
+	default:
+		t.Fatal("Expected fatal error")
+	}
+
+	// Reported once.
+	r.dialer("tcp", "zk:2181", time.Second)
+	select {
+	case <-r.fatal:
+		t.Error("Unexpected second fatal error")
+	default:
+	}
+}
+
+func TestReconnectorClose(t *testing.T) {
+	r, _ := newTestReconnector(ReconnectConfig{})
+	r.close()
+	r.close()
+
+	if _, err := r.dialer("tcp", "zk:2181", time.Second); err != zkclient.ErrClosing {
+		t.Errorf("Expected ErrClosing, got %v", err)
+	}
+}
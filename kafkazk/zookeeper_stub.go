@@ -4,6 +4,7 @@
 package kafkazk
 
 import (
+	"context"
 	"errors"
 	"regexp"
 	"strings"
@@ -16,16 +17,22 @@ var (
 	errNotExist = errors.New("znode doesn't exist")
 )
 
-// Stub stubs the Handler interface.
+// Stub stubs the Handler interface. Responses are deterministic; some can be
+// scripted with the Set methods.
 type Stub struct {
 	bmm  mapper.BrokerMetaMap
 	data map[string]*StubZnode
+	// Scripted reassignments. The default reassignments are used if nil.
+	reassignments Reassignments
+	// KafkaConfigs passed to UpdateKafkaConfig, in call order.
+	configUpdates []KafkaConfig
 }
 
 // StubZnode stubs a ZooKeeper znode.
 type StubZnode struct {
 	value    []byte
 	version  int32
+	acl      []ACL
 	children map[string]*StubZnode
 }
 
@@ -62,19 +69,62 @@ func (zk *Stub) AddBrokers(b map[int]mapper.BrokerMeta) {
 
 // Many of these methods aren't complete stubs as they haven't been needed.
 
+// SetReassignments sets the Reassignments returned by ListReassignments and
+// GetReassignments. A nil Reassignments restores the defaults.
+func (zk *Stub) SetReassignments(r Reassignments) {
+	zk.reassignments = r
+}
+
+// SubmitReassignment stubs SubmitReassignment. The submitted partitions are
+// returned by subsequent ListReassignments and GetReassignments calls.
+func (zk *Stub) SubmitReassignment(pm *mapper.PartitionMap) error {
+	if len(zk.GetReassignments()) > 0 {
+		return ErrReassignmentInProgress
+	}
+
+	r := Reassignments{}
+	for _, p := range pm.Partitions {
+		if r[p.Topic] == nil {
+			r[p.Topic] = map[int][]int{}
+		}
+		r[p.Topic][p.Partition] = p.Replicas
+	}
+
+	zk.reassignments = r
+
+	return nil
+}
+
+// CancelReassignments stubs CancelReassignments. The cancelled reassignments
+// are removed from those returned by subsequent ListReassignments and
+// GetReassignments calls.
+func (zk *Stub) CancelReassignments(topics []string) (Reassignments, error) {
+	cancelled, remaining := Reassignments{}, Reassignments{}
+
+	for t, partitions := range zk.GetReassignments() {
+		if len(topics) == 0 || inStrings(t, topics) {
+			cancelled[t] = partitions
+		} else {
+			remaining[t] = partitions
+		}
+	}
+
+	zk.reassignments = remaining
+
+	return cancelled, nil
+}
+
 // ListReassignments stubs ListReassignments.
 func (zk *Stub) ListReassignments() (Reassignments, error) {
-	r := Reassignments{
-		"reassigning_topic": map[int][]int{
-			0: {1003, 1000, 1002},
-			1: {1005, 1010},
-		},
-	}
-	return r, nil
+	return zk.GetReassignments(), nil
 }
 
 // GetReassignments stubs GetReassignments.
 func (zk *Stub) GetReassignments() Reassignments {
+	if zk.reassignments != nil {
+		return zk.reassignments
+	}
+
 	r := Reassignments{
 		"reassigning_topic": map[int][]int{
 			0: {1003, 1000, 1002},
@@ -97,6 +147,15 @@ func (zk *Stub) Create(p, d string) error {
 	return zk.Set(p, d)
 }
 
+// CreateWithACL stubs CreateWithACL.
+func (zk *Stub) CreateWithACL(p, d string, acl []ACL) error {
+	if err := zk.Set(p, d); err != nil {
+		return err
+	}
+
+	return zk.SetACL(p, acl)
+}
+
 // CreateSequential stubs CreateSequential.
 func (zk *Stub) CreateSequential(a, b string) error {
 	_, _ = a, b
@@ -162,62 +221,150 @@ func (zk *Stub) Get(p string) ([]byte, error) {
 	return current.value, nil
 }
 
-// Delete stubs Delete.
-func (zk *Stub) Delete(p string) error {
+// GetWithVersion stubs GetWithVersion.
+func (zk *Stub) GetWithVersion(p string) ([]byte, int32, error) {
+	n, err := zk.znode(p)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return n.value, n.version, nil
+}
+
+// SetWithVersion stubs SetWithVersion.
+func (zk *Stub) SetWithVersion(p, d string, v int32) error {
+	n, err := zk.znode(p)
+	if err != nil {
+		return err
+	}
+
+	if n.version != v {
+		return NewErrBadVersion(p)
+	}
+
+	n.value = []byte(d)
+	n.version++
+
+	return nil
+}
+
+// DeleteWithVersion stubs DeleteWithVersion.
+func (zk *Stub) DeleteWithVersion(p string, v int32) error {
+	n, err := zk.znode(p)
+	if err != nil {
+		return err
+	}
+
+	if n.version != v {
+		return NewErrBadVersion(p)
+	}
+
+	return zk.Delete(p)
+}
+
+// GetACL stubs GetACL. Znodes created without an ACL have an open ACL. ACLs
+// aren't enforced by the stub.
+func (zk *Stub) GetACL(p string) ([]ACL, error) {
+	n, err := zk.znode(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.acl == nil {
+		return WorldACL(PermAll), nil
+	}
+
+	return n.acl, nil
+}
+
+// SetACL stubs SetACL.
+func (zk *Stub) SetACL(p string, acl []ACL) error {
+	n, err := zk.znode(p)
+	if err != nil {
+		return err
+	}
+
+	n.acl = append([]ACL{}, acl...)
+
+	return nil
+}
+
+// znode returns the *StubZnode at path p.
+func (zk *Stub) znode(p string) (*StubZnode, error) {
 	pathTrimmed := strings.Trim(p, "/")
 	paths := strings.Split(pathTrimmed, "/")
 	var current *StubZnode
 
 	if current = zk.data[paths[0]]; current == nil {
-		return errNotExist
+		return nil, errNotExist
 	}
 
-	for i, path := range paths[1:] {
+	for _, path := range paths[1:] {
 		next := current.children[path]
 		if next == nil {
-			return errNotExist
-		}
-
-		if i == len(paths)-2 {
-			delete(current.children, path)
-			return nil
+			return nil, errNotExist
 		}
-
 		current = next
 	}
 
-	return nil
+	return current, nil
 }
 
-// Children stubs children.
-func (zk *Stub) Children(p string) ([]string, error) {
+// RecursiveDelete stubs RecursiveDelete.
+func (zk *Stub) RecursiveDelete(p string) error {
+	return recursiveDelete(zk, p)
+}
+
+// ExportTree stubs ExportTree.
+func (zk *Stub) ExportTree(p string) (*Znode, error) {
+	return exportTree(zk, p)
+}
+
+// Delete stubs Delete.
+func (zk *Stub) Delete(p string) error {
 	pathTrimmed := strings.Trim(p, "/")
 	paths := strings.Split(pathTrimmed, "/")
-	children := []string{}
 	var current *StubZnode
 
 	if current = zk.data[paths[0]]; current == nil {
-		return nil, errNotExist
+		return errNotExist
+	}
+
+	if len(paths) == 1 {
+		delete(zk.data, paths[0])
+		return nil
 	}
 
 	for i, path := range paths[1:] {
 		next := current.children[path]
 		if next == nil {
-			return nil, errNotExist
+			return errNotExist
 		}
 
 		if i == len(paths)-2 {
-			for k := range next.children {
-				children = append(children, k)
-			}
-
-			return children, nil
+			delete(current.children, path)
+			return nil
 		}
 
 		current = next
 	}
 
-	return nil, errNotExist
+	return nil
+}
+
+// Children stubs children.
+func (zk *Stub) Children(p string) ([]string, error) {
+	n, err := zk.znode(p)
+	if err != nil {
+		return nil, err
+	}
+
+	children := []string{}
+	for k := range n.children {
+		children = append(children, k)
+	}
+
+	return children, nil
 }
 
 func (zk *Stub) NextInt(p string) (int32, error) {
@@ -283,15 +430,38 @@ func (zk *Stub) Ready() bool {
 	return true
 }
 
+// Fatal stubs Fatal.
+func (zk *Stub) Fatal() <-chan error {
+	return nil
+}
+
 // InitRawClient stubs InitRawClient.
 func (zk *Stub) InitRawClient() error {
 	return nil
 }
 
-// UpdateKafkaConfig stubs UpdateKafkaConfig.
+// UpdateKafkaConfig stubs UpdateKafkaConfig. The KafkaConfig is recorded and
+// every config is reported as changed.
 func (zk *Stub) UpdateKafkaConfig(c KafkaConfig) ([]bool, error) {
-	_ = c
-	return []bool{}, nil
+	zk.configUpdates = append(zk.configUpdates, c)
+
+	changed := make([]bool, len(c.Configs))
+	for i := range changed {
+		changed[i] = true
+	}
+
+	return changed, nil
+}
+
+// KafkaConfigUpdates returns all KafkaConfigs passed to UpdateKafkaConfig, in
+// call order.
+func (zk *Stub) KafkaConfigUpdates() []KafkaConfig {
+	return zk.configUpdates
+}
+
+// ResetKafkaConfigUpdates clears the recorded KafkaConfig updates.
+func (zk *Stub) ResetKafkaConfigUpdates() {
+	zk.configUpdates = nil
 }
 
 // GetTopics stubs GetTopics.
@@ -318,6 +488,11 @@ func (zk *Stub) GetTopics(ts []*regexp.Regexp) ([]string, error) {
 	return matched, nil
 }
 
+// ListTopics stubs ListTopics.
+func (zk *Stub) ListTopics(ctx context.Context, ts []*regexp.Regexp, size int) <-chan TopicChunk {
+	return listTopics(ctx, zk, ts, size)
+}
+
 // GetTopicMetadata stubs GetTopicMetadata.
 func (zk *Stub) GetTopicMetadata(t string) (TopicMetadata, error) {
 	return TopicMetadata{
@@ -344,6 +519,17 @@ func (zk *Stub) GetTopicConfig(t string) (*TopicConfig, error) {
 	}, nil
 }
 
+// GetBrokerConfig stubs GetBrokerConfig.
+func (zk *Stub) GetBrokerConfig(id int) (*BrokerConfig, error) {
+	return &BrokerConfig{
+		Version: 1,
+		Config: map[string]string{
+			"leader.replication.throttled.rate":   "100000000",
+			"follower.replication.throttled.rate": "100000000",
+		},
+	}, nil
+}
+
 // GetAllBrokerMeta stubs GetAllBrokerMeta.
 func (zk *Stub) GetAllBrokerMeta(withMetrics bool) (mapper.BrokerMetaMap, []error) {
 	b := zk.bmm.Copy()
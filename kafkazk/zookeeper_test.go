@@ -2,6 +2,7 @@ package kafkazk
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"testing"
 
@@ -48,3 +49,37 @@ func testGetMapString(n string) string {
     {"topic":"%s","partition":2,"replicas":[1003,1004,1001]},
     {"topic":"%s","partition":3,"replicas":[1004,1003,1002]}]}`, n, n, n, n)
 }
+
+func TestParseConnect(t *testing.T) {
+	tests := []struct {
+		connect string
+		servers []string
+		chroot  string
+	}{
+		{"localhost:2181", []string{"localhost:2181"}, ""},
+		{"zk1:2181,zk2:2181/kafka", []string{"zk1:2181", "zk2:2181"}, "kafka"},
+		{"zk1:2181, zk2:2181/kafka/prod/", []string{"zk1:2181", "zk2:2181"}, "kafka/prod"},
+		{"zk1:2181/", []string{"zk1:2181"}, ""},
+	}
+
+	for _, test := range tests {
+		servers, chroot, err := ParseConnect(test.connect)
+		if err != nil {
+			t.Fatalf("[%s] %s", test.connect, err)
+		}
+
+		if !reflect.DeepEqual(servers, test.servers) {
+			t.Errorf("[%s] expected servers %v, got %v", test.connect, test.servers, servers)
+		}
+
+		if chroot != test.chroot {
+			t.Errorf("[%s] expected chroot '%s', got '%s'", test.connect, test.chroot, chroot)
+		}
+	}
+
+	for _, connect := range []string{"", "/kafka", "zk1:2181/kafka//prod"} {
+		if _, _, err := ParseConnect(connect); err == nil {
+			t.Errorf("[%s] expected error", connect)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package kafkazk
+
+import (
+	"errors"
+	"time"
+
+	zkclient "github.com/go-zookeeper/zk"
+)
+
+// ErrRequestTimeout is returned when a ZooKeeper request isn't answered
+// within the configured read or write timeout.
+var ErrRequestTimeout = errors.New("ZooKeeper request timed out")
+
+// TimeoutConfig configures ZooKeeper timeouts. Requests to an ensemble member
+// that's partitioned away otherwise wait until the session is lost, and
+// requests made while disconnected wait until a connection is re-established.
+type TimeoutConfig struct {
+	// The session timeout requested from the ensemble. The connection to a
+	// server is considered lost if it's unresponsive for two thirds of the
+	// session timeout. Defaults to 10s.
+	Session time.Duration
+	// The timeout for establishing a connection to a server. Defaults to 1s.
+	Connect time.Duration
+	// The timeouts for read (e.g. get, exists, children) and write (e.g.
+	// create, set, delete) requests. Requests aren't bounded if unset. A
+	// request that times out may still be applied by the ensemble.
+	Read  time.Duration
+	Write time.Duration
+}
+
+// DefaultTimeoutConfig returns the TimeoutConfig values used for those
+// that aren't specified.
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		Session: 10 * time.Second,
+		Connect: time.Second,
+	}
+}
+
+// zkConn is the subset of the *zkclient.Conn API used by the ZKHandler.
+type zkConn interface {
+	AddAuth(string, []byte) error
+	State() zkclient.State
+	Close()
+	Get(string) ([]byte, *zkclient.Stat, error)
+	Set(string, []byte, int32) (*zkclient.Stat, error)
+	Delete(string, int32) error
+	Create(string, []byte, int32, []zkclient.ACL) (string, error)
+	GetACL(string) ([]zkclient.ACL, *zkclient.Stat, error)
+	SetACL(string, []zkclient.ACL, int32) (*zkclient.Stat, error)
+	Exists(string) (bool, *zkclient.Stat, error)
+	Children(string) ([]string, *zkclient.Stat, error)
+}
+
+// timeoutConn is a zkConn that bounds the time waited for each request.
+// Timed out requests are abandoned rather than cancelled; their results are
+// discarded once they complete.
+type timeoutConn struct {
+	zkConn
+	read  time.Duration
+	write time.Duration
+}
+
+// withTimeout calls f, returning ErrRequestTimeout if it doesn't return
+// within the timeout. f is called directly if the timeout is 0. On a timeout,
+// any results set by f must not be read.
+func withTimeout(timeout time.Duration, f func() error) error {
+	if timeout <= 0 {
+		return f()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- f() }()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-t.C:
+		return ErrRequestTimeout
+	}
+}
+
+func (c *timeoutConn) Get(p string) ([]byte, *zkclient.Stat, error) {
+	var data []byte
+	var stat *zkclient.Stat
+
+	err := withTimeout(c.read, func() (err error) {
+		data, stat, err = c.zkConn.Get(p)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, stat, nil
+}
+
+func (c *timeoutConn) Set(p string, d []byte, version int32) (*zkclient.Stat, error) {
+	var stat *zkclient.Stat
+
+	err := withTimeout(c.write, func() (err error) {
+		stat, err = c.zkConn.Set(p, d, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stat, nil
+}
+
+func (c *timeoutConn) Delete(p string, version int32) error {
+	return withTimeout(c.write, func() error {
+		return c.zkConn.Delete(p, version)
+	})
+}
+
+func (c *timeoutConn) Create(p string, d []byte, flags int32, acl []zkclient.ACL) (string, error) {
+	var path string
+
+	err := withTimeout(c.write, func() (err error) {
+		path, err = c.zkConn.Create(p, d, flags, acl)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (c *timeoutConn) GetACL(p string) ([]zkclient.ACL, *zkclient.Stat, error) {
+	var acl []zkclient.ACL
+	var stat *zkclient.Stat
+
+	err := withTimeout(c.read, func() (err error) {
+		acl, stat, err = c.zkConn.GetACL(p)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return acl, stat, nil
+}
+
+func (c *timeoutConn) SetACL(p string, acl []zkclient.ACL, version int32) (*zkclient.Stat, error) {
+	var stat *zkclient.Stat
+
+	err := withTimeout(c.write, func() (err error) {
+		stat, err = c.zkConn.SetACL(p, acl, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stat, nil
+}
+
+func (c *timeoutConn) Exists(p string) (bool, *zkclient.Stat, error) {
+	var exists bool
+	var stat *zkclient.Stat
+
+	err := withTimeout(c.read, func() (err error) {
+		exists, stat, err = c.zkConn.Exists(p)
+		return err
+	})
+	if err != nil {
+		return false, nil, err
+	}
+
+	return exists, stat, nil
+}
+
+func (c *timeoutConn) Children(p string) ([]string, *zkclient.Stat, error) {
+	var children []string
+	var stat *zkclient.Stat
+
+	err := withTimeout(c.read, func() (err error) {
+		children, stat, err = c.zkConn.Children(p)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return children, stat, nil
+}
@@ -0,0 +1,57 @@
+package kafkazk
+
+import (
+	"testing"
+	"time"
+
+	zkclient "github.com/go-zookeeper/zk"
+)
+
+// blockingConn is a zkConn whose requests block until released.
+type blockingConn struct {
+	zkConn
+	release chan struct{}
+}
+
+func (c *blockingConn) Get(p string) ([]byte, *zkclient.Stat, error) {
+	<-c.release
+	return []byte("data"), &zkclient.Stat{}, nil
+}
+
+func (c *blockingConn) Set(p string, d []byte, version int32) (*zkclient.Stat, error) {
+	<-c.release
+	return &zkclient.Stat{}, nil
+}
+
+func TestTimeoutConn(t *testing.T) {
+	bc := &blockingConn{release: make(chan struct{})}
+	defer close(bc.release)
+
+	c := &timeoutConn{zkConn: bc, read: 10 * time.Millisecond, write: 10 * time.Millisecond}
+
+	if _, _, err := c.Get("/test"); err != ErrRequestTimeout {
+		t.Errorf("Expected ErrRequestTimeout for a read, got %v", err)
+	}
+
+	if _, err := c.Set("/test", nil, -1); err != ErrRequestTimeout {
+		t.Errorf("Expected ErrRequestTimeout for a write, got %v", err)
+	}
+
+	// Requests that complete within the timeout return their results.
+	released := &blockingConn{release: make(chan struct{})}
+	close(released.release)
+
+	c = &timeoutConn{zkConn: released, read: time.Second}
+
+	data, _, err := c.Get("/test")
+	if err != nil || string(data) != "data" {
+		t.Errorf("Expected data, got %q, %v", data, err)
+	}
+
+	// Requests aren't bounded without a timeout.
+	c = &timeoutConn{zkConn: released}
+
+	if _, err := c.Set("/test", nil, -1); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
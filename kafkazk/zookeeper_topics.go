@@ -0,0 +1,88 @@
+package kafkazk
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+// DefaultTopicChunkSize is the number of topics per TopicChunk if a
+// non-positive chunk size is passed to ListTopics.
+const DefaultTopicChunkSize = 500
+
+// TopicChunk is a chunk of topics and their states returned by ListTopics. If
+// Err is non-nil, it's the last chunk sent and States is nil.
+type TopicChunk struct {
+	States map[string]*mapper.TopicState
+	Err    error
+}
+
+// topicStateGetter is the subset of Handler methods used by listTopics.
+type topicStateGetter interface {
+	GetTopics([]*regexp.Regexp) ([]string, error)
+	GetTopicState(string) (*mapper.TopicState, error)
+}
+
+// listTopics streams the states of the topics matching ts, in name order, in
+// chunks of up to size topics. The channel is unbuffered so that only one
+// chunk is held in memory until it's received. The channel is closed once all
+// topics are sent, an error is sent, or the context is cancelled.
+func listTopics(ctx context.Context, zk topicStateGetter, ts []*regexp.Regexp, size int) <-chan TopicChunk {
+	if size <= 0 {
+		size = DefaultTopicChunkSize
+	}
+
+	chunks := make(chan TopicChunk)
+
+	go func() {
+		defer close(chunks)
+
+		send := func(c TopicChunk) bool {
+			select {
+			case chunks <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		topics, err := zk.GetTopics(ts)
+		if err != nil {
+			send(TopicChunk{Err: err})
+			return
+		}
+
+		sort.Strings(topics)
+
+		for len(topics) > 0 {
+			n := size
+			if n > len(topics) {
+				n = len(topics)
+			}
+
+			states := make(map[string]*mapper.TopicState, n)
+			for _, t := range topics[:n] {
+				if ctx.Err() != nil {
+					return
+				}
+
+				state, err := zk.GetTopicState(t)
+				if err != nil {
+					send(TopicChunk{Err: err})
+					return
+				}
+				states[t] = state
+			}
+
+			if !send(TopicChunk{States: states}) {
+				return
+			}
+
+			topics = topics[n:]
+		}
+	}()
+
+	return chunks
+}
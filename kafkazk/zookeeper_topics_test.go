@@ -0,0 +1,105 @@
+package kafkazk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/mapper"
+)
+
+// topicsStub returns n topics, failing GetTopicState for the topic named fail.
+type topicsStub struct {
+	n    int
+	fail string
+}
+
+func (s topicsStub) GetTopics([]*regexp.Regexp) ([]string, error) {
+	var topics []string
+	for i := s.n - 1; i >= 0; i-- {
+		topics = append(topics, fmt.Sprintf("topic%03d", i))
+	}
+	return topics, nil
+}
+
+func (s topicsStub) GetTopicState(t string) (*mapper.TopicState, error) {
+	if t == s.fail {
+		return nil, errors.New("state unavailable")
+	}
+	return &mapper.TopicState{}, nil
+}
+
+func TestListTopicsChunks(t *testing.T) {
+	var sizes []int
+	var last string
+
+	for c := range listTopics(context.Background(), topicsStub{n: 25}, nil, 10) {
+		if c.Err != nil {
+			t.Fatal(c.Err)
+		}
+		sizes = append(sizes, len(c.States))
+
+		// Topics are chunked in name order.
+		for topic := range c.States {
+			if topic <= last {
+				t.Errorf("Topic %s out of order", topic)
+			}
+		}
+		for topic := range c.States {
+			if topic > last {
+				last = topic
+			}
+		}
+	}
+
+	if fmt.Sprint(sizes) != "[10 10 5]" {
+		t.Errorf("Expected chunk sizes [10 10 5], got %v", sizes)
+	}
+}
+
+func TestListTopicsError(t *testing.T) {
+	var chunks []TopicChunk
+	for c := range listTopics(context.Background(), topicsStub{n: 25, fail: "topic012"}, nil, 10) {
+		chunks = append(chunks, c)
+	}
+
+	if len(chunks) != 2 || chunks[0].Err != nil || chunks[1].Err == nil {
+		t.Errorf("Expected a chunk followed by an error, got %v", chunks)
+	}
+}
+
+func TestListTopicsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := listTopics(ctx, topicsStub{n: 25}, nil, 10)
+
+	<-chunks
+	cancel()
+
+	// The channel is closed; at most one in-flight chunk is received.
+	var n int
+	for range chunks {
+		n++
+	}
+
+	if n > 1 {
+		t.Errorf("Expected at most 1 chunk after cancellation, got %d", n)
+	}
+}
+
+func TestStubListTopics(t *testing.T) {
+	zk := NewZooKeeperStub()
+
+	var topics int
+	for c := range zk.ListTopics(context.Background(), []*regexp.Regexp{regexp.MustCompile("test")}, 0) {
+		if c.Err != nil {
+			t.Fatal(c.Err)
+		}
+		topics += len(c.States)
+	}
+
+	if topics != 2 {
+		t.Errorf("Expected 2 topics, got %d", topics)
+	}
+}
@@ -0,0 +1,82 @@
+package kafkazk
+
+import (
+	"encoding/base64"
+	"path"
+	"sort"
+	"unicode/utf8"
+)
+
+// Znode is a znode and its descendants, as returned by ExportTree. Data that
+// isn't valid UTF-8 (e.g. compressed broker metrics) is base64 encoded, with
+// Encoding set to "base64".
+type Znode struct {
+	Path     string   `json:"path"`
+	Data     string   `json:"data"`
+	Encoding string   `json:"encoding,omitempty"`
+	Children []*Znode `json:"children,omitempty"`
+}
+
+// DecodedData returns the znode data, decoding it if encoded.
+func (n *Znode) DecodedData() ([]byte, error) {
+	if n.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(n.Data)
+	}
+
+	return []byte(n.Data), nil
+}
+
+// recursiveDelete deletes the znode at path p and all of its descendants,
+// deepest first. Znodes created beneath p while the delete is in progress
+// may cause it to fail.
+func recursiveDelete(zk SimpleZooKeeperClient, p string) error {
+	children, err := zk.Children(p)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range children {
+		if err := recursiveDelete(zk, path.Join(p, c)); err != nil {
+			return err
+		}
+	}
+
+	// The root znode can't be deleted.
+	if p == "/" {
+		return nil
+	}
+
+	return zk.Delete(p)
+}
+
+// exportTree returns the znode at path p and all of its descendants. Children
+// are sorted by name.
+func exportTree(zk SimpleZooKeeperClient, p string) (*Znode, error) {
+	data, err := zk.Get(p)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Znode{Path: p, Data: string(data)}
+	if !utf8.Valid(data) {
+		n.Data = base64.StdEncoding.EncodeToString(data)
+		n.Encoding = "base64"
+	}
+
+	children, err := zk.Children(p)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(children)
+
+	for _, c := range children {
+		child, err := exportTree(zk, path.Join(p, c))
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+	}
+
+	return n, nil
+}
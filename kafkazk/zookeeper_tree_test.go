@@ -0,0 +1,61 @@
+package kafkazk
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRecursiveDelete(t *testing.T) {
+	zk := NewZooKeeperStub()
+	zk.Create("/keep", "")
+	zk.Create("/tree/a/b", "")
+	zk.Create("/tree/c", "")
+
+	if err := zk.RecursiveDelete("/tree"); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, _ := zk.Exists("/tree"); exists {
+		t.Error("Expected /tree to be deleted")
+	}
+
+	if exists, _ := zk.Exists("/keep"); !exists {
+		t.Error("Expected /keep to exist")
+	}
+
+	if err := zk.RecursiveDelete("/tree"); err == nil {
+		t.Error("Expected error deleting non-existent znode")
+	}
+}
+
+func TestExportTree(t *testing.T) {
+	zk := NewZooKeeperStub()
+	zk.Create("/tree", "root")
+	zk.Create("/tree/b", "text")
+	zk.Create("/tree/a", string([]byte{0x1f, 0x8b, 0xff}))
+	zk.Create("/tree/b/c", "")
+
+	n, err := zk.ExportTree("/tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"path":"/tree","data":"root","children":[{"path":"/tree/a","data":"H4v/","encoding":"base64"},{"path":"/tree/b","data":"text","children":[{"path":"/tree/b/c","data":""}]}]}`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	d, err := n.Children[0].DecodedData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(d) != string([]byte{0x1f, 0x8b, 0xff}) {
+		t.Errorf("Unexpected decoded data %v", d)
+	}
+}
@@ -17,6 +17,10 @@ type BrokerMeta struct {
 	Rack                       string
 	LogMessageFormat           string
 	InterBrokerProtocolVersion string
+	// Listener endpoints along with the security protocol of each.
+	Endpoints kafkaadmin.BrokerEndpoints `json:"-"`
+	// The JMX port, if set in the broker registration.
+	JMXPort int `json:"jmx_port"`
 }
 
 // Copy returns a copy of a BrokerMetaMap.
@@ -41,6 +45,12 @@ func (bm BrokerMeta) Copy() BrokerMeta {
 		Rack:                       bm.Rack,
 		LogMessageFormat:           bm.LogMessageFormat,
 		InterBrokerProtocolVersion: bm.InterBrokerProtocolVersion,
+		JMXPort:                    bm.JMXPort,
+	}
+
+	if bm.Endpoints != nil {
+		cp.Endpoints = make(kafkaadmin.BrokerEndpoints, len(bm.Endpoints))
+		copy(cp.Endpoints, bm.Endpoints)
 	}
 
 	return cp
@@ -58,6 +68,7 @@ func BrokerMetaMapFromStates(states kafkaadmin.BrokerStates) (BrokerMetaMap, err
 			Rack:                       state.Rack,
 			LogMessageFormat:           state.LogMessageFormat,
 			InterBrokerProtocolVersion: state.InterBrokerProtocolVersion,
+			Endpoints:                  state.Endpoints,
 		}
 	}
 
@@ -2,6 +2,8 @@ package mapper
 
 import (
 	"testing"
+
+	"github.com/DataDog/kafka-kit/v4/kafkaadmin"
 )
 
 func TestBrokerMetaCopy(t *testing.T) {
@@ -13,6 +15,10 @@ func TestBrokerMetaCopy(t *testing.T) {
 		Rack:                       "a",
 		LogMessageFormat:           "0.10",
 		InterBrokerProtocolVersion: "0.10",
+		Endpoints: kafkaadmin.BrokerEndpoints{
+			{Listener: "PLAINTEXT", SecurityProtocol: "PLAINTEXT", Host: "localhost", Port: 9092},
+		},
+		JMXPort: 9999,
 	}
 
 	cp := orig.Copy()
@@ -27,7 +33,10 @@ func TestBrokerMetaCopy(t *testing.T) {
 		orig.Rack != cp.Rack,
 		orig.Port != cp.Port,
 		orig.LogMessageFormat != cp.LogMessageFormat,
-		orig.InterBrokerProtocolVersion != cp.InterBrokerProtocolVersion:
+		orig.InterBrokerProtocolVersion != cp.InterBrokerProtocolVersion,
+		orig.JMXPort != cp.JMXPort,
+		len(orig.Endpoints) != len(cp.Endpoints),
+		orig.Endpoints[0] != cp.Endpoints[0]:
 		equal = false
 	default:
 		equal = true
@@ -39,4 +48,10 @@ func TestBrokerMetaCopy(t *testing.T) {
 		t.Logf("Want:\n%+v\n", orig)
 		t.Fail()
 	}
+
+	// The endpoints must not share the underlying array.
+	cp.Endpoints[0].Port = 9093
+	if orig.Endpoints[0].Port != 9092 {
+		t.Error("Expected copied endpoints to be independent")
+	}
 }
@@ -0,0 +1,451 @@
+//
+//If this proto file is updated, the generated outputs can be updated with
+//the `make generate-code` command.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.19.1
+// source: autothrottle.proto
+
+package autothrottle
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Autothrottle_GetThrottle_FullMethodName           = "/autothrottle.Autothrottle/GetThrottle"
+	Autothrottle_SetThrottle_FullMethodName           = "/autothrottle.Autothrottle/SetThrottle"
+	Autothrottle_RemoveThrottle_FullMethodName        = "/autothrottle.Autothrottle/RemoveThrottle"
+	Autothrottle_ListBrokerThrottles_FullMethodName   = "/autothrottle.Autothrottle/ListBrokerThrottles"
+	Autothrottle_RemoveBrokerThrottles_FullMethodName = "/autothrottle.Autothrottle/RemoveBrokerThrottles"
+	Autothrottle_GetStatus_FullMethodName             = "/autothrottle.Autothrottle/GetStatus"
+	Autothrottle_GetCapacity_FullMethodName           = "/autothrottle.Autothrottle/GetCapacity"
+	Autothrottle_Pause_FullMethodName                 = "/autothrottle.Autothrottle/Pause"
+	Autothrottle_Resume_FullMethodName                = "/autothrottle.Autothrottle/Resume"
+)
+
+// AutothrottleClient is the client API for Autothrottle service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AutothrottleClient interface {
+	// GetThrottle returns a ThrottleResponse describing the throttle override
+	// configuration. If the ThrottleRequest.broker_id field is set, the
+	// broker-specific override is returned. Otherwise the global override is
+	// returned. A zero rate indicates that no override is set.
+	GetThrottle(ctx context.Context, in *ThrottleRequest, opts ...grpc.CallOption) (*ThrottleResponse, error)
+	// SetThrottle sets a throttle override with the rate, autoremove and
+	// ttl_seconds fields of the ThrottleRequest. If the broker_id field is set,
+	// the override applies to that broker only. Otherwise the override applies
+	// to all brokers participating in a reassignment.
+	SetThrottle(ctx context.Context, in *ThrottleRequest, opts ...grpc.CallOption) (*ThrottleResponse, error)
+	// RemoveThrottle removes the global throttle override, or the
+	// broker-specific override if the ThrottleRequest.broker_id field is set.
+	RemoveThrottle(ctx context.Context, in *ThrottleRequest, opts ...grpc.CallOption) (*ThrottleResponse, error)
+	// ListBrokerThrottles returns a BrokerThrottlesResponse with all configured
+	// broker-specific throttle overrides.
+	ListBrokerThrottles(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BrokerThrottlesResponse, error)
+	// RemoveBrokerThrottles removes all broker-specific throttle overrides. The
+	// removed overrides are returned in the BrokerThrottlesResponse.
+	RemoveBrokerThrottles(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BrokerThrottlesResponse, error)
+	// GetStatus returns a StatusResponse describing the autothrottle state as
+	// of the most recent check interval.
+	GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StatusResponse, error)
+	// GetCapacity returns a CapacityResponse describing the configured
+	// throttle rate limits and instance-type network capacities.
+	GetCapacity(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CapacityResponse, error)
+	// Pause pauses autothrottle. While paused, autothrottle continues observing
+	// reassignments but doesn't write any throttle or override changes.
+	Pause(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PauseResponse, error)
+	// Resume resumes a paused autothrottle.
+	Resume(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PauseResponse, error)
+}
+
+type autothrottleClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAutothrottleClient(cc grpc.ClientConnInterface) AutothrottleClient {
+	return &autothrottleClient{cc}
+}
+
+func (c *autothrottleClient) GetThrottle(ctx context.Context, in *ThrottleRequest, opts ...grpc.CallOption) (*ThrottleResponse, error) {
+	out := new(ThrottleResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_GetThrottle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) SetThrottle(ctx context.Context, in *ThrottleRequest, opts ...grpc.CallOption) (*ThrottleResponse, error) {
+	out := new(ThrottleResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_SetThrottle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) RemoveThrottle(ctx context.Context, in *ThrottleRequest, opts ...grpc.CallOption) (*ThrottleResponse, error) {
+	out := new(ThrottleResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_RemoveThrottle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) ListBrokerThrottles(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BrokerThrottlesResponse, error) {
+	out := new(BrokerThrottlesResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_ListBrokerThrottles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) RemoveBrokerThrottles(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BrokerThrottlesResponse, error) {
+	out := new(BrokerThrottlesResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_RemoveBrokerThrottles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) GetCapacity(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CapacityResponse, error) {
+	out := new(CapacityResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_GetCapacity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) Pause(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PauseResponse, error) {
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_Pause_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *autothrottleClient) Resume(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PauseResponse, error) {
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, Autothrottle_Resume_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AutothrottleServer is the server API for Autothrottle service.
+// All implementations must embed UnimplementedAutothrottleServer
+// for forward compatibility
+type AutothrottleServer interface {
+	// GetThrottle returns a ThrottleResponse describing the throttle override
+	// configuration. If the ThrottleRequest.broker_id field is set, the
+	// broker-specific override is returned. Otherwise the global override is
+	// returned. A zero rate indicates that no override is set.
+	GetThrottle(context.Context, *ThrottleRequest) (*ThrottleResponse, error)
+	// SetThrottle sets a throttle override with the rate, autoremove and
+	// ttl_seconds fields of the ThrottleRequest. If the broker_id field is set,
+	// the override applies to that broker only. Otherwise the override applies
+	// to all brokers participating in a reassignment.
+	SetThrottle(context.Context, *ThrottleRequest) (*ThrottleResponse, error)
+	// RemoveThrottle removes the global throttle override, or the
+	// broker-specific override if the ThrottleRequest.broker_id field is set.
+	RemoveThrottle(context.Context, *ThrottleRequest) (*ThrottleResponse, error)
+	// ListBrokerThrottles returns a BrokerThrottlesResponse with all configured
+	// broker-specific throttle overrides.
+	ListBrokerThrottles(context.Context, *Empty) (*BrokerThrottlesResponse, error)
+	// RemoveBrokerThrottles removes all broker-specific throttle overrides. The
+	// removed overrides are returned in the BrokerThrottlesResponse.
+	RemoveBrokerThrottles(context.Context, *Empty) (*BrokerThrottlesResponse, error)
+	// GetStatus returns a StatusResponse describing the autothrottle state as
+	// of the most recent check interval.
+	GetStatus(context.Context, *Empty) (*StatusResponse, error)
+	// GetCapacity returns a CapacityResponse describing the configured
+	// throttle rate limits and instance-type network capacities.
+	GetCapacity(context.Context, *Empty) (*CapacityResponse, error)
+	// Pause pauses autothrottle. While paused, autothrottle continues observing
+	// reassignments but doesn't write any throttle or override changes.
+	Pause(context.Context, *Empty) (*PauseResponse, error)
+	// Resume resumes a paused autothrottle.
+	Resume(context.Context, *Empty) (*PauseResponse, error)
+	mustEmbedUnimplementedAutothrottleServer()
+}
+
+// UnimplementedAutothrottleServer must be embedded to have forward compatible implementations.
+type UnimplementedAutothrottleServer struct {
+}
+
+func (UnimplementedAutothrottleServer) GetThrottle(context.Context, *ThrottleRequest) (*ThrottleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetThrottle not implemented")
+}
+func (UnimplementedAutothrottleServer) SetThrottle(context.Context, *ThrottleRequest) (*ThrottleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetThrottle not implemented")
+}
+func (UnimplementedAutothrottleServer) RemoveThrottle(context.Context, *ThrottleRequest) (*ThrottleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveThrottle not implemented")
+}
+func (UnimplementedAutothrottleServer) ListBrokerThrottles(context.Context, *Empty) (*BrokerThrottlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBrokerThrottles not implemented")
+}
+func (UnimplementedAutothrottleServer) RemoveBrokerThrottles(context.Context, *Empty) (*BrokerThrottlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveBrokerThrottles not implemented")
+}
+func (UnimplementedAutothrottleServer) GetStatus(context.Context, *Empty) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedAutothrottleServer) GetCapacity(context.Context, *Empty) (*CapacityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapacity not implemented")
+}
+func (UnimplementedAutothrottleServer) Pause(context.Context, *Empty) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedAutothrottleServer) Resume(context.Context, *Empty) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedAutothrottleServer) mustEmbedUnimplementedAutothrottleServer() {}
+
+// UnsafeAutothrottleServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AutothrottleServer will
+// result in compilation errors.
+type UnsafeAutothrottleServer interface {
+	mustEmbedUnimplementedAutothrottleServer()
+}
+
+func RegisterAutothrottleServer(s grpc.ServiceRegistrar, srv AutothrottleServer) {
+	s.RegisterService(&Autothrottle_ServiceDesc, srv)
+}
+
+func _Autothrottle_GetThrottle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThrottleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).GetThrottle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_GetThrottle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).GetThrottle(ctx, req.(*ThrottleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_SetThrottle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThrottleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).SetThrottle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_SetThrottle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).SetThrottle(ctx, req.(*ThrottleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_RemoveThrottle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThrottleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).RemoveThrottle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_RemoveThrottle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).RemoveThrottle(ctx, req.(*ThrottleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_ListBrokerThrottles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).ListBrokerThrottles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_ListBrokerThrottles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).ListBrokerThrottles(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_RemoveBrokerThrottles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).RemoveBrokerThrottles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_RemoveBrokerThrottles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).RemoveBrokerThrottles(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).GetStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_GetCapacity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).GetCapacity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_GetCapacity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).GetCapacity(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).Pause(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Autothrottle_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutothrottleServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autothrottle_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutothrottleServer).Resume(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Autothrottle_ServiceDesc is the grpc.ServiceDesc for Autothrottle service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Autothrottle_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "autothrottle.Autothrottle",
+	HandlerType: (*AutothrottleServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetThrottle",
+			Handler:    _Autothrottle_GetThrottle_Handler,
+		},
+		{
+			MethodName: "SetThrottle",
+			Handler:    _Autothrottle_SetThrottle_Handler,
+		},
+		{
+			MethodName: "RemoveThrottle",
+			Handler:    _Autothrottle_RemoveThrottle_Handler,
+		},
+		{
+			MethodName: "ListBrokerThrottles",
+			Handler:    _Autothrottle_ListBrokerThrottles_Handler,
+		},
+		{
+			MethodName: "RemoveBrokerThrottles",
+			Handler:    _Autothrottle_RemoveBrokerThrottles_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Autothrottle_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetCapacity",
+			Handler:    _Autothrottle_GetCapacity_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _Autothrottle_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Autothrottle_Resume_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "autothrottle.proto",
+}
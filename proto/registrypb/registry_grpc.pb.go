@@ -30,6 +30,7 @@ const (
 	Registry_ListTopics_FullMethodName            = "/registry.Registry/ListTopics"
 	Registry_CreateTopic_FullMethodName           = "/registry.Registry/CreateTopic"
 	Registry_DeleteTopic_FullMethodName           = "/registry.Registry/DeleteTopic"
+	Registry_ReconcileTopics_FullMethodName       = "/registry.Registry/ReconcileTopics"
 	Registry_ReassigningTopics_FullMethodName     = "/registry.Registry/ReassigningTopics"
 	Registry_UnderReplicatedTopics_FullMethodName = "/registry.Registry/UnderReplicatedTopics"
 	Registry_TopicMappings_FullMethodName         = "/registry.Registry/TopicMappings"
@@ -95,6 +96,18 @@ type RegistryClient interface {
 	// Example:
 	// $ curl -XDELETE "localhost:8080/v1/topics/mytopic"
 	DeleteTopic(ctx context.Context, in *TopicRequest, opts ...grpc.CallOption) (*Empty, error)
+	// ReconcileTopics takes a ReconcileTopicsRequest manifest of desired topics
+	// and reconciles the cluster toward it. Topics missing from the cluster are
+	// created and topic configs that differ from the manifest are altered.
+	// Partition count and replication factor differences and topics not in the
+	// manifest are reported but never changed. If dry_run is set, the diff is
+	// returned without making any changes.
+	// Example:
+	// $ curl -XPOST "localhost:8080/v1/topics/reconcile" -d '{
+	//     "dry_run": true,
+	//     "topics": [{"name": "mytopic", "partitions": 32, "replication": 2, "configs": {"retention.ms": "172800000"}}]
+	//   }'
+	ReconcileTopics(ctx context.Context, in *ReconcileTopicsRequest, opts ...grpc.CallOption) (*ReconcileTopicsResponse, error)
 	// ReassigningTopics returns a TopicResponse with the names field populated
 	// with topic names of all topics undergoing a reassignment.
 	ReassigningTopics(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TopicResponse, error)
@@ -211,6 +224,15 @@ func (c *registryClient) DeleteTopic(ctx context.Context, in *TopicRequest, opts
 	return out, nil
 }
 
+func (c *registryClient) ReconcileTopics(ctx context.Context, in *ReconcileTopicsRequest, opts ...grpc.CallOption) (*ReconcileTopicsResponse, error) {
+	out := new(ReconcileTopicsResponse)
+	err := c.cc.Invoke(ctx, Registry_ReconcileTopics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *registryClient) ReassigningTopics(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TopicResponse, error) {
 	out := new(TopicResponse)
 	err := c.cc.Invoke(ctx, Registry_ReassigningTopics_FullMethodName, in, out, opts...)
@@ -354,6 +376,18 @@ type RegistryServer interface {
 	// Example:
 	// $ curl -XDELETE "localhost:8080/v1/topics/mytopic"
 	DeleteTopic(context.Context, *TopicRequest) (*Empty, error)
+	// ReconcileTopics takes a ReconcileTopicsRequest manifest of desired topics
+	// and reconciles the cluster toward it. Topics missing from the cluster are
+	// created and topic configs that differ from the manifest are altered.
+	// Partition count and replication factor differences and topics not in the
+	// manifest are reported but never changed. If dry_run is set, the diff is
+	// returned without making any changes.
+	// Example:
+	// $ curl -XPOST "localhost:8080/v1/topics/reconcile" -d '{
+	//     "dry_run": true,
+	//     "topics": [{"name": "mytopic", "partitions": 32, "replication": 2, "configs": {"retention.ms": "172800000"}}]
+	//   }'
+	ReconcileTopics(context.Context, *ReconcileTopicsRequest) (*ReconcileTopicsResponse, error)
 	// ReassigningTopics returns a TopicResponse with the names field populated
 	// with topic names of all topics undergoing a reassignment.
 	ReassigningTopics(context.Context, *Empty) (*TopicResponse, error)
@@ -425,6 +459,9 @@ func (UnimplementedRegistryServer) CreateTopic(context.Context, *CreateTopicRequ
 func (UnimplementedRegistryServer) DeleteTopic(context.Context, *TopicRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteTopic not implemented")
 }
+func (UnimplementedRegistryServer) ReconcileTopics(context.Context, *ReconcileTopicsRequest) (*ReconcileTopicsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileTopics not implemented")
+}
 func (UnimplementedRegistryServer) ReassigningTopics(context.Context, *Empty) (*TopicResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReassigningTopics not implemented")
 }
@@ -594,6 +631,24 @@ func _Registry_DeleteTopic_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Registry_ReconcileTopics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileTopicsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).ReconcileTopics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_ReconcileTopics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).ReconcileTopics(ctx, req.(*ReconcileTopicsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Registry_ReassigningTopics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -809,6 +864,10 @@ var Registry_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteTopic",
 			Handler:    _Registry_DeleteTopic_Handler,
 		},
+		{
+			MethodName: "ReconcileTopics",
+			Handler:    _Registry_ReconcileTopics_Handler,
+		},
 		{
 			MethodName: "ReassigningTopics",
 			Handler:    _Registry_ReassigningTopics_Handler,